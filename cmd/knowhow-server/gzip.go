@@ -0,0 +1,174 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipMinSize is the response size from which responses are compressed.
+// Smaller responses gain little and cost CPU on both ends.
+const gzipMinSize = 1024
+
+var gzipWriters = sync.Pool{
+	New: func() any { return gzip.NewWriter(io.Discard) },
+}
+
+// gzipHandler compresses responses to requests accepting gzip once they
+// reach minSize bytes. WebSocket upgrades pass through untouched, since the
+// connection is hijacked for subscriptions.
+func gzipHandler(next http.Handler, minSize int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w, minSize: minSize}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.TrimSpace(coding)
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		// q=0 means "not acceptable"
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter buffers the start of a response until it reaches
+// minSize bytes, then sends it compressed. Responses ending or flushed
+// before that are sent as they are.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minSize int
+	status  int
+	buf     []byte
+	started bool         // headers sent
+	gz      *gzip.Writer // set once compressing
+}
+
+func (g *gzipResponseWriter) WriteHeader(code int) {
+	// Informational responses don't end the header phase
+	if code >= 100 && code < 200 {
+		g.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if g.status == 0 {
+		g.status = code
+	}
+}
+
+func (g *gzipResponseWriter) Write(p []byte) (int, error) {
+	if g.started {
+		if g.gz != nil {
+			return g.gz.Write(p)
+		}
+		return g.ResponseWriter.Write(p)
+	}
+
+	g.buf = append(g.buf, p...)
+	if len(g.buf) < g.minSize {
+		return len(p), nil
+	}
+	if err := g.start(true); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// start sends the headers and the buffered body, compressed or not.
+// Responses the handler already encoded are never compressed again.
+func (g *gzipResponseWriter) start(compress bool) error {
+	g.started = true
+	h := g.Header()
+	if compress && h.Get("Content-Encoding") == "" {
+		// Sniff before compressing: net/http would sniff the gzip bytes
+		if h.Get("Content-Type") == "" {
+			h.Set("Content-Type", http.DetectContentType(g.buf))
+		}
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		g.gz = gzipWriters.Get().(*gzip.Writer)
+		g.gz.Reset(g.ResponseWriter)
+	}
+	if g.status != 0 {
+		g.ResponseWriter.WriteHeader(g.status)
+	}
+
+	buf := g.buf
+	g.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if g.gz != nil {
+		_, err := g.gz.Write(buf)
+		return err
+	}
+	_, err := g.ResponseWriter.Write(buf)
+	return err
+}
+
+// Flush sends what was written so far. A response flushed before reaching
+// the minimum size is sent uncompressed.
+func (g *gzipResponseWriter) Flush() {
+	if !g.started {
+		if err := g.start(false); err != nil {
+			slog.Debug("failed to write response", "error", err)
+			return
+		}
+	}
+	if g.gz != nil {
+		if err := g.gz.Flush(); err != nil {
+			slog.Debug("failed to flush gzip response", "error", err)
+			return
+		}
+	}
+	if err := http.NewResponseController(g.ResponseWriter).Flush(); err != nil {
+		slog.Debug("failed to flush response", "error", err)
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// set deadlines.
+func (g *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+// close sends a response still buffered and finishes the gzip stream.
+func (g *gzipResponseWriter) close() {
+	if !g.started {
+		if err := g.start(false); err != nil {
+			slog.Debug("failed to write response", "error", err)
+		}
+	}
+	if g.gz == nil {
+		return
+	}
+	if err := g.gz.Close(); err != nil {
+		slog.Debug("failed to finish gzip response", "error", err)
+	}
+	gzipWriters.Put(g.gz)
+}
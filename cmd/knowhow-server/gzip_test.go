@@ -0,0 +1,110 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzipHandler(t *testing.T) {
+	large := `{"data":"` + strings.Repeat("knowhow ", 200) + `"}`
+	small := `{"data":"ok"}`
+
+	tests := []struct {
+		name           string
+		body           string
+		acceptEncoding string
+		upgrade        bool
+		wantGzip       bool
+	}{
+		{"large response", large, "gzip, deflate", false, true},
+		{"small response", small, "gzip", false, false},
+		{"client without gzip", large, "", false, false},
+		{"gzip refused", large, "gzip;q=0", false, false},
+		{"websocket upgrade", large, "gzip", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				// Write in pieces, crossing the threshold midway
+				for i := 0; i < len(tt.body); i += 100 {
+					if _, err := io.WriteString(w, tt.body[i:min(i+100, len(tt.body))]); err != nil {
+						t.Errorf("write: %v", err)
+					}
+				}
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/query", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			if tt.upgrade {
+				req.Header.Set("Upgrade", "websocket")
+			}
+			rec := httptest.NewRecorder()
+			gzipHandler(next, gzipMinSize).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusCreated {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusCreated)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+
+			body := rec.Body.String()
+			gzipped := rec.Header().Get("Content-Encoding") == "gzip"
+			if gzipped != tt.wantGzip {
+				t.Fatalf("gzip = %v, want %v", gzipped, tt.wantGzip)
+			}
+			if gzipped {
+				zr, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("gzip reader: %v", err)
+				}
+				raw, err := io.ReadAll(zr)
+				if err != nil {
+					t.Fatalf("decompress: %v", err)
+				}
+				body = string(raw)
+			}
+			if body != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestGzipHandlerClientRoundTrip(t *testing.T) {
+	large := strings.Repeat("knowhow ", 500)
+	srv := httptest.NewServer(gzipHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept-Encoding"); got != "gzip" {
+			t.Errorf("Accept-Encoding = %q, want gzip", got)
+		}
+		if _, err := io.WriteString(w, large); err != nil {
+			t.Errorf("write: %v", err)
+		}
+	}), gzipMinSize))
+	defer srv.Close()
+
+	// A default client asks for gzip and decompresses transparently
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !resp.Uncompressed {
+		t.Error("expected a compressed response")
+	}
+	if string(body) != large {
+		t.Errorf("body has %d bytes, want %d", len(body), len(large))
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// healthCheckTimeout bounds the database ping of a readiness probe, so a
+// slow database fails the probe instead of hanging it.
+const healthCheckTimeout = 2 * time.Second
+
+// healthStatus is the JSON body of the health endpoints.
+type healthStatus struct {
+	Status string `json:"status,omitempty"`
+	DB     string `json:"db,omitempty"`
+	Uptime *int64 `json:"uptime,omitempty"` // Seconds since the server started
+}
+
+// liveHandler reports that the process is up, without checking
+// dependencies (liveness probe).
+func liveHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, http.StatusOK, healthStatus{Status: "ok"})
+	}
+}
+
+// readyHandler pings the database and responds with 200 {"db":"up"} and the
+// uptime, or 503 {"db":"down"} if the ping fails or takes longer than
+// timeout (readiness probe).
+func readyHandler(ping func(context.Context) error, started time.Time, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		// Don't wait past the timeout even if the ping ignores ctx
+		result := make(chan error, 1)
+		go func() { result <- ping(ctx) }()
+		var err error
+		select {
+		case err = <-result:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		if err != nil {
+			slog.Warn("health check: database unreachable", "error", err)
+			writeHealth(w, http.StatusServiceUnavailable, healthStatus{DB: "down"})
+			return
+		}
+		uptime := int64(time.Since(started).Seconds())
+		writeHealth(w, http.StatusOK, healthStatus{DB: "up", Uptime: &uptime})
+	}
+}
+
+func writeHealth(w http.ResponseWriter, code int, status healthStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		slog.Debug("failed to write health status", "error", err)
+	}
+}
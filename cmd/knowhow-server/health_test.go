@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadyHandler(t *testing.T) {
+	started := time.Now().Add(-90 * time.Second)
+	tests := []struct {
+		name     string
+		ping     func(context.Context) error
+		wantCode int
+		wantBody string
+	}{
+		{"up", func(context.Context) error { return nil }, http.StatusOK, `{"db":"up","uptime":90}`},
+		{"down", func(context.Context) error { return errors.New("connection closed") }, http.StatusServiceUnavailable, `{"db":"down"}`},
+		{"slow", func(context.Context) error { time.Sleep(time.Second); return nil }, http.StatusServiceUnavailable, `{"db":"down"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			readyHandler(tt.ping, started, 50*time.Millisecond).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.wantBody {
+				t.Errorf("body = %s, want %s", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestLiveHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	liveHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+}
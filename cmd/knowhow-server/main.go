@@ -3,9 +3,11 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/fs"
 	"log/slog"
 	"net/http"
@@ -21,11 +23,64 @@ import (
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/gorilla/websocket"
 	"github.com/raphaelgruber/memcp-go/internal/config"
+	"github.com/raphaelgruber/memcp-go/internal/db"
 	"github.com/raphaelgruber/memcp-go/internal/graph"
+	"github.com/raphaelgruber/memcp-go/internal/metrics"
+	"github.com/raphaelgruber/memcp-go/internal/tracing"
 	"github.com/raphaelgruber/memcp-go/web"
 	"github.com/vektah/gqlparser/v2/ast"
 )
 
+// exportArchive streams the vault archive, flushing after every record.
+func exportArchive(w http.ResponseWriter, r *http.Request, rc *http.ResponseController, resolver *graph.Resolver) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="vault.ndjson"`)
+	if err := resolver.ExportArchive(r.Context(), flushWriter{w: w, rc: rc}); err != nil {
+		slog.Error("vault export failed", "error", err)
+		// Abort the response so the client sees a broken stream, not a
+		// truncated archive
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// importArchive imports the vault archive in the request body and responds
+// with the import counts as JSON. The on_conflict query parameter is passed
+// on as the conflict mode (default skip).
+func importArchive(w http.ResponseWriter, r *http.Request, resolver *graph.Resolver) {
+	onConflict := db.ConflictMode(r.URL.Query().Get("on_conflict"))
+	if onConflict == "" {
+		onConflict = db.ConflictSkip
+	}
+
+	stats, err := resolver.ImportArchive(r.Context(), r.Body, onConflict)
+	if err != nil {
+		slog.Warn("vault import failed", "imported", stats.Imported, "skipped", stats.Skipped, "error", err)
+		http.Error(w, fmt.Sprintf("import failed after %d imported and %d skipped records: %v", stats.Imported, stats.Skipped, err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	slog.Info("vault imported", "imported", stats.Imported, "skipped", stats.Skipped)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		slog.Warn("failed to write import result", "error", err)
+	}
+}
+
+// flushWriter flushes the response after each write, so archive records
+// reach the client as they're exported.
+type flushWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, f.rc.Flush()
+}
+
 func main() {
 	// Parse flags
 	wipeDB := flag.Bool("wipe", false, "wipe all data from database on startup (testing only)")
@@ -45,7 +100,7 @@ func main() {
 	if os.Getenv("LOG_LEVEL") == "debug" {
 		level = slog.LevelDebug
 	}
-	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	logger := slog.New(tracing.NewHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
 	slog.SetDefault(logger)
 
 	slog.Info("starting knowhow-server", "port", port)
@@ -102,6 +157,7 @@ func main() {
 	srv.Use(extension.AutomaticPersistedQuery{
 		Cache: lru.New[string](100),
 	})
+	srv.AroundOperations(logOperation)
 
 	// Setup routes
 	mux := http.NewServeMux()
@@ -109,13 +165,63 @@ func main() {
 	// GraphQL playground moved to /playground
 	mux.Handle("/playground", playground.Handler("Knowhow GraphQL", "/query"))
 
+	// Rate limits per client IP, checked before requests reach gqlgen
+	queryLimiter := newRateLimiter(cfg.RateLimit, cfg.TrustProxy)
+	subscriptionLimiter := newRateLimiter(cfg.SubscriptionRateLimit, cfg.TrustProxy)
+	evictCtx, stopEviction := context.WithCancel(context.Background())
+	defer stopEviction()
+	go queryLimiter.runEviction(evictCtx)
+	go subscriptionLimiter.runEviction(evictCtx)
+
 	// GraphQL endpoint (no CORS needed: Vite proxy handles dev, same-origin handles prod)
-	mux.Handle("/query", srv)
+	var queryHandler http.Handler = srv
+	if cfg.HTTPCompress {
+		queryHandler = gzipHandler(queryHandler, gzipMinSize)
+	}
+	mux.Handle("/query", traceHandler(rateLimit(queryHandler, queryLimiter, subscriptionLimiter)))
+
+	// Health checks: /health and /health/ready ping the database (readiness),
+	// /health/live only answers if the process is up (liveness)
+	started := time.Now()
+	ready := readyHandler(resolver.PingDB, started, healthCheckTimeout)
+	mux.HandleFunc("/health", ready)
+	mux.HandleFunc("/health/ready", ready)
+	mux.HandleFunc("/health/live", liveHandler())
+
+	// Prometheus metrics endpoint, a read view of the collector behind serverStats
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := resolver.Metrics().WritePrometheus(w); err != nil {
+			slog.Warn("failed to write metrics", "error", err)
+			return
+		}
+		if cfg.MetricsRuntime {
+			if err := metrics.WriteRuntimePrometheus(w); err != nil {
+				slog.Warn("failed to write runtime metrics", "error", err)
+			}
+		}
+	})
 
-	// Health check endpoint
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintln(w, "ok")
+	// Vault archive for backups, streamed as NDJSON (too large for a GraphQL
+	// query): GET exports, POST imports
+	mux.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) {
+		// Large vaults take longer than the server's read and write timeouts
+		rc := http.NewResponseController(w)
+		if err := rc.SetReadDeadline(time.Time{}); err != nil {
+			slog.Warn("failed to clear read deadline for archive", "error", err)
+		}
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			slog.Warn("failed to clear write deadline for archive", "error", err)
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			exportArchive(w, r, rc, resolver)
+		case http.MethodPost:
+			importArchive(w, r, resolver)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
 	})
 
 	// Serve embedded SPA from web/dist
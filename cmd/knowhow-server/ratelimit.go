@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a per-client token bucket limiter. Each client may burst up
+// to a minute's worth of requests, refilled continuously.
+type rateLimiter struct {
+	perMinute  int
+	trustProxy bool // Key by X-Forwarded-For instead of the remote address
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a limiter allowing perMinute requests per client.
+// Returns nil (no limit) if perMinute isn't positive.
+func newRateLimiter(perMinute int, trustProxy bool) *rateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		perMinute:  perMinute,
+		trustProxy: trustProxy,
+		buckets:    make(map[string]*bucket),
+		now:        time.Now,
+	}
+}
+
+// allow takes a token from key's bucket. If the bucket is empty, it returns
+// false and how long until the next token is available.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	capacity := float64(l.perMinute)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[key] = b
+	}
+
+	perSecond := capacity / 60
+	b.tokens = min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// evictIdle removes buckets that have been refilled completely, which behave
+// the same as a new bucket.
+func (l *rateLimiter) evictIdle() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= time.Minute {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// runEviction evicts idle buckets every minute until ctx is done.
+func (l *rateLimiter) runEviction(ctx context.Context) {
+	if l == nil {
+		return
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.evictIdle()
+		}
+	}
+}
+
+// clientIP returns the client address of r. Behind a trusted proxy, that's
+// the last X-Forwarded-For entry, the address the proxy itself appended.
+func (l *rateLimiter) clientIP(r *http.Request) string {
+	if l.trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			hops := strings.Split(fwd, ",")
+			if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimit wraps next, limiting queries and mutations with queries and
+// WebSocket subscription upgrades with subscriptions. Limited requests get a
+// 429 with a Retry-After header and never reach next. A nil limiter doesn't
+// limit.
+func rateLimit(next http.Handler, queries, subscriptions *rateLimiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limiter := queries
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			limiter = subscriptions
+		}
+		if limiter != nil {
+			if ok, wait := limiter.allow(limiter.clientIP(r)); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
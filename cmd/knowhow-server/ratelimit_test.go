@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	now := time.Now()
+	queries := newRateLimiter(2, false)
+	queries.now = func() time.Time { return now }
+	subscriptions := newRateLimiter(1, false)
+	subscriptions.now = func() time.Time { return now }
+
+	handler := rateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), queries, subscriptions)
+	do := func(remoteAddr string, upgrade bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/query", nil)
+		req.RemoteAddr = remoteAddr
+		if upgrade {
+			req.Header.Set("Upgrade", "websocket")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := range 2 {
+		if rec := do("10.0.0.1:1234", false); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i+1, rec.Code)
+		}
+	}
+	rec := do("10.0.0.1:5678", false)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want 30", got)
+	}
+
+	if rec := do("10.0.0.2:1234", false); rec.Code != http.StatusOK {
+		t.Errorf("other client: status = %d, want 200", rec.Code)
+	}
+
+	if rec := do("10.0.0.1:1234", true); rec.Code != http.StatusOK {
+		t.Errorf("first upgrade: status = %d, want 200", rec.Code)
+	}
+	if rec := do("10.0.0.1:1234", true); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("second upgrade: status = %d, want 429", rec.Code)
+	}
+
+	now = now.Add(30 * time.Second)
+	if rec := do("10.0.0.1:1234", false); rec.Code != http.StatusOK {
+		t.Errorf("after refill: status = %d, want 200", rec.Code)
+	}
+
+	now = now.Add(time.Minute)
+	queries.evictIdle()
+	if len(queries.buckets) != 0 {
+		t.Errorf("buckets after eviction = %d, want 0", len(queries.buckets))
+	}
+}
+
+func TestRateLimitClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/query", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4, 5.6.7.8")
+
+	if got := newRateLimiter(1, false).clientIP(req); got != "10.0.0.1" {
+		t.Errorf("untrusted proxy: clientIP = %q, want 10.0.0.1", got)
+	}
+	if got := newRateLimiter(1, true).clientIP(req); got != "5.6.7.8" {
+		t.Errorf("trusted proxy: clientIP = %q, want 5.6.7.8", got)
+	}
+}
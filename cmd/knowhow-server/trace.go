@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/raphaelgruber/memcp-go/internal/tracing"
+)
+
+// traceHandler gives each request a trace ID, taken from a W3C traceparent
+// header when the caller sends one, and returns it in the X-Trace-Id header.
+// Logs written with the request context carry it as trace_id.
+func traceHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := tracing.FromTraceparent(r.Header.Get("traceparent"))
+		if id == "" {
+			id = tracing.NewID()
+		}
+		w.Header().Set(tracing.Header, id)
+		next.ServeHTTP(w, r.WithContext(tracing.WithID(r.Context(), id)))
+	})
+}
+
+// logOperation logs each GraphQL operation and its duration at debug level,
+// with the trace ID of its request.
+func logOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	op := graphql.GetOperationContext(ctx)
+	name := op.OperationName
+	if name == "" && op.Operation != nil {
+		name = string(op.Operation.Operation)
+	}
+	slog.DebugContext(ctx, "graphql operation", "operation", name)
+
+	start := time.Now()
+	handler := next(ctx)
+	logged := false
+	return func(ctx context.Context) *graphql.Response {
+		resp := handler(ctx)
+		// Subscriptions return many responses; time up to the first
+		if !logged {
+			logged = true
+			slog.DebugContext(ctx, "graphql operation complete", "operation", name, "duration_ms", time.Since(start).Milliseconds())
+		}
+		return resp
+	}
+}
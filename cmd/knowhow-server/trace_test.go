@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/raphaelgruber/memcp-go/internal/tracing"
+)
+
+func TestTraceHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		want        string // "" for a generated ID
+	}{
+		{"no traceparent", "", ""},
+		{"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"malformed traceparent", "garbage", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = tracing.ID(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/query", nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			rec := httptest.NewRecorder()
+			traceHandler(next).ServeHTTP(rec, req)
+
+			got := rec.Header().Get(tracing.Header)
+			if len(got) != 32 {
+				t.Fatalf("%s = %q, want a 32-digit trace ID", tracing.Header, got)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("%s = %q, want %q", tracing.Header, got, tt.want)
+			}
+			if seen != got {
+				t.Errorf("context trace ID = %q, want %q", seen, got)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ var (
 	addLabels    []string
 	addSummary   string
 	addRelatesTo []string
+	addContext   string
 )
 
 var addCmd = &cobra.Command{
@@ -24,12 +25,14 @@ var addCmd = &cobra.Command{
 The content can be a simple note, fact, or any piece of information.
 Use --type to specify the entity type (concept, note, task, etc.).
 Use --labels to add organizational tags.
+Use --context to put it in a project namespace.
 
 Examples:
   knowhow add "SurrealDB supports HNSW indexes for vector search"
   knowhow add "John Doe is a senior SRE" --type person --labels "work,team-platform"
   knowhow add "Fix token refresh bug" --type task --labels "work,auth-service"
-  knowhow add "Meeting notes from standup" --relates-to "john-doe:mentioned_in"`,
+  knowhow add "Meeting notes from standup" --relates-to "john-doe:mentioned_in"
+  knowhow add "Deploys go through ArgoCD" --context payments-api`,
 	Args: cobra.ExactArgs(1),
 	RunE: runAdd,
 }
@@ -39,6 +42,7 @@ func init() {
 	addCmd.Flags().StringSliceVarP(&addLabels, "labels", "l", nil, "labels/tags for organization")
 	addCmd.Flags().StringVarP(&addSummary, "summary", "s", "", "short summary (auto-generated if not provided)")
 	addCmd.Flags().StringSliceVar(&addRelatesTo, "relates-to", nil, "relations in format entity:rel_type")
+	addCmd.Flags().StringVar(&addContext, "context", "", "project namespace of the entity (default: global)")
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
@@ -64,6 +68,9 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	if addSummary != "" {
 		input.Summary = &addSummary
 	}
+	if addContext != "" {
+		input.Context = &addContext
+	}
 
 	// Create entity via GraphQL
 	entity, err := gqlClient.CreateEntity(ctx, input)
@@ -16,9 +16,22 @@ var (
 	askLabels     []string
 	askTypes      []string
 	askVerified   bool
+	askMinConf    float64
 	askLimit      int
+	askDiversity  float64
+	askRerank     bool
+	askDecay      bool
+	askExpand     bool
+	askLanguage   string
+	askContext    string
+	askMode       string
 	askOutputFile string
 	askNoStream   bool
+	askBatchFile  string
+	askProvider   string
+	askModel      string
+	askSystem     string
+	askPreset     string
 )
 
 var askCmd = &cobra.Command{
@@ -31,12 +44,30 @@ an answer using the configured LLM.
 
 Optionally use --template to format the response using a predefined template.
 
+Use --system-prompt to replace the default system prompt, or --prompt-preset
+to use a stored one. A preset named "terse" is the template
+"system-prompt:terse", added with:
+  knowhow template add terse.md --name "system-prompt:terse"
+
 Examples:
   knowhow ask "What do I know about John Doe?"
   knowhow ask "How does the auth service work?"
   knowhow ask "John Doe" --template "Peer Review"
-  knowhow ask "auth-service" --template "Service Summary" -o summary.md`,
-	Args: cobra.ExactArgs(1),
+  knowhow ask "auth-service" --template "Service Summary" -o summary.md
+  knowhow ask "How do we deploy?" --diversity 0.5
+  knowhow ask "Why did the March outage happen?" --rerank
+  knowhow ask "What does the payment service depend on?" --expand-graph
+  knowhow ask "What does ERR_TOKEN_EXPIRED mean?" --mode keyword
+  knowhow ask "Summarize our auth design" --provider anthropic --model claude-opus-4-1
+  knowhow ask "How do we deploy?" --system-prompt "Answer in German, as bullet points."
+  knowhow ask "How do we deploy?" --prompt-preset terse
+  knowhow ask --batch questions.txt -o faq.md`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if askBatchFile != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: runAsk,
 }
 
@@ -45,12 +76,36 @@ func init() {
 	askCmd.Flags().StringSliceVarP(&askLabels, "labels", "l", nil, "filter by labels")
 	askCmd.Flags().StringSliceVarP(&askTypes, "type", "t", nil, "filter by entity types")
 	askCmd.Flags().BoolVar(&askVerified, "verified", false, "only use verified knowledge")
+	askCmd.Flags().Float64Var(&askMinConf, "min-confidence", 0, "0-1: only use knowledge with at least this confidence")
 	askCmd.Flags().IntVarP(&askLimit, "limit", "n", 20, "max context entities")
+	askCmd.Flags().Float64Var(&askDiversity, "diversity", 0, "0-1: prefer varied sources over the most similar ones")
+	askCmd.Flags().BoolVar(&askRerank, "rerank", false, "pick sources by the server's rerank model")
+	askCmd.Flags().BoolVar(&askDecay, "decay", false, "prefer recently accessed sources")
+	askCmd.Flags().BoolVar(&askExpand, "expand-graph", false, "also give the LLM summaries of entities related to the top sources")
+	askCmd.Flags().StringVar(&askLanguage, "language", "", "only use entities in this language (ISO 639-1 code, e.g. de)")
+	askCmd.Flags().StringVar(&askContext, "context", "", "only use entities in this project context")
+	askCmd.Flags().StringVar(&askMode, "mode", "", "retrieval: hybrid (default), keyword or vector")
 	askCmd.Flags().StringVarP(&askOutputFile, "output", "o", "", "write output to file")
 	askCmd.Flags().BoolVar(&askNoStream, "no-stream", false, "disable streaming output")
+	askCmd.Flags().StringVar(&askProvider, "provider", "", "LLM provider for this question (default: server config)")
+	askCmd.Flags().StringVar(&askModel, "model", "", "LLM model for this question (default: server config)")
+	askCmd.Flags().StringVar(&askBatchFile, "batch", "", "answer questions from a file (one per line) as Q&A markdown")
+	askCmd.Flags().StringVar(&askSystem, "system-prompt", "", "replace the default system prompt of the answer")
+	askCmd.Flags().StringVar(&askPreset, "prompt-preset", "", "use a stored system prompt (template \"system-prompt:<name>\")")
+	askCmd.MarkFlagsMutuallyExclusive("system-prompt", "prompt-preset")
+	askCmd.MarkFlagsMutuallyExclusive("template", "system-prompt")
+	askCmd.MarkFlagsMutuallyExclusive("template", "prompt-preset")
+	askCmd.MarkFlagsMutuallyExclusive("batch", "system-prompt")
+	askCmd.MarkFlagsMutuallyExclusive("batch", "prompt-preset")
+	askCmd.MarkFlagsMutuallyExclusive("batch", "provider")
+	askCmd.MarkFlagsMutuallyExclusive("batch", "model")
 }
 
 func runAsk(cmd *cobra.Command, args []string) error {
+	if askBatchFile != "" {
+		return runAskBatch(cmd)
+	}
+
 	query := args[0]
 	ctx := context.Background()
 
@@ -59,14 +114,34 @@ func runAsk(cmd *cobra.Command, args []string) error {
 		Labels:       askLabels,
 		Types:        askTypes,
 		VerifiedOnly: &askVerified,
+		Diversity:    &askDiversity,
+		Rerank:       askRerank,
+		ApplyDecay:   askDecay,
+		ExpandGraph:  askExpand,
+		Language:     askLanguage,
+		Context:      askContext,
+		Mode:         askMode,
 		Limit:        &askLimit,
 	}
+	if cmd.Flags().Changed("min-confidence") {
+		opts.MinConfidence = &askMinConf
+	}
 
 	var templateName *string
 	if askTemplate != "" {
 		templateName = &askTemplate
 	}
 
+	var override *client.ModelOverride
+	if askProvider != "" || askModel != "" {
+		override = &client.ModelOverride{Provider: askProvider, Model: askModel}
+	}
+
+	var prompt *client.AskPrompt
+	if askSystem != "" || askPreset != "" {
+		prompt = &client.AskPrompt{SystemPrompt: askSystem, Preset: askPreset}
+	}
+
 	// Auto-detect: stream unless writing to file, not a TTY, or explicitly disabled
 	// Templates don't support streaming yet
 	shouldStream := !askNoStream &&
@@ -77,7 +152,7 @@ func runAsk(cmd *cobra.Command, args []string) error {
 	if shouldStream {
 		// Streaming mode - tokens printed as they arrive
 		var fullAnswer strings.Builder
-		err := gqlClient.AskStream(ctx, query, opts, templateName, func(token string) error {
+		err := gqlClient.AskStream(ctx, query, opts, templateName, override, prompt, func(token string) error {
 			fmt.Print(token)
 			fullAnswer.WriteString(token)
 			return nil
@@ -91,7 +166,7 @@ func runAsk(cmd *cobra.Command, args []string) error {
 	}
 
 	// Non-streaming mode - wait for complete response
-	answer, err := gqlClient.Ask(ctx, query, opts, templateName)
+	answer, err := gqlClient.Ask(ctx, query, opts, templateName, override, prompt)
 	if err != nil {
 		return fmt.Errorf("ask: %w", err)
 	}
@@ -108,3 +183,78 @@ func runAsk(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// runAskBatch answers every question in askBatchFile in a single request and
+// renders the answers as a Q&A markdown document.
+func runAskBatch(cmd *cobra.Command) error {
+	ctx := context.Background()
+
+	data, err := os.ReadFile(askBatchFile)
+	if err != nil {
+		return fmt.Errorf("read batch file: %w", err)
+	}
+
+	var questions []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		questions = append(questions, line)
+	}
+	if len(questions) == 0 {
+		return fmt.Errorf("no questions found in %s", askBatchFile)
+	}
+
+	opts := &client.SearchOptions{
+		Labels:       askLabels,
+		Types:        askTypes,
+		VerifiedOnly: &askVerified,
+		Diversity:    &askDiversity,
+		Rerank:       askRerank,
+		ApplyDecay:   askDecay,
+		ExpandGraph:  askExpand,
+		Language:     askLanguage,
+		Context:      askContext,
+		Mode:         askMode,
+		Limit:        &askLimit,
+	}
+	if cmd.Flags().Changed("min-confidence") {
+		opts.MinConfidence = &askMinConf
+	}
+
+	answers, err := gqlClient.AskBatch(ctx, questions, opts)
+	if err != nil {
+		return fmt.Errorf("ask batch: %w", err)
+	}
+
+	var doc strings.Builder
+	var inputTokens, outputTokens, failed int
+	for _, a := range answers {
+		fmt.Fprintf(&doc, "## %s\n\n", a.Question)
+		if a.Error != nil {
+			fmt.Fprintf(&doc, "_Error: %s_\n\n", *a.Error)
+			failed++
+			continue
+		}
+		fmt.Fprintf(&doc, "%s\n\n", strings.TrimSpace(a.Answer))
+		inputTokens += a.InputTokens
+		outputTokens += a.OutputTokens
+	}
+
+	if askOutputFile != "" {
+		if err := os.WriteFile(askOutputFile, []byte(doc.String()), 0644); err != nil {
+			return fmt.Errorf("write output file: %w", err)
+		}
+		fmt.Printf("%d answers written to %s\n", len(answers)-failed, askOutputFile)
+	} else {
+		fmt.Print(doc.String())
+	}
+
+	fmt.Fprintf(os.Stderr, "Tokens: %d input, %d output", inputTokens, outputTokens)
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, " (%d questions failed)", failed)
+	}
+	fmt.Fprintln(os.Stderr)
+	return nil
+}
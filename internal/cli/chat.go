@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/raphaelgruber/memcp-go/internal/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	chatConversation string
+	chatTitle        string
+	chatLabels       []string
+	chatTypes        []string
+	chatVerified     bool
+	chatLimit        int
+)
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Chat interactively with the knowledge base",
+	Long: `Start an interactive chat. Each question searches the knowledge base and
+the answer is streamed; earlier turns are sent along so follow-up questions
+work. Messages are saved in a conversation that can be resumed later.
+
+Commands:
+  /search <query>  search without asking the LLM
+  /sources         entities retrieved for the last question
+  /history <query> search messages of past conversations
+  /new             start a new conversation
+  /help            show commands
+  /quit            leave (or Ctrl+D)
+
+Examples:
+  knowhow chat
+  knowhow chat --labels "work" --title "Auth questions"
+  knowhow chat --conversation <id>  # resume`,
+	Args: cobra.NoArgs,
+	RunE: runChat,
+}
+
+func init() {
+	chatCmd.Flags().StringVar(&chatConversation, "conversation", "", "resume an existing conversation by ID")
+	chatCmd.Flags().StringVar(&chatTitle, "title", "", "title for the new conversation")
+	chatCmd.Flags().StringSliceVarP(&chatLabels, "labels", "l", nil, "filter context by labels")
+	chatCmd.Flags().StringSliceVarP(&chatTypes, "type", "t", nil, "filter context by entity types")
+	chatCmd.Flags().BoolVar(&chatVerified, "verified", false, "only use verified knowledge")
+	chatCmd.Flags().IntVarP(&chatLimit, "limit", "n", 20, "max context entities per question")
+	chatCmd.MarkFlagsMutuallyExclusive("conversation", "title")
+	rootCmd.AddCommand(chatCmd)
+}
+
+// chatSession is the state of an interactive chat.
+type chatSession struct {
+	conversationID string
+	history        []client.ChatMessage
+	lastQuestion   string
+}
+
+func runChat(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	session := &chatSession{}
+	if chatConversation != "" {
+		conv, err := gqlClient.GetConversation(ctx, chatConversation)
+		if err != nil {
+			return fmt.Errorf("get conversation: %w", err)
+		}
+		if conv == nil {
+			return fmt.Errorf("conversation not found: %s", chatConversation)
+		}
+		session.conversationID = conv.ID
+		for _, m := range conv.Messages {
+			session.history = append(session.history, client.ChatMessage{Role: m.Role, Content: m.Content})
+		}
+		fmt.Printf("Resumed %q (%d messages)\n", conv.Title, len(conv.Messages))
+	} else if err := session.start(ctx, chatTitle); err != nil {
+		return err
+	}
+	fmt.Println("Ask a question, /help for commands, /quit to leave.")
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for {
+		fmt.Print("\n> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			break
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "/") {
+			quit, err := session.command(ctx, line)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			if quit {
+				return nil
+			}
+			continue
+		}
+
+		if err := session.ask(ctx, line); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read input: %w", err)
+	}
+	return nil
+}
+
+// start creates a new conversation and clears the history.
+func (s *chatSession) start(ctx context.Context, title string) error {
+	conv, err := gqlClient.CreateConversation(ctx, title)
+	if err != nil {
+		return fmt.Errorf("create conversation: %w", err)
+	}
+	s.conversationID = conv.ID
+	s.history = nil
+	s.lastQuestion = ""
+	fmt.Printf("Conversation %s\n", conv.ID)
+	return nil
+}
+
+// searchOptions returns the context filters from the command flags.
+func (s *chatSession) searchOptions() *client.SearchOptions {
+	return &client.SearchOptions{
+		Labels:       chatLabels,
+		Types:        chatTypes,
+		VerifiedOnly: &chatVerified,
+		Limit:        &chatLimit,
+	}
+}
+
+// ask streams the answer to question and records the turn. Ctrl+C stops the
+// answer without leaving the chat.
+func (s *chatSession) ask(ctx context.Context, question string) error {
+	streamCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	var answer strings.Builder
+	err := gqlClient.ChatStream(streamCtx, s.conversationID, question, s.history, s.searchOptions(), func(token string) error {
+		fmt.Print(token)
+		answer.WriteString(token)
+		return nil
+	})
+	fmt.Println()
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("(interrupted)")
+	} else if err != nil {
+		return err
+	}
+
+	s.lastQuestion = question
+	s.history = append(s.history, client.ChatMessage{Role: "user", Content: question})
+	if answer.Len() > 0 {
+		s.history = append(s.history, client.ChatMessage{Role: "assistant", Content: answer.String()})
+	}
+	return nil
+}
+
+// command runs a slash command. Returns true if the chat should end.
+func (s *chatSession) command(ctx context.Context, line string) (bool, error) {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "/quit", "/exit":
+		return true, nil
+
+	case "/new":
+		return false, s.start(ctx, arg)
+
+	case "/search":
+		if arg == "" {
+			return false, fmt.Errorf("usage: /search <query>")
+		}
+		return false, s.printSearch(ctx, arg)
+
+	case "/history":
+		if arg == "" {
+			return false, fmt.Errorf("usage: /history <query>")
+		}
+		return false, printMessageSearch(ctx, arg)
+
+	case "/sources":
+		if s.lastQuestion == "" {
+			fmt.Println("No question asked yet.")
+			return false, nil
+		}
+		return false, s.printSearch(ctx, s.lastQuestion)
+
+	case "/help":
+		fmt.Println("/search <query>  search without asking the LLM")
+		fmt.Println("/sources         entities retrieved for the last question")
+		fmt.Println("/history <query> search messages of past conversations")
+		fmt.Println("/new [title]     start a new conversation")
+		fmt.Println("/quit            leave (or Ctrl+D)")
+		return false, nil
+
+	default:
+		return false, fmt.Errorf("unknown command %s (try /help)", name)
+	}
+}
+
+// printMessageSearch lists the messages of past conversations matching query.
+func printMessageSearch(ctx context.Context, query string) error {
+	results, err := gqlClient.SearchMessages(ctx, query, 0)
+	if err != nil {
+		return fmt.Errorf("search messages: %w", err)
+	}
+	if len(results) == 0 {
+		fmt.Println("No messages found.")
+		return nil
+	}
+	for i, result := range results {
+		fmt.Printf("%d. %s (%s) %s, %s\n", i+1, result.ConversationTitle, result.ConversationID,
+			result.Message.Role, result.Message.CreatedAt.Format("2006-01-02 15:04"))
+		fmt.Printf("   %s\n", truncate(strings.Join(strings.Fields(result.Message.Content), " "), 120))
+	}
+	return nil
+}
+
+// printSearch lists the entities a search with the chat filters returns.
+func (s *chatSession) printSearch(ctx context.Context, query string) error {
+	opts := s.searchOptions()
+	opts.Query = query
+	results, err := gqlClient.Search(ctx, *opts)
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+	if len(results) == 0 {
+		fmt.Println("No results found.")
+		return nil
+	}
+	for i, result := range results {
+		fmt.Printf("%d. %s [%s] (%s)\n", i+1, result.Entity.Name, result.Entity.Type, result.Entity.ID)
+		for _, chunk := range result.MatchedChunks {
+			if chunk.HeadingPath != nil && *chunk.HeadingPath != "" {
+				fmt.Printf("   § %s\n", *chunk.HeadingPath)
+			}
+		}
+	}
+	return nil
+}
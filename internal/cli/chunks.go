@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/raphaelgruber/memcp-go/internal/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	chunksThreshold  int
+	chunksTargetSize int
+	chunksMinSize    int
+	chunksMaxSize    int
+	chunksOverlap    int
+	chunksStrategy   string
+)
+
+var chunksCmd = &cobra.Command{
+	Use:   "chunks",
+	Short: "Inspect how documents are chunked",
+	Long: `Inspect how documents are chunked for search.
+
+Subcommands:
+  preview  Show the chunks a Markdown file would be split into
+
+Examples:
+  knowhow chunks preview docs/architecture.md
+  knowhow chunks preview docs/architecture.md --target-size 500 --max-size 800
+  knowhow chunks preview docs/architecture.md --strategy fixed --target-size 600`,
+}
+
+var chunksPreviewCmd = &cobra.Command{
+	Use:   "preview <file>",
+	Short: "Show the chunks a Markdown file would be split into",
+	Long: `Show the chunks a Markdown file would be split into on ingest, using the
+server's chunking settings. Nothing is stored or embedded.
+
+Override parameters to try other settings; use --verbose to print the full
+chunk text instead of a preview.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChunksPreview,
+}
+
+func init() {
+	chunksPreviewCmd.Flags().IntVar(&chunksThreshold, "threshold", 0, "only chunk content longer than this many characters")
+	chunksPreviewCmd.Flags().IntVar(&chunksTargetSize, "target-size", 0, "ideal chunk size in characters")
+	chunksPreviewCmd.Flags().IntVar(&chunksMinSize, "min-size", 0, "smaller chunks merge with neighbors")
+	chunksPreviewCmd.Flags().IntVar(&chunksMaxSize, "max-size", 0, "larger chunks split at sentences")
+	chunksPreviewCmd.Flags().IntVar(&chunksOverlap, "overlap", 0, "characters repeated between neighboring chunks")
+	chunksPreviewCmd.Flags().StringVar(&chunksStrategy, "strategy", "", "split by heading (default), fixed size or sentence")
+
+	chunksCmd.AddCommand(chunksPreviewCmd)
+	rootCmd.AddCommand(chunksCmd)
+}
+
+func runChunksPreview(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	// Only send parameters that were set, so the server's defaults apply otherwise
+	var opts client.ChunkOptions
+	for flag, target := range map[string]**int{
+		"threshold":   &opts.Threshold,
+		"target-size": &opts.TargetSize,
+		"min-size":    &opts.MinSize,
+		"max-size":    &opts.MaxSize,
+		"overlap":     &opts.Overlap,
+	} {
+		if cmd.Flags().Changed(flag) {
+			v, err := cmd.Flags().GetInt(flag)
+			if err != nil {
+				return fmt.Errorf("read --%s: %w", flag, err)
+			}
+			*target = &v
+		}
+	}
+
+	if chunksStrategy != "" {
+		opts.Strategy = &chunksStrategy
+	}
+
+	chunks, err := gqlClient.PreviewChunks(ctx, string(content), opts)
+	if err != nil {
+		return fmt.Errorf("preview chunks: %w", err)
+	}
+
+	if len(chunks) == 0 {
+		fmt.Printf("%s would not be chunked (%d characters, below the threshold or a single chunk)\n", args[0], len(content))
+		return nil
+	}
+
+	fmt.Printf("%s: %d chunks\n\n", args[0], len(chunks))
+	for _, chunk := range chunks {
+		heading := ""
+		if chunk.HeadingPath != nil && *chunk.HeadingPath != "" {
+			heading = " § " + *chunk.HeadingPath
+		}
+		fmt.Printf("[%d]%s (%d chars)\n", chunk.Position, heading, chunk.Length)
+
+		text := chunk.Content
+		if !verbose && len(text) > 200 {
+			text = text[:200] + "..."
+		}
+		for _, line := range strings.Split(text, "\n") {
+			fmt.Printf("   %s\n", line)
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var compactDryRun bool
+
+var compactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Remove dangling chunks, relations and empty entities",
+	Long: `Remove data left behind over time:
+
+  - chunks whose entity no longer exists
+  - relations whose source or target entity no longer exists
+  - entities with no content, summary or relations
+
+Use --dry-run to see what would be removed first.
+
+Examples:
+  knowhow compact --dry-run
+  knowhow compact`,
+	RunE: runCompact,
+}
+
+func init() {
+	compactCmd.Flags().BoolVar(&compactDryRun, "dry-run", false, "only report what would be removed")
+	rootCmd.AddCommand(compactCmd)
+}
+
+func runCompact(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	report, err := gqlClient.Compact(ctx, compactDryRun)
+	if err != nil {
+		return fmt.Errorf("compact: %w", err)
+	}
+
+	action := "Removed"
+	if report.DryRun {
+		action = "Would remove"
+	}
+
+	fmt.Printf("%s:\n", action)
+	fmt.Printf("  Orphaned chunks:    %d\n", report.OrphanedChunks)
+	fmt.Printf("  Dangling relations: %d\n", report.DanglingRelations)
+	fmt.Printf("  Empty entities:     %d\n", len(report.EmptyEntities))
+	for _, e := range report.EmptyEntities {
+		fmt.Printf("    - %s [%s] (%s)\n", e.Name, e.Type, e.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var decayApply bool
+
+var decayCmd = &cobra.Command{
+	Use:   "decay",
+	Short: "Show or apply entity decay settings",
+	Long: `Show the effective decay curve and half-life for each entity type.
+
+Entity weights fall with time since last access according to the server's
+KNOWHOW_DECAY_* settings. They are computed when searching, so accessing an
+entity resets its weight to 1.0 right away. --apply runs the confidence decay
+of unverified AI-generated entities (KNOWHOW_DECAY_AI_CONFIDENCE_DAYS) now.
+
+Examples:
+  knowhow decay
+  knowhow decay --apply`,
+	RunE: runDecay,
+}
+
+func init() {
+	decayCmd.Flags().BoolVar(&decayApply, "apply", false, "apply confidence decay of unverified AI entities now")
+	rootCmd.AddCommand(decayCmd)
+}
+
+func runDecay(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if decayApply {
+		if _, err := gqlClient.ApplyDecay(ctx); err != nil {
+			return fmt.Errorf("apply decay: %w", err)
+		}
+		fmt.Println("Confidence decay applied.")
+		fmt.Println()
+	}
+
+	cfg, err := gqlClient.GetDecayConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("get decay config: %w", err)
+	}
+
+	fmt.Printf("Curve:      %s\n", cfg.Curve)
+	fmt.Printf("Half-life:  %s (default)\n", formatHalfLife(cfg.DefaultHalfLifeDays))
+	fmt.Printf("Min weight: %.2f\n", cfg.MinWeight)
+	if cfg.IntervalSeconds > 0 {
+		fmt.Printf("Interval:   %s\n", time.Duration(cfg.IntervalSeconds)*time.Second)
+	} else {
+		fmt.Printf("Interval:   disabled\n")
+	}
+
+	if len(cfg.Types) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\nBy Type:\n")
+	for _, t := range cfg.Types {
+		source := "default"
+		if t.Configured {
+			source = "configured"
+		}
+		fmt.Printf("  %-20s %12s  (%s)\n", t.Type, formatHalfLife(t.HalfLifeDays), source)
+	}
+
+	return nil
+}
+
+// formatHalfLife formats a half-life in days; non-positive values disable decay.
+func formatHalfLife(days float64) string {
+	if days <= 0 {
+		return "no decay"
+	}
+	return fmt.Sprintf("%g days", days)
+}
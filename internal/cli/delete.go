@@ -12,31 +12,47 @@ import (
 
 var (
 	deleteForce bool
+	deleteLabel string
 )
 
 var deleteCmd = &cobra.Command{
-	Use:   "delete <entity>",
+	Use:   "delete [entity]",
 	Short: "Delete an entity from the knowledge base",
 	Long: `Delete an entity from the knowledge base.
 
 This will also delete associated chunks and relations (cascade delete).
-Requires confirmation unless --force is used.
+Requires confirmation unless --force (or --yes) is used.
+
+With --label, all entities with that label are deleted at once instead.
 
 Examples:
   knowhow delete "auth-service"
-  knowhow delete "old-notes" --force`,
-	Args: cobra.ExactArgs(1),
+  knowhow delete "old-notes" --force
+  knowhow delete --label "scratch" --yes`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runDelete,
 }
 
 func init() {
 	deleteCmd.Flags().BoolVarP(&deleteForce, "force", "f", false, "skip confirmation")
+	deleteCmd.Flags().BoolVarP(&deleteForce, "yes", "y", false, "skip confirmation (same as --force)")
+	deleteCmd.Flags().StringVar(&deleteLabel, "label", "", "delete all entities with this label")
 }
 
 func runDelete(cmd *cobra.Command, args []string) error {
-	entityRef := args[0]
 	ctx := context.Background()
 
+	if cmd.Flags().Changed("label") {
+		if len(args) > 0 {
+			return fmt.Errorf("give either an entity or --label, not both")
+		}
+		return runDeleteByLabel(ctx, deleteLabel)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("entity or --label required")
+	}
+	entityRef := args[0]
+
 	// Find entity
 	entity, err := gqlClient.GetEntity(ctx, entityRef)
 	if err != nil {
@@ -55,16 +71,11 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	// Confirm deletion
 	if !deleteForce {
 		fmt.Printf("About to delete: %s (%s)\n", entity.Name, entity.ID)
-		fmt.Print("\nContinue? [y/N]: ")
-
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+		confirmed, err := confirmDelete()
 		if err != nil {
-			return fmt.Errorf("read input: %w", err)
+			return err
 		}
-		response = strings.TrimSpace(strings.ToLower(response))
-
-		if response != "y" && response != "yes" {
+		if !confirmed {
 			fmt.Println("Cancelled.")
 			return nil
 		}
@@ -82,3 +93,56 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Deleted: %s\n", entity.Name)
 	return nil
 }
+
+func runDeleteByLabel(ctx context.Context, label string) error {
+	if strings.TrimSpace(label) == "" {
+		return fmt.Errorf("--label must not be empty")
+	}
+
+	if !deleteForce {
+		labels, err := gqlClient.ListLabels(ctx)
+		if err != nil {
+			return fmt.Errorf("list labels: %w", err)
+		}
+		count := 0
+		for _, l := range labels {
+			if l.Label == label {
+				count = l.Count
+			}
+		}
+		if count == 0 {
+			fmt.Printf("No entities labeled %q.\n", label)
+			return nil
+		}
+
+		fmt.Printf("About to delete %d entities labeled %q\n", count, label)
+		confirmed, err := confirmDelete()
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	deleted, err := gqlClient.DeleteEntitiesByLabel(ctx, label)
+	if err != nil {
+		return fmt.Errorf("delete entities by label: %w", err)
+	}
+	fmt.Printf("Deleted %d entities labeled %q\n", deleted, label)
+	return nil
+}
+
+// confirmDelete asks the user to confirm a deletion on stdin.
+func confirmDelete() (bool, error) {
+	fmt.Print("\nContinue? [y/N]: ")
+
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("read input: %w", err)
+	}
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
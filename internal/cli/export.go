@@ -2,7 +2,9 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -15,23 +17,35 @@ var (
 	exportLabels   []string
 	exportVerified bool
 	exportEntity   string
+	exportOut      string
 )
 
 var exportCmd = &cobra.Command{
-	Use:   "export <path>",
-	Short: "Export knowledge base to Markdown files",
+	Use:   "export [path]",
+	Short: "Export knowledge base to Markdown files or an archive",
 	Long: `Export the knowledge base to Markdown files for backup or migration.
 
 Creates a directory structure with entities organized by type,
 preserving all metadata in frontmatter.
 
+With --out, the whole vault (entities, chunks, relations, contradictions and
+templates, embeddings included) is written to a single newline-delimited JSON
+archive instead, for a lossless backup. Filters don't apply to archives.
+
 Examples:
   knowhow export ./backup
   knowhow export ./backup --type document
   knowhow export ./backup --labels "work,banking"
   knowhow export ./backup --verified-only
-  knowhow export ./backup --entity "auth-service"`,
-	Args: cobra.ExactArgs(1),
+  knowhow export ./backup --entity "auth-service"
+  knowhow export --out vault.ndjson
+  knowhow export --out - | gzip > vault.ndjson.gz`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("out") {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: runExport,
 }
 
@@ -40,9 +54,18 @@ func init() {
 	exportCmd.Flags().StringSliceVarP(&exportLabels, "labels", "l", nil, "export entities with these labels")
 	exportCmd.Flags().BoolVar(&exportVerified, "verified-only", false, "export only verified entities")
 	exportCmd.Flags().StringVar(&exportEntity, "entity", "", "export specific entity")
+	exportCmd.Flags().StringVarP(&exportOut, "out", "o", "", "write the whole vault to this NDJSON archive (- for stdout)")
+	exportCmd.MarkFlagsMutuallyExclusive("out", "type")
+	exportCmd.MarkFlagsMutuallyExclusive("out", "labels")
+	exportCmd.MarkFlagsMutuallyExclusive("out", "verified-only")
+	exportCmd.MarkFlagsMutuallyExclusive("out", "entity")
 }
 
 func runExport(cmd *cobra.Command, args []string) error {
+	if exportOut != "" {
+		return runExportArchive(context.Background(), exportOut)
+	}
+
 	exportPath := args[0]
 	ctx := context.Background()
 
@@ -151,3 +174,39 @@ updated_at: %s
 	fmt.Printf("\nExported %d entities to %s\n", exported, exportPath)
 	return nil
 }
+
+// runExportArchive downloads the vault archive to out, or stdout for "-".
+// The archive is written to a temporary file first, so a failed export
+// never leaves a truncated archive behind.
+func runExportArchive(ctx context.Context, out string) error {
+	if out == "-" {
+		if _, err := gqlClient.ExportArchive(ctx, os.Stdout); err != nil {
+			return fmt.Errorf("export archive: %w", err)
+		}
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(out), filepath.Base(out)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create archive: %w", err)
+	}
+	defer func() {
+		if err := os.Remove(tmp.Name()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove %s: %v\n", tmp.Name(), err)
+		}
+	}()
+
+	size, err := gqlClient.ExportArchive(ctx, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("export archive: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), out); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+
+	fmt.Printf("Exported vault to %s (%.1f MB)\n", out, float64(size)/(1024*1024))
+	return nil
+}
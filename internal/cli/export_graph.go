@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportGraphRoot  string
+	exportGraphDepth int
+)
+
+var exportGraphCmd = &cobra.Command{
+	Use:   "export-graph [file]",
+	Short: "Export the knowledge graph as JSON",
+	Long: `Export entities and the relations between them as JSON, for visualization
+or sharing. Writes to stdout unless a file is given.
+
+With --root, only the neighborhood of that entity is exported: everything
+reachable within --depth hops, following relations in both directions.
+
+Examples:
+  knowhow export-graph graph.json
+  knowhow export-graph --root "auth-service" --depth 2 > auth.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExportGraph,
+}
+
+func init() {
+	exportGraphCmd.Flags().StringVar(&exportGraphRoot, "root", "", "export only the neighborhood of this entity (ID or name)")
+	exportGraphCmd.Flags().IntVarP(&exportGraphDepth, "depth", "d", 2, "maximum hops from the root")
+	rootCmd.AddCommand(exportGraphCmd)
+}
+
+func runExportGraph(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	rootID := ""
+	if exportGraphRoot != "" {
+		root, err := resolveEntity(ctx, exportGraphRoot)
+		if err != nil {
+			return fmt.Errorf("root entity: %w", err)
+		}
+		rootID = root.ID
+	}
+
+	graph, err := gqlClient.ExportGraph(ctx, rootID, exportGraphDepth)
+	if err != nil {
+		return fmt.Errorf("export graph: %w", err)
+	}
+
+	data, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode graph: %w", err)
+	}
+
+	if len(args) == 0 {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if err := os.WriteFile(args[0], append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", args[0], err)
+	}
+	fmt.Printf("Exported %d entities and %d relations to %s\n", len(graph.Entities), len(graph.Relations), args[0])
+	return nil
+}
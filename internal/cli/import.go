@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var importOnConflict string
+
+var importCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Import a vault archive written by export --out",
+	Long: `Import a newline-delimited JSON archive written by "knowhow export --out",
+keeping record IDs and embeddings (nothing is re-embedded). The archive's
+embedding dimension must match the server's. Use - to read from stdin.
+
+Records that already exist are kept (--on-conflict skip) or replaced
+(--on-conflict overwrite). Relations are imported last and skipped if an
+endpoint entity doesn't exist.
+
+Examples:
+  knowhow import vault.ndjson
+  knowhow import vault.ndjson --on-conflict overwrite
+  gunzip -c vault.ndjson.gz | knowhow import -`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importOnConflict, "on-conflict", "skip", "existing records: skip or overwrite")
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	if importOnConflict != "skip" && importOnConflict != "overwrite" {
+		return fmt.Errorf("invalid --on-conflict %q (use skip or overwrite)", importOnConflict)
+	}
+
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("open archive: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	stats, err := gqlClient.ImportArchive(context.Background(), r, importOnConflict)
+	if err != nil {
+		return fmt.Errorf("import archive: %w", err)
+	}
+
+	fmt.Printf("Imported %d records, skipped %d\n", stats.Imported, stats.Skipped)
+	return nil
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var importRelationsCmd = &cobra.Command{
+	Use:   "import-relations <file>",
+	Short: "Create relations from a CSV edge list",
+	Long: `Create relations from a CSV edge list with columns from,to,type[,strength].
+
+Endpoints are entity names (case-insensitive) or IDs. A header row starting
+with "from,to" and lines starting with # are ignored. Rows whose endpoints
+don't match an entity are skipped and reported. Use - to read from stdin.
+
+Examples:
+  knowhow import-relations edges.csv
+  cat edges.csv | knowhow import-relations -`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportRelations,
+}
+
+func init() {
+	rootCmd.AddCommand(importRelationsCmd)
+}
+
+func runImportRelations(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("read edge list: %w", err)
+	}
+
+	report, err := gqlClient.ImportRelations(ctx, string(data))
+	if err != nil {
+		return fmt.Errorf("import relations: %w", err)
+	}
+
+	fmt.Printf("Imported %d of %d relations\n", report.Created, report.Rows)
+
+	if len(report.Unresolved) > 0 {
+		fmt.Printf("\nUnresolved endpoints (%d):\n", len(report.Unresolved))
+		for _, ref := range report.Unresolved {
+			fmt.Printf("  - %s\n", ref)
+		}
+	}
+
+	if len(report.Skipped) > 0 {
+		fmt.Printf("\nSkipped rows (%d):\n", len(report.Skipped))
+		for _, row := range report.Skipped {
+			fmt.Printf("  line %d: %s\n", row.Line, row.Reason)
+		}
+	}
+
+	return nil
+}
@@ -3,27 +3,109 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
+	"github.com/raphaelgruber/memcp-go/internal/client"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	jobsStatus string
+	jobsLimit  int
+	jobsOffset int
 )
 
 var jobsCmd = &cobra.Command{
 	Use:   "jobs [job-id]",
 	Short: "List or inspect background jobs",
-	Long: `List all background jobs or inspect a specific job by ID.
+	Long: `List background jobs (most recent first) or inspect a specific job by ID.
+
+The list includes finished jobs from previous server runs.
 
 Examples:
-  knowhow jobs           # List all jobs
-  knowhow jobs abc123    # Show details for job abc123`,
+  knowhow jobs                      # List recent jobs
+  knowhow jobs --status failed      # Only failed jobs
+  knowhow jobs --limit 20 --offset 20  # Second page of 20
+  knowhow jobs abc123               # Show details for job abc123
+  knowhow jobs watch abc123         # Follow job abc123 until it finishes
+  knowhow jobs cancel abc123        # Stop job abc123`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runJobs,
 }
 
 func init() {
+	jobsCmd.Flags().StringVar(&jobsStatus, "status", "", "filter by status (pending, running, completed, failed, cancelled)")
+	jobsCmd.Flags().IntVarP(&jobsLimit, "limit", "n", 50, "max results")
+	jobsCmd.Flags().IntVar(&jobsOffset, "offset", 0, "number of jobs to skip")
+	jobsCmd.AddCommand(jobsCancelCmd)
+	jobsCmd.AddCommand(jobsWatchCmd)
 	rootCmd.AddCommand(jobsCmd)
 }
 
+var jobsCancelCmd = &cobra.Command{
+	Use:   "cancel <job-id>",
+	Short: "Stop a pending or running job",
+	Long: `Stop a pending or running job. Files already processed stay ingested and
+the job keeps their result; remaining files are skipped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runJobsCancel,
+}
+
+var jobsWatchCmd = &cobra.Command{
+	Use:   "watch <job-id>",
+	Short: "Follow a job's progress until it finishes",
+	Long: `Follow a job's progress live until it completes, fails or is cancelled.
+Updates are pushed by the server as the job advances. Press Ctrl+C to stop
+watching; the job keeps running.
+
+When output isn't a terminal, one line is printed per update.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runJobsWatch,
+}
+
+func runJobsWatch(cmd *cobra.Command, args []string) error {
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		return RunJobWatch(gqlClient, args[0])
+	}
+
+	var final client.JobProgressEvent
+	err := gqlClient.WatchJobProgress(context.Background(), args[0], func(e client.JobProgressEvent) error {
+		fmt.Printf("%s %s %d/%d\n", e.JobID, e.Status, e.Progress, e.Total)
+		final = e
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("watch job: %w", err)
+	}
+	switch final.Status {
+	case "failed":
+		if final.Error != nil {
+			return fmt.Errorf("job failed: %s", *final.Error)
+		}
+		return fmt.Errorf("job failed")
+	case "cancelled":
+		return fmt.Errorf("job cancelled after %d/%d files", final.Progress, final.Total)
+	}
+	return nil
+}
+
+func runJobsCancel(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cancelled, err := gqlClient.CancelJob(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("cancel job: %w", err)
+	}
+	if !cancelled {
+		return fmt.Errorf("job %s is not pending or running", args[0])
+	}
+
+	fmt.Printf("Cancelled job %s\n", args[0])
+	return nil
+}
+
 func runJobs(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
@@ -37,7 +119,15 @@ func runJobs(cmd *cobra.Command, args []string) error {
 }
 
 func listJobs(ctx context.Context) error {
-	jobs, err := gqlClient.ListJobs(ctx)
+	opts := client.ListJobsOptions{
+		Limit:  &jobsLimit,
+		Offset: &jobsOffset,
+	}
+	if jobsStatus != "" {
+		opts.Status = &jobsStatus
+	}
+
+	jobs, err := gqlClient.ListJobs(ctx, opts)
 	if err != nil {
 		return fmt.Errorf("list jobs: %w", err)
 	}
@@ -55,7 +145,7 @@ func listJobs(ctx context.Context) error {
 		if job.Total > 0 {
 			progress = fmt.Sprintf("%d/%d", job.Progress, job.Total)
 		}
-		started := job.StartedAt.Format("15:04:05")
+		started := job.StartedAt.Format("2006-01-02 15:04:05")
 		fmt.Printf("%-10s %-10s %-12s %-10s %s\n", job.ID, job.Type, job.Status, progress, started)
 	}
 
@@ -93,9 +183,15 @@ func showJob(ctx context.Context, id string) error {
 		fmt.Printf("  Files processed: %d\n", job.Result.FilesProcessed)
 		fmt.Printf("  Entities created: %d\n", job.Result.EntitiesCreated)
 		fmt.Printf("  Chunks created: %d\n", job.Result.ChunksCreated)
+		if job.Result.ChunksFailed > 0 {
+			fmt.Printf("  Chunks failed: %d (re-ingest to retry)\n", job.Result.ChunksFailed)
+		}
 		if job.Result.RelationsCreated > 0 {
 			fmt.Printf("  Relations created: %d\n", job.Result.RelationsCreated)
 		}
+		if job.Result.EntitiesDeleted > 0 {
+			fmt.Printf("  Entities deleted: %d\n", job.Result.EntitiesDeleted)
+		}
 		if len(job.Result.Errors) > 0 {
 			fmt.Printf("\n  Errors (%d):\n", len(job.Result.Errors))
 			for _, e := range job.Result.Errors {
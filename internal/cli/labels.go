@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var labelsCmd = &cobra.Command{
+	Use:   "labels",
+	Short: "Manage labels across all entities",
+	Long: `Manage labels across all entities.
+
+Subcommands:
+  rename  Rename a label on all entities
+
+Use 'knowhow list labels' to list labels with counts.
+
+Examples:
+  knowhow labels rename k8s kubernetes`,
+}
+
+var labelsRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a label on all entities",
+	Long: `Replace a label with another on all entities and their chunks. Entities
+that already have the new label keep it once. The rename runs in a single
+transaction: it applies to all entities or none.
+
+Examples:
+  knowhow labels rename k8s kubernetes
+  knowhow labels rename "work stuff" work`,
+	Args: cobra.ExactArgs(2),
+	RunE: runLabelsRename,
+}
+
+func init() {
+	labelsCmd.AddCommand(labelsRenameCmd)
+	rootCmd.AddCommand(labelsCmd)
+}
+
+func runLabelsRename(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	renamed, err := gqlClient.RenameLabel(ctx, args[0], args[1])
+	if err != nil {
+		return fmt.Errorf("rename label: %w", err)
+	}
+
+	fmt.Printf("Renamed %q to %q on %d entities\n", args[0], args[1], renamed)
+	return nil
+}
@@ -9,9 +9,11 @@ import (
 )
 
 var (
-	listType   string
-	listLabels []string
-	listLimit  int
+	listType        string
+	listLabels      []string
+	listHasMetadata []string
+	listContext     string
+	listLimit       int
 )
 
 var listCmd = &cobra.Command{
@@ -28,6 +30,8 @@ Examples:
   knowhow list
   knowhow list --type person
   knowhow list --labels "work,banking"
+  knowhow list --has-metadata jira_ticket
+  knowhow list --context payments-api
   knowhow list labels
   knowhow list types`,
 	RunE: runList,
@@ -54,10 +58,14 @@ var listTypesCmd = &cobra.Command{
 func init() {
 	listCmd.Flags().StringVarP(&listType, "type", "t", "", "filter by entity type")
 	listCmd.Flags().StringSliceVarP(&listLabels, "labels", "l", nil, "filter by labels")
+	listCmd.Flags().StringSliceVar(&listHasMetadata, "has-metadata", nil, "only entities with these metadata keys set")
+	listCmd.Flags().StringVar(&listContext, "context", "", "only entities in this project context")
 	listCmd.Flags().IntVarP(&listLimit, "limit", "n", 50, "max results")
 
 	listEntitiesCmd.Flags().StringVarP(&listType, "type", "t", "", "filter by entity type")
 	listEntitiesCmd.Flags().StringSliceVarP(&listLabels, "labels", "l", nil, "filter by labels")
+	listEntitiesCmd.Flags().StringSliceVar(&listHasMetadata, "has-metadata", nil, "only entities with these metadata keys set")
+	listEntitiesCmd.Flags().StringVar(&listContext, "context", "", "only entities in this project context")
 	listEntitiesCmd.Flags().IntVarP(&listLimit, "limit", "n", 50, "max results")
 
 	listCmd.AddCommand(listEntitiesCmd)
@@ -74,8 +82,10 @@ func runListEntities(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
 	opts := client.ListEntitiesOptions{
-		Labels: listLabels,
-		Limit:  &listLimit,
+		Labels:          listLabels,
+		HasMetadataKeys: listHasMetadata,
+		Context:         listContext,
+		Limit:           &listLimit,
 	}
 	if listType != "" {
 		opts.Type = &listType
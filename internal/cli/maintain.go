@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/raphaelgruber/memcp-go/internal/client"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var maintainCmd = &cobra.Command{
+	Use:   "maintain",
+	Short: "Run maintenance tasks on the knowledge base",
+	Long: `Run maintenance tasks on the knowledge base.
+
+Subcommands:
+  contradictions  Detect entities making contradictory claims
+  dedupe          Find near-duplicate entities and optionally merge them
+
+Examples:
+  knowhow maintain contradictions
+  knowhow maintain contradictions --entity auth-service
+  knowhow maintain dedupe --threshold 0.95 --auto-merge`,
+}
+
+var maintainContradictionsCmd = &cobra.Command{
+	Use:   "contradictions",
+	Short: "Detect entities making contradictory claims",
+	Long: `Compare each verified entity with its most similar entities and let the
+LLM find contradictory factual claims. Each contradiction is recorded with an
+explanation and confidence; pairs with a recorded contradiction, including
+resolved ones, are skipped. The server log lists each contradiction.
+
+The scan runs as a background job; on a terminal its progress is shown,
+otherwise the job ID is printed (see 'knowhow jobs').
+
+With --entity, only that entity is compared, whether verified or not.
+
+Examples:
+  knowhow maintain contradictions
+  knowhow maintain contradictions --entity auth-service`,
+	Args: cobra.NoArgs,
+	RunE: runMaintainContradictions,
+}
+
+var maintainDedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Find near-duplicate entities and optionally merge them",
+	Long: `Find pairs of entities whose embeddings have a cosine similarity of at
+least --threshold and print them as a review report. Chunked entities are
+compared by their chunks. Entities are checked 100 per request.
+
+With --auto-merge, each pair is merged into the entity with the higher
+confidence, then the more accessed one. Only entities of the same type are
+merged; pairs where both entities are verified need human judgment and are
+skipped.
+
+Examples:
+  knowhow maintain dedupe
+  knowhow maintain dedupe --threshold 0.9
+  knowhow maintain dedupe --threshold 0.95 --auto-merge`,
+	Args: cobra.NoArgs,
+	RunE: runMaintainDedupe,
+}
+
+// dedupePageSize is the number of entities checked per dedupe request.
+const dedupePageSize = 100
+
+var (
+	maintainEntity  string
+	dedupeThreshold float64
+	dedupeAutoMerge bool
+)
+
+func init() {
+	maintainContradictionsCmd.Flags().StringVar(&maintainEntity, "entity", "", "only compare this entity ID")
+
+	maintainDedupeCmd.Flags().Float64Var(&dedupeThreshold, "threshold", 0.95, "minimum embedding similarity (0-1]")
+	maintainDedupeCmd.Flags().BoolVar(&dedupeAutoMerge, "auto-merge", false, "merge pairs that are safe to merge")
+
+	maintainCmd.AddCommand(maintainContradictionsCmd)
+	maintainCmd.AddCommand(maintainDedupeCmd)
+	rootCmd.AddCommand(maintainCmd)
+}
+
+func runMaintainContradictions(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if maintainEntity != "" {
+		created, err := gqlClient.DetectContradictions(ctx, maintainEntity)
+		if err != nil {
+			return fmt.Errorf("detect contradictions: %w", err)
+		}
+		fmt.Printf("Recorded %d contradictions for %s\n", created, maintainEntity)
+		return nil
+	}
+
+	job, err := gqlClient.DetectAllContradictions(ctx)
+	if err != nil {
+		return fmt.Errorf("start contradictions job: %w", err)
+	}
+
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Printf("Started job %s\n", job.ID)
+		fmt.Printf("  Use 'knowhow jobs %s' to check progress\n", job.ID)
+		return nil
+	}
+
+	return RunJobProgress(gqlClient, job)
+}
+
+func runMaintainDedupe(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	// Check the entities page by page; a pair spanning two pages is
+	// reported on both, so print it once
+	var pairs []client.DuplicatePair
+	var merged, skipped int
+	seen := make(map[[2]string]bool)
+	cursor := ""
+	for {
+		report, err := gqlClient.DedupeEntities(ctx, dedupeThreshold, dedupeAutoMerge, cursor, dedupePageSize)
+		if err != nil {
+			return fmt.Errorf("dedupe: %w", err)
+		}
+		for _, p := range report.Pairs {
+			key := [2]string{p.Keep.ID, p.Merge.ID}
+			if p.Merge.ID < p.Keep.ID {
+				key = [2]string{p.Merge.ID, p.Keep.ID}
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			pairs = append(pairs, p)
+			switch {
+			case p.Merged:
+				merged++
+			case p.SkipReason != nil || p.Error != nil:
+				skipped++
+			}
+		}
+		if report.NextCursor == nil {
+			break
+		}
+		cursor = *report.NextCursor
+	}
+
+	if len(pairs) == 0 {
+		fmt.Printf("No pairs with similarity >= %.2f\n", dedupeThreshold)
+		return nil
+	}
+
+	for _, p := range pairs {
+		status := "candidate"
+		switch {
+		case p.Merged:
+			status = "merged"
+		case p.Error != nil:
+			status = "failed: " + *p.Error
+		case p.SkipReason != nil:
+			status = "skipped: " + *p.SkipReason
+		}
+		fmt.Printf("%.3f  %s (%s) <- %s (%s)  [%s]\n",
+			p.Similarity, p.Keep.Name, p.Keep.ID, p.Merge.Name, p.Merge.ID, status)
+	}
+
+	fmt.Println()
+	if dedupeAutoMerge {
+		fmt.Printf("%d pairs: %d merged, %d skipped\n", len(pairs), merged, skipped)
+	} else {
+		fmt.Printf("%d pairs, %d need review (run with --auto-merge to merge the rest)\n", len(pairs), skipped)
+	}
+	return nil
+}
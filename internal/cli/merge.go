@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	mergeForce bool
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <keep> <duplicate>",
+	Short: "Merge a duplicate entity into another",
+	Long: `Merge a duplicate entity into another and delete the duplicate.
+
+Relations, contradictions and chunks of the duplicate move to the kept
+entity; a relation the kept entity already has keeps the higher strength.
+Labels are combined and the higher confidence is kept.
+Requires confirmation unless --force is used.
+
+Examples:
+  knowhow merge "auth-service" "authentication-service"
+  knowhow merge "kubernetes" "k8s" --force`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMerge,
+}
+
+func init() {
+	mergeCmd.Flags().BoolVarP(&mergeForce, "force", "f", false, "skip confirmation")
+	rootCmd.AddCommand(mergeCmd)
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	keep, err := resolveEntity(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("entity to keep: %w", err)
+	}
+	duplicate, err := resolveEntity(ctx, args[1])
+	if err != nil {
+		return fmt.Errorf("duplicate entity: %w", err)
+	}
+	if keep.ID == duplicate.ID {
+		return fmt.Errorf("cannot merge %s into itself", keep.Name)
+	}
+
+	if !mergeForce {
+		fmt.Printf("About to merge %s (%s) into %s (%s) and delete %s\n", duplicate.Name, duplicate.ID, keep.Name, keep.ID, duplicate.ID)
+		fmt.Print("\nContinue? [y/N]: ")
+
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("read input: %w", err)
+		}
+		response = strings.TrimSpace(strings.ToLower(response))
+
+		if response != "y" && response != "yes" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	merged, err := gqlClient.MergeEntities(ctx, keep.ID, duplicate.ID)
+	if err != nil {
+		return fmt.Errorf("merge entities: %w", err)
+	}
+
+	fmt.Printf("Merged %s into %s (labels: %s, confidence %.2f)\n", duplicate.Name, merged.Name, strings.Join(merged.Labels, ", "), merged.Confidence)
+	return nil
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var normalizeLabelsCmd = &cobra.Command{
+	Use:   "normalize-labels",
+	Short: "Normalize the labels of existing entities",
+	Long: `Apply the server's label normalization to all existing entities: labels are
+trimmed, lowercased and aliases replaced by their canonical label, so
+near-duplicates like "K8s" and "kubernetes" collapse into one.
+
+New labels are normalized on write once the server runs with
+KNOWHOW_NORMALIZE_LABELS=true; aliases come from KNOWHOW_LABEL_ALIASES.
+
+Examples:
+  knowhow normalize-labels`,
+	Args: cobra.NoArgs,
+	RunE: runNormalizeLabels,
+}
+
+func init() {
+	rootCmd.AddCommand(normalizeLabelsCmd)
+}
+
+func runNormalizeLabels(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	updated, err := gqlClient.NormalizeLabels(ctx)
+	if err != nil {
+		return fmt.Errorf("normalize labels: %w", err)
+	}
+
+	fmt.Printf("Normalized labels of %d entities\n", updated)
+	return nil
+}
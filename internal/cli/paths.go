@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/raphaelgruber/memcp-go/internal/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pathsMaxDepth int
+	pathsLimit    int
+)
+
+var pathsCmd = &cobra.Command{
+	Use:   "paths <from> <to>",
+	Short: "Show how two entities are connected",
+	Long: `Find paths between two entities through their relations.
+
+Paths are listed shortest first. Relations are followed in both directions;
+a reversed hop is shown with <-[type]-.
+
+Examples:
+  knowhow paths "john-doe" "billing-service"
+  knowhow paths "auth-service" "kubernetes" --depth 3 --limit 5`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPaths,
+}
+
+func init() {
+	pathsCmd.Flags().IntVarP(&pathsMaxDepth, "depth", "d", 4, "maximum number of hops per path")
+	pathsCmd.Flags().IntVarP(&pathsLimit, "limit", "n", 10, "maximum number of paths")
+	rootCmd.AddCommand(pathsCmd)
+}
+
+func runPaths(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	from, err := resolveEntity(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("source entity: %w", err)
+	}
+	to, err := resolveEntity(ctx, args[1])
+	if err != nil {
+		return fmt.Errorf("target entity: %w", err)
+	}
+
+	paths, err := gqlClient.FindPaths(ctx, from.ID, to.ID, pathsMaxDepth, pathsLimit)
+	if err != nil {
+		return fmt.Errorf("find paths: %w", err)
+	}
+
+	if len(paths) == 0 {
+		fmt.Printf("No paths found between %s and %s within %d hops.\n", from.Name, to.Name, pathsMaxDepth)
+		return nil
+	}
+
+	fmt.Printf("Found %d paths:\n\n", len(paths))
+	for i, path := range paths {
+		line := path[0].FromID
+		for _, step := range path {
+			if step.Reverse {
+				line += fmt.Sprintf(" <-[%s]- %s", step.RelType, step.ToID)
+			} else {
+				line += fmt.Sprintf(" -[%s]-> %s", step.RelType, step.ToID)
+			}
+		}
+		fmt.Printf("%d. %s\n", i+1, line)
+	}
+
+	return nil
+}
+
+// resolveEntity looks up an entity by ID, falling back to name.
+func resolveEntity(ctx context.Context, ref string) (*client.Entity, error) {
+	entity, err := gqlClient.GetEntity(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if entity != nil {
+		return entity, nil
+	}
+
+	entity, err = gqlClient.GetEntityByName(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("not found: %s", ref)
+	}
+	return entity, nil
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"charm.land/bubbles/v2/progress"
@@ -67,6 +68,9 @@ type progressModel struct {
 	done     bool
 	quitting bool
 	err      error
+
+	// updates delivers pushed job states; nil polls the server instead
+	updates <-chan jobUpdateMsg
 }
 
 // newProgressModel creates a new progress model.
@@ -86,14 +90,29 @@ func newProgressModel(c *client.Client, job *client.Job) progressModel {
 	}
 }
 
-// Init returns the initial command (start polling).
+// Init returns the initial command (start polling or waiting for updates).
 func (m progressModel) Init() tea.Cmd {
 	return tea.Batch(
-		tickCmd(),
+		m.nextUpdate(),
 		m.progress.Init(),
 	)
 }
 
+// nextUpdate returns the command delivering the next job state: the next
+// pushed update if subscribed, otherwise a poll after the poll interval.
+func (m progressModel) nextUpdate() tea.Cmd {
+	if m.updates == nil {
+		return tickCmd()
+	}
+	return func() tea.Msg {
+		msg, ok := <-m.updates
+		if !ok {
+			return jobUpdateMsg{err: fmt.Errorf("job progress stream ended before the job finished")}
+		}
+		return msg
+	}
+}
+
 // Update handles messages and returns the updated model.
 func (m progressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -130,10 +149,14 @@ func (m progressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.err = fmt.Errorf("job failed with unknown error")
 			}
 			return m, tea.Quit
+		case "cancelled":
+			m.done = true
+			m.err = fmt.Errorf("job cancelled after %d/%d files", m.job.Progress, m.job.Total)
+			return m, tea.Quit
 		}
 
-		// Continue polling for running jobs
-		return m, tickCmd()
+		// Continue polling (or waiting for updates) for running jobs
+		return m, m.nextUpdate()
 
 	case progress.FrameMsg:
 		// Update progress bar animation
@@ -199,9 +222,15 @@ func (m progressModel) finalView() string {
 		output += fmt.Sprintf("  Files processed:   %d\n", r.FilesProcessed)
 		output += fmt.Sprintf("  Entities created:  %d\n", r.EntitiesCreated)
 		output += fmt.Sprintf("  Chunks created:    %d\n", r.ChunksCreated)
+		if r.ChunksFailed > 0 {
+			output += fmt.Sprintf("  Chunks failed:     %d (re-ingest to retry)\n", r.ChunksFailed)
+		}
 		if r.RelationsCreated > 0 {
 			output += fmt.Sprintf("  Relations created: %d\n", r.RelationsCreated)
 		}
+		if r.EntitiesDeleted > 0 {
+			output += fmt.Sprintf("  Entities deleted:  %d\n", r.EntitiesDeleted)
+		}
 		if len(r.Errors) > 0 {
 			output += m.theme.errorStyle().Render(fmt.Sprintf("\nWarnings (%d):\n", len(r.Errors)))
 			for _, e := range r.Errors {
@@ -266,3 +295,64 @@ func RunJobProgress(c *client.Client, job *client.Job) error {
 
 	return nil
 }
+
+// RunJobWatch runs the interactive progress UI for a job by ID, updated by
+// the server's jobProgress subscription instead of polling.
+// Returns nil on success or Ctrl+C, error on job failure or unknown job.
+func RunJobWatch(c *client.Client, jobID string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := make(chan jobUpdateMsg)
+	go func() {
+		defer close(updates)
+		send := func(msg jobUpdateMsg) bool {
+			select {
+			case updates <- msg:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		job := client.Job{ID: jobID}
+		err := c.WatchJobProgress(ctx, jobID, func(e client.JobProgressEvent) error {
+			job.Progress, job.Total, job.Status, job.Error = e.Progress, e.Total, e.Status, e.Error
+			update := job
+			if isFinishedJobStatus(e.Status) {
+				// The final state carries no result; fetch the whole job for it
+				if full, err := c.GetJob(ctx, jobID); err != nil {
+					slog.Debug("failed to get finished job", "job_id", jobID, "error", err)
+				} else if full != nil {
+					update = *full
+				}
+			}
+			if !send(jobUpdateMsg{job: &update}) {
+				return ctx.Err()
+			}
+			return nil
+		})
+		if err != nil && ctx.Err() == nil {
+			send(jobUpdateMsg{err: err})
+		}
+	}()
+
+	model := newProgressModel(c, &client.Job{ID: jobID})
+	model.job = nil // Show loading until the first update
+	model.updates = updates
+	p := tea.NewProgram(model)
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("progress UI error: %w", err)
+	}
+	if m, ok := finalModel.(progressModel); ok && !m.quitting && m.err != nil {
+		return m.err
+	}
+	return nil
+}
+
+// isFinishedJobStatus reports whether a job with status has finished.
+func isFinishedJobStatus(status string) bool {
+	return status == "completed" || status == "failed" || status == "cancelled"
+}
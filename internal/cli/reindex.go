@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex <entity>",
+	Short: "Regenerate an entity's embedding and chunks",
+	Long: `Regenerate an entity's embedding and chunks from its current content.
+
+Updates made through knowhow re-index automatically. Use this after content
+was changed outside of knowhow or when search results for an entity look stale.
+
+Examples:
+  knowhow reindex "auth-service"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReindex,
+}
+
+func init() {
+	rootCmd.AddCommand(reindexCmd)
+}
+
+func runReindex(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	entity, err := resolveEntity(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("get entity: %w", err)
+	}
+
+	if _, err := gqlClient.ReindexEntity(ctx, entity.ID); err != nil {
+		return fmt.Errorf("reindex: %w", err)
+	}
+
+	fmt.Printf("Reindexed: %s (%s)\n", entity.Name, entity.ID)
+	return nil
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var relinkCmd = &cobra.Command{
+	Use:   "relink",
+	Short: "Repair relations to entities that no longer exist",
+	Long: `Repair relations whose source or target entity no longer exists, e.g. after
+an entity was renamed or merged under a new ID. The missing endpoint is
+re-resolved to the entity whose name matches its old ID; relations that
+can't be resolved are removed. The server log lists each change.
+
+Examples:
+  knowhow relink`,
+	Args: cobra.NoArgs,
+	RunE: runRelink,
+}
+
+func init() {
+	rootCmd.AddCommand(relinkCmd)
+}
+
+func runRelink(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	changed, err := gqlClient.RelinkRelations(ctx)
+	if err != nil {
+		return fmt.Errorf("relink relations: %w", err)
+	}
+
+	fmt.Printf("Repaired or removed %d dangling relations\n", changed)
+	return nil
+}
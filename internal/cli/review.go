@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/raphaelgruber/memcp-go/internal/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reviewPriority string
+	reviewSources  []string
+	reviewTypes    []string
+	reviewLimit    int
+	reviewOffset   int
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "List unverified entities to review",
+	Long: `List unverified entities in review order, e.g. to triage knowledge
+extracted by the LLM.
+
+Priorities:
+  confidence  Lowest confidence first (default)
+  access      Most accessed first
+
+Subcommands:
+  verify  Mark reviewed entities as verified
+
+Examples:
+  knowhow review
+  knowhow review --source ai_generated --limit 20
+  knowhow review --priority access --type service
+  knowhow review verify "auth-service" "billing-service"`,
+	RunE: runReview,
+}
+
+var reviewVerifyCmd = &cobra.Command{
+	Use:   "verify <entity>...",
+	Short: "Mark entities as verified",
+	Long:  `Mark entities (by ID or name) as verified, removing them from the review queue.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runReviewVerify,
+}
+
+func init() {
+	reviewCmd.Flags().StringVarP(&reviewPriority, "priority", "p", "confidence", "review order: confidence or access")
+	reviewCmd.Flags().StringSliceVarP(&reviewSources, "source", "s", nil, "only entities from these sources (manual, mcp, scrape, ai_generated)")
+	reviewCmd.Flags().StringSliceVarP(&reviewTypes, "type", "t", nil, "only entities of these types")
+	reviewCmd.Flags().IntVarP(&reviewLimit, "limit", "n", 50, "max results")
+	reviewCmd.Flags().IntVar(&reviewOffset, "offset", 0, "entities to skip (for paging)")
+
+	reviewCmd.AddCommand(reviewVerifyCmd)
+	rootCmd.AddCommand(reviewCmd)
+}
+
+func runReview(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	entities, err := gqlClient.GetReviewQueue(ctx, client.ReviewQueueOptions{
+		Priority: reviewPriority,
+		Sources:  reviewSources,
+		Types:    reviewTypes,
+		Limit:    reviewLimit,
+		Offset:   reviewOffset,
+	})
+	if err != nil {
+		return fmt.Errorf("get review queue: %w", err)
+	}
+
+	if len(entities) == 0 {
+		fmt.Println("Nothing to review.")
+		return nil
+	}
+
+	fmt.Printf("Review queue (%d):\n\n", len(entities))
+	for i, entity := range entities {
+		fmt.Printf("%d. %s [%s] (id: %s)\n", reviewOffset+i+1, entity.Name, entity.Type, entity.ID)
+		fmt.Printf("   confidence %.2f, source %s, accessed %d times\n", entity.Confidence, entity.Source, entity.AccessCount)
+		if verbose && entity.Summary != nil && *entity.Summary != "" {
+			fmt.Printf("   %s\n", *entity.Summary)
+		}
+	}
+
+	if len(entities) == reviewLimit {
+		fmt.Printf("\nMore with --offset %d\n", reviewOffset+reviewLimit)
+	}
+	return nil
+}
+
+func runReviewVerify(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	ids := make([]string, 0, len(args))
+	for _, ref := range args {
+		entity, err := resolveEntity(ctx, ref)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, entity.ID)
+	}
+
+	changed, err := gqlClient.VerifyEntities(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("verify entities: %w", err)
+	}
+
+	fmt.Printf("Verified %d entities (%d already verified)\n", changed, len(ids)-changed)
+	return nil
+}
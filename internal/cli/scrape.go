@@ -7,26 +7,38 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/raphaelgruber/memcp-go/internal/client"
+	"github.com/raphaelgruber/memcp-go/internal/parser"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
 
 var (
-	scrapeName         string
-	scrapeExtractGraph bool
-	scrapeLabels       []string
-	scrapeDryRun       bool
-	scrapeRecursive    bool
-	scrapeSync         bool
-	scrapeForce        bool
+	scrapeName          string
+	scrapeExtractGraph  bool
+	scrapeAutoSummarize bool
+	scrapeLabels        []string
+	scrapeDryRun        bool
+	scrapeRecursive     bool
+	scrapeSync          bool
+	scrapeForce         bool
+	scrapeDiff          bool
+	scrapeFailFast      bool
+	scrapePrune         bool
+	scrapeChunkStrategy string
+	scrapeChunkSize     int
+	scrapeChunkOverlap  int
+	scrapeConcurrency   int
+	scrapeURL           string
 )
 
 var scrapeCmd = &cobra.Command{
-	Use:   "scrape <path>",
-	Short: "Ingest Markdown files from a directory",
+	Use:     "scrape <path>",
+	Aliases: []string{"ingest"},
+	Short:   "Ingest Markdown files from a directory or a web page",
 	Long: `Scrape and ingest Markdown files from a directory into the knowledge base.
 
 Files are parsed for frontmatter metadata, content is chunked if long,
@@ -36,34 +48,99 @@ By default, unchanged files are skipped based on content hash comparison.
 Use --force to re-ingest all files regardless of changes.
 
 Use --extract-graph to also extract entity relationships using LLM.
+Use --auto-summarize to generate summaries for long files without one.
 Use --name to give the job a name for easy identification and rerunning.
 Use --labels to apply curated labels to all ingested entities.
+Use --fail-fast to stop at the first file that fails instead of collecting
+errors and continuing; the job fails with that file's error.
+
+A .knowhow.yaml in the scraped directory or any subdirectory sets defaults
+for the files below it (nested files cascade, nearest wins):
+
+  type: runbook
+  labels: [ops, oncall]
+  extract_graph: true
+  auto_summarize: false
+
+Frontmatter type overrides the default type, --labels are added to the
+configured labels, and --extract-graph/--auto-summarize enable the feature
+regardless of the config. Unchanged files are skipped even if .knowhow.yaml
+changed; use --force to re-apply it.
+
+Use --prune to delete entities scraped from this directory whose file no
+longer exists (only entities created by scrape, never manual ones). The
+server checks the files on its disk, so --prune requires --sync or --force;
+with --dry-run it only reports how many entities would be deleted.
+
+Use --chunk-strategy to change how long files are split for search:
+heading (default) splits at section headings, fixed into --chunk-size
+windows repeating --chunk-overlap characters, and sentence packs whole
+sentences up to --chunk-size. Chunking changes only apply to changed files;
+use --force to re-chunk everything. Try settings with 'knowhow chunks preview'.
+
+Use --diff to compare the files with the entities a previous scrape created
+without ingesting anything: files are reported as new, changed (with the
+name, type and summary the update would produce and a line count of content
+changes) or unchanged.
+
+Use --url instead of a path to ingest a web page: the server fetches it,
+extracts the main content as Markdown (navigation, ads and other page chrome
+stripped) and stores it with the URL as source path. Re-ingesting an
+unchanged page is a no-op. --labels, --extract-graph, --auto-summarize,
+--dry-run and the chunking flags apply.
 
 Examples:
   knowhow scrape ./docs
   knowhow scrape ./notes --labels "personal"
   knowhow scrape ./specs --extract-graph
+  knowhow scrape ./specs --extract-graph --concurrency 1  # gentle on LLM rate limits
+  knowhow scrape ./notes --auto-summarize
   knowhow scrape ./wiki --recursive --dry-run
   knowhow scrape ./docs --force  # re-ingest all files
-  knowhow scrape ./docs --name "my-docs" --labels "docs,important"`,
-	Args: cobra.ExactArgs(1),
+  knowhow scrape ./docs --diff   # preview changes against existing entities
+  knowhow scrape ./docs --sync --prune  # also delete entities of removed files
+  knowhow scrape ./api-docs --force --chunk-strategy sentence --chunk-size 500
+  knowhow scrape ./docs --name "my-docs" --labels "docs,important"
+  knowhow ingest --url https://go.dev/blog/go1.22 --labels go`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runScrape,
 }
 
 func init() {
 	scrapeCmd.Flags().StringVarP(&scrapeName, "name", "n", "", "name for this job (for identification and rerunning)")
 	scrapeCmd.Flags().BoolVar(&scrapeExtractGraph, "extract-graph", false, "extract entity relations using LLM")
+	scrapeCmd.Flags().BoolVar(&scrapeAutoSummarize, "auto-summarize", false, "generate summaries using LLM for long files without one")
 	scrapeCmd.Flags().StringSliceVarP(&scrapeLabels, "labels", "l", nil, "curated labels to apply to all ingested entities")
 	scrapeCmd.Flags().BoolVar(&scrapeDryRun, "dry-run", false, "show what would be ingested without making changes")
 	scrapeCmd.Flags().BoolVarP(&scrapeRecursive, "recursive", "r", true, "recursively process subdirectories")
 	scrapeCmd.Flags().BoolVar(&scrapeSync, "sync", false, "wait for completion (default: run async with hash checking)")
 	scrapeCmd.Flags().BoolVar(&scrapeForce, "force", false, "force re-ingest all files (skip hash checking)")
+	scrapeCmd.Flags().BoolVar(&scrapeFailFast, "fail-fast", false, "fail the job on the first file error instead of continuing")
+	scrapeCmd.Flags().BoolVar(&scrapePrune, "prune", false, "delete entities of files removed from the directory (requires --sync or --force)")
+	scrapeCmd.Flags().StringVar(&scrapeChunkStrategy, "chunk-strategy", "", "split long files by heading (default), fixed size or sentence")
+	scrapeCmd.Flags().IntVar(&scrapeChunkSize, "chunk-size", 0, "chunk size in characters (default: server setting)")
+	scrapeCmd.Flags().IntVar(&scrapeChunkOverlap, "chunk-overlap", 0, "characters repeated between neighboring chunks (default: server setting)")
+	scrapeCmd.Flags().IntVar(&scrapeConcurrency, "concurrency", 0, "files processed in parallel for this ingest (default: server setting, max 32)")
+	scrapeCmd.Flags().BoolVar(&scrapeDiff, "diff", false, "compare files with existing entities without ingesting")
+	scrapeCmd.Flags().StringVar(&scrapeURL, "url", "", "ingest the web page at this URL instead of a directory")
+	scrapeCmd.MarkFlagsMutuallyExclusive("diff", "sync")
+	scrapeCmd.MarkFlagsMutuallyExclusive("diff", "force")
 }
 
 func runScrape(cmd *cobra.Command, args []string) error {
-	path := args[0]
 	ctx := context.Background()
 
+	if scrapeURL != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("pass either a path or --url, not both")
+		}
+		return runScrapeURL(ctx, cmd)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("path required (or --url)")
+	}
+	path := args[0]
+
 	// Verify path exists
 	info, err := os.Stat(path)
 	if err != nil {
@@ -72,16 +149,35 @@ func runScrape(cmd *cobra.Command, args []string) error {
 	if !info.IsDir() {
 		return fmt.Errorf("path must be a directory: %s", path)
 	}
+	if scrapePrune && !scrapeSync && !scrapeForce {
+		return fmt.Errorf("--prune requires --sync or --force (the server checks which files were removed)")
+	}
 
 	opts := &client.IngestOptions{
-		Labels:       scrapeLabels,
-		ExtractGraph: &scrapeExtractGraph,
-		DryRun:       &scrapeDryRun,
-		Recursive:    &scrapeRecursive,
+		Labels:        scrapeLabels,
+		ExtractGraph:  &scrapeExtractGraph,
+		AutoSummarize: &scrapeAutoSummarize,
+		DryRun:        &scrapeDryRun,
+		Recursive:     &scrapeRecursive,
+		FailFast:      &scrapeFailFast,
+		Prune:         &scrapePrune,
 	}
 	if scrapeName != "" {
 		opts.Name = &scrapeName
 	}
+	// Only send chunking settings that were set, so the server's defaults apply otherwise
+	if scrapeChunkStrategy != "" {
+		opts.ChunkStrategy = &scrapeChunkStrategy
+	}
+	if cmd.Flags().Changed("chunk-size") {
+		opts.ChunkSize = &scrapeChunkSize
+	}
+	if cmd.Flags().Changed("chunk-overlap") {
+		opts.ChunkOverlap = &scrapeChunkOverlap
+	}
+	if cmd.Flags().Changed("concurrency") {
+		opts.Concurrency = &scrapeConcurrency
+	}
 
 	// Sync mode with server-side file reading (legacy)
 	if scrapeSync {
@@ -113,6 +209,47 @@ func runScrape(cmd *cobra.Command, args []string) error {
 	return runScrapeWithHashCheck(ctx, path, opts)
 }
 
+// runScrapeURL ingests the web page at --url.
+func runScrapeURL(ctx context.Context, cmd *cobra.Command) error {
+	for _, flag := range []string{"name", "recursive", "sync", "force", "fail-fast", "prune", "diff", "concurrency"} {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s doesn't apply to --url", flag)
+		}
+	}
+
+	opts := &client.IngestOptions{
+		Labels:        scrapeLabels,
+		ExtractGraph:  &scrapeExtractGraph,
+		AutoSummarize: &scrapeAutoSummarize,
+		DryRun:        &scrapeDryRun,
+	}
+	if scrapeChunkStrategy != "" {
+		opts.ChunkStrategy = &scrapeChunkStrategy
+	}
+	if cmd.Flags().Changed("chunk-size") {
+		opts.ChunkSize = &scrapeChunkSize
+	}
+	if cmd.Flags().Changed("chunk-overlap") {
+		opts.ChunkOverlap = &scrapeChunkOverlap
+	}
+
+	entity, err := gqlClient.IngestURL(ctx, scrapeURL, opts)
+	if err != nil {
+		return fmt.Errorf("ingest url: %w", err)
+	}
+
+	if scrapeDryRun {
+		fmt.Printf("Would ingest: %s (%s)\n", entity.Name, entity.Type)
+	} else {
+		fmt.Printf("Ingested: %s (%s)\n", entity.Name, entity.ID)
+	}
+	fmt.Printf("  Source: %s\n", derefOr(entity.SourcePath, scrapeURL))
+	if entity.Content != nil {
+		fmt.Printf("  Content: %d characters\n", len(*entity.Content))
+	}
+	return nil
+}
+
 // runScrapeWithHashCheck implements the two-phase hash-based ingestion protocol.
 func runScrapeWithHashCheck(ctx context.Context, dirPath string, opts *client.IngestOptions) error {
 	// 1. Collect files locally
@@ -133,11 +270,6 @@ func runScrapeWithHashCheck(ctx context.Context, dirPath string, opts *client.In
 	fmt.Printf("Found %d Markdown files\n", len(files))
 
 	// 2. Compute hashes and read content locally
-	type fileData struct {
-		path    string
-		content []byte
-		hash    string
-	}
 	fileMap := make(map[string]fileData, len(files))
 	var fileHashes []client.FileHashInput
 
@@ -150,13 +282,34 @@ func runScrapeWithHashCheck(ctx context.Context, dirPath string, opts *client.In
 		hash := sha256.Sum256(content)
 		hashStr := hex.EncodeToString(hash[:])
 
-		fileMap[f] = fileData{path: f, content: content, hash: hashStr}
+		dirCfg, err := parser.ResolveDirConfig(dirPath, f)
+		if err != nil {
+			return fmt.Errorf("load directory config: %w", err)
+		}
+
+		fileMap[f] = fileData{path: f, content: content, hash: hashStr, config: dirCfg}
 		fileHashes = append(fileHashes, client.FileHashInput{
 			Path: f,
 			Hash: hashStr,
 		})
 	}
 
+	if scrapeDiff {
+		var all []client.FileContentInput
+		for _, f := range files {
+			if data, ok := fileMap[f]; ok {
+				all = append(all, data.input())
+			}
+		}
+		fmt.Printf("Comparing with existing entities...\n")
+		diff, err := gqlClient.DiffFiles(ctx, all, baseDir, opts.Labels)
+		if err != nil {
+			return fmt.Errorf("diff: %w", err)
+		}
+		printIngestDiff(diff)
+		return nil
+	}
+
 	// 3. Ask server which files are needed
 	fmt.Printf("Checking for changes...\n")
 	checkResult, err := gqlClient.CheckHashes(ctx, fileHashes)
@@ -189,11 +342,7 @@ func runScrapeWithHashCheck(ctx context.Context, dirPath string, opts *client.In
 		if !ok {
 			continue
 		}
-		filesToUpload = append(filesToUpload, client.FileContentInput{
-			Path:    data.path,
-			Content: string(data.content),
-			Hash:    data.hash,
-		})
+		filesToUpload = append(filesToUpload, data.input())
 	}
 
 	// 5. Send to server for async processing
@@ -213,6 +362,62 @@ func runScrapeWithHashCheck(ctx context.Context, dirPath string, opts *client.In
 	return RunJobProgress(gqlClient, job)
 }
 
+// fileData is a locally read Markdown file with its resolved directory config.
+type fileData struct {
+	path    string
+	content []byte
+	hash    string
+	config  parser.DirConfig
+}
+
+// input converts the file to the upload format.
+func (d fileData) input() client.FileContentInput {
+	input := client.FileContentInput{
+		Path:          d.path,
+		Content:       string(d.content),
+		Hash:          d.hash,
+		Labels:        d.config.Labels,
+		ExtractGraph:  d.config.ExtractGraph,
+		AutoSummarize: d.config.AutoSummarize,
+	}
+	if d.config.Type != "" {
+		input.Type = &d.config.Type
+	}
+	return input
+}
+
+// printIngestDiff prints the result of a --diff scrape.
+func printIngestDiff(diff *client.IngestDiff) {
+	fmt.Printf("\n%d new, %d changed, %d unchanged\n", len(diff.New), len(diff.Changed), len(diff.Unchanged))
+
+	if len(diff.New) > 0 {
+		fmt.Printf("\nNew:\n")
+		for _, p := range diff.New {
+			fmt.Printf("  + %s\n", p)
+		}
+	}
+
+	if len(diff.Changed) > 0 {
+		fmt.Printf("\nChanged:\n")
+		for _, c := range diff.Changed {
+			fmt.Printf("  ~ %s (%s) +%d -%d lines\n", c.Path, c.EntityID, c.LinesAdded, c.LinesRemoved)
+			if c.Old.Name != c.New.Name {
+				fmt.Printf("      name:    %q → %q\n", c.Old.Name, c.New.Name)
+			}
+			if c.Old.Type != c.New.Type {
+				fmt.Printf("      type:    %s → %s\n", c.Old.Type, c.New.Type)
+			}
+			oldSummary, newSummary := derefOr(c.Old.Summary, "-"), derefOr(c.New.Summary, "-")
+			if oldSummary != newSummary {
+				fmt.Printf("      summary: %s → %s\n", truncate(oldSummary, 60), truncate(newSummary, 60))
+			}
+			if !slices.Equal(c.Old.Labels, c.New.Labels) {
+				fmt.Printf("      labels:  [%s] → [%s]\n", strings.Join(c.Old.Labels, ", "), strings.Join(c.New.Labels, ", "))
+			}
+		}
+	}
+}
+
 // collectMarkdownFiles walks a directory and returns all markdown file paths.
 func collectMarkdownFiles(dirPath string, recursive bool) ([]string, error) {
 	var files []string
@@ -239,6 +444,12 @@ func collectMarkdownFiles(dirPath string, recursive bool) ([]string, error) {
 func printIngestResult(result *client.IngestResult) {
 	if scrapeDryRun {
 		fmt.Printf("Dry run - would ingest %d files\n", result.FilesProcessed)
+		if scrapePrune {
+			fmt.Printf("  Would delete %d entities of removed files\n", result.EntitiesDeleted)
+		}
+		for _, c := range result.Changes {
+			fmt.Printf("  %-9s %s\n", c.Action, c.Path)
+		}
 	} else {
 		fmt.Printf("Ingested %d files", result.FilesProcessed)
 		if result.FilesSkipped > 0 {
@@ -247,9 +458,15 @@ func printIngestResult(result *client.IngestResult) {
 		fmt.Println()
 		fmt.Printf("  Entities created: %d\n", result.EntitiesCreated)
 		fmt.Printf("  Chunks created: %d\n", result.ChunksCreated)
+		if result.ChunksFailed > 0 {
+			fmt.Printf("  Chunks failed: %d (re-ingest to retry)\n", result.ChunksFailed)
+		}
 		if result.RelationsCreated > 0 {
 			fmt.Printf("  Relations created: %d\n", result.RelationsCreated)
 		}
+		if result.EntitiesDeleted > 0 {
+			fmt.Printf("  Entities deleted: %d (files removed)\n", result.EntitiesDeleted)
+		}
 	}
 
 	if len(result.Errors) > 0 {
@@ -259,3 +476,19 @@ func printIngestResult(result *client.IngestResult) {
 		}
 	}
 }
+
+func derefOr(s *string, fallback string) string {
+	if s == nil || *s == "" {
+		return fallback
+	}
+	return *s
+}
+
+// truncate shortens s to at most n runes, marking the cut with "...".
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
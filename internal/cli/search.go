@@ -2,17 +2,37 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/raphaelgruber/memcp-go/internal/client"
 	"github.com/spf13/cobra"
 )
 
 var (
-	searchLabels   []string
-	searchTypes    []string
-	searchVerified bool
-	searchLimit    int
+	searchLabels      []string
+	searchLabelGroups []string
+	searchHasMetadata []string
+	searchTypes       []string
+	searchVerified    bool
+	searchMinConf     float64
+	searchExclude     []string
+	searchDiversity   float64
+	searchRerank      bool
+	searchDecay       bool
+	searchGroupByType bool
+	searchChunks      bool
+	searchLanguage    string
+	searchContext     string
+	searchMode        string
+	searchEfSearch    int
+	searchOverFetch   int
+	searchSort        string
+	searchStream      bool
+	searchJSON        bool
+	searchLimit       int
 )
 
 var searchCmd = &cobra.Command{
@@ -26,17 +46,62 @@ Use 'ask' command for LLM-augmented responses.
 Examples:
   knowhow search "authentication"
   knowhow search "token refresh" --labels "work,auth-service"
+  knowhow search "incident" --label-group "work,team" --label-group "security"
   knowhow search "senior engineer" --type person
-  knowhow search "kubernetes" --verified`,
+  knowhow search "kubernetes" --verified
+  knowhow search "auth-service" --exclude auth-service  # similar to, not including
+  knowhow search "deployment" --diversity 0.5  # fewer near-duplicates
+  knowhow search "how do we rotate secrets" --rerank
+  knowhow search "deploy checklist" --decay  # recently used knowledge first
+  knowhow search "Bereitstellung" --language de
+  knowhow search "deploy" --context payments-api  # one project only
+  knowhow search "ERR_TOKEN_EXPIRED" --mode keyword  # exact terms only
+  knowhow search "keeping services available" --mode vector  # meaning, not wording
+  knowhow search "deploy runbook" --ef-search 200  # higher recall, slower
+  knowhow search "auth" --group-by-type  # results per type, label counts
+  knowhow search "runbook" --sort popularity  # most used of the matches first
+  knowhow search "token expiry" --chunks  # best chunks across all entities
+  knowhow search "runbook" --limit 100 --stream  # print results one by one
+  knowhow search "runbook" --stream --json | jq -r '.entity.name'
+
+With --json, each result is printed as one line of JSON with its entity,
+score and matched chunks (content and heading path). With --stream, results
+are printed one by one as the server sends them; if the stream fails midway,
+the results received so far are printed and the command exits with an error.
+The server still ranks all results before sending the first, so --stream
+doesn't show the first result sooner.
+
+With --chunks, the best matching chunks are listed instead of entities, each
+with its entity, heading path and position for citing; one entity can
+contribute several chunks.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runSearch,
 }
 
 func init() {
 	searchCmd.Flags().StringSliceVarP(&searchLabels, "labels", "l", nil, "filter by labels")
+	searchCmd.Flags().StringArrayVar(&searchLabelGroups, "label-group", nil, "comma-separated labels (OR'd); repeat to AND groups")
+	searchCmd.Flags().StringSliceVar(&searchHasMetadata, "has-metadata", nil, "only entities with these metadata keys set")
 	searchCmd.Flags().StringSliceVarP(&searchTypes, "type", "t", nil, "filter by entity types")
 	searchCmd.Flags().BoolVar(&searchVerified, "verified", false, "only return verified entities")
+	searchCmd.Flags().Float64Var(&searchMinConf, "min-confidence", 0, "0-1: only entities with at least this confidence")
+	searchCmd.Flags().Float64Var(&searchDiversity, "diversity", 0, "0-1: prefer varied results over the most similar ones")
+	searchCmd.Flags().BoolVar(&searchRerank, "rerank", false, "reorder results with the server's rerank model")
+	searchCmd.Flags().BoolVar(&searchDecay, "decay", false, "rank recently accessed entities higher")
+	searchCmd.Flags().StringSliceVar(&searchExclude, "exclude", nil, "entity IDs to leave out of the results")
+	searchCmd.Flags().StringVar(&searchLanguage, "language", "", "only entities in this language (ISO 639-1 code, e.g. de)")
+	searchCmd.Flags().StringVar(&searchContext, "context", "", "only entities in this project context")
+	searchCmd.Flags().StringVar(&searchMode, "mode", "", "retrieval: hybrid (default), keyword (BM25 only) or vector (embeddings only)")
+	searchCmd.Flags().IntVar(&searchEfSearch, "ef-search", 0, "candidates the vector index explores (default 60; higher = better recall, slower)")
+	searchCmd.Flags().IntVar(&searchOverFetch, "over-fetch", 0, "candidates per retriever as a multiple of the limit (default 2)")
+	searchCmd.Flags().StringVar(&searchSort, "sort", "", "order results by relevance (default), recency (last accessed) or popularity (access count)")
+	searchCmd.Flags().BoolVar(&searchGroupByType, "group-by-type", false, "group results by entity type and show label/source counts")
+	searchCmd.Flags().BoolVar(&searchChunks, "chunks", false, "list the best matching chunks across entities instead of entities")
+	searchCmd.Flags().BoolVar(&searchStream, "stream", false, "print results one by one as the server sends them (ranked in full first)")
+	searchCmd.Flags().BoolVar(&searchJSON, "json", false, "print each result as a line of JSON")
 	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 10, "max results")
+	searchCmd.MarkFlagsMutuallyExclusive("json", "group-by-type")
+	searchCmd.MarkFlagsMutuallyExclusive("chunks", "group-by-type", "stream")
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
@@ -44,11 +109,38 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
 	opts := client.SearchOptions{
-		Query:        query,
-		Labels:       searchLabels,
-		Types:        searchTypes,
-		VerifiedOnly: &searchVerified,
-		Limit:        &searchLimit,
+		Query:           query,
+		Labels:          searchLabels,
+		LabelGroups:     parseLabelGroups(searchLabelGroups),
+		HasMetadataKeys: searchHasMetadata,
+		Types:           searchTypes,
+		VerifiedOnly:    &searchVerified,
+		ExcludeIDs:      searchExclude,
+		Diversity:       &searchDiversity,
+		Rerank:          searchRerank,
+		ApplyDecay:      searchDecay,
+		Language:        searchLanguage,
+		Context:         searchContext,
+		Mode:            searchMode,
+		OverFetchFactor: searchOverFetch,
+		SortBy:          searchSort,
+		Limit:           &searchLimit,
+	}
+	if cmd.Flags().Changed("min-confidence") {
+		opts.MinConfidence = &searchMinConf
+	}
+	if cmd.Flags().Changed("ef-search") {
+		opts.EfSearch = &searchEfSearch
+	}
+
+	if searchGroupByType {
+		return runFacetedSearch(ctx, opts)
+	}
+	if searchChunks {
+		return runChunkSearch(ctx, opts)
+	}
+	if searchStream {
+		return runStreamSearch(ctx, opts)
 	}
 
 	results, err := gqlClient.Search(ctx, opts)
@@ -56,6 +148,16 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("search: %w", err)
 	}
 
+	if searchJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, result := range results {
+			if err := enc.Encode(result); err != nil {
+				return fmt.Errorf("write result: %w", err)
+			}
+		}
+		return nil
+	}
+
 	if len(results) == 0 {
 		fmt.Println("No results found.")
 		return nil
@@ -63,20 +165,146 @@ func runSearch(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Found %d results:\n\n", len(results))
 	for i, result := range results {
-		entity := result.Entity
-		fmt.Printf("%d. %s [%s]\n", i+1, entity.Name, entity.Type)
-		if entity.Summary != nil && *entity.Summary != "" {
-			fmt.Printf("   %s\n", *entity.Summary)
-		} else if entity.Content != nil && len(*entity.Content) > 100 {
-			fmt.Printf("   %s...\n", (*entity.Content)[:100])
-		} else if entity.Content != nil {
-			fmt.Printf("   %s\n", *entity.Content)
+		printSearchResult(i+1, result.Entity)
+	}
+
+	return nil
+}
+
+func runStreamSearch(ctx context.Context, opts client.SearchOptions) error {
+	enc := json.NewEncoder(os.Stdout)
+	n := 0
+	err := gqlClient.SearchStream(ctx, opts, func(result client.EntitySearchResult) error {
+		n++
+		if searchJSON {
+			// Encode writes each line straight to stdout, so results
+			// received before a stream error are already out
+			if err := enc.Encode(result); err != nil {
+				return fmt.Errorf("write result: %w", err)
+			}
+			return nil
+		}
+		printSearchResult(n, result.Entity)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	if n == 0 && !searchJSON {
+		fmt.Println("No results found.")
+	}
+	return nil
+}
+
+func runChunkSearch(ctx context.Context, opts client.SearchOptions) error {
+	matches, err := gqlClient.SearchChunks(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	if searchJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, match := range matches {
+			if err := enc.Encode(match); err != nil {
+				return fmt.Errorf("write result: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if len(matches) == 0 {
+		fmt.Println("No results found.")
+		return nil
+	}
+
+	fmt.Printf("Found %d chunks:\n\n", len(matches))
+	for i, match := range matches {
+		source := match.EntityName
+		if match.HeadingPath != nil && *match.HeadingPath != "" {
+			source += " > " + *match.HeadingPath
+		}
+		fmt.Printf("%d. %s (chunk %d, score %.4f)\n", i+1, source, match.Position, match.Score)
+		content := match.Content
+		if len(content) > 200 {
+			content = content[:200] + "..."
+		}
+		fmt.Printf("   %s\n\n", content)
+	}
+	return nil
+}
+
+func runFacetedSearch(ctx context.Context, opts client.SearchOptions) error {
+	faceted, err := gqlClient.SearchFaceted(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	if faceted.Total == 0 {
+		fmt.Println("No results found.")
+		return nil
+	}
+
+	fmt.Printf("Found %d results:\n", faceted.Total)
+	for _, group := range faceted.Groups {
+		fmt.Printf("\n%s (%d)\n\n", group.Type, group.Count)
+		for i, result := range group.Results {
+			printSearchResult(i+1, result.Entity)
 		}
-		if verbose && len(entity.Labels) > 0 {
-			fmt.Printf("   Labels: %v\n", entity.Labels)
+	}
+
+	if len(faceted.Labels) > 0 {
+		labels := make([]string, len(faceted.Labels))
+		for i, l := range faceted.Labels {
+			labels[i] = fmt.Sprintf("%s (%d)", l.Label, l.Count)
 		}
-		fmt.Println()
+		fmt.Printf("Labels: %s\n", strings.Join(labels, ", "))
 	}
+	sources := make([]string, len(faceted.Sources))
+	for i, s := range faceted.Sources {
+		sources[i] = fmt.Sprintf("%s (%d)", s.Source, s.Count)
+	}
+	fmt.Printf("Sources: %s\n", strings.Join(sources, ", "))
 
 	return nil
 }
+
+// printSearchResult prints a numbered result with its summary or a content preview.
+func printSearchResult(n int, entity client.Entity) {
+	pinned := ""
+	if entity.Pinned {
+		pinned = " (pinned)"
+	}
+	fmt.Printf("%d. %s [%s]%s\n", n, entity.Name, entity.Type, pinned)
+	if entity.Summary != nil && *entity.Summary != "" {
+		fmt.Printf("   %s\n", *entity.Summary)
+	} else if entity.Content != nil && len(*entity.Content) > 100 {
+		fmt.Printf("   %s...\n", (*entity.Content)[:100])
+	} else if entity.Content != nil {
+		fmt.Printf("   %s\n", *entity.Content)
+	}
+	if verbose && len(entity.Labels) > 0 {
+		fmt.Printf("   Labels: %v\n", entity.Labels)
+	}
+	if verbose || searchSort == "recency" || searchSort == "popularity" {
+		fmt.Printf("   Accessed: %d times, last %s\n", entity.AccessCount, entity.AccessedAt.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Println()
+}
+
+// parseLabelGroups splits each comma-separated flag value into a label group.
+func parseLabelGroups(values []string) [][]string {
+	groups := make([][]string, 0, len(values))
+	for _, v := range values {
+		var group []string
+		for _, label := range strings.Split(v, ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				group = append(group, label)
+			}
+		}
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/raphaelgruber/memcp-go/internal/client"
+)
+
+// searchStreamServer serves a searchStream subscription sending a result
+// for each name, then a final event failing with streamErr (if set).
+func searchStreamServer(t *testing.T, names []string, streamErr string) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{Subprotocols: []string{"graphql-transport-ws"}}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		var msg struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil || msg.Type != "connection_init" {
+			t.Errorf("read connection_init: %v (type %q)", err, msg.Type)
+			return
+		}
+		if err := conn.WriteJSON(map[string]string{"type": "connection_ack"}); err != nil {
+			t.Errorf("write connection_ack: %v", err)
+			return
+		}
+		if err := conn.ReadJSON(&msg); err != nil || msg.Type != "subscribe" {
+			t.Errorf("read subscribe: %v (type %q)", err, msg.Type)
+			return
+		}
+
+		send := func(event map[string]any) bool {
+			payload := map[string]any{"data": map[string]any{"searchStream": event}}
+			if err := conn.WriteJSON(map[string]any{"id": msg.ID, "type": "next", "payload": payload}); err != nil {
+				t.Errorf("write next: %v", err)
+				return false
+			}
+			return true
+		}
+		for _, name := range names {
+			result := map[string]any{"entity": map[string]any{"id": "entity:" + name, "name": name, "type": "note"}, "score": 0.5}
+			if !send(map[string]any{"result": result, "done": false}) {
+				return
+			}
+		}
+		final := map[string]any{"done": true}
+		if streamErr != "" {
+			final["error"] = streamErr
+		}
+		send(final)
+
+		// Wait for the client to close the subscription
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+// captureStdout returns what fn writes to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Errorf("read stdout: %v", err)
+		}
+		out <- string(data)
+	}()
+	fn()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return <-out
+}
+
+func TestRunStreamSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		names     []string
+		streamErr string
+		json      bool
+		wantOut   []string // in order
+		wantErr   bool
+	}{
+		{"all results", []string{"alpha", "beta"}, "", false, []string{"1. alpha [note]", "2. beta [note]"}, false},
+		{"partial results kept on error", []string{"alpha", "beta"}, "search failed", false, []string{"1. alpha [note]", "2. beta [note]"}, true},
+		{"partial json lines kept on error", []string{"alpha"}, "search failed", true, []string{`"name":"alpha"`}, true},
+		{"no results", nil, "", false, []string{"No results found."}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := searchStreamServer(t, tt.names, tt.streamErr)
+			defer server.Close()
+
+			prevClient, prevJSON := gqlClient, searchJSON
+			gqlClient, searchJSON = client.New(server.URL+"/query"), tt.json
+			defer func() { gqlClient, searchJSON = prevClient, prevJSON }()
+
+			var err error
+			out := captureStdout(t, func() {
+				err = runStreamSearch(context.Background(), client.SearchOptions{Query: "q"})
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("runStreamSearch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(fmt.Sprint(err), tt.streamErr) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.streamErr)
+			}
+			rest := out
+			for _, want := range tt.wantOut {
+				i := strings.Index(rest, want)
+				if i < 0 {
+					t.Fatalf("output missing %q in order:\n%s", want, out)
+				}
+				rest = rest[i+len(want):]
+			}
+			if tt.json {
+				for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+					if !json.Valid([]byte(line)) {
+						t.Errorf("invalid JSON line %q", line)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show knowledge graph statistics",
+	Long: `Show the shape of the knowledge graph: entity and relation counts,
+average degree, orphaned entities, the most connected entities and the
+relation type distribution. Results are cached by the server for a minute.
+
+Examples:
+  knowhow stats`,
+	RunE: runStats,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	stats, err := gqlClient.GetGraphAnalytics(ctx)
+	if err != nil {
+		return fmt.Errorf("get graph analytics: %w", err)
+	}
+
+	fmt.Printf("Knowledge Graph\n")
+	fmt.Printf("═══════════════════════════════════════\n\n")
+	fmt.Printf("Entities:       %d\n", stats.Nodes)
+	fmt.Printf("Relations:      %d\n", stats.Edges)
+	fmt.Printf("Average degree: %.2f\n", stats.AverageDegree)
+	fmt.Printf("Orphans:        %d\n", stats.Orphans)
+
+	if len(stats.TopConnected) > 0 {
+		fmt.Printf("\nMost Connected:\n")
+		for _, e := range stats.TopConnected {
+			fmt.Printf("  %-30s [%s] %d\n", e.Name, e.Type, e.Degree)
+		}
+	}
+
+	if len(stats.RelationTypes) > 0 {
+		fmt.Printf("\nRelation Types:\n")
+		for _, rt := range stats.RelationTypes {
+			pct := 0.0
+			if stats.Edges > 0 {
+				pct = float64(rt.Count) / float64(stats.Edges) * 100
+			}
+			fmt.Printf("  %-20s %6d (%5.1f%%)\n", rt.RelType, rt.Count, pct)
+		}
+	}
+
+	return nil
+}
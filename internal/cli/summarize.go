@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var summarizeCmd = &cobra.Command{
+	Use:   "summarize [entity]",
+	Short: "Generate summaries for entities without one",
+	Long: `Start a background job that generates LLM summaries for existing entities
+with long content but no summary.
+
+With an entity (ID or name), summarize just that entity right away,
+replacing its summary, whatever the length of its content.
+
+Examples:
+  knowhow summarize
+  knowhow summarize "auth-service"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSummarize,
+}
+
+func init() {
+	rootCmd.AddCommand(summarizeCmd)
+}
+
+func runSummarize(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if len(args) == 1 {
+		entity, err := resolveEntity(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("get entity: %w", err)
+		}
+		summarized, err := gqlClient.SummarizeEntity(ctx, entity.ID)
+		if err != nil {
+			return fmt.Errorf("summarize: %w", err)
+		}
+		if summarized.Summary != nil {
+			fmt.Printf("%s: %s\n", summarized.Name, *summarized.Summary)
+		}
+		return nil
+	}
+
+	job, err := gqlClient.GenerateMissingSummaries(ctx)
+	if err != nil {
+		return fmt.Errorf("start summarize job: %w", err)
+	}
+
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Printf("Started job %s\n", job.ID)
+		fmt.Printf("  Use 'knowhow jobs %s' to check progress\n", job.ID)
+		return nil
+	}
+
+	return RunJobProgress(gqlClient, job)
+}
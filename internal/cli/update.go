@@ -17,6 +17,9 @@ var (
 	updateLabels      string // "add:label1,label2" or "remove:label1" or "set:label1,label2"
 	updateVerified    bool
 	updateSetVerified bool
+	updateAlways      bool
+	updatePinned      bool
+	updateAliases     []string
 )
 
 var updateCmd = &cobra.Command{
@@ -31,6 +34,10 @@ Examples:
   knowhow update "john-doe" --labels "add:senior,promoted"
   knowhow update "auth-service" --labels "remove:deprecated"
   knowhow update "auth-service" --verified
+  knowhow update "glossary" --always-in-context
+  knowhow update "glossary" --always-in-context=false
+  knowhow update "team-roster" --pinned
+  knowhow update "kubernetes" --add-alias k8s,kube
   knowhow update "concept-123" --content-file ./updated.md`,
 	Args: cobra.ExactArgs(1),
 	RunE: runUpdate,
@@ -43,6 +50,9 @@ func init() {
 	updateCmd.Flags().StringVarP(&updateLabels, "labels", "l", "", "label changes: add:x,y / remove:x,y / set:x,y")
 	updateCmd.Flags().BoolVar(&updateVerified, "verified", false, "mark as verified")
 	updateCmd.Flags().BoolVar(&updateSetVerified, "set-verified", false, "explicitly set verified flag")
+	updateCmd.Flags().BoolVar(&updateAlways, "always-in-context", false, "include in the context of every ask")
+	updateCmd.Flags().BoolVar(&updatePinned, "pinned", false, "exempt from decay (pinning resets the decay weight)")
+	updateCmd.Flags().StringSliceVar(&updateAliases, "add-alias", nil, "alternative names lookups and relations resolve to this entity")
 }
 
 func runUpdate(cmd *cobra.Command, args []string) error {
@@ -113,15 +123,39 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		hasUpdate = true
 	}
 
-	if !hasUpdate {
+	alwaysChanged := cmd.Flags().Changed("always-in-context")
+	pinnedChanged := cmd.Flags().Changed("pinned")
+	if !hasUpdate && !alwaysChanged && !pinnedChanged && len(updateAliases) == 0 {
 		fmt.Println("No updates specified.")
 		return nil
 	}
 
 	// Apply update
-	updated, err := gqlClient.UpdateEntity(ctx, entity.ID, update)
-	if err != nil {
-		return fmt.Errorf("update entity: %w", err)
+	updated := entity
+	if hasUpdate {
+		updated, err = gqlClient.UpdateEntity(ctx, entity.ID, update)
+		if err != nil {
+			return fmt.Errorf("update entity: %w", err)
+		}
+	}
+	if alwaysChanged {
+		if _, err := gqlClient.SetAlwaysInContext(ctx, entity.ID, updateAlways); err != nil {
+			return fmt.Errorf("set always in context: %w", err)
+		}
+		updated.AlwaysInContext = updateAlways
+	}
+	if pinnedChanged {
+		if _, err := gqlClient.PinEntity(ctx, entity.ID, updatePinned); err != nil {
+			return fmt.Errorf("pin entity: %w", err)
+		}
+		updated.Pinned = updatePinned
+	}
+	for _, alias := range updateAliases {
+		aliased, err := gqlClient.AddEntityAlias(ctx, entity.ID, alias)
+		if err != nil {
+			return fmt.Errorf("add alias %q: %w", alias, err)
+		}
+		updated.Aliases = aliased.Aliases
 	}
 
 	fmt.Printf("Updated entity: %s\n", updated.Name)
@@ -129,6 +163,15 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Type: %s\n", updated.Type)
 		fmt.Printf("  Labels: %v\n", updated.Labels)
 		fmt.Printf("  Verified: %v\n", updated.Verified)
+		if alwaysChanged {
+			fmt.Printf("  Always in context: %v\n", updated.AlwaysInContext)
+		}
+		if pinnedChanged {
+			fmt.Printf("  Pinned: %v\n", updated.Pinned)
+		}
+		if len(updateAliases) > 0 {
+			fmt.Printf("  Aliases: %v\n", updated.Aliases)
+		}
 	}
 
 	return nil
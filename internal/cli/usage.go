@@ -13,6 +13,9 @@ var (
 	usageSince    string
 	usageDetailed bool
 	usageCosts    bool
+	usageLimit    int
+
+	usageSourcesSince string
 )
 
 var usageCmd = &cobra.Command{
@@ -24,14 +27,74 @@ Examples:
   knowhow usage
   knowhow usage --since "7 days ago"
   knowhow usage --detailed
-  knowhow usage --costs`,
+  knowhow usage --costs
+  knowhow usage sources --since 30d`,
 	RunE: runUsage,
 }
 
+var usageSourcesCmd = &cobra.Command{
+	Use:   "sources",
+	Short: "Show which entities inform answers most",
+	Long: `Rank entities by how often asks included them in the answer context.
+
+Retrieved counts every ask that found the entity; included counts asks whose
+context contained its text. Knowledge that is never retrieved doesn't appear.`,
+	RunE: runUsageSources,
+}
+
 func init() {
 	usageCmd.Flags().StringVar(&usageSince, "since", "24h", "time period (e.g., '24h', '7d', '30d')")
 	usageCmd.Flags().BoolVar(&usageDetailed, "detailed", false, "show detailed breakdown")
 	usageCmd.Flags().BoolVar(&usageCosts, "costs", false, "show cost estimates")
+
+	usageSourcesCmd.Flags().StringVar(&usageSourcesSince, "since", "30d", "time period (e.g., '24h', '7d', '30d')")
+	usageSourcesCmd.Flags().IntVarP(&usageLimit, "limit", "n", 20, "max entities")
+	usageCmd.AddCommand(usageSourcesCmd)
+}
+
+// parseSince converts a period like "24h", "7d" or "30d" (or any Go
+// duration) to the time that long ago.
+func parseSince(period string) (time.Time, error) {
+	switch period {
+	case "24h":
+		return time.Now().Add(-24 * time.Hour), nil
+	case "7d":
+		return time.Now().Add(-7 * 24 * time.Hour), nil
+	case "30d":
+		return time.Now().Add(-30 * 24 * time.Hour), nil
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid duration: %s", period)
+	}
+	return time.Now().Add(-d), nil
+}
+
+func runUsageSources(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	since, err := parseSince(usageSourcesSince)
+	if err != nil {
+		return err
+	}
+
+	sources, err := gqlClient.MostUsefulSources(ctx, since.Format(time.RFC3339), usageLimit)
+	if err != nil {
+		return fmt.Errorf("get answer sources: %w", err)
+	}
+
+	if len(sources) == 0 {
+		fmt.Printf("No asks retrieved any entities since %s.\n", usageSourcesSince)
+		return nil
+	}
+
+	fmt.Printf("Most useful sources (since %s)\n", usageSourcesSince)
+	fmt.Printf("═══════════════════════════════════════\n\n")
+	fmt.Printf("  %-40s %9s %9s %8s\n", "Entity", "Included", "Retrieved", "Avg rank")
+	for _, src := range sources {
+		fmt.Printf("  %-40s %9d %9d %8.1f\n", src.Entity.Name, src.Included, src.Retrieved, src.AvgRank)
+	}
+	return nil
 }
 
 func runUsage(cmd *cobra.Command, args []string) error {
@@ -45,22 +108,9 @@ func runUsage(cmd *cobra.Command, args []string) error {
 	printServerStats(stats)
 	fmt.Println()
 
-	// Parse since duration
-	var since time.Time
-	switch usageSince {
-	case "24h":
-		since = time.Now().Add(-24 * time.Hour)
-	case "7d":
-		since = time.Now().Add(-7 * 24 * time.Hour)
-	case "30d":
-		since = time.Now().Add(-30 * 24 * time.Hour)
-	default:
-		// Try parsing as duration
-		d, err := time.ParseDuration(usageSince)
-		if err != nil {
-			return fmt.Errorf("invalid duration: %s", usageSince)
-		}
-		since = time.Now().Add(-d)
+	since, err := parseSince(usageSourcesSince)
+	if err != nil {
+		return err
 	}
 
 	sinceStr := since.Format(time.RFC3339)
@@ -69,7 +119,7 @@ func runUsage(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("get token usage: %w", err)
 	}
 
-	fmt.Printf("Token Usage (since %s)\n", usageSince)
+	fmt.Printf("Token Usage (since %s)\n", usageSourcesSince)
 	fmt.Printf("═══════════════════════════════════════\n\n")
 
 	fmt.Printf("Total tokens: %d\n", summary.TotalTokens)
@@ -167,6 +217,7 @@ func printServerStats(stats *client.ServerStats) {
 	fmt.Printf("Server Statistics (in-memory, since restart)\n")
 	fmt.Printf("═══════════════════════════════════════════════\n")
 	fmt.Printf("Uptime: %s\n", formatUptime(stats.UptimeSeconds))
+	fmt.Printf("Vector index: %s\n", stats.VectorIndex)
 
 	if stats.Embedding != nil {
 		fmt.Printf("\nEmbeddings:\n")
@@ -194,6 +245,35 @@ func printServerStats(stats *client.ServerStats) {
 		fmt.Printf("\nDB Search:\n")
 		printOpStats(stats.DBSearch)
 	}
+
+	if len(stats.DBConnections) > 0 {
+		fmt.Printf("\nDB Connections:\n")
+		for i, conn := range stats.DBConnections {
+			state := "connected"
+			if !conn.Connected {
+				state = "disconnected"
+			}
+			fmt.Printf("  #%d: %d active (peak %d), %d queries, %s\n",
+				i, conn.Active, conn.MaxActive, conn.Queries, state)
+		}
+	}
+
+	if stats.Rerank != nil {
+		fmt.Printf("\nRerank:\n")
+		printOpStats(stats.Rerank)
+	}
+
+	if stats.AnswerCache != nil {
+		fmt.Printf("\nAnswer Cache:\n")
+		fmt.Printf("  Hits: %d, Misses: %d (%.1f%% hit rate)\n",
+			stats.AnswerCache.Hits, stats.AnswerCache.Misses, stats.AnswerCache.HitRate*100)
+	}
+
+	if stats.EmbedCache != nil {
+		fmt.Printf("\nEmbedding Cache:\n")
+		fmt.Printf("  Hits: %d, Misses: %d (%.1f%% hit rate)\n",
+			stats.EmbedCache.Hits, stats.EmbedCache.Misses, stats.EmbedCache.HitRate*100)
+	}
 }
 
 // printOpStats displays timing statistics for an operation.
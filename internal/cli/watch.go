@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/raphaelgruber/memcp-go/internal/client"
+	"github.com/spf13/cobra"
+)
+
+var watchLabels []string
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Stream live entity changes",
+	Long: `Print entities as they are created, updated or deleted by any client or
+background job. Press Ctrl+C to stop.
+
+Examples:
+  knowhow watch
+  knowhow watch --labels "work,ops"`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().StringSliceVarP(&watchLabels, "labels", "l", nil, "only entities with any of these labels")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Println("Watching entity changes (Ctrl+C to stop)...")
+	err := gqlClient.WatchEntityChanges(ctx, watchLabels, func(event client.EntityChangeEvent) error {
+		line := fmt.Sprintf("%s %-8s %s (%s)", time.Now().Format("15:04:05"), event.Type, event.Entity.Name, event.Entity.ID)
+		if len(event.Entity.Labels) > 0 {
+			line += " [" + strings.Join(event.Entity.Labels, ", ") + "]"
+		}
+		fmt.Println(line)
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("watch: %w", err)
+	}
+	return nil
+}
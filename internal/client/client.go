@@ -88,6 +88,8 @@ func (c *Client) Execute(ctx context.Context, query string, variables map[string
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	// Leave Accept-Encoding unset: the transport then asks for gzip and
+	// decompresses the response transparently
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("execute request: %w", err)
@@ -127,22 +129,29 @@ func (c *Client) Execute(ctx context.Context, query string, variables map[string
 
 // Entity represents a knowledge entity.
 type Entity struct {
-	ID          string         `json:"id"`
-	Type        string         `json:"type"`
-	Name        string         `json:"name"`
-	Content     *string        `json:"content,omitempty"`
-	Summary     *string        `json:"summary,omitempty"`
-	Labels      []string       `json:"labels"`
-	ContentHash *string        `json:"contentHash,omitempty"`
-	Verified    bool           `json:"verified"`
-	Confidence  float64        `json:"confidence"`
-	Source      string         `json:"source"`
-	SourcePath  *string        `json:"sourcePath,omitempty"`
-	Metadata    map[string]any `json:"metadata,omitempty"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	UpdatedAt   time.Time      `json:"updatedAt"`
-	AccessedAt  time.Time      `json:"accessedAt"`
-	AccessCount int            `json:"accessCount"`
+	ID              string         `json:"id"`
+	Type            string         `json:"type"`
+	Name            string         `json:"name"`
+	Content         *string        `json:"content,omitempty"`
+	Summary         *string        `json:"summary,omitempty"`
+	Labels          []string       `json:"labels"`
+	Aliases         []string       `json:"aliases,omitempty"`
+	ContentHash     *string        `json:"contentHash,omitempty"`
+	Verified        bool           `json:"verified"`
+	Confidence      float64        `json:"confidence"`
+	Source          string         `json:"source"`
+	SourcePath      *string        `json:"sourcePath,omitempty"`
+	Metadata        map[string]any `json:"metadata,omitempty"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+	ModifiedAt      time.Time      `json:"modifiedAt"`
+	AccessedAt      time.Time      `json:"accessedAt"`
+	AccessCount     int            `json:"accessCount"`
+	DecayWeight     *float64       `json:"decayWeight,omitempty"`
+	AlwaysInContext bool           `json:"alwaysInContext"`
+	Pinned          bool           `json:"pinned"`
+	Language        *string        `json:"language,omitempty"`
+	Context         *string        `json:"context,omitempty"`
 }
 
 // Template represents an output rendering template.
@@ -169,14 +178,42 @@ type ChunkMatch struct {
 	Position    int     `json:"position"`
 }
 
+// ChunkSearchResult is a chunk matched by SearchChunks, with the entity to cite.
+type ChunkSearchResult struct {
+	EntityID    string  `json:"entityId"`
+	EntityName  string  `json:"entityName"`
+	Content     string  `json:"content"`
+	HeadingPath *string `json:"headingPath,omitempty"`
+	Position    int     `json:"position"`
+	Score       float64 `json:"score"`
+}
+
+// ChunkPreview is a chunk a document would be split into on ingest.
+type ChunkPreview struct {
+	Position    int     `json:"position"`
+	Content     string  `json:"content"`
+	HeadingPath *string `json:"headingPath,omitempty"`
+	Length      int     `json:"length"`
+}
+
 // IngestResult summarizes an ingestion operation.
 type IngestResult struct {
-	FilesProcessed   int      `json:"filesProcessed"`
-	FilesSkipped     int      `json:"filesSkipped"`
-	EntitiesCreated  int      `json:"entitiesCreated"`
-	ChunksCreated    int      `json:"chunksCreated"`
-	RelationsCreated int      `json:"relationsCreated"`
-	Errors           []string `json:"errors"`
+	FilesProcessed   int          `json:"filesProcessed"`
+	FilesSkipped     int          `json:"filesSkipped"`
+	EntitiesCreated  int          `json:"entitiesCreated"`
+	ChunksCreated    int          `json:"chunksCreated"`
+	ChunksFailed     int          `json:"chunksFailed"`
+	RelationsCreated int          `json:"relationsCreated"`
+	EntitiesDeleted  int          `json:"entitiesDeleted"`
+	Errors           []string     `json:"errors"`
+	Changes          []FileAction `json:"changes,omitempty"` // Dry runs only
+}
+
+// FileAction is what ingesting a file would do to its entity:
+// "create", "update" or "unchanged".
+type FileAction struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
 }
 
 // FileHashInput represents a file with its content hash for deduplication.
@@ -195,6 +232,11 @@ type FileContentInput struct {
 	Path    string `json:"path"`
 	Content string `json:"content"`
 	Hash    string `json:"hash"`
+	// Per-file defaults from .knowhow.yaml
+	Type          *string  `json:"type,omitempty"`
+	Labels        []string `json:"labels,omitempty"`
+	ExtractGraph  *bool    `json:"extractGraph,omitempty"`
+	AutoSummarize *bool    `json:"autoSummarize,omitempty"`
 }
 
 // LabelCount represents a label with its entity count.
@@ -209,6 +251,27 @@ type TypeCount struct {
 	Count int    `json:"count"`
 }
 
+// SourceCount represents an entity source with its count.
+type SourceCount struct {
+	Source string `json:"source"`
+	Count  int    `json:"count"`
+}
+
+// TypeGroup holds the search results of one entity type in rank order.
+type TypeGroup struct {
+	Type    string               `json:"type"`
+	Count   int                  `json:"count"`
+	Results []EntitySearchResult `json:"results"`
+}
+
+// FacetedSearchResult holds search results grouped by type with facet counts.
+type FacetedSearchResult struct {
+	Total   int           `json:"total"`
+	Groups  []TypeGroup   `json:"groups"`
+	Labels  []LabelCount  `json:"labels"`
+	Sources []SourceCount `json:"sources"`
+}
+
 // TokenUsageSummary provides aggregated token usage statistics.
 type TokenUsageSummary struct {
 	TotalTokens  int            `json:"totalTokens"`
@@ -236,12 +299,32 @@ type OperationStats struct {
 
 // ServerStats holds in-memory runtime statistics (resets on server restart).
 type ServerStats struct {
-	UptimeSeconds float64         `json:"uptimeSeconds"`
-	Embedding     *OperationStats `json:"embedding,omitempty"`
-	LLMGenerate   *OperationStats `json:"llmGenerate,omitempty"`
-	LLMStream     *OperationStats `json:"llmStream,omitempty"`
-	DBQuery       *OperationStats `json:"dbQuery,omitempty"`
-	DBSearch      *OperationStats `json:"dbSearch,omitempty"`
+	UptimeSeconds float64             `json:"uptimeSeconds"`
+	VectorIndex   string              `json:"vectorIndex"`
+	Embedding     *OperationStats     `json:"embedding,omitempty"`
+	LLMGenerate   *OperationStats     `json:"llmGenerate,omitempty"`
+	LLMStream     *OperationStats     `json:"llmStream,omitempty"`
+	DBQuery       *OperationStats     `json:"dbQuery,omitempty"`
+	DBSearch      *OperationStats     `json:"dbSearch,omitempty"`
+	Rerank        *OperationStats     `json:"rerank,omitempty"`
+	AnswerCache   *CacheStats         `json:"answerCache,omitempty"`
+	EmbedCache    *CacheStats         `json:"embedCache,omitempty"`
+	DBConnections []DBConnectionStats `json:"dbConnections"`
+}
+
+// DBConnectionStats holds the load of one pooled database connection.
+type DBConnectionStats struct {
+	Active    int  `json:"active"`
+	MaxActive int  `json:"maxActive"`
+	Queries   int  `json:"queries"`
+	Connected bool `json:"connected"`
+}
+
+// CacheStats holds hit statistics of a cache.
+type CacheStats struct {
+	Hits    int     `json:"hits"`
+	Misses  int     `json:"misses"`
+	HitRate float64 `json:"hitRate"`
 }
 
 // =============================================================================
@@ -259,6 +342,7 @@ type CreateEntityInput struct {
 	Source     *string        `json:"source,omitempty"`
 	SourcePath *string        `json:"sourcePath,omitempty"`
 	Metadata   map[string]any `json:"metadata,omitempty"`
+	Context    *string        `json:"context,omitempty"`
 }
 
 // CreateEntity creates a new entity.
@@ -267,7 +351,7 @@ func (c *Client) CreateEntity(ctx context.Context, input CreateEntityInput) (*En
 		mutation CreateEntity($input: EntityInput!) {
 			createEntity(input: $input) {
 				id type name content summary labels verified confidence
-				source sourcePath metadata createdAt updatedAt accessedAt accessCount
+				source sourcePath metadata createdAt updatedAt modifiedAt accessedAt accessCount context
 			}
 		}
 	`
@@ -281,6 +365,37 @@ func (c *Client) CreateEntity(ctx context.Context, input CreateEntityInput) (*En
 	return &result.CreateEntity, nil
 }
 
+// CreateEntityResult is the outcome of one input of CreateEntities: the
+// created entity, or the error that kept it from being created.
+type CreateEntityResult struct {
+	Entity *Entity `json:"entity,omitempty"`
+	Error  *string `json:"error,omitempty"`
+}
+
+// CreateEntities creates many entities in one request (at most 1000).
+// Results are in input order; a failing input doesn't fail the others.
+func (c *Client) CreateEntities(ctx context.Context, inputs []CreateEntityInput) ([]CreateEntityResult, error) {
+	const query = `
+		mutation CreateEntities($inputs: [EntityInput!]!) {
+			createEntities(inputs: $inputs) {
+				entity {
+					id type name content summary labels verified confidence
+					source sourcePath metadata createdAt updatedAt modifiedAt accessedAt accessCount
+				}
+				error
+			}
+		}
+	`
+
+	var result struct {
+		CreateEntities []CreateEntityResult `json:"createEntities"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"inputs": inputs}, &result); err != nil {
+		return nil, err
+	}
+	return result.CreateEntities, nil
+}
+
 // UpdateEntityInput is the input for updating an entity.
 type UpdateEntityInput struct {
 	Name      *string        `json:"name,omitempty"`
@@ -299,7 +414,7 @@ func (c *Client) UpdateEntity(ctx context.Context, id string, input UpdateEntity
 		mutation UpdateEntity($id: ID!, $input: EntityUpdate!) {
 			updateEntity(id: $id, input: $input) {
 				id type name content summary labels verified confidence
-				source sourcePath metadata createdAt updatedAt accessedAt accessCount
+				source sourcePath metadata createdAt updatedAt modifiedAt accessedAt accessCount
 			}
 		}
 	`
@@ -313,6 +428,63 @@ func (c *Client) UpdateEntity(ctx context.Context, id string, input UpdateEntity
 	return &result.UpdateEntity, nil
 }
 
+// SetAlwaysInContext sets whether an entity is included in every ask context.
+func (c *Client) SetAlwaysInContext(ctx context.Context, id string, enabled bool) (*Entity, error) {
+	const query = `
+		mutation SetAlwaysInContext($id: ID!, $enabled: Boolean!) {
+			setAlwaysInContext(id: $id, enabled: $enabled) {
+				id type name labels alwaysInContext
+			}
+		}
+	`
+
+	var result struct {
+		SetAlwaysInContext Entity `json:"setAlwaysInContext"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"id": id, "enabled": enabled}, &result); err != nil {
+		return nil, err
+	}
+	return &result.SetAlwaysInContext, nil
+}
+
+// PinEntity sets whether an entity is exempt from decay.
+func (c *Client) PinEntity(ctx context.Context, id string, pinned bool) (*Entity, error) {
+	const query = `
+		mutation PinEntity($id: ID!, $pinned: Boolean!) {
+			pinEntity(id: $id, pinned: $pinned) {
+				id type name labels pinned decayWeight
+			}
+		}
+	`
+
+	var result struct {
+		PinEntity Entity `json:"pinEntity"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"id": id, "pinned": pinned}, &result); err != nil {
+		return nil, err
+	}
+	return &result.PinEntity, nil
+}
+
+// MergeEntities merges the entity mergeID into keepID, deleting mergeID.
+func (c *Client) MergeEntities(ctx context.Context, keepID, mergeID string) (*Entity, error) {
+	const query = `
+		mutation MergeEntities($keepId: ID!, $mergeId: ID!) {
+			mergeEntities(keepId: $keepId, mergeId: $mergeId) {
+				id type name labels confidence
+			}
+		}
+	`
+
+	var result struct {
+		MergeEntities Entity `json:"mergeEntities"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"keepId": keepID, "mergeId": mergeID}, &result); err != nil {
+		return nil, err
+	}
+	return &result.MergeEntities, nil
+}
+
 // DeleteEntity deletes an entity by ID.
 func (c *Client) DeleteEntity(ctx context.Context, id string) (bool, error) {
 	const query = `
@@ -330,13 +502,68 @@ func (c *Client) DeleteEntity(ctx context.Context, id string) (bool, error) {
 	return result.DeleteEntity, nil
 }
 
+// DeleteEntitiesByLabel deletes all entities with the label, including their
+// chunks and relations. Returns the number of entities deleted.
+func (c *Client) DeleteEntitiesByLabel(ctx context.Context, label string) (int, error) {
+	const query = `
+		mutation DeleteEntitiesByLabel($label: String!) {
+			deleteEntitiesByLabel(label: $label)
+		}
+	`
+
+	var result struct {
+		DeleteEntitiesByLabel int `json:"deleteEntitiesByLabel"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"label": label}, &result); err != nil {
+		return 0, err
+	}
+	return result.DeleteEntitiesByLabel, nil
+}
+
+// ReindexEntity regenerates an entity's embedding and chunks on the server.
+func (c *Client) ReindexEntity(ctx context.Context, id string) (bool, error) {
+	const query = `
+		mutation ReindexEntity($id: ID!) {
+			reindexEntity(id: $id)
+		}
+	`
+
+	var result struct {
+		ReindexEntity bool `json:"reindexEntity"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"id": id}, &result); err != nil {
+		return false, err
+	}
+	return result.ReindexEntity, nil
+}
+
+// AddEntityAlias adds an alternative name to an entity.
+func (c *Client) AddEntityAlias(ctx context.Context, id, alias string) (*Entity, error) {
+	const query = `
+		mutation AddEntityAlias($id: ID!, $alias: String!) {
+			addEntityAlias(id: $id, alias: $alias) {
+				id type name labels aliases
+			}
+		}
+	`
+
+	var result struct {
+		AddEntityAlias Entity `json:"addEntityAlias"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"id": id, "alias": alias}, &result); err != nil {
+		return nil, err
+	}
+	return &result.AddEntityAlias, nil
+}
+
 // GetEntity retrieves an entity by ID.
 func (c *Client) GetEntity(ctx context.Context, id string) (*Entity, error) {
 	const query = `
 		query GetEntity($id: ID!) {
 			entity(id: $id) {
-				id type name content summary labels verified confidence
-				source sourcePath metadata createdAt updatedAt accessedAt accessCount
+				id type name content summary labels aliases verified confidence
+				source sourcePath metadata createdAt updatedAt modifiedAt accessedAt accessCount
+				decayWeight
 			}
 		}
 	`
@@ -355,8 +582,8 @@ func (c *Client) GetEntityByName(ctx context.Context, name string) (*Entity, err
 	const query = `
 		query GetEntityByName($name: String!) {
 			entityByName(name: $name) {
-				id type name content summary labels verified confidence
-				source sourcePath metadata createdAt updatedAt accessedAt accessCount
+				id type name content summary labels aliases verified confidence
+				source sourcePath metadata createdAt updatedAt modifiedAt accessedAt accessCount
 			}
 		}
 	`
@@ -372,18 +599,20 @@ func (c *Client) GetEntityByName(ctx context.Context, name string) (*Entity, err
 
 // ListEntitiesOptions configures entity listing.
 type ListEntitiesOptions struct {
-	Type   *string
-	Labels []string
-	Limit  *int
+	Type            *string
+	Labels          []string
+	HasMetadataKeys []string
+	Context         string // Only entities in this project context ("" = all)
+	Limit           *int
 }
 
 // ListEntities returns entities with optional filtering.
 func (c *Client) ListEntities(ctx context.Context, opts ListEntitiesOptions) ([]Entity, error) {
 	const query = `
-		query ListEntities($type: String, $labels: [String!], $limit: Int) {
-			entities(type: $type, labels: $labels, limit: $limit) {
+		query ListEntities($type: String, $labels: [String!], $hasMetadataKeys: [String!], $context: String, $limit: Int) {
+			entities(type: $type, labels: $labels, hasMetadataKeys: $hasMetadataKeys, context: $context, limit: $limit) {
 				id type name content summary labels verified confidence
-				source sourcePath metadata createdAt updatedAt accessedAt accessCount
+				source sourcePath metadata createdAt updatedAt modifiedAt accessedAt accessCount context
 			}
 		}
 	`
@@ -395,6 +624,12 @@ func (c *Client) ListEntities(ctx context.Context, opts ListEntitiesOptions) ([]
 	if len(opts.Labels) > 0 {
 		vars["labels"] = opts.Labels
 	}
+	if len(opts.HasMetadataKeys) > 0 {
+		vars["hasMetadataKeys"] = opts.HasMetadataKeys
+	}
+	if opts.Context != "" {
+		vars["context"] = opts.Context
+	}
 	if opts.Limit != nil {
 		vars["limit"] = *opts.Limit
 	}
@@ -408,17 +643,197 @@ func (c *Client) ListEntities(ctx context.Context, opts ListEntitiesOptions) ([]
 	return result.Entities, nil
 }
 
+// ReviewQueueOptions configures GetReviewQueue.
+type ReviewQueueOptions struct {
+	Priority string // "confidence" (lowest first) or "access" (most accessed first); empty uses the server default
+	Sources  []string
+	Types    []string
+	Limit    int
+	Offset   int
+}
+
+// GetReviewQueue returns unverified entities in review order.
+func (c *Client) GetReviewQueue(ctx context.Context, opts ReviewQueueOptions) ([]Entity, error) {
+	const query = `
+		query ReviewQueue($priority: String, $sources: [String!], $types: [String!], $limit: Int, $offset: Int) {
+			reviewQueue(priority: $priority, sources: $sources, types: $types, limit: $limit, offset: $offset) {
+				id type name summary labels verified confidence
+				source sourcePath createdAt updatedAt modifiedAt accessedAt accessCount
+			}
+		}
+	`
+
+	vars := map[string]any{}
+	if opts.Priority != "" {
+		vars["priority"] = opts.Priority
+	}
+	if len(opts.Sources) > 0 {
+		vars["sources"] = opts.Sources
+	}
+	if len(opts.Types) > 0 {
+		vars["types"] = opts.Types
+	}
+	if opts.Limit > 0 {
+		vars["limit"] = opts.Limit
+	}
+	if opts.Offset > 0 {
+		vars["offset"] = opts.Offset
+	}
+
+	var result struct {
+		ReviewQueue []Entity `json:"reviewQueue"`
+	}
+	if err := c.Execute(ctx, query, vars, &result); err != nil {
+		return nil, err
+	}
+	return result.ReviewQueue, nil
+}
+
+// VerifyEntities marks entities as verified and returns the number changed.
+func (c *Client) VerifyEntities(ctx context.Context, ids []string) (int, error) {
+	const query = `
+		mutation VerifyEntities($ids: [ID!]!) {
+			verifyEntities(ids: $ids)
+		}
+	`
+
+	var result struct {
+		VerifyEntities int `json:"verifyEntities"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"ids": ids}, &result); err != nil {
+		return 0, err
+	}
+	return result.VerifyEntities, nil
+}
+
+// EntityPage is one page of changed entities.
+type EntityPage struct {
+	Entities   []Entity `json:"entities"`
+	NextCursor *string  `json:"nextCursor"` // nil when there are no more changes
+}
+
+// ChangedEntities returns entities updated after since, oldest change first.
+// Pass the previous page's NextCursor as cursor ("" for the first page).
+// limit <= 0 uses the server default.
+func (c *Client) ChangedEntities(ctx context.Context, since time.Time, cursor string, limit int) (*EntityPage, error) {
+	const query = `
+		query ChangedEntities($since: String!, $cursor: String, $limit: Int) {
+			changedEntities(since: $since, cursor: $cursor, limit: $limit) {
+				entities {
+					id type name content summary labels verified confidence
+					source sourcePath metadata createdAt updatedAt modifiedAt accessedAt accessCount
+				}
+				nextCursor
+			}
+		}
+	`
+
+	vars := map[string]any{"since": since.Format(time.RFC3339Nano)}
+	if cursor != "" {
+		vars["cursor"] = cursor
+	}
+	if limit > 0 {
+		vars["limit"] = limit
+	}
+
+	var result struct {
+		ChangedEntities EntityPage `json:"changedEntities"`
+	}
+	if err := c.Execute(ctx, query, vars, &result); err != nil {
+		return nil, err
+	}
+	return &result.ChangedEntities, nil
+}
+
 // =============================================================================
 // SEARCH OPERATIONS
 // =============================================================================
 
 // SearchOptions configures search operations.
 type SearchOptions struct {
-	Query        string
-	Labels       []string
-	Types        []string
-	VerifiedOnly *bool
-	Limit        *int
+	Query           string
+	Mode            string // hybrid (default), keyword or vector
+	Labels          []string
+	LabelGroups     [][]string // Labels OR'd within a group, groups AND'd together
+	HasMetadataKeys []string   // Only entities with these metadata keys set
+	Types           []string
+	VerifiedOnly    *bool
+	MinConfidence   *float64 // 0-1: only entities with at least this confidence
+	ExcludeIDs      []string // Entity IDs to leave out of the results
+	Diversity       *float64 // 0-1: prefer varied results over pure relevance
+	Rerank          bool     // Reorder results with the server's rerank model
+	ApplyDecay      bool     // Rank recently accessed entities higher
+	ExpandGraph     bool     // Add entities related to the top results to the answer context
+	Language        string   // Only entities in this language (ISO 639-1 code)
+	Context         string   // Only entities in this project context ("" = all)
+	EfSearch        *int     // Candidates the vector index explores (higher = better recall, slower)
+	OverFetchFactor int      // Candidates per retriever as a multiple of the limit
+	SortBy          string   // Result order: relevance (default), recency or popularity
+	Limit           *int
+}
+
+// toInput converts the options to a GraphQL SearchInput.
+// fallbackQuery is used when no explicit Query is set.
+func (o SearchOptions) toInput(fallbackQuery string) map[string]any {
+	input := map[string]any{"query": o.Query}
+	if o.Query == "" {
+		input["query"] = fallbackQuery
+	}
+	if o.Mode != "" {
+		input["mode"] = o.Mode
+	}
+	if len(o.Labels) > 0 {
+		input["labels"] = o.Labels
+	}
+	if len(o.LabelGroups) > 0 {
+		input["labelGroups"] = o.LabelGroups
+	}
+	if len(o.HasMetadataKeys) > 0 {
+		input["hasMetadataKeys"] = o.HasMetadataKeys
+	}
+	if len(o.Types) > 0 {
+		input["types"] = o.Types
+	}
+	if o.VerifiedOnly != nil {
+		input["verifiedOnly"] = *o.VerifiedOnly
+	}
+	if o.MinConfidence != nil {
+		input["minConfidence"] = *o.MinConfidence
+	}
+	if len(o.ExcludeIDs) > 0 {
+		input["excludeIds"] = o.ExcludeIDs
+	}
+	if o.Diversity != nil {
+		input["diversity"] = *o.Diversity
+	}
+	if o.Rerank {
+		input["rerank"] = true
+	}
+	if o.ApplyDecay {
+		input["applyDecay"] = true
+	}
+	if o.ExpandGraph {
+		input["expandGraph"] = true
+	}
+	if o.Language != "" {
+		input["language"] = o.Language
+	}
+	if o.Context != "" {
+		input["context"] = o.Context
+	}
+	if o.EfSearch != nil {
+		input["efSearch"] = *o.EfSearch
+	}
+	if o.OverFetchFactor > 0 {
+		input["overFetchFactor"] = o.OverFetchFactor
+	}
+	if o.SortBy != "" {
+		input["sortBy"] = o.SortBy
+	}
+	if o.Limit != nil {
+		input["limit"] = *o.Limit
+	}
+	return input
 }
 
 // Search performs hybrid search.
@@ -428,7 +843,7 @@ func (c *Client) Search(ctx context.Context, opts SearchOptions) ([]EntitySearch
 			search(input: $input) {
 				entity {
 					id type name content summary labels verified confidence
-					source sourcePath metadata createdAt updatedAt accessedAt accessCount
+					source sourcePath metadata createdAt updatedAt modifiedAt accessedAt accessCount language pinned context
 				}
 				matchedChunks { content headingPath position }
 				score
@@ -436,62 +851,84 @@ func (c *Client) Search(ctx context.Context, opts SearchOptions) ([]EntitySearch
 		}
 	`
 
-	input := map[string]any{"query": opts.Query}
-	if len(opts.Labels) > 0 {
-		input["labels"] = opts.Labels
+	var result struct {
+		Search []EntitySearchResult `json:"search"`
 	}
-	if len(opts.Types) > 0 {
-		input["types"] = opts.Types
+	if err := c.Execute(ctx, query, map[string]any{"input": opts.toInput("")}, &result); err != nil {
+		return nil, err
 	}
-	if opts.VerifiedOnly != nil {
-		input["verifiedOnly"] = *opts.VerifiedOnly
+	return result.Search, nil
+}
+
+// SearchChunks returns the best matching chunks across all entities,
+// ungrouped, ordered by score.
+func (c *Client) SearchChunks(ctx context.Context, opts SearchOptions) ([]ChunkSearchResult, error) {
+	const query = `
+		query SearchChunks($input: SearchInput!) {
+			searchChunks(input: $input) {
+				entityId entityName content headingPath position score
+			}
+		}
+	`
+
+	var result struct {
+		SearchChunks []ChunkSearchResult `json:"searchChunks"`
 	}
-	if opts.Limit != nil {
-		input["limit"] = *opts.Limit
+	if err := c.Execute(ctx, query, map[string]any{"input": opts.toInput("")}, &result); err != nil {
+		return nil, err
 	}
+	return result.SearchChunks, nil
+}
+
+// SearchFaceted performs hybrid search with results grouped by entity type.
+func (c *Client) SearchFaceted(ctx context.Context, opts SearchOptions) (*FacetedSearchResult, error) {
+	const query = `
+		query SearchFaceted($input: SearchInput!) {
+			searchFaceted(input: $input) {
+				total
+				groups {
+					type count
+					results {
+						entity {
+							id type name content summary labels verified confidence
+							source sourcePath metadata createdAt updatedAt modifiedAt accessedAt accessCount language pinned context
+						}
+						matchedChunks { content headingPath position }
+						score
+					}
+				}
+				labels { label count }
+				sources { source count }
+			}
+		}
+	`
 
 	var result struct {
-		Search []EntitySearchResult `json:"search"`
+		SearchFaceted FacetedSearchResult `json:"searchFaceted"`
 	}
-	if err := c.Execute(ctx, query, map[string]any{"input": input}, &result); err != nil {
+	if err := c.Execute(ctx, query, map[string]any{"input": opts.toInput("")}, &result); err != nil {
 		return nil, err
 	}
-	return result.Search, nil
+	return &result.SearchFaceted, nil
 }
 
 // Ask performs search and synthesizes an answer using LLM.
-func (c *Client) Ask(ctx context.Context, question string, opts *SearchOptions, templateName *string) (string, error) {
+func (c *Client) Ask(ctx context.Context, question string, opts *SearchOptions, templateName *string, override *ModelOverride, prompt *AskPrompt) (string, error) {
 	const query = `
-		query Ask($query: String!, $input: SearchInput, $templateName: String) {
-			ask(query: $query, input: $input, templateName: $templateName)
+		query Ask($query: String!, $input: SearchInput, $templateName: String, $provider: String, $model: String, $systemPrompt: String, $promptPreset: String) {
+			ask(query: $query, input: $input, templateName: $templateName, provider: $provider, model: $model, systemPrompt: $systemPrompt, promptPreset: $promptPreset)
 		}
 	`
 
 	vars := map[string]any{"query": question}
 	if opts != nil {
-		input := map[string]any{}
-		if opts.Query != "" {
-			input["query"] = opts.Query
-		} else {
-			input["query"] = question
-		}
-		if len(opts.Labels) > 0 {
-			input["labels"] = opts.Labels
-		}
-		if len(opts.Types) > 0 {
-			input["types"] = opts.Types
-		}
-		if opts.VerifiedOnly != nil {
-			input["verifiedOnly"] = *opts.VerifiedOnly
-		}
-		if opts.Limit != nil {
-			input["limit"] = *opts.Limit
-		}
-		vars["input"] = input
+		vars["input"] = opts.toInput(question)
 	}
 	if templateName != nil {
 		vars["templateName"] = *templateName
 	}
+	override.addVars(vars)
+	prompt.addVars(vars)
 
 	var result struct {
 		Ask string `json:"ask"`
@@ -502,6 +939,81 @@ func (c *Client) Ask(ctx context.Context, question string, opts *SearchOptions,
 	return result.Ask, nil
 }
 
+// ModelOverride selects an LLM other than the server's configured one for a
+// single ask request. The server must have the provider's API key.
+type ModelOverride struct {
+	Provider string // empty uses the configured provider
+	Model    string
+}
+
+// addVars sets the provider and model variables; a nil override sets none.
+func (o *ModelOverride) addVars(vars map[string]any) {
+	if o == nil {
+		return
+	}
+	if o.Provider != "" {
+		vars["provider"] = o.Provider
+	}
+	if o.Model != "" {
+		vars["model"] = o.Model
+	}
+}
+
+// AskPrompt replaces the server's default system prompt for a single ask
+// request, either with the given text or with a stored preset. Set at most
+// one of the fields.
+type AskPrompt struct {
+	SystemPrompt string
+	Preset       string // name of a template stored as "system-prompt:<name>"
+}
+
+// addVars sets the system prompt variables; a nil prompt sets none.
+func (p *AskPrompt) addVars(vars map[string]any) {
+	if p == nil {
+		return
+	}
+	if p.SystemPrompt != "" {
+		vars["systemPrompt"] = p.SystemPrompt
+	}
+	if p.Preset != "" {
+		vars["promptPreset"] = p.Preset
+	}
+}
+
+// BatchAnswer is the answer to one question of an AskBatch call.
+type BatchAnswer struct {
+	Question     string  `json:"question"`
+	Answer       string  `json:"answer"`
+	InputTokens  int     `json:"inputTokens"`
+	OutputTokens int     `json:"outputTokens"`
+	Error        *string `json:"error,omitempty"`
+}
+
+// AskBatch answers several questions concurrently on the server.
+// Answers are returned in question order; failed questions carry an Error.
+func (c *Client) AskBatch(ctx context.Context, questions []string, opts *SearchOptions) ([]BatchAnswer, error) {
+	const query = `
+		query AskBatch($questions: [String!]!, $input: SearchInput) {
+			askBatch(questions: $questions, input: $input) {
+				question answer inputTokens outputTokens error
+			}
+		}
+	`
+
+	vars := map[string]any{"questions": questions}
+	if opts != nil {
+		vars["input"] = opts.toInput("")
+	}
+
+	var result struct {
+		AskBatch []BatchAnswer `json:"askBatch"`
+	}
+	if err := c.Execute(ctx, query, vars, &result); err != nil {
+		return nil, err
+	}
+	return result.AskBatch, nil
+}
+
 // =============================================================================
 // RELATION OPERATIONS
 // =============================================================================
@@ -531,22 +1043,158 @@ func (c *Client) CreateRelation(ctx context.Context, input CreateRelationInput)
 	return result.CreateRelation, nil
 }
 
-// =============================================================================
-// INGEST OPERATIONS
-// =============================================================================
+// SkippedRow is an edge list row that was not imported.
+type SkippedRow struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
 
-// IngestOptions configures ingestion.
-type IngestOptions struct {
-	// Name is a user-provided identifier for the job (for rerunning)
-	Name *string
-	// Labels to apply to all ingested entities (curated)
-	Labels       []string
-	ExtractGraph *bool
-	DryRun       *bool
-	Recursive    *bool
+// RelationImportReport summarizes a relation import.
+type RelationImportReport struct {
+	Rows       int          `json:"rows"`
+	Created    int          `json:"created"`
+	Unresolved []string     `json:"unresolved"`
+	Skipped    []SkippedRow `json:"skipped"`
 }
 
-// Job represents a background processing job.
+// ImportRelations creates relations from a CSV edge list (from,to,type[,strength]).
+func (c *Client) ImportRelations(ctx context.Context, csv string) (*RelationImportReport, error) {
+	const query = `
+		mutation ImportRelations($csv: String!) {
+			importRelations(csv: $csv) {
+				rows created unresolved
+				skipped { line reason }
+			}
+		}
+	`
+
+	var result struct {
+		ImportRelations RelationImportReport `json:"importRelations"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"csv": csv}, &result); err != nil {
+		return nil, err
+	}
+	return &result.ImportRelations, nil
+}
+
+// PathStep is a single hop along a path between two entities.
+type PathStep struct {
+	FromID  string `json:"fromId"`
+	ToID    string `json:"toId"`
+	RelType string `json:"relType"`
+	Reverse bool   `json:"reverse"`
+}
+
+// FindPaths returns paths between two entities, shortest first.
+// Zero maxDepth or maxPaths uses the server defaults.
+func (c *Client) FindPaths(ctx context.Context, fromID, toID string, maxDepth, maxPaths int) ([][]PathStep, error) {
+	const query = `
+		query AllPaths($fromId: ID!, $toId: ID!, $maxDepth: Int, $maxPaths: Int) {
+			allPaths(fromId: $fromId, toId: $toId, maxDepth: $maxDepth, maxPaths: $maxPaths) {
+				fromId toId relType reverse
+			}
+		}
+	`
+
+	vars := map[string]any{"fromId": fromID, "toId": toID}
+	if maxDepth > 0 {
+		vars["maxDepth"] = maxDepth
+	}
+	if maxPaths > 0 {
+		vars["maxPaths"] = maxPaths
+	}
+
+	var result struct {
+		AllPaths [][]PathStep `json:"allPaths"`
+	}
+	if err := c.Execute(ctx, query, vars, &result); err != nil {
+		return nil, err
+	}
+	return result.AllPaths, nil
+}
+
+// Relation is a directed relation between two entities.
+type Relation struct {
+	ID        string    `json:"id"`
+	FromID    string    `json:"fromId"`
+	ToID      string    `json:"toId"`
+	RelType   string    `json:"relType"`
+	Strength  float64   `json:"strength"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// GraphExport is a set of entities and the relations between them.
+type GraphExport struct {
+	Entities  []Entity   `json:"entities"`
+	Relations []Relation `json:"relations"`
+}
+
+// ExportGraph exports the knowledge graph. With rootID set, only entities
+// within depth hops of the root are exported (zero depth uses the server
+// default); otherwise the whole graph.
+func (c *Client) ExportGraph(ctx context.Context, rootID string, depth int) (*GraphExport, error) {
+	const query = `
+		query ExportGraph($rootId: ID, $depth: Int) {
+			exportGraph(rootId: $rootId, depth: $depth) {
+				entities {
+					id type name content summary labels contentHash
+					verified confidence source sourcePath metadata
+					createdAt updatedAt modifiedAt accessedAt accessCount language
+				}
+				relations {
+					id fromId toId relType strength source createdAt
+				}
+			}
+		}
+	`
+
+	vars := map[string]any{}
+	if rootID != "" {
+		vars["rootId"] = rootID
+	}
+	if depth > 0 {
+		vars["depth"] = depth
+	}
+
+	var result struct {
+		ExportGraph GraphExport `json:"exportGraph"`
+	}
+	if err := c.Execute(ctx, query, vars, &result); err != nil {
+		return nil, err
+	}
+	return &result.ExportGraph, nil
+}
+
+// =============================================================================
+// INGEST OPERATIONS
+// =============================================================================
+
+// IngestOptions configures ingestion.
+type IngestOptions struct {
+	// Name is a user-provided identifier for the job (for rerunning)
+	Name *string
+	// Labels to apply to all ingested entities (curated)
+	Labels        []string
+	ExtractGraph  *bool
+	AutoSummarize *bool
+	DryRun        *bool
+	Recursive     *bool
+	// FailFast fails the job on the first file error instead of continuing
+	FailFast *bool
+	// Prune deletes scraped entities whose file was removed (directory ingests only)
+	Prune *bool
+	// ChunkStrategy splits long content by heading (default), fixed size or sentence
+	ChunkStrategy *string
+	// ChunkSize and ChunkOverlap are in characters
+	ChunkSize    *int
+	ChunkOverlap *int
+	// Concurrency is the number of files processed in parallel (default:
+	// server setting)
+	Concurrency *int
+}
+
+// Job represents a background processing job.
 type Job struct {
 	ID          string        `json:"id"`
 	Type        string        `json:"type"`
@@ -567,7 +1215,7 @@ func (c *Client) IngestFile(ctx context.Context, filePath string, opts *IngestOp
 		mutation IngestFile($filePath: String!, $input: IngestInput) {
 			ingestFile(filePath: $filePath, input: $input) {
 				id type name content summary labels verified confidence
-				source sourcePath metadata createdAt updatedAt accessedAt accessCount
+				source sourcePath metadata createdAt updatedAt modifiedAt accessedAt accessCount
 			}
 		}
 	`
@@ -584,6 +1232,9 @@ func (c *Client) IngestFile(ctx context.Context, filePath string, opts *IngestOp
 		if opts.ExtractGraph != nil {
 			input["extractGraph"] = *opts.ExtractGraph
 		}
+		if opts.AutoSummarize != nil {
+			input["autoSummarize"] = *opts.AutoSummarize
+		}
 		if opts.DryRun != nil {
 			input["dryRun"] = *opts.DryRun
 		}
@@ -602,12 +1253,60 @@ func (c *Client) IngestFile(ctx context.Context, filePath string, opts *IngestOp
 	return &result.IngestFile, nil
 }
 
+// IngestURL fetches a web page on the server and ingests its main content.
+func (c *Client) IngestURL(ctx context.Context, url string, opts *IngestOptions) (*Entity, error) {
+	const query = `
+		mutation IngestURL($url: String!, $input: IngestInput) {
+			ingestURL(url: $url, input: $input) {
+				id type name content summary labels verified confidence
+				source sourcePath metadata createdAt updatedAt modifiedAt accessedAt accessCount
+			}
+		}
+	`
+
+	vars := map[string]any{"url": url}
+	if opts != nil {
+		input := map[string]any{}
+		if len(opts.Labels) > 0 {
+			input["labels"] = opts.Labels
+		}
+		if opts.ExtractGraph != nil {
+			input["extractGraph"] = *opts.ExtractGraph
+		}
+		if opts.AutoSummarize != nil {
+			input["autoSummarize"] = *opts.AutoSummarize
+		}
+		if opts.DryRun != nil {
+			input["dryRun"] = *opts.DryRun
+		}
+		if opts.ChunkStrategy != nil {
+			input["chunkStrategy"] = *opts.ChunkStrategy
+		}
+		if opts.ChunkSize != nil {
+			input["chunkSize"] = *opts.ChunkSize
+		}
+		if opts.ChunkOverlap != nil {
+			input["chunkOverlap"] = *opts.ChunkOverlap
+		}
+		vars["input"] = input
+	}
+
+	var result struct {
+		IngestURL Entity `json:"ingestURL"`
+	}
+	if err := c.Execute(ctx, query, vars, &result); err != nil {
+		return nil, err
+	}
+	return &result.IngestURL, nil
+}
+
 // IngestDirectory ingests all files from a directory.
 func (c *Client) IngestDirectory(ctx context.Context, dirPath string, opts *IngestOptions) (*IngestResult, error) {
 	const query = `
 		mutation IngestDirectory($dirPath: String!, $input: IngestInput) {
 			ingestDirectory(dirPath: $dirPath, input: $input) {
-				filesProcessed entitiesCreated chunksCreated relationsCreated errors
+				filesProcessed entitiesCreated chunksCreated chunksFailed relationsCreated entitiesDeleted errors
+				changes { path action }
 			}
 		}
 	`
@@ -624,12 +1323,33 @@ func (c *Client) IngestDirectory(ctx context.Context, dirPath string, opts *Inge
 		if opts.ExtractGraph != nil {
 			input["extractGraph"] = *opts.ExtractGraph
 		}
+		if opts.AutoSummarize != nil {
+			input["autoSummarize"] = *opts.AutoSummarize
+		}
 		if opts.DryRun != nil {
 			input["dryRun"] = *opts.DryRun
 		}
 		if opts.Recursive != nil {
 			input["recursive"] = *opts.Recursive
 		}
+		if opts.FailFast != nil {
+			input["failFast"] = *opts.FailFast
+		}
+		if opts.ChunkStrategy != nil {
+			input["chunkStrategy"] = *opts.ChunkStrategy
+		}
+		if opts.ChunkSize != nil {
+			input["chunkSize"] = *opts.ChunkSize
+		}
+		if opts.ChunkOverlap != nil {
+			input["chunkOverlap"] = *opts.ChunkOverlap
+		}
+		if opts.Concurrency != nil {
+			input["concurrency"] = *opts.Concurrency
+		}
+		if opts.Prune != nil {
+			input["prune"] = *opts.Prune
+		}
 		vars["input"] = input
 	}
 
@@ -648,7 +1368,7 @@ func (c *Client) IngestDirectoryAsync(ctx context.Context, dirPath string, opts
 		mutation IngestDirectoryAsync($dirPath: String!, $input: IngestInput) {
 			ingestDirectoryAsync(dirPath: $dirPath, input: $input) {
 				id type status progress total startedAt completedAt error
-				result { filesProcessed entitiesCreated chunksCreated relationsCreated errors }
+				result { filesProcessed entitiesCreated chunksCreated chunksFailed relationsCreated entitiesDeleted errors }
 			}
 		}
 	`
@@ -665,12 +1385,33 @@ func (c *Client) IngestDirectoryAsync(ctx context.Context, dirPath string, opts
 		if opts.ExtractGraph != nil {
 			input["extractGraph"] = *opts.ExtractGraph
 		}
+		if opts.AutoSummarize != nil {
+			input["autoSummarize"] = *opts.AutoSummarize
+		}
 		if opts.DryRun != nil {
 			input["dryRun"] = *opts.DryRun
 		}
 		if opts.Recursive != nil {
 			input["recursive"] = *opts.Recursive
 		}
+		if opts.FailFast != nil {
+			input["failFast"] = *opts.FailFast
+		}
+		if opts.ChunkStrategy != nil {
+			input["chunkStrategy"] = *opts.ChunkStrategy
+		}
+		if opts.ChunkSize != nil {
+			input["chunkSize"] = *opts.ChunkSize
+		}
+		if opts.ChunkOverlap != nil {
+			input["chunkOverlap"] = *opts.ChunkOverlap
+		}
+		if opts.Concurrency != nil {
+			input["concurrency"] = *opts.Concurrency
+		}
+		if opts.Prune != nil {
+			input["prune"] = *opts.Prune
+		}
 		vars["input"] = input
 	}
 
@@ -683,6 +1424,64 @@ func (c *Client) IngestDirectoryAsync(ctx context.Context, dirPath string, opts
 	return &result.IngestDirectoryAsync, nil
 }
 
+// GenerateMissingSummaries starts a job that generates summaries for entities without one.
+func (c *Client) GenerateMissingSummaries(ctx context.Context) (*Job, error) {
+	const query = `
+		mutation GenerateMissingSummaries {
+			generateMissingSummaries {
+				id type status progress total startedAt completedAt error
+				result { filesProcessed entitiesCreated chunksCreated chunksFailed relationsCreated entitiesDeleted errors }
+			}
+		}
+	`
+
+	var result struct {
+		GenerateMissingSummaries Job `json:"generateMissingSummaries"`
+	}
+	if err := c.Execute(ctx, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result.GenerateMissingSummaries, nil
+}
+
+// SummarizeEntity generates an LLM summary of an entity's content,
+// replacing its summary.
+func (c *Client) SummarizeEntity(ctx context.Context, id string) (*Entity, error) {
+	const query = `
+		mutation SummarizeEntity($id: ID!) {
+			summarizeEntity(id: $id) {
+				id type name summary
+			}
+		}
+	`
+
+	var result struct {
+		SummarizeEntity Entity `json:"summarizeEntity"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"id": id}, &result); err != nil {
+		return nil, err
+	}
+	return &result.SummarizeEntity, nil
+}
+
+// CancelJob stops a pending or running job. Returns false if no such job
+// is running.
+func (c *Client) CancelJob(ctx context.Context, id string) (bool, error) {
+	const query = `
+		mutation CancelJob($id: ID!) {
+			cancelJob(id: $id)
+		}
+	`
+
+	var result struct {
+		CancelJob bool `json:"cancelJob"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"id": id}, &result); err != nil {
+		return false, err
+	}
+	return result.CancelJob, nil
+}
+
 // CheckHashes queries which files need uploading based on content hashes.
 // Returns paths that are NOT in the database (new or changed content).
 func (c *Client) CheckHashes(ctx context.Context, files []FileHashInput) (*CheckHashesResult, error) {
@@ -714,7 +1513,8 @@ func (c *Client) IngestFiles(ctx context.Context, files []FileContentInput, base
 	const query = `
 		mutation IngestFiles($input: IngestFilesInput!) {
 			ingestFiles(input: $input) {
-				filesProcessed filesSkipped entitiesCreated chunksCreated relationsCreated errors
+				filesProcessed filesSkipped entitiesCreated chunksCreated chunksFailed relationsCreated entitiesDeleted errors
+				changes { path action }
 			}
 		}
 	`
@@ -735,9 +1535,27 @@ func (c *Client) IngestFiles(ctx context.Context, files []FileContentInput, base
 		if opts.ExtractGraph != nil {
 			options["extractGraph"] = *opts.ExtractGraph
 		}
+		if opts.AutoSummarize != nil {
+			options["autoSummarize"] = *opts.AutoSummarize
+		}
 		if opts.DryRun != nil {
 			options["dryRun"] = *opts.DryRun
 		}
+		if opts.FailFast != nil {
+			options["failFast"] = *opts.FailFast
+		}
+		if opts.ChunkStrategy != nil {
+			options["chunkStrategy"] = *opts.ChunkStrategy
+		}
+		if opts.ChunkSize != nil {
+			options["chunkSize"] = *opts.ChunkSize
+		}
+		if opts.ChunkOverlap != nil {
+			options["chunkOverlap"] = *opts.ChunkOverlap
+		}
+		if opts.Concurrency != nil {
+			options["concurrency"] = *opts.Concurrency
+		}
 		input["options"] = options
 	}
 
@@ -759,7 +1577,7 @@ func (c *Client) IngestFilesAsync(ctx context.Context, files []FileContentInput,
 		mutation IngestFilesAsync($input: IngestFilesInput!) {
 			ingestFilesAsync(input: $input) {
 				id type status progress total startedAt completedAt error
-				result { filesProcessed entitiesCreated chunksCreated relationsCreated errors }
+				result { filesProcessed entitiesCreated chunksCreated chunksFailed relationsCreated entitiesDeleted errors }
 			}
 		}
 	`
@@ -780,9 +1598,27 @@ func (c *Client) IngestFilesAsync(ctx context.Context, files []FileContentInput,
 		if opts.ExtractGraph != nil {
 			options["extractGraph"] = *opts.ExtractGraph
 		}
+		if opts.AutoSummarize != nil {
+			options["autoSummarize"] = *opts.AutoSummarize
+		}
 		if opts.DryRun != nil {
 			options["dryRun"] = *opts.DryRun
 		}
+		if opts.FailFast != nil {
+			options["failFast"] = *opts.FailFast
+		}
+		if opts.ChunkStrategy != nil {
+			options["chunkStrategy"] = *opts.ChunkStrategy
+		}
+		if opts.ChunkSize != nil {
+			options["chunkSize"] = *opts.ChunkSize
+		}
+		if opts.ChunkOverlap != nil {
+			options["chunkOverlap"] = *opts.ChunkOverlap
+		}
+		if opts.Concurrency != nil {
+			options["concurrency"] = *opts.Concurrency
+		}
 		input["options"] = options
 	}
 
@@ -795,25 +1631,149 @@ func (c *Client) IngestFilesAsync(ctx context.Context, files []FileContentInput,
 	return &result.IngestFilesAsync, nil
 }
 
+// EntityPreview is the subset of an entity shown when comparing versions.
+type EntityPreview struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Summary       *string  `json:"summary,omitempty"`
+	Labels        []string `json:"labels"`
+	ContentLength int      `json:"contentLength"`
+}
+
+// FileChange compares an existing entity with the entity a file would produce.
+type FileChange struct {
+	Path         string        `json:"path"`
+	EntityID     string        `json:"entityId"`
+	Old          EntityPreview `json:"old"`
+	New          EntityPreview `json:"new"`
+	LinesAdded   int           `json:"linesAdded"`
+	LinesRemoved int           `json:"linesRemoved"`
+}
+
+// IngestDiff describes what ingesting a set of files would change.
+type IngestDiff struct {
+	New       []string     `json:"new"`
+	Changed   []FileChange `json:"changed"`
+	Unchanged []string     `json:"unchanged"`
+}
+
+// DiffFiles compares files with the entities a previous ingest created,
+// without writing anything. baseDir must match the one used for ingestion.
+func (c *Client) DiffFiles(ctx context.Context, files []FileContentInput, baseDir string, labels []string) (*IngestDiff, error) {
+	const query = `
+		query IngestFilesDiff($input: IngestFilesInput!) {
+			ingestFilesDiff(input: $input) {
+				new unchanged
+				changed {
+					path entityId linesAdded linesRemoved
+					old { name type summary labels contentLength }
+					new { name type summary labels contentLength }
+				}
+			}
+		}
+	`
+
+	input := map[string]any{
+		"files":   files,
+		"baseDir": baseDir,
+	}
+	if len(labels) > 0 {
+		input["options"] = map[string]any{"labels": labels}
+	}
+
+	var result struct {
+		IngestFilesDiff IngestDiff `json:"ingestFilesDiff"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"input": input}, &result); err != nil {
+		return nil, err
+	}
+	return &result.IngestFilesDiff, nil
+}
+
+// ChunkOptions overrides chunking parameters for PreviewChunks; nil fields
+// use the server's ingest defaults.
+type ChunkOptions struct {
+	Strategy   *string // heading, fixed or sentence
+	Threshold  *int
+	TargetSize *int
+	MinSize    *int
+	MaxSize    *int
+	Overlap    *int
+}
+
+// PreviewChunks returns the chunks content would be split into on ingest,
+// without storing anything. Empty if the content wouldn't be chunked.
+func (c *Client) PreviewChunks(ctx context.Context, content string, opts ChunkOptions) ([]ChunkPreview, error) {
+	const query = `
+		query PreviewChunks($content: String!, $options: ChunkOptionsInput) {
+			previewChunks(content: $content, options: $options) {
+				position content headingPath length
+			}
+		}
+	`
+
+	options := map[string]any{}
+	for name, v := range map[string]*int{
+		"threshold":  opts.Threshold,
+		"targetSize": opts.TargetSize,
+		"minSize":    opts.MinSize,
+		"maxSize":    opts.MaxSize,
+		"overlap":    opts.Overlap,
+	} {
+		if v != nil {
+			options[name] = *v
+		}
+	}
+	if opts.Strategy != nil {
+		options["strategy"] = *opts.Strategy
+	}
+
+	var result struct {
+		PreviewChunks []ChunkPreview `json:"previewChunks"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"content": content, "options": options}, &result); err != nil {
+		return nil, err
+	}
+	return result.PreviewChunks, nil
+}
+
 // =============================================================================
 // JOB OPERATIONS
 // =============================================================================
 
-// ListJobs returns all background jobs.
-func (c *Client) ListJobs(ctx context.Context) ([]Job, error) {
+// ListJobsOptions configures job listing.
+type ListJobsOptions struct {
+	Status *string
+	Limit  *int
+	Offset *int
+}
+
+// ListJobs returns background jobs, most recent first.
+func (c *Client) ListJobs(ctx context.Context, opts ListJobsOptions) ([]Job, error) {
 	const query = `
-		query ListJobs {
-			jobs {
+		query ListJobs($status: String, $limit: Int, $offset: Int) {
+			jobs(status: $status, limit: $limit, offset: $offset) {
 				id type status progress total startedAt completedAt error
-				result { filesProcessed entitiesCreated chunksCreated relationsCreated errors }
+				result { filesProcessed entitiesCreated chunksCreated chunksFailed relationsCreated entitiesDeleted errors }
 			}
 		}
 	`
 
+	vars := map[string]any{}
+	if opts.Status != nil {
+		vars["status"] = *opts.Status
+	}
+	if opts.Limit != nil {
+		vars["limit"] = *opts.Limit
+	}
+	if opts.Offset != nil {
+		vars["offset"] = *opts.Offset
+	}
+
 	var result struct {
 		Jobs []Job `json:"jobs"`
 	}
-	if err := c.Execute(ctx, query, nil, &result); err != nil {
+	if err := c.Execute(ctx, query, vars, &result); err != nil {
 		return nil, err
 	}
 	return result.Jobs, nil
@@ -825,7 +1785,7 @@ func (c *Client) GetJob(ctx context.Context, id string) (*Job, error) {
 		query GetJob($id: ID!) {
 			job(id: $id) {
 				id type status progress total startedAt completedAt error
-				result { filesProcessed entitiesCreated chunksCreated relationsCreated errors }
+				result { filesProcessed entitiesCreated chunksCreated chunksFailed relationsCreated entitiesDeleted errors }
 			}
 		}
 	`
@@ -960,6 +1920,52 @@ func (c *Client) ListTypes(ctx context.Context) ([]TypeCount, error) {
 	return result.Types, nil
 }
 
+// ConnectedEntity is an entity with its relation count.
+type ConnectedEntity struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Degree int    `json:"degree"`
+}
+
+// RelationTypeCount represents a relation type with its count.
+type RelationTypeCount struct {
+	RelType string `json:"relType"`
+	Count   int    `json:"count"`
+}
+
+// GraphAnalytics describes the overall shape of the knowledge graph.
+type GraphAnalytics struct {
+	Nodes         int                 `json:"nodes"`
+	Edges         int                 `json:"edges"`
+	AverageDegree float64             `json:"averageDegree"`
+	Orphans       int                 `json:"orphans"`
+	TopConnected  []ConnectedEntity   `json:"topConnected"`
+	RelationTypes []RelationTypeCount `json:"relationTypes"`
+	ComputedAt    time.Time           `json:"computedAt"`
+}
+
+// GetGraphAnalytics returns graph-wide statistics.
+func (c *Client) GetGraphAnalytics(ctx context.Context) (*GraphAnalytics, error) {
+	const query = `
+		query GetGraphAnalytics {
+			graphAnalytics {
+				nodes edges averageDegree orphans computedAt
+				topConnected { id name type degree }
+				relationTypes { relType count }
+			}
+		}
+	`
+
+	var result struct {
+		GraphAnalytics GraphAnalytics `json:"graphAnalytics"`
+	}
+	if err := c.Execute(ctx, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result.GraphAnalytics, nil
+}
+
 // =============================================================================
 // USAGE OPERATIONS
 // =============================================================================
@@ -983,12 +1989,47 @@ func (c *Client) GetUsageSummary(ctx context.Context, since string) (*TokenUsage
 	return &result.UsageSummary, nil
 }
 
+// AnswerSourceStats is how often an entity was retrieved for asks.
+type AnswerSourceStats struct {
+	Entity    Entity  `json:"entity"`
+	Retrieved int     `json:"retrieved"`
+	Included  int     `json:"included"`
+	AvgRank   float64 `json:"avgRank"`
+}
+
+// MostUsefulSources returns entities ranked by how often they informed
+// answers since the given datetime. Zero limit uses the server default.
+func (c *Client) MostUsefulSources(ctx context.Context, since string, limit int) ([]AnswerSourceStats, error) {
+	const query = `
+		query MostUsefulSources($since: String!, $limit: Int) {
+			mostUsefulSources(since: $since, limit: $limit) {
+				entity { id type name }
+				retrieved included avgRank
+			}
+		}
+	`
+
+	vars := map[string]any{"since": since}
+	if limit > 0 {
+		vars["limit"] = limit
+	}
+
+	var result struct {
+		MostUsefulSources []AnswerSourceStats `json:"mostUsefulSources"`
+	}
+	if err := c.Execute(ctx, query, vars, &result); err != nil {
+		return nil, err
+	}
+	return result.MostUsefulSources, nil
+}
+
 // GetServerStats returns in-memory runtime statistics.
 func (c *Client) GetServerStats(ctx context.Context) (*ServerStats, error) {
 	const query = `
 		query GetServerStats {
 			serverStats {
 				uptimeSeconds
+				vectorIndex
 				embedding {
 					count totalTimeMs avgTimeMs minTimeMs maxTimeMs
 				}
@@ -1012,6 +2053,18 @@ func (c *Client) GetServerStats(ctx context.Context) (*ServerStats, error) {
 				dbSearch {
 					count totalTimeMs avgTimeMs minTimeMs maxTimeMs
 				}
+				rerank {
+					count totalTimeMs avgTimeMs minTimeMs maxTimeMs
+				}
+				answerCache {
+					hits misses hitRate
+				}
+				embedCache {
+					hits misses hitRate
+				}
+				dbConnections {
+					active maxActive queries connected
+				}
 			}
 		}
 	`
@@ -1026,17 +2079,446 @@ func (c *Client) GetServerStats(ctx context.Context) (*ServerStats, error) {
 }
 
 // =============================================================================
-// STREAMING OPERATIONS
+// MAINTENANCE OPERATIONS
 // =============================================================================
 
-// AskStreamEvent represents a streaming token event.
-type AskStreamEvent struct {
-	Token string  `json:"token"`
-	Done  bool    `json:"done"`
-	Error *string `json:"error,omitempty"`
+// CompactReport lists dangling data found (and removed unless dry-run).
+type CompactReport struct {
+	OrphanedChunks    int      `json:"orphanedChunks"`
+	DanglingRelations int      `json:"danglingRelations"`
+	EmptyEntities     []Entity `json:"emptyEntities"`
+	DryRun            bool     `json:"dryRun"`
 }
 
-// graphql-transport-ws protocol message types
+// Compact removes orphaned chunks, dangling relations and empty entities.
+// With dryRun, it only reports what would be removed.
+func (c *Client) Compact(ctx context.Context, dryRun bool) (*CompactReport, error) {
+	const query = `
+		mutation Compact($dryRun: Boolean) {
+			compact(dryRun: $dryRun) {
+				orphanedChunks danglingRelations dryRun
+				emptyEntities { id type name }
+			}
+		}
+	`
+
+	var result struct {
+		Compact CompactReport `json:"compact"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"dryRun": dryRun}, &result); err != nil {
+		return nil, err
+	}
+	return &result.Compact, nil
+}
+
+// RenameLabel renames a label on all entities and returns the number of
+// entities changed.
+func (c *Client) RenameLabel(ctx context.Context, oldLabel, newLabel string) (int, error) {
+	const query = `
+		mutation RenameLabel($old: String!, $new: String!) {
+			renameLabel(old: $old, new: $new)
+		}
+	`
+
+	var result struct {
+		RenameLabel int `json:"renameLabel"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"old": oldLabel, "new": newLabel}, &result); err != nil {
+		return 0, err
+	}
+	return result.RenameLabel, nil
+}
+
+// NormalizeLabels applies the server's label normalization to existing
+// entities and returns how many were updated.
+func (c *Client) NormalizeLabels(ctx context.Context) (int, error) {
+	const query = `
+		mutation NormalizeLabels {
+			normalizeLabels
+		}
+	`
+
+	var result struct {
+		NormalizeLabels int `json:"normalizeLabels"`
+	}
+	if err := c.Execute(ctx, query, nil, &result); err != nil {
+		return 0, err
+	}
+	return result.NormalizeLabels, nil
+}
+
+// RelinkRelations repairs relations to entities that no longer exist and
+// returns how many were repaired or removed.
+func (c *Client) RelinkRelations(ctx context.Context) (int, error) {
+	const query = `
+		mutation RelinkRelations {
+			relinkRelations
+		}
+	`
+
+	var result struct {
+		RelinkRelations int `json:"relinkRelations"`
+	}
+	if err := c.Execute(ctx, query, nil, &result); err != nil {
+		return 0, err
+	}
+	return result.RelinkRelations, nil
+}
+
+// DetectContradictions compares an entity with its most similar entities and
+// returns how many contradictions were recorded.
+func (c *Client) DetectContradictions(ctx context.Context, entityID string) (int, error) {
+	const query = `
+		mutation DetectContradictions($entityId: ID!) {
+			detectContradictions(entityId: $entityId)
+		}
+	`
+
+	var result struct {
+		DetectContradictions int `json:"detectContradictions"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"entityId": entityID}, &result); err != nil {
+		return 0, err
+	}
+	return result.DetectContradictions, nil
+}
+
+// DetectAllContradictions starts a background job running contradiction
+// detection for every verified entity.
+func (c *Client) DetectAllContradictions(ctx context.Context) (*Job, error) {
+	const query = `
+		mutation DetectAllContradictions {
+			detectAllContradictions {
+				id type status progress total startedAt completedAt error
+				result { filesProcessed entitiesCreated chunksCreated chunksFailed relationsCreated entitiesDeleted errors }
+			}
+		}
+	`
+
+	var result struct {
+		DetectAllContradictions Job `json:"detectAllContradictions"`
+	}
+	if err := c.Execute(ctx, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result.DetectAllContradictions, nil
+}
+
+// DuplicatePair is a pair of near-duplicate entities found by DedupeEntities.
+type DuplicatePair struct {
+	Keep       Entity  `json:"keep"`
+	Merge      Entity  `json:"merge"`
+	Similarity float64 `json:"similarity"`
+	Merged     bool    `json:"merged"`
+	SkipReason *string `json:"skipReason,omitempty"`
+	Error      *string `json:"error,omitempty"`
+}
+
+// DedupeReport is the result of DedupeEntities for one page of entities.
+type DedupeReport struct {
+	Pairs      []DuplicatePair `json:"pairs"`
+	Merged     int             `json:"merged"`
+	Skipped    int             `json:"skipped"`
+	NextCursor *string         `json:"nextCursor"` // nil when all entities were checked
+}
+
+// DedupeEntities finds pairs of entities with an embedding similarity of at
+// least threshold for one page of entities and, with autoMerge, merges those
+// that are safe to merge. Pass the previous report's NextCursor as cursor
+// ("" for the first page). limit <= 0 uses the server default.
+func (c *Client) DedupeEntities(ctx context.Context, threshold float64, autoMerge bool, cursor string, limit int) (*DedupeReport, error) {
+	const query = `
+		mutation DedupeEntities($threshold: Float, $autoMerge: Boolean, $cursor: String, $limit: Int) {
+			dedupeEntities(threshold: $threshold, autoMerge: $autoMerge, cursor: $cursor, limit: $limit) {
+				pairs {
+					keep { id name type verified confidence accessCount }
+					merge { id name type verified confidence accessCount }
+					similarity merged skipReason error
+				}
+				merged skipped nextCursor
+			}
+		}
+	`
+
+	var result struct {
+		DedupeEntities DedupeReport `json:"dedupeEntities"`
+	}
+	vars := map[string]any{"threshold": threshold, "autoMerge": autoMerge}
+	if cursor != "" {
+		vars["cursor"] = cursor
+	}
+	if limit > 0 {
+		vars["limit"] = limit
+	}
+	if err := c.Execute(ctx, query, vars, &result); err != nil {
+		return nil, err
+	}
+	return &result.DedupeEntities, nil
+}
+
+// =============================================================================
+// DECAY OPERATIONS
+// =============================================================================
+
+// TypeDecay is the effective half-life for one entity type.
+type TypeDecay struct {
+	Type         string  `json:"type"`
+	HalfLifeDays float64 `json:"halfLifeDays"`
+	Configured   bool    `json:"configured"`
+}
+
+// DecayConfig describes the server's decay settings.
+type DecayConfig struct {
+	Curve               string      `json:"curve"`
+	DefaultHalfLifeDays float64     `json:"defaultHalfLifeDays"`
+	MinWeight           float64     `json:"minWeight"`
+	IntervalSeconds     int         `json:"intervalSeconds"`
+	Types               []TypeDecay `json:"types"`
+}
+
+// GetDecayConfig returns the effective decay configuration.
+func (c *Client) GetDecayConfig(ctx context.Context) (*DecayConfig, error) {
+	const query = `
+		query GetDecayConfig {
+			decayConfig {
+				curve defaultHalfLifeDays minWeight intervalSeconds
+				types { type halfLifeDays configured }
+			}
+		}
+	`
+
+	var result struct {
+		DecayConfig DecayConfig `json:"decayConfig"`
+	}
+	if err := c.Execute(ctx, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result.DecayConfig, nil
+}
+
+// ApplyDecay lowers the confidence of unverified AI-generated entities by age now.
+func (c *Client) ApplyDecay(ctx context.Context) (bool, error) {
+	const query = `
+		mutation ApplyDecay {
+			applyDecay
+		}
+	`
+
+	var result struct {
+		ApplyDecay bool `json:"applyDecay"`
+	}
+	if err := c.Execute(ctx, query, nil, &result); err != nil {
+		return false, err
+	}
+	return result.ApplyDecay, nil
+}
+
+// =============================================================================
+// CONVERSATION OPERATIONS
+// =============================================================================
+
+// Conversation is a chat session with its messages.
+type Conversation struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	EntityID  *string   `json:"entityId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Messages  []Message `json:"messages"`
+}
+
+// Message is a single chat message.
+type Message struct {
+	ID        string    `json:"id"`
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ChatMessage is a prior turn sent as history with ChatStream.
+type ChatMessage struct {
+	Role    string `json:"role"` // "user" or "assistant"
+	Content string `json:"content"`
+}
+
+// CreateConversation starts a new conversation. An empty title uses the server default.
+func (c *Client) CreateConversation(ctx context.Context, title string) (*Conversation, error) {
+	const query = `
+		mutation CreateConversation($title: String) {
+			createConversation(title: $title) {
+				id title entityId createdAt updatedAt
+			}
+		}
+	`
+
+	vars := map[string]any{}
+	if title != "" {
+		vars["title"] = title
+	}
+
+	var result struct {
+		CreateConversation Conversation `json:"createConversation"`
+	}
+	if err := c.Execute(ctx, query, vars, &result); err != nil {
+		return nil, err
+	}
+	return &result.CreateConversation, nil
+}
+
+// GetConversation returns a conversation with its messages, or nil if not found.
+func (c *Client) GetConversation(ctx context.Context, id string) (*Conversation, error) {
+	const query = `
+		query GetConversation($id: ID!) {
+			conversation(id: $id) {
+				id title entityId createdAt updatedAt
+				messages { id role content createdAt }
+			}
+		}
+	`
+
+	var result struct {
+		Conversation *Conversation `json:"conversation"`
+	}
+	if err := c.Execute(ctx, query, map[string]any{"id": id}, &result); err != nil {
+		return nil, err
+	}
+	return result.Conversation, nil
+}
+
+// MessageSearchResult is a message of a past conversation found by SearchMessages.
+type MessageSearchResult struct {
+	Message           Message `json:"message"`
+	ConversationID    string  `json:"conversationId"`
+	ConversationTitle string  `json:"conversationTitle"`
+	Distance          float64 `json:"distance"`
+}
+
+// SearchMessages finds messages of past conversations similar to query,
+// closest first. A limit of 0 uses the server default.
+func (c *Client) SearchMessages(ctx context.Context, query string, limit int) ([]MessageSearchResult, error) {
+	const gql = `
+		query SearchMessages($query: String!, $limit: Int) {
+			searchMessages(query: $query, limit: $limit) {
+				message { id role content createdAt }
+				conversationId conversationTitle distance
+			}
+		}
+	`
+
+	vars := map[string]any{"query": query}
+	if limit > 0 {
+		vars["limit"] = limit
+	}
+
+	var result struct {
+		SearchMessages []MessageSearchResult `json:"searchMessages"`
+	}
+	if err := c.Execute(ctx, gql, vars, &result); err != nil {
+		return nil, err
+	}
+	return result.SearchMessages, nil
+}
+
+// =============================================================================
+// ARCHIVE OPERATIONS
+// =============================================================================
+
+// ExportArchive streams the whole vault as a newline-delimited JSON archive
+// (see db.Client.ExportAll for the record schema) into w and returns the
+// number of bytes written. Unlike GraphQL requests, the download isn't bound
+// by the client timeout, as large vaults take a while.
+func (c *Client) ExportArchive(ctx context.Context, w io.Writer) (int64, error) {
+	u, err := url.Parse(c.endpoint)
+	if err != nil {
+		return 0, fmt.Errorf("parse endpoint: %w", err)
+	}
+	u.Path = "/archive"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+
+	httpClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if err != nil {
+			return 0, fmt.Errorf("server error: %s", resp.Status)
+		}
+		return 0, fmt.Errorf("server error: %s - %s", resp.Status, string(body))
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("download archive: %w", err)
+	}
+	return n, nil
+}
+
+// ImportStats counts the records of an archive import.
+type ImportStats struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+// ImportArchive uploads a vault archive written by ExportArchive. onConflict
+// decides what happens to records that already exist: "skip" keeps them,
+// "overwrite" replaces them. Like ExportArchive, the upload isn't bound by
+// the client timeout.
+func (c *Client) ImportArchive(ctx context.Context, r io.Reader, onConflict string) (*ImportStats, error) {
+	u, err := url.Parse(c.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse endpoint: %w", err)
+	}
+	u.Path = "/archive"
+	u.RawQuery = url.Values{"on_conflict": {onConflict}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), r)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	httpClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server error: %s - %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var stats ImportStats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	return &stats, nil
+}
+
+// =============================================================================
+// STREAMING OPERATIONS
+// =============================================================================
+
+// AskStreamEvent represents a streaming token event.
+type AskStreamEvent struct {
+	Token string  `json:"token"`
+	Done  bool    `json:"done"`
+	Error *string `json:"error,omitempty"`
+}
+
+// graphql-transport-ws protocol message types
 const (
 	gqlConnectionInit      = "connection_init"
 	gqlConnectionAck       = "connection_ack"
@@ -1067,8 +2549,239 @@ func (c *Client) AskStream(
 	question string,
 	opts *SearchOptions,
 	templateName *string,
+	override *ModelOverride,
+	prompt *AskPrompt,
+	onToken func(token string) error,
+) error {
+	const subscriptionQuery = `
+		subscription AskStream($query: String!, $input: SearchInput, $templateName: String, $provider: String, $model: String, $systemPrompt: String, $promptPreset: String) {
+			askStream(query: $query, input: $input, templateName: $templateName, provider: $provider, model: $model, systemPrompt: $systemPrompt, promptPreset: $promptPreset) {
+				token
+				done
+				error
+			}
+		}
+	`
+
+	vars := map[string]any{"query": question}
+	if opts != nil {
+		vars["input"] = opts.toInput(question)
+	}
+	if templateName != nil {
+		vars["templateName"] = *templateName
+	}
+	override.addVars(vars)
+	prompt.addVars(vars)
+
+	return c.subscribe(ctx, subscriptionQuery, vars, func(payload json.RawMessage) (bool, error) {
+		var data struct {
+			Data struct {
+				AskStream AskStreamEvent `json:"askStream"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(payload, &data); err != nil {
+			return false, fmt.Errorf("unmarshal next payload: %w", err)
+		}
+
+		event := data.Data.AskStream
+
+		// Check for error in event
+		if event.Error != nil {
+			return false, fmt.Errorf("stream error: %s", *event.Error)
+		}
+
+		// Send token to callback (if not empty)
+		if event.Token != "" {
+			if err := onToken(event.Token); err != nil {
+				return false, err
+			}
+		}
+
+		return event.Done, nil
+	})
+}
+
+// ChatStream sends a message in a conversation and streams the answer token by
+// token. history holds the prior turns used as context; the server persists
+// both the message and the answer. Return an error from onToken to abort.
+func (c *Client) ChatStream(
+	ctx context.Context,
+	conversationID string,
+	message string,
+	history []ChatMessage,
+	opts *SearchOptions,
 	onToken func(token string) error,
 ) error {
+	const subscriptionQuery = `
+		subscription ChatStream($conversationId: ID!, $message: String!, $history: [ChatMessageInput!]!, $input: SearchInput) {
+			chatStream(conversationId: $conversationId, message: $message, history: $history, input: $input) {
+				token
+				done
+				error
+			}
+		}
+	`
+
+	if history == nil {
+		history = []ChatMessage{}
+	}
+	vars := map[string]any{
+		"conversationId": conversationID,
+		"message":        message,
+		"history":        history,
+	}
+	if opts != nil {
+		vars["input"] = opts.toInput(message)
+	}
+
+	return c.subscribe(ctx, subscriptionQuery, vars, func(payload json.RawMessage) (bool, error) {
+		var data struct {
+			Data struct {
+				ChatStream AskStreamEvent `json:"chatStream"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(payload, &data); err != nil {
+			return false, fmt.Errorf("unmarshal next payload: %w", err)
+		}
+
+		event := data.Data.ChatStream
+		if event.Error != nil {
+			return false, fmt.Errorf("stream error: %s", *event.Error)
+		}
+		if event.Token != "" {
+			if err := onToken(event.Token); err != nil {
+				return false, err
+			}
+		}
+		return event.Done, nil
+	})
+}
+
+// SearchStream performs hybrid search and passes each result to onResult in
+// rank order as the server sends it. Return an error from onResult to abort.
+func (c *Client) SearchStream(ctx context.Context, opts SearchOptions, onResult func(EntitySearchResult) error) error {
+	const subscriptionQuery = `
+		subscription SearchStream($input: SearchInput!) {
+			searchStream(input: $input) {
+				result {
+					entity {
+						id type name content summary labels verified confidence
+						source sourcePath metadata createdAt updatedAt modifiedAt accessedAt accessCount language
+					}
+					matchedChunks { content headingPath position }
+					score
+				}
+				done
+				error
+			}
+		}
+	`
+
+	return c.subscribe(ctx, subscriptionQuery, map[string]any{"input": opts.toInput("")}, func(payload json.RawMessage) (bool, error) {
+		var data struct {
+			Data struct {
+				SearchStream struct {
+					Result *EntitySearchResult `json:"result"`
+					Done   bool                `json:"done"`
+					Error  *string             `json:"error"`
+				} `json:"searchStream"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(payload, &data); err != nil {
+			return false, fmt.Errorf("unmarshal next payload: %w", err)
+		}
+
+		event := data.Data.SearchStream
+		if event.Error != nil {
+			return false, fmt.Errorf("stream error: %s", *event.Error)
+		}
+		if event.Result != nil {
+			if err := onResult(*event.Result); err != nil {
+				return false, err
+			}
+		}
+		return event.Done, nil
+	})
+}
+
+// EntityChangeEvent is a live entity create, update or delete.
+type EntityChangeEvent struct {
+	Type   string `json:"type"` // created, updated or deleted
+	Entity Entity `json:"entity"`
+}
+
+// WatchEntityChanges streams entity changes until ctx is canceled or onEvent
+// returns an error. If labels is set, only entities with any of them are sent.
+func (c *Client) WatchEntityChanges(ctx context.Context, labels []string, onEvent func(EntityChangeEvent) error) error {
+	const subscriptionQuery = `
+		subscription EntityChanges($labels: [String!]) {
+			entityChanges(labels: $labels) {
+				type
+				entity {
+					id type name summary labels verified confidence
+					source sourcePath createdAt updatedAt modifiedAt accessedAt accessCount
+				}
+			}
+		}
+	`
+
+	vars := map[string]any{}
+	if len(labels) > 0 {
+		vars["labels"] = labels
+	}
+
+	return c.subscribe(ctx, subscriptionQuery, vars, func(payload json.RawMessage) (bool, error) {
+		var data struct {
+			Data struct {
+				EntityChanges EntityChangeEvent `json:"entityChanges"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(payload, &data); err != nil {
+			return false, fmt.Errorf("unmarshal next payload: %w", err)
+		}
+		return false, onEvent(data.Data.EntityChanges)
+	})
+}
+
+// JobProgressEvent is a live update of a job's progress.
+type JobProgressEvent struct {
+	JobID    string  `json:"jobId"`
+	Progress int     `json:"progress"`
+	Total    int     `json:"total"`
+	Status   string  `json:"status"`
+	Error    *string `json:"error,omitempty"`
+}
+
+// WatchJobProgress streams a job's progress, starting with its current
+// state, until the job finishes, ctx is canceled or onEvent returns an
+// error. Fails if the job is unknown.
+func (c *Client) WatchJobProgress(ctx context.Context, id string, onEvent func(JobProgressEvent) error) error {
+	const subscriptionQuery = `
+		subscription JobProgress($id: ID!) {
+			jobProgress(id: $id) {
+				jobId progress total status error
+			}
+		}
+	`
+
+	return c.subscribe(ctx, subscriptionQuery, map[string]any{"id": id}, func(payload json.RawMessage) (bool, error) {
+		var data struct {
+			Data struct {
+				JobProgress JobProgressEvent `json:"jobProgress"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(payload, &data); err != nil {
+			return false, fmt.Errorf("unmarshal next payload: %w", err)
+		}
+		return false, onEvent(data.Data.JobProgress)
+	})
+}
+
+// subscribe runs a GraphQL subscription over graphql-transport-ws.
+// onNext receives the payload of each "next" message and returns true once the
+// stream is done. Returns when the server completes, onNext is done or fails,
+// or ctx is canceled.
+func (c *Client) subscribe(ctx context.Context, query string, vars map[string]any, onNext func(payload json.RawMessage) (bool, error)) error {
 	// Convert HTTP endpoint to WebSocket endpoint
 	wsEndpoint := c.endpoint
 	wsEndpoint = strings.Replace(wsEndpoint, "http://", "ws://", 1)
@@ -1091,16 +2804,32 @@ func (c *Client) AskStream(
 	}
 	defer conn.Close()
 
-	// Track connection state for proper cleanup
+	// Track connection state for proper cleanup. Once subscribed, the
+	// subscription is completed and the socket closed with a close frame, so
+	// the server stops streaming instead of seeing a dropped connection.
 	var mu sync.Mutex
 	closed := false
+	subscriptionID := ""
 	closeConn := func() {
 		mu.Lock()
 		defer mu.Unlock()
-		if !closed {
-			closed = true
-			conn.Close()
+		if closed {
+			return
 		}
+		closed = true
+		if subscriptionID != "" {
+			if err := conn.SetWriteDeadline(time.Now().Add(time.Second)); err != nil {
+				slog.Debug("set websocket write deadline", "error", err)
+			}
+			if err := conn.WriteJSON(wsMessage{ID: subscriptionID, Type: gqlComplete}); err != nil {
+				slog.Debug("send subscription complete", "error", err)
+			}
+			closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			if err := conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second)); err != nil {
+				slog.Debug("send websocket close", "error", err)
+			}
+		}
+		conn.Close()
 	}
 	defer closeConn()
 
@@ -1119,60 +2848,26 @@ func (c *Client) AskStream(
 		return fmt.Errorf("expected connection_ack, got %s", ackMsg.Type)
 	}
 
-	// Build subscription query
-	const subscriptionQuery = `
-		subscription AskStream($query: String!, $input: SearchInput, $templateName: String) {
-			askStream(query: $query, input: $input, templateName: $templateName) {
-				token
-				done
-				error
-			}
-		}
-	`
-
-	vars := map[string]any{"query": question}
-	if opts != nil {
-		input := map[string]any{}
-		if opts.Query != "" {
-			input["query"] = opts.Query
-		} else {
-			input["query"] = question
-		}
-		if len(opts.Labels) > 0 {
-			input["labels"] = opts.Labels
-		}
-		if len(opts.Types) > 0 {
-			input["types"] = opts.Types
-		}
-		if opts.VerifiedOnly != nil {
-			input["verifiedOnly"] = *opts.VerifiedOnly
-		}
-		if opts.Limit != nil {
-			input["limit"] = *opts.Limit
-		}
-		vars["input"] = input
-	}
-	if templateName != nil {
-		vars["templateName"] = *templateName
-	}
-
 	// Send subscribe message
-	subscriptionID := uuid.New().String()
+	id := uuid.New().String()
 	payload, err := json.Marshal(wsSubscribePayload{
-		Query:     subscriptionQuery,
+		Query:     query,
 		Variables: vars,
 	})
 	if err != nil {
 		return fmt.Errorf("marshal subscribe payload: %w", err)
 	}
 	subMsg := wsMessage{
-		ID:      subscriptionID,
+		ID:      id,
 		Type:    gqlSubscribe,
 		Payload: payload,
 	}
 	if err := conn.WriteJSON(subMsg); err != nil {
 		return fmt.Errorf("send subscribe: %w", err)
 	}
+	mu.Lock()
+	subscriptionID = id
+	mu.Unlock()
 
 	// Handle context cancellation in a separate goroutine
 	done := make(chan struct{})
@@ -1198,32 +2893,11 @@ func (c *Client) AskStream(
 
 		switch msg.Type {
 		case gqlNext:
-			// Parse the data payload
-			var data struct {
-				Data struct {
-					AskStream AskStreamEvent `json:"askStream"`
-				} `json:"data"`
+			finished, err := onNext(msg.Payload)
+			if err != nil {
+				return err
 			}
-			if err := json.Unmarshal(msg.Payload, &data); err != nil {
-				return fmt.Errorf("unmarshal next payload: %w", err)
-			}
-
-			event := data.Data.AskStream
-
-			// Check for error in event
-			if event.Error != nil {
-				return fmt.Errorf("stream error: %s", *event.Error)
-			}
-
-			// Send token to callback (if not empty)
-			if event.Token != "" {
-				if err := onToken(event.Token); err != nil {
-					return err
-				}
-			}
-
-			// Check if done
-			if event.Done {
+			if finished {
 				return nil
 			}
 
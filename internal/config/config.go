@@ -19,6 +19,12 @@ const (
 	ProviderBedrock   LLMProvider = "bedrock"
 )
 
+// ModelRef names an LLM by provider and model.
+type ModelRef struct {
+	Provider LLMProvider
+	Model    string
+}
+
 // Config holds all configuration values.
 type Config struct {
 	// SurrealDB connection
@@ -28,20 +34,36 @@ type Config struct {
 	SurrealDBUser      string
 	SurrealDBPass      string
 	SurrealDBAuthLevel string
+	SurrealDBPoolSize  int // WebSocket connections queries are spread over
 
 	// Embedding configuration
 	EmbedProvider            LLMProvider
 	EmbedModel               string
 	EmbedDimension           int
+	HNSWDistance             string // COSINE, EUCLIDEAN or MANHATTAN; changing it requires reindexing
 	BedrockEmbedModelProvider string // e.g., "amazon" for Titan, "cohere" for Cohere
+	EmbedBatchSize           int    // Texts per embedding request when embedding chunks (0 = all at once)
+	EmbedBatchRetries        int    // Retries of an embedding request failing with a transient error
+	EmbedRetryBackoffMS      int    // Milliseconds before the first retry, doubled for each further retry
+	EmbedTimeout             int    // Seconds per embedding request (0 = no limit)
+	EmbedCacheSize           int    // Recently embedded texts kept to skip re-embedding (0 disables)
+	EmbedConcurrency         int    // Parallel requests per batch for providers without batch input (Ollama)
+	EmbedTextLimit           int    // Characters of an entity's own embedding text; chunks are embedded in full (0 = no cap)
 
 	// LLM configuration (for ask, extract-graph, render)
 	LLMProvider LLMProvider
 	LLMModel    string
+	LLMTimeout  int // Seconds per non-streaming generation (0 = no limit)
+	LLMTemperature float64 // Default sampling temperature (negative = provider default)
+	LLMMaxTokens   int     // Default cap on generated tokens
+	RerankModel string // Ollama model scoring results for search rerank (empty disables)
+	LLMFallback []ModelRef // Providers tried in order when the configured one fails
+	ModelPricingPath string // JSON file of per-model token prices for cost tracking (empty disables)
 
 	// Provider-specific settings
 	OllamaHost           string
 	OpenAIAPIKey         string
+	OpenAIBaseURL        string // Optional, for OpenAI-compatible endpoints
 	AnthropicAPIKey      string
 	BedrockModelProvider string // e.g., "anthropic" for inference profiles
 
@@ -50,7 +72,50 @@ type Config struct {
 	LogLevel slog.Level
 
 	// Server settings
-	IngestConcurrency int
+	IngestConcurrency        int
+	MaxConcurrentExtractions int // LLM graph extractions running at once across all jobs (0 = unlimited)
+	FuzzyNameThreshold       float64 // 0-1: name similarity needed to link relations to a misspelled entity name (0 = off, the default)
+	MaxNeighborDepth         int     // Hops the neighbors query follows at most
+	MetricsSnapshotInterval  int // Seconds between persisted metrics snapshots (0 disables)
+	MetricsRuntime           bool // Include Go runtime metrics in /metrics
+	IndexWaitTimeout         int // Seconds to block startup until the vector index answers (0 checks in background)
+	EntityContentLimit       int // Content bytes above which chunked entities keep content only in chunks (0 = always store)
+	JobWebhookURL            string // URL notified with a JSON POST when a job finishes (empty disables)
+	JobWebhookSecret         string // Key of the HMAC-SHA256 signature header on webhook posts (empty = unsigned)
+	IngestMaxFileBytes       int    // Files above this size are skipped by ingest (0 = no limit)
+	IngestSkipBinaryCheck    bool   // Ingest files without checking they are valid text
+
+	// Label normalization, language detection and confidence defaults on write
+	NormalizeLabels    bool               // Trim, lowercase and de-alias labels before storing them
+	LabelAliases       map[string]string  // Alias → canonical label, e.g. "k8s=kubernetes"
+	DetectLanguage     bool               // Detect and store the language of entity content
+	ConfidenceDefaults map[string]float64 // Confidence of new entities by source, e.g. "manual=0.9,ai_generated=0.6"
+
+	// Ask context assembly
+	ContextMode      string // "chunks" (matched chunks) or "content" (entity content)
+	ContextMaxChunks int    // Matched chunks used per entity (0 = all)
+	ContextMaxChars  int    // Content characters per entity (0 = unlimited)
+	ContextMaxAlways int    // Entities flagged always-in-context added to every ask (0 = none)
+	ContextMaxTotal  int    // Characters of the whole ask context, related entities included (0 = unlimited)
+	AnswerCacheTTL   int    // Seconds to cache answers of identical asks; any entity change clears the cache (0 disables)
+
+	// Token budget
+	MaxTokensPerConversation int // Tokens LLM answers may use per conversation (0 = unlimited)
+
+	// Rate limiting of the GraphQL endpoint, per client IP
+	RateLimit             int  // Queries and mutations per minute (0 = unlimited)
+	SubscriptionRateLimit int  // WebSocket subscription upgrades per minute (0 = unlimited)
+	TrustProxy            bool // Identify clients by X-Forwarded-For (only behind a reverse proxy)
+	HTTPCompress          bool // Gzip GraphQL responses for clients accepting it
+
+	// Decay of entity weight by time since last access
+	DecayCurve            string             // "exponential" or "linear"
+	DecayHalfLifeDays     float64            // Default half-life for unlisted types
+	DecayTypeHalfLifeDays map[string]float64 // Per-type half-lives, e.g. "task=7,concept=365"
+	DecayMinWeight        float64            // Floor for the decay weight
+	DecayInterval         int                // Seconds between confidence decay runs (0 disables)
+	DecayAIConfidenceDays float64            // Half-life of unverified AI entities' confidence (0 disables)
+	DecayMinConfidence    float64            // Floor for downgraded confidence
 }
 
 // Load reads configuration from environment variables.
@@ -63,20 +128,36 @@ func Load() Config {
 		SurrealDBUser:      getEnv("SURREALDB_USER", "root"),
 		SurrealDBPass:      getEnv("SURREALDB_PASS", "root"),
 		SurrealDBAuthLevel: getEnv("SURREALDB_AUTH_LEVEL", "root"),
+		SurrealDBPoolSize:  getEnvInt("KNOWHOW_DB_POOL_SIZE", 1),
 
 		// Embedding (default to local Ollama with bge-m3)
 		EmbedProvider:            LLMProvider(getEnv("KNOWHOW_EMBED_PROVIDER", "ollama")),
 		EmbedModel:               getEnv("KNOWHOW_EMBED_MODEL", "bge-m3"),
 		EmbedDimension:           getEnvInt("KNOWHOW_EMBED_DIMENSION", 1024),
+		HNSWDistance:             strings.ToUpper(getEnv("KNOWHOW_HNSW_DISTANCE", "COSINE")),
 		BedrockEmbedModelProvider: getEnv("KNOWHOW_BEDROCK_EMBED_MODEL_PROVIDER", ""),
+		EmbedBatchSize:           getEnvInt("KNOWHOW_EMBED_BATCH_SIZE", 32),
+		EmbedBatchRetries:        getEnvInt("KNOWHOW_EMBED_BATCH_RETRIES", 2),
+		EmbedRetryBackoffMS:      getEnvInt("KNOWHOW_EMBED_RETRY_BACKOFF_MS", 500),
+		EmbedTimeout:             getEnvInt("KNOWHOW_EMBED_TIMEOUT", 60),
+		EmbedCacheSize:           getEnvInt("KNOWHOW_EMBED_CACHE_SIZE", 0),
+		EmbedConcurrency:         getEnvInt("KNOWHOW_EMBED_CONCURRENCY", 4),
+		EmbedTextLimit:           getEnvInt("KNOWHOW_EMBED_TEXT_LIMIT", 8000),
 
 		// LLM (default to local Ollama)
 		LLMProvider: LLMProvider(getEnv("KNOWHOW_LLM_PROVIDER", "ollama")),
 		LLMModel:    getEnv("KNOWHOW_LLM_MODEL", "llama3.2"),
+		LLMTimeout:  getEnvInt("KNOWHOW_LLM_TIMEOUT", 300),
+		LLMTemperature: getEnvFloat("KNOWHOW_LLM_TEMPERATURE", -1),
+		LLMMaxTokens:   getEnvInt("KNOWHOW_LLM_MAX_TOKENS", 8192),
+		RerankModel: getEnv("KNOWHOW_RERANK_MODEL", ""),
+		LLMFallback: parseModelRefs("KNOWHOW_LLM_FALLBACK", getEnv("KNOWHOW_LLM_FALLBACK", "")),
+		ModelPricingPath: getEnv("KNOWHOW_MODEL_PRICING", ""),
 
 		// Provider hosts/keys
 		OllamaHost:           getEnv("OLLAMA_HOST", "http://localhost:11434"),
 		OpenAIAPIKey:         getEnv("OPENAI_API_KEY", ""),
+		OpenAIBaseURL:        getEnv("OPENAI_BASE_URL", ""),
 		AnthropicAPIKey:      getEnv("ANTHROPIC_API_KEY", ""),
 		BedrockModelProvider: getEnv("KNOWHOW_BEDROCK_MODEL_PROVIDER", ""),
 
@@ -85,7 +166,50 @@ func Load() Config {
 		LogLevel: parseLogLevel(getEnv("KNOWHOW_LOG_LEVEL", "INFO")),
 
 		// Server settings
-		IngestConcurrency: getEnvInt("KNOWHOW_INGEST_CONCURRENCY", 4),
+		IngestConcurrency:        getEnvInt("KNOWHOW_INGEST_CONCURRENCY", 4),
+		MaxConcurrentExtractions: getEnvInt("KNOWHOW_MAX_CONCURRENT_EXTRACTIONS", 4),
+		FuzzyNameThreshold:       getEnvFloat("KNOWHOW_FUZZY_NAME_THRESHOLD", 0),
+		MaxNeighborDepth:         getEnvInt("KNOWHOW_MAX_NEIGHBOR_DEPTH", 3),
+		MetricsSnapshotInterval:  getEnvInt("KNOWHOW_METRICS_SNAPSHOT_INTERVAL", 300),
+		MetricsRuntime:           getEnvBool("KNOWHOW_METRICS_RUNTIME", false),
+		IndexWaitTimeout:         getEnvInt("KNOWHOW_INDEX_WAIT_TIMEOUT", 0),
+		EntityContentLimit:       getEnvInt("KNOWHOW_ENTITY_CONTENT_LIMIT", 0),
+		JobWebhookURL:            getEnv("KNOWHOW_JOB_WEBHOOK_URL", ""),
+		JobWebhookSecret:         getEnv("KNOWHOW_JOB_WEBHOOK_SECRET", ""),
+		IngestMaxFileBytes:       getEnvInt("KNOWHOW_INGEST_MAX_FILE_BYTES", 10<<20),
+		IngestSkipBinaryCheck:    getEnvBool("KNOWHOW_INGEST_SKIP_BINARY_CHECK", false),
+
+		// Labels, language and confidence
+		NormalizeLabels:    getEnvBool("KNOWHOW_NORMALIZE_LABELS", false),
+		LabelAliases:       parseAliases("KNOWHOW_LABEL_ALIASES", getEnv("KNOWHOW_LABEL_ALIASES", "")),
+		DetectLanguage:     getEnvBool("KNOWHOW_DETECT_LANGUAGE", true),
+		ConfidenceDefaults: parseConfidenceDefaults("KNOWHOW_CONFIDENCE_DEFAULTS", getEnv("KNOWHOW_CONFIDENCE_DEFAULTS", "")),
+
+		// Ask context assembly
+		ContextMode:      getEnv("KNOWHOW_CONTEXT_MODE", "chunks"),
+		ContextMaxChunks: getEnvInt("KNOWHOW_CONTEXT_MAX_CHUNKS", 3),
+		ContextMaxChars:  getEnvInt("KNOWHOW_CONTEXT_MAX_CHARS", 2000),
+		ContextMaxAlways: getEnvInt("KNOWHOW_CONTEXT_MAX_ALWAYS", 5),
+		ContextMaxTotal:  getEnvInt("KNOWHOW_CONTEXT_MAX_TOTAL_CHARS", 32000),
+		AnswerCacheTTL:   getEnvInt("KNOWHOW_ANSWER_CACHE_TTL", 0),
+
+		// Token budget
+		MaxTokensPerConversation: getEnvInt("KNOWHOW_MAX_TOKENS_PER_CONVERSATION", 0),
+
+		// Rate limiting
+		RateLimit:             getEnvInt("KNOWHOW_RATE_LIMIT", 0),
+		SubscriptionRateLimit: getEnvInt("KNOWHOW_SUBSCRIPTION_RATE_LIMIT", 0),
+		TrustProxy:            getEnvBool("KNOWHOW_TRUST_PROXY", false),
+		HTTPCompress:          getEnvBool("KNOWHOW_HTTP_COMPRESS", true),
+
+		// Decay
+		DecayCurve:            getEnv("KNOWHOW_DECAY_CURVE", "exponential"),
+		DecayHalfLifeDays:     getEnvFloat("KNOWHOW_DECAY_HALF_LIFE_DAYS", 90),
+		DecayTypeHalfLifeDays: parseTypeFloats("KNOWHOW_DECAY_TYPE_HALF_LIVES", getEnv("KNOWHOW_DECAY_TYPE_HALF_LIVES", "")),
+		DecayMinWeight:        getEnvFloat("KNOWHOW_DECAY_MIN_WEIGHT", 0.1),
+		DecayInterval:         getEnvInt("KNOWHOW_DECAY_INTERVAL", 0),
+		DecayAIConfidenceDays: getEnvFloat("KNOWHOW_DECAY_AI_CONFIDENCE_DAYS", 0),
+		DecayMinConfidence:    getEnvFloat("KNOWHOW_DECAY_MIN_CONFIDENCE", 0.2),
 	}
 }
 
@@ -108,6 +232,123 @@ func getEnvInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
+func getEnvFloat(key string, defaultVal float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			slog.Warn("invalid float env var, using default", "key", key, "value", val, "default", defaultVal, "error", err)
+			return defaultVal
+		}
+		return f
+	}
+	return defaultVal
+}
+
+// parseTypeFloats parses "type=value,type=value" pairs. Invalid pairs are skipped.
+func parseTypeFloats(key, s string) map[string]float64 {
+	result := make(map[string]float64)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			slog.Warn("invalid type=value pair in env var, skipping", "key", key, "pair", pair)
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil {
+			slog.Warn("invalid value in env var, skipping", "key", key, "pair", pair, "error", err)
+			continue
+		}
+		result[strings.TrimSpace(name)] = f
+	}
+	return result
+}
+
+// defaultConfidence is the confidence of new entities by source: entities a
+// user created are trusted most, LLM-generated ones least.
+var defaultConfidence = map[string]float64{
+	"manual":       0.9,
+	"mcp":          0.8,
+	"scrape":       0.8,
+	"ai_generated": 0.6,
+}
+
+// parseConfidenceDefaults parses "source=confidence,..." pairs on top of
+// defaultConfidence. Confidences outside 0-1 are skipped.
+func parseConfidenceDefaults(key, s string) map[string]float64 {
+	result := make(map[string]float64, len(defaultConfidence))
+	for source, confidence := range defaultConfidence {
+		result[source] = confidence
+	}
+	for source, confidence := range parseTypeFloats(key, s) {
+		if confidence < 0 || confidence > 1 {
+			slog.Warn("confidence must be between 0 and 1, skipping", "key", key, "source", source, "confidence", confidence)
+			continue
+		}
+		result[source] = confidence
+	}
+	return result
+}
+
+func getEnvBool(key string, defaultVal bool) bool {
+	if val := os.Getenv(key); val != "" {
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			slog.Warn("invalid boolean env var, using default", "key", key, "value", val, "default", defaultVal, "error", err)
+			return defaultVal
+		}
+		return b
+	}
+	return defaultVal
+}
+
+// parseModelRefs parses "provider:model,..." pairs, e.g.
+// "openai:gpt-4o-mini,ollama:llama3". Unknown providers are skipped.
+func parseModelRefs(key, s string) []ModelRef {
+	var result []ModelRef
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		provider, model, ok := strings.Cut(pair, ":")
+		model = strings.TrimSpace(model)
+		if !ok || model == "" {
+			slog.Warn("invalid provider:model pair in env var, skipping", "key", key, "pair", pair)
+			continue
+		}
+		p := LLMProvider(strings.ToLower(strings.TrimSpace(provider)))
+		switch p {
+		case ProviderOllama, ProviderOpenAI, ProviderAnthropic, ProviderBedrock:
+			result = append(result, ModelRef{Provider: p, Model: model})
+		default:
+			slog.Warn("unknown LLM provider in env var, skipping", "key", key, "provider", p)
+		}
+	}
+	return result
+}
+
+// parseAliases parses "alias=canonical,..." pairs.
+func parseAliases(key, s string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		alias, canonical, ok := strings.Cut(pair, "=")
+		if !ok {
+			slog.Warn("invalid alias=label pair in env var, skipping", "key", key, "pair", pair)
+			continue
+		}
+		result[strings.TrimSpace(alias)] = strings.TrimSpace(canonical)
+	}
+	return result
+}
+
 func parseLogLevel(s string) slog.Level {
 	switch strings.ToUpper(s) {
 	case "DEBUG":
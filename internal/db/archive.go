@@ -0,0 +1,550 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/raphaelgruber/memcp-go/internal/metrics"
+	"github.com/raphaelgruber/memcp-go/internal/models"
+	surrealmodels "github.com/surrealdb/surrealdb.go/pkg/models"
+)
+
+// A vault archive is newline-delimited JSON, one record per line. Every
+// record has a "kind"; the first line is the header, followed by all
+// templates, entities, chunks, relations and contradictions in that order,
+// so a record's references always point to records above it:
+//
+//	{"kind":"header","version":1,"embed_dimension":1024,"exported_at":"..."}
+//	{"kind":"template","id":"...","name":"...","content":"...",...}
+//	{"kind":"entity","id":"...","type":"...","name":"...","embedding":[...],...}
+//	{"kind":"chunk","id":"...","entity":"<entity id>","content":"...","embedding":[...],...}
+//	{"kind":"relation","id":"...","in":"<entity id>","out":"<entity id>","rel_type":"...",...}
+//	{"kind":"contradiction","id":"...","in":"<entity id>","out":"<entity id>",...}
+//
+// Record IDs are plain strings without the table prefix. Apart from id and
+// references, the fields are the JSON fields of the models type of the
+// record (models.Entity, models.Chunk, ...), embeddings included as float
+// arrays so that an import doesn't need to re-embed. ArchiveVersion is the
+// version of this format written by ExportAll.
+const ArchiveVersion = 1
+
+// Archive record kinds.
+const (
+	ArchiveKindHeader        = "header"
+	ArchiveKindTemplate      = "template"
+	ArchiveKindEntity        = "entity"
+	ArchiveKindChunk         = "chunk"
+	ArchiveKindRelation      = "relation"
+	ArchiveKindContradiction = "contradiction"
+)
+
+// archivePageSize is the number of records fetched per query while exporting.
+const archivePageSize = 200
+
+// ArchiveHeader is the first record of an archive.
+type ArchiveHeader struct {
+	Kind           string    `json:"kind"`
+	Version        int       `json:"version"`
+	EmbedDimension int       `json:"embed_dimension"` // Length of all embeddings in the archive
+	ExportedAt     time.Time `json:"exported_at"`
+}
+
+// ArchiveTemplate is a template record.
+type ArchiveTemplate struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	models.Template
+}
+
+// ArchiveEntity is an entity record.
+type ArchiveEntity struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	models.Entity
+}
+
+// ArchiveChunk is a chunk record, Entity being the ID of its entity.
+type ArchiveChunk struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Entity string `json:"entity"`
+	models.Chunk
+}
+
+// ArchiveRelation is a relation record between the entities In and Out.
+type ArchiveRelation struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	In   string `json:"in"`
+	Out  string `json:"out"`
+	models.Relation
+}
+
+// ArchiveContradiction is a contradiction record between the entities In and Out.
+type ArchiveContradiction struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	In   string `json:"in"`
+	Out  string `json:"out"`
+	models.Contradiction
+}
+
+// ExportAll writes the whole vault to w as a newline-delimited JSON archive
+// (see ArchiveVersion for the record schema). Records are fetched page by
+// page and written one by one, so the vault is never held in memory.
+func (c *Client) ExportAll(ctx context.Context, w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	header := ArchiveHeader{
+		Kind:           ArchiveKindHeader,
+		Version:        ArchiveVersion,
+		EmbedDimension: c.embedDimension,
+		ExportedAt:     time.Now().UTC(),
+	}
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+
+	err := exportTable(ctx, c, enc, "template", func(t models.Template) any {
+		return ArchiveTemplate{Kind: ArchiveKindTemplate, ID: archiveID(t.ID), Template: t}
+	})
+	if err != nil {
+		return err
+	}
+	err = exportTable(ctx, c, enc, "entity", func(e models.Entity) any {
+		return ArchiveEntity{Kind: ArchiveKindEntity, ID: archiveID(e.ID), Entity: e}
+	})
+	if err != nil {
+		return err
+	}
+	err = exportTable(ctx, c, enc, "chunk", func(ch models.Chunk) any {
+		return ArchiveChunk{Kind: ArchiveKindChunk, ID: archiveID(ch.ID), Entity: archiveID(ch.Entity), Chunk: ch}
+	})
+	if err != nil {
+		return err
+	}
+	err = exportTable(ctx, c, enc, "relates_to", func(r models.Relation) any {
+		return ArchiveRelation{Kind: ArchiveKindRelation, ID: archiveID(r.ID), In: archiveID(r.In), Out: archiveID(r.Out), Relation: r}
+	})
+	if err != nil {
+		return err
+	}
+	return exportTable(ctx, c, enc, "contradicts", func(r models.Contradiction) any {
+		return ArchiveContradiction{Kind: ArchiveKindContradiction, ID: archiveID(r.ID), In: archiveID(r.In), Out: archiveID(r.Out), Contradiction: r}
+	})
+}
+
+// exportTable writes every record of table as the archive record returned
+// by toRecord, reading archivePageSize records per query.
+func exportTable[T any](ctx context.Context, c *Client, enc *json.Encoder, table string, toRecord func(T) any) error {
+	for start := 0; ; start += archivePageSize {
+		page, err := exportPage[T](ctx, c, table, start)
+		if err != nil {
+			return fmt.Errorf("export %s: %w", table, err)
+		}
+
+		for _, record := range page {
+			if err := enc.Encode(toRecord(record)); err != nil {
+				return fmt.Errorf("write %s: %w", table, err)
+			}
+		}
+		if len(page) < archivePageSize {
+			return nil
+		}
+	}
+}
+
+// exportPage returns archivePageSize records of table from offset start, by ID.
+func exportPage[T any](ctx context.Context, c *Client, table string, start int) ([]T, error) {
+	begin := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, begin)
+
+	results, err := runQuery[[]T](ctx, c, `
+		SELECT * FROM type::table($table) ORDER BY id LIMIT $limit START $start
+	`, map[string]any{
+		"table": table,
+		"limit": archivePageSize,
+		"start": start,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if results == nil || len(*results) == 0 {
+		return nil, nil
+	}
+	return (*results)[0].Result, nil
+}
+
+// archiveID returns the ID part of a record ID.
+func archiveID(id surrealmodels.RecordID) string {
+	s, err := models.RecordIDString(id)
+	if err != nil {
+		return fmt.Sprintf("%v", id.ID)
+	}
+	return s
+}
+
+// ConflictMode decides what ImportAll does with a record that already exists.
+type ConflictMode string
+
+const (
+	ConflictSkip      ConflictMode = "skip"      // Keep the existing record
+	ConflictOverwrite ConflictMode = "overwrite" // Replace it with the archived one
+)
+
+// ImportStats counts the records of an import.
+type ImportStats struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+// ImportAll reads an archive written by ExportAll and creates its records
+// with their original IDs. Records that already exist (by ID, templates also
+// by name and relations by endpoints and type) are kept or overwritten
+// according to onConflict; chunks of a kept entity are skipped, those of an
+// overwritten one replaced. Relations and contradictions are imported last,
+// and skipped if an endpoint doesn't exist. Fails before writing anything if
+// the archive's embedding dimension doesn't match the schema's, and on the
+// first record that can't be imported. updated_at is set to the import time.
+func (c *Client) ImportAll(ctx context.Context, r io.Reader, onConflict ConflictMode) (ImportStats, error) {
+	var stats ImportStats
+	if onConflict != ConflictSkip && onConflict != ConflictOverwrite {
+		return stats, fmt.Errorf("invalid conflict mode %q (use %s or %s)", onConflict, ConflictSkip, ConflictOverwrite)
+	}
+	overwrite := onConflict == ConflictOverwrite
+
+	dec := json.NewDecoder(r)
+	var header ArchiveHeader
+	if err := dec.Decode(&header); err != nil {
+		return stats, fmt.Errorf("read header: %w", err)
+	}
+	if header.Kind != ArchiveKindHeader {
+		return stats, fmt.Errorf("not a vault archive: first record is %q, want %q", header.Kind, ArchiveKindHeader)
+	}
+	if header.Version > ArchiveVersion {
+		return stats, fmt.Errorf("archive version %d is newer than supported version %d", header.Version, ArchiveVersion)
+	}
+	if header.EmbedDimension != c.embedDimension {
+		return stats, fmt.Errorf("archive embedding dimension %d doesn't match the schema's %d: import into a database with KNOWHOW_EMBED_DIMENSION=%d",
+			header.EmbedDimension, c.embedDimension, header.EmbedDimension)
+	}
+
+	count := func(imported bool) {
+		if imported {
+			stats.Imported++
+		} else {
+			stats.Skipped++
+		}
+	}
+
+	// Chunks of entities that were kept are skipped with them
+	keptEntities := make(map[string]bool)
+	var relations []ArchiveRelation
+	var contradictions []ArchiveContradiction
+	for n := 2; ; n++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return stats, fmt.Errorf("read record %d: %w", n, err)
+		}
+		var record struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return stats, fmt.Errorf("read record %d: %w", n, err)
+		}
+
+		var imported bool
+		var err error
+		switch record.Kind {
+		case ArchiveKindTemplate:
+			var t ArchiveTemplate
+			if err = json.Unmarshal(raw, &t); err == nil {
+				imported, err = c.importTemplate(ctx, t, overwrite)
+			}
+		case ArchiveKindEntity:
+			var e ArchiveEntity
+			if err = json.Unmarshal(raw, &e); err == nil {
+				if err = c.checkArchiveEmbedding(e.Embedding); err == nil {
+					imported, err = c.importEntity(ctx, e, overwrite)
+					if err == nil && !imported {
+						keptEntities[e.ID] = true
+					}
+				}
+			}
+		case ArchiveKindChunk:
+			var ch ArchiveChunk
+			if err = json.Unmarshal(raw, &ch); err == nil && !keptEntities[ch.Entity] {
+				if err = c.checkArchiveEmbedding(ch.Embedding); err == nil {
+					imported, err = c.importChunk(ctx, ch)
+				}
+			}
+		case ArchiveKindRelation:
+			var rel ArchiveRelation
+			if err = json.Unmarshal(raw, &rel); err == nil {
+				relations = append(relations, rel)
+				continue
+			}
+		case ArchiveKindContradiction:
+			var con ArchiveContradiction
+			if err = json.Unmarshal(raw, &con); err == nil {
+				contradictions = append(contradictions, con)
+				continue
+			}
+		default:
+			err = fmt.Errorf("unknown record kind %q", record.Kind)
+		}
+		if err != nil {
+			return stats, fmt.Errorf("import record %d: %w", n, err)
+		}
+		count(imported)
+	}
+
+	for _, rel := range relations {
+		imported, err := c.importRelation(ctx, rel, overwrite)
+		if err != nil {
+			return stats, fmt.Errorf("import relation %s: %w", rel.ID, err)
+		}
+		count(imported)
+	}
+	for _, con := range contradictions {
+		imported, err := c.importContradiction(ctx, con, overwrite)
+		if err != nil {
+			return stats, fmt.Errorf("import contradiction %s: %w", con.ID, err)
+		}
+		count(imported)
+	}
+	return stats, nil
+}
+
+// checkArchiveEmbedding rejects embeddings that don't fit the vector index.
+func (c *Client) checkArchiveEmbedding(embedding []float32) error {
+	if len(embedding) > 0 && len(embedding) != c.embedDimension {
+		return fmt.Errorf("embedding dimension %d doesn't match the schema's %d", len(embedding), c.embedDimension)
+	}
+	return nil
+}
+
+// importQuery runs an import statement whose last result reports whether
+// the record was written.
+func (c *Client) importQuery(ctx context.Context, sql string, vars map[string]any) (bool, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[any](ctx, c, sql, vars)
+	if err != nil {
+		return false, err
+	}
+	if results == nil || len(*results) == 0 {
+		return false, nil
+	}
+	imported, _ := (*results)[len(*results)-1].Result.(bool)
+	return imported, nil
+}
+
+// archiveTime formats t for a <datetime> cast.
+func archiveTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func (c *Client) importTemplate(ctx context.Context, t ArchiveTemplate, overwrite bool) (bool, error) {
+	return c.importQuery(ctx, `
+		LET $rid = type::record("template", $id);
+		LET $existing = (SELECT VALUE id FROM template WHERE id = $rid OR name = $name);
+		IF array::len($existing) > 0 AND !$overwrite {
+			false
+		} ELSE {
+			UPSERT (IF array::len($existing) > 0 { $existing[0] } ELSE { $rid }) SET
+				name = $name,
+				description = $description,
+				content = $content,
+				created_at = <datetime>$created_at;
+			true
+		};
+	`, map[string]any{
+		"id":          t.ID,
+		"name":        t.Name,
+		"description": optionalString(t.Description),
+		"content":     t.Content,
+		"created_at":  archiveTime(t.CreatedAt),
+		"overwrite":   overwrite,
+	})
+}
+
+func (c *Client) importEntity(ctx context.Context, e ArchiveEntity, overwrite bool) (bool, error) {
+	labels := e.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	return c.importQuery(ctx, `
+		LET $rid = type::record("entity", $id);
+		LET $exists = record::exists($rid);
+		IF $exists AND !$overwrite {
+			false
+		} ELSE {
+			IF $exists {
+				DELETE chunk WHERE entity = $rid;
+			};
+			UPSERT $rid SET
+				type = $type,
+				name = $name,
+				content = $content,
+				summary = $summary,
+				labels = $labels,
+				aliases = $aliases,
+				verified = $verified,
+				confidence = $confidence,
+				source = $source,
+				source_path = $source_path,
+				content_hash = $content_hash,
+				metadata = $metadata,
+				embedding = $embedding,
+				created_at = <datetime>$created_at,
+				accessed = <datetime>$accessed,
+				access_count = $access_count,
+				always_in_context = $always_in_context,
+				pinned = $pinned,
+				language = $language,
+				context = $context;
+			true
+		};
+	`, map[string]any{
+		"id":                e.ID,
+		"type":              e.Type,
+		"name":              e.Name,
+		"content":           optionalString(e.Content),
+		"summary":           optionalString(e.Summary),
+		"labels":            labels,
+		"aliases":           optionalStrings(e.Aliases),
+		"verified":          e.Verified,
+		"confidence":        e.Confidence,
+		"source":            string(e.Source),
+		"source_path":       optionalString(e.SourcePath),
+		"content_hash":      optionalString(e.ContentHash),
+		"metadata":          optionalObject(e.Metadata),
+		"embedding":         optionalEmbedding(e.Embedding),
+		"created_at":        archiveTime(e.CreatedAt),
+		"accessed":          archiveTime(e.Accessed),
+		"access_count":      e.AccessCount,
+		"always_in_context": e.AlwaysInContext,
+		"pinned":            e.Pinned,
+		"language":          optionalString(e.Language),
+		"context":           optionalString(e.Context),
+		"overwrite":         overwrite,
+	})
+}
+
+// importChunk writes a chunk of an entity that was just imported, so there
+// is no conflict to resolve: its old chunks were deleted when overwriting.
+func (c *Client) importChunk(ctx context.Context, ch ArchiveChunk) (bool, error) {
+	labels := ch.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	return c.importQuery(ctx, `
+		UPSERT type::record("chunk", $id) SET
+			entity = type::record("entity", $entity),
+			content = $content,
+			position = $position,
+			heading_path = $heading_path,
+			labels = $labels,
+			embedding = $embedding,
+			created_at = <datetime>$created_at;
+		RETURN true;
+	`, map[string]any{
+		"id":           ch.ID,
+		"entity":       ch.Entity,
+		"content":      ch.Content,
+		"position":     ch.Position,
+		"heading_path": optionalString(ch.HeadingPath),
+		"labels":       labels,
+		"embedding":    ch.Embedding,
+		"created_at":   archiveTime(ch.CreatedAt),
+	})
+}
+
+func (c *Client) importRelation(ctx context.Context, rel ArchiveRelation, overwrite bool) (bool, error) {
+	return c.importQuery(ctx, `
+		LET $rid = type::record("relates_to", $id);
+		LET $in_rec = type::record("entity", $in);
+		LET $out_rec = type::record("entity", $out);
+		LET $unique = string::concat(array::sort([<string>$in_rec, <string>$out_rec]), $rel_type);
+		LET $existing = (SELECT VALUE id FROM relates_to WHERE id = $rid OR unique_key = $unique);
+		IF !record::exists($in_rec) OR !record::exists($out_rec) OR (array::len($existing) > 0 AND !$overwrite) {
+			false
+		} ELSE IF array::len($existing) > 0 {
+			UPDATE $existing[0] SET
+				strength = $strength,
+				source = $source,
+				metadata = $metadata,
+				created_at = <datetime>$created_at;
+			true
+		} ELSE {
+			INSERT RELATION INTO relates_to {
+				id: $rid,
+				in: $in_rec,
+				out: $out_rec,
+				rel_type: $rel_type,
+				strength: $strength,
+				source: $source,
+				metadata: $metadata,
+				created_at: <datetime>$created_at
+			};
+			true
+		};
+	`, map[string]any{
+		"id":         rel.ID,
+		"in":         rel.In,
+		"out":        rel.Out,
+		"rel_type":   rel.RelType,
+		"strength":   rel.Strength,
+		"source":     rel.Source,
+		"metadata":   optionalObject(rel.Metadata),
+		"created_at": archiveTime(rel.CreatedAt),
+		"overwrite":  overwrite,
+	})
+}
+
+func (c *Client) importContradiction(ctx context.Context, con ArchiveContradiction, overwrite bool) (bool, error) {
+	return c.importQuery(ctx, `
+		LET $rid = type::record("contradicts", $id);
+		LET $in_rec = type::record("entity", $in);
+		LET $out_rec = type::record("entity", $out);
+		LET $exists = record::exists($rid);
+		IF !record::exists($in_rec) OR !record::exists($out_rec) OR ($exists AND !$overwrite) {
+			false
+		} ELSE IF $exists {
+			UPDATE $rid SET
+				explanation = $explanation,
+				confidence = $confidence,
+				resolved = $resolved,
+				detected_at = <datetime>$detected_at;
+			true
+		} ELSE {
+			INSERT RELATION INTO contradicts {
+				id: $rid,
+				in: $in_rec,
+				out: $out_rec,
+				explanation: $explanation,
+				confidence: $confidence,
+				resolved: $resolved,
+				detected_at: <datetime>$detected_at
+			};
+			true
+		};
+	`, map[string]any{
+		"id":          con.ID,
+		"in":          con.In,
+		"out":         con.Out,
+		"explanation": con.Explanation,
+		"confidence":  con.Confidence,
+		"resolved":    con.Resolved,
+		"detected_at": archiveTime(con.DetectedAt),
+		"overwrite":   overwrite,
+	})
+}
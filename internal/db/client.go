@@ -4,6 +4,7 @@ package db
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -37,20 +38,41 @@ type Config struct {
 	AuthLevel string // "root" or "database"
 }
 
-// Client wraps SurrealDB connection with auto-reconnect.
+// Client wraps a pool of SurrealDB connections with auto-reconnect.
+// Queries run on the least busy connection.
 type Client struct {
-	conn       *rews.Connection[*gorillaws.Connection]
-	db         *surrealdb.DB
-	cfg        Config
-	logger     logger.Logger
-	metrics    *metrics.Collector
-	lastActive atomic.Int64 // Unix timestamp of last DB operation (for idle detection)
-	done       chan struct{} // closed on Close() to stop monitorConnection goroutine
+	conns   []*poolConn
+	next    atomic.Uint64 // round-robin start of the least-busy scan
+	cfg     Config
+	logger  logger.Logger
+	metrics *metrics.Collector
+	done    chan struct{} // closed on Close() to stop monitorConnection goroutines
+
+	embedDimension int          // set by InitSchema, used for index probes
+	indexStatus    atomic.Value // string, one of the IndexStatus* constants
 }
 
-// NewClient creates a new SurrealDB client with auto-reconnecting WebSocket.
-// If mc is nil, metrics recording is disabled.
+// Vector index states reported by IndexStatus.
+const (
+	IndexStatusPending = "pending" // not verified yet, searches may be degraded
+	IndexStatusReady   = "ready"   // probe query succeeded
+	IndexStatusFailed  = "failed"  // probe did not succeed before the deadline
+)
+
+// NewClient creates a new SurrealDB client with a single auto-reconnecting
+// WebSocket. If mc is nil, metrics recording is disabled.
 func NewClient(ctx context.Context, cfg Config, log *slog.Logger, mc *metrics.Collector) (*Client, error) {
+	return NewPool(ctx, cfg, 1, log, mc)
+}
+
+// NewPool creates a SurrealDB client with size independent auto-reconnecting
+// WebSockets, so concurrent queries don't queue on a single connection.
+// If mc is nil, metrics recording is disabled.
+func NewPool(ctx context.Context, cfg Config, size int, log *slog.Logger, mc *metrics.Collector) (*Client, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("pool size must be at least 1, got %d", size)
+	}
+
 	// Create logger adapter for SurrealDB SDK
 	var sdkLogger logger.Logger
 	if log != nil {
@@ -59,6 +81,37 @@ func NewClient(ctx context.Context, cfg Config, log *slog.Logger, mc *metrics.Co
 		sdkLogger = logger.New(slog.Default().Handler())
 	}
 
+	conns := make([]*poolConn, 0, size)
+	for i := range size {
+		pc, err := dial(ctx, cfg, sdkLogger)
+		if err != nil {
+			for _, open := range conns {
+				if closeErr := open.conn.Close(ctx); closeErr != nil {
+					sdkLogger.Debug("failed to close connection during cleanup", "error", closeErr)
+				}
+			}
+			if size > 1 {
+				return nil, fmt.Errorf("connection %d: %w", i, err)
+			}
+			return nil, err
+		}
+		conns = append(conns, pc)
+	}
+
+	sdkLogger.Info("SurrealDB connection established", "pool_size", size)
+	client := &Client{conns: conns, cfg: cfg, logger: sdkLogger, metrics: mc, done: make(chan struct{})}
+	client.indexStatus.Store(IndexStatusPending)
+
+	// Start a health monitor per connection
+	for _, pc := range conns {
+		go client.monitorConnection(pc)
+	}
+
+	return client, nil
+}
+
+// dial opens, authenticates and configures one auto-reconnecting WebSocket.
+func dial(ctx context.Context, cfg Config, sdkLogger logger.Logger) (*poolConn, error) {
 	// Use surrealcbor for CBOR encoding/decoding (handles SurrealDB custom tags)
 	codec := surrealcbor.New()
 
@@ -144,28 +197,27 @@ func NewClient(ctx context.Context, cfg Config, log *slog.Logger, mc *metrics.Co
 		return nil, fmt.Errorf("use: %w", err)
 	}
 
-	sdkLogger.Info("SurrealDB connection established")
-	client := &Client{conn: conn, db: db, cfg: cfg, logger: sdkLogger, metrics: mc, done: make(chan struct{})}
-	client.lastActive.Store(time.Now().Unix()) // Initialize to prevent immediate heartbeat
-
-	// Start connection health monitor
-	go client.monitorConnection()
-
-	return client, nil
+	pc := &poolConn{conn: conn, db: db}
+	pc.lastActive.Store(time.Now().Unix()) // Initialize to prevent immediate heartbeat
+	return pc, nil
 }
 
-// Close closes the SurrealDB connection and stops the heartbeat monitor.
+// Close closes the SurrealDB connections and stops the heartbeat monitors.
 func (c *Client) Close(ctx context.Context) error {
 	c.logger.Info("closing SurrealDB connection")
 	close(c.done)
-	return c.conn.Close(ctx)
+	var errs []error
+	for _, pc := range c.conns {
+		errs = append(errs, pc.conn.Close(ctx))
+	}
+	return errors.Join(errs...)
 }
 
-// monitorConnection logs WebSocket connection state changes and sends periodic heartbeats.
+// monitorConnection logs state changes of one pooled WebSocket and sends periodic heartbeats.
 // Heartbeat queries keep the connection alive during long external operations (e.g., LLM calls)
 // that would otherwise let the WebSocket go idle and get closed by the server/network.
 // Following NATS pattern: 2 consecutive failures trigger connection close to force reconnect.
-func (c *Client) monitorConnection() {
+func (c *Client) monitorConnection(pc *poolConn) {
 	const (
 		heartbeatInterval = 30 * time.Second // Check every 30s (was 10s)
 		idleThreshold     = 10 * time.Second // Only ping if idle >10s (was 5s)
@@ -185,7 +237,7 @@ func (c *Client) monitorConnection() {
 			return
 		case <-ticker.C:
 		}
-		isConnected := !c.conn.IsClosed()
+		isConnected := !pc.conn.IsClosed()
 
 		// Log connection state changes
 		if !isConnected && wasConnected {
@@ -198,12 +250,12 @@ func (c *Client) monitorConnection() {
 
 		// Send heartbeat only when idle (no recent DB operations)
 		if isConnected {
-			lastActive := time.Unix(c.lastActive.Load(), 0)
+			lastActive := time.Unix(pc.lastActive.Load(), 0)
 			idleDuration := time.Since(lastActive)
 
 			if idleDuration > idleThreshold {
 				ctx, cancel := context.WithTimeout(context.Background(), heartbeatTimeout)
-				_, err := surrealdb.Query[any](ctx, c.db, "RETURN 1", nil)
+				err := pc.ping(ctx)
 				cancel()
 
 				if err != nil {
@@ -213,7 +265,7 @@ func (c *Client) monitorConnection() {
 							"consecutive", consecutiveFailures,
 							"idle_for", idleDuration.Round(time.Second))
 						// Force close to trigger rews auto-reconnect on next operation
-				if closeErr := c.conn.Close(context.Background()); closeErr != nil {
+				if closeErr := pc.conn.Close(context.Background()); closeErr != nil {
 					c.logger.Debug("failed to close connection for reconnect", "error", closeErr)
 				}
 						consecutiveFailures = 0
@@ -227,6 +279,21 @@ func (c *Client) monitorConnection() {
 	}
 }
 
+// Ping checks that the database answers a trivial query on every pooled
+// connection. It fails right away if a WebSocket is disconnected; bound slow
+// answers with ctx.
+func (c *Client) Ping(ctx context.Context) error {
+	for i, pc := range c.conns {
+		if err := pc.ping(ctx); err != nil {
+			if len(c.conns) > 1 {
+				return fmt.Errorf("connection %d: %w", i, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 // recordTiming records operation timing if metrics are enabled.
 func (c *Client) recordTiming(op string, start time.Time) {
 	if c.metrics != nil {
@@ -234,33 +301,107 @@ func (c *Client) recordTiming(op string, start time.Time) {
 	}
 }
 
-// startOp marks connection active and returns start time for timing.
+// startOp returns the start time of an operation for timing.
 // Usage: start := c.startOp(); defer c.recordTiming(metrics.OpDBQuery, start)
 func (c *Client) startOp() time.Time {
-	c.lastActive.Store(time.Now().Unix())
 	return time.Now()
 }
 
-// DB returns the underlying SurrealDB client for queries.
+// DB returns the SurrealDB client of the first pooled connection, bypassing
+// dispatch and active counts.
 func (c *Client) DB() *surrealdb.DB {
-	return c.db
+	return c.conns[0].db
 }
 
-// InitSchema initializes the database schema with the given embedding dimension.
-func (c *Client) InitSchema(ctx context.Context, embedDimension int) error {
-	c.logger.Info("initializing database schema", "embed_dimension", embedDimension)
-	_, err := surrealdb.Query[any](ctx, c.db, SchemaSQL(embedDimension), nil)
+// InitSchema initializes the database schema with the given embedding
+// dimension and HNSW distance metric (see Distances).
+func (c *Client) InitSchema(ctx context.Context, embedDimension int, distance string) error {
+	if err := ValidateDistance(distance); err != nil {
+		return fmt.Errorf("init schema: %w", err)
+	}
+	c.logger.Info("initializing database schema", "embed_dimension", embedDimension, "distance", distance)
+	_, err := runQuery[any](ctx, c, SchemaSQL(embedDimension, distance), nil)
 	if err != nil {
 		return fmt.Errorf("init schema: %w", err)
 	}
+	c.embedDimension = embedDimension
 	c.logger.Info("schema initialization complete")
 	return nil
 }
 
+// IndexStatus returns the last known state of the embedding vector indexes.
+func (c *Client) IndexStatus() string {
+	status, ok := c.indexStatus.Load().(string)
+	if !ok {
+		return IndexStatusPending
+	}
+	return status
+}
+
+// VerifyVectorIndex runs a tiny KNN query against the entity and chunk
+// embedding indexes. An error means the indexes are not queryable yet.
+func (c *Client) VerifyVectorIndex(ctx context.Context) error {
+	if c.embedDimension <= 0 {
+		return fmt.Errorf("verify vector index: schema not initialized")
+	}
+
+	probe := make([]float32, c.embedDimension)
+	for i := range probe {
+		probe[i] = 1
+	}
+
+	sql := `
+		SELECT id FROM entity WHERE embedding <|1,40|> $probe LIMIT 1;
+		SELECT id FROM chunk WHERE embedding <|1,40|> $probe LIMIT 1;
+	`
+	results, err := runQuery[[]map[string]any](ctx, c, sql, map[string]any{"probe": probe})
+	if err != nil {
+		return fmt.Errorf("verify vector index: %w", err)
+	}
+	if results == nil {
+		return fmt.Errorf("verify vector index: no results")
+	}
+	for _, r := range *results {
+		if r.Error != nil {
+			return fmt.Errorf("verify vector index: %w", r.Error)
+		}
+	}
+	return nil
+}
+
+// WaitForVectorIndex probes the embedding indexes until they answer or the
+// timeout elapses, updating IndexStatus accordingly.
+func (c *Client) WaitForVectorIndex(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	delay := 500 * time.Millisecond
+	for {
+		err := c.VerifyVectorIndex(ctx)
+		if err == nil {
+			c.indexStatus.Store(IndexStatusReady)
+			c.logger.Info("vector index ready", "elapsed", time.Since(start).Round(time.Millisecond))
+			return nil
+		}
+		c.logger.Debug("vector index not ready", "error", err)
+
+		select {
+		case <-ctx.Done():
+			c.indexStatus.Store(IndexStatusFailed)
+			return fmt.Errorf("vector index not ready after %s: %w", timeout, err)
+		case <-c.done:
+			return fmt.Errorf("vector index check aborted: client closed")
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, 10*time.Second)
+	}
+}
+
 // Query executes a SurrealQL query with parameters.
 // Returns the raw query results as []surrealdb.QueryResult[any].
 func (c *Client) Query(ctx context.Context, sql string, vars map[string]any) (*[]surrealdb.QueryResult[any], error) {
-	return surrealdb.Query[any](ctx, c.db, sql, vars)
+	return runQuery[any](ctx, c, sql, vars)
 }
 
 // WipeData deletes all data from the database while preserving schema.
@@ -270,11 +411,11 @@ func (c *Client) WipeData(ctx context.Context) error {
 
 	// Delete all records from each table
 	// Order matters due to relations referencing entities
-	tables := []string{"message", "conversation", "relates_to", "chunk", "template", "token_usage", "ingest_job", "entity"}
+	tables := []string{"message", "conversation", "relates_to", "chunk", "template", "token_usage", "answer_source", "metrics_snapshot", "ingest_job", "entity"}
 
 	for _, table := range tables {
 		query := fmt.Sprintf("DELETE %s", table)
-		if _, err := surrealdb.Query[any](ctx, c.db, query, nil); err != nil {
+		if _, err := runQuery[any](ctx, c, query, nil); err != nil {
 			return fmt.Errorf("delete %s: %w", table, err)
 		}
 		c.logger.Info("deleted table data", "table", table)
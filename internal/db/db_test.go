@@ -2,19 +2,32 @@
 package db
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"maps"
+	"math"
+	"math/rand/v2"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/raphaelgruber/memcp-go/internal/metrics"
 	"github.com/raphaelgruber/memcp-go/internal/models"
+	surrealmodels "github.com/surrealdb/surrealdb.go/pkg/models"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 var testDB *Client
+var testConfig Config
 var testContainer testcontainers.Container
 
 // TestMain sets up and tears down the SurrealDB container for all tests.
@@ -54,20 +67,21 @@ func TestMain(m *testing.M) {
 	}
 
 	// Connect to test database
-	testDB, err = NewClient(ctx, Config{
+	testConfig = Config{
 		URL:       fmt.Sprintf("ws://%s:%s/rpc", host, mappedPort.Port()),
 		Namespace: "test",
 		Database:  "test",
 		Username:  "root",
 		Password:  "root",
 		AuthLevel: "root",
-	}, nil, nil)
+	}
+	testDB, err = NewClient(ctx, testConfig, nil, nil)
 	if err != nil {
 		log.Fatalf("Failed to connect to test database: %v", err)
 	}
 
 	// Initialize schema with test embedding dimension (384)
-	if err := testDB.InitSchema(ctx, 384); err != nil {
+	if err := testDB.Migrate(ctx, 384, DistanceCosine); err != nil {
 		log.Fatalf("Failed to initialize schema: %v", err)
 	}
 
@@ -91,6 +105,116 @@ func dummyEmbedding() []float32 {
 	return embedding
 }
 
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+
+	// Test migrations follow the registered ones, applied by TestMain
+	last := schemaVersion
+	if n := len(Migrations); n > 0 {
+		last = Migrations[n-1].Version
+	}
+	defer func() {
+		if _, err := testDB.Query(ctx, "REMOVE TABLE IF EXISTS migration_test; DELETE _migration WHERE version > $last;", map[string]any{"last": last}); err != nil {
+			t.Errorf("cleanup failed: %v", err)
+		}
+	}()
+
+	countRows := func() int {
+		t.Helper()
+		results, err := testDB.Query(ctx, "SELECT VALUE n FROM migration_test", nil)
+		if err != nil {
+			t.Fatalf("count failed: %v", err)
+		}
+		rows, _ := (*results)[0].Result.([]any)
+		return len(rows)
+	}
+
+	migrations := append(slices.Clone(Migrations),
+		Migration{Version: last + 1, SQL: "DEFINE TABLE migration_test SCHEMALESS; CREATE migration_test SET n = 1"},
+	)
+	for range 2 {
+		if err := testDB.migrate(ctx, migrations); err != nil {
+			t.Fatalf("migrate failed: %v", err)
+		}
+	}
+	if got := countRows(); got != 1 {
+		t.Errorf("migration %d created %d rows, want 1 (applied once)", last+1, got)
+	}
+
+	// A failing migration names its version and isn't recorded
+	migrations = append(migrations, Migration{Version: last + 2, SQL: "CREATE migration_test SET n = 2; THROW 'broken'"})
+	err := testDB.migrate(ctx, migrations)
+	if want := fmt.Sprintf("migration %03d", last+2); err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("migrate error = %v, want %s failure", err, want)
+	}
+	applied, err := testDB.appliedMigrations(ctx)
+	if err != nil {
+		t.Fatalf("appliedMigrations failed: %v", err)
+	}
+	if !applied[schemaVersion] || !applied[last+1] || applied[last+2] {
+		t.Errorf("applied = %v, want versions up to %d", applied, last+1)
+	}
+	if got := countRows(); got != 1 {
+		t.Errorf("rows after failed migration = %d, want 1 (rolled back)", got)
+	}
+}
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := NewPool(ctx, testConfig, 0, nil, nil); err == nil {
+		t.Error("expected error for pool size 0")
+	}
+
+	pool, err := NewPool(ctx, testConfig, 3, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPool failed: %v", err)
+	}
+	defer func() {
+		if err := pool.Close(ctx); err != nil {
+			t.Errorf("Close failed: %v", err)
+		}
+	}()
+
+	const queries = 30
+	var wg sync.WaitGroup
+	for range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := pool.Query(ctx, "RETURN 1", nil); err != nil {
+				t.Errorf("Query failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := pool.Ping(ctx); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+
+	stats := pool.ConnectionStats()
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 connection stats, got %d", len(stats))
+	}
+	var total int64
+	for i, s := range stats {
+		if s.Queries == 0 {
+			t.Errorf("connection %d ran no queries", i)
+		}
+		if s.Active != 0 {
+			t.Errorf("connection %d: expected 0 active queries, got %d", i, s.Active)
+		}
+		if !s.Connected {
+			t.Errorf("connection %d not connected", i)
+		}
+		total += s.Queries
+	}
+	if total != queries {
+		t.Errorf("expected %d queries in total, got %d", queries, total)
+	}
+}
+
 // =============================================================================
 // ENTITY TESTS
 // =============================================================================
@@ -127,6 +251,46 @@ func TestCreateEntity(t *testing.T) {
 	_, _ = testDB.DeleteEntity(ctx, models.MustRecordIDString(entity.ID))
 }
 
+func TestCreateEntitiesBatch(t *testing.T) {
+	ctx := context.Background()
+
+	existing, err := testDB.CreateEntity(ctx, models.EntityInput{Type: "concept", Name: "Batch Existing", Embedding: dummyEmbedding()})
+	if err != nil {
+		t.Fatalf("CreateEntity failed: %v", err)
+	}
+	defer func() { _, _ = testDB.DeleteEntity(ctx, models.MustRecordIDString(existing.ID)) }()
+
+	content := "Batch created entity"
+	results, err := testDB.CreateEntitiesBatch(ctx, []models.EntityInput{
+		{Type: "concept", Name: "Batch One", Content: &content, Labels: []string{"batch"}, Embedding: dummyEmbedding()},
+		{Type: "concept", Name: "Batch Existing", Embedding: dummyEmbedding()},
+		{Type: "concept", Name: "Batch Two", Embedding: dummyEmbedding()},
+	})
+	if err != nil {
+		t.Fatalf("CreateEntitiesBatch failed: %v", err)
+	}
+	for _, r := range results {
+		if r.Entity != nil && r.Entity.Name != "Batch Existing" {
+			defer func() { _, _ = testDB.DeleteEntity(ctx, models.MustRecordIDString(r.Entity.ID)) }()
+		}
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].Err != nil || results[0].Entity == nil || results[0].Entity.Name != "Batch One" {
+		t.Errorf("expected Batch One created, got %+v", results[0])
+	} else if models.MustRecordIDString(results[0].Entity.ID) != "batch-one" {
+		t.Errorf("expected slugified ID batch-one, got %s", models.MustRecordIDString(results[0].Entity.ID))
+	}
+	if !errors.Is(results[1].Err, ErrEntityAlreadyExists) {
+		t.Errorf("expected ErrEntityAlreadyExists for the existing entity, got %v", results[1].Err)
+	}
+	if results[2].Err != nil || results[2].Entity == nil || results[2].Entity.Name != "Batch Two" {
+		t.Errorf("expected Batch Two created despite the failed input, got %+v", results[2])
+	}
+}
+
 func TestGetEntity(t *testing.T) {
 	ctx := context.Background()
 
@@ -271,6 +435,109 @@ func TestGetEntitiesByNames(t *testing.T) {
 	}
 }
 
+func TestEntityAliases(t *testing.T) {
+	ctx := context.Background()
+
+	var createdIDs []string
+	for _, name := range []string{"Alias Test Kubernetes", "Alias Test Nomad"} {
+		created, err := testDB.CreateEntity(ctx, models.EntityInput{Type: "concept", Name: name, Embedding: dummyEmbedding()})
+		if err != nil {
+			t.Fatalf("Failed to create test entity %s: %v", name, err)
+		}
+		createdIDs = append(createdIDs, models.MustRecordIDString(created.ID))
+	}
+	defer func() {
+		for _, id := range createdIDs {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+	k8sID, nomadID := createdIDs[0], createdIDs[1]
+
+	entity, err := testDB.AddAlias(ctx, k8sID, " Alias-K8s ")
+	if err != nil {
+		t.Fatalf("AddAlias failed: %v", err)
+	}
+	if !slices.Equal(entity.Aliases, []string{"alias-k8s"}) {
+		t.Errorf("Aliases = %v, want [alias-k8s]", entity.Aliases)
+	}
+	// Adding it again keeps one copy
+	if entity, err = testDB.AddAlias(ctx, k8sID, "alias-k8s"); err != nil || len(entity.Aliases) != 1 {
+		t.Errorf("AddAlias again = %v, %v, want one alias", entity, err)
+	}
+
+	found, err := testDB.GetEntityByName(ctx, "ALIAS-K8S")
+	if err != nil {
+		t.Fatalf("GetEntityByName failed: %v", err)
+	}
+	if found == nil || found.Name != "Alias Test Kubernetes" {
+		t.Errorf("GetEntityByName(alias) = %v, want Alias Test Kubernetes", found)
+	}
+
+	byNames, err := testDB.GetEntitiesByNames(ctx, []string{"Alias-K8s", "Alias Test Nomad"})
+	if err != nil {
+		t.Fatalf("GetEntitiesByNames failed: %v", err)
+	}
+	if e := byNames["alias-k8s"]; e == nil || e.Name != "Alias Test Kubernetes" {
+		t.Errorf("GetEntitiesByNames[alias-k8s] = %v, want Alias Test Kubernetes", e)
+	}
+	if e := byNames["alias test nomad"]; e == nil || e.Name != "Alias Test Nomad" {
+		t.Errorf("GetEntitiesByNames[alias test nomad] = %v, want Alias Test Nomad", e)
+	}
+
+	// Another entity's alias or name can't be claimed
+	for _, alias := range []string{"alias-k8s", "alias test kubernetes"} {
+		_, err := testDB.AddAlias(ctx, nomadID, alias)
+		if !errors.Is(err, ErrAliasTaken) || !strings.Contains(err.Error(), "Alias Test Kubernetes") {
+			t.Errorf("AddAlias(%q) error = %v, want ErrAliasTaken naming the owner", alias, err)
+		}
+	}
+
+	if _, err := testDB.AddAlias(ctx, "alias-test-missing", "whatever"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("AddAlias on missing entity error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFindEntitiesByNameFuzzy(t *testing.T) {
+	ctx := context.Background()
+
+	var createdIDs []string
+	for _, name := range []string{"Kubernetes Operator", "Kubernetes Operators Guide"} {
+		created, err := testDB.CreateEntity(ctx, models.EntityInput{Type: "concept", Name: name, Embedding: dummyEmbedding()})
+		if err != nil {
+			t.Fatalf("Failed to create test entity %s: %v", name, err)
+		}
+		createdIDs = append(createdIDs, models.MustRecordIDString(created.ID))
+	}
+	defer func() {
+		for _, id := range createdIDs {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	// Plural form with a typo: two edits away
+	matches, err := testDB.FindEntitiesByNameFuzzy(ctx, "kubernets operators", 3)
+	if err != nil {
+		t.Fatalf("FindEntitiesByNameFuzzy failed: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Name != "Kubernetes Operator" {
+		t.Fatalf("expected only Kubernetes Operator, got %d matches", len(matches))
+	}
+	if matches[0].Distance != 2 {
+		t.Errorf("expected distance 2, got %d", matches[0].Distance)
+	}
+	if matches[0].Score < 0.89 || matches[0].Score > 0.9 {
+		t.Errorf("expected score ~0.89, got %f", matches[0].Score)
+	}
+
+	matches, err = testDB.FindEntitiesByNameFuzzy(ctx, "kubernets operators", 1)
+	if err != nil {
+		t.Fatalf("FindEntitiesByNameFuzzy failed: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("expected no matches within 1 edit, got %d", len(matches))
+	}
+}
+
 func TestUpdateEntity(t *testing.T) {
 	ctx := context.Background()
 
@@ -489,363 +756,3050 @@ func TestHybridSearch(t *testing.T) {
 	if !found {
 		t.Error("HybridSearch with web label should find JavaScript")
 	}
-}
-
-// =============================================================================
-// CHUNK TESTS
-// =============================================================================
 
-func TestCreateAndGetChunks(t *testing.T) {
-	ctx := context.Background()
+	// Search with label groups: (programming OR scripting) AND web
+	results, err = testDB.HybridSearch(ctx, SearchOptions{
+		Query:       "language",
+		Embedding:   dummyEmbedding(),
+		LabelGroups: [][]string{{"programming", "scripting"}, {"web"}},
+		Limit:       10,
+	})
+	if err != nil {
+		t.Fatalf("HybridSearch with label groups failed: %v", err)
+	}
+	for _, r := range results {
+		if r.Name != "JavaScript" {
+			t.Errorf("HybridSearch with label groups returned unexpected entity %q (labels: %v)", r.Name, r.Labels)
+		}
+	}
 
-	// Create test entity
-	content := "Long content that would be chunked"
-	entity, err := testDB.CreateEntity(ctx, models.EntityInput{
-		Type:      "document",
-		Name:      "Chunk Test Doc",
-		Content:   &content,
-		Embedding: dummyEmbedding(),
+	// Excluded entities never appear, on either the vector or full-text leg
+	results, err = testDB.HybridSearch(ctx, SearchOptions{
+		Query:      "Go programming",
+		Embedding:  dummyEmbedding(),
+		ExcludeIDs: []string{createdIDs[0]},
+		Limit:      10,
 	})
 	if err != nil {
-		t.Fatalf("Failed to create test entity: %v", err)
+		t.Fatalf("HybridSearch with exclude failed: %v", err)
 	}
-	entityID := models.MustRecordIDString(entity.ID)
-	defer func() {
-		_, _ = testDB.DeleteEntity(ctx, entityID)
-	}()
+	for _, r := range results {
+		if models.MustRecordIDString(r.ID) == createdIDs[0] {
+			t.Errorf("HybridSearch returned excluded entity %q", r.Name)
+		}
+	}
+}
 
-	// Create chunks
-	headingPath := "## Section 1"
-	chunks := []models.ChunkInput{
-		{EntityID: entityID, Content: "Chunk 1 content", Position: 0, HeadingPath: &headingPath, Embedding: dummyEmbedding()},
-		{EntityID: entityID, Content: "Chunk 2 content", Position: 1, Embedding: dummyEmbedding()},
+func TestHybridSearchLanguage(t *testing.T) {
+	ctx := context.Background()
+
+	en, de := "en", "de"
+	content1 := "Deployment runbook for the platform"
+	content2 := "Bereitstellung der Plattform, deployment runbook"
+
+	entities := []models.EntityInput{
+		{Type: "document", Name: "Deployment EN", Content: &content1, Language: &en, Embedding: dummyEmbedding()},
+		{Type: "document", Name: "Deployment DE", Content: &content2, Language: &de, Embedding: dummyEmbedding()},
 	}
 
-	err = testDB.CreateChunks(ctx, entityID, chunks)
-	if err != nil {
-		t.Fatalf("CreateChunks failed: %v", err)
+	var createdIDs []string
+	for _, input := range entities {
+		entity, err := testDB.CreateEntity(ctx, input)
+		if err != nil {
+			t.Fatalf("Failed to create test entity: %v", err)
+		}
+		createdIDs = append(createdIDs, models.MustRecordIDString(entity.ID))
 	}
+	defer func() {
+		for _, id := range createdIDs {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
 
-	// Get chunks
-	retrieved, err := testDB.GetChunks(ctx, entityID)
+	results, err := testDB.HybridSearch(ctx, SearchOptions{
+		Query:     "deployment runbook",
+		Embedding: dummyEmbedding(),
+		Language:  "de",
+		Limit:     10,
+	})
 	if err != nil {
-		t.Fatalf("GetChunks failed: %v", err)
+		t.Fatalf("HybridSearch failed: %v", err)
 	}
-	if len(retrieved) != 2 {
-		t.Errorf("Expected 2 chunks, got %d", len(retrieved))
+	if len(results) != 1 || results[0].Name != "Deployment DE" {
+		t.Fatalf("expected only the German entity, got %d results", len(results))
 	}
-	if retrieved[0].Position != 0 {
-		t.Error("Chunks should be ordered by position")
+
+	// An empty language clears it
+	empty := ""
+	updated, err := testDB.UpdateEntity(ctx, createdIDs[1], models.EntityUpdate{Language: &empty})
+	if err != nil {
+		t.Fatalf("UpdateEntity failed: %v", err)
+	}
+	if updated.Language != nil {
+		t.Errorf("expected language cleared, got %q", *updated.Language)
 	}
 }
 
-func TestDeleteChunks(t *testing.T) {
+func TestEntityContextIsolation(t *testing.T) {
 	ctx := context.Background()
 
-	// Create test entity with chunks
-	content := "Content for chunk deletion test"
-	entity, err := testDB.CreateEntity(ctx, models.EntityInput{
-		Type:      "document",
-		Name:      "Delete Chunks Test",
-		Content:   &content,
+	alpha, beta := "alpha", "beta"
+	content := "Release checklist for the payment service"
+
+	entities := []models.EntityInput{
+		{Type: "document", Name: "Release Checklist Alpha", Content: &content, Context: &alpha, Embedding: dummyEmbedding()},
+		{Type: "document", Name: "Release Checklist Beta", Content: &content, Context: &beta, Embedding: dummyEmbedding()},
+		{Type: "document", Name: "Release Checklist Global", Content: &content, Embedding: dummyEmbedding()},
+	}
+
+	var createdIDs []string
+	for _, input := range entities {
+		entity, err := testDB.CreateEntity(ctx, input)
+		if err != nil {
+			t.Fatalf("Failed to create test entity: %v", err)
+		}
+		createdIDs = append(createdIDs, models.MustRecordIDString(entity.ID))
+	}
+	defer func() {
+		for _, id := range createdIDs {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	results, err := testDB.HybridSearch(ctx, SearchOptions{
+		Query:     "release checklist",
 		Embedding: dummyEmbedding(),
+		Context:   &alpha,
+		Limit:     10,
 	})
 	if err != nil {
-		t.Fatalf("Failed to create test entity: %v", err)
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "Release Checklist Alpha" {
+		t.Fatalf("expected only the alpha entity, got %d results", len(results))
+	}
+	if results[0].Context == nil || *results[0].Context != alpha {
+		t.Errorf("expected context %q, got %v", alpha, results[0].Context)
 	}
-	entityID := models.MustRecordIDString(entity.ID)
-	defer func() {
-		_, _ = testDB.DeleteEntity(ctx, entityID)
-	}()
 
-	chunks := []models.ChunkInput{
-		{EntityID: entityID, Content: "To be deleted", Position: 0},
+	// A nil context searches all entities
+	results, err = testDB.HybridSearch(ctx, SearchOptions{
+		Query:     "release checklist",
+		Embedding: dummyEmbedding(),
+		Limit:     10,
+	})
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(results) < 3 {
+		t.Errorf("expected all 3 entities without a context filter, got %d", len(results))
 	}
-	_ = testDB.CreateChunks(ctx, entityID, chunks)
 
-	// Delete chunks
-	err = testDB.DeleteChunks(ctx, entityID)
+	listed, err := testDB.ListEntities(ctx, ListOptions{Context: &beta})
 	if err != nil {
-		t.Fatalf("DeleteChunks failed: %v", err)
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Name != "Release Checklist Beta" {
+		t.Errorf("expected only the beta entity, got %d entities", len(listed))
 	}
 
-	// Verify gone
-	retrieved, err := testDB.GetChunks(ctx, entityID)
+	found, err := testDB.GetEntityByNameIn(ctx, "release checklist alpha", &beta)
 	if err != nil {
-		t.Fatalf("GetChunks after delete failed: %v", err)
+		t.Fatalf("GetEntityByNameIn failed: %v", err)
 	}
-	if len(retrieved) != 0 {
-		t.Errorf("Expected 0 chunks after delete, got %d", len(retrieved))
+	if found != nil {
+		t.Errorf("expected no match in another context, got %s", found.Name)
+	}
+	found, err = testDB.GetEntityByNameIn(ctx, "release checklist alpha", &alpha)
+	if err != nil {
+		t.Fatalf("GetEntityByNameIn failed: %v", err)
+	}
+	if found == nil || found.Name != "Release Checklist Alpha" {
+		t.Errorf("expected the alpha entity, got %v", found)
 	}
-}
 
-// =============================================================================
-// RELATION TESTS
-// =============================================================================
+	// Always-in-context entities are listed per context
+	for _, id := range createdIDs {
+		if _, err := testDB.SetAlwaysInContext(ctx, id, true); err != nil {
+			t.Fatalf("SetAlwaysInContext failed: %v", err)
+		}
+	}
+	always, err := testDB.ListAlwaysInContext(ctx, 100, &alpha)
+	if err != nil {
+		t.Fatalf("ListAlwaysInContext failed: %v", err)
+	}
+	if len(always) != 1 || always[0].Name != "Release Checklist Alpha" {
+		t.Errorf("expected only the alpha entity always in context, got %d entities", len(always))
+	}
+	always, err = testDB.ListAlwaysInContext(ctx, 100, nil)
+	if err != nil {
+		t.Fatalf("ListAlwaysInContext failed: %v", err)
+	}
+	if len(always) < 3 {
+		t.Errorf("expected all 3 entities always in context without a filter, got %d", len(always))
+	}
+
+	// Neighbors in a context don't cross into other contexts, not even
+	// through an entity of another context
+	notes, err := testDB.CreateEntity(ctx, models.EntityInput{Type: "document", Name: "Release Notes Alpha", Context: &alpha, Embedding: dummyEmbedding()})
+	if err != nil {
+		t.Fatalf("Failed to create test entity: %v", err)
+	}
+	notesID := models.MustRecordIDString(notes.ID)
+	createdIDs = append(createdIDs, notesID)
+	for _, rel := range [][2]string{
+		{createdIDs[0], createdIDs[1]}, // alpha -> beta
+		{createdIDs[2], createdIDs[0]}, // global -> alpha
+		{createdIDs[1], notesID},       // beta -> alpha notes
+		{notesID, createdIDs[0]},       // alpha notes -> alpha
+	} {
+		if err := testDB.CreateRelation(ctx, models.RelationInput{FromID: rel[0], ToID: rel[1], RelType: "references"}); err != nil {
+			t.Fatalf("CreateRelation failed: %v", err)
+		}
+	}
+	neighbors, err := testDB.GetNeighborsIn(ctx, createdIDs[0], 2, nil, &alpha)
+	if err != nil {
+		t.Fatalf("GetNeighborsIn failed: %v", err)
+	}
+	if len(neighbors) != 1 || neighbors[0].Name != "Release Notes Alpha" {
+		names := make([]string, len(neighbors))
+		for i, n := range neighbors {
+			names[i] = n.Name
+		}
+		t.Errorf("expected only the alpha neighbor, got %v", names)
+	}
+	neighbors, err = testDB.GetNeighborsIn(ctx, createdIDs[0], 1, nil, nil)
+	if err != nil {
+		t.Fatalf("GetNeighborsIn failed: %v", err)
+	}
+	if len(neighbors) != 3 {
+		t.Errorf("expected 3 neighbors without a context filter, got %d", len(neighbors))
+	}
+}
+
+func TestListEntitiesWithoutSummary(t *testing.T) {
+	ctx := context.Background()
+
+	long := strings.Repeat("Long content without a summary. ", 20)
+	short := "Short content"
+	summary := "Already summarized"
+	inputs := map[string]models.EntityInput{
+		"long":       {Type: "document", Name: "Unsummarized Long", Content: &long, Embedding: dummyEmbedding()},
+		"short":      {Type: "document", Name: "Unsummarized Short", Content: &short, Embedding: dummyEmbedding()},
+		"chunked":    {Type: "document", Name: "Unsummarized Chunked", Embedding: dummyEmbedding()},
+		"summarized": {Type: "document", Name: "Summarized Long", Content: &long, Summary: &summary, Embedding: dummyEmbedding()},
+	}
+	ids := map[string]string{}
+	for key, input := range inputs {
+		entity, err := testDB.CreateEntity(ctx, input)
+		if err != nil {
+			t.Fatalf("Failed to create test entity: %v", err)
+		}
+		ids[key] = models.MustRecordIDString(entity.ID)
+	}
+	defer func() {
+		for _, id := range ids {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+	if err := testDB.CreateChunks(ctx, ids["chunked"], []models.ChunkInput{
+		{Content: "Content kept only in chunks", Position: 0, Embedding: dummyEmbedding()},
+	}); err != nil {
+		t.Fatalf("CreateChunks failed: %v", err)
+	}
+
+	// Page one ID at a time through the whole listing
+	listed := map[string]bool{}
+	after := ""
+	for {
+		page, err := testDB.ListEntitiesWithoutSummary(ctx, 500, after, 1)
+		if err != nil {
+			t.Fatalf("ListEntitiesWithoutSummary failed: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		if len(page) != 1 {
+			t.Fatalf("page has %d IDs, want at most 1", len(page))
+		}
+		if listed[page[0]] || page[0] <= after {
+			t.Fatalf("ID %s listed out of order after %q", page[0], after)
+		}
+		listed[page[0]] = true
+		after = page[0]
+	}
+
+	for key, want := range map[string]bool{"long": true, "chunked": true, "short": false, "summarized": false} {
+		if listed[ids[key]] != want {
+			t.Errorf("%s entity listed = %v, want %v", key, listed[ids[key]], want)
+		}
+	}
+}
+
+func TestSearchModes(t *testing.T) {
+	ctx := context.Background()
+
+	// Same embedding: vector search can't tell them apart, BM25 can
+	lexical := "Pod eviction happens when a node runs out of memory"
+	semantic := "Workloads get rescheduled once a host lacks free RAM"
+	var createdIDs []string
+	for name, content := range map[string]*string{"Search mode lexical": &lexical, "Search mode semantic": &semantic} {
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:      "note",
+			Name:      name,
+			Content:   content,
+			Labels:    []string{"search-mode-test"},
+			Embedding: dummyEmbedding(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create test entity: %v", err)
+		}
+		createdIDs = append(createdIDs, models.MustRecordIDString(entity.ID))
+	}
+	defer func() {
+		for _, id := range createdIDs {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{SearchModeKeyword, []string{"Search mode lexical"}},
+		{SearchModeVector, []string{"Search mode lexical", "Search mode semantic"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			opts := SearchOptions{
+				Mode:      tt.mode,
+				Query:     "eviction",
+				Embedding: dummyEmbedding(),
+				Labels:    []string{"search-mode-test"},
+				Limit:     10,
+			}
+
+			entities, err := testDB.HybridSearch(ctx, opts)
+			if err != nil {
+				t.Fatalf("HybridSearch failed: %v", err)
+			}
+			var names []string
+			for _, e := range entities {
+				names = append(names, e.Name)
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("HybridSearch names = %v, want %v", names, tt.want)
+			}
+
+			results, err := testDB.SearchWithChunks(ctx, opts)
+			if err != nil {
+				t.Fatalf("SearchWithChunks failed: %v", err)
+			}
+			names = names[:0]
+			for _, r := range results {
+				names = append(names, r.Name)
+				if r.Score <= 0 {
+					t.Errorf("%s has score %v, want > 0", r.Name, r.Score)
+				}
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("SearchWithChunks names = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchWithChunksRanksChunkHits(t *testing.T) {
+	ctx := context.Background()
+
+	// The query matches the chunk exactly and the entity only loosely
+	query := make([]float32, 384)
+	for i := range query {
+		query[i] = float32(i%5) / 5.0
+	}
+	loose := make([]float32, 384)
+	for i := range loose {
+		loose[i] = query[i] + float32(i%3)/3.0
+	}
+
+	var ids []string
+	defer func() {
+		for _, id := range ids {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+	for _, in := range []models.EntityInput{
+		{Type: "note", Name: "Rank loose entity", Labels: []string{"rank-chunks-test"}, Embedding: loose},
+		{Type: "document", Name: "Rank chunked entity", Labels: []string{"rank-chunks-test"}},
+	} {
+		entity, err := testDB.CreateEntity(ctx, in)
+		if err != nil {
+			t.Fatalf("Failed to create test entity: %v", err)
+		}
+		ids = append(ids, models.MustRecordIDString(entity.ID))
+	}
+	if err := testDB.CreateChunks(ctx, ids[1], []models.ChunkInput{
+		{Content: "Exact match", Position: 0, Labels: []string{"rank-chunks-test"}, Embedding: query},
+	}); err != nil {
+		t.Fatalf("CreateChunks failed: %v", err)
+	}
+
+	results, err := testDB.SearchWithChunks(ctx, SearchOptions{
+		Mode:      SearchModeVector,
+		Embedding: query,
+		Labels:    []string{"rank-chunks-test"},
+		Limit:     1,
+	})
+	if err != nil {
+		t.Fatalf("SearchWithChunks failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "Rank chunked entity" {
+		t.Errorf("SearchWithChunks = %v, want the chunk hit ranked first", results)
+	}
+}
+
+func TestSearchChunks(t *testing.T) {
+	ctx := context.Background()
+
+	entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "document",
+		Name:      "Search chunks runbook",
+		Labels:    []string{"search-chunks-test"},
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test entity: %v", err)
+	}
+	entityID := models.MustRecordIDString(entity.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, entityID)
+	}()
+
+	setup, teardown := "## Setup", "## Teardown"
+	err = testDB.CreateChunks(ctx, entityID, []models.ChunkInput{
+		{Content: "Rotate the signing key before the certificate expires", Position: 0, HeadingPath: &setup, Labels: []string{"search-chunks-test"}, Embedding: dummyEmbedding()},
+		{Content: "Revoke the old certificate once the new one is live", Position: 1, HeadingPath: &teardown, Labels: []string{"search-chunks-test"}, Embedding: dummyEmbedding()},
+		{Content: "Nothing relevant to the query here", Position: 2, Labels: []string{"search-chunks-test"}, Embedding: dummyEmbedding()},
+	})
+	if err != nil {
+		t.Fatalf("CreateChunks failed: %v", err)
+	}
+
+	matches, err := testDB.SearchChunks(ctx, SearchOptions{
+		Mode:   SearchModeKeyword,
+		Query:  "certificate",
+		Labels: []string{"search-chunks-test"},
+		Limit:  10,
+	})
+	if err != nil {
+		t.Fatalf("SearchChunks failed: %v", err)
+	}
+
+	// Both matching chunks of the same entity come back, not one grouped result
+	if len(matches) != 2 {
+		t.Fatalf("got %d chunks, want 2: %+v", len(matches), matches)
+	}
+	positions := []int{}
+	for _, m := range matches {
+		if m.EntityID != entityID || m.EntityName != "Search chunks runbook" {
+			t.Errorf("chunk %d entity = %q (%q), want %q", m.Position, m.EntityID, m.EntityName, entityID)
+		}
+		if m.HeadingPath == nil {
+			t.Errorf("chunk %d has no heading path", m.Position)
+		}
+		if m.Score <= 0 {
+			t.Errorf("chunk %d has score %v, want > 0", m.Position, m.Score)
+		}
+		positions = append(positions, m.Position)
+	}
+	slices.Sort(positions)
+	if !slices.Equal(positions, []int{0, 1}) {
+		t.Errorf("positions = %v, want [0 1]", positions)
+	}
+
+	// Hybrid search fuses vector matches in: all chunks share the embedding
+	matches, err = testDB.SearchChunks(ctx, SearchOptions{
+		Query:     "certificate",
+		Embedding: dummyEmbedding(),
+		Labels:    []string{"search-chunks-test"},
+		Limit:     10,
+	})
+	if err != nil {
+		t.Fatalf("hybrid SearchChunks failed: %v", err)
+	}
+	if len(matches) != 3 {
+		t.Errorf("hybrid search got %d chunks, want 3", len(matches))
+	}
+}
+
+// BenchmarkVectorSearchEfSearch measures vector search latency and recall
+// at several efSearch values on near-duplicate embeddings, where the
+// approximate index is most likely to miss true nearest neighbours. Recall
+// is the share of the exact top 10 found; low efSearch trades it for speed.
+func BenchmarkVectorSearchEfSearch(b *testing.B) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewPCG(1, 2))
+	nearDuplicate := func() []float32 {
+		embedding := dummyEmbedding()
+		for i := range embedding {
+			embedding[i] += float32(rng.NormFloat64() * 0.001)
+		}
+		return embedding
+	}
+
+	var createdIDs []string
+	defer func() {
+		for _, id := range createdIDs {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+	for i := range 300 {
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:      "note",
+			Name:      fmt.Sprintf("ef bench %03d", i),
+			Labels:    []string{"ef-bench"},
+			Embedding: nearDuplicate(),
+		})
+		if err != nil {
+			b.Fatalf("Failed to create test entity: %v", err)
+		}
+		createdIDs = append(createdIDs, models.MustRecordIDString(entity.ID))
+	}
+
+	// Exact nearest neighbours by brute force, bypassing the index
+	query := nearDuplicate()
+	results, err := runQuery[[]string](ctx, testDB, `
+		SELECT VALUE name FROM (
+			SELECT name, vector::similarity::cosine(embedding, $emb) AS similarity FROM entity
+			WHERE labels CONTAINS "ef-bench" ORDER BY similarity DESC LIMIT 10
+		)
+	`, map[string]any{"emb": query})
+	if err != nil {
+		b.Fatalf("exact search failed: %v", err)
+	}
+	exact := (*results)[0].Result
+
+	for _, efSearch := range []int{10, 60, 400} {
+		b.Run(fmt.Sprintf("ef=%d", efSearch), func(b *testing.B) {
+			opts := SearchOptions{
+				Mode:      SearchModeVector,
+				Embedding: query,
+				Labels:    []string{"ef-bench"},
+				EfSearch:  &efSearch,
+				Limit:     len(exact),
+			}
+			var entities []models.Entity
+			for b.Loop() {
+				var err error
+				entities, err = testDB.HybridSearch(ctx, opts)
+				if err != nil {
+					b.Fatalf("HybridSearch failed: %v", err)
+				}
+			}
+
+			found := 0
+			for _, e := range entities {
+				if slices.Contains(exact, e.Name) {
+					found++
+				}
+			}
+			b.ReportMetric(float64(found)/float64(len(exact)), "recall")
+		})
+	}
+}
+
+func TestSearchMinConfidence(t *testing.T) {
+	ctx := context.Background()
+
+	content := "Confidence filter runbook"
+	var createdIDs []string
+	for _, confidence := range []float64{0.3, 0.6, 0.9} {
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:       "note",
+			Name:       fmt.Sprintf("Confidence %.1f", confidence),
+			Content:    &content,
+			Labels:     []string{"min-confidence-test"},
+			Confidence: &confidence,
+			Embedding:  dummyEmbedding(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create test entity: %v", err)
+		}
+		createdIDs = append(createdIDs, models.MustRecordIDString(entity.ID))
+	}
+	defer func() {
+		for _, id := range createdIDs {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	minConfidence := 0.5
+	opts := SearchOptions{
+		Query:         "confidence filter runbook",
+		Embedding:     dummyEmbedding(),
+		Labels:        []string{"min-confidence-test"},
+		MinConfidence: &minConfidence,
+		Limit:         10,
+	}
+	want := []string{"Confidence 0.6", "Confidence 0.9"}
+
+	entities, err := testDB.HybridSearch(ctx, opts)
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	var names []string
+	for _, e := range entities {
+		names = append(names, e.Name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, want) {
+		t.Errorf("HybridSearch names = %v, want %v", names, want)
+	}
+
+	results, err := testDB.SearchWithChunks(ctx, opts)
+	if err != nil {
+		t.Fatalf("SearchWithChunks failed: %v", err)
+	}
+	names = names[:0]
+	for _, r := range results {
+		names = append(names, r.Name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, want) {
+		t.Errorf("SearchWithChunks names = %v, want %v", names, want)
+	}
+}
+
+func TestHybridSearchDecay(t *testing.T) {
+	ctx := context.Background()
+
+	content := "Rollback procedure for the billing service"
+	var ids []string
+	for _, name := range []string{"Rollback Stale", "Rollback Fresh"} {
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:      "decay-rank",
+			Name:      name,
+			Content:   &content,
+			Embedding: dummyEmbedding(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create test entity: %v", err)
+		}
+		ids = append(ids, models.MustRecordIDString(entity.ID))
+	}
+	defer func() {
+		for _, id := range ids {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	if _, err := testDB.Query(ctx, `UPDATE entity SET accessed = time::now() - 30d WHERE name = "Rollback Stale"`, nil); err != nil {
+		t.Fatalf("Failed to age entity: %v", err)
+	}
+
+	opts := SearchOptions{
+		Query:      "rollback procedure",
+		Embedding:  dummyEmbedding(),
+		Types:      []string{"decay-rank"},
+		ApplyDecay: true,
+		Decay:      DecayConfig{Curve: DecayExponential, HalfLifeDays: 10, MinWeight: 0.1},
+		Limit:      10,
+	}
+	results, err := testDB.HybridSearch(ctx, opts)
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "Rollback Fresh" || results[1].Name != "Rollback Stale" {
+		t.Fatalf("expected Rollback Fresh before Rollback Stale, got %v", entityNames(results))
+	}
+
+	// Without a half-life nothing decays
+	opts.Decay.HalfLifeDays = 0
+	results, err = testDB.HybridSearch(ctx, opts)
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "Rollback Stale" {
+		t.Fatalf("expected unweighted Rollback Stale first, got %v", entityNames(results))
+	}
+}
+
+// entityNames returns the names of entities, for test failure messages.
+func entityNames(entities []models.Entity) []string {
+	names := make([]string, len(entities))
+	for i, e := range entities {
+		names[i] = e.Name
+	}
+	return names
+}
+
+func TestListEntitiesHasMetadataKeys(t *testing.T) {
+	ctx := context.Background()
+
+	withTicket, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "task",
+		Name:      "Metadata Key Test Ticket",
+		Metadata:  map[string]any{"jira_ticket": "KH-42"},
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entity with metadata: %v", err)
+	}
+	withoutTicket, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "task",
+		Name:      "Metadata Key Test Plain",
+		Metadata:  map[string]any{"owner": "someone"},
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entity without ticket: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, models.MustRecordIDString(withTicket.ID))
+		_, _ = testDB.DeleteEntity(ctx, models.MustRecordIDString(withoutTicket.ID))
+	}()
+
+	entities, err := testDB.ListEntities(ctx, ListOptions{Type: "task", HasMetadataKeys: []string{"jira_ticket"}})
+	if err != nil {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+	if len(entities) != 1 || entities[0].Name != "Metadata Key Test Ticket" {
+		names := make([]string, len(entities))
+		for i, e := range entities {
+			names[i] = e.Name
+		}
+		t.Errorf("Expected only the ticket entity, got %v", names)
+	}
+}
+
+// =============================================================================
+// CHUNK TESTS
+// =============================================================================
+
+func TestCreateAndGetChunks(t *testing.T) {
+	ctx := context.Background()
+
+	// Create test entity
+	content := "Long content that would be chunked"
+	entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "document",
+		Name:      "Chunk Test Doc",
+		Content:   &content,
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test entity: %v", err)
+	}
+	entityID := models.MustRecordIDString(entity.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, entityID)
+	}()
+
+	// Create chunks
+	headingPath := "## Section 1"
+	chunks := []models.ChunkInput{
+		{EntityID: entityID, Content: "Chunk 1 content", Position: 0, HeadingPath: &headingPath, Embedding: dummyEmbedding()},
+		{EntityID: entityID, Content: "Chunk 2 content", Position: 1, Embedding: dummyEmbedding()},
+	}
+
+	err = testDB.CreateChunks(ctx, entityID, chunks)
+	if err != nil {
+		t.Fatalf("CreateChunks failed: %v", err)
+	}
+
+	// Get chunks
+	retrieved, err := testDB.GetChunks(ctx, entityID)
+	if err != nil {
+		t.Fatalf("GetChunks failed: %v", err)
+	}
+	if len(retrieved) != 2 {
+		t.Errorf("Expected 2 chunks, got %d", len(retrieved))
+	}
+	if retrieved[0].Position != 0 {
+		t.Error("Chunks should be ordered by position")
+	}
+}
+
+func TestDeleteChunks(t *testing.T) {
+	ctx := context.Background()
+
+	// Create test entity with chunks
+	content := "Content for chunk deletion test"
+	entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "document",
+		Name:      "Delete Chunks Test",
+		Content:   &content,
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test entity: %v", err)
+	}
+	entityID := models.MustRecordIDString(entity.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, entityID)
+	}()
+
+	chunks := []models.ChunkInput{
+		{EntityID: entityID, Content: "To be deleted", Position: 0},
+	}
+	_ = testDB.CreateChunks(ctx, entityID, chunks)
+
+	// Delete chunks
+	err = testDB.DeleteChunks(ctx, entityID)
+	if err != nil {
+		t.Fatalf("DeleteChunks failed: %v", err)
+	}
+
+	// Verify gone
+	retrieved, err := testDB.GetChunks(ctx, entityID)
+	if err != nil {
+		t.Fatalf("GetChunks after delete failed: %v", err)
+	}
+	if len(retrieved) != 0 {
+		t.Errorf("Expected 0 chunks after delete, got %d", len(retrieved))
+	}
+}
+
+func TestCompact(t *testing.T) {
+	ctx := context.Background()
+
+	content := "Compact test content"
+	kept, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "document",
+		Name:      "Compact Test Kept",
+		Content:   &content,
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create kept entity: %v", err)
+	}
+	empty, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type: "note",
+		Name: "Compact Test Empty",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create empty entity: %v", err)
+	}
+	// Chunked entities store their content only in chunks
+	chunked, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type: "document",
+		Name: "Compact Test Chunked",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create chunked entity: %v", err)
+	}
+	keptID := models.MustRecordIDString(kept.ID)
+	emptyID := models.MustRecordIDString(empty.ID)
+	chunkedID := models.MustRecordIDString(chunked.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, keptID)
+		_, _ = testDB.DeleteEntity(ctx, emptyID)
+		_, _ = testDB.DeleteEntity(ctx, chunkedID)
+	}()
+	if err := testDB.CreateChunks(ctx, chunkedID, []models.ChunkInput{
+		{EntityID: chunkedID, Content: "Chunked content", Position: 0, Embedding: dummyEmbedding()},
+	}); err != nil {
+		t.Fatalf("Failed to create chunk: %v", err)
+	}
+
+	// Chunks pointing at an entity that doesn't exist
+	ghostID := "compact-test-ghost"
+	if err := testDB.CreateChunks(ctx, ghostID, []models.ChunkInput{
+		{EntityID: ghostID, Content: "Orphaned", Position: 0, Embedding: dummyEmbedding()},
+	}); err != nil {
+		t.Fatalf("Failed to create orphaned chunk: %v", err)
+	}
+
+	exists := func(id string) bool {
+		t.Helper()
+		e, err := testDB.GetEntity(ctx, id)
+		if err != nil {
+			t.Fatalf("GetEntity failed: %v", err)
+		}
+		return e != nil
+	}
+	hasEmpty := func(report CompactReport) bool {
+		for _, e := range report.EmptyEntities {
+			if models.MustRecordIDString(e.ID) == emptyID {
+				return true
+			}
+		}
+		return false
+	}
+
+	report, err := testDB.Compact(ctx, true)
+	if err != nil {
+		t.Fatalf("Compact dry run failed: %v", err)
+	}
+	if report.OrphanedChunks < 1 {
+		t.Errorf("Expected at least 1 orphaned chunk, got %d", report.OrphanedChunks)
+	}
+	if !hasEmpty(report) {
+		t.Error("Expected empty entity in dry run report")
+	}
+	if !exists(emptyID) {
+		t.Fatal("Dry run must not delete entities")
+	}
+
+	report, err = testDB.Compact(ctx, false)
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if !hasEmpty(report) {
+		t.Error("Expected empty entity in report")
+	}
+	if exists(emptyID) {
+		t.Error("Expected empty entity to be deleted")
+	}
+	if !exists(keptID) {
+		t.Error("Expected entity with content to be kept")
+	}
+	if !exists(chunkedID) {
+		t.Error("Expected chunked entity to be kept")
+	}
+	if got, err := testDB.GetChunks(ctx, chunkedID); err != nil || len(got) != 1 {
+		t.Errorf("Expected chunk of chunked entity to be kept, got %d (%v)", len(got), err)
+	}
+	chunks, err := testDB.GetChunks(ctx, ghostID)
+	if err != nil {
+		t.Fatalf("GetChunks failed: %v", err)
+	}
+	if len(chunks) != 0 {
+		t.Errorf("Expected orphaned chunks to be deleted, got %d", len(chunks))
+	}
+}
+
+// =============================================================================
+// RELATION TESTS
+// =============================================================================
+
+func TestCreateAndGetRelations(t *testing.T) {
+	ctx := context.Background()
+
+	// Create two entities
+	content1 := "First entity"
+	content2 := "Second entity"
+	entity1, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "concept",
+		Name:      "Relation Test 1",
+		Content:   &content1,
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entity 1: %v", err)
+	}
+	entity2, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "concept",
+		Name:      "Relation Test 2",
+		Content:   &content2,
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entity 2: %v", err)
+	}
+
+	id1 := models.MustRecordIDString(entity1.ID)
+	id2 := models.MustRecordIDString(entity2.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, id1)
+		_, _ = testDB.DeleteEntity(ctx, id2)
+	}()
+
+	// Create relation
+	err = testDB.CreateRelation(ctx, models.RelationInput{
+		FromID:  id1,
+		ToID:    id2,
+		RelType: "relates_to",
+	})
+	if err != nil {
+		t.Fatalf("CreateRelation failed: %v", err)
+	}
+
+	// Get relations for entity1
+	relations, err := testDB.GetRelations(ctx, id1)
+	if err != nil {
+		t.Fatalf("GetRelations failed: %v", err)
+	}
+	if len(relations) == 0 {
+		t.Error("Expected at least one relation")
+	}
+
+	// Get relations for entity2 (should also see the relation)
+	relations, err = testDB.GetRelations(ctx, id2)
+	if err != nil {
+		t.Fatalf("GetRelations for entity2 failed: %v", err)
+	}
+	if len(relations) == 0 {
+		t.Error("Expected to find relation from entity2 perspective")
+	}
+}
+
+func TestCreateRelations(t *testing.T) {
+	ctx := context.Background()
+
+	ids := make([]string, 3)
+	for i := range ids {
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:      "concept",
+			Name:      fmt.Sprintf("Batch Relation Test %d", i),
+			Embedding: dummyEmbedding(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create entity %d: %v", i, err)
+		}
+		ids[i] = models.MustRecordIDString(entity.ID)
+	}
+	defer func() {
+		for _, id := range ids {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	strength := 0.4
+	created, err := testDB.CreateRelations(ctx, []models.RelationInput{
+		{FromID: ids[0], ToID: ids[1], RelType: "depends_on"},
+		{FromID: ids[1], ToID: ids[2], RelType: "depends_on"},
+		// Same pair and type as the first: updates instead of duplicating
+		{FromID: ids[0], ToID: ids[1], RelType: "depends_on", Strength: &strength},
+	})
+	if err != nil {
+		t.Fatalf("CreateRelations failed: %v", err)
+	}
+	if created != 3 {
+		t.Errorf("CreateRelations() = %d, want 3", created)
+	}
+
+	relations, err := testDB.GetRelations(ctx, ids[1])
+	if err != nil {
+		t.Fatalf("GetRelations failed: %v", err)
+	}
+	if len(relations) != 2 {
+		t.Fatalf("Expected 2 relations for middle entity, got %d", len(relations))
+	}
+
+	relations, err = testDB.GetRelations(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("GetRelations failed: %v", err)
+	}
+	if len(relations) != 1 || relations[0].Strength != strength {
+		t.Errorf("Expected one relation with strength %v, got %+v", strength, relations)
+	}
+}
+
+func TestCreateRelationConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	ids := make([]string, 2)
+	for i := range ids {
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:      "concept",
+			Name:      fmt.Sprintf("Concurrent Relation Test %d", i),
+			Embedding: dummyEmbedding(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create entity %d: %v", i, err)
+		}
+		ids[i] = models.MustRecordIDString(entity.ID)
+	}
+	defer func() {
+		for _, id := range ids {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	// Like graph extraction workers finding the same relation at once
+	const callers = 4
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- testDB.CreateRelation(ctx, models.RelationInput{FromID: ids[0], ToID: ids[1], RelType: "concurrent_rel"})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("CreateRelation failed: %v", err)
+		}
+	}
+
+	relations, err := testDB.GetRelations(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("GetRelations failed: %v", err)
+	}
+	if len(relations) != 1 {
+		t.Errorf("Expected exactly 1 relation, got %d", len(relations))
+	}
+}
+
+func TestGraphAnalytics(t *testing.T) {
+	ctx := context.Background()
+
+	ids := make([]string, 3)
+	for i := range ids {
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:      "concept",
+			Name:      fmt.Sprintf("Analytics Test %d", i),
+			Embedding: dummyEmbedding(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create entity %d: %v", i, err)
+		}
+		ids[i] = models.MustRecordIDString(entity.ID)
+	}
+	defer func() {
+		for _, id := range ids {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	before, err := testDB.GraphAnalytics(ctx, 100)
+	if err != nil {
+		t.Fatalf("GraphAnalytics failed: %v", err)
+	}
+
+	// Hub with two spokes
+	for _, id := range ids[1:] {
+		if err := testDB.CreateRelation(ctx, models.RelationInput{FromID: ids[0], ToID: id, RelType: "analytics_test"}); err != nil {
+			t.Fatalf("CreateRelation failed: %v", err)
+		}
+	}
+
+	after, err := testDB.GraphAnalytics(ctx, 100)
+	if err != nil {
+		t.Fatalf("GraphAnalytics failed: %v", err)
+	}
+	if after.Edges != before.Edges+2 {
+		t.Errorf("Expected %d edges, got %d", before.Edges+2, after.Edges)
+	}
+	if after.Orphans != before.Orphans-3 {
+		t.Errorf("Expected %d orphans, got %d", before.Orphans-3, after.Orphans)
+	}
+
+	var hubDegree int
+	for _, e := range after.TopConnected {
+		if e.ID == ids[0] {
+			hubDegree = e.Degree
+		}
+	}
+	if hubDegree != 2 {
+		t.Errorf("Expected hub degree 2, got %d", hubDegree)
+	}
+
+	var typeCount int
+	for _, rt := range after.RelationTypes {
+		if rt.RelType == "analytics_test" {
+			typeCount = rt.Count
+		}
+	}
+	if typeCount != 2 {
+		t.Errorf("Expected 2 analytics_test relations, got %d", typeCount)
+	}
+}
+
+func TestDeleteRelation(t *testing.T) {
+	ctx := context.Background()
+
+	// Create two entities with relation
+	content1 := "Delete relation test 1"
+	content2 := "Delete relation test 2"
+	entity1, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "concept",
+		Name:      "Delete Rel Test 1",
+		Content:   &content1,
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entity 1: %v", err)
+	}
+	entity2, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "concept",
+		Name:      "Delete Rel Test 2",
+		Content:   &content2,
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entity 2: %v", err)
+	}
+
+	id1 := models.MustRecordIDString(entity1.ID)
+	id2 := models.MustRecordIDString(entity2.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, id1)
+		_, _ = testDB.DeleteEntity(ctx, id2)
+	}()
+
+	_ = testDB.CreateRelation(ctx, models.RelationInput{
+		FromID:  id1,
+		ToID:    id2,
+		RelType: "test_rel",
+	})
+
+	// Delete relation
+	err = testDB.DeleteRelation(ctx, id1, id2, "test_rel")
+	if err != nil {
+		t.Fatalf("DeleteRelation failed: %v", err)
+	}
+
+	// Verify gone
+	relations, _ := testDB.GetRelations(ctx, id1)
+	for _, rel := range relations {
+		if rel.RelType == "test_rel" {
+			t.Error("Relation should have been deleted")
+		}
+	}
+}
+
+func TestGetNeighbors(t *testing.T) {
+	ctx := context.Background()
+
+	ids := map[string]string{}
+	for _, name := range []string{"Neighbor A", "Neighbor B", "Neighbor C", "Neighbor D"} {
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{Type: "concept", Name: name, Embedding: dummyEmbedding()})
+		if err != nil {
+			t.Fatalf("Failed to create test entity: %v", err)
+		}
+		ids[name] = models.MustRecordIDString(entity.ID)
+	}
+	defer func() {
+		for _, id := range ids {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	// A -> B -> C, A -> C (shortcut), D -> B (points at the path)
+	for _, rel := range []struct{ from, to, relType string }{
+		{"Neighbor A", "Neighbor B", "depends_on"},
+		{"Neighbor B", "Neighbor C", "depends_on"},
+		{"Neighbor A", "Neighbor C", "references"},
+		{"Neighbor D", "Neighbor B", "depends_on"},
+	} {
+		if err := testDB.CreateRelation(ctx, models.RelationInput{FromID: ids[rel.from], ToID: ids[rel.to], RelType: rel.relType}); err != nil {
+			t.Fatalf("CreateRelation failed: %v", err)
+		}
+	}
+
+	distances := func(neighbors []Neighbor) map[string]int {
+		m := make(map[string]int, len(neighbors))
+		for _, n := range neighbors {
+			m[n.Name] = n.Distance
+		}
+		return m
+	}
+
+	neighbors, err := testDB.GetNeighbors(ctx, ids["Neighbor A"], 2, nil)
+	if err != nil {
+		t.Fatalf("GetNeighbors failed: %v", err)
+	}
+	want := map[string]int{"Neighbor B": 1, "Neighbor C": 1, "Neighbor D": 2}
+	if got := distances(neighbors); len(neighbors) != 3 || fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("GetNeighbors depth 2 = %v, want %v", got, want)
+	}
+	if neighbors[len(neighbors)-1].Name != "Neighbor D" {
+		t.Errorf("expected nearest first, got %s last", neighbors[len(neighbors)-1].Name)
+	}
+
+	// Without the shortcut, C is two hops away
+	neighbors, err = testDB.GetNeighbors(ctx, ids["Neighbor A"], 2, []string{"depends_on"})
+	if err != nil {
+		t.Fatalf("GetNeighbors failed: %v", err)
+	}
+	want = map[string]int{"Neighbor B": 1, "Neighbor C": 2, "Neighbor D": 2}
+	if got := distances(neighbors); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("GetNeighbors depends_on = %v, want %v", got, want)
+	}
+}
+
+func TestCreateContradiction(t *testing.T) {
+	ctx := context.Background()
+
+	var ids []string
+	for _, name := range []string{"Contradiction Alpha", "Contradiction Beta"} {
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{Type: "concept", Name: name, Embedding: dummyEmbedding()})
+		if err != nil {
+			t.Fatalf("Failed to create test entity: %v", err)
+		}
+		ids = append(ids, models.MustRecordIDString(entity.ID))
+	}
+	defer func() {
+		for _, id := range ids {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	similar, err := testDB.FindSimilarEntities(ctx, dummyEmbedding(), ids[0], 50)
+	if err != nil {
+		t.Fatalf("FindSimilarEntities failed: %v", err)
+	}
+	for _, e := range similar {
+		if models.MustRecordIDString(e.ID) == ids[0] {
+			t.Error("FindSimilarEntities returned the excluded entity")
+		}
+	}
+
+	// Chunked entities have no embedding of their own and match by their chunks
+	chunked, err := testDB.CreateEntity(ctx, models.EntityInput{Type: "document", Name: "Contradiction Chunked"})
+	if err != nil {
+		t.Fatalf("Failed to create chunked entity: %v", err)
+	}
+	chunkedID := models.MustRecordIDString(chunked.ID)
+	ids = append(ids, chunkedID)
+	chunkEmbedding := make([]float32, 384)
+	for i := range chunkEmbedding {
+		chunkEmbedding[i] = float32(383-i) / 384.0
+	}
+	if err := testDB.CreateChunks(ctx, chunkedID, []models.ChunkInput{
+		{Content: "The service is owned by team A", Position: 0, Embedding: chunkEmbedding},
+		{Content: "It runs in three regions", Position: 1, Embedding: chunkEmbedding},
+	}); err != nil {
+		t.Fatalf("CreateChunks failed: %v", err)
+	}
+	similar, err = testDB.FindSimilarEntities(ctx, chunkEmbedding, ids[0], 5)
+	if err != nil {
+		t.Fatalf("FindSimilarEntities failed: %v", err)
+	}
+	if len(similar) == 0 || models.MustRecordIDString(similar[0].ID) != chunkedID {
+		t.Fatalf("FindSimilarEntities did not return the chunked entity first: %v", similar)
+	}
+	for _, e := range similar[1:] {
+		if models.MustRecordIDString(e.ID) == chunkedID {
+			t.Error("FindSimilarEntities returned the chunked entity once per chunk")
+		}
+	}
+
+	confidence := 0.9
+	input := models.ContradictionInput{FromID: ids[0], ToID: ids[1], Explanation: "different owners", Confidence: &confidence}
+	created, err := testDB.CreateContradiction(ctx, input)
+	if err != nil {
+		t.Fatalf("CreateContradiction failed: %v", err)
+	}
+	if !created {
+		t.Fatal("expected contradiction to be created")
+	}
+
+	// Resolved contradictions aren't re-created, in either direction
+	if _, err := testDB.Query(ctx, `UPDATE contradicts SET resolved = true WHERE explanation = "different owners"`, nil); err != nil {
+		t.Fatalf("Failed to resolve contradiction: %v", err)
+	}
+	reversed := models.ContradictionInput{FromID: ids[1], ToID: ids[0], Explanation: "different owners again", Confidence: &confidence}
+	for _, in := range []models.ContradictionInput{input, reversed} {
+		created, err := testDB.CreateContradiction(ctx, in)
+		if err != nil {
+			t.Fatalf("CreateContradiction failed: %v", err)
+		}
+		if created {
+			t.Errorf("expected contradiction %s -> %s to be skipped", in.FromID, in.ToID)
+		}
+	}
+}
+
+func TestFindSimilarPairs(t *testing.T) {
+	ctx := context.Background()
+
+	// An embedding no other test uses, so only this test's entities pair up
+	emb := make([]float32, 384)
+	for i := range emb {
+		emb[i] = float32(i%7) / 7.0
+	}
+	other := make([]float32, 384)
+	for i := range other {
+		other[i] = float32(i%2) - 0.5
+	}
+
+	var ids []string
+	defer func() {
+		for _, id := range ids {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+	for _, in := range []models.EntityInput{
+		{Type: "concept", Name: "Pair Alpha", Embedding: emb},
+		{Type: "concept", Name: "Pair Beta", Embedding: emb},
+		{Type: "concept", Name: "Pair Unrelated", Embedding: other},
+		{Type: "document", Name: "Pair Chunked"},
+	} {
+		entity, err := testDB.CreateEntity(ctx, in)
+		if err != nil {
+			t.Fatalf("Failed to create test entity: %v", err)
+		}
+		ids = append(ids, models.MustRecordIDString(entity.ID))
+	}
+	if err := testDB.CreateChunks(ctx, ids[3], []models.ChunkInput{
+		{Content: "Same as alpha", Position: 0, Embedding: emb},
+	}); err != nil {
+		t.Fatalf("CreateChunks failed: %v", err)
+	}
+
+	mine := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		mine[id] = true
+	}
+
+	// One entity per page, so the cursor is followed across every page
+	found := make(map[[2]string]bool)
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 10000 {
+			t.Fatal("FindSimilarPairs did not stop paging")
+		}
+		page, err := testDB.FindSimilarPairs(ctx, 0.99, 5, 1, cursor)
+		if err != nil {
+			t.Fatalf("FindSimilarPairs failed: %v", err)
+		}
+		for _, p := range page.Pairs {
+			a, b := models.MustRecordIDString(p.A.ID), models.MustRecordIDString(p.B.ID)
+			if !mine[a] || !mine[b] {
+				continue
+			}
+			if p.Similarity < 0.99 {
+				t.Errorf("pair %s-%s has similarity %f below the threshold", p.A.Name, p.B.Name, p.Similarity)
+			}
+			if a > b {
+				a, b = b, a
+			}
+			found[[2]string{a, b}] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	key := func(a, b string) [2]string {
+		if a > b {
+			a, b = b, a
+		}
+		return [2]string{a, b}
+	}
+	want := [][2]string{key(ids[0], ids[1]), key(ids[0], ids[3]), key(ids[1], ids[3])}
+	for _, k := range want {
+		if !found[k] {
+			t.Errorf("pair %v not found", k)
+		}
+	}
+	if len(found) != len(want) {
+		t.Errorf("found %d pairs, want %d: %v", len(found), len(want), found)
+	}
+}
+
+func TestRebuildRelationKeys(t *testing.T) {
+	ctx := context.Background()
+
+	entity1, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "concept",
+		Name:      "Rebuild Keys Test 1",
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entity 1: %v", err)
+	}
+	entity2, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "concept",
+		Name:      "Rebuild Keys Test 2",
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entity 2: %v", err)
+	}
+
+	id1 := models.MustRecordIDString(entity1.ID)
+	id2 := models.MustRecordIDString(entity2.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, id1)
+		_, _ = testDB.DeleteEntity(ctx, id2)
+	}()
+
+	if err := testDB.CreateRelation(ctx, models.RelationInput{FromID: id1, ToID: id2, RelType: "rebuild_rel"}); err != nil {
+		t.Fatalf("CreateRelation failed: %v", err)
+	}
+
+	removed, err := testDB.RebuildRelationKeys(ctx)
+	if err != nil {
+		t.Fatalf("RebuildRelationKeys failed: %v", err)
+	}
+	if removed != 0 {
+		t.Errorf("Expected no duplicates removed, got %d", removed)
+	}
+
+	// Relation must survive the rebuild
+	relations, err := testDB.GetRelations(ctx, id1)
+	if err != nil {
+		t.Fatalf("GetRelations failed: %v", err)
+	}
+	if len(relations) != 1 {
+		t.Errorf("Expected 1 relation after rebuild, got %d", len(relations))
+	}
+}
+
+func TestListDanglingRelations(t *testing.T) {
+	ctx := context.Background()
+
+	entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "concept",
+		Name:      "Dangling Relation Test",
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+	id := models.MustRecordIDString(entity.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, id)
+	}()
+
+	// Relation to an entity that doesn't exist
+	if err := testDB.CreateRelation(ctx, models.RelationInput{FromID: id, ToID: "dangling-relation-ghost", RelType: "references"}); err != nil {
+		t.Fatalf("CreateRelation failed: %v", err)
+	}
+
+	dangling, err := testDB.ListDanglingRelations(ctx)
+	if err != nil {
+		t.Fatalf("ListDanglingRelations failed: %v", err)
+	}
+	var ids []surrealmodels.RecordID
+	for _, rel := range dangling {
+		if models.MustRecordIDString(rel.In) == id {
+			ids = append(ids, rel.ID)
+		}
+	}
+	if len(ids) != 1 {
+		t.Fatalf("Expected 1 dangling relation from %s, got %d", id, len(ids))
+	}
+
+	if err := testDB.DeleteRelationsByID(ctx, ids); err != nil {
+		t.Fatalf("DeleteRelationsByID failed: %v", err)
+	}
+	relations, err := testDB.GetRelations(ctx, id)
+	if err != nil {
+		t.Fatalf("GetRelations failed: %v", err)
+	}
+	if len(relations) != 0 {
+		t.Errorf("Expected relation deleted, got %d", len(relations))
+	}
+}
+
+func TestMergeEntities(t *testing.T) {
+	ctx := context.Background()
+
+	create := func(name string, labels []string, confidence float64) string {
+		t.Helper()
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:       "concept",
+			Name:       name,
+			Labels:     labels,
+			Confidence: &confidence,
+			Embedding:  dummyEmbedding(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		return models.MustRecordIDString(entity.ID)
+	}
+	keepID := create("Merge Keep", []string{"a"}, 0.5)
+	mergeID := create("Merge Duplicate", []string{"b"}, 0.8)
+	otherID := create("Merge Other", nil, 0.5)
+	defer func() {
+		for _, id := range []string{keepID, mergeID, otherID} {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	weak, strong := 0.3, 0.9
+	for _, rel := range []models.RelationInput{
+		{FromID: keepID, ToID: otherID, RelType: "uses", Strength: &weak},
+		{FromID: mergeID, ToID: otherID, RelType: "uses", Strength: &strong}, // duplicates keep's relation
+		{FromID: otherID, ToID: mergeID, RelType: "owns"},
+		{FromID: keepID, ToID: mergeID, RelType: "same_as"}, // would become a self-loop
+	} {
+		if err := testDB.CreateRelation(ctx, rel); err != nil {
+			t.Fatalf("CreateRelation failed: %v", err)
+		}
+	}
+	if err := testDB.CreateChunks(ctx, mergeID, []models.ChunkInput{
+		{Content: "duplicate chunk", Position: 0, Embedding: dummyEmbedding()},
+	}); err != nil {
+		t.Fatalf("CreateChunks failed: %v", err)
+	}
+
+	merged, err := testDB.MergeEntities(ctx, keepID, mergeID)
+	if err != nil {
+		t.Fatalf("MergeEntities failed: %v", err)
+	}
+	if merged.Confidence != 0.8 {
+		t.Errorf("Expected higher confidence 0.8, got %f", merged.Confidence)
+	}
+	if len(merged.Labels) != 2 {
+		t.Errorf("Expected union of labels, got %v", merged.Labels)
+	}
+
+	if gone, err := testDB.GetEntity(ctx, mergeID); err != nil || gone != nil {
+		t.Errorf("Expected merged entity deleted, got %v (err=%v)", gone, err)
+	}
+
+	relations, err := testDB.GetRelations(ctx, keepID)
+	if err != nil {
+		t.Fatalf("GetRelations failed: %v", err)
+	}
+	byType := make(map[string][]models.Relation)
+	for _, rel := range relations {
+		byType[rel.RelType] = append(byType[rel.RelType], rel)
+	}
+	if len(byType["uses"]) != 1 || byType["uses"][0].Strength != 0.9 {
+		t.Errorf("Expected one uses relation with max strength 0.9, got %v", byType["uses"])
+	}
+	if len(byType["owns"]) != 1 || models.MustRecordIDString(byType["owns"][0].Out) != keepID {
+		t.Errorf("Expected owns relation repointed to %s, got %v", keepID, byType["owns"])
+	}
+	if len(byType["same_as"]) != 0 {
+		t.Errorf("Expected self-loop dropped, got %v", byType["same_as"])
+	}
+
+	chunks, err := testDB.GetChunks(ctx, keepID)
+	if err != nil {
+		t.Fatalf("GetChunks failed: %v", err)
+	}
+	if len(chunks) != 1 {
+		t.Errorf("Expected chunk re-parented, got %d chunks", len(chunks))
+	}
+
+	if _, err := testDB.MergeEntities(ctx, keepID, keepID); err == nil {
+		t.Error("Expected error merging an entity into itself")
+	}
+}
+
+// =============================================================================
+// TEMPLATE TESTS
+// =============================================================================
+
+func TestTemplates(t *testing.T) {
+	ctx := context.Background()
+
+	// Create template
+	description := "Test template description"
+	template, err := testDB.CreateTemplate(ctx, models.TemplateInput{
+		Name:        "Test Template",
+		Description: &description,
+		Content:     "# {{name}}\n\n{{content}}",
+	})
+	if err != nil {
+		t.Fatalf("CreateTemplate failed: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.DeleteTemplate(ctx, "Test Template")
+	}()
+
+	if template.Name != "Test Template" {
+		t.Errorf("Expected name 'Test Template', got %q", template.Name)
+	}
+
+	// Get template
+	retrieved, err := testDB.GetTemplate(ctx, "Test Template")
+	if err != nil {
+		t.Fatalf("GetTemplate failed: %v", err)
+	}
+	if retrieved == nil {
+		t.Fatal("GetTemplate returned nil")
+	}
+
+	// List templates
+	templates, err := testDB.ListTemplates(ctx)
+	if err != nil {
+		t.Fatalf("ListTemplates failed: %v", err)
+	}
+	found := false
+	for _, tmpl := range templates {
+		if tmpl.Name == "Test Template" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("ListTemplates should include created template")
+	}
+
+	// Delete template
+	deleted, err := testDB.DeleteTemplate(ctx, "Test Template")
+	if err != nil {
+		t.Fatalf("DeleteTemplate failed: %v", err)
+	}
+	if !deleted {
+		t.Error("DeleteTemplate should return true")
+	}
+}
+
+// =============================================================================
+// TOKEN USAGE TESTS
+// =============================================================================
+
+func TestTokenUsage(t *testing.T) {
+	ctx := context.Background()
+
+	// Record usage
+	err := testDB.RecordTokenUsage(ctx, models.TokenUsageInput{
+		Operation:    "test_embed",
+		Model:        "test-model",
+		InputTokens:  100,
+		OutputTokens: 50,
+	})
+	if err != nil {
+		t.Fatalf("RecordTokenUsage failed: %v", err)
+	}
+
+	// Get summary (from start of time to capture our record)
+	summary, err := testDB.GetTokenUsageSummary(ctx, "2020-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("GetTokenUsageSummary failed: %v", err)
+	}
+	if summary.TotalTokens < 150 {
+		t.Errorf("Expected at least 150 total tokens, got %d", summary.TotalTokens)
+	}
+}
+
+func TestListConversationsFilters(t *testing.T) {
+	ctx := context.Background()
+
+	created := map[string]time.Time{
+		"Range January":  time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC),
+		"Range February": time.Date(2020, 2, 10, 0, 0, 0, 0, time.UTC),
+		"Range March":    time.Date(2020, 3, 10, 0, 0, 0, 0, time.UTC),
+	}
+	for title, at := range created {
+		conv, err := testDB.CreateConversation(ctx, title, nil)
+		if err != nil {
+			t.Fatalf("CreateConversation failed: %v", err)
+		}
+		id := models.MustRecordIDString(conv.ID)
+		defer func() {
+			if _, err := testDB.DeleteConversation(ctx, id); err != nil {
+				t.Errorf("DeleteConversation failed: %v", err)
+			}
+		}()
+		if _, err := testDB.Query(ctx, `UPDATE type::record("conversation", $id) SET created_at = $at`,
+			map[string]any{"id": id, "at": at}); err != nil {
+			t.Fatalf("set created_at failed: %v", err)
+		}
+	}
+
+	titles := func(convs []models.Conversation) []string {
+		result := make([]string, len(convs))
+		for i, c := range convs {
+			result[i] = c.Title
+		}
+		return result
+	}
+
+	after := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	opts := ConversationListOptions{CreatedAfter: &after, CreatedBefore: &before}
+	convs, err := testDB.ListConversations(ctx, opts)
+	if err != nil {
+		t.Fatalf("ListConversations failed: %v", err)
+	}
+	if got := titles(convs); !slices.Equal(got, []string{"Range February"}) {
+		t.Errorf("expected only the February conversation, got %v", got)
+	}
+	count, err := testDB.CountConversations(ctx, opts)
+	if err != nil {
+		t.Fatalf("CountConversations failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected count 1, got %d", count)
+	}
+
+	// Sorted by creation, newest first; unknown sort keys sort by update
+	after = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	before = time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	opts = ConversationListOptions{CreatedAfter: &after, CreatedBefore: &before, Sort: ConversationSortCreated}
+	convs, err = testDB.ListConversations(ctx, opts)
+	if err != nil {
+		t.Fatalf("ListConversations failed: %v", err)
+	}
+	if got, want := titles(convs), []string{"Range March", "Range February", "Range January"}; !slices.Equal(got, want) {
+		t.Errorf("sort created: got %v, want %v", got, want)
+	}
+	opts.Sort = "bogus"
+	convs, err = testDB.ListConversations(ctx, opts)
+	if err != nil {
+		t.Fatalf("ListConversations with unknown sort failed: %v", err)
+	}
+	if len(convs) != 3 {
+		t.Errorf("unknown sort: expected 3 conversations, got %d", len(convs))
+	}
+}
+
+func TestConversationTokenUsage(t *testing.T) {
+	ctx := context.Background()
+
+	conversationID := "budget-test-conversation"
+	for _, tokens := range []int{100, 250} {
+		if err := testDB.RecordTokenUsage(ctx, models.TokenUsageInput{
+			Operation:      "llm_stream",
+			Model:          "test-model",
+			InputTokens:    tokens,
+			OutputTokens:   50,
+			ConversationID: &conversationID,
+		}); err != nil {
+			t.Fatalf("RecordTokenUsage failed: %v", err)
+		}
+	}
+	// Usage of other calls doesn't count
+	if err := testDB.RecordTokenUsage(ctx, models.TokenUsageInput{Operation: "llm_stream", Model: "test-model", InputTokens: 1000}); err != nil {
+		t.Fatalf("RecordTokenUsage failed: %v", err)
+	}
+
+	used, err := testDB.GetConversationTokenUsage(ctx, conversationID)
+	if err != nil {
+		t.Fatalf("GetConversationTokenUsage failed: %v", err)
+	}
+	if used != 450 {
+		t.Errorf("Expected 450 tokens, got %d", used)
+	}
+
+	used, err = testDB.GetConversationTokenUsage(ctx, "no-such-conversation")
+	if err != nil {
+		t.Fatalf("GetConversationTokenUsage failed: %v", err)
+	}
+	if used != 0 {
+		t.Errorf("Expected 0 tokens for unknown conversation, got %d", used)
+	}
+}
+
+func TestWaitForVectorIndex(t *testing.T) {
+	ctx := context.Background()
+
+	if err := testDB.WaitForVectorIndex(ctx, 30*time.Second); err != nil {
+		t.Fatalf("WaitForVectorIndex failed: %v", err)
+	}
+	if status := testDB.IndexStatus(); status != IndexStatusReady {
+		t.Errorf("Expected index status %q, got %q", IndexStatusReady, status)
+	}
+}
+
+func TestDecayWeight(t *testing.T) {
+	now := time.Now()
+	daysAgo := func(days float64) time.Time {
+		return now.Add(-time.Duration(days * 24 * float64(time.Hour)))
+	}
+	exponential := DecayConfig{
+		Curve:            DecayExponential,
+		HalfLifeDays:     10,
+		TypeHalfLifeDays: map[string]float64{"task": 5, "concept": 0},
+		MinWeight:        0.1,
+	}
+	linear := DecayConfig{Curve: DecayLinear, HalfLifeDays: 10, MinWeight: 0.1}
+
+	tests := []struct {
+		name       string
+		cfg        DecayConfig
+		entityType string
+		accessed   time.Time
+		pinned     bool
+		want       float64
+	}{
+		{"just accessed", exponential, "note", now, false, 1.0},
+		{"one half-life", exponential, "note", daysAgo(10), false, 0.5},
+		{"two per-type half-lives", exponential, "task", daysAgo(10), false, 0.25},
+		{"floor", exponential, "note", daysAgo(365), false, 0.1},
+		{"type without decay", exponential, "concept", daysAgo(365), false, 1.0},
+		{"pinned", exponential, "note", daysAgo(365), true, 1.0},
+		{"accessed in the future", exponential, "note", now.Add(time.Hour), false, 1.0},
+		{"linear one half-life", linear, "note", daysAgo(10), false, 0.5},
+		{"linear floor", linear, "note", daysAgo(30), false, 0.1},
+		{"no half-life", DecayConfig{}, "note", daysAgo(365), false, 1.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.cfg.Weight(tt.entityType, tt.accessed, tt.pinned, now)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Weight() = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDecay(t *testing.T) {
+	ctx := context.Background()
+
+	note, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "decay-note",
+		Name:      "Decay Test Note",
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	noteID := models.MustRecordIDString(note.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, noteID)
+	}()
+
+	// Pretend it was last accessed 10 days ago
+	if _, err := testDB.Query(ctx, `UPDATE entity SET accessed = time::now() - 10d WHERE type = "decay-note"`, nil); err != nil {
+		t.Fatalf("Failed to age entity: %v", err)
+	}
+	before, err := testDB.GetEntity(ctx, noteID)
+	if err != nil || before == nil {
+		t.Fatalf("GetEntity failed: entity=%v err=%v", before, err)
+	}
+
+	// Decay weights aren't stored, so applying decay leaves entities untouched
+	cfg := DecayConfig{Curve: DecayExponential, HalfLifeDays: 10, MinWeight: 0.1}
+	if err := testDB.ApplyDecay(ctx, cfg); err != nil {
+		t.Fatalf("ApplyDecay failed: %v", err)
+	}
+	after, err := testDB.GetEntity(ctx, noteID)
+	if err != nil || after == nil {
+		t.Fatalf("GetEntity failed: entity=%v err=%v", after, err)
+	}
+	if !after.UpdatedAt.Equal(before.UpdatedAt) {
+		t.Errorf("ApplyDecay changed updated_at from %v to %v", before.UpdatedAt, after.UpdatedAt)
+	}
+	if w := cfg.Weight(after.Type, after.Accessed, after.Pinned, time.Now()); w < 0.49 || w > 0.51 {
+		t.Errorf("Expected weight ~0.5, got %f", w)
+	}
+
+	// Accessing resets the weight
+	if err := testDB.UpdateEntityAccess(ctx, noteID); err != nil {
+		t.Fatalf("UpdateEntityAccess failed: %v", err)
+	}
+	accessed, err := testDB.GetEntity(ctx, noteID)
+	if err != nil || accessed == nil {
+		t.Fatalf("GetEntity failed: entity=%v err=%v", accessed, err)
+	}
+	if w := cfg.Weight(accessed.Type, accessed.Accessed, accessed.Pinned, time.Now()); w < 0.99 {
+		t.Errorf("Expected weight ~1.0 after access, got %f", w)
+	}
+}
+
+func TestApplyDecayPinned(t *testing.T) {
+	ctx := context.Background()
+
+	pinned, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "decay-pinned",
+		Name:      "Decay Pinned Roster",
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pinned entity: %v", err)
+	}
+	peer, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "decay-pinned",
+		Name:      "Decay Unpinned Peer",
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create peer: %v", err)
+	}
+	pinnedID := models.MustRecordIDString(pinned.ID)
+	peerID := models.MustRecordIDString(peer.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, pinnedID)
+		_, _ = testDB.DeleteEntity(ctx, peerID)
+	}()
+
+	updated, err := testDB.SetPinned(ctx, pinnedID, true)
+	if err != nil {
+		t.Fatalf("SetPinned failed: %v", err)
+	}
+	if !updated.Pinned {
+		t.Error("Pinned = false after pinning")
+	}
+
+	// Pretend both were last accessed 10 days ago
+	if _, err := testDB.Query(ctx, `UPDATE entity SET accessed = time::now() - 10d WHERE type = "decay-pinned"`, nil); err != nil {
+		t.Fatalf("Failed to age entities: %v", err)
+	}
+
+	cfg := DecayConfig{Curve: DecayExponential, HalfLifeDays: 10, MinWeight: 0.1}
+	weight := func(id string) float64 {
+		t.Helper()
+		e, err := testDB.GetEntity(ctx, id)
+		if err != nil || e == nil {
+			t.Fatalf("Expected entity %s, got err=%v", id, err)
+		}
+		return cfg.Weight(e.Type, e.Accessed, e.Pinned, time.Now())
+	}
+	if w := weight(pinnedID); w != 1.0 {
+		t.Errorf("Expected pinned weight 1.0, got %f", w)
+	}
+	if w := weight(peerID); w >= 1.0 {
+		t.Errorf("Expected unpinned peer to decay, got %f", w)
+	}
+}
+
+func TestApplyDecayAIConfidence(t *testing.T) {
+	ctx := context.Background()
+
+	aiSource := models.SourceAIGenerated
+	verified := true
+	confidence := 0.6
+	unverified, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:       "decay-ai",
+		Name:       "Decay AI Unverified",
+		Source:     &aiSource,
+		Confidence: &confidence,
+		Embedding:  dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+	checked, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:       "decay-ai",
+		Name:       "Decay AI Verified",
+		Source:     &aiSource,
+		Verified:   &verified,
+		Confidence: &confidence,
+		Embedding:  dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+	kept, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:       "decay-ai",
+		Name:       "Decay AI Pinned",
+		Source:     &aiSource,
+		Confidence: &confidence,
+		Embedding:  dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+	unverifiedID := models.MustRecordIDString(unverified.ID)
+	checkedID := models.MustRecordIDString(checked.ID)
+	keptID := models.MustRecordIDString(kept.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, unverifiedID)
+		_, _ = testDB.DeleteEntity(ctx, checkedID)
+		_, _ = testDB.DeleteEntity(ctx, keptID)
+	}()
+	if _, err := testDB.SetPinned(ctx, keptID, true); err != nil {
+		t.Fatalf("SetPinned failed: %v", err)
+	}
+
+	// Pretend both were created 30 days ago
+	if _, err := testDB.Query(ctx, `UPDATE entity SET created_at = time::now() - 30d WHERE type = "decay-ai"`, nil); err != nil {
+		t.Fatalf("Failed to age entities: %v", err)
+	}
+
+	cfg := DecayConfig{
+		HalfLifeDays:             90,
+		MinWeight:                0.1,
+		AIConfidence:             0.6,
+		AIConfidenceHalfLifeDays: 30,
+		MinConfidence:            0.2,
+	}
+	if err := testDB.ApplyDecay(ctx, cfg); err != nil {
+		t.Fatalf("ApplyDecay failed: %v", err)
+	}
+
+	got := func(id string) float64 {
+		t.Helper()
+		e, err := testDB.GetEntity(ctx, id)
+		if err != nil || e == nil {
+			t.Fatalf("Expected entity %s, got err=%v", id, err)
+		}
+		return e.Confidence
+	}
+
+	// One half-life elapsed: 0.3; verified and pinned entities keep their confidence
+	if c := got(unverifiedID); c < 0.29 || c > 0.31 {
+		t.Errorf("Expected unverified confidence ~0.3, got %f", c)
+	}
+	if c := got(checkedID); c != 0.6 {
+		t.Errorf("Expected verified confidence 0.6, got %f", c)
+	}
+	if c := got(keptID); c != 0.6 {
+		t.Errorf("Expected pinned confidence 0.6, got %f", c)
+	}
+}
+
+func TestReviewQueue(t *testing.T) {
+	ctx := context.Background()
+
+	aiSource := models.SourceAIGenerated
+	var ids []string
+	for _, e := range []struct {
+		name       string
+		confidence float64
+	}{
+		{"Review Sure", 0.9},
+		{"Review Unsure", 0.3},
+		{"Review Medium", 0.6},
+	} {
+		confidence := e.confidence
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:       "review-test",
+			Name:       e.name,
+			Source:     &aiSource,
+			Confidence: &confidence,
+			Embedding:  dummyEmbedding(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create entity: %v", err)
+		}
+		ids = append(ids, models.MustRecordIDString(entity.ID))
+	}
+	defer func() {
+		for _, id := range ids {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	queue, err := testDB.GetReviewQueue(ctx, ReviewQueueOptions{Types: []string{"review-test"}})
+	if err != nil {
+		t.Fatalf("GetReviewQueue failed: %v", err)
+	}
+	if len(queue) != 3 || queue[0].Name != "Review Unsure" || queue[2].Name != "Review Sure" {
+		t.Fatalf("Expected entities by ascending confidence, got %v", queue)
+	}
+
+	// Verified entities leave the queue
+	verified, err := testDB.SetVerified(ctx, []string{ids[1], "review-missing"}, true)
+	if err != nil {
+		t.Fatalf("SetVerified failed: %v", err)
+	}
+	if len(verified) != 1 {
+		t.Errorf("Expected 1 entity verified, got %d", len(verified))
+	}
+
+	queue, err = testDB.GetReviewQueue(ctx, ReviewQueueOptions{Types: []string{"review-test"}, Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("GetReviewQueue failed: %v", err)
+	}
+	if len(queue) != 1 || queue[0].Name != "Review Sure" {
+		t.Errorf("Expected second page to hold Review Sure, got %v", queue)
+	}
+
+	if _, err := testDB.GetReviewQueue(ctx, ReviewQueueOptions{Priority: "newest"}); err == nil {
+		t.Error("Expected error for invalid priority")
+	}
+}
+
+func TestAnswerSourceStats(t *testing.T) {
+	ctx := context.Background()
+	since := time.Now().Add(-time.Minute).Format(time.RFC3339)
+
+	if err := testDB.RecordAnswerSources(ctx, "answer-1", []models.AnswerSourceInput{
+		{EntityID: "stats-popular", Rank: 1, Included: true},
+		{EntityID: "stats-rare", Rank: 2, Included: false},
+	}); err != nil {
+		t.Fatalf("RecordAnswerSources failed: %v", err)
+	}
+	if err := testDB.RecordAnswerSources(ctx, "answer-2", []models.AnswerSourceInput{
+		{EntityID: "stats-popular", Rank: 3, Included: true},
+	}); err != nil {
+		t.Fatalf("RecordAnswerSources failed: %v", err)
+	}
+	defer func() {
+		if _, err := testDB.Query(ctx, `DELETE answer_source WHERE answer_id IN ["answer-1", "answer-2"]`, nil); err != nil {
+			t.Logf("cleanup failed: %v", err)
+		}
+	}()
+
+	stats, err := testDB.GetAnswerSourceStats(ctx, since, 0)
+	if err != nil {
+		t.Fatalf("GetAnswerSourceStats failed: %v", err)
+	}
+
+	byID := make(map[string]models.AnswerSourceStats)
+	for _, st := range stats {
+		byID[st.EntityID] = st
+	}
+	popular, rare := byID["stats-popular"], byID["stats-rare"]
+	if popular.Retrieved != 2 || popular.Included != 2 || popular.AvgRank != 2 {
+		t.Errorf("Unexpected stats for popular source: %+v", popular)
+	}
+	if rare.Retrieved != 1 || rare.Included != 0 {
+		t.Errorf("Unexpected stats for rare source: %+v", rare)
+	}
+	if len(stats) < 2 || stats[0].Included < stats[len(stats)-1].Included {
+		t.Errorf("Expected stats ordered by inclusions, got %+v", stats)
+	}
+}
+
+func TestMetricsSnapshots(t *testing.T) {
+	ctx := context.Background()
+
+	count := int64(3)
+	err := testDB.SaveMetricsSnapshot(ctx, metrics.Snapshot{
+		UptimeSeconds: 42,
+		Embedding: &metrics.OperationSnapshot{
+			Count:       count,
+			TotalTimeMs: 30,
+			AvgTimeMs:   10,
+			MinTimeMs:   5,
+			MaxTimeMs:   15,
+		},
+	})
+	if err != nil {
+		t.Fatalf("SaveMetricsSnapshot failed: %v", err)
+	}
+
+	snapshots, err := testDB.GetMetricsSnapshots(ctx, "2020-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("GetMetricsSnapshots failed: %v", err)
+	}
+	if len(snapshots) == 0 {
+		t.Fatal("Expected at least one metrics snapshot")
+	}
+
+	last := snapshots[len(snapshots)-1]
+	if last.Embedding == nil || last.Embedding.Count != count {
+		t.Errorf("Expected embedding count %d, got %+v", count, last.Embedding)
+	}
+	if last.LLMGenerate != nil {
+		t.Errorf("Expected no llm_generate stats, got %+v", last.LLMGenerate)
+	}
+}
+
+func TestListIngestJobs(t *testing.T) {
+	ctx := context.Background()
 
-func TestCreateAndGetRelations(t *testing.T) {
+	ids := []string{"list-jobs-a", "list-jobs-b", "list-jobs-c"}
+	for _, id := range ids {
+		if err := testDB.CreateIngestJob(ctx, id, "ingest", "", "/tmp/docs", []string{"a.md"}, nil, nil); err != nil {
+			t.Fatalf("CreateIngestJob %s failed: %v", id, err)
+		}
+		time.Sleep(10 * time.Millisecond) // distinct started_at for ordering
+	}
+	if err := testDB.CompleteJob(ctx, ids[0], map[string]any{"files_processed": 1}); err != nil {
+		t.Fatalf("CompleteJob failed: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.Query(ctx, `DELETE ingest_job WHERE record::id(id) IN $ids`, map[string]any{"ids": ids})
+	}()
+
+	completed := "completed"
+	jobs, err := testDB.ListIngestJobs(ctx, &completed, 100, 0)
+	if err != nil {
+		t.Fatalf("ListIngestJobs failed: %v", err)
+	}
+	found := false
+	for _, j := range jobs {
+		if j.Status != completed {
+			t.Errorf("Expected only completed jobs, got status %q", j.Status)
+		}
+		if models.MustRecordIDString(j.ID) == ids[0] {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected completed job in filtered list")
+	}
+
+	page, err := testDB.ListIngestJobs(ctx, nil, 2, 0)
+	if err != nil {
+		t.Fatalf("ListIngestJobs page failed: %v", err)
+	}
+	if len(page) != 2 {
+		t.Fatalf("Expected page of 2 jobs, got %d", len(page))
+	}
+	if page[0].StartedAt.Before(page[1].StartedAt) {
+		t.Error("Expected most recent job first")
+	}
+	next, err := testDB.ListIngestJobs(ctx, nil, 2, 1)
+	if err != nil {
+		t.Fatalf("ListIngestJobs offset failed: %v", err)
+	}
+	if len(next) == 0 || next[0].ID != page[1].ID {
+		t.Error("Expected offset 1 to start at the second job")
+	}
+}
+
+func TestCancelledJobNotResumed(t *testing.T) {
 	ctx := context.Background()
 
-	// Create two entities
-	content1 := "First entity"
-	content2 := "Second entity"
+	id := "cancelled-job"
+	if err := testDB.CreateIngestJob(ctx, id, "ingest", "", "/tmp/docs", []string{"a.md", "b.md"}, nil, nil); err != nil {
+		t.Fatalf("CreateIngestJob failed: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.Query(ctx, `DELETE type::record("ingest_job", $id)`, map[string]any{"id": id})
+	}()
+
+	if err := testDB.UpdateJobStatus(ctx, id, "cancelled"); err != nil {
+		t.Fatalf("UpdateJobStatus failed: %v", err)
+	}
+	// The goroutine stopping later stores its result without reviving the job
+	if err := testDB.SetJobResult(ctx, id, 1, map[string]any{"files_processed": 1}); err != nil {
+		t.Fatalf("SetJobResult failed: %v", err)
+	}
+
+	job, err := testDB.GetIngestJob(ctx, id)
+	if err != nil {
+		t.Fatalf("GetIngestJob failed: %v", err)
+	}
+	if job == nil || job.Status != "cancelled" || job.Progress != 1 || job.CompletedAt == nil {
+		t.Errorf("Expected cancelled job with progress 1 and completion time, got %+v", job)
+	}
+
+	incomplete, err := testDB.GetIncompleteJobs(ctx)
+	if err != nil {
+		t.Fatalf("GetIncompleteJobs failed: %v", err)
+	}
+	for _, j := range incomplete {
+		if models.MustRecordIDString(j.ID) == id {
+			t.Error("Cancelled job listed as incomplete, would be resumed")
+		}
+	}
+}
+
+func TestGetExistingHashes(t *testing.T) {
+	ctx := context.Background()
+
+	// Create entities with content hashes
+	hash1 := "abc123def456"
+	hash2 := "xyz789ghi012"
+	content := "test content"
+
 	entity1, err := testDB.CreateEntity(ctx, models.EntityInput{
-		Type:      "concept",
-		Name:      "Relation Test 1",
-		Content:   &content1,
-		Embedding: dummyEmbedding(),
+		Type:        "document",
+		Name:        "Hash Test 1",
+		Content:     &content,
+		ContentHash: &hash1,
+		Embedding:   dummyEmbedding(),
 	})
 	if err != nil {
-		t.Fatalf("Failed to create entity 1: %v", err)
+		t.Fatalf("CreateEntity 1 failed: %v", err)
 	}
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, models.MustRecordIDString(entity1.ID))
+	}()
+
 	entity2, err := testDB.CreateEntity(ctx, models.EntityInput{
-		Type:      "concept",
-		Name:      "Relation Test 2",
-		Content:   &content2,
+		Type:        "document",
+		Name:        "Hash Test 2",
+		Content:     &content,
+		ContentHash: &hash2,
+		Embedding:   dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("CreateEntity 2 failed: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, models.MustRecordIDString(entity2.ID))
+	}()
+
+	// Query with mix of existing and non-existing hashes
+	hashes := []string{hash1, "nonexistent", hash2, "alsonotexist"}
+	existing, err := testDB.GetExistingHashes(ctx, hashes)
+	if err != nil {
+		t.Fatalf("GetExistingHashes failed: %v", err)
+	}
+
+	if len(existing) != 2 {
+		t.Errorf("Expected 2 existing hashes, got %d: %v", len(existing), existing)
+	}
+
+	// Verify the correct hashes were returned
+	found := make(map[string]bool)
+	for _, h := range existing {
+		found[h] = true
+	}
+	if !found[hash1] || !found[hash2] {
+		t.Errorf("Expected hashes %s and %s, got %v", hash1, hash2, existing)
+	}
+
+	// Empty input should return empty result
+	empty, err := testDB.GetExistingHashes(ctx, []string{})
+	if err != nil {
+		t.Fatalf("GetExistingHashes with empty input failed: %v", err)
+	}
+	if len(empty) != 0 {
+		t.Errorf("Expected empty result, got %v", empty)
+	}
+}
+
+func TestGetEntitiesByIDs(t *testing.T) {
+	ctx := context.Background()
+
+	entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "document",
+		Name:      "IDs Lookup Test",
 		Embedding: dummyEmbedding(),
 	})
 	if err != nil {
-		t.Fatalf("Failed to create entity 2: %v", err)
+		t.Fatalf("CreateEntity failed: %v", err)
 	}
+	id := models.MustRecordIDString(entity.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, id)
+	}()
 
-	id1 := models.MustRecordIDString(entity1.ID)
-	id2 := models.MustRecordIDString(entity2.ID)
+	found, err := testDB.GetEntitiesByIDs(ctx, []string{id, "does-not-exist"})
+	if err != nil {
+		t.Fatalf("GetEntitiesByIDs failed: %v", err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("Expected 1 entity, got %d", len(found))
+	}
+	if found[id] == nil || found[id].Name != "IDs Lookup Test" {
+		t.Errorf("Expected entity %s in result, got %v", id, found)
+	}
+
+	empty, err := testDB.GetEntitiesByIDs(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetEntitiesByIDs with empty input failed: %v", err)
+	}
+	if len(empty) != 0 {
+		t.Errorf("Expected empty result, got %v", empty)
+	}
+}
+
+func TestClearEntityContent(t *testing.T) {
+	ctx := context.Background()
+
+	content := "content that moves to chunks"
+	entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "document",
+		Name:      "Clear Content Test",
+		Content:   &content,
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("CreateEntity failed: %v", err)
+	}
+	id := models.MustRecordIDString(entity.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, id)
+	}()
+
+	if err := testDB.ClearEntityContent(ctx, id); err != nil {
+		t.Fatalf("ClearEntityContent failed: %v", err)
+	}
+
+	got, err := testDB.GetEntity(ctx, id)
+	if err != nil {
+		t.Fatalf("GetEntity failed: %v", err)
+	}
+	if got == nil {
+		t.Fatal("entity missing after ClearEntityContent")
+	}
+	if got.Content != nil {
+		t.Errorf("Content = %q, want none", *got.Content)
+	}
+}
+
+func TestClearContentHash(t *testing.T) {
+	ctx := context.Background()
+
+	hash := "abc123"
+	entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:        "document",
+		Name:        "Clear Hash Test",
+		ContentHash: &hash,
+		Embedding:   dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("CreateEntity failed: %v", err)
+	}
+	id := models.MustRecordIDString(entity.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, id)
+	}()
+
+	if err := testDB.ClearContentHash(ctx, id); err != nil {
+		t.Fatalf("ClearContentHash failed: %v", err)
+	}
+
+	got, err := testDB.GetEntity(ctx, id)
+	if err != nil {
+		t.Fatalf("GetEntity failed: %v", err)
+	}
+	if got == nil {
+		t.Fatal("entity missing after ClearContentHash")
+	}
+	if got.ContentHash != nil {
+		t.Errorf("ContentHash = %q, want none", *got.ContentHash)
+	}
+}
+
+func TestGetEntitiesModifiedSince(t *testing.T) {
+	ctx := context.Background()
+
+	since := time.Now().Add(-time.Second)
+	var ids []string
+	for i := range 3 {
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:      "document",
+			Name:      fmt.Sprintf("Modified Since Test %d", i),
+			Labels:    []string{"modified-since-test"},
+			Embedding: dummyEmbedding(),
+		})
+		if err != nil {
+			t.Fatalf("CreateEntity failed: %v", err)
+		}
+		ids = append(ids, models.MustRecordIDString(entity.ID))
+	}
+	defer func() {
+		for _, id := range ids {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	// Page through everything changed since the start, two at a time
+	seen := make(map[string]bool)
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 100 {
+			t.Fatal("pagination did not terminate")
+		}
+		page, err := testDB.GetEntitiesModifiedSince(ctx, since, 2, cursor)
+		if err != nil {
+			t.Fatalf("GetEntitiesModifiedSince failed: %v", err)
+		}
+		if len(page.Entities) > 2 {
+			t.Fatalf("page has %d entities, want at most 2", len(page.Entities))
+		}
+		for i, e := range page.Entities {
+			id := models.MustRecordIDString(e.ID)
+			if seen[id] {
+				t.Errorf("entity %s returned twice", id)
+			}
+			seen[id] = true
+			if !e.ModifiedAt.After(since) {
+				t.Errorf("entity %s modified at %v, not after %v", id, e.ModifiedAt, since)
+			}
+			if i > 0 && e.ModifiedAt.Before(page.Entities[i-1].ModifiedAt) {
+				t.Error("entities not ordered by modified_at")
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			t.Errorf("entity %s missing from changes", id)
+		}
+	}
+
+	// Nothing changed after now
+	page, err := testDB.GetEntitiesModifiedSince(ctx, time.Now().Add(time.Hour), 10, "")
+	if err != nil {
+		t.Fatalf("GetEntitiesModifiedSince failed: %v", err)
+	}
+	if len(page.Entities) != 0 || page.NextCursor != "" {
+		t.Errorf("got %d entities and cursor %q for a future since, want none", len(page.Entities), page.NextCursor)
+	}
+
+	if _, err := testDB.GetEntitiesModifiedSince(ctx, since, 10, "not a cursor!"); err == nil {
+		t.Error("expected error for invalid cursor")
+	}
+
+	// Access tracking isn't a change; editing content is
+	checkpoint := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	if err := testDB.UpdateEntityAccess(ctx, ids[0]); err != nil {
+		t.Fatalf("UpdateEntityAccess failed: %v", err)
+	}
+	content := "Edited content"
+	if _, err := testDB.UpdateEntity(ctx, ids[1], models.EntityUpdate{Content: &content}); err != nil {
+		t.Fatalf("UpdateEntity failed: %v", err)
+	}
+	page, err = testDB.GetEntitiesModifiedSince(ctx, checkpoint, 10, "")
+	if err != nil {
+		t.Fatalf("GetEntitiesModifiedSince failed: %v", err)
+	}
+	var changed []string
+	for _, e := range page.Entities {
+		if id := models.MustRecordIDString(e.ID); id == ids[0] || id == ids[1] {
+			changed = append(changed, id)
+		}
+	}
+	if !slices.Equal(changed, []string{ids[1]}) {
+		t.Errorf("changed since checkpoint = %v, want only the edited entity %s", changed, ids[1])
+	}
+}
+
+func TestSetEntityLabels(t *testing.T) {
+	ctx := context.Background()
+
+	entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "document",
+		Name:      "Set Labels Test",
+		Labels:    []string{"K8s", "kubernetes"},
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("CreateEntity failed: %v", err)
+	}
+	id := models.MustRecordIDString(entity.ID)
+	defer func() {
+		_, _ = testDB.DeleteEntity(ctx, id)
+	}()
+
+	if err := testDB.CreateChunks(ctx, id, []models.ChunkInput{
+		{EntityID: id, Content: "chunk", Position: 0, Labels: entity.Labels, Embedding: dummyEmbedding()},
+	}); err != nil {
+		t.Fatalf("CreateChunks failed: %v", err)
+	}
+
+	all, err := testDB.ListEntityLabels(ctx)
+	if err != nil {
+		t.Fatalf("ListEntityLabels failed: %v", err)
+	}
+	if got := all[id]; len(got) != 2 {
+		t.Errorf("ListEntityLabels()[%s] = %v, want 2 labels", id, got)
+	}
+
+	if err := testDB.SetEntityLabels(ctx, id, []string{"kubernetes"}); err != nil {
+		t.Fatalf("SetEntityLabels failed: %v", err)
+	}
+
+	got, err := testDB.GetEntity(ctx, id)
+	if err != nil {
+		t.Fatalf("GetEntity failed: %v", err)
+	}
+	if len(got.Labels) != 1 || got.Labels[0] != "kubernetes" {
+		t.Errorf("entity labels = %v, want [kubernetes]", got.Labels)
+	}
+
+	chunks, err := testDB.GetChunks(ctx, id)
+	if err != nil {
+		t.Fatalf("GetChunks failed: %v", err)
+	}
+	for _, c := range chunks {
+		if len(c.Labels) != 1 || c.Labels[0] != "kubernetes" {
+			t.Errorf("chunk labels = %v, want [kubernetes]", c.Labels)
+		}
+	}
+}
+
+func TestAlwaysInContext(t *testing.T) {
+	ctx := context.Background()
+
+	entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "concept",
+		Name:      "Always In Context Test",
+		Embedding: dummyEmbedding(),
+	})
+	if err != nil {
+		t.Fatalf("CreateEntity failed: %v", err)
+	}
+	id := models.MustRecordIDString(entity.ID)
 	defer func() {
-		_, _ = testDB.DeleteEntity(ctx, id1)
-		_, _ = testDB.DeleteEntity(ctx, id2)
+		_, _ = testDB.DeleteEntity(ctx, id)
 	}()
 
-	// Create relation
-	err = testDB.CreateRelation(ctx, models.RelationInput{
-		FromID:  id1,
-		ToID:    id2,
-		RelType: "relates_to",
-	})
-	if err != nil {
-		t.Fatalf("CreateRelation failed: %v", err)
+	if entity.AlwaysInContext {
+		t.Error("new entity should not be always in context")
 	}
 
-	// Get relations for entity1
-	relations, err := testDB.GetRelations(ctx, id1)
+	updated, err := testDB.SetAlwaysInContext(ctx, id, true)
 	if err != nil {
-		t.Fatalf("GetRelations failed: %v", err)
+		t.Fatalf("SetAlwaysInContext failed: %v", err)
 	}
-	if len(relations) == 0 {
-		t.Error("Expected at least one relation")
+	if !updated.AlwaysInContext {
+		t.Error("AlwaysInContext = false after enabling")
 	}
 
-	// Get relations for entity2 (should also see the relation)
-	relations, err = testDB.GetRelations(ctx, id2)
-	if err != nil {
-		t.Fatalf("GetRelations for entity2 failed: %v", err)
+	listed := func() bool {
+		entities, err := testDB.ListAlwaysInContext(ctx, 100, nil)
+		if err != nil {
+			t.Fatalf("ListAlwaysInContext failed: %v", err)
+		}
+		for _, e := range entities {
+			if models.MustRecordIDString(e.ID) == id {
+				return true
+			}
+		}
+		return false
 	}
-	if len(relations) == 0 {
-		t.Error("Expected to find relation from entity2 perspective")
+	if !listed() {
+		t.Error("entity missing from ListAlwaysInContext")
+	}
+
+	if _, err := testDB.SetAlwaysInContext(ctx, id, false); err != nil {
+		t.Fatalf("SetAlwaysInContext failed: %v", err)
+	}
+	if listed() {
+		t.Error("entity still listed after disabling")
+	}
+
+	if _, err := testDB.SetAlwaysInContext(ctx, "does-not-exist", true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetAlwaysInContext(missing) error = %v, want ErrNotFound", err)
 	}
 }
 
-func TestDeleteRelation(t *testing.T) {
+func TestGetEntitiesBySourcePathPrefix(t *testing.T) {
 	ctx := context.Background()
 
-	// Create two entities with relation
-	content1 := "Delete relation test 1"
-	content2 := "Delete relation test 2"
-	entity1, err := testDB.CreateEntity(ctx, models.EntityInput{
-		Type:      "concept",
-		Name:      "Delete Rel Test 1",
-		Content:   &content1,
-		Embedding: dummyEmbedding(),
-	})
-	if err != nil {
-		t.Fatalf("Failed to create entity 1: %v", err)
+	create := func(name, path string, source models.EntitySource) string {
+		t.Helper()
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:       "document",
+			Name:       name,
+			Source:     &source,
+			SourcePath: &path,
+			Embedding:  dummyEmbedding(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		return models.MustRecordIDString(entity.ID)
 	}
-	entity2, err := testDB.CreateEntity(ctx, models.EntityInput{
-		Type:      "concept",
-		Name:      "Delete Rel Test 2",
-		Content:   &content2,
-		Embedding: dummyEmbedding(),
-	})
+	scrapedID := create("Prune Scraped", "prune-docs/a.md", models.SourceScrape)
+	nestedID := create("Prune Nested", "prune-docs/sub/b.md", models.SourceScrape)
+	manualID := create("Prune Manual", "prune-docs/c.md", models.SourceManual)
+	siblingID := create("Prune Sibling", "prune-docs-old/d.md", models.SourceScrape)
+	defer func() {
+		for _, id := range []string{scrapedID, nestedID, manualID, siblingID} {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
+	}()
+
+	paths, err := testDB.GetEntitiesBySourcePathPrefix(ctx, "prune-docs/", models.SourceScrape)
 	if err != nil {
-		t.Fatalf("Failed to create entity 2: %v", err)
+		t.Fatalf("GetEntitiesBySourcePathPrefix failed: %v", err)
 	}
 
-	id1 := models.MustRecordIDString(entity1.ID)
-	id2 := models.MustRecordIDString(entity2.ID)
+	want := map[string]string{
+		scrapedID: "prune-docs/a.md",
+		nestedID:  "prune-docs/sub/b.md",
+	}
+	if !maps.Equal(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+}
+
+func TestDeleteEntitiesByLabel(t *testing.T) {
+	ctx := context.Background()
+
+	create := func(name string, labels []string) string {
+		t.Helper()
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:      "note",
+			Name:      name,
+			Labels:    labels,
+			Embedding: dummyEmbedding(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		return models.MustRecordIDString(entity.ID)
+	}
+	throwawayID := create("Throwaway One", []string{"bulk-delete-test"})
+	bothID := create("Throwaway Two", []string{"keep", "bulk-delete-test"})
+	keptID := create("Kept Note", []string{"keep"})
 	defer func() {
-		_, _ = testDB.DeleteEntity(ctx, id1)
-		_, _ = testDB.DeleteEntity(ctx, id2)
+		for _, id := range []string{throwawayID, bothID, keptID} {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
 	}()
 
-	_ = testDB.CreateRelation(ctx, models.RelationInput{
-		FromID:  id1,
-		ToID:    id2,
-		RelType: "test_rel",
+	err := testDB.CreateChunks(ctx, throwawayID, []models.ChunkInput{
+		{EntityID: throwawayID, Content: "Throwaway chunk", Position: 0, Embedding: dummyEmbedding()},
 	})
+	if err != nil {
+		t.Fatalf("CreateChunks failed: %v", err)
+	}
 
-	// Delete relation
-	err = testDB.DeleteRelation(ctx, id1, id2, "test_rel")
+	deleted, err := testDB.DeleteEntitiesByLabel(ctx, "bulk-delete-test")
 	if err != nil {
-		t.Fatalf("DeleteRelation failed: %v", err)
+		t.Fatalf("DeleteEntitiesByLabel failed: %v", err)
+	}
+	if len(deleted) != 2 {
+		t.Errorf("Expected 2 deleted entities, got %d", len(deleted))
 	}
 
-	// Verify gone
-	relations, _ := testDB.GetRelations(ctx, id1)
-	for _, rel := range relations {
-		if rel.RelType == "test_rel" {
-			t.Error("Relation should have been deleted")
+	for _, id := range []string{throwawayID, bothID} {
+		if entity, err := testDB.GetEntity(ctx, id); err != nil || entity != nil {
+			t.Errorf("Expected %s to be deleted, got %v (err %v)", id, entity, err)
 		}
 	}
-}
+	if entity, err := testDB.GetEntity(ctx, keptID); err != nil || entity == nil {
+		t.Errorf("Expected %s to be kept (err %v)", keptID, err)
+	}
+	if chunks, err := testDB.GetChunks(ctx, throwawayID); err != nil || len(chunks) != 0 {
+		t.Errorf("Expected chunks to be deleted with their entity, got %d (err %v)", len(chunks), err)
+	}
 
-// =============================================================================
-// TEMPLATE TESTS
-// =============================================================================
+	if _, err := testDB.DeleteEntitiesByLabel(ctx, ""); err == nil {
+		t.Error("Expected error for empty label")
+	}
+}
 
-func TestTemplates(t *testing.T) {
+func TestRenameLabel(t *testing.T) {
 	ctx := context.Background()
 
-	// Create template
-	description := "Test template description"
-	template, err := testDB.CreateTemplate(ctx, models.TemplateInput{
-		Name:        "Test Template",
-		Description: &description,
-		Content:     "# {{name}}\n\n{{content}}",
-	})
-	if err != nil {
-		t.Fatalf("CreateTemplate failed: %v", err)
+	create := func(name string, labels []string) string {
+		t.Helper()
+		entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+			Type:      "note",
+			Name:      name,
+			Labels:    labels,
+			Embedding: dummyEmbedding(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		return models.MustRecordIDString(entity.ID)
 	}
+	oldID := create("Rename Old", []string{"rename-old", "other"})
+	bothID := create("Rename Both", []string{"rename-new", "rename-old"})
+	untouchedID := create("Rename Untouched", []string{"other"})
 	defer func() {
-		_, _ = testDB.DeleteTemplate(ctx, "Test Template")
+		for _, id := range []string{oldID, bothID, untouchedID} {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
 	}()
 
-	if template.Name != "Test Template" {
-		t.Errorf("Expected name 'Test Template', got %q", template.Name)
+	if err := testDB.CreateChunks(ctx, oldID, []models.ChunkInput{
+		{EntityID: oldID, Content: "chunk", Position: 0, Labels: []string{"rename-old", "other"}, Embedding: dummyEmbedding()},
+	}); err != nil {
+		t.Fatalf("CreateChunks failed: %v", err)
 	}
 
-	// Get template
-	retrieved, err := testDB.GetTemplate(ctx, "Test Template")
+	renamed, err := testDB.RenameLabel(ctx, "rename-old", "rename-new")
 	if err != nil {
-		t.Fatalf("GetTemplate failed: %v", err)
+		t.Fatalf("RenameLabel failed: %v", err)
 	}
-	if retrieved == nil {
-		t.Fatal("GetTemplate returned nil")
+	if renamed != 2 {
+		t.Errorf("RenameLabel() = %d, want 2", renamed)
 	}
 
-	// List templates
-	templates, err := testDB.ListTemplates(ctx)
+	for id, want := range map[string][]string{
+		oldID:       {"other", "rename-new"},
+		bothID:      {"rename-new"},
+		untouchedID: {"other"},
+	} {
+		entity, err := testDB.GetEntity(ctx, id)
+		if err != nil {
+			t.Fatalf("GetEntity failed: %v", err)
+		}
+		got := slices.Sorted(slices.Values(entity.Labels))
+		if !slices.Equal(got, want) {
+			t.Errorf("labels of %s = %v, want %v", id, got, want)
+		}
+	}
+
+	chunks, err := testDB.GetChunks(ctx, oldID)
 	if err != nil {
-		t.Fatalf("ListTemplates failed: %v", err)
+		t.Fatalf("GetChunks failed: %v", err)
 	}
-	found := false
-	for _, tmpl := range templates {
-		if tmpl.Name == "Test Template" {
-			found = true
+	for _, c := range chunks {
+		if got := slices.Sorted(slices.Values(c.Labels)); !slices.Equal(got, []string{"other", "rename-new"}) {
+			t.Errorf("chunk labels = %v, want [other rename-new]", got)
 		}
 	}
-	if !found {
-		t.Error("ListTemplates should include created template")
+
+	for _, tt := range [][2]string{{"", "x"}, {"x", ""}, {"same", "same"}} {
+		if _, err := testDB.RenameLabel(ctx, tt[0], tt[1]); err == nil {
+			t.Errorf("RenameLabel(%q, %q) succeeded, want error", tt[0], tt[1])
+		}
 	}
+}
 
-	// Delete template
-	deleted, err := testDB.DeleteTemplate(ctx, "Test Template")
+func TestSearchMessages(t *testing.T) {
+	ctx := context.Background()
+
+	conv, err := testDB.CreateConversation(ctx, "Message Search Test", nil)
 	if err != nil {
-		t.Fatalf("DeleteTemplate failed: %v", err)
+		t.Fatalf("CreateConversation failed: %v", err)
 	}
-	if !deleted {
-		t.Error("DeleteTemplate should return true")
+	convID := models.MustRecordIDString(conv.ID)
+	defer func() { _, _ = testDB.DeleteConversation(ctx, convID) }()
+
+	if _, err := testDB.CreateMessage(ctx, convID, "user", "How do we rotate the signing keys?", dummyEmbedding()); err != nil {
+		t.Fatalf("CreateMessage failed: %v", err)
+	}
+	if _, err := testDB.CreateMessage(ctx, convID, "assistant", "", nil); err != nil {
+		t.Fatalf("CreateMessage failed: %v", err)
 	}
-}
 
-// =============================================================================
-// TOKEN USAGE TESTS
-// =============================================================================
+	messages, err := testDB.GetMessages(ctx, convID)
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(messages))
+	}
 
-func TestTokenUsage(t *testing.T) {
-	ctx := context.Background()
+	results, err := testDB.SearchMessages(ctx, "signing keys", dummyEmbedding(), 10)
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected only the embedded message, got %d results", len(results))
+	}
+	if results[0].ConversationTitle != "Message Search Test" {
+		t.Errorf("Expected conversation title, got %q", results[0].ConversationTitle)
+	}
 
-	// Record usage
-	err := testDB.RecordTokenUsage(ctx, models.TokenUsageInput{
-		Operation:    "test_embed",
-		Model:        "test-model",
-		InputTokens:  100,
-		OutputTokens: 50,
-	})
+	// Without an embedding, messages are matched by text
+	results, err = testDB.SearchMessages(ctx, "SIGNING", nil, 10)
 	if err != nil {
-		t.Fatalf("RecordTokenUsage failed: %v", err)
+		t.Fatalf("SearchMessages by text failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Role != "user" {
+		t.Errorf("Expected the user message by text, got %+v", results)
 	}
 
-	// Get summary (from start of time to capture our record)
-	summary, err := testDB.GetTokenUsageSummary(ctx, "2020-01-01T00:00:00Z")
+	// Messages stored without an embedder are found by text too
+	if _, err := testDB.CreateMessage(ctx, convID, "user", "Where is the staging kubeconfig?", nil); err != nil {
+		t.Fatalf("CreateMessage failed: %v", err)
+	}
+	results, err = testDB.SearchMessages(ctx, "kubeconfig", nil, 10)
 	if err != nil {
-		t.Fatalf("GetTokenUsageSummary failed: %v", err)
+		t.Fatalf("SearchMessages by text failed: %v", err)
 	}
-	if summary.TotalTokens < 150 {
-		t.Errorf("Expected at least 150 total tokens, got %d", summary.TotalTokens)
+	if len(results) != 1 || results[0].Content != "Where is the staging kubeconfig?" {
+		t.Errorf("Expected the unembedded message by text, got %+v", results)
 	}
 }
 
-func TestGetExistingHashes(t *testing.T) {
+func TestExportAll(t *testing.T) {
 	ctx := context.Background()
 
-	// Create entities with content hashes
-	hash1 := "abc123def456"
-	hash2 := "xyz789ghi012"
-	content := "test content"
-
-	entity1, err := testDB.CreateEntity(ctx, models.EntityInput{
-		Type:        "document",
-		Name:        "Hash Test 1",
-		Content:     &content,
-		ContentHash: &hash1,
-		Embedding:   dummyEmbedding(),
+	entity, err := testDB.CreateEntity(ctx, models.EntityInput{
+		Type:      "note",
+		Name:      "Archived Note",
+		Embedding: dummyEmbedding(),
 	})
 	if err != nil {
-		t.Fatalf("CreateEntity 1 failed: %v", err)
+		t.Fatalf("CreateEntity failed: %v", err)
 	}
-	defer func() {
-		_, _ = testDB.DeleteEntity(ctx, models.MustRecordIDString(entity1.ID))
-	}()
+	entityID := models.MustRecordIDString(entity.ID)
+	defer func() { _, _ = testDB.DeleteEntity(ctx, entityID) }()
 
-	entity2, err := testDB.CreateEntity(ctx, models.EntityInput{
-		Type:        "document",
-		Name:        "Hash Test 2",
-		Content:     &content,
-		ContentHash: &hash2,
-		Embedding:   dummyEmbedding(),
+	err = testDB.CreateChunks(ctx, entityID, []models.ChunkInput{
+		{EntityID: entityID, Content: "Archived chunk", Position: 0, Embedding: dummyEmbedding()},
 	})
 	if err != nil {
-		t.Fatalf("CreateEntity 2 failed: %v", err)
+		t.Fatalf("CreateChunks failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := testDB.ExportAll(ctx, &buf); err != nil {
+		t.Fatalf("ExportAll failed: %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	var header ArchiveHeader
+	if err := json.Unmarshal(lines[0], &header); err != nil {
+		t.Fatalf("Failed to parse header: %v", err)
+	}
+	if header.Kind != ArchiveKindHeader || header.Version != ArchiveVersion || header.EmbedDimension != 384 {
+		t.Errorf("Unexpected header: %+v", header)
+	}
+
+	var foundEntity, foundChunk bool
+	for _, line := range lines[1:] {
+		var kind struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.Unmarshal(line, &kind); err != nil {
+			t.Fatalf("Failed to parse record %s: %v", line, err)
+		}
+		switch kind.Kind {
+		case ArchiveKindEntity:
+			var record ArchiveEntity
+			if err := json.Unmarshal(line, &record); err != nil {
+				t.Fatalf("Failed to parse entity: %v", err)
+			}
+			if record.ID == entityID {
+				foundEntity = true
+				if record.Name != "Archived Note" || len(record.Embedding) != 384 {
+					t.Errorf("Entity not exported losslessly: name %q, %d dimensions", record.Name, len(record.Embedding))
+				}
+			}
+		case ArchiveKindChunk:
+			var record ArchiveChunk
+			if err := json.Unmarshal(line, &record); err != nil {
+				t.Fatalf("Failed to parse chunk: %v", err)
+			}
+			if record.Entity == entityID {
+				foundChunk = true
+				if record.Content != "Archived chunk" || len(record.Embedding) != 384 {
+					t.Errorf("Chunk not exported losslessly: content %q, %d dimensions", record.Content, len(record.Embedding))
+				}
+			}
+		}
+	}
+	if !foundEntity || !foundChunk {
+		t.Errorf("Expected entity and chunk in archive, found entity %v, chunk %v", foundEntity, foundChunk)
+	}
+}
+
+func TestImportAll(t *testing.T) {
+	ctx := context.Background()
+
+	archive := func(dimension int, records ...string) io.Reader {
+		header := fmt.Sprintf(`{"kind":"header","version":1,"embed_dimension":%d}`, dimension)
+		return strings.NewReader(header + "\n" + strings.Join(records, "\n"))
+	}
+	embedding, err := json.Marshal(dummyEmbedding())
+	if err != nil {
+		t.Fatalf("Failed to marshal embedding: %v", err)
 	}
+	entity := func(id, name string) string {
+		return fmt.Sprintf(`{"kind":"entity","id":%q,"type":"note","name":%q,"labels":["import-test"],"source":"manual","confidence":0.5,"embedding":%s,"created_at":"2024-01-02T03:04:05Z"}`, id, name, embedding)
+	}
+	relation := `{"kind":"relation","id":"import_rel","in":"import_a","out":"import_b","rel_type":"references","strength":1,"source":"manual"}`
 	defer func() {
-		_, _ = testDB.DeleteEntity(ctx, models.MustRecordIDString(entity2.ID))
+		for _, id := range []string{"import_a", "import_b"} {
+			_, _ = testDB.DeleteEntity(ctx, id)
+		}
 	}()
 
-	// Query with mix of existing and non-existing hashes
-	hashes := []string{hash1, "nonexistent", hash2, "alsonotexist"}
-	existing, err := testDB.GetExistingHashes(ctx, hashes)
+	// Relation first: it's deferred until its endpoints exist
+	entityB := strings.Replace(entity("import_b", "Import B"), `"labels"`, `"context":"import-project","pinned":true,"labels"`, 1)
+	stats, err := testDB.ImportAll(ctx, archive(384, relation, entity("import_a", "Import A"), entityB), ConflictSkip)
 	if err != nil {
-		t.Fatalf("GetExistingHashes failed: %v", err)
+		t.Fatalf("ImportAll failed: %v", err)
 	}
-
-	if len(existing) != 2 {
-		t.Errorf("Expected 2 existing hashes, got %d: %v", len(existing), existing)
+	if stats.Imported != 3 || stats.Skipped != 0 {
+		t.Errorf("Expected 3 imported, 0 skipped, got %+v", stats)
 	}
-
-	// Verify the correct hashes were returned
-	found := make(map[string]bool)
-	for _, h := range existing {
-		found[h] = true
+	relations, err := testDB.GetRelations(ctx, "import_a")
+	if err != nil {
+		t.Fatalf("GetRelations failed: %v", err)
 	}
-	if !found[hash1] || !found[hash2] {
-		t.Errorf("Expected hashes %s and %s, got %v", hash1, hash2, existing)
+	if len(relations) != 1 {
+		t.Errorf("Expected 1 imported relation, got %d", len(relations))
+	}
+	imported, err := testDB.GetEntity(ctx, "import_b")
+	if err != nil || imported == nil {
+		t.Fatalf("GetEntity failed: %v", err)
+	}
+	if imported.Context == nil || *imported.Context != "import-project" {
+		t.Errorf("Expected imported context import-project, got %v", imported.Context)
+	}
+	if !imported.Pinned {
+		t.Error("Expected imported entity to stay pinned")
 	}
 
-	// Empty input should return empty result
-	empty, err := testDB.GetExistingHashes(ctx, []string{})
+	// Existing records are skipped, or overwritten
+	stats, err = testDB.ImportAll(ctx, archive(384, entity("import_a", "Renamed A")), ConflictSkip)
 	if err != nil {
-		t.Fatalf("GetExistingHashes with empty input failed: %v", err)
+		t.Fatalf("ImportAll (skip) failed: %v", err)
 	}
-	if len(empty) != 0 {
-		t.Errorf("Expected empty result, got %v", empty)
+	if stats.Imported != 0 || stats.Skipped != 1 {
+		t.Errorf("Expected 1 skipped, got %+v", stats)
+	}
+	stats, err = testDB.ImportAll(ctx, archive(384, entity("import_a", "Renamed A")), ConflictOverwrite)
+	if err != nil {
+		t.Fatalf("ImportAll (overwrite) failed: %v", err)
+	}
+	if stats.Imported != 1 {
+		t.Errorf("Expected 1 imported, got %+v", stats)
+	}
+	got, err := testDB.GetEntity(ctx, "import_a")
+	if err != nil || got == nil || got.Name != "Renamed A" {
+		t.Errorf("Expected overwritten entity, got %+v (err %v)", got, err)
+	}
+
+	// Mismatched dimensions are refused
+	if _, err := testDB.ImportAll(ctx, archive(1024, entity("import_c", "Import C")), ConflictSkip); err == nil {
+		t.Error("Expected error for mismatched embedding dimension")
+	}
+	if entity, err := testDB.GetEntity(ctx, "import_c"); err != nil || entity != nil {
+		t.Errorf("Expected nothing imported from mismatched archive, got %v (err %v)", entity, err)
 	}
 }
@@ -24,6 +24,10 @@ var (
 
 	// ErrNotFound indicates the requested entity does not exist.
 	ErrNotFound = errors.New("entity not found")
+
+	// ErrAliasTaken indicates an alias is already the name or an alias of
+	// another entity.
+	ErrAliasTaken = errors.New("alias already taken")
 )
 
 // wrapQueryError inspects a SurrealDB error and wraps it with the appropriate
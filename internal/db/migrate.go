@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Migration is a numbered schema change that Migrate applies once per
+// database.
+type Migration struct {
+	Version int    // Order of application; unique and above schemaVersion
+	SQL     string // SurrealQL, run in one transaction with the version record
+}
+
+// Migrations lists the schema changes applied after the initial schema, in
+// version order. Add changes InitSchema can't make to existing databases
+// (redefining an index, changing analyzer filters, backfilling fields) here
+// with the next version. Never edit a migration that may have been applied.
+var Migrations = []Migration{
+	// Entities from before modified_at count as modified at their last update
+	{Version: 2, SQL: "UPDATE entity SET modified_at = updated_at WHERE modified_at IS NONE RETURN NONE"},
+}
+
+// schemaVersion is the migration version of the schema InitSchema creates.
+const schemaVersion = 1
+
+// migrationTableSQL defines the table recording applied migrations.
+const migrationTableSQL = `
+	DEFINE TABLE IF NOT EXISTS _migration SCHEMAFULL;
+	DEFINE FIELD IF NOT EXISTS version ON _migration TYPE int;
+	DEFINE FIELD IF NOT EXISTS applied_at ON _migration TYPE datetime DEFAULT time::now();
+	DEFINE INDEX IF NOT EXISTS idx_migration_version ON _migration FIELDS version UNIQUE;
+`
+
+// Migrate brings the database schema up to date. It runs InitSchema, which
+// creates whatever a fresh database lacks, and records it as migration 1,
+// then applies the registered Migrations not applied yet, in version order.
+// A failing migration stops Migrate with an error naming its version;
+// the migrations before it stay applied.
+func (c *Client) Migrate(ctx context.Context, embedDimension int, distance string) error {
+	if err := c.InitSchema(ctx, embedDimension, distance); err != nil {
+		return err
+	}
+	return c.migrate(ctx, Migrations)
+}
+
+// migrate applies the schema version and migrations not yet recorded in the
+// _migration table.
+func (c *Client) migrate(ctx context.Context, migrations []Migration) error {
+	if err := validateMigrations(migrations); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	if _, err := runQuery[any](ctx, c, migrationTableSQL, nil); err != nil {
+		return fmt.Errorf("migrate: define migration table: %w", err)
+	}
+
+	applied, err := c.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	all := append([]Migration{{Version: schemaVersion}}, migrations...)
+	for _, m := range all {
+		if applied[m.Version] {
+			continue
+		}
+		c.logger.Info("applying schema migration", "version", m.Version)
+		if err := c.applyMigration(ctx, m); err != nil {
+			return fmt.Errorf("migration %03d failed: %w", m.Version, err)
+		}
+	}
+	return nil
+}
+
+// appliedMigrations returns the versions recorded in the _migration table.
+func (c *Client) appliedMigrations(ctx context.Context) (map[int]bool, error) {
+	results, err := runQuery[[]struct {
+		Version int `json:"version"`
+	}](ctx, c, "SELECT version FROM _migration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: list applied migrations: %w", err)
+	}
+
+	applied := map[int]bool{}
+	if results == nil || len(*results) == 0 {
+		return applied, nil
+	}
+	for _, row := range (*results)[0].Result {
+		applied[row.Version] = true
+	}
+	return applied, nil
+}
+
+// applyMigration runs a migration and records its version in one
+// transaction, so a failing migration leaves no trace.
+func (c *Client) applyMigration(ctx context.Context, m Migration) error {
+	sql := strings.TrimSpace(m.SQL)
+	if sql != "" && !strings.HasSuffix(sql, ";") {
+		sql += ";"
+	}
+	_, err := runQuery[any](ctx, c, `
+		BEGIN TRANSACTION;
+		`+sql+`
+		CREATE _migration SET version = $version;
+		COMMIT TRANSACTION;
+	`, map[string]any{"version": m.Version})
+	return wrapQueryError(err)
+}
+
+// validateMigrations checks that migrations follow the initial schema in
+// strictly ascending version order.
+func validateMigrations(migrations []Migration) error {
+	prev := schemaVersion
+	for _, m := range migrations {
+		if m.Version <= prev {
+			return fmt.Errorf("migration %03d: versions must be unique, ascending and above %d", m.Version, schemaVersion)
+		}
+		prev = m.Version
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/surrealdb/surrealdb.go"
+	"github.com/surrealdb/surrealdb.go/contrib/rews"
+	"github.com/surrealdb/surrealdb.go/pkg/connection/gorillaws"
+)
+
+// poolConn is one auto-reconnecting WebSocket of a Client's pool.
+type poolConn struct {
+	conn *rews.Connection[*gorillaws.Connection]
+	db   *surrealdb.DB
+
+	lastActive atomic.Int64 // Unix timestamp of last query (for idle detection)
+	active     atomic.Int64 // Queries in flight
+	maxActive  atomic.Int64 // Highest number of queries in flight at once
+	queries    atomic.Int64 // Queries run since the pool was created
+}
+
+// ping runs a trivial query on the connection, bypassing the active counts.
+func (pc *poolConn) ping(ctx context.Context) error {
+	if pc.conn.IsClosed() {
+		return fmt.Errorf("ping: connection closed")
+	}
+	if _, err := surrealdb.Query[any](ctx, pc.db, "RETURN 1", nil); err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	return nil
+}
+
+// acquire picks the connection with the fewest queries in flight and counts
+// a query on it. Ties go to the next connection in round-robin order, so idle
+// pools still spread queries. Call release when the query is done.
+func (c *Client) acquire() *poolConn {
+	n := uint64(len(c.conns))
+	start := c.next.Add(1) % n
+	best := c.conns[start]
+	for i := uint64(1); i < n; i++ {
+		pc := c.conns[(start+i)%n]
+		if pc.active.Load() < best.active.Load() {
+			best = pc
+		}
+	}
+
+	best.lastActive.Store(time.Now().Unix())
+	best.queries.Add(1)
+	active := best.active.Add(1)
+	for {
+		peak := best.maxActive.Load()
+		if active <= peak || best.maxActive.CompareAndSwap(peak, active) {
+			break
+		}
+	}
+	return best
+}
+
+// release ends a query counted by acquire.
+func (pc *poolConn) release() {
+	pc.active.Add(-1)
+}
+
+// runQuery runs a SurrealQL query on the least busy pooled connection.
+func runQuery[T any](ctx context.Context, c *Client, sql string, vars map[string]any) (*[]surrealdb.QueryResult[T], error) {
+	pc := c.acquire()
+	defer pc.release()
+	return surrealdb.Query[T](ctx, pc.db, sql, vars)
+}
+
+// ConnectionStats describes the load of one pooled connection.
+type ConnectionStats struct {
+	Active    int64 // Queries in flight
+	MaxActive int64 // Highest number of queries in flight at once
+	Queries   int64 // Queries run since the pool was created
+	Connected bool
+}
+
+// ConnectionStats returns the load of each pooled connection, in pool order.
+// Connections that are often busy at once suggest a larger pool.
+func (c *Client) ConnectionStats() []ConnectionStats {
+	stats := make([]ConnectionStats, len(c.conns))
+	for i, pc := range c.conns {
+		stats[i] = ConnectionStats{
+			Active:    pc.active.Load(),
+			MaxActive: pc.maxActive.Load(),
+			Queries:   pc.queries.Load(),
+			Connected: !pc.conn.IsClosed(),
+		}
+	}
+	return stats
+}
@@ -2,12 +2,21 @@
 package db
 
 import (
+	"cmp"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/raphaelgruber/memcp-go/internal/metrics"
 	"github.com/raphaelgruber/memcp-go/internal/models"
+	"github.com/raphaelgruber/memcp-go/internal/tracing"
 	"github.com/surrealdb/surrealdb.go"
 	surrealmodels "github.com/surrealdb/surrealdb.go/pkg/models"
 )
@@ -37,6 +46,14 @@ func optionalObject(m map[string]any) any {
 	return m
 }
 
+// optionalStrings returns models.None for nil/empty slices, otherwise returns the slice.
+func optionalStrings(s []string) any {
+	if len(s) == 0 {
+		return surrealmodels.None
+	}
+	return s
+}
+
 // optionalEmbedding returns models.None for nil/empty slices, otherwise returns the slice.
 func optionalEmbedding(e []float32) any {
 	if len(e) == 0 {
@@ -55,7 +72,42 @@ func (c *Client) CreateEntity(ctx context.Context, input models.EntityInput) (*m
 	start := c.startOp()
 	defer c.recordTiming(metrics.OpDBQuery, start)
 
-	// Use explicit ID if provided, otherwise generate from name
+	sql := `
+		CREATE type::record("entity", $id) SET
+			type = $type,
+			name = $name,
+			content = $content,
+			summary = $summary,
+			labels = $labels,
+			content_hash = $content_hash,
+			verified = $verified,
+			confidence = $confidence,
+			source = $source,
+			source_path = $source_path,
+			metadata = $metadata,
+			language = $language,
+			context = $context,
+			embedding = $embedding,
+			access_count = 0
+		RETURN AFTER
+	`
+
+	results, err := runQuery[[]models.Entity](ctx, c, sql, entityParams(input))
+	if err != nil {
+		return nil, fmt.Errorf("create entity: %w", wrapQueryError(err))
+	}
+
+	if results == nil || len(*results) == 0 || len((*results)[0].Result) == 0 {
+		return nil, fmt.Errorf("create entity: no result returned")
+	}
+
+	return &(*results)[0].Result[0], nil
+}
+
+// entityParams returns the query parameters of an entity to create, with
+// defaults for the unset fields. Without an explicit ID, the ID is the
+// slugified name.
+func entityParams(input models.EntityInput) map[string]any {
 	id := slugify(input.Name)
 	if input.ID != nil && *input.ID != "" {
 		id = *input.ID
@@ -81,25 +133,7 @@ func (c *Client) CreateEntity(ctx context.Context, input models.EntityInput) (*m
 		verified = *input.Verified
 	}
 
-	sql := `
-		CREATE type::record("entity", $id) SET
-			type = $type,
-			name = $name,
-			content = $content,
-			summary = $summary,
-			labels = $labels,
-			content_hash = $content_hash,
-			verified = $verified,
-			confidence = $confidence,
-			source = $source,
-			source_path = $source_path,
-			metadata = $metadata,
-			embedding = $embedding,
-			access_count = 0
-		RETURN AFTER
-	`
-
-	results, err := surrealdb.Query[[]models.Entity](ctx, c.db, sql, map[string]any{
+	return map[string]any{
 		"id":           id,
 		"type":         input.Type,
 		"name":         input.Name,
@@ -112,17 +146,90 @@ func (c *Client) CreateEntity(ctx context.Context, input models.EntityInput) (*m
 		"source":       source,
 		"source_path":  optionalString(input.SourcePath),
 		"metadata":     optionalObject(input.Metadata),
+		"language":     optionalString(input.Language),
+		"context":      optionalString(input.Context),
 		"embedding":    optionalEmbedding(input.Embedding),
-	})
-	if err != nil {
-		return nil, fmt.Errorf("create entity: %w", wrapQueryError(err))
 	}
+}
 
-	if results == nil || len(*results) == 0 || len((*results)[0].Result) == 0 {
-		return nil, fmt.Errorf("create entity: no result returned")
+// entityBatchSize bounds the number of entities created per query.
+const entityBatchSize = 100
+
+// EntityBatchResult is the outcome of one input of CreateEntitiesBatch: the
+// created entity, or the error that kept it from being created.
+type EntityBatchResult struct {
+	Entity *models.Entity
+	Err    error
+}
+
+// CreateEntitiesBatch creates many entities like CreateEntity, batching
+// several CREATE statements into each query. Statements succeed or fail on
+// their own, so an input that can't be created (e.g. an ID that already
+// exists) only fails its own result. Results are in input order. The
+// returned error means a query failed as a whole; results of earlier
+// batches stay created.
+func (c *Client) CreateEntitiesBatch(ctx context.Context, inputs []models.EntityInput) ([]EntityBatchResult, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	out := make([]EntityBatchResult, 0, len(inputs))
+	for batchStart := 0; batchStart < len(inputs); batchStart += entityBatchSize {
+		batch := inputs[batchStart:min(batchStart+entityBatchSize, len(inputs))]
+
+		var sql strings.Builder
+		vars := make(map[string]any, len(batch))
+		for i, input := range batch {
+			vars[fmt.Sprintf("entity_%d", i)] = entityParams(input)
+			fmt.Fprintf(&sql, `
+				CREATE type::record("entity", $entity_%[1]d.id) SET
+					type = $entity_%[1]d.type,
+					name = $entity_%[1]d.name,
+					content = $entity_%[1]d.content,
+					summary = $entity_%[1]d.summary,
+					labels = $entity_%[1]d.labels,
+					content_hash = $entity_%[1]d.content_hash,
+					verified = $entity_%[1]d.verified,
+					confidence = $entity_%[1]d.confidence,
+					source = $entity_%[1]d.source,
+					source_path = $entity_%[1]d.source_path,
+					metadata = $entity_%[1]d.metadata,
+					language = $entity_%[1]d.language,
+					context = $entity_%[1]d.context,
+					embedding = $entity_%[1]d.embedding,
+					access_count = 0
+				RETURN AFTER;
+			`, i)
+		}
+
+		// Failed statements come back as results with an error, alongside
+		// the joined error of all of them
+		results, err := runQuery[[]models.Entity](ctx, c, sql.String(), vars)
+		if results == nil || len(*results) != len(batch) {
+			if err == nil {
+				err = fmt.Errorf("got results for %d of %d statements", resultCount(results), len(batch))
+			}
+			return out, fmt.Errorf("create entities: %w", err)
+		}
+		for _, r := range *results {
+			switch {
+			case r.Error != nil:
+				out = append(out, EntityBatchResult{Err: wrapQueryError(r.Error)})
+			case len(r.Result) == 0:
+				out = append(out, EntityBatchResult{Err: fmt.Errorf("no result returned")})
+			default:
+				out = append(out, EntityBatchResult{Entity: &r.Result[0]})
+			}
+		}
 	}
+	return out, nil
+}
 
-	return &(*results)[0].Result[0], nil
+// resultCount returns the number of statement results of a query.
+func resultCount[T any](results *[]surrealdb.QueryResult[T]) int {
+	if results == nil {
+		return 0
+	}
+	return len(*results)
 }
 
 // UpsertEntity creates a new entity or updates an existing one by ID.
@@ -145,26 +252,6 @@ func (c *Client) UpsertEntity(ctx context.Context, input models.EntityInput) (*m
 	}
 	wasCreated := existing == nil
 
-	// Ensure labels is not nil
-	labels := input.Labels
-	if labels == nil {
-		labels = []string{}
-	}
-
-	// Set defaults
-	source := models.SourceManual
-	if input.Source != nil {
-		source = *input.Source
-	}
-	confidence := 0.5
-	if input.Confidence != nil {
-		confidence = *input.Confidence
-	}
-	verified := false
-	if input.Verified != nil {
-		verified = *input.Verified
-	}
-
 	// Use SurrealDB UPSERT - creates if not exists, updates if exists
 	sql := `
 		UPSERT type::record("entity", $id) SET
@@ -179,26 +266,14 @@ func (c *Client) UpsertEntity(ctx context.Context, input models.EntityInput) (*m
 			source = $source,
 			source_path = $source_path,
 			metadata = $metadata,
+			language = $language,
+			context = $context,
 			embedding = $embedding,
 			access_count = IF access_count THEN access_count ELSE 0 END
 		RETURN AFTER
 	`
 
-	results, err := surrealdb.Query[[]models.Entity](ctx, c.db, sql, map[string]any{
-		"id":           id,
-		"type":         input.Type,
-		"name":         input.Name,
-		"content":      optionalString(input.Content),
-		"summary":      optionalString(input.Summary),
-		"labels":       labels,
-		"content_hash": optionalString(input.ContentHash),
-		"verified":     verified,
-		"confidence":   confidence,
-		"source":       source,
-		"source_path":  optionalString(input.SourcePath),
-		"metadata":     optionalObject(input.Metadata),
-		"embedding":    optionalEmbedding(input.Embedding),
-	})
+	results, err := runQuery[[]models.Entity](ctx, c, sql, entityParams(input))
 	if err != nil {
 		return nil, false, fmt.Errorf("upsert entity: %w", wrapQueryError(err))
 	}
@@ -218,7 +293,7 @@ func (c *Client) GetEntity(ctx context.Context, id string) (*models.Entity, erro
 	start := c.startOp()
 	defer c.recordTiming(metrics.OpDBQuery, start)
 
-	results, err := surrealdb.Query[[]models.Entity](ctx, c.db, `
+	results, err := runQuery[[]models.Entity](ctx, c, `
 		SELECT * FROM type::record("entity", $id)
 	`, map[string]any{"id": id})
 
@@ -232,12 +307,92 @@ func (c *Client) GetEntity(ctx context.Context, id string) (*models.Entity, erro
 	return &(*results)[0].Result[0], nil
 }
 
-// GetEntityByName retrieves an entity by name (case-insensitive).
-// Returns nil if not found.
+// SetAlwaysInContext sets whether an entity is included in every ask context.
+// Returns ErrNotFound if the entity doesn't exist.
+func (c *Client) SetAlwaysInContext(ctx context.Context, id string, enabled bool) (*models.Entity, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[[]models.Entity](ctx, c, `
+		UPDATE type::record("entity", $id) SET always_in_context = $enabled RETURN AFTER
+	`, map[string]any{"id": id, "enabled": enabled})
+	if err != nil {
+		return nil, fmt.Errorf("set always in context: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 || len((*results)[0].Result) == 0 {
+		return nil, ErrNotFound
+	}
+	return &(*results)[0].Result[0], nil
+}
+
+// SetPinned sets whether an entity is exempt from decay: pinned entities
+// keep a decay weight of 1.0. Returns ErrNotFound if the entity doesn't exist.
+func (c *Client) SetPinned(ctx context.Context, id string, pinned bool) (*models.Entity, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[[]models.Entity](ctx, c, `
+		UPDATE type::record("entity", $id) SET
+			pinned = $pinned
+		RETURN AFTER
+	`, map[string]any{"id": id, "pinned": pinned})
+	if err != nil {
+		return nil, fmt.Errorf("set pinned: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 || len((*results)[0].Result) == 0 {
+		return nil, ErrNotFound
+	}
+	return &(*results)[0].Result[0], nil
+}
+
+// ListAlwaysInContext returns up to limit entities flagged to be included in
+// every ask context, ordered by name. Embeddings are omitted.
+func (c *Client) ListAlwaysInContext(ctx context.Context, limit int, entityContext *string) ([]models.Entity, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	vars := map[string]any{"limit": limit}
+	where := "always_in_context = true"
+	if entityContext != nil {
+		where += " AND " + contextClause("context", *entityContext, vars)
+	}
+	results, err := runQuery[[]models.Entity](ctx, c, `
+		SELECT * OMIT embedding FROM entity
+		WHERE `+where+`
+		ORDER BY name
+		LIMIT $limit
+	`, vars)
+	if err != nil {
+		return nil, fmt.Errorf("list always in context: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return []models.Entity{}, nil
+	}
+	return (*results)[0].Result, nil
+}
+
+// GetEntityByName retrieves an entity by name or alias (case-insensitive),
+// preferring a name match. Returns nil if not found.
 func (c *Client) GetEntityByName(ctx context.Context, name string) (*models.Entity, error) {
-	results, err := surrealdb.Query[[]models.Entity](ctx, c.db, `
-		SELECT * FROM entity WHERE string::lowercase(name) = string::lowercase($name) LIMIT 1
-	`, map[string]any{"name": name})
+	return c.GetEntityByNameIn(ctx, name, nil)
+}
+
+// GetEntityByNameIn is GetEntityByName restricted to the entities of a
+// project context. A nil context searches all entities.
+func (c *Client) GetEntityByNameIn(ctx context.Context, name string, entityContext *string) (*models.Entity, error) {
+	vars := map[string]any{"name": strings.ToLower(name)}
+	where := "(string::lowercase(name) = $name OR aliases CONTAINS $name)"
+	if entityContext != nil {
+		where += " AND " + contextClause("context", *entityContext, vars)
+	}
+	results, err := runQuery[[]models.Entity](ctx, c, `
+		SELECT *, string::lowercase(name) = $name AS name_match FROM entity
+		WHERE `+where+`
+		ORDER BY name_match DESC LIMIT 1
+	`, vars)
 
 	if err != nil {
 		return nil, fmt.Errorf("get entity by name: %w", err)
@@ -249,6 +404,53 @@ func (c *Client) GetEntityByName(ctx context.Context, name string) (*models.Enti
 	return &(*results)[0].Result[0], nil
 }
 
+// fuzzyNameCandidates caps the matches FindEntitiesByNameFuzzy returns.
+const fuzzyNameCandidates = 5
+
+// NameMatch is an entity whose name is close to a looked-up name.
+type NameMatch struct {
+	models.Entity
+	Distance int     `json:"name_distance"` // Levenshtein distance of the lowercased names
+	Score    float64 `json:"-"`             // 1 - Distance / length of the longer name
+}
+
+// FindEntitiesByNameFuzzy returns entities whose name is within maxDistance
+// edits (Levenshtein, case-insensitive) of name, closest first. Use it when
+// GetEntityByName misses on typos or plural forms. No index serves the
+// distance, so every entity name is compared: a scan of the whole table.
+func (c *Client) FindEntitiesByNameFuzzy(ctx context.Context, name string, maxDistance int) ([]NameMatch, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || maxDistance < 0 {
+		return []NameMatch{}, nil
+	}
+
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[[]NameMatch](ctx, c, `
+		SELECT *, string::distance::levenshtein(string::lowercase(name), $name) AS name_distance
+		FROM entity
+		WHERE string::distance::levenshtein(string::lowercase(name), $name) <= $max
+		ORDER BY name_distance
+		LIMIT $limit
+	`, map[string]any{"name": name, "max": maxDistance, "limit": fuzzyNameCandidates})
+	if err != nil {
+		return nil, fmt.Errorf("find entities by fuzzy name: %w", err)
+	}
+	if results == nil || len(*results) == 0 {
+		return []NameMatch{}, nil
+	}
+
+	matches := (*results)[0].Result
+	for i := range matches {
+		longest := max(len([]rune(name)), len([]rune(matches[i].Name)))
+		if longest > 0 {
+			matches[i].Score = 1 - float64(matches[i].Distance)/float64(longest)
+		}
+	}
+	return matches, nil
+}
+
 // GetEntitiesByNames retrieves multiple entities by name (case-insensitive).
 // Returns a map of lowercase(name) -> entity for efficient lookup.
 // Names not found are simply not in the returned map.
@@ -263,8 +465,8 @@ func (c *Client) GetEntitiesByNames(ctx context.Context, names []string) (map[st
 		lowerNames[i] = strings.ToLower(n)
 	}
 
-	results, err := surrealdb.Query[[]models.Entity](ctx, c.db, `
-		SELECT * FROM entity WHERE string::lowercase(name) IN $names
+	results, err := runQuery[[]models.Entity](ctx, c, `
+		SELECT * FROM entity WHERE string::lowercase(name) IN $names OR aliases CONTAINSANY $names
 	`, map[string]any{"names": lowerNames})
 
 	if err != nil {
@@ -272,17 +474,102 @@ func (c *Client) GetEntitiesByNames(ctx context.Context, names []string) (map[st
 	}
 
 	entityMap := make(map[string]*models.Entity, len(names))
+	if results == nil || len(*results) == 0 {
+		return entityMap, nil
+	}
+	found := (*results)[0].Result
+	for i := range found {
+		entityMap[strings.ToLower(found[i].Name)] = &found[i]
+	}
+	// Names win over aliases of other entities
+	for i := range found {
+		for _, alias := range found[i].Aliases {
+			if _, ok := entityMap[alias]; !ok && slices.Contains(lowerNames, alias) {
+				entityMap[alias] = &found[i]
+			}
+		}
+	}
+	return entityMap, nil
+}
+
+// AddAlias adds an alternative name to an entity, so name lookups such as
+// GetEntityByName and relation resolution find it by the alias too. Aliases
+// are stored lowercased. Returns ErrAliasTaken, naming the owner, if the
+// alias is another entity's name or alias, and ErrNotFound if the entity
+// doesn't exist.
+func (c *Client) AddAlias(ctx context.Context, entityID, alias string) (*models.Entity, error) {
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	if alias == "" {
+		return nil, fmt.Errorf("add alias: alias is empty")
+	}
+
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[struct {
+		Owner  *string        `json:"owner"`
+		Entity *models.Entity `json:"entity"`
+	}](ctx, c, `
+		BEGIN TRANSACTION;
+		LET $rec = type::record("entity", $id);
+		LET $owner = (SELECT VALUE name FROM entity
+			WHERE id != $rec AND (string::lowercase(name) = $alias OR aliases CONTAINS $alias)
+			LIMIT 1)[0];
+		IF $owner = NONE AND record::exists($rec) {
+			UPDATE $rec SET aliases = array::union(aliases ?? [], [$alias]) RETURN NONE;
+		};
+		RETURN { owner: $owner, entity: (SELECT * FROM ONLY $rec) };
+		COMMIT TRANSACTION;
+	`, map[string]any{"id": entityID, "alias": alias})
+	if err != nil {
+		return nil, fmt.Errorf("add alias: %w", wrapQueryError(err))
+	}
+
+	if results == nil || len(*results) == 0 {
+		return nil, ErrNotFound
+	}
+	result := (*results)[len(*results)-1].Result
+	if result.Owner != nil {
+		return nil, fmt.Errorf("add alias: %w: %q belongs to %q", ErrAliasTaken, alias, *result.Owner)
+	}
+	if result.Entity == nil {
+		return nil, ErrNotFound
+	}
+	return result.Entity, nil
+}
+
+// GetEntitiesByIDs retrieves multiple entities by ID.
+// Returns a map of ID -> entity; IDs not found are simply not in the map.
+func (c *Client) GetEntitiesByIDs(ctx context.Context, ids []string) (map[string]*models.Entity, error) {
+	if len(ids) == 0 {
+		return map[string]*models.Entity{}, nil
+	}
+
+	results, err := runQuery[[]models.Entity](ctx, c, `
+		SELECT * FROM $ids.map(|$id| type::record("entity", $id))
+	`, map[string]any{"ids": ids})
+
+	if err != nil {
+		return nil, fmt.Errorf("get entities by ids: %w", err)
+	}
+
+	entityMap := make(map[string]*models.Entity, len(ids))
 	if results != nil && len(*results) > 0 {
 		for i := range (*results)[0].Result {
 			entity := &(*results)[0].Result[i]
-			entityMap[strings.ToLower(entity.Name)] = entity
+			id, err := models.RecordIDString(entity.ID)
+			if err != nil {
+				return nil, fmt.Errorf("get entities by ids: %w", err)
+			}
+			entityMap[id] = entity
 		}
 	}
 	return entityMap, nil
 }
 
 // UpdateEntity updates an entity with partial data.
-// Only non-nil fields in the update are changed.
+// Only non-nil fields in the update are changed. Embeddings and chunks are not
+// regenerated; use service.EntityService.Update or ReindexEntity for that.
 func (c *Client) UpdateEntity(ctx context.Context, id string, update models.EntityUpdate) (*models.Entity, error) {
 	start := c.startOp()
 	defer c.recordTiming(metrics.OpDBQuery, start)
@@ -327,6 +614,14 @@ func (c *Client) UpdateEntity(ctx context.Context, id string, update models.Enti
 		setClauses = append(setClauses, "metadata = $metadata")
 		vars["metadata"] = update.Metadata
 	}
+	if update.Language != nil {
+		if *update.Language == "" {
+			setClauses = append(setClauses, "language = NONE")
+		} else {
+			setClauses = append(setClauses, "language = $language")
+			vars["language"] = *update.Language
+		}
+	}
 	if update.Embedding != nil {
 		setClauses = append(setClauses, "embedding = $embedding")
 		vars["embedding"] = update.Embedding
@@ -339,7 +634,7 @@ func (c *Client) UpdateEntity(ctx context.Context, id string, update models.Enti
 		UPDATE type::record("entity", $id) SET %s RETURN AFTER
 	`, strings.Join(setClauses, ", "))
 
-	results, err := surrealdb.Query[[]models.Entity](ctx, c.db, sql, vars)
+	results, err := runQuery[[]models.Entity](ctx, c, sql, vars)
 	if err != nil {
 		return nil, fmt.Errorf("update entity: %w", err)
 	}
@@ -351,6 +646,35 @@ func (c *Client) UpdateEntity(ctx context.Context, id string, update models.Enti
 	return &(*results)[0].Result[0], nil
 }
 
+// ClearEntityContent removes an entity's content, e.g. once it is stored in chunks.
+func (c *Client) ClearEntityContent(ctx context.Context, id string) error {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	_, err := runQuery[any](ctx, c, `
+		UPDATE type::record("entity", $id) SET content = NONE
+	`, map[string]any{"id": id})
+	if err != nil {
+		return fmt.Errorf("clear entity content: %w", err)
+	}
+	return nil
+}
+
+// ClearContentHash removes an entity's content hash so the next ingest of its
+// file isn't skipped as unchanged.
+func (c *Client) ClearContentHash(ctx context.Context, id string) error {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	_, err := runQuery[any](ctx, c, `
+		UPDATE type::record("entity", $id) SET content_hash = NONE
+	`, map[string]any{"id": id})
+	if err != nil {
+		return fmt.Errorf("clear content hash: %w", err)
+	}
+	return nil
+}
+
 // DeleteEntity deletes an entity by ID.
 // Cascade delete of chunks and relations is handled by SurrealDB events.
 // Returns true if entity was deleted.
@@ -360,7 +684,7 @@ func (c *Client) DeleteEntity(ctx context.Context, id string) (bool, error) {
 
 	sql := `DELETE type::record("entity", $id) RETURN BEFORE`
 
-	results, err := surrealdb.Query[[]models.Entity](ctx, c.db, sql, map[string]any{"id": id})
+	results, err := runQuery[[]models.Entity](ctx, c, sql, map[string]any{"id": id})
 	if err != nil {
 		return false, fmt.Errorf("delete entity: %w", err)
 	}
@@ -371,9 +695,127 @@ func (c *Client) DeleteEntity(ctx context.Context, id string) (bool, error) {
 	return true, nil
 }
 
+// DeleteEntitiesByLabel deletes all entities with the label in a single
+// statement and returns them as they were. Their chunks and relations are
+// removed by the cascade events.
+func (c *Client) DeleteEntitiesByLabel(ctx context.Context, label string) ([]models.Entity, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	if label == "" {
+		return nil, fmt.Errorf("delete entities by label: label required")
+	}
+
+	sql := `DELETE entity WHERE labels CONTAINS $label RETURN BEFORE`
+
+	results, err := runQuery[[]models.Entity](ctx, c, sql, map[string]any{"label": label})
+	if err != nil {
+		return nil, fmt.Errorf("delete entities by label: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return nil, nil
+	}
+	return (*results)[0].Result, nil
+}
+
+// MergeEntities merges the entity mergeID into keepID and deletes it. Its
+// relations and contradictions are repointed to keepID, dropping those that
+// would link keepID to itself; a repointed relation that duplicates an
+// existing one (same unique_key) raises that relation's strength to the max of
+// both instead. Chunks are moved after keepID's own chunks, labels are
+// unioned (on chunks too) and the higher confidence is kept. Returns the
+// merged entity.
+func (c *Client) MergeEntities(ctx context.Context, keepID, mergeID string) (*models.Entity, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	if keepID == mergeID {
+		return nil, fmt.Errorf("merge entities: cannot merge %s into itself", keepID)
+	}
+	for _, id := range []string{keepID, mergeID} {
+		entity, err := c.GetEntity(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("merge entities: %w", err)
+		}
+		if entity == nil {
+			return nil, fmt.Errorf("merge entities: entity not found: %s", id)
+		}
+	}
+
+	sql := `
+		BEGIN TRANSACTION;
+		LET $keep = type::record("entity", $keep_id);
+		LET $merge = type::record("entity", $merge_id);
+
+		FOR $rel IN (SELECT * FROM relates_to WHERE in = $merge OR out = $merge) {
+			LET $from = IF $rel.in = $merge THEN $keep ELSE $rel.in END;
+			LET $to = IF $rel.out = $merge THEN $keep ELSE $rel.out END;
+			DELETE $rel.id;
+			IF $from != $to {
+				LET $unique = string::concat(array::sort([<string>$from, <string>$to]), $rel.rel_type);
+				LET $existing = (SELECT * FROM relates_to WHERE unique_key = $unique);
+				IF array::len($existing) > 0 {
+					UPDATE $existing[0].id SET strength = math::max([strength, $rel.strength]);
+				} ELSE {
+					RELATE $from->relates_to->$to SET
+						rel_type = $rel.rel_type,
+						strength = $rel.strength,
+						source = $rel.source,
+						metadata = $rel.metadata,
+						created_at = $rel.created_at;
+				};
+			};
+		};
+
+		FOR $con IN (SELECT * FROM contradicts WHERE in = $merge OR out = $merge) {
+			LET $from = IF $con.in = $merge THEN $keep ELSE $con.in END;
+			LET $to = IF $con.out = $merge THEN $keep ELSE $con.out END;
+			DELETE $con.id;
+			IF $from != $to {
+				RELATE $from->contradicts->$to SET
+					explanation = $con.explanation,
+					confidence = $con.confidence,
+					resolved = $con.resolved,
+					detected_at = $con.detected_at;
+			};
+		};
+
+		LET $offset = ((SELECT VALUE position FROM chunk WHERE entity = $keep ORDER BY position DESC LIMIT 1)[0] ?? -1) + 1;
+		UPDATE chunk SET entity = $keep, position = position + $offset WHERE entity = $merge RETURN NONE;
+
+		LET $merged = (SELECT labels, confidence FROM ONLY $merge);
+		UPDATE $keep SET
+			labels = array::union(labels, $merged.labels),
+			confidence = math::max([confidence, $merged.confidence])
+		RETURN NONE;
+		UPDATE chunk SET labels = $keep.labels WHERE entity = $keep RETURN NONE;
+		UPDATE answer_source SET entity_id = $keep_id WHERE entity_id = $merge_id RETURN NONE;
+
+		DELETE $merge;
+		COMMIT TRANSACTION;
+	`
+
+	if _, err := runQuery[any](ctx, c, sql, map[string]any{
+		"keep_id":  keepID,
+		"merge_id": mergeID,
+	}); err != nil {
+		return nil, fmt.Errorf("merge entities: %w", wrapQueryError(err))
+	}
+
+	merged, err := c.GetEntity(ctx, keepID)
+	if err != nil {
+		return nil, fmt.Errorf("merge entities: %w", err)
+	}
+	if merged == nil {
+		return nil, fmt.Errorf("merge entities: entity not found after merge: %s", keepID)
+	}
+	return merged, nil
+}
+
 // UpdateEntityAccess updates access tracking for an entity.
 func (c *Client) UpdateEntityAccess(ctx context.Context, id string) error {
-	_, err := surrealdb.Query[any](ctx, c.db, `
+	_, err := runQuery[any](ctx, c, `
 		UPDATE type::record("entity", $id) SET
 			accessed = time::now(),
 			access_count += 1
@@ -391,9 +833,9 @@ func (c *Client) GetExistingHashes(ctx context.Context, hashes []string) ([]stri
 		return []string{}, nil
 	}
 
-	results, err := surrealdb.Query[[]struct {
+	results, err := runQuery[[]struct {
 		ContentHash *string `json:"content_hash"`
-	}](ctx, c.db, `
+	}](ctx, c, `
 		SELECT content_hash FROM entity WHERE content_hash IN $hashes
 	`, map[string]any{"hashes": hashes})
 
@@ -418,145 +860,447 @@ func (c *Client) GetExistingHashes(ctx context.Context, hashes []string) ([]stri
 // SEARCH QUERIES
 // =============================================================================
 
-// SearchOptions configures entity search behavior.
+// Search modes selecting the retrievers of a search.
+const (
+	SearchModeHybrid  = "hybrid"  // RRF fusion of BM25 and vector results
+	SearchModeKeyword = "keyword" // BM25 full-text matches only, scored by relevance
+	SearchModeVector  = "vector"  // Nearest neighbours only, scored by cosine similarity
+)
+
+// SearchOptions configures entity search behavior.
 type SearchOptions struct {
-	Query        string    // Search query text
-	Embedding    []float32 // Query embedding for vector search
-	Labels       []string  // Filter by labels (CONTAINSANY)
-	Types        []string  // Filter by entity types
-	VerifiedOnly bool      // Only return verified entities
-	Limit        int       // Max results (default 10)
+	Mode            string      // SearchMode* (default hybrid)
+	Query           string      // Search query text
+	Embedding       []float32   // Query embedding for vector search
+	Labels          []string    // Filter by labels (CONTAINSANY)
+	LabelGroups     [][]string  // Labels OR'd within a group, groups AND'd together
+	Types           []string    // Filter by entity types
+	HasMetadataKeys []string    // Only entities with these metadata keys set
+	VerifiedOnly    bool        // Only return verified entities
+	MinConfidence   *float64    // Only entities with at least this confidence (nil = any)
+	ExcludeIDs      []string    // Entity IDs to leave out of the results
+	Language        string      // Only entities in this language (ISO 639-1)
+	Context         *string     // Only entities in this project context (nil = all)
+	ApplyDecay      bool        // Weight the fused ranking by Decay's freshness weight
+	Decay           DecayConfig // Curve and half-lives of ApplyDecay
+	Limit           int         // Max results (default 10)
+
+	// EfSearch is the number of candidates the HNSW index explores per
+	// vector search (nil = 60, clamped to 1000). Higher values find the true
+	// nearest neighbours more reliably, at the cost of latency.
+	EfSearch *int
+	// OverFetchFactor multiplies the limit to get the candidates fetched per
+	// retriever before fusion (0 = 2, clamped to 10); chunk matches fetch
+	// one limit more. More candidates improve recall, at the cost of latency.
+	OverFetchFactor int
 }
 
-// HybridSearch performs RRF fusion of BM25 + vector search results.
-// Returns entities ranked by combined relevance score.
-func (c *Client) HybridSearch(ctx context.Context, opts SearchOptions) ([]models.Entity, error) {
-	start := c.startOp()
-	defer c.recordTiming(metrics.OpDBSearch, start)
+// HNSW search defaults and bounds.
+const (
+	defaultEfSearch        = 60
+	maxEfSearch            = 1000 // Beyond this, queries slow down for little recall
+	defaultOverFetchFactor = 2
+	maxOverFetchFactor     = 10
+)
 
-	limit := opts.Limit
-	if limit <= 0 {
-		limit = 10
+// knnParams returns the efSearch and over-fetch factor of a search,
+// defaulted and clamped to their bounds.
+func (o SearchOptions) knnParams() (efSearch, overFetch int) {
+	efSearch = defaultEfSearch
+	if o.EfSearch != nil && *o.EfSearch > 0 {
+		efSearch = min(*o.EfSearch, maxEfSearch)
 	}
+	overFetch = defaultOverFetchFactor
+	if o.OverFetchFactor > 0 {
+		overFetch = min(o.OverFetchFactor, maxOverFetchFactor)
+	}
+	return efSearch, overFetch
+}
 
-	// Build dynamic filter clauses
+// searchFilterClauses builds the WHERE conditions shared by all search queries
+// and registers their parameters in vars.
+func searchFilterClauses(opts SearchOptions, vars map[string]any) []string {
 	filterClauses := []string{}
-	vars := map[string]any{
-		"q":     opts.Query,
-		"emb":   opts.Embedding,
-		"limit": limit,
-	}
 
 	if len(opts.Labels) > 0 {
 		filterClauses = append(filterClauses, "labels CONTAINSANY $labels")
 		vars["labels"] = opts.Labels
 	}
+	for i, group := range opts.LabelGroups {
+		if len(group) == 0 {
+			continue
+		}
+		param := fmt.Sprintf("label_group_%d", i)
+		filterClauses = append(filterClauses, fmt.Sprintf("labels CONTAINSANY $%s", param))
+		vars[param] = group
+	}
 	if len(opts.Types) > 0 {
 		filterClauses = append(filterClauses, "type IN $types")
 		vars["types"] = opts.Types
 	}
+	filterClauses = append(filterClauses, metadataKeyClauses(opts.HasMetadataKeys, vars)...)
 	if opts.VerifiedOnly {
 		filterClauses = append(filterClauses, "verified = true")
 	}
-
-	filterClause := ""
-	if len(filterClauses) > 0 {
-		filterClause = "AND " + strings.Join(filterClauses, " AND ")
+	if len(opts.ExcludeIDs) > 0 {
+		filterClauses = append(filterClauses, excludeIDsClause("id", opts.ExcludeIDs, vars))
+	}
+	if opts.Language != "" {
+		filterClauses = append(filterClauses, languageClause("language", opts.Language, vars))
+	}
+	if opts.MinConfidence != nil {
+		filterClauses = append(filterClauses, minConfidenceClause("confidence", *opts.MinConfidence, vars))
+	}
+	if opts.Context != nil {
+		filterClauses = append(filterClauses, contextClause("context", *opts.Context, vars))
 	}
 
-	// RRF fusion query - combines vector (2x limit for variety) with BM25
-	// Note: parentheses around OR clause ensure filter applies correctly
-	sql := fmt.Sprintf(`
-		SELECT * FROM search::rrf([
-			(SELECT * FROM entity
-			 WHERE embedding <|%d,60|> $emb %s),
-			(SELECT * FROM entity
-			 WHERE (content @0@ $q OR name @1@ $q) %s)
-		], $limit, 60)
-	`, limit*2, filterClause, filterClause)
+	return filterClauses
+}
 
-	results, err := surrealdb.Query[[]models.Entity](ctx, c.db, sql, vars)
-	if err != nil {
-		return nil, fmt.Errorf("hybrid search: %w", err)
+// excludeIDsClause builds a condition leaving out the given entity IDs.
+// field is the entity record (id on entity, entity on chunk).
+func excludeIDsClause(field string, ids []string, vars map[string]any) string {
+	vars["exclude"] = ids
+	return field + ` NOT IN $exclude.map(|$id| type::record("entity", $id))`
+}
+
+// languageClause builds a condition keeping only entities in language.
+// field is the entity's language (language on entity, entity.language on chunk).
+func languageClause(field, language string, vars map[string]any) string {
+	vars["language"] = language
+	return field + " = $language"
+}
+
+// contextClause builds a condition keeping only entities in a project
+// context. field is the entity's context (context on entity, entity.context
+// on chunk).
+func contextClause(field, entityContext string, vars map[string]any) string {
+	vars["context"] = entityContext
+	return field + " = $context"
+}
+
+// minConfidenceClause builds a condition keeping only entities with at least
+// the given confidence. field is the entity's confidence (confidence on
+// entity, entity.confidence on chunk).
+func minConfidenceClause(field string, minConfidence float64, vars map[string]any) string {
+	vars["min_confidence"] = minConfidence
+	return field + " >= $min_confidence"
+}
+
+// metadataKeyClauses builds conditions requiring each metadata key to be set.
+// Keys are passed as parameters, so arbitrary key names are safe.
+func metadataKeyClauses(keys []string, vars map[string]any) []string {
+	clauses := make([]string, 0, len(keys))
+	for i, key := range keys {
+		param := fmt.Sprintf("metadata_key_%d", i)
+		clauses = append(clauses, fmt.Sprintf("metadata[$%s] != NONE", param))
+		vars[param] = key
 	}
+	return clauses
+}
 
-	if results != nil && len(*results) > 0 {
-		return (*results)[0].Result, nil
+// chunkFilterClauses builds the WHERE conditions of searchFilterClauses for
+// the chunk table. Chunks reference their entity instead of being one, so
+// entity ID, language, confidence and context are checked on the entity.
+func chunkFilterClauses(opts SearchOptions, vars map[string]any) []string {
+	chunkOpts := opts
+	chunkOpts.ExcludeIDs = nil
+	chunkOpts.Language = ""
+	chunkOpts.MinConfidence = nil
+	chunkOpts.Context = nil
+	clauses := searchFilterClauses(chunkOpts, vars)
+	if len(opts.ExcludeIDs) > 0 {
+		clauses = append(clauses, excludeIDsClause("entity", opts.ExcludeIDs, vars))
+	}
+	if opts.Language != "" {
+		clauses = append(clauses, languageClause("entity.language", opts.Language, vars))
+	}
+	if opts.MinConfidence != nil {
+		clauses = append(clauses, minConfidenceClause("entity.confidence", *opts.MinConfidence, vars))
+	}
+	if opts.Context != nil {
+		clauses = append(clauses, contextClause("entity.context", *opts.Context, vars))
+	}
+	return clauses
+}
+
+// rrfK is the rank constant of the RRF fusion in search queries.
+const rrfK = 60
+
+// keywordScore sums the BM25 scores of the content (@0@) and name (@1@)
+// matches of a keyword search; a field that didn't match scores NONE.
+const keywordScore = "(search::score(0) ?? 0) + (search::score(1) ?? 0)"
+
+// applyDecay reorders fused search results by their RRF score multiplied by
+// the entity's decay weight under cfg, so fresher knowledge ranks higher.
+// The fused order stands in for the score: the result at rank r (from 1)
+// scores 1/(rrfK+r). Ties keep their fused order.
+func applyDecay[T any](results []T, cfg DecayConfig, entity func(T) models.Entity) {
+	now := time.Now()
+	scores := make([]float64, len(results))
+	order := make([]int, len(results))
+	for i, result := range results {
+		e := entity(result)
+		scores[i] = cfg.Weight(e.Type, e.Accessed, e.Pinned, now) / float64(rrfK+i+1)
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(scores[b], scores[a])
+	})
+
+	sorted := make([]T, len(results))
+	for i, idx := range order {
+		sorted[i] = results[idx]
 	}
-	return []models.Entity{}, nil
+	copy(results, sorted)
 }
 
-// SearchWithChunks performs hybrid search including chunk matches.
-// Returns entities with their matching chunks for RAG context.
-func (c *Client) SearchWithChunks(ctx context.Context, opts SearchOptions) ([]models.EntitySearchResult, error) {
+// HybridSearch performs RRF fusion of BM25 + vector search results, or
+// runs one of them alone with opts.Mode. Returns entities ranked by
+// relevance, weighted by decay weight with opts.ApplyDecay.
+//
+// opts.EfSearch and opts.OverFetchFactor trade recall for latency: the
+// HNSW index is approximate, so with near-duplicate embeddings a small
+// efSearch can miss some of the nearest neighbours; raising it (or fetching
+// more candidates) finds them at the cost of a slower query.
+func (c *Client) HybridSearch(ctx context.Context, opts SearchOptions) ([]models.Entity, error) {
 	start := c.startOp()
 	defer c.recordTiming(metrics.OpDBSearch, start)
+	defer tracing.Start(ctx, "db.hybrid_search")()
 
 	limit := opts.Limit
 	if limit <= 0 {
 		limit = 10
 	}
+	efSearch, overFetch := opts.knnParams()
 
-	// Build filter clause
-	filterClauses := []string{}
+	// Build dynamic filter clauses
 	vars := map[string]any{
 		"q":     opts.Query,
 		"emb":   opts.Embedding,
 		"limit": limit,
 	}
+	filterClauses := searchFilterClauses(opts, vars)
 
-	if len(opts.Labels) > 0 {
-		filterClauses = append(filterClauses, "labels CONTAINSANY $labels")
-		vars["labels"] = opts.Labels
+	filterClause := ""
+	if len(filterClauses) > 0 {
+		filterClause = "AND " + strings.Join(filterClauses, " AND ")
 	}
-	if len(opts.Types) > 0 {
-		filterClauses = append(filterClauses, "type IN $types")
-		vars["types"] = opts.Types
+
+	var sql string
+	switch opts.Mode {
+	case SearchModeKeyword:
+		// Note: parentheses around OR clause ensure filter applies correctly
+		sql = fmt.Sprintf(`
+			SELECT *, %s AS score FROM entity
+			WHERE (content @0@ $q OR name @1@ $q) %s
+			ORDER BY score DESC LIMIT $limit
+		`, keywordScore, filterClause)
+	case SearchModeVector:
+		sql = fmt.Sprintf(`
+			SELECT *, vector::similarity::cosine(embedding, $emb) AS score FROM entity
+			WHERE embedding <|%d,%d|> $emb %s
+			ORDER BY score DESC
+		`, limit, efSearch, filterClause)
+	default:
+		// RRF fusion query - combines vector (over-fetched for variety) with BM25
+		// Note: parentheses around OR clause ensure filter applies correctly
+		sql = fmt.Sprintf(`
+			SELECT * FROM search::rrf([
+				(SELECT * FROM entity
+				 WHERE embedding <|%d,%d|> $emb %s),
+				(SELECT * FROM entity
+				 WHERE (content @0@ $q OR name @1@ $q) %s)
+			], $limit, %d)
+		`, limit*overFetch, efSearch, filterClause, filterClause, rrfK)
+	}
+
+	results, err := runQuery[[]models.Entity](ctx, c, sql, vars)
+	if err != nil {
+		return nil, fmt.Errorf("hybrid search: %w", err)
 	}
-	if opts.VerifiedOnly {
-		filterClauses = append(filterClauses, "verified = true")
+
+	if results == nil || len(*results) == 0 {
+		return []models.Entity{}, nil
 	}
+	entities := (*results)[0].Result
+	if opts.ApplyDecay {
+		applyDecay(entities, opts.Decay, func(e models.Entity) models.Entity { return e })
+	}
+	return entities, nil
+}
+
+// SearchWithChunks performs hybrid search including chunk matches. Like
+// HybridSearch, opts.Mode can restrict it to BM25 or vector matches, and
+// opts.EfSearch and opts.OverFetchFactor trade recall for latency. In those
+// modes entity and chunk matches are ranked together by score.
+// Returns entities with their matching chunks for RAG context, weighted by
+// decay weight with opts.ApplyDecay.
+func (c *Client) SearchWithChunks(ctx context.Context, opts SearchOptions) ([]models.EntitySearchResult, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBSearch, start)
+	defer tracing.Start(ctx, "db.search_with_chunks")()
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	efSearch, overFetch := opts.knnParams()
+	entityCandidates, chunkCandidates := limit*overFetch, limit*(overFetch+1)
+
+	// Build filter clause
+	vars := map[string]any{
+		"q":   opts.Query,
+		"emb": opts.Embedding,
+	}
+	filterClauses := searchFilterClauses(opts, vars)
+	chunkFilterClauses := chunkFilterClauses(opts, vars)
 
 	filterClause := ""
 	chunkFilterClause := ""
 	if len(filterClauses) > 0 {
 		filterClause = "AND " + strings.Join(filterClauses, " AND ")
-		chunkFilterClause = "AND " + strings.Join(filterClauses, " AND ")
+	}
+	if len(chunkFilterClauses) > 0 {
+		chunkFilterClause = "AND " + strings.Join(chunkFilterClauses, " AND ")
 	}
 
-	// Search entities and chunks, then aggregate by entity
-	sql := fmt.Sprintf(`
-		LET $entity_hits = (
+	// Entity and chunk retrievers of the search mode
+	var entityHits, chunkHits string
+	switch opts.Mode {
+	case SearchModeKeyword:
+		entityHits = fmt.Sprintf(`
+			SELECT *, [] AS matched_chunks, %s AS score FROM entity
+			WHERE (content @0@ $q OR name @1@ $q) %s
+			ORDER BY score DESC LIMIT %d`, keywordScore, filterClause, entityCandidates)
+		chunkHits = fmt.Sprintf(`
+			SELECT entity.* AS entity, search::score(0) AS score,
+				   [{ content: content, heading_path: heading_path, position: position }] AS matched_chunks
+			FROM chunk
+			WHERE content @0@ $q %s
+			ORDER BY score DESC LIMIT %d`, chunkFilterClause, chunkCandidates)
+	case SearchModeVector:
+		entityHits = fmt.Sprintf(`
+			SELECT *, [] AS matched_chunks, vector::similarity::cosine(embedding, $emb) AS score FROM entity
+			WHERE embedding <|%d,%d|> $emb %s
+			ORDER BY score DESC`, entityCandidates, efSearch, filterClause)
+		chunkHits = fmt.Sprintf(`
+			SELECT entity.* AS entity, vector::similarity::cosine(embedding, $emb) AS score,
+				   [{ content: content, heading_path: heading_path, position: position }] AS matched_chunks
+			FROM chunk
+			WHERE embedding <|%d,%d|> $emb %s
+			ORDER BY score DESC`, chunkCandidates, efSearch, chunkFilterClause)
+	default:
+		entityHits = fmt.Sprintf(`
 			SELECT *, [] AS matched_chunks FROM search::rrf([
-				(SELECT * FROM entity WHERE embedding <|%d,60|> $emb %s),
-				(SELECT * FROM entity WHERE content @0@ $q OR name @1@ $q %s)
-			], %d, 60)
-		);
-
-		LET $chunk_hits = (
+				(SELECT * FROM entity WHERE embedding <|%d,%d|> $emb %s),
+				(SELECT * FROM entity WHERE (content @0@ $q OR name @1@ $q) %s)
+			], %d, %d)`, entityCandidates, efSearch, filterClause, filterClause, entityCandidates, rrfK)
+		chunkHits = fmt.Sprintf(`
 			SELECT entity.* AS entity,
 				   [{ content: content, heading_path: heading_path, position: position }] AS matched_chunks
 			FROM chunk
-			WHERE embedding <|%d,60|> $emb %s
+			WHERE embedding <|%d,%d|> $emb %s`, chunkCandidates, efSearch, chunkFilterClause)
+	}
+
+	// Search entities and chunks, then aggregate by entity
+	sql := fmt.Sprintf(`
+		LET $entity_hits = (%s
+		);
+
+		LET $chunk_hits = (%s
 		);
 
 		-- Merge entity hits with chunk hits
 		RETURN array::distinct(array::concat($entity_hits, $chunk_hits.map(|$c|
-			object::extend($c.entity, { matched_chunks: $c.matched_chunks })
-		))).slice(0, $limit)
-	`, limit*2, filterClause, filterClause, limit*2, limit*3, chunkFilterClause)
+			object::extend($c.entity, { matched_chunks: $c.matched_chunks, score: $c.score })
+		)))
+	`, entityHits, chunkHits)
 
-	results, err := surrealdb.Query[[]models.EntitySearchResult](ctx, c.db, sql, vars)
+	results, err := runQuery[[]models.EntitySearchResult](ctx, c, sql, vars)
 	if err != nil {
 		return nil, fmt.Errorf("search with chunks: %w", err)
 	}
 
 	// Result is in the last query result (RETURN statement)
-	if results != nil && len(*results) > 0 {
-		lastIdx := len(*results) - 1
-		return (*results)[lastIdx].Result, nil
+	if results == nil || len(*results) == 0 {
+		return []models.EntitySearchResult{}, nil
+	}
+	hits := (*results)[len(*results)-1].Result
+	if opts.Mode == SearchModeKeyword || opts.Mode == SearchModeVector {
+		// Entity and chunk hits are scored alike: interleave them, best first
+		slices.SortStableFunc(hits, func(a, b models.EntitySearchResult) int { return cmp.Compare(b.Score, a.Score) })
 	}
-	return []models.EntitySearchResult{}, nil
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	if opts.ApplyDecay {
+		applyDecay(hits, opts.Decay, func(r models.EntitySearchResult) models.Entity { return r.Entity })
+	}
+	return hits, nil
+}
+
+// SearchChunks performs hybrid search on chunks alone, returning the best
+// chunks across all entities ordered by fused score (or by BM25 or cosine
+// similarity score with opts.Mode). Unlike SearchWithChunks, results aren't
+// grouped: one entity can contribute several chunks. Each match carries its
+// entity's ID and name for citation. opts.ApplyDecay is ignored.
+func (c *Client) SearchChunks(ctx context.Context, opts SearchOptions) ([]models.ChunkMatch, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBSearch, start)
+	defer tracing.Start(ctx, "db.search_chunks")()
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	efSearch, overFetch := opts.knnParams()
+
+	vars := map[string]any{
+		"q":     opts.Query,
+		"emb":   opts.Embedding,
+		"limit": limit,
+	}
+	filterClause := ""
+	if clauses := chunkFilterClauses(opts, vars); len(clauses) > 0 {
+		filterClause = "AND " + strings.Join(clauses, " AND ")
+	}
+
+	const fields = "meta::id(entity) AS entity_id, entity.name AS entity_name, content, heading_path, position"
+	var sql string
+	switch opts.Mode {
+	case SearchModeKeyword:
+		sql = fmt.Sprintf(`
+			SELECT %s, search::score(0) AS score FROM chunk
+			WHERE content @0@ $q %s
+			ORDER BY score DESC LIMIT $limit
+		`, fields, filterClause)
+	case SearchModeVector:
+		sql = fmt.Sprintf(`
+			SELECT %s, vector::similarity::cosine(embedding, $emb) AS score FROM chunk
+			WHERE embedding <|%d,%d|> $emb %s
+			ORDER BY score DESC
+		`, fields, limit, efSearch, filterClause)
+	default:
+		sql = fmt.Sprintf(`
+			SELECT %s, rrf_score AS score FROM search::rrf([
+				(SELECT * FROM chunk
+				 WHERE embedding <|%d,%d|> $emb %s),
+				(SELECT * FROM chunk
+				 WHERE content @0@ $q %s)
+			], $limit, %d)
+		`, fields, limit*overFetch, efSearch, filterClause, filterClause, rrfK)
+	}
+
+	results, err := runQuery[[]models.ChunkMatch](ctx, c, sql, vars)
+	if err != nil {
+		return nil, fmt.Errorf("search chunks: %w", err)
+	}
+	if results == nil || len(*results) == 0 {
+		return []models.ChunkMatch{}, nil
+	}
+	return (*results)[0].Result, nil
 }
 
 // =============================================================================
@@ -584,7 +1328,7 @@ func (c *Client) CreateChunks(ctx context.Context, entityID string, chunks []mod
 		if labels == nil {
 			labels = []string{}
 		}
-		_, err := surrealdb.Query[any](ctx, c.db, sql, map[string]any{
+		_, err := runQuery[any](ctx, c, sql, map[string]any{
 			"entity_id":    entityID,
 			"content":      chunk.Content,
 			"position":     chunk.Position,
@@ -602,7 +1346,7 @@ func (c *Client) CreateChunks(ctx context.Context, entityID string, chunks []mod
 
 // DeleteChunks deletes all chunks for an entity.
 func (c *Client) DeleteChunks(ctx context.Context, entityID string) error {
-	_, err := surrealdb.Query[any](ctx, c.db, `
+	_, err := runQuery[any](ctx, c, `
 		DELETE chunk WHERE entity = type::record("entity", $entity_id)
 	`, map[string]any{"entity_id": entityID})
 	if err != nil {
@@ -613,7 +1357,7 @@ func (c *Client) DeleteChunks(ctx context.Context, entityID string) error {
 
 // GetChunks retrieves all chunks for an entity, ordered by position.
 func (c *Client) GetChunks(ctx context.Context, entityID string) ([]models.Chunk, error) {
-	results, err := surrealdb.Query[[]models.Chunk](ctx, c.db, `
+	results, err := runQuery[[]models.Chunk](ctx, c, `
 		SELECT * FROM chunk
 		WHERE entity = type::record("entity", $entity_id)
 		ORDER BY position ASC
@@ -635,6 +1379,9 @@ func (c *Client) GetChunks(ctx context.Context, entityID string) ([]models.Chunk
 
 // CreateRelation creates a relation between two entities.
 // If a relation of the same type already exists, updates its strength.
+// Concurrent calls for the same relation are safe: a call losing the race
+// retries once after a short random wait. Errors wrap ErrTransactionConflict
+// if the retry conflicts again.
 func (c *Client) CreateRelation(ctx context.Context, input models.RelationInput) error {
 	c.startOp() // Mark activity for heartbeat
 	strength := 1.0
@@ -664,27 +1411,113 @@ func (c *Client) CreateRelation(ctx context.Context, input models.RelationInput)
 		END
 	`
 
-	_, err := surrealdb.Query[any](ctx, c.db, sql, map[string]any{
+	vars := map[string]any{
 		"from_id":  input.FromID,
 		"to_id":    input.ToID,
 		"rel_type": input.RelType,
 		"strength": strength,
 		"source":   source,
 		"metadata": optionalObject(input.Metadata),
-	})
+	}
+	_, err := runQuery[any](ctx, c, sql, vars)
+	if err != nil && isRelationRace(err) {
+		// Another caller created the same relation concurrently. The unique
+		// key makes the retry an update of that relation.
+		slog.Debug("relation creation raced, retrying", "from", input.FromID, "to", input.ToID, "rel_type", input.RelType, "error", err)
+		select {
+		case <-time.After(rand.N(relationRetryJitter)):
+		case <-ctx.Done():
+			return fmt.Errorf("create relation: %w", ctx.Err())
+		}
+		_, err = runQuery[any](ctx, c, sql, vars)
+	}
 	if err != nil {
-		return fmt.Errorf("create relation: %w", err)
+		return fmt.Errorf("create relation: %w", wrapQueryError(err))
 	}
 	return nil
 }
 
+// relationRetryJitter bounds the random wait before CreateRelation retries a
+// raced relation, so concurrent retries don't collide again.
+const relationRetryJitter = 20 * time.Millisecond
+
+// isRelationRace reports whether err comes from creating a relation another
+// caller created at the same time: a transaction conflict, or the unique
+// index on unique_key rejecting the second edge.
+func isRelationRace(err error) bool {
+	if errors.Is(wrapQueryError(err), ErrTransactionConflict) {
+		return true
+	}
+	var queryErr *surrealdb.QueryError
+	return errors.As(err, &queryErr) && strings.Contains(queryErr.Message, "unique_relates_to")
+}
+
+// relationBatchSize bounds the number of relations upserted per query.
+const relationBatchSize = 100
+
+// CreateRelations creates many relations with the same upsert semantics as
+// CreateRelation, batching several relations into each query. Each batch is
+// a transaction. Returns the number of relations written, which on error
+// counts the batches committed before the failing one.
+func (c *Client) CreateRelations(ctx context.Context, inputs []models.RelationInput) (int, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	for batchStart := 0; batchStart < len(inputs); batchStart += relationBatchSize {
+		batch := inputs[batchStart:min(batchStart+relationBatchSize, len(inputs))]
+
+		var sql strings.Builder
+		sql.WriteString("BEGIN TRANSACTION;")
+		vars := make(map[string]any, len(batch)*6)
+		for i, input := range batch {
+			strength := 1.0
+			if input.Strength != nil {
+				strength = *input.Strength
+			}
+			source := "manual"
+			if input.Source != nil {
+				source = *input.Source
+			}
+			vars[fmt.Sprintf("from_%d", i)] = input.FromID
+			vars[fmt.Sprintf("to_%d", i)] = input.ToID
+			vars[fmt.Sprintf("rel_type_%d", i)] = input.RelType
+			vars[fmt.Sprintf("strength_%d", i)] = strength
+			vars[fmt.Sprintf("source_%d", i)] = source
+			vars[fmt.Sprintf("metadata_%d", i)] = optionalObject(input.Metadata)
+
+			fmt.Fprintf(&sql, `
+				LET $from_rec = type::record("entity", $from_%[1]d);
+				LET $to_rec = type::record("entity", $to_%[1]d);
+				LET $unique = string::concat(array::sort([<string>$from_rec, <string>$to_rec]), $rel_type_%[1]d);
+				LET $existing = (SELECT * FROM relates_to WHERE unique_key = $unique);
+				IF array::len($existing) > 0 THEN
+					UPDATE $existing[0].id SET strength = $strength_%[1]d, metadata = $metadata_%[1]d
+				ELSE
+					RELATE $from_rec->relates_to->$to_rec SET
+						rel_type = $rel_type_%[1]d,
+						strength = $strength_%[1]d,
+						source = $source_%[1]d,
+						metadata = $metadata_%[1]d
+				END;
+			`, i)
+		}
+
+		sql.WriteString("COMMIT TRANSACTION;")
+
+		if _, err := runQuery[any](ctx, c, sql.String(), vars); err != nil {
+			return batchStart, fmt.Errorf("create relations: %w", wrapQueryError(err))
+		}
+	}
+	return len(inputs), nil
+}
+
 // GetRelations retrieves all relations for an entity (both directions).
 func (c *Client) GetRelations(ctx context.Context, entityID string) ([]models.Relation, error) {
 	sql := `
 		SELECT * FROM relates_to
 		WHERE in = type::record("entity", $id) OR out = type::record("entity", $id)
 	`
-	results, err := surrealdb.Query[[]models.Relation](ctx, c.db, sql, map[string]any{"id": entityID})
+	results, err := runQuery[[]models.Relation](ctx, c, sql, map[string]any{"id": entityID})
 	if err != nil {
 		return nil, fmt.Errorf("get relations: %w", err)
 	}
@@ -695,6 +1528,116 @@ func (c *Client) GetRelations(ctx context.Context, entityID string) ([]models.Re
 	return (*results)[0].Result, nil
 }
 
+// Neighbor is an entity reached from an origin entity over relations.
+type Neighbor struct {
+	models.Entity
+	Distance int // Hops on the shortest path from the origin
+}
+
+// GetNeighbors returns the entities within depth hops of an entity,
+// following relates_to in both directions, nearest first. With relTypes set,
+// only relations of those types are followed. Entities reached via several
+// paths are returned once with their shortest distance; the origin itself
+// is left out.
+//
+// Traversal is a breadth-first search with one single-hop SELECT per level,
+// as the SDK fails to decode results of ->relates_to..{depth}-> recursion.
+func (c *Client) GetNeighbors(ctx context.Context, entityID string, depth int, relTypes []string) ([]Neighbor, error) {
+	return c.GetNeighborsIn(ctx, entityID, depth, relTypes, nil)
+}
+
+// GetNeighborsIn is GetNeighbors restricted to the entities of a project
+// context: only relations to entities of that context are followed. A nil
+// context follows all relations.
+func (c *Client) GetNeighborsIn(ctx context.Context, entityID string, depth int, relTypes []string, entityContext *string) ([]Neighbor, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	if depth <= 0 {
+		depth = 1
+	}
+
+	typeClause := ""
+	vars := map[string]any{}
+	if len(relTypes) > 0 {
+		typeClause = "AND rel_type IN $rel_types"
+		vars["rel_types"] = relTypes
+	}
+	where := "(in IN $recs OR out IN $recs)"
+	if entityContext != nil {
+		where = "((in IN $recs AND " + contextClause("out.context", *entityContext, vars) +
+			") OR (out IN $recs AND " + contextClause("in.context", *entityContext, vars) + "))"
+	}
+	sql := fmt.Sprintf(`
+		LET $recs = $ids.map(|$id| type::record("entity", $id));
+		SELECT in, out FROM relates_to WHERE %s %s;
+	`, where, typeClause)
+
+	distance := map[string]int{entityID: 0}
+	var order []string
+	frontier := []string{entityID}
+	for hop := 1; hop <= depth && len(frontier) > 0; hop++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		vars["ids"] = frontier
+		results, err := runQuery[[]models.Relation](ctx, c, sql, vars)
+		if err != nil {
+			return nil, fmt.Errorf("get neighbors: %w", err)
+		}
+		if results == nil || len(*results) == 0 {
+			break
+		}
+
+		inFrontier := make(map[string]bool, len(frontier))
+		for _, id := range frontier {
+			inFrontier[id] = true
+		}
+		var next []string
+		for _, rel := range (*results)[len(*results)-1].Result {
+			inID, err := models.RecordIDString(rel.In)
+			if err != nil {
+				slog.Debug("skipping relation with invalid source", "error", err)
+				continue
+			}
+			outID, err := models.RecordIDString(rel.Out)
+			if err != nil {
+				slog.Debug("skipping relation with invalid target", "error", err)
+				continue
+			}
+			// A relation between two frontier entities leads to both
+			for _, pair := range [][2]string{{inID, outID}, {outID, inID}} {
+				from, to := pair[0], pair[1]
+				if !inFrontier[from] {
+					continue
+				}
+				if _, seen := distance[to]; seen {
+					continue
+				}
+				distance[to] = hop
+				order = append(order, to)
+				next = append(next, to)
+			}
+		}
+		frontier = next
+	}
+
+	found, err := c.GetEntitiesByIDs(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	// order is by distance already, as each level is appended in turn
+	neighbors := make([]Neighbor, 0, len(order))
+	for _, id := range order {
+		if entity, ok := found[id]; ok {
+			neighbors = append(neighbors, Neighbor{Entity: *entity, Distance: distance[id]})
+		}
+	}
+	return neighbors, nil
+}
+
 // DeleteRelation deletes a specific relation by from, to, and type.
 func (c *Client) DeleteRelation(ctx context.Context, fromID, toID, relType string) error {
 	sql := `
@@ -703,7 +1646,7 @@ func (c *Client) DeleteRelation(ctx context.Context, fromID, toID, relType strin
 			OR
 			(in = type::record("entity", $to_id) AND out = type::record("entity", $from_id) AND rel_type = $rel_type)
 	`
-	_, err := surrealdb.Query[any](ctx, c.db, sql, map[string]any{
+	_, err := runQuery[any](ctx, c, sql, map[string]any{
 		"from_id":  fromID,
 		"to_id":    toID,
 		"rel_type": relType,
@@ -714,6 +1657,418 @@ func (c *Client) DeleteRelation(ctx context.Context, fromID, toID, relType strin
 	return nil
 }
 
+// ListRelations returns all relations.
+func (c *Client) ListRelations(ctx context.Context) ([]models.Relation, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[[]models.Relation](ctx, c, `SELECT * FROM relates_to`, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list relations: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return []models.Relation{}, nil
+	}
+	return (*results)[0].Result, nil
+}
+
+// ListDanglingRelations returns relations whose source or target entity
+// doesn't exist.
+func (c *Client) ListDanglingRelations(ctx context.Context) ([]models.Relation, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[[]models.Relation](ctx, c, `
+		SELECT * FROM relates_to WHERE !record::exists(in) OR !record::exists(out)
+	`, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list dangling relations: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return []models.Relation{}, nil
+	}
+	return (*results)[0].Result, nil
+}
+
+// DeleteRelationsByID deletes relations by their record IDs.
+func (c *Client) DeleteRelationsByID(ctx context.Context, ids []surrealmodels.RecordID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	if _, err := runQuery[any](ctx, c, `DELETE $ids`, map[string]any{"ids": ids}); err != nil {
+		return fmt.Errorf("delete relations: %w", err)
+	}
+	return nil
+}
+
+// RebuildRelationKeys recomputes unique_key for all relations and removes
+// duplicate relations (same entity pair and type), keeping the one with the
+// highest strength. Returns the number of duplicates removed.
+func (c *Client) RebuildRelationKeys(ctx context.Context) (int, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	// Compute keys with the same expression as the unique_key field definition,
+	// since stored keys may be missing or stale.
+	type keyRow struct {
+		ID       surrealmodels.RecordID `json:"id"`
+		Key      string                 `json:"key"`
+		Strength float64                `json:"strength"`
+	}
+	results, err := runQuery[[]keyRow](ctx, c, `
+		SELECT id, strength, <string>string::concat(array::sort([<string>in, <string>out]), rel_type) AS key
+		FROM relates_to
+		ORDER BY created_at ASC
+	`, nil)
+	if err != nil {
+		return 0, fmt.Errorf("list relation keys: %w", err)
+	}
+
+	var rows []keyRow
+	if results != nil && len(*results) > 0 {
+		rows = (*results)[0].Result
+	}
+
+	// Keep the strongest relation per key (earliest wins on ties)
+	keep := make(map[string]keyRow, len(rows))
+	var duplicates []surrealmodels.RecordID
+	for _, row := range rows {
+		kept, ok := keep[row.Key]
+		if !ok {
+			keep[row.Key] = row
+			continue
+		}
+		if row.Strength > kept.Strength {
+			duplicates = append(duplicates, kept.ID)
+			keep[row.Key] = row
+		} else {
+			duplicates = append(duplicates, row.ID)
+		}
+	}
+
+	if len(duplicates) > 0 {
+		slog.Info("removing duplicate relations", "count", len(duplicates))
+		if _, err := runQuery[any](ctx, c, `DELETE $ids`, map[string]any{"ids": duplicates}); err != nil {
+			return 0, fmt.Errorf("delete duplicate relations: %w", err)
+		}
+	}
+
+	// Touching every relation re-evaluates the unique_key VALUE expression
+	if _, err := runQuery[any](ctx, c, `
+		UPDATE relates_to;
+		REBUILD INDEX IF EXISTS unique_relates_to ON relates_to;
+	`, nil); err != nil {
+		return 0, fmt.Errorf("rebuild relation keys: %w", err)
+	}
+
+	return len(duplicates), nil
+}
+
+// =============================================================================
+// CONTRADICTION QUERIES
+// =============================================================================
+
+// similarEntity is an entity found by the distance of its embedding.
+type similarEntity struct {
+	models.Entity
+	Distance float64 `json:"distance"`
+}
+
+// similarChunk is the entity of a chunk found by the distance of the chunk's
+// embedding.
+type similarChunk struct {
+	Entity   models.Entity `json:"entity"`
+	Distance float64       `json:"distance"`
+}
+
+// similarChunksPerEntity is the number of chunks FindSimilarEntities fetches
+// per entity it returns, as one entity can have several close chunks.
+const similarChunksPerEntity = 3
+
+// FindSimilarEntities returns the limit entities nearest to emb, nearest
+// first, leaving out the entity excludeID. Entities match by their own
+// embedding or by their nearest chunk, so chunked entities, which have no
+// embedding of their own, are found too.
+func (c *Client) FindSimilarEntities(ctx context.Context, emb []float32, excludeID string, limit int) ([]models.Entity, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBSearch, start)
+
+	if len(emb) == 0 {
+		return []models.Entity{}, nil
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+	vars := map[string]any{
+		"emb":     emb,
+		"exclude": excludeID,
+	}
+
+	entities, err := runQuery[[]similarEntity](ctx, c, fmt.Sprintf(`
+		SELECT *, vector::distance::knn() AS distance FROM entity
+		WHERE embedding <|%d,60|> $emb AND id != type::record("entity", $exclude)
+		ORDER BY distance ASC
+	`, limit), vars)
+	if err != nil {
+		return nil, fmt.Errorf("find similar entities: %w", err)
+	}
+	chunks, err := runQuery[[]similarChunk](ctx, c, fmt.Sprintf(`
+		SELECT entity.* AS entity, vector::distance::knn() AS distance FROM chunk
+		WHERE embedding <|%d,60|> $emb AND entity != type::record("entity", $exclude)
+		ORDER BY distance ASC
+	`, limit*similarChunksPerEntity), vars)
+	if err != nil {
+		return nil, fmt.Errorf("find similar chunks: %w", err)
+	}
+
+	// Merge both, keeping each entity at its nearest distance
+	var hits []similarEntity
+	if entities != nil && len(*entities) > 0 {
+		hits = (*entities)[0].Result
+	}
+	if chunks != nil && len(*chunks) > 0 {
+		for _, ch := range (*chunks)[0].Result {
+			hits = append(hits, similarEntity{Entity: ch.Entity, Distance: ch.Distance})
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b similarEntity) int {
+		return cmp.Compare(a.Distance, b.Distance)
+	})
+
+	seen := make(map[string]bool, len(hits))
+	similar := make([]models.Entity, 0, limit)
+	for _, hit := range hits {
+		id, err := models.RecordIDString(hit.ID)
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		similar = append(similar, hit.Entity)
+		if len(similar) == limit {
+			break
+		}
+	}
+	return similar, nil
+}
+
+// SimilarPair is two entities whose embeddings are near-duplicates.
+type SimilarPair struct {
+	A, B       models.Entity
+	Similarity float64 // Cosine similarity of the embeddings
+}
+
+// SimilarPairPage is the pairs found for one page of entities and the cursor
+// for the next page.
+type SimilarPairPage struct {
+	Pairs      []SimilarPair
+	NextCursor string // Empty when there are no more entities
+}
+
+// FindSimilarPairs returns the pairs of entities whose embeddings have a
+// cosine similarity of at least minSimilarity, most similar first, for one
+// page of limit entities (ordered by ID). cursor is the NextCursor of the
+// previous page, or "" for the first page. Each entity is compared with its
+// peers nearest entities only. Chunked entities, which have no embedding of
+// their own, are compared by their first chunk and found by any of their
+// chunks. A pair is reported once per page, but a pair whose entities are
+// on different pages can be reported on both. Entities are returned without
+// embedding or content.
+func (c *Client) FindSimilarPairs(ctx context.Context, minSimilarity float64, peers, limit int, cursor string) (SimilarPairPage, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBSearch, start)
+
+	if peers <= 0 {
+		peers = 5
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	type candidate struct {
+		models.Entity
+		Similarity float64 `json:"similarity"`
+	}
+	type chunkCandidate struct {
+		Entity     models.Entity `json:"entity"`
+		Similarity float64       `json:"similarity"`
+	}
+	const fields = `id, name, type, labels, verified, confidence, access_count`
+
+	vars := map[string]any{"limit": limit + 1} // one extra to detect a next page
+	cursorClause := ""
+	if cursor != "" {
+		cursorClause = `WHERE id > type::record("entity", $after)`
+		vars["after"] = cursor
+	}
+	all, err := runQuery[[]candidate](ctx, c, fmt.Sprintf(`
+		SELECT %s, embedding ?? (
+			SELECT VALUE embedding FROM chunk WHERE entity = $parent.id ORDER BY position LIMIT 1
+		)[0] AS embedding
+		FROM entity %s ORDER BY id LIMIT $limit
+	`, fields, cursorClause), vars)
+	if err != nil {
+		return SimilarPairPage{}, fmt.Errorf("find similar pairs: %w", err)
+	}
+	page := SimilarPairPage{Pairs: []SimilarPair{}}
+	if all == nil || len(*all) == 0 {
+		return page, nil
+	}
+	entities := (*all)[0].Result
+	if len(entities) > limit {
+		entities = entities[:limit]
+		id, err := models.RecordIDString(entities[limit-1].ID)
+		if err != nil {
+			return SimilarPairPage{}, fmt.Errorf("get entity ID for cursor: %w", err)
+		}
+		page.NextCursor = id
+	}
+
+	entitySQL := fmt.Sprintf(`
+		SELECT %s, vector::similarity::cosine(embedding, $emb) AS similarity FROM entity
+		WHERE embedding <|%d,60|> $emb AND id != $id
+	`, fields, peers)
+	chunkSQL := fmt.Sprintf(`
+		SELECT entity.{%s} AS entity, vector::similarity::cosine(embedding, $emb) AS similarity FROM chunk
+		WHERE embedding <|%d,60|> $emb AND entity != $id
+	`, fields, peers*similarChunksPerEntity)
+
+	seen := make(map[[2]string]bool)
+	for _, a := range entities {
+		if len(a.Embedding) == 0 {
+			continue
+		}
+		aID, err := models.RecordIDString(a.ID)
+		if err != nil {
+			return SimilarPairPage{}, fmt.Errorf("find similar pairs: %w", err)
+		}
+		vars := map[string]any{"emb": a.Embedding, "id": a.ID}
+		results, err := runQuery[[]candidate](ctx, c, entitySQL, vars)
+		if err != nil {
+			return SimilarPairPage{}, fmt.Errorf("find similar pairs: %w", err)
+		}
+		chunks, err := runQuery[[]chunkCandidate](ctx, c, chunkSQL, vars)
+		if err != nil {
+			return SimilarPairPage{}, fmt.Errorf("find similar pairs: %w", err)
+		}
+
+		var peersFound []candidate
+		if results != nil && len(*results) > 0 {
+			peersFound = (*results)[0].Result
+		}
+		if chunks != nil && len(*chunks) > 0 {
+			for _, ch := range (*chunks)[0].Result {
+				peersFound = append(peersFound, candidate{Entity: ch.Entity, Similarity: ch.Similarity})
+			}
+		}
+		// Most similar first, so each peer is kept at its best match
+		slices.SortStableFunc(peersFound, func(x, y candidate) int { return cmp.Compare(y.Similarity, x.Similarity) })
+
+		a.Embedding = nil
+		compared := make(map[string]bool, peers)
+		for _, b := range peersFound {
+			if b.Similarity < minSimilarity {
+				break
+			}
+			bID, err := models.RecordIDString(b.ID)
+			if err != nil {
+				return SimilarPairPage{}, fmt.Errorf("find similar pairs: %w", err)
+			}
+			if compared[bID] {
+				continue
+			}
+			if len(compared) == peers {
+				break
+			}
+			compared[bID] = true
+			key := [2]string{aID, bID}
+			if bID < aID {
+				key = [2]string{bID, aID}
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			page.Pairs = append(page.Pairs, SimilarPair{A: a.Entity, B: b.Entity, Similarity: b.Similarity})
+		}
+	}
+
+	slices.SortStableFunc(page.Pairs, func(x, y SimilarPair) int { return cmp.Compare(y.Similarity, x.Similarity) })
+	return page, nil
+}
+
+// CreateContradiction records that two entities make contradictory claims.
+// Returns false without changes if a contradiction between the two exists
+// already in either direction, including one marked resolved.
+func (c *Client) CreateContradiction(ctx context.Context, input models.ContradictionInput) (bool, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	confidence := 0.5
+	if input.Confidence != nil {
+		confidence = *input.Confidence
+	}
+
+	results, err := runQuery[any](ctx, c, `
+		LET $from = type::record("entity", $from_id);
+		LET $to = type::record("entity", $to_id);
+		LET $existing = (SELECT VALUE id FROM contradicts
+			WHERE (in = $from AND out = $to) OR (in = $to AND out = $from));
+		IF array::len($existing) > 0 OR !record::exists($from) OR !record::exists($to) {
+			false
+		} ELSE {
+			RELATE $from->contradicts->$to SET
+				explanation = $explanation,
+				confidence = $confidence;
+			true
+		};
+	`, map[string]any{
+		"from_id":     input.FromID,
+		"to_id":       input.ToID,
+		"explanation": input.Explanation,
+		"confidence":  confidence,
+	})
+	if err != nil {
+		return false, fmt.Errorf("create contradiction: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return false, nil
+	}
+	created, _ := (*results)[len(*results)-1].Result.(bool)
+	return created, nil
+}
+
+// ListVerifiedEntityIDs returns the IDs of all verified entities.
+func (c *Client) ListVerifiedEntityIDs(ctx context.Context) ([]string, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[[]models.Entity](ctx, c, `
+		SELECT id FROM entity WHERE verified = true ORDER BY id
+	`, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list verified entity ids: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return []string{}, nil
+	}
+	ids := make([]string, 0, len((*results)[0].Result))
+	for _, e := range (*results)[0].Result {
+		id, err := models.RecordIDString(e.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list verified entity ids: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 // =============================================================================
 // TEMPLATE QUERIES
 // =============================================================================
@@ -730,7 +2085,7 @@ func (c *Client) CreateTemplate(ctx context.Context, input models.TemplateInput)
 		RETURN AFTER
 	`
 
-	results, err := surrealdb.Query[[]models.Template](ctx, c.db, sql, map[string]any{
+	results, err := runQuery[[]models.Template](ctx, c, sql, map[string]any{
 		"id":          id,
 		"name":        input.Name,
 		"description": optionalString(input.Description),
@@ -749,7 +2104,7 @@ func (c *Client) CreateTemplate(ctx context.Context, input models.TemplateInput)
 
 // GetTemplate retrieves a template by name.
 func (c *Client) GetTemplate(ctx context.Context, name string) (*models.Template, error) {
-	results, err := surrealdb.Query[[]models.Template](ctx, c.db, `
+	results, err := runQuery[[]models.Template](ctx, c, `
 		SELECT * FROM template WHERE name = $name LIMIT 1
 	`, map[string]any{"name": name})
 
@@ -765,7 +2120,7 @@ func (c *Client) GetTemplate(ctx context.Context, name string) (*models.Template
 
 // ListTemplates returns all templates.
 func (c *Client) ListTemplates(ctx context.Context) ([]models.Template, error) {
-	results, err := surrealdb.Query[[]models.Template](ctx, c.db, `
+	results, err := runQuery[[]models.Template](ctx, c, `
 		SELECT * FROM template ORDER BY name ASC
 	`, nil)
 
@@ -783,7 +2138,7 @@ func (c *Client) ListTemplates(ctx context.Context) ([]models.Template, error) {
 func (c *Client) DeleteTemplate(ctx context.Context, name string) (bool, error) {
 	sql := `DELETE template WHERE name = $name RETURN BEFORE`
 
-	results, err := surrealdb.Query[[]models.Template](ctx, c.db, sql, map[string]any{"name": name})
+	results, err := runQuery[[]models.Template](ctx, c, sql, map[string]any{"name": name})
 	if err != nil {
 		return false, fmt.Errorf("delete template: %w", err)
 	}
@@ -810,17 +2165,19 @@ func (c *Client) RecordTokenUsage(ctx context.Context, input models.TokenUsageIn
 			output_tokens = $output_tokens,
 			total_tokens = $total_tokens,
 			cost_usd = $cost_usd,
-			entity_id = $entity_id
+			entity_id = $entity_id,
+			conversation_id = $conversation_id
 	`
 
-	_, err := surrealdb.Query[any](ctx, c.db, sql, map[string]any{
-		"operation":     input.Operation,
-		"model":         input.Model,
-		"input_tokens":  input.InputTokens,
-		"output_tokens": input.OutputTokens,
-		"total_tokens":  total,
-		"cost_usd":      optionalFloat(input.CostUSD),
-		"entity_id":     optionalString(input.EntityID),
+	_, err := runQuery[any](ctx, c, sql, map[string]any{
+		"operation":       input.Operation,
+		"model":           input.Model,
+		"input_tokens":    input.InputTokens,
+		"output_tokens":   input.OutputTokens,
+		"total_tokens":    total,
+		"cost_usd":        optionalFloat(input.CostUSD),
+		"entity_id":       optionalString(input.EntityID),
+		"conversation_id": optionalString(input.ConversationID),
 	})
 	if err != nil {
 		return fmt.Errorf("record token usage: %w", err)
@@ -828,6 +2185,28 @@ func (c *Client) RecordTokenUsage(ctx context.Context, input models.TokenUsageIn
 	return nil
 }
 
+// GetConversationTokenUsage returns the total tokens used by LLM calls made
+// for a conversation.
+func (c *Client) GetConversationTokenUsage(ctx context.Context, conversationID string) (int, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[[]struct {
+		Total int `json:"total"`
+	}](ctx, c, `
+		SELECT math::sum(total_tokens) AS total FROM token_usage
+		WHERE conversation_id = $conversation_id
+		GROUP ALL
+	`, map[string]any{"conversation_id": conversationID})
+	if err != nil {
+		return 0, fmt.Errorf("get conversation token usage: %w", err)
+	}
+	if results == nil || len(*results) == 0 || len((*results)[0].Result) == 0 {
+		return 0, nil
+	}
+	return (*results)[0].Result[0].Total, nil
+}
+
 // GetTokenUsageSummary returns aggregated token usage statistics.
 // Uses separate simple queries instead of complex multi-statement query for better
 // concurrency behavior with the WebSocket connection.
@@ -842,7 +2221,7 @@ func (c *Client) GetTokenUsageSummary(ctx context.Context, since string) (*model
 		TotalTokens int      `json:"total_tokens"`
 		CostUSD     *float64 `json:"cost_usd"`
 	}
-	usageResults, err := surrealdb.Query[[]usageRow](ctx, c.db, usageSQL, vars)
+	usageResults, err := runQuery[[]usageRow](ctx, c, usageSQL, vars)
 	if err != nil {
 		return nil, fmt.Errorf("get token usage: %w", err)
 	}
@@ -872,7 +2251,7 @@ func (c *Client) GetTokenUsageSummary(ctx context.Context, since string) (*model
 		Operation string  `json:"operation"`
 		Tokens    float64 `json:"tokens"`
 	}
-	opResults, err := surrealdb.Query[[]opResult](ctx, c.db, byOpSQL, vars)
+	opResults, err := runQuery[[]opResult](ctx, c, byOpSQL, vars)
 	if err != nil {
 		return nil, fmt.Errorf("get tokens by operation: %w", err)
 	}
@@ -893,7 +2272,7 @@ func (c *Client) GetTokenUsageSummary(ctx context.Context, since string) (*model
 		Model  string  `json:"model"`
 		Tokens float64 `json:"tokens"`
 	}
-	modelResults, err := surrealdb.Query[[]modelResult](ctx, c.db, byModelSQL, vars)
+	modelResults, err := runQuery[[]modelResult](ctx, c, byModelSQL, vars)
 	if err != nil {
 		return nil, fmt.Errorf("get tokens by model: %w", err)
 	}
@@ -906,6 +2285,148 @@ func (c *Client) GetTokenUsageSummary(ctx context.Context, since string) (*model
 	return summary, nil
 }
 
+// =============================================================================
+// METRICS SNAPSHOT QUERIES
+// =============================================================================
+
+// MetricsSnapshotRecord is a persisted metrics.Snapshot with its capture time.
+type MetricsSnapshotRecord struct {
+	UptimeSeconds float64                    `json:"uptime_seconds"`
+	Embedding     *metrics.OperationSnapshot `json:"embedding,omitempty"`
+	LLMGenerate   *metrics.OperationSnapshot `json:"llm_generate,omitempty"`
+	LLMStream     *metrics.OperationSnapshot `json:"llm_stream,omitempty"`
+	DBQuery       *metrics.OperationSnapshot `json:"db_query,omitempty"`
+	DBSearch      *metrics.OperationSnapshot `json:"db_search,omitempty"`
+	CreatedAt     time.Time                  `json:"created_at"`
+}
+
+// optionalOperation returns models.None for nil snapshots, otherwise returns the snapshot.
+func optionalOperation(s *metrics.OperationSnapshot) any {
+	if s == nil {
+		return surrealmodels.None
+	}
+	return s
+}
+
+// SaveMetricsSnapshot persists a metrics snapshot for historical trend analysis.
+func (c *Client) SaveMetricsSnapshot(ctx context.Context, snap metrics.Snapshot) error {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	sql := `
+		CREATE metrics_snapshot SET
+			uptime_seconds = $uptime_seconds,
+			embedding = $embedding,
+			llm_generate = $llm_generate,
+			llm_stream = $llm_stream,
+			db_query = $db_query,
+			db_search = $db_search
+	`
+
+	_, err := runQuery[any](ctx, c, sql, map[string]any{
+		"uptime_seconds": snap.UptimeSeconds,
+		"embedding":      optionalOperation(snap.Embedding),
+		"llm_generate":   optionalOperation(snap.LLMGenerate),
+		"llm_stream":     optionalOperation(snap.LLMStream),
+		"db_query":       optionalOperation(snap.DBQuery),
+		"db_search":      optionalOperation(snap.DBSearch),
+	})
+	if err != nil {
+		return fmt.Errorf("save metrics snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetMetricsSnapshots returns persisted metrics snapshots since the given
+// datetime, oldest first.
+func (c *Client) GetMetricsSnapshots(ctx context.Context, since string) ([]MetricsSnapshotRecord, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[[]MetricsSnapshotRecord](ctx, c, `
+		SELECT * OMIT id FROM metrics_snapshot
+		WHERE created_at >= <datetime>$since
+		ORDER BY created_at ASC
+	`, map[string]any{"since": since})
+	if err != nil {
+		return nil, fmt.Errorf("get metrics snapshots: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return []MetricsSnapshotRecord{}, nil
+	}
+	return (*results)[0].Result, nil
+}
+
+// =============================================================================
+// ANSWER SOURCE QUERIES
+// =============================================================================
+
+// RecordAnswerSources records the entities retrieved for one ask.
+func (c *Client) RecordAnswerSources(ctx context.Context, answerID string, sources []models.AnswerSourceInput) error {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	if len(sources) == 0 {
+		return nil
+	}
+
+	rows := make([]map[string]any, len(sources))
+	for i, src := range sources {
+		rows[i] = map[string]any{
+			"answer_id": answerID,
+			"entity_id": src.EntityID,
+			"rank":      src.Rank,
+			"included":  src.Included,
+		}
+	}
+
+	if _, err := runQuery[any](ctx, c, `INSERT INTO answer_source $rows RETURN NONE`, map[string]any{"rows": rows}); err != nil {
+		return fmt.Errorf("record answer sources: %w", err)
+	}
+	return nil
+}
+
+// GetAnswerSourceStats ranks entities by how often they were included in the
+// context of asks since the given datetime, most first (ties by retrievals).
+func (c *Client) GetAnswerSourceStats(ctx context.Context, since string, limit int) ([]models.AnswerSourceStats, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[[]models.AnswerSourceStats](ctx, c, `
+		SELECT
+			entity_id,
+			count() AS retrieved,
+			count(included) AS included,
+			math::mean(rank) AS avg_rank
+		FROM answer_source
+		WHERE created_at >= <datetime>$since
+		GROUP BY entity_id
+	`, map[string]any{"since": since})
+	if err != nil {
+		return nil, fmt.Errorf("get answer source stats: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return []models.AnswerSourceStats{}, nil
+	}
+	stats := (*results)[0].Result
+
+	slices.SortFunc(stats, func(a, b models.AnswerSourceStats) int {
+		if c := cmp.Compare(b.Included, a.Included); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Retrieved, a.Retrieved); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.EntityID, b.EntityID)
+	})
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
 // =============================================================================
 // UTILITY QUERIES
 // =============================================================================
@@ -934,7 +2455,7 @@ func (c *Client) ListLabels(ctx context.Context) ([]LabelCount, error) {
 		}).sort(|$a, $b| IF $a.count > $b.count THEN -1 ELSE IF $a.count < $b.count THEN 1 ELSE 0 END)
 	`
 
-	results, err := surrealdb.Query[[]LabelCount](ctx, c.db, sql, nil)
+	results, err := runQuery[[]LabelCount](ctx, c, sql, nil)
 	if err != nil {
 		return nil, fmt.Errorf("list labels: %w", err)
 	}
@@ -942,8 +2463,143 @@ func (c *Client) ListLabels(ctx context.Context) ([]LabelCount, error) {
 	if results == nil || len(*results) == 0 {
 		return []LabelCount{}, nil
 	}
-	lastIdx := len(*results) - 1
-	return (*results)[lastIdx].Result, nil
+	lastIdx := len(*results) - 1
+	return (*results)[lastIdx].Result, nil
+}
+
+// ListEntityLabels returns the labels of every entity, keyed by entity ID.
+func (c *Client) ListEntityLabels(ctx context.Context) (map[string][]string, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[[]models.Entity](ctx, c, `SELECT id, labels FROM entity`, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list entity labels: %w", err)
+	}
+
+	labels := make(map[string][]string)
+	if results == nil || len(*results) == 0 {
+		return labels, nil
+	}
+	for _, e := range (*results)[0].Result {
+		id, err := models.RecordIDString(e.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list entity labels: %w", err)
+		}
+		labels[id] = e.Labels
+	}
+	return labels, nil
+}
+
+// ListEntityNames returns the name of every entity by ID.
+func (c *Client) ListEntityNames(ctx context.Context) (map[string]string, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[[]models.Entity](ctx, c, `SELECT id, name FROM entity`, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list entity names: %w", err)
+	}
+
+	names := make(map[string]string)
+	if results == nil || len(*results) == 0 {
+		return names, nil
+	}
+	for _, e := range (*results)[0].Result {
+		id, err := models.RecordIDString(e.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list entity names: %w", err)
+		}
+		names[id] = e.Name
+	}
+	return names, nil
+}
+
+// GetEntitiesBySourcePathPrefix returns the source_path of every entity with
+// the given source whose source_path starts with prefix, by entity ID.
+func (c *Client) GetEntitiesBySourcePathPrefix(ctx context.Context, prefix string, source models.EntitySource) (map[string]string, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	results, err := runQuery[[]models.Entity](ctx, c, `
+		SELECT id, source_path FROM entity
+		WHERE source = $source AND source_path != NONE
+			AND string::starts_with(source_path, $prefix)
+	`, map[string]any{"prefix": prefix, "source": source})
+	if err != nil {
+		return nil, fmt.Errorf("get entities by source path prefix: %w", err)
+	}
+
+	paths := make(map[string]string)
+	if results == nil || len(*results) == 0 {
+		return paths, nil
+	}
+	for _, e := range (*results)[0].Result {
+		id, err := models.RecordIDString(e.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get entities by source path prefix: %w", err)
+		}
+		if e.SourcePath != nil {
+			paths[id] = *e.SourcePath
+		}
+	}
+	return paths, nil
+}
+
+// SetEntityLabels replaces the labels of an entity and of its chunks, which
+// inherit them. Unlike UpdateEntity it leaves the access time alone.
+func (c *Client) SetEntityLabels(ctx context.Context, id string, labels []string) error {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	if labels == nil {
+		labels = []string{}
+	}
+	_, err := runQuery[any](ctx, c, `
+		UPDATE type::record("entity", $id) SET labels = $labels;
+		UPDATE chunk SET labels = $labels WHERE entity = type::record("entity", $id);
+	`, map[string]any{"id": id, "labels": labels})
+	if err != nil {
+		return fmt.Errorf("set entity labels: %w", err)
+	}
+	return nil
+}
+
+// RenameLabel replaces the label oldLabel with newLabel on all entities and
+// their chunks in one transaction. Entities that already have newLabel keep
+// it once. Returns the number of entities changed.
+func (c *Client) RenameLabel(ctx context.Context, oldLabel, newLabel string) (int, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	if oldLabel == "" || newLabel == "" {
+		return 0, fmt.Errorf("rename label: old and new label required")
+	}
+	if oldLabel == newLabel {
+		return 0, fmt.Errorf("rename label: old and new label are the same: %s", oldLabel)
+	}
+
+	results, err := runQuery[[]models.Entity](ctx, c, `
+		BEGIN TRANSACTION;
+		UPDATE chunk SET labels = array::union(array::difference(labels, [$old]), [$new])
+			WHERE labels CONTAINS $old RETURN NONE;
+		UPDATE entity SET labels = array::union(array::difference(labels, [$old]), [$new])
+			WHERE labels CONTAINS $old RETURN id;
+		COMMIT TRANSACTION;
+	`, map[string]any{"old": oldLabel, "new": newLabel})
+	if err != nil {
+		return 0, fmt.Errorf("rename label: %w", wrapQueryError(err))
+	}
+
+	if results == nil {
+		return 0, nil
+	}
+	// Only the entity update returns rows
+	renamed := 0
+	for _, r := range *results {
+		renamed += len(r.Result)
+	}
+	return renamed, nil
 }
 
 // ListTypes returns entity types with counts.
@@ -952,7 +2608,7 @@ func (c *Client) ListTypes(ctx context.Context) ([]TypeCount, error) {
 		SELECT type, count() AS count FROM entity GROUP BY type ORDER BY count DESC
 	`
 
-	results, err := surrealdb.Query[[]TypeCount](ctx, c.db, sql, nil)
+	results, err := runQuery[[]TypeCount](ctx, c, sql, nil)
 	if err != nil {
 		return nil, fmt.Errorf("list types: %w", err)
 	}
@@ -963,8 +2619,133 @@ func (c *Client) ListTypes(ctx context.Context) ([]TypeCount, error) {
 	return (*results)[0].Result, nil
 }
 
+// ConnectedEntity is an entity with its relation count.
+type ConnectedEntity struct {
+	ID     string
+	Name   string
+	Type   string
+	Degree int
+}
+
+// RelationTypeCount represents a relation type with its count.
+type RelationTypeCount struct {
+	RelType string `json:"rel_type"`
+	Count   int    `json:"count"`
+}
+
+// GraphAnalytics describes the overall shape of the knowledge graph.
+type GraphAnalytics struct {
+	Nodes         int                 // Entities
+	Edges         int                 // Relations
+	AverageDegree float64             // Relations per entity (each relation counts for both ends)
+	Orphans       int                 // Entities without any relation
+	TopConnected  []ConnectedEntity   // Entities with the most relations, most first
+	RelationTypes []RelationTypeCount // Relation counts by type, most first
+	ComputedAt    time.Time
+}
+
+// GraphAnalytics computes graph-wide statistics. topN limits TopConnected.
+// Every entity and relation is scanned, so callers should cache the result.
+func (c *Client) GraphAnalytics(ctx context.Context, topN int) (GraphAnalytics, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	analytics := GraphAnalytics{ComputedAt: time.Now()}
+
+	var err error
+	if analytics.Nodes, err = c.countRows(ctx, `SELECT count() AS count FROM entity GROUP ALL`, nil); err != nil {
+		return analytics, fmt.Errorf("count entities: %w", err)
+	}
+	if analytics.Edges, err = c.countRows(ctx, `SELECT count() AS count FROM relates_to GROUP ALL`, nil); err != nil {
+		return analytics, fmt.Errorf("count relations: %w", err)
+	}
+	if analytics.Orphans, err = c.countRows(ctx, `
+		SELECT count() AS count FROM entity
+		WHERE array::len(->relates_to) = 0 AND array::len(<-relates_to) = 0
+		GROUP ALL
+	`, nil); err != nil {
+		return analytics, fmt.Errorf("count orphans: %w", err)
+	}
+	if analytics.Nodes > 0 {
+		analytics.AverageDegree = float64(2*analytics.Edges) / float64(analytics.Nodes)
+	}
+
+	type degreeRow struct {
+		ID     surrealmodels.RecordID `json:"id"`
+		Name   string                 `json:"name"`
+		Type   string                 `json:"type"`
+		Degree int                    `json:"degree"`
+	}
+	degrees, err := runQuery[[]degreeRow](ctx, c, `
+		SELECT id, name, type, array::len(->relates_to) + array::len(<-relates_to) AS degree
+		FROM entity
+		ORDER BY degree DESC
+		LIMIT $limit
+	`, map[string]any{"limit": topN})
+	if err != nil {
+		return analytics, fmt.Errorf("top connected entities: %w", err)
+	}
+	analytics.TopConnected = []ConnectedEntity{}
+	if degrees != nil && len(*degrees) > 0 {
+		for _, row := range (*degrees)[0].Result {
+			if row.Degree == 0 {
+				break
+			}
+			id, err := models.RecordIDString(row.ID)
+			if err != nil {
+				return analytics, fmt.Errorf("top connected entities: %w", err)
+			}
+			analytics.TopConnected = append(analytics.TopConnected, ConnectedEntity{
+				ID:     id,
+				Name:   row.Name,
+				Type:   row.Type,
+				Degree: row.Degree,
+			})
+		}
+	}
+
+	relTypes, err := runQuery[[]RelationTypeCount](ctx, c, `
+		SELECT rel_type, count() AS count FROM relates_to GROUP BY rel_type ORDER BY count DESC
+	`, nil)
+	if err != nil {
+		return analytics, fmt.Errorf("relation types: %w", err)
+	}
+	analytics.RelationTypes = []RelationTypeCount{}
+	if relTypes != nil && len(*relTypes) > 0 {
+		analytics.RelationTypes = (*relTypes)[0].Result
+	}
+
+	return analytics, nil
+}
+
+// countRows runs a `SELECT count() AS count ... GROUP ALL` query.
+// An empty table yields 0.
+func (c *Client) countRows(ctx context.Context, sql string, vars map[string]any) (int, error) {
+	type countRow struct {
+		Count int `json:"count"`
+	}
+	results, err := runQuery[[]countRow](ctx, c, sql, vars)
+	if err != nil {
+		return 0, err
+	}
+	if results == nil || len(*results) == 0 || len((*results)[0].Result) == 0 {
+		return 0, nil
+	}
+	return (*results)[0].Result[0].Count, nil
+}
+
+// ListOptions configures entity listing.
+type ListOptions struct {
+	Type            string   // Filter by entity type
+	Labels          []string // Filter by labels (CONTAINSANY)
+	HasMetadataKeys []string // Only entities with these metadata keys set
+	Context         *string  // Only entities in this project context (nil = all)
+	Limit           int      // Max results (default 50)
+}
+
 // ListEntities returns entities with optional filtering.
-func (c *Client) ListEntities(ctx context.Context, entityType string, labels []string, limit int) ([]models.Entity, error) {
+func (c *Client) ListEntities(ctx context.Context, opts ListOptions) ([]models.Entity, error) {
+	limit := opts.Limit
 	if limit <= 0 {
 		limit = 50
 	}
@@ -972,13 +2753,17 @@ func (c *Client) ListEntities(ctx context.Context, entityType string, labels []s
 	filterClauses := []string{}
 	vars := map[string]any{"limit": limit}
 
-	if entityType != "" {
+	if opts.Type != "" {
 		filterClauses = append(filterClauses, "type = $type")
-		vars["type"] = entityType
+		vars["type"] = opts.Type
 	}
-	if len(labels) > 0 {
+	if len(opts.Labels) > 0 {
 		filterClauses = append(filterClauses, "labels CONTAINSANY $labels")
-		vars["labels"] = labels
+		vars["labels"] = opts.Labels
+	}
+	filterClauses = append(filterClauses, metadataKeyClauses(opts.HasMetadataKeys, vars)...)
+	if opts.Context != nil {
+		filterClauses = append(filterClauses, contextClause("context", *opts.Context, vars))
 	}
 
 	whereClause := ""
@@ -990,7 +2775,7 @@ func (c *Client) ListEntities(ctx context.Context, entityType string, labels []s
 		SELECT * FROM entity %s ORDER BY updated_at DESC LIMIT $limit
 	`, whereClause)
 
-	results, err := surrealdb.Query[[]models.Entity](ctx, c.db, sql, vars)
+	results, err := runQuery[[]models.Entity](ctx, c, sql, vars)
 	if err != nil {
 		return nil, fmt.Errorf("list entities: %w", err)
 	}
@@ -1001,24 +2786,310 @@ func (c *Client) ListEntities(ctx context.Context, entityType string, labels []s
 	return (*results)[0].Result, nil
 }
 
+// Review queue priorities.
+const (
+	ReviewByConfidence = "confidence" // Lowest confidence first
+	ReviewByAccess     = "access"     // Most accessed first
+)
+
+// ReviewQueueOptions controls GetReviewQueue.
+type ReviewQueueOptions struct {
+	Priority string   // ReviewByConfidence (default) or ReviewByAccess
+	Sources  []string // Only entities from these sources
+	Types    []string // Only entities of these types
+	Limit    int      // Max results (default 50)
+	Offset   int      // Entities to skip, for pagination
+}
+
+// GetReviewQueue returns unverified entities in review order.
+func (c *Client) GetReviewQueue(ctx context.Context, opts ReviewQueueOptions) ([]models.Entity, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var order string
+	switch opts.Priority {
+	case "", ReviewByConfidence:
+		order = "confidence ASC, access_count DESC"
+	case ReviewByAccess:
+		order = "access_count DESC, confidence ASC"
+	default:
+		return nil, fmt.Errorf("invalid review priority %q (want %q or %q)", opts.Priority, ReviewByConfidence, ReviewByAccess)
+	}
+
+	filterClauses := []string{"verified = false"}
+	vars := map[string]any{"limit": limit, "offset": max(opts.Offset, 0)}
+	if len(opts.Sources) > 0 {
+		filterClauses = append(filterClauses, "source INSIDE $sources")
+		vars["sources"] = opts.Sources
+	}
+	if len(opts.Types) > 0 {
+		filterClauses = append(filterClauses, "type INSIDE $types")
+		vars["types"] = opts.Types
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT * FROM entity WHERE %s ORDER BY %s, name ASC LIMIT $limit START $offset
+	`, strings.Join(filterClauses, " AND "), order)
+
+	results, err := runQuery[[]models.Entity](ctx, c, sql, vars)
+	if err != nil {
+		return nil, fmt.Errorf("get review queue: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return []models.Entity{}, nil
+	}
+	return (*results)[0].Result, nil
+}
+
+// SetVerified marks entities as verified (or unverified) and returns the
+// changed entities. IDs that don't exist are skipped.
+func (c *Client) SetVerified(ctx context.Context, ids []string, verified bool) ([]models.Entity, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	if len(ids) == 0 {
+		return []models.Entity{}, nil
+	}
+
+	results, err := runQuery[[]models.Entity](ctx, c, `
+		UPDATE $ids.map(|$id| type::record("entity", $id)) SET verified = $verified
+		WHERE verified != $verified
+		RETURN AFTER
+	`, map[string]any{"ids": ids, "verified": verified})
+	if err != nil {
+		return nil, fmt.Errorf("set verified: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return []models.Entity{}, nil
+	}
+	return (*results)[0].Result, nil
+}
+
+// EntityPage is one page of entities and the cursor for the next page.
+type EntityPage struct {
+	Entities   []models.Entity
+	NextCursor string // Empty when there are no more entities
+}
+
+// GetEntitiesModifiedSince returns entities whose content or metadata changed
+// after since (by modified_at, which access tracking doesn't bump), oldest
+// change first (ties ordered by ID), for incremental sync. cursor is the
+// NextCursor of the previous page, or "" for the first page. Embeddings are
+// omitted. Deleted entities are not reported since deletes are permanent.
+func (c *Client) GetEntitiesModifiedSince(ctx context.Context, since time.Time, limit int, cursor string) (EntityPage, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	if limit <= 0 {
+		limit = 100
+	}
+
+	vars := map[string]any{
+		"since": since.UTC().Format(time.RFC3339Nano),
+		"limit": limit + 1, // one extra to detect a next page
+	}
+	cursorClause := ""
+	if cursor != "" {
+		after, afterID, err := decodeEntityCursor(cursor)
+		if err != nil {
+			return EntityPage{}, err
+		}
+		cursorClause = `AND (modified_at > <datetime>$after
+			OR (modified_at = <datetime>$after AND id > type::record("entity", $after_id)))`
+		vars["after"] = after.UTC().Format(time.RFC3339Nano)
+		vars["after_id"] = afterID
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT * OMIT embedding FROM entity
+		WHERE modified_at > <datetime>$since %s
+		ORDER BY modified_at ASC, id ASC
+		LIMIT $limit
+	`, cursorClause)
+
+	results, err := runQuery[[]models.Entity](ctx, c, sql, vars)
+	if err != nil {
+		return EntityPage{}, fmt.Errorf("get entities modified since: %w", err)
+	}
+
+	page := EntityPage{Entities: []models.Entity{}}
+	if results == nil || len(*results) == 0 {
+		return page, nil
+	}
+	page.Entities = (*results)[0].Result
+	if len(page.Entities) > limit {
+		page.Entities = page.Entities[:limit]
+		last := page.Entities[limit-1]
+		id, err := models.RecordIDString(last.ID)
+		if err != nil {
+			return EntityPage{}, fmt.Errorf("get entity ID for cursor: %w", err)
+		}
+		page.NextCursor = encodeEntityCursor(last.ModifiedAt, id)
+	}
+	return page, nil
+}
+
+// encodeEntityCursor returns an opaque cursor for the position after an
+// entity in modified_at, ID order.
+func encodeEntityCursor(modifiedAt time.Time, id string) string {
+	raw := modifiedAt.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeEntityCursor reverses encodeEntityCursor.
+func decodeEntityCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor: %w", err)
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+	modifiedAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor: %w", err)
+	}
+	return modifiedAt, id, nil
+}
+
+// ListEntitiesWithoutSummary returns, in ID order, the IDs of up to limit
+// entities that have no summary and either at least minContentLength
+// characters of content or content kept only in chunks. With afterID set,
+// the listing continues after that entity. Content isn't loaded, so callers
+// page through IDs and fetch entities one at a time.
+func (c *Client) ListEntitiesWithoutSummary(ctx context.Context, minContentLength int, afterID string, limit int) ([]string, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	vars := map[string]any{"min_len": minContentLength, "limit": limit}
+	cursorClause := ""
+	if afterID != "" {
+		cursorClause = `AND id > type::record("entity", $after_id)`
+		vars["after_id"] = afterID
+	}
+
+	type idRow struct {
+		ID surrealmodels.RecordID `json:"id"`
+	}
+	results, err := runQuery[[]idRow](ctx, c, fmt.Sprintf(`
+		SELECT id FROM entity
+		WHERE summary = NONE
+			AND (string::len(content ?? "") >= $min_len
+				OR (content = NONE AND count(SELECT id FROM chunk WHERE entity = $parent.id LIMIT 1) > 0))
+			%s
+		ORDER BY id
+		LIMIT $limit
+	`, cursorClause), vars)
+	if err != nil {
+		return nil, fmt.Errorf("list entities without summary: %w", err)
+	}
+
+	ids := []string{}
+	if results == nil || len(*results) == 0 {
+		return ids, nil
+	}
+	for _, row := range (*results)[0].Result {
+		id, err := models.RecordIDString(row.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list entities without summary: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// CompactReport lists dangling data found (and removed unless dry-run) by Compact.
+type CompactReport struct {
+	OrphanedChunks    int             // Chunks whose parent entity no longer exists
+	DanglingRelations int             // Relations with a missing endpoint
+	EmptyEntities     []models.Entity // Entities with no content, summary, chunks or relations
+}
+
+// Compact finds chunks whose entity no longer exists, relations whose
+// endpoints no longer exist, and entities with neither content, summary,
+// chunks nor relations. Chunked entities keep their content only in chunks,
+// so they never count as empty. Unless dryRun is set, they are deleted.
+// Dangling relations are removed first, so entities left without relations
+// count as empty.
+func (c *Client) Compact(ctx context.Context, dryRun bool) (CompactReport, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	var report CompactReport
+
+	verb := "DELETE %s WHERE %s RETURN BEFORE"
+	if dryRun {
+		verb = "SELECT * FROM %s WHERE %s"
+	}
+
+	type idRow struct {
+		ID surrealmodels.RecordID `json:"id"`
+	}
+
+	relations, err := runQuery[[]idRow](ctx, c, fmt.Sprintf(verb, "relates_to",
+		"!record::exists(in) OR !record::exists(out)"), nil)
+	if err != nil {
+		return report, fmt.Errorf("compact relations: %w", err)
+	}
+	if relations != nil && len(*relations) > 0 {
+		report.DanglingRelations = len((*relations)[0].Result)
+	}
+
+	chunks, err := runQuery[[]idRow](ctx, c, fmt.Sprintf(verb, "chunk",
+		"!record::exists(entity)"), nil)
+	if err != nil {
+		return report, fmt.Errorf("compact chunks: %w", err)
+	}
+	if chunks != nil && len(*chunks) > 0 {
+		report.OrphanedChunks = len((*chunks)[0].Result)
+	}
+
+	entities, err := runQuery[[]models.Entity](ctx, c, fmt.Sprintf(verb, "entity", `
+		(content IS NONE OR string::trim(content) = "")
+		AND (summary IS NONE OR string::trim(summary) = "")
+		AND array::len(->relates_to) = 0 AND array::len(<-relates_to) = 0
+		AND count(SELECT id FROM chunk WHERE entity = $parent.id) = 0`), nil)
+	if err != nil {
+		return report, fmt.Errorf("compact entities: %w", err)
+	}
+	report.EmptyEntities = []models.Entity{}
+	if entities != nil && len(*entities) > 0 {
+		report.EmptyEntities = (*entities)[0].Result
+	}
+
+	return report, nil
+}
+
 // =============================================================================
 // INGEST JOB QUERIES
 // =============================================================================
 
 // CreateIngestJob creates a new ingest job record.
-func (c *Client) CreateIngestJob(ctx context.Context, id, name, dirPath string, files, labels []string, opts map[string]any) error {
+func (c *Client) CreateIngestJob(ctx context.Context, id, jobType, name, dirPath string, files, labels []string, opts map[string]any) error {
 	c.startOp() // Mark activity for heartbeat
 
 	// SurrealDB requires non-nil arrays for array<string> fields
 	if labels == nil {
 		labels = []string{}
 	}
+	if files == nil {
+		files = []string{}
+	}
 
 	// Build SQL dynamically to handle optional name field
 	// SurrealDB option<T> doesn't accept NULL, must omit field entirely
 	var sql string
 	params := map[string]any{
 		"id":       id,
+		"job_type": jobType,
 		"labels":   labels,
 		"dir_path": dirPath,
 		"files":    files,
@@ -1029,7 +3100,7 @@ func (c *Client) CreateIngestJob(ctx context.Context, id, name, dirPath string,
 	if name != "" {
 		sql = `
 			CREATE type::record("ingest_job", $id) SET
-				job_type = "ingest",
+				job_type = $job_type,
 				status = "pending",
 				name = $name,
 				labels = $labels,
@@ -1043,7 +3114,7 @@ func (c *Client) CreateIngestJob(ctx context.Context, id, name, dirPath string,
 	} else {
 		sql = `
 			CREATE type::record("ingest_job", $id) SET
-				job_type = "ingest",
+				job_type = $job_type,
 				status = "pending",
 				labels = $labels,
 				dir_path = $dir_path,
@@ -1054,7 +3125,7 @@ func (c *Client) CreateIngestJob(ctx context.Context, id, name, dirPath string,
 		`
 	}
 
-	_, err := surrealdb.Query[any](ctx, c.db, sql, params)
+	_, err := runQuery[any](ctx, c, sql, params)
 	if err != nil {
 		return fmt.Errorf("create ingest job: %w", err)
 	}
@@ -1063,7 +3134,7 @@ func (c *Client) CreateIngestJob(ctx context.Context, id, name, dirPath string,
 
 // GetIngestJob retrieves an ingest job by ID.
 func (c *Client) GetIngestJob(ctx context.Context, id string) (*models.IngestJob, error) {
-	results, err := surrealdb.Query[[]models.IngestJob](ctx, c.db, `
+	results, err := runQuery[[]models.IngestJob](ctx, c, `
 		SELECT * FROM type::record("ingest_job", $id)
 	`, map[string]any{"id": id})
 
@@ -1079,7 +3150,7 @@ func (c *Client) GetIngestJob(ctx context.Context, id string) (*models.IngestJob
 
 // GetJobByName retrieves the most recent job with the given name.
 func (c *Client) GetJobByName(ctx context.Context, name string) (*models.IngestJob, error) {
-	results, err := surrealdb.Query[[]models.IngestJob](ctx, c.db, `
+	results, err := runQuery[[]models.IngestJob](ctx, c, `
 		SELECT * FROM ingest_job WHERE name = $name ORDER BY started_at DESC LIMIT 1
 	`, map[string]any{"name": name})
 
@@ -1093,9 +3164,33 @@ func (c *Client) GetJobByName(ctx context.Context, name string) (*models.IngestJ
 	return &(*results)[0].Result[0], nil
 }
 
+// ListIngestJobs returns persisted jobs, most recent first, optionally
+// filtered by status. The file list is omitted to keep results small.
+func (c *Client) ListIngestJobs(ctx context.Context, status *string, limit, offset int) ([]models.IngestJob, error) {
+	where := ""
+	vars := map[string]any{"limit": limit, "offset": offset}
+	if status != nil {
+		where = "WHERE status = $status"
+		vars["status"] = *status
+	}
+
+	results, err := runQuery[[]models.IngestJob](ctx, c, fmt.Sprintf(`
+		SELECT * OMIT files FROM ingest_job %s ORDER BY started_at DESC LIMIT $limit START $offset
+	`, where), vars)
+
+	if err != nil {
+		return nil, fmt.Errorf("list ingest jobs: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return []models.IngestJob{}, nil
+	}
+	return (*results)[0].Result, nil
+}
+
 // GetIncompleteJobs returns all pending or running jobs.
 func (c *Client) GetIncompleteJobs(ctx context.Context) ([]models.IngestJob, error) {
-	results, err := surrealdb.Query[[]models.IngestJob](ctx, c.db, `
+	results, err := runQuery[[]models.IngestJob](ctx, c, `
 		SELECT * FROM ingest_job WHERE status IN ["pending", "running"] ORDER BY started_at ASC
 	`, nil)
 
@@ -1112,7 +3207,7 @@ func (c *Client) GetIncompleteJobs(ctx context.Context) ([]models.IngestJob, err
 // UpdateJobStatus updates the status of a job.
 func (c *Client) UpdateJobStatus(ctx context.Context, id, status string) error {
 	c.startOp() // Mark activity for heartbeat
-	_, err := surrealdb.Query[any](ctx, c.db, `
+	_, err := runQuery[any](ctx, c, `
 		UPDATE type::record("ingest_job", $id) SET status = $status
 	`, map[string]any{"id": id, "status": status})
 	if err != nil {
@@ -1124,7 +3219,7 @@ func (c *Client) UpdateJobStatus(ctx context.Context, id, status string) error {
 // UpdateJobProgress updates the progress of a job.
 func (c *Client) UpdateJobProgress(ctx context.Context, id string, progress int) error {
 	c.startOp() // Mark activity for heartbeat
-	_, err := surrealdb.Query[any](ctx, c.db, `
+	_, err := runQuery[any](ctx, c, `
 		UPDATE type::record("ingest_job", $id) SET progress = $progress
 	`, map[string]any{"id": id, "progress": progress})
 	if err != nil {
@@ -1136,7 +3231,7 @@ func (c *Client) UpdateJobProgress(ctx context.Context, id string, progress int)
 // CompleteJob marks a job as completed with result.
 func (c *Client) CompleteJob(ctx context.Context, id string, result map[string]any) error {
 	c.startOp() // Mark activity for heartbeat
-	_, err := surrealdb.Query[any](ctx, c.db, `
+	_, err := runQuery[any](ctx, c, `
 		UPDATE type::record("ingest_job", $id) SET
 			status = "completed",
 			result = $result,
@@ -1148,10 +3243,26 @@ func (c *Client) CompleteJob(ctx context.Context, id string, result map[string]a
 	return nil
 }
 
+// SetJobResult stores the result and final progress of a job without
+// changing its status, e.g. for a cancelled job that stopped.
+func (c *Client) SetJobResult(ctx context.Context, id string, progress int, result map[string]any) error {
+	c.startOp() // Mark activity for heartbeat
+	_, err := runQuery[any](ctx, c, `
+		UPDATE type::record("ingest_job", $id) SET
+			progress = $progress,
+			result = $result,
+			completed_at = time::now()
+	`, map[string]any{"id": id, "progress": progress, "result": result})
+	if err != nil {
+		return fmt.Errorf("set job result: %w", err)
+	}
+	return nil
+}
+
 // FailJob marks a job as failed with error message.
 func (c *Client) FailJob(ctx context.Context, id string, errMsg string) error {
 	c.startOp() // Mark activity for heartbeat
-	_, err := surrealdb.Query[any](ctx, c.db, `
+	_, err := runQuery[any](ctx, c, `
 		UPDATE type::record("ingest_job", $id) SET
 			status = "failed",
 			error = $error,
@@ -1169,9 +3280,9 @@ func (c *Client) GetEntitiesBySourcePaths(ctx context.Context, paths []string) (
 		return []string{}, nil
 	}
 
-	results, err := surrealdb.Query[[]struct {
+	results, err := runQuery[[]struct {
 		SourcePath *string `json:"source_path"`
-	}](ctx, c.db, `
+	}](ctx, c, `
 		SELECT source_path FROM entity WHERE source_path IN $paths
 	`, map[string]any{"paths": paths})
 
@@ -1208,7 +3319,7 @@ func (c *Client) CreateConversation(ctx context.Context, title string, entityID
 		RETURN AFTER
 	`
 
-	results, err := surrealdb.Query[[]models.Conversation](ctx, c.db, sql, map[string]any{
+	results, err := runQuery[[]models.Conversation](ctx, c, sql, map[string]any{
 		"title":     title,
 		"entity_id": optionalString(entityID),
 	})
@@ -1228,7 +3339,7 @@ func (c *Client) GetConversation(ctx context.Context, id string) (*models.Conver
 	start := c.startOp()
 	defer c.recordTiming(metrics.OpDBQuery, start)
 
-	results, err := surrealdb.Query[[]models.Conversation](ctx, c.db, `
+	results, err := runQuery[[]models.Conversation](ctx, c, `
 		SELECT * FROM type::record("conversation", $id)
 	`, map[string]any{"id": id})
 	if err != nil {
@@ -1241,18 +3352,67 @@ func (c *Client) GetConversation(ctx context.Context, id string) (*models.Conver
 	return &(*results)[0].Result[0], nil
 }
 
-// ListConversations returns conversations ordered by most recently updated.
-func (c *Client) ListConversations(ctx context.Context, limit int) ([]models.Conversation, error) {
+// Conversation sort orders for ListConversations.
+const (
+	ConversationSortUpdated = "updated" // Most recently updated first
+	ConversationSortCreated = "created" // Most recently created first
+	ConversationSortTitle   = "title"   // Alphabetical by title
+)
+
+// ConversationListOptions configures conversation listing.
+type ConversationListOptions struct {
+	CreatedAfter  *time.Time // Only conversations created at or after this time
+	CreatedBefore *time.Time // Only conversations created before this time
+	Sort          string     // ConversationSort* (default updated; unknown keys sort by update too)
+	Limit         int        // Max results (default 50)
+	Offset        int        // Conversations to skip, for pagination
+}
+
+// conversationWhereClause builds the WHERE clause of the creation date filters
+// and registers their parameters in vars.
+func conversationWhereClause(opts ConversationListOptions, vars map[string]any) string {
+	filterClauses := []string{}
+	if opts.CreatedAfter != nil {
+		filterClauses = append(filterClauses, "created_at >= $created_after")
+		vars["created_after"] = *opts.CreatedAfter
+	}
+	if opts.CreatedBefore != nil {
+		filterClauses = append(filterClauses, "created_at < $created_before")
+		vars["created_before"] = *opts.CreatedBefore
+	}
+	if len(filterClauses) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(filterClauses, " AND ")
+}
+
+// ListConversations returns conversations, most recently updated first
+// unless opts.Sort says otherwise.
+func (c *Client) ListConversations(ctx context.Context, opts ConversationListOptions) ([]models.Conversation, error) {
 	start := c.startOp()
 	defer c.recordTiming(metrics.OpDBQuery, start)
 
+	limit := opts.Limit
 	if limit <= 0 {
 		limit = 50
 	}
 
-	results, err := surrealdb.Query[[]models.Conversation](ctx, c.db, `
-		SELECT * FROM conversation ORDER BY updated_at DESC LIMIT $limit
-	`, map[string]any{"limit": limit})
+	var order string
+	switch opts.Sort {
+	case ConversationSortCreated:
+		order = "created_at DESC"
+	case ConversationSortTitle:
+		order = "title ASC, updated_at DESC"
+	default:
+		order = "updated_at DESC"
+	}
+
+	vars := map[string]any{"limit": limit, "offset": max(opts.Offset, 0)}
+	sql := fmt.Sprintf(`
+		SELECT * FROM conversation %s ORDER BY %s LIMIT $limit START $offset
+	`, conversationWhereClause(opts, vars), order)
+
+	results, err := runQuery[[]models.Conversation](ctx, c, sql, vars)
 	if err != nil {
 		return nil, fmt.Errorf("list conversations: %w", err)
 	}
@@ -1263,13 +3423,31 @@ func (c *Client) ListConversations(ctx context.Context, limit int) ([]models.Con
 	return (*results)[0].Result, nil
 }
 
+// CountConversations returns the number of conversations matching the date
+// filters of opts, ignoring its sort and pagination.
+func (c *Client) CountConversations(ctx context.Context, opts ConversationListOptions) (int, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	vars := map[string]any{}
+	sql := fmt.Sprintf(`
+		SELECT count() AS count FROM conversation %s GROUP ALL
+	`, conversationWhereClause(opts, vars))
+
+	count, err := c.countRows(ctx, sql, vars)
+	if err != nil {
+		return 0, fmt.Errorf("count conversations: %w", err)
+	}
+	return count, nil
+}
+
 // DeleteConversation deletes a conversation by ID.
 // Messages are cascade-deleted by the SurrealDB event.
 func (c *Client) DeleteConversation(ctx context.Context, id string) (bool, error) {
 	start := c.startOp()
 	defer c.recordTiming(metrics.OpDBQuery, start)
 
-	results, err := surrealdb.Query[[]models.Conversation](ctx, c.db, `
+	results, err := runQuery[[]models.Conversation](ctx, c, `
 		DELETE type::record("conversation", $id) RETURN BEFORE
 	`, map[string]any{"id": id})
 	if err != nil {
@@ -1283,7 +3461,8 @@ func (c *Client) DeleteConversation(ctx context.Context, id string) (bool, error
 }
 
 // CreateMessage creates a new message in a conversation and touches the conversation's updated_at.
-func (c *Client) CreateMessage(ctx context.Context, conversationID, role, content string) (*models.Message, error) {
+// A nil embedding stores the message without one, leaving it out of SearchMessages.
+func (c *Client) CreateMessage(ctx context.Context, conversationID, role, content string, embedding []float32) (*models.Message, error) {
 	start := c.startOp()
 	defer c.recordTiming(metrics.OpDBQuery, start)
 
@@ -1291,16 +3470,18 @@ func (c *Client) CreateMessage(ctx context.Context, conversationID, role, conten
 		LET $msg = CREATE message SET
 			conversation = type::record("conversation", $conv_id),
 			role = $role,
-			content = $content
+			content = $content,
+			embedding = $embedding
 		RETURN AFTER;
 		UPDATE type::record("conversation", $conv_id) SET updated_at = time::now();
 		RETURN $msg;
 	`
 
-	results, err := surrealdb.Query[[]models.Message](ctx, c.db, sql, map[string]any{
-		"conv_id": conversationID,
-		"role":    role,
-		"content": content,
+	results, err := runQuery[[]models.Message](ctx, c, sql, map[string]any{
+		"conv_id":   conversationID,
+		"role":      role,
+		"content":   content,
+		"embedding": optionalEmbedding(embedding),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create message: %w", err)
@@ -1324,8 +3505,8 @@ func (c *Client) GetMessages(ctx context.Context, conversationID string) ([]mode
 	start := c.startOp()
 	defer c.recordTiming(metrics.OpDBQuery, start)
 
-	results, err := surrealdb.Query[[]models.Message](ctx, c.db, `
-		SELECT * FROM message
+	results, err := runQuery[[]models.Message](ctx, c, `
+		SELECT * OMIT embedding FROM message
 		WHERE conversation = type::record("conversation", $conv_id)
 		ORDER BY created_at ASC
 	`, map[string]any{"conv_id": conversationID})
@@ -1339,7 +3520,126 @@ func (c *Client) GetMessages(ctx context.Context, conversationID string) ([]mode
 	return (*results)[0].Result, nil
 }
 
+// SearchMessages returns the messages closest to the query embedding, nearest
+// first, with the title of their conversation; messages without an embedding
+// (empty content, or stored without an embedder) don't match. Without a query
+// embedding, messages containing query (case-insensitive) are returned, newest
+// first, whether they have an embedding or not.
+func (c *Client) SearchMessages(ctx context.Context, query string, emb []float32, limit int) ([]models.MessageSearchResult, error) {
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBSearch, start)
+
+	if limit <= 0 {
+		limit = 10
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT *, conversation.title AS conversation_title, vector::distance::knn() AS distance
+		OMIT embedding
+		FROM message
+		WHERE embedding <|%d,60|> $emb
+		ORDER BY distance ASC
+	`, limit)
+	if len(emb) == 0 {
+		sql = `
+			SELECT *, conversation.title AS conversation_title
+			OMIT embedding
+			FROM message
+			WHERE string::contains(string::lowercase(content), string::lowercase($q))
+			ORDER BY created_at DESC
+			LIMIT $limit
+		`
+	}
+
+	results, err := runQuery[[]models.MessageSearchResult](ctx, c, sql, map[string]any{
+		"q":     query,
+		"emb":   emb,
+		"limit": limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search messages: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return []models.MessageSearchResult{}, nil
+	}
+	return (*results)[0].Result, nil
+}
+
 // slugify delegates to the shared models.Slugify function.
 func slugify(name string) string {
 	return models.Slugify(name)
 }
+
+// Decay curves for ApplyDecay.
+const (
+	DecayExponential = "exponential" // Weight halves every half-life
+	DecayLinear      = "linear"      // Weight falls linearly, reaching 0.5 at one half-life
+)
+
+// DecayConfig controls how the decay weight of an entity falls with time
+// since last access.
+type DecayConfig struct {
+	Curve            string             // DecayExponential (default) or DecayLinear
+	HalfLifeDays     float64            // Half-life for types not in TypeHalfLifeDays (<= 0 disables decay)
+	TypeHalfLifeDays map[string]float64 // Per-type half-life overrides
+	MinWeight        float64            // Floor for the decay weight
+
+	// Unverified, unpinned AI-generated entities lose confidence with age:
+	// starting from AIConfidence, it halves every AIConfidenceHalfLifeDays
+	// (<= 0 disables) down to MinConfidence. Confidence is only ever lowered.
+	AIConfidence             float64
+	AIConfidenceHalfLifeDays float64
+	MinConfidence            float64
+}
+
+// HalfLife returns the effective half-life in days for an entity type.
+func (c DecayConfig) HalfLife(entityType string) float64 {
+	if days, ok := c.TypeHalfLifeDays[entityType]; ok {
+		return days
+	}
+	return c.HalfLifeDays
+}
+
+// Weight returns the decay weight of an entity of entityType last accessed
+// at accessed: 1.0 when fresh or pinned, falling with age along the curve
+// to MinWeight. Computed on demand rather than stored, so decay never
+// rewrites entities.
+func (c DecayConfig) Weight(entityType string, accessed time.Time, pinned bool, now time.Time) float64 {
+	halfLife := c.HalfLife(entityType)
+	if pinned || halfLife <= 0 {
+		return 1.0
+	}
+	ageDays := max(now.Sub(accessed).Hours()/24, 0)
+	if c.Curve == DecayLinear {
+		return max(c.MinWeight, 1-ageDays/(2*halfLife))
+	}
+	return max(c.MinWeight, math.Pow(0.5, ageDays/halfLife))
+}
+
+// ApplyDecay lowers the confidence of unverified, unpinned AI-generated
+// entities with age (see DecayConfig.AIConfidenceHalfLifeDays). Decay weights aren't
+// stored, so there is nothing else to update; see DecayConfig.Weight.
+func (c *Client) ApplyDecay(ctx context.Context, cfg DecayConfig) error {
+	if cfg.AIConfidenceHalfLifeDays <= 0 {
+		return nil
+	}
+
+	start := c.startOp()
+	defer c.recordTiming(metrics.OpDBQuery, start)
+
+	_, err := runQuery[any](ctx, c, `
+		UPDATE entity SET confidence = math::max([$min_confidence, math::min([confidence,
+			$ai_confidence * math::pow(0.5, (duration::secs(time::now() - created_at) / 86400.0) / $ai_half)])])
+		WHERE source = $ai_source AND verified = false AND pinned != true AND confidence > $min_confidence RETURN NONE;
+	`, map[string]any{
+		"ai_confidence":  cfg.AIConfidence,
+		"ai_half":        cfg.AIConfidenceHalfLifeDays,
+		"min_confidence": cfg.MinConfidence,
+		"ai_source":      models.SourceAIGenerated,
+	})
+	if err != nil {
+		return fmt.Errorf("apply decay: %w", err)
+	}
+	return nil
+}
@@ -1,11 +1,34 @@
 package db
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+)
+
+// HNSW distance metrics for the embedding vector indexes.
+const (
+	DistanceCosine    = "COSINE"
+	DistanceEuclidean = "EUCLIDEAN"
+	DistanceManhattan = "MANHATTAN"
+)
+
+// Distances lists the supported HNSW distance metrics.
+var Distances = []string{DistanceCosine, DistanceEuclidean, DistanceManhattan}
+
+// ValidateDistance returns an error if distance is not a supported HNSW metric.
+func ValidateDistance(distance string) error {
+	if !slices.Contains(Distances, distance) {
+		return fmt.Errorf("invalid HNSW distance %q (want one of %v)", distance, Distances)
+	}
+	return nil
+}
 
 // SchemaSQL returns the database schema initialization SQL for Knowhow.
 // Personal knowledge RAG database with flexible entity model.
-// The dimension parameter configures HNSW vector index dimensions.
-func SchemaSQL(dimension int) string {
+// The dimension and distance parameters configure the HNSW vector indexes.
+// Indexes are only created if missing, so changing either requires removing
+// the existing indexes and reindexing.
+func SchemaSQL(dimension int, distance string) string {
 	return fmt.Sprintf(`
     -- ==========================================================================
     -- ENTITY TABLE (Core - Flexible Knowledge Atom)
@@ -24,6 +47,7 @@ func SchemaSQL(dimension int) string {
 
     -- Organization
     DEFINE FIELD IF NOT EXISTS labels ON entity TYPE array<string> DEFAULT [];  -- Flexible tags ["work", "banking", "team-platform"]
+    DEFINE FIELD IF NOT EXISTS aliases ON entity TYPE option<array<string>>;    -- Lowercased alternative names ["k8s"], unique across entities
 
     -- Quality & Trust
     DEFINE FIELD IF NOT EXISTS verified ON entity TYPE bool DEFAULT false;      -- Human-reviewed?
@@ -41,19 +65,28 @@ func SchemaSQL(dimension int) string {
     -- Timestamps
     DEFINE FIELD IF NOT EXISTS created_at ON entity TYPE datetime DEFAULT time::now();
     DEFINE FIELD IF NOT EXISTS updated_at ON entity TYPE datetime VALUE time::now();
+    DEFINE FIELD IF NOT EXISTS modified_at ON entity TYPE datetime DEFAULT time::now(); -- Set by track_modified
     DEFINE FIELD IF NOT EXISTS accessed ON entity TYPE datetime DEFAULT time::now();
     DEFINE FIELD IF NOT EXISTS access_count ON entity TYPE int DEFAULT 0;
+    DEFINE FIELD IF NOT EXISTS always_in_context ON entity TYPE bool DEFAULT false; -- Included in every ask context
+    DEFINE FIELD IF NOT EXISTS pinned ON entity TYPE bool DEFAULT false;   -- Exempt from decay
+    DEFINE FIELD IF NOT EXISTS language ON entity TYPE option<string>;  -- ISO 639-1 code detected from content
+    DEFINE FIELD IF NOT EXISTS context ON entity TYPE option<string>;   -- Project namespace; NONE = global
 
     -- Indexes
     DEFINE INDEX IF NOT EXISTS idx_entity_type ON entity FIELDS type;
     DEFINE INDEX IF NOT EXISTS idx_entity_labels ON entity FIELDS labels;
+    DEFINE INDEX IF NOT EXISTS idx_entity_aliases ON entity FIELDS aliases;
     DEFINE INDEX IF NOT EXISTS idx_entity_verified ON entity FIELDS verified;
     DEFINE INDEX IF NOT EXISTS idx_entity_source ON entity FIELDS source;
+    DEFINE INDEX IF NOT EXISTS idx_entity_always_in_context ON entity FIELDS always_in_context;
+    DEFINE INDEX IF NOT EXISTS idx_entity_language ON entity FIELDS language;
+    DEFINE INDEX IF NOT EXISTS idx_entity_context ON entity FIELDS context;
     DEFINE ANALYZER IF NOT EXISTS entity_analyzer TOKENIZERS class FILTERS lowercase, ascii, snowball(english);
     DEFINE INDEX IF NOT EXISTS idx_entity_content_ft ON entity FIELDS content FULLTEXT ANALYZER entity_analyzer BM25;
     DEFINE INDEX IF NOT EXISTS idx_entity_name_ft ON entity FIELDS name FULLTEXT ANALYZER entity_analyzer BM25;
     DEFINE INDEX IF NOT EXISTS idx_entity_embedding ON entity FIELDS embedding
-        HNSW DIMENSION %d DIST COSINE TYPE F32 EFC 150 M 12;
+        HNSW DIMENSION %[1]d DIST %[2]s TYPE F32 EFC 150 M 12;
 
     -- ==========================================================================
     -- CHUNK TABLE (RAG Pieces for Long Content)
@@ -75,7 +108,23 @@ func SchemaSQL(dimension int) string {
     DEFINE ANALYZER IF NOT EXISTS chunk_analyzer TOKENIZERS class FILTERS lowercase, ascii, snowball(english);
     DEFINE INDEX IF NOT EXISTS idx_chunk_content_ft ON chunk FIELDS content FULLTEXT ANALYZER chunk_analyzer BM25;
     DEFINE INDEX IF NOT EXISTS idx_chunk_embedding ON chunk FIELDS embedding
-        HNSW DIMENSION %d DIST COSINE TYPE F32 EFC 150 M 12;
+        HNSW DIMENSION %[1]d DIST %[2]s TYPE F32 EFC 150 M 12;
+
+    -- Content and metadata changes set modified_at; access tracking and
+    -- derived fields (embedding, timestamps) don't
+    DEFINE EVENT IF NOT EXISTS track_modified ON entity
+    WHEN $event = "UPDATE" AND $before.modified_at = $after.modified_at AND (
+        $before.type != $after.type OR $before.name != $after.name
+        OR $before.content != $after.content OR $before.summary != $after.summary
+        OR $before.labels != $after.labels OR $before.aliases != $after.aliases
+        OR $before.verified != $after.verified OR $before.confidence != $after.confidence
+        OR $before.source != $after.source OR $before.source_path != $after.source_path
+        OR $before.content_hash != $after.content_hash OR $before.metadata != $after.metadata
+        OR $before.always_in_context != $after.always_in_context OR $before.pinned != $after.pinned
+        OR $before.language != $after.language OR $before.context != $after.context
+    ) THEN {
+        UPDATE $after.id SET modified_at = time::now()
+    };
 
     -- Cascade delete when parent entity deleted
     DEFINE EVENT IF NOT EXISTS cascade_delete_chunks ON entity
@@ -146,10 +195,44 @@ func SchemaSQL(dimension int) string {
     DEFINE FIELD IF NOT EXISTS total_tokens ON token_usage TYPE int;
     DEFINE FIELD IF NOT EXISTS cost_usd ON token_usage TYPE option<float>; -- Estimated cost (if known)
     DEFINE FIELD IF NOT EXISTS entity_id ON token_usage TYPE option<string>; -- Related entity if applicable
+    DEFINE FIELD IF NOT EXISTS conversation_id ON token_usage TYPE option<string>; -- Conversation the call was made for (budgets)
     DEFINE FIELD IF NOT EXISTS created_at ON token_usage TYPE datetime DEFAULT time::now();
 
     DEFINE INDEX IF NOT EXISTS idx_usage_operation ON token_usage FIELDS operation;
     DEFINE INDEX IF NOT EXISTS idx_usage_created ON token_usage FIELDS created_at;
+    DEFINE INDEX IF NOT EXISTS idx_usage_conversation ON token_usage FIELDS conversation_id;
+
+    -- ==========================================================================
+    -- ANSWER_SOURCE TABLE (Ask Analytics)
+    -- ==========================================================================
+    -- One row per entity retrieved for an ask, to rank knowledge by how often
+    -- it informs answers.
+    DEFINE TABLE IF NOT EXISTS answer_source SCHEMAFULL;
+
+    DEFINE FIELD IF NOT EXISTS answer_id ON answer_source TYPE string;     -- Groups the sources of one ask
+    DEFINE FIELD IF NOT EXISTS entity_id ON answer_source TYPE string;
+    DEFINE FIELD IF NOT EXISTS rank ON answer_source TYPE int;             -- 1-based position in the answer context
+    DEFINE FIELD IF NOT EXISTS included ON answer_source TYPE bool;        -- Contributed text to the context
+    DEFINE FIELD IF NOT EXISTS created_at ON answer_source TYPE datetime DEFAULT time::now();
+
+    DEFINE INDEX IF NOT EXISTS idx_answer_source_answer ON answer_source FIELDS answer_id;
+    DEFINE INDEX IF NOT EXISTS idx_answer_source_created ON answer_source FIELDS created_at;
+
+    -- ==========================================================================
+    -- METRICS_SNAPSHOT TABLE (Historical Runtime Stats)
+    -- ==========================================================================
+    -- Periodic snapshots of in-memory metrics so trends survive restarts.
+    DEFINE TABLE IF NOT EXISTS metrics_snapshot SCHEMAFULL;
+
+    DEFINE FIELD IF NOT EXISTS uptime_seconds ON metrics_snapshot TYPE float;
+    DEFINE FIELD IF NOT EXISTS embedding ON metrics_snapshot TYPE option<object> FLEXIBLE;
+    DEFINE FIELD IF NOT EXISTS llm_generate ON metrics_snapshot TYPE option<object> FLEXIBLE;
+    DEFINE FIELD IF NOT EXISTS llm_stream ON metrics_snapshot TYPE option<object> FLEXIBLE;
+    DEFINE FIELD IF NOT EXISTS db_query ON metrics_snapshot TYPE option<object> FLEXIBLE;
+    DEFINE FIELD IF NOT EXISTS db_search ON metrics_snapshot TYPE option<object> FLEXIBLE;
+    DEFINE FIELD IF NOT EXISTS created_at ON metrics_snapshot TYPE datetime DEFAULT time::now();
+
+    DEFINE INDEX IF NOT EXISTS idx_metrics_snapshot_created ON metrics_snapshot FIELDS created_at;
 
     -- ==========================================================================
     -- INGEST_JOB TABLE (Async Job Persistence)
@@ -197,14 +280,17 @@ func SchemaSQL(dimension int) string {
     DEFINE FIELD IF NOT EXISTS conversation ON message TYPE record<conversation>;
     DEFINE FIELD IF NOT EXISTS role ON message TYPE string;
     DEFINE FIELD IF NOT EXISTS content ON message TYPE string;
+    DEFINE FIELD IF NOT EXISTS embedding ON message TYPE option<array<float>>;  -- NONE for empty content
     DEFINE FIELD IF NOT EXISTS created_at ON message TYPE datetime DEFAULT time::now();
 
     DEFINE INDEX IF NOT EXISTS idx_message_conversation ON message FIELDS conversation;
+    DEFINE INDEX IF NOT EXISTS idx_message_embedding ON message FIELDS embedding
+        HNSW DIMENSION %[1]d DIST %[2]s TYPE F32 EFC 150 M 12;
 
     -- Cascade delete messages when conversation deleted
     DEFINE EVENT IF NOT EXISTS cascade_delete_messages ON conversation
     WHEN $event = "DELETE" THEN {
         DELETE FROM message WHERE conversation = $before.id
     };
-`, dimension, dimension)
+`, dimension, distance)
 }
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSchemaSQLDistance(t *testing.T) {
+	for _, distance := range Distances {
+		sql := SchemaSQL(384, distance)
+		if got := strings.Count(sql, "HNSW DIMENSION 384 DIST "+distance+" "); got != 2 {
+			t.Errorf("SchemaSQL(384, %s): expected 2 HNSW indexes with distance, got %d", distance, got)
+		}
+	}
+}
+
+func TestValidateMigrations(t *testing.T) {
+	tests := []struct {
+		name       string
+		migrations []Migration
+		wantErr    bool
+	}{
+		{"none", nil, false},
+		{"ascending", []Migration{{Version: 2}, {Version: 5}}, false},
+		{"initial schema version", []Migration{{Version: 1}}, true},
+		{"duplicate", []Migration{{Version: 2}, {Version: 2}}, true},
+		{"out of order", []Migration{{Version: 3}, {Version: 2}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMigrations(tt.migrations)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDistance(t *testing.T) {
+	if err := ValidateDistance(DistanceEuclidean); err != nil {
+		t.Errorf("ValidateDistance(EUCLIDEAN) error = %v", err)
+	}
+	if err := ValidateDistance("DOT"); err == nil {
+		t.Error("expected error for unsupported distance")
+	}
+}
@@ -2,13 +2,71 @@
 package graph
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/raphaelgruber/memcp-go/internal/db"
 	"github.com/raphaelgruber/memcp-go/internal/metrics"
 	"github.com/raphaelgruber/memcp-go/internal/models"
+	"github.com/raphaelgruber/memcp-go/internal/parser"
 	"github.com/raphaelgruber/memcp-go/internal/service"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
+// askError reports an exhausted conversation token budget as a GraphQL error
+// with code BUDGET_EXCEEDED, so clients can tell it from a failed answer.
+// Other errors are returned as is.
+func askError(err error) error {
+	if errors.Is(err, service.ErrBudgetExceeded) {
+		return &gqlerror.Error{
+			Message:    err.Error(),
+			Extensions: map[string]any{"code": "BUDGET_EXCEEDED"},
+		}
+	}
+	return err
+}
+
+// entityInputToModel converts a GraphQL EntityInput to models.EntityInput.
+func entityInputToModel(input EntityInput) models.EntityInput {
+	modelInput := models.EntityInput{
+		Type:       input.Type,
+		Name:       input.Name,
+		Content:    input.Content,
+		Summary:    input.Summary,
+		Labels:     input.Labels,
+		Verified:   input.Verified,
+		SourcePath: input.SourcePath,
+		Metadata:   input.Metadata,
+		Language:   input.Language,
+		Context:    input.Context,
+	}
+
+	// Set source if provided
+	if input.Source != nil {
+		source := models.EntitySource(*input.Source)
+		modelInput.Source = &source
+	}
+	return modelInput
+}
+
+// entityBatchResultsToGraphQL converts the results of a batch create to
+// GraphQL CreateEntityResults.
+func entityBatchResultsToGraphQL(results []db.EntityBatchResult) []*CreateEntityResult {
+	out := make([]*CreateEntityResult, len(results))
+	for i, r := range results {
+		out[i] = &CreateEntityResult{}
+		if r.Err != nil {
+			msg := r.Err.Error()
+			out[i].Error = &msg
+			continue
+		}
+		out[i].Entity = entityToGraphQL(r.Entity)
+	}
+	return out
+}
+
 // entityToGraphQL converts a models.Entity to a GraphQL Entity.
 func entityToGraphQL(e *models.Entity) *Entity {
 	if e == nil {
@@ -19,25 +77,75 @@ func entityToGraphQL(e *models.Entity) *Entity {
 	if err != nil {
 		idStr = fmt.Sprintf("%v", e.ID.ID)
 	}
+	aliases := e.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
 
 	return &Entity{
-		ID:          idStr,
-		Type:        e.Type,
-		Name:        e.Name,
-		Content:     e.Content,
-		Summary:     e.Summary,
-		Labels:      e.Labels,
-		ContentHash: e.ContentHash,
-		Verified:    e.Verified,
-		Confidence:  e.Confidence,
-		Source:      string(e.Source),
-		SourcePath:  e.SourcePath,
-		Metadata:    e.Metadata,
-		CreatedAt:   e.CreatedAt,
-		UpdatedAt:   e.UpdatedAt,
-		AccessedAt:  e.Accessed,
-		AccessCount: e.AccessCount,
-		Relations:   []Relation{}, // Relations loaded separately if needed
+		ID:              idStr,
+		Type:            e.Type,
+		Name:            e.Name,
+		Content:         e.Content,
+		Summary:         e.Summary,
+		Labels:          e.Labels,
+		Aliases:         aliases,
+		ContentHash:     e.ContentHash,
+		Verified:        e.Verified,
+		Confidence:      e.Confidence,
+		Source:          string(e.Source),
+		SourcePath:      e.SourcePath,
+		Metadata:        e.Metadata,
+		CreatedAt:       e.CreatedAt,
+		UpdatedAt:       e.UpdatedAt,
+		ModifiedAt:      e.ModifiedAt,
+		AccessedAt:      e.Accessed,
+		AccessCount:     e.AccessCount,
+		AlwaysInContext: e.AlwaysInContext,
+		Pinned:          e.Pinned,
+		Language:        e.Language,
+		Context:         e.Context,
+		Relations:       []Relation{}, // Relations loaded separately if needed
+	}
+}
+
+// relationToGraphQL converts a models.Relation to a GraphQL Relation.
+func relationToGraphQL(r models.Relation) *Relation {
+	idStr, err := models.RecordIDString(r.ID)
+	if err != nil {
+		idStr = fmt.Sprintf("%v", r.ID.ID)
+	}
+	return &Relation{
+		ID:        idStr,
+		FromID:    models.MustRecordIDString(r.In),
+		ToID:      models.MustRecordIDString(r.Out),
+		RelType:   r.RelType,
+		Strength:  r.Strength,
+		Source:    r.Source,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// graphExportToGraphQL converts a service.GraphExport to a GraphQL GraphExport.
+func graphExportToGraphQL(g *service.GraphExport) *GraphExport {
+	entities := make([]*Entity, len(g.Entities))
+	for i := range g.Entities {
+		entities[i] = entityToGraphQL(&g.Entities[i])
+	}
+	relations := make([]*Relation, len(g.Relations))
+	for i, rel := range g.Relations {
+		relations[i] = relationToGraphQL(rel)
+	}
+	return &GraphExport{Entities: entities, Relations: relations}
+}
+
+// usefulSourceToGraphQL converts a service.UsefulSource to GraphQL AnswerSourceStats.
+func usefulSourceToGraphQL(u service.UsefulSource) *AnswerSourceStats {
+	return &AnswerSourceStats{
+		Entity:    entityToGraphQL(&u.Entity),
+		Retrieved: u.Retrieved,
+		Included:  u.Included,
+		AvgRank:   u.AvgRank,
 	}
 }
 
@@ -86,6 +194,212 @@ func searchResultToGraphQL(r *models.EntitySearchResult) *EntitySearchResult {
 	}
 }
 
+// chunkSearchResultToGraphQL converts a chunk-level search match to its GraphQL type.
+func chunkSearchResultToGraphQL(m *models.ChunkMatch) *ChunkSearchResult {
+	return &ChunkSearchResult{
+		EntityID:    m.EntityID,
+		EntityName:  m.EntityName,
+		Content:     m.Content,
+		HeadingPath: m.HeadingPath,
+		Position:    m.Position,
+		Score:       m.Score,
+	}
+}
+
+// facetedResultsToGraphQL converts service.FacetedResults to a GraphQL FacetedSearchResult.
+func facetedResultsToGraphQL(f *service.FacetedResults) *FacetedSearchResult {
+	groups := make([]*TypeGroup, len(f.Groups))
+	for i, g := range f.Groups {
+		results := make([]*EntitySearchResult, len(g.Results))
+		for j := range g.Results {
+			results[j] = searchResultToGraphQL(&g.Results[j])
+		}
+		groups[i] = &TypeGroup{Type: g.Type, Count: len(g.Results), Results: results}
+	}
+
+	labels := make([]*LabelCount, len(f.Labels))
+	for i, l := range f.Labels {
+		labels[i] = &LabelCount{Label: l.Value, Count: l.Count}
+	}
+
+	sources := make([]*SourceCount, len(f.Sources))
+	for i, s := range f.Sources {
+		sources[i] = &SourceCount{Source: s.Value, Count: s.Count}
+	}
+
+	return &FacetedSearchResult{
+		Total:   f.Total,
+		Groups:  groups,
+		Labels:  labels,
+		Sources: sources,
+	}
+}
+
+// chunkOptionsFromGraphQL converts an optional GraphQL ChunkOptionsInput to service.ChunkOptions.
+func chunkOptionsFromGraphQL(input *ChunkOptionsInput) service.ChunkOptions {
+	opts := service.ChunkOptions{}
+	if input == nil {
+		return opts
+	}
+	if input.Strategy != nil {
+		opts.Strategy = parser.ChunkStrategy(*input.Strategy)
+	}
+	if input.Threshold != nil {
+		opts.Threshold = *input.Threshold
+	}
+	if input.TargetSize != nil {
+		opts.TargetSize = *input.TargetSize
+	}
+	if input.MinSize != nil {
+		opts.MinSize = *input.MinSize
+	}
+	if input.MaxSize != nil {
+		opts.MaxSize = *input.MaxSize
+	}
+	opts.Overlap = input.Overlap
+	return opts
+}
+
+// chunkPreviewToGraphQL converts a service.ChunkPreview to a GraphQL ChunkPreview.
+func chunkPreviewToGraphQL(c service.ChunkPreview) *ChunkPreview {
+	preview := &ChunkPreview{
+		Position: c.Position,
+		Content:  c.Content,
+		Length:   len(c.Content),
+	}
+	if c.HeadingPath != "" {
+		preview.HeadingPath = &c.HeadingPath
+	}
+	return preview
+}
+
+// pathToGraphQL converts a path of models.PathStep to GraphQL PathSteps.
+func pathToGraphQL(path []models.PathStep) []*PathStep {
+	result := make([]*PathStep, len(path))
+	for i, step := range path {
+		result[i] = &PathStep{
+			FromID:  step.FromID,
+			ToID:    step.ToID,
+			RelType: step.RelType,
+			Reverse: step.Reverse,
+		}
+	}
+	return result
+}
+
+// searchInputToOptions converts an optional GraphQL SearchInput to service.SearchOptions.
+func searchInputToOptions(input *SearchInput) service.SearchOptions {
+	opts := service.SearchOptions{}
+	if input == nil {
+		return opts
+	}
+
+	opts.Query = input.Query
+	if input.Mode != nil {
+		opts.Mode = *input.Mode
+	}
+	opts.Labels = input.Labels
+	opts.LabelGroups = input.LabelGroups
+	opts.HasMetadataKeys = input.HasMetadataKeys
+	opts.Types = input.Types
+	if input.VerifiedOnly != nil {
+		opts.VerifiedOnly = *input.VerifiedOnly
+	}
+	opts.MinConfidence = input.MinConfidence
+	opts.ExcludeIDs = input.ExcludeIds
+	if input.Language != nil {
+		opts.Language = *input.Language
+	}
+	if input.Context != nil {
+		opts.Context = *input.Context
+	}
+	if input.Diversity != nil {
+		opts.Diversity = *input.Diversity
+	}
+	if input.Rerank != nil {
+		opts.Rerank = *input.Rerank
+	}
+	if input.ApplyDecay != nil {
+		opts.ApplyDecay = *input.ApplyDecay
+	}
+	if input.ExpandGraph != nil {
+		opts.ExpandGraph = *input.ExpandGraph
+	}
+	if input.ConversationID != nil {
+		opts.ConversationID = *input.ConversationID
+	}
+	opts.EfSearch = input.EfSearch
+	if input.OverFetchFactor != nil {
+		opts.OverFetchFactor = *input.OverFetchFactor
+	}
+	if input.SortBy != nil {
+		opts.SortBy = *input.SortBy
+	}
+	if input.Limit != nil {
+		opts.Limit = *input.Limit
+	}
+	return opts
+}
+
+// ingestInputToOptions converts an optional GraphQL IngestInput to service.IngestOptions.
+func ingestInputToOptions(input *IngestInput) service.IngestOptions {
+	opts := service.IngestOptions{}
+	if input == nil {
+		return opts
+	}
+
+	if input.Name != nil {
+		opts.Name = *input.Name
+	}
+	opts.Labels = input.Labels
+	if input.ExtractGraph != nil {
+		opts.ExtractGraph = *input.ExtractGraph
+	}
+	if input.AutoSummarize != nil {
+		opts.AutoSummarize = *input.AutoSummarize
+	}
+	if input.DryRun != nil {
+		opts.DryRun = *input.DryRun
+	}
+	if input.Recursive != nil {
+		opts.Recursive = *input.Recursive
+	}
+	if input.FailFast != nil {
+		opts.FailFast = *input.FailFast
+	}
+	if input.Prune != nil {
+		opts.Prune = *input.Prune
+	}
+	if input.ChunkStrategy != nil {
+		opts.ChunkStrategy = parser.ChunkStrategy(*input.ChunkStrategy)
+	}
+	if input.ChunkSize != nil {
+		opts.ChunkSize = *input.ChunkSize
+	}
+	opts.ChunkOverlap = input.ChunkOverlap
+	if input.MaxFileBytes != nil {
+		opts.MaxFileBytes = int64(*input.MaxFileBytes)
+	}
+	if input.Concurrency != nil {
+		opts.Concurrency = *input.Concurrency
+	}
+	return opts
+}
+
+// jobProgressToGraphQL converts a service.JobProgress to a GraphQL JobProgressEvent.
+func jobProgressToGraphQL(p service.JobProgress) *JobProgressEvent {
+	event := &JobProgressEvent{
+		JobID:    p.JobID,
+		Progress: p.Progress,
+		Total:    p.Total,
+		Status:   string(p.Status),
+	}
+	if p.Error != "" {
+		event.Error = &p.Error
+	}
+	return event
+}
+
 // serviceJobToGraphQL converts a service.Job to a GraphQL Job.
 func serviceJobToGraphQL(j *service.Job) *Job {
 	snapshot := j.Snapshot()
@@ -95,14 +409,7 @@ func serviceJobToGraphQL(j *service.Job) *Job {
 	}
 	var result *IngestResult
 	if snapshot.Result != nil {
-		result = &IngestResult{
-			FilesProcessed:   snapshot.Result.FilesProcessed,
-			FilesSkipped:     snapshot.Result.FilesSkipped,
-			EntitiesCreated:  snapshot.Result.EntitiesCreated,
-			ChunksCreated:    snapshot.Result.ChunksCreated,
-			RelationsCreated: snapshot.Result.RelationsCreated,
-			Errors:           snapshot.Result.Errors,
-		}
+		result = ingestResultToGraphQL(snapshot.Result)
 	}
 
 	// Handle persistence fields
@@ -166,7 +473,9 @@ func dbJobToGraphQL(j *models.IngestJob) *Job {
 			FilesProcessed:   intFromMap(j.Result, "files_processed"),
 			EntitiesCreated:  intFromMap(j.Result, "entities_created"),
 			ChunksCreated:    intFromMap(j.Result, "chunks_created"),
+			ChunksFailed:     intFromMap(j.Result, "chunks_failed"),
 			RelationsCreated: intFromMap(j.Result, "relations_created"),
+			EntitiesDeleted:  intFromMap(j.Result, "entities_deleted"),
 			Errors:           stringsFromMap(j.Result, "errors"),
 		}
 	}
@@ -238,6 +547,21 @@ func messageToGraphQL(m *models.Message) Message {
 	}
 }
 
+// messageSearchResultToGraphQL converts a models.MessageSearchResult to GraphQL.
+func messageSearchResultToGraphQL(r *models.MessageSearchResult) *MessageSearchResult {
+	convID, err := models.RecordIDString(r.Conversation)
+	if err != nil {
+		convID = fmt.Sprintf("%v", r.Conversation.ID)
+	}
+	msg := messageToGraphQL(&r.Message)
+	return &MessageSearchResult{
+		Message:           &msg,
+		ConversationID:    convID,
+		ConversationTitle: r.ConversationTitle,
+		Distance:          r.Distance,
+	}
+}
+
 // intFromMap extracts an int from a map[string]any.
 func intFromMap(m map[string]any, key string) int {
 	if v, ok := m[key]; ok {
@@ -326,6 +650,55 @@ func operationSnapshotToGraphQL(s *metrics.OperationSnapshot) *OperationStats {
 	return stats
 }
 
+// metricsRecordToGraphQL converts a persisted db.MetricsSnapshotRecord to a GraphQL MetricsSnapshot.
+func metricsRecordToGraphQL(r db.MetricsSnapshotRecord) *MetricsSnapshot {
+	return &MetricsSnapshot{
+		CreatedAt:     r.CreatedAt,
+		UptimeSeconds: r.UptimeSeconds,
+		Embedding:     operationSnapshotToGraphQL(r.Embedding),
+		LlmGenerate:   operationSnapshotToGraphQL(r.LLMGenerate),
+		LlmStream:     operationSnapshotToGraphQL(r.LLMStream),
+		DbQuery:       operationSnapshotToGraphQL(r.DBQuery),
+		DbSearch:      operationSnapshotToGraphQL(r.DBSearch),
+	}
+}
+
+// fileContentInputsToService converts uploaded files, including their
+// .knowhow.yaml defaults, to service file contents.
+func fileContentInputsToService(inputs []*FileContentInput) []service.FileContent {
+	files := make([]service.FileContent, len(inputs))
+	for i, f := range inputs {
+		files[i] = service.FileContent{
+			Path:    f.Path,
+			Content: f.Content,
+			Hash:    f.Hash,
+			Config: parser.DirConfig{
+				Labels:        f.Labels,
+				ExtractGraph:  f.ExtractGraph,
+				AutoSummarize: f.AutoSummarize,
+			},
+		}
+		if f.Type != nil {
+			files[i].Config.Type = *f.Type
+		}
+	}
+	return files
+}
+
+// batchAnswerToGraphQL converts a service.BatchAnswer to GraphQL BatchAnswer.
+func batchAnswerToGraphQL(a service.BatchAnswer) *BatchAnswer {
+	result := &BatchAnswer{
+		Question:     a.Question,
+		Answer:       a.Answer,
+		InputTokens:  int(a.Usage.InputTokens),
+		OutputTokens: int(a.Usage.OutputTokens),
+	}
+	if a.Error != "" {
+		result.Error = &a.Error
+	}
+	return result
+}
+
 // metricsSnapshotToGraphQL converts a metrics.Snapshot to a GraphQL ServerStats.
 func metricsSnapshotToGraphQL(s metrics.Snapshot) *ServerStats {
 	return &ServerStats{
@@ -335,5 +708,217 @@ func metricsSnapshotToGraphQL(s metrics.Snapshot) *ServerStats {
 		LlmStream:     operationSnapshotToGraphQL(s.LLMStream),
 		DbQuery:       operationSnapshotToGraphQL(s.DBQuery),
 		DbSearch:      operationSnapshotToGraphQL(s.DBSearch),
+		Rerank:        operationSnapshotToGraphQL(s.Rerank),
+		AnswerCache:   cacheSnapshotToGraphQL(s.AnswerCache),
+		EmbedCache:    cacheSnapshotToGraphQL(s.EmbedCache),
+	}
+}
+
+// dbConnectionStatsToGraphQL converts pooled connection stats to GraphQL.
+func dbConnectionStatsToGraphQL(stats []db.ConnectionStats) []*DBConnectionStats {
+	result := make([]*DBConnectionStats, len(stats))
+	for i, s := range stats {
+		result[i] = &DBConnectionStats{
+			Active:    int(s.Active),
+			MaxActive: int(s.MaxActive),
+			Queries:   int(s.Queries),
+			Connected: s.Connected,
+		}
+	}
+	return result
+}
+
+// cacheSnapshotToGraphQL converts a metrics.CacheSnapshot to GraphQL CacheStats.
+func cacheSnapshotToGraphQL(s *metrics.CacheSnapshot) *CacheStats {
+	if s == nil {
+		return nil
+	}
+	return &CacheStats{
+		Hits:    int(s.Hits),
+		Misses:  int(s.Misses),
+		HitRate: s.HitRate,
+	}
+}
+
+// ingestResultToGraphQL converts a service.IngestResult to GraphQL IngestResult.
+func ingestResultToGraphQL(r *service.IngestResult) *IngestResult {
+	result := &IngestResult{
+		FilesProcessed:   r.FilesProcessed,
+		FilesSkipped:     r.FilesSkipped,
+		EntitiesCreated:  r.EntitiesCreated,
+		ChunksCreated:    r.ChunksCreated,
+		ChunksFailed:     r.ChunksFailed,
+		RelationsCreated: r.RelationsCreated,
+		EntitiesDeleted:  r.EntitiesDeleted,
+		Errors:           r.Errors,
+	}
+	if r.Changes != nil {
+		result.Changes = make([]*FileAction, len(r.Changes))
+		for i, c := range r.Changes {
+			result.Changes[i] = &FileAction{Path: c.Path, Action: c.Action}
+		}
+	}
+	return result
+}
+
+// ingestDiffToGraphQL converts a service.IngestDiff to GraphQL IngestDiff.
+func ingestDiffToGraphQL(d service.IngestDiff) *IngestDiff {
+	changed := make([]*FileChange, len(d.Changed))
+	for i, c := range d.Changed {
+		changed[i] = &FileChange{
+			Path:         c.Path,
+			EntityID:     c.EntityID,
+			Old:          entityPreviewToGraphQL(c.Old),
+			New:          entityPreviewToGraphQL(c.New),
+			LinesAdded:   c.LinesAdded,
+			LinesRemoved: c.LinesRemoved,
+		}
+	}
+	return &IngestDiff{
+		New:       d.New,
+		Changed:   changed,
+		Unchanged: d.Unchanged,
+	}
+}
+
+// entityPreviewToGraphQL converts a service.EntityPreview to GraphQL EntityPreview.
+func entityPreviewToGraphQL(p service.EntityPreview) *EntityPreview {
+	labels := p.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	return &EntityPreview{
+		Name:          p.Name,
+		Type:          p.Type,
+		Summary:       p.Summary,
+		Labels:        labels,
+		ContentLength: p.ContentLength,
+	}
+}
+
+// graphAnalyticsToGraphQL converts db.GraphAnalytics to GraphQL GraphAnalytics.
+func graphAnalyticsToGraphQL(a db.GraphAnalytics) *GraphAnalytics {
+	top := make([]*ConnectedEntity, len(a.TopConnected))
+	for i, e := range a.TopConnected {
+		top[i] = &ConnectedEntity{ID: e.ID, Name: e.Name, Type: e.Type, Degree: e.Degree}
+	}
+	relTypes := make([]*RelationTypeCount, len(a.RelationTypes))
+	for i, rt := range a.RelationTypes {
+		relTypes[i] = &RelationTypeCount{RelType: rt.RelType, Count: rt.Count}
+	}
+	return &GraphAnalytics{
+		Nodes:         a.Nodes,
+		Edges:         a.Edges,
+		AverageDegree: a.AverageDegree,
+		Orphans:       a.Orphans,
+		TopConnected:  top,
+		RelationTypes: relTypes,
+		ComputedAt:    a.ComputedAt,
+	}
+}
+
+// neighborToGraphQL converts a db.Neighbor to a GraphQL Neighbor.
+func neighborToGraphQL(n *db.Neighbor) *Neighbor {
+	return &Neighbor{
+		Entity:   entityToGraphQL(&n.Entity),
+		Distance: n.Distance,
+	}
+}
+
+// dedupeReportToGraphQL converts a service.DedupeReport to GraphQL DedupeReport.
+func dedupeReportToGraphQL(r *service.DedupeReport) *DedupeReport {
+	pairs := make([]*DuplicatePair, len(r.Pairs))
+	for i := range r.Pairs {
+		p := &r.Pairs[i]
+		pair := &DuplicatePair{
+			Keep:       entityToGraphQL(&p.Keep),
+			Merge:      entityToGraphQL(&p.Merge),
+			Similarity: p.Similarity,
+			Merged:     p.Merged,
+		}
+		if p.SkipReason != "" {
+			pair.SkipReason = &p.SkipReason
+		}
+		if p.Error != "" {
+			pair.Error = &p.Error
+		}
+		pairs[i] = pair
+	}
+	report := &DedupeReport{
+		Pairs:   pairs,
+		Merged:  r.Merged,
+		Skipped: r.Skipped,
+	}
+	if r.NextCursor != "" {
+		report.NextCursor = &r.NextCursor
+	}
+	return report
+}
+
+// compactReportToGraphQL converts a db.CompactReport to GraphQL CompactReport.
+func compactReportToGraphQL(r db.CompactReport, dryRun bool) *CompactReport {
+	entities := make([]*Entity, len(r.EmptyEntities))
+	for i := range r.EmptyEntities {
+		entities[i] = entityToGraphQL(&r.EmptyEntities[i])
+	}
+	return &CompactReport{
+		OrphanedChunks:    r.OrphanedChunks,
+		DanglingRelations: r.DanglingRelations,
+		EmptyEntities:     entities,
+		DryRun:            dryRun,
+	}
+}
+
+// importReportToGraphQL converts a service.ImportReport to a GraphQL RelationImportReport.
+func importReportToGraphQL(r service.ImportReport) *RelationImportReport {
+	skipped := make([]*SkippedRow, len(r.Skipped))
+	for i, row := range r.Skipped {
+		skipped[i] = &SkippedRow{Line: row.Line, Reason: row.Reason}
+	}
+	unresolved := r.Unresolved
+	if unresolved == nil {
+		unresolved = []string{}
+	}
+	return &RelationImportReport{
+		Rows:       r.Rows,
+		Created:    r.Created,
+		Unresolved: unresolved,
+		Skipped:    skipped,
+	}
+}
+
+// decayConfigToGraphQL converts a db.DecayConfig to a GraphQL DecayConfig.
+// types lists the entity types present in the database; configured types are
+// always included.
+func decayConfigToGraphQL(cfg db.DecayConfig, interval time.Duration, types []string) *DecayConfig {
+	curve := cfg.Curve
+	if curve == "" {
+		curve = db.DecayExponential
+	}
+
+	names := slices.Clone(types)
+	for t := range cfg.TypeHalfLifeDays {
+		if !slices.Contains(names, t) {
+			names = append(names, t)
+		}
+	}
+	slices.Sort(names)
+
+	typeDecays := make([]*TypeDecay, len(names))
+	for i, t := range names {
+		_, configured := cfg.TypeHalfLifeDays[t]
+		typeDecays[i] = &TypeDecay{
+			Type:         t,
+			HalfLifeDays: cfg.HalfLife(t),
+			Configured:   configured,
+		}
+	}
+
+	return &DecayConfig{
+		Curve:               curve,
+		DefaultHalfLifeDays: cfg.HalfLifeDays,
+		MinWeight:           cfg.MinWeight,
+		IntervalSeconds:     int(interval.Seconds()),
+		Types:               typeDecays,
 	}
 }
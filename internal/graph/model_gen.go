@@ -6,6 +6,17 @@ import (
 	"time"
 )
 
+// How often an entity was retrieved for asks
+type AnswerSourceStats struct {
+	Entity *Entity `json:"entity"`
+	// Asks that retrieved the entity
+	Retrieved int `json:"retrieved"`
+	// Asks whose context included its text (summary, chunks or content)
+	Included int `json:"included"`
+	// Average 1-based position in the answer context
+	AvgRank float64 `json:"avgRank"`
+}
+
 type AskStreamEvent struct {
 	// Token content from the LLM stream
 	Token string `json:"token"`
@@ -15,6 +26,21 @@ type AskStreamEvent struct {
 	Error *string `json:"error,omitempty"`
 }
 
+type BatchAnswer struct {
+	Question     string `json:"question"`
+	Answer       string `json:"answer"`
+	InputTokens  int    `json:"inputTokens"`
+	OutputTokens int    `json:"outputTokens"`
+	// Error message if this question failed
+	Error *string `json:"error,omitempty"`
+}
+
+type CacheStats struct {
+	Hits    int     `json:"hits"`
+	Misses  int     `json:"misses"`
+	HitRate float64 `json:"hitRate"`
+}
+
 type CheckHashesInput struct {
 	Files []*FileHashInput `json:"files"`
 }
@@ -24,6 +50,161 @@ type CheckHashesResult struct {
 	Needed []string `json:"needed"`
 }
 
+// Chunking parameters for a preview; unset fields use the ingest defaults
+type ChunkOptionsInput struct {
+	// heading (default), fixed or sentence
+	Strategy *string `json:"strategy,omitempty"`
+	// Only chunk content longer than this many characters
+	Threshold  *int `json:"threshold,omitempty"`
+	TargetSize *int `json:"targetSize,omitempty"`
+	// Smaller chunks merge with neighbors
+	MinSize *int `json:"minSize,omitempty"`
+	// Larger chunks split at sentences
+	MaxSize *int `json:"maxSize,omitempty"`
+	// Characters repeated between neighboring chunks
+	Overlap *int `json:"overlap,omitempty"`
+}
+
+// A chunk a document would be split into on ingest
+type ChunkPreview struct {
+	Position    int     `json:"position"`
+	Content     string  `json:"content"`
+	HeadingPath *string `json:"headingPath,omitempty"`
+	Length      int     `json:"length"`
+}
+
+// A chunk matched by searchChunks, with the entity to cite
+type ChunkSearchResult struct {
+	EntityID    string  `json:"entityId"`
+	EntityName  string  `json:"entityName"`
+	Content     string  `json:"content"`
+	HeadingPath *string `json:"headingPath,omitempty"`
+	Position    int     `json:"position"`
+	// Fused RRF score, or BM25 / cosine similarity score in keyword / vector mode
+	Score float64 `json:"score"`
+}
+
+type CompactReport struct {
+	// Chunks whose parent entity no longer exists
+	OrphanedChunks int `json:"orphanedChunks"`
+	// Relations with a missing endpoint
+	DanglingRelations int `json:"danglingRelations"`
+	// Entities with no content, summary or relations
+	EmptyEntities []*Entity `json:"emptyEntities"`
+	// True if nothing was removed
+	DryRun bool `json:"dryRun"`
+}
+
+type ConnectedEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Number of relations in either direction
+	Degree int `json:"degree"`
+}
+
+// Outcome of one input of createEntities: the entity, or why it wasn't created
+type CreateEntityResult struct {
+	Entity *Entity `json:"entity,omitempty"`
+	Error  *string `json:"error,omitempty"`
+}
+
+// Load of one pooled database connection. Connections that are often busy at
+// once (high maxActive) suggest a larger pool
+type DBConnectionStats struct {
+	// Queries in flight
+	Active int `json:"active"`
+	// Highest number of queries in flight at once
+	MaxActive int `json:"maxActive"`
+	// Queries run since the server started
+	Queries   int  `json:"queries"`
+	Connected bool `json:"connected"`
+}
+
+type DecayConfig struct {
+	// exponential or linear
+	Curve               string  `json:"curve"`
+	DefaultHalfLifeDays float64 `json:"defaultHalfLifeDays"`
+	MinWeight           float64 `json:"minWeight"`
+	// Seconds between periodic confidence decay runs (0 if disabled)
+	IntervalSeconds int `json:"intervalSeconds"`
+	// Effective half-life for every configured or existing entity type
+	Types []*TypeDecay `json:"types"`
+}
+
+type DedupeReport struct {
+	// Near-duplicate pairs, most similar first
+	Pairs  []*DuplicatePair `json:"pairs"`
+	Merged int              `json:"merged"`
+	// Pairs that can't be merged automatically or failed to merge
+	Skipped int `json:"skipped"`
+	// Pass as cursor to check the next page of entities; null when all entities were checked
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
+
+type DuplicatePair struct {
+	// Survivor of a merge: the entity with higher confidence, then more accesses
+	Keep  *Entity `json:"keep"`
+	Merge *Entity `json:"merge"`
+	// Cosine similarity of the embeddings
+	Similarity float64 `json:"similarity"`
+	Merged     bool    `json:"merged"`
+	// Why the pair can't be merged automatically ("different types", "both verified", "already merged")
+	SkipReason *string `json:"skipReason,omitempty"`
+	// Merge failure
+	Error *string `json:"error,omitempty"`
+}
+
+type EntityChangeEvent struct {
+	// created, updated or deleted
+	Type string `json:"type"`
+	// Entity after the change (before it for deleted)
+	Entity *Entity `json:"entity"`
+}
+
+// A page of entities from changedEntities
+type EntityPage struct {
+	Entities []*Entity `json:"entities"`
+	// Pass as cursor to get the next page; null when there are no more changes
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
+
+type EntityPreview struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Summary       *string  `json:"summary,omitempty"`
+	Labels        []string `json:"labels"`
+	ContentLength int      `json:"contentLength"`
+}
+
+// Search results grouped by type, with facet counts over all results
+type FacetedSearchResult struct {
+	Total int `json:"total"`
+	// Groups ordered by each type's best-ranked result
+	Groups []*TypeGroup `json:"groups"`
+	// Results per label, most common first
+	Labels []*LabelCount `json:"labels"`
+	// Results per source (manual, mcp, scrape, ai_generated), most common first
+	Sources []*SourceCount `json:"sources"`
+}
+
+type FileAction struct {
+	Path string `json:"path"`
+	// create, update or unchanged
+	Action string `json:"action"`
+}
+
+type FileChange struct {
+	Path     string         `json:"path"`
+	EntityID string         `json:"entityId"`
+	Old      *EntityPreview `json:"old"`
+	New      *EntityPreview `json:"new"`
+	// Content lines only in the file
+	LinesAdded int `json:"linesAdded"`
+	// Content lines only in the existing entity
+	LinesRemoved int `json:"linesRemoved"`
+}
+
 type FileContentInput struct {
 	// File path (used for entity name derivation)
 	Path string `json:"path"`
@@ -31,6 +212,14 @@ type FileContentInput struct {
 	Content string `json:"content"`
 	// SHA256 hash of content
 	Hash string `json:"hash"`
+	// Entity type if frontmatter has none (from .knowhow.yaml)
+	Type *string `json:"type,omitempty"`
+	// Labels added to this file (from .knowhow.yaml)
+	Labels []string `json:"labels,omitempty"`
+	// Extract relations using LLM for this file (from .knowhow.yaml)
+	ExtractGraph *bool `json:"extractGraph,omitempty"`
+	// Generate a summary using LLM for this file (from .knowhow.yaml)
+	AutoSummarize *bool `json:"autoSummarize,omitempty"`
 }
 
 type FileHashInput struct {
@@ -40,6 +229,34 @@ type FileHashInput struct {
 	Hash string `json:"hash"`
 }
 
+type GraphAnalytics struct {
+	Nodes int `json:"nodes"`
+	Edges int `json:"edges"`
+	// Relations per entity (each relation counts for both ends)
+	AverageDegree float64 `json:"averageDegree"`
+	// Entities without any relation
+	Orphans int `json:"orphans"`
+	// Entities with the most relations, most first
+	TopConnected  []*ConnectedEntity   `json:"topConnected"`
+	RelationTypes []*RelationTypeCount `json:"relationTypes"`
+	ComputedAt    time.Time            `json:"computedAt"`
+}
+
+// Entities and the relations between them
+type GraphExport struct {
+	Entities  []*Entity   `json:"entities"`
+	Relations []*Relation `json:"relations"`
+}
+
+type IngestDiff struct {
+	// Files without an existing entity
+	New []string `json:"new"`
+	// Files whose entity would be updated
+	Changed []*FileChange `json:"changed"`
+	// Files whose entity is up to date
+	Unchanged []string `json:"unchanged"`
+}
+
 type IngestFilesInput struct {
 	Files []*FileContentInput `json:"files"`
 	// Base directory name for entity ID derivation (e.g., 'insights' from ~/.claude/insights)
@@ -63,9 +280,45 @@ type Job struct {
 	PendingFiles *int          `json:"pendingFiles,omitempty"`
 }
 
+type JobProgressEvent struct {
+	JobID string `json:"jobId"`
+	// Files processed so far
+	Progress int `json:"progress"`
+	Total    int `json:"total"`
+	// pending, running, completed, failed or cancelled
+	Status string `json:"status"`
+	// Set when the job failed
+	Error *string `json:"error,omitempty"`
+}
+
+type MessageSearchResult struct {
+	Message           *Message `json:"message"`
+	ConversationID    string   `json:"conversationId"`
+	ConversationTitle string   `json:"conversationTitle"`
+	// Vector distance to the query (lower is closer), 0 for text matches
+	Distance float64 `json:"distance"`
+}
+
+type MetricsSnapshot struct {
+	CreatedAt     time.Time       `json:"createdAt"`
+	UptimeSeconds float64         `json:"uptimeSeconds"`
+	Embedding     *OperationStats `json:"embedding,omitempty"`
+	LlmGenerate   *OperationStats `json:"llmGenerate,omitempty"`
+	LlmStream     *OperationStats `json:"llmStream,omitempty"`
+	DbQuery       *OperationStats `json:"dbQuery,omitempty"`
+	DbSearch      *OperationStats `json:"dbSearch,omitempty"`
+}
+
 type Mutation struct {
 }
 
+// An entity reached from another over relations
+type Neighbor struct {
+	Entity *Entity `json:"entity"`
+	// Hops on the shortest path from the origin entity
+	Distance int `json:"distance"`
+}
+
 type OperationStats struct {
 	Count             int      `json:"count"`
 	TotalTimeMs       int      `json:"totalTimeMs"`
@@ -82,17 +335,84 @@ type OperationStats struct {
 	MaxOutputTokens   *int     `json:"maxOutputTokens,omitempty"`
 }
 
+// A single hop along a path between two entities
+type PathStep struct {
+	FromID  string `json:"fromId"`
+	ToID    string `json:"toId"`
+	RelType string `json:"relType"`
+	// True if the underlying relation points from toId to fromId
+	Reverse bool `json:"reverse"`
+}
+
 type Query struct {
 }
 
+type RelationImportReport struct {
+	// Edge rows read (excluding header)
+	Rows int `json:"rows"`
+	// Relations created or updated
+	Created int `json:"created"`
+	// Endpoint references that matched no entity
+	Unresolved []string      `json:"unresolved"`
+	Skipped    []*SkippedRow `json:"skipped"`
+}
+
+type RelationTypeCount struct {
+	RelType string `json:"relType"`
+	Count   int    `json:"count"`
+}
+
+type SearchStreamEvent struct {
+	// Next search result in rank order, null on the final event
+	Result *EntitySearchResult `json:"result,omitempty"`
+	// True when all results were sent
+	Done bool `json:"done"`
+	// Error message if the search failed
+	Error *string `json:"error,omitempty"`
+}
+
 type ServerStats struct {
-	UptimeSeconds float64         `json:"uptimeSeconds"`
-	Embedding     *OperationStats `json:"embedding,omitempty"`
-	LlmGenerate   *OperationStats `json:"llmGenerate,omitempty"`
-	LlmStream     *OperationStats `json:"llmStream,omitempty"`
-	DbQuery       *OperationStats `json:"dbQuery,omitempty"`
-	DbSearch      *OperationStats `json:"dbSearch,omitempty"`
+	UptimeSeconds float64 `json:"uptimeSeconds"`
+	// Embedding vector index state: pending, ready or failed
+	VectorIndex string          `json:"vectorIndex"`
+	Embedding   *OperationStats `json:"embedding,omitempty"`
+	LlmGenerate *OperationStats `json:"llmGenerate,omitempty"`
+	LlmStream   *OperationStats `json:"llmStream,omitempty"`
+	DbQuery     *OperationStats `json:"dbQuery,omitempty"`
+	DbSearch    *OperationStats `json:"dbSearch,omitempty"`
+	// Search result reranking (null if no rerank model is configured or unused)
+	Rerank *OperationStats `json:"rerank,omitempty"`
+	// Answer cache lookups by ask (null if caching is disabled or unused)
+	AnswerCache *CacheStats `json:"answerCache,omitempty"`
+	// Embedding cache lookups (null if caching is disabled or unused)
+	EmbedCache *CacheStats `json:"embedCache,omitempty"`
+	// Load of each pooled database connection (KNOWHOW_DB_POOL_SIZE)
+	DbConnections []*DBConnectionStats `json:"dbConnections"`
+}
+
+type SkippedRow struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+type SourceCount struct {
+	Source string `json:"source"`
+	Count  int    `json:"count"`
 }
 
 type Subscription struct {
 }
+
+type TypeDecay struct {
+	Type         string  `json:"type"`
+	HalfLifeDays float64 `json:"halfLifeDays"`
+	// True if set per type, false if the default half-life applies
+	Configured bool `json:"configured"`
+}
+
+// Search results of one entity type, in rank order
+type TypeGroup struct {
+	Type    string                `json:"type"`
+	Count   int                   `json:"count"`
+	Results []*EntitySearchResult `json:"results"`
+}
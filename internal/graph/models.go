@@ -7,23 +7,29 @@ import (
 
 // Entity represents a knowledge entity in the GraphQL schema.
 type Entity struct {
-	ID          string         `json:"id"`
-	Type        string         `json:"type"`
-	Name        string         `json:"name"`
-	Content     *string        `json:"content,omitempty"`
-	Summary     *string        `json:"summary,omitempty"`
-	Labels      []string       `json:"labels"`
-	ContentHash *string        `json:"contentHash,omitempty"`
-	Verified    bool           `json:"verified"`
-	Confidence  float64        `json:"confidence"`
-	Source      string         `json:"source"`
-	SourcePath  *string        `json:"sourcePath,omitempty"`
-	Metadata    map[string]any `json:"metadata,omitempty"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	UpdatedAt   time.Time      `json:"updatedAt"`
-	AccessedAt  time.Time      `json:"accessedAt"`
-	AccessCount int            `json:"accessCount"`
-	Relations   []Relation     `json:"relations"`
+	ID              string         `json:"id"`
+	Type            string         `json:"type"`
+	Name            string         `json:"name"`
+	Content         *string        `json:"content,omitempty"`
+	Summary         *string        `json:"summary,omitempty"`
+	Labels          []string       `json:"labels"`
+	Aliases         []string       `json:"aliases"`
+	ContentHash     *string        `json:"contentHash,omitempty"`
+	Verified        bool           `json:"verified"`
+	Confidence      float64        `json:"confidence"`
+	Source          string         `json:"source"`
+	SourcePath      *string        `json:"sourcePath,omitempty"`
+	Metadata        map[string]any `json:"metadata,omitempty"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+	ModifiedAt      time.Time      `json:"modifiedAt"`
+	AccessedAt      time.Time      `json:"accessedAt"`
+	AccessCount     int            `json:"accessCount"`
+	AlwaysInContext bool           `json:"alwaysInContext"`
+	Pinned          bool           `json:"pinned"`
+	Language        *string        `json:"language,omitempty"`
+	Context         *string        `json:"context,omitempty"`
+	Relations       []Relation     `json:"relations"`
 }
 
 // Relation represents a relationship between entities.
@@ -63,12 +69,15 @@ type ChunkMatch struct {
 
 // IngestResult summarizes an ingestion operation.
 type IngestResult struct {
-	FilesProcessed   int      `json:"filesProcessed"`
-	FilesSkipped     int      `json:"filesSkipped"`
-	EntitiesCreated  int      `json:"entitiesCreated"`
-	ChunksCreated    int      `json:"chunksCreated"`
-	RelationsCreated int      `json:"relationsCreated"`
-	Errors           []string `json:"errors"`
+	FilesProcessed   int           `json:"filesProcessed"`
+	FilesSkipped     int           `json:"filesSkipped"`
+	EntitiesCreated  int           `json:"entitiesCreated"`
+	ChunksCreated    int           `json:"chunksCreated"`
+	ChunksFailed     int           `json:"chunksFailed"`
+	RelationsCreated int           `json:"relationsCreated"`
+	EntitiesDeleted  int           `json:"entitiesDeleted"`
+	Errors           []string      `json:"errors"`
+	Changes          []*FileAction `json:"changes,omitempty"`
 }
 
 // LabelCount represents a label with its entity count.
@@ -126,6 +135,8 @@ type EntityInput struct {
 	Source     *string        `json:"source,omitempty"`
 	SourcePath *string        `json:"sourcePath,omitempty"`
 	Metadata   map[string]any `json:"metadata,omitempty"`
+	Language   *string        `json:"language,omitempty"`
+	Context    *string        `json:"context,omitempty"`
 }
 
 // EntityUpdate is the input for updating entities.
@@ -150,11 +161,26 @@ type RelationInput struct {
 
 // SearchInput is the input for search operations.
 type SearchInput struct {
-	Query        string   `json:"query"`
-	Labels       []string `json:"labels,omitempty"`
-	Types        []string `json:"types,omitempty"`
-	VerifiedOnly *bool    `json:"verifiedOnly,omitempty"`
-	Limit        *int     `json:"limit,omitempty"`
+	Query           string     `json:"query"`
+	Mode            *string    `json:"mode,omitempty"`
+	Labels          []string   `json:"labels,omitempty"`
+	LabelGroups     [][]string `json:"labelGroups,omitempty"`
+	HasMetadataKeys []string   `json:"hasMetadataKeys,omitempty"`
+	Types           []string   `json:"types,omitempty"`
+	VerifiedOnly    *bool      `json:"verifiedOnly,omitempty"`
+	MinConfidence   *float64   `json:"minConfidence,omitempty"`
+	ExcludeIds      []string   `json:"excludeIds,omitempty"`
+	Diversity       *float64   `json:"diversity,omitempty"`
+	Rerank          *bool      `json:"rerank,omitempty"`
+	ApplyDecay      *bool      `json:"applyDecay,omitempty"`
+	ExpandGraph     *bool      `json:"expandGraph,omitempty"`
+	Language        *string    `json:"language,omitempty"`
+	Context         *string    `json:"context,omitempty"`
+	ConversationID  *string    `json:"conversationId,omitempty"`
+	EfSearch        *int       `json:"efSearch,omitempty"`
+	OverFetchFactor *int       `json:"overFetchFactor,omitempty"`
+	SortBy          *string    `json:"sortBy,omitempty"`
+	Limit           *int       `json:"limit,omitempty"`
 }
 
 // IngestInput is the input for ingest operations.
@@ -164,6 +190,22 @@ type IngestInput struct {
 	// Curated labels to apply to all ingested entities
 	Labels       []string `json:"labels,omitempty"`
 	ExtractGraph *bool    `json:"extractGraph,omitempty"`
-	DryRun       *bool    `json:"dryRun,omitempty"`
-	Recursive    *bool    `json:"recursive,omitempty"`
+	// Generate a summary via LLM for long content without a frontmatter summary
+	AutoSummarize *bool `json:"autoSummarize,omitempty"`
+	DryRun        *bool `json:"dryRun,omitempty"`
+	Recursive     *bool `json:"recursive,omitempty"`
+	// Fail the job on the first file error instead of continuing (default false)
+	FailFast *bool `json:"failFast,omitempty"`
+	// Delete scraped entities under the directory whose file no longer exists
+	Prune *bool `json:"prune,omitempty"`
+	// How long content is split: heading (default), fixed or sentence
+	ChunkStrategy *string `json:"chunkStrategy,omitempty"`
+	// Chunk size in characters
+	ChunkSize *int `json:"chunkSize,omitempty"`
+	// Characters repeated between neighboring chunks
+	ChunkOverlap *int `json:"chunkOverlap,omitempty"`
+	// Skip files larger than this many bytes (negative = no limit)
+	MaxFileBytes *int `json:"maxFileBytes,omitempty"`
+	// Files processed in parallel (default: server setting, max 32)
+	Concurrency *int `json:"concurrency,omitempty"`
 }
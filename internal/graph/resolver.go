@@ -5,24 +5,39 @@ package graph
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"time"
 
 	"github.com/raphaelgruber/memcp-go/internal/config"
 	"github.com/raphaelgruber/memcp-go/internal/db"
 	"github.com/raphaelgruber/memcp-go/internal/llm"
 	"github.com/raphaelgruber/memcp-go/internal/metrics"
+	"github.com/raphaelgruber/memcp-go/internal/models"
 	"github.com/raphaelgruber/memcp-go/internal/service"
 )
 
+// backgroundIndexWait bounds the non-blocking vector index check at startup.
+const backgroundIndexWait = 5 * time.Minute
+
 // Resolver is the root resolver with all dependencies.
 type Resolver struct {
-	db            *db.Client
-	entityService *service.EntityService
-	searchService *service.SearchService
-	ingestService *service.IngestService
-	jobManager    *service.JobManager
-	cfg           config.Config
-	metrics       *metrics.Collector
+	db             *db.Client
+	entityService  *service.EntityService
+	searchService  *service.SearchService
+	conversations  *service.ConversationService
+	contradictions *service.ContradictionService
+	ingestService  *service.IngestService
+	jobManager     *service.JobManager
+	entityEvents   *service.EntityEvents
+	labels         *service.LabelNormalizer
+	answerCache    *service.AnswerCache
+	cfg            config.Config
+	metrics        *metrics.Collector
+	metricsStore   *service.MetricsPersister
+	decay          *service.DecayRunner
 }
 
 // NewResolver creates a new resolver with all dependencies.
@@ -30,6 +45,19 @@ func NewResolver(ctx context.Context, cfg config.Config) (*Resolver, error) {
 	// Create metrics collector for runtime statistics
 	mc := metrics.NewCollector()
 
+	// Refuse to start with a misspelled context mode instead of silently
+	// using another one
+	contextOpts := service.ContextOptions{
+		Mode:               cfg.ContextMode,
+		MaxChunksPerSource: cfg.ContextMaxChunks,
+		MaxCharsPerSource:  cfg.ContextMaxChars,
+		MaxAlwaysInContext: cfg.ContextMaxAlways,
+		MaxTotalChars:      cfg.ContextMaxTotal,
+	}
+	if err := contextOpts.Validate(); err != nil {
+		return nil, fmt.Errorf("KNOWHOW_CONTEXT_MODE: %w", err)
+	}
+
 	// Connect to database
 	dbCfg := db.Config{
 		URL:       cfg.SurrealDBURL,
@@ -40,21 +68,51 @@ func NewResolver(ctx context.Context, cfg config.Config) (*Resolver, error) {
 		AuthLevel: cfg.SurrealDBAuthLevel,
 	}
 
-	dbClient, err := db.NewClient(ctx, dbCfg, nil, mc)
+	dbClient, err := db.NewPool(ctx, dbCfg, cfg.SurrealDBPoolSize, nil, mc)
 	if err != nil {
 		return nil, err
 	}
 
-	// Initialize schema with configured embedding dimension
-	if err := dbClient.InitSchema(ctx, cfg.EmbedDimension); err != nil {
+	// Initialize schema with configured embedding dimension and distance
+	// metric, then apply pending migrations
+	if err := dbClient.Migrate(ctx, cfg.EmbedDimension, cfg.HNSWDistance); err != nil {
 		if closeErr := dbClient.Close(ctx); closeErr != nil {
 			slog.Warn("failed to close DB during cleanup", "error", closeErr)
 		}
 		return nil, err
 	}
 
+	// Verify the vector indexes answer queries; block only if configured
+	if cfg.IndexWaitTimeout > 0 {
+		if err := dbClient.WaitForVectorIndex(ctx, time.Duration(cfg.IndexWaitTimeout)*time.Second); err != nil {
+			slog.Warn("vector index not ready, searches may be degraded", "error", err)
+		}
+	} else {
+		go func() {
+			if err := dbClient.WaitForVectorIndex(context.Background(), backgroundIndexWait); err != nil {
+				slog.Warn("vector index not ready, searches may be degraded", "error", err)
+			}
+		}()
+	}
+
+	// With a pricing table, token usage is recorded with its cost; embedding
+	// usage is only worth a write per request then
+	pricing, err := llm.LoadPricing(cfg.ModelPricingPath)
+	if err != nil {
+		if closeErr := dbClient.Close(ctx); closeErr != nil {
+			slog.Warn("failed to close DB during cleanup", "error", closeErr)
+		}
+		return nil, err
+	}
+	var usage llm.UsageRecorder = dbClient
+	var embedUsage llm.UsageRecorder
+	if pricing != nil {
+		usage = llm.PricedUsage(dbClient, pricing)
+		embedUsage = usage
+	}
+
 	// Initialize LLM components
-	embedder, err := llm.NewEmbedder(ctx, cfg, mc)
+	embedder, err := llm.NewEmbedder(ctx, cfg, mc, embedUsage)
 	if err != nil {
 		if closeErr := dbClient.Close(ctx); closeErr != nil {
 			slog.Warn("failed to close DB during cleanup", "error", closeErr)
@@ -62,7 +120,27 @@ func NewResolver(ctx context.Context, cfg config.Config) (*Resolver, error) {
 		return nil, err
 	}
 
-	model, err := llm.NewModel(cfg, mc)
+	// A model returning the wrong dimension would fail every write, so refuse
+	// to start; an unreachable model may come up later
+	if err := embedder.Validate(ctx); err != nil {
+		if errors.Is(err, llm.ErrDimensionMismatch) {
+			if closeErr := dbClient.Close(ctx); closeErr != nil {
+				slog.Warn("failed to close DB during cleanup", "error", closeErr)
+			}
+			return nil, err
+		}
+		slog.Warn("could not validate embedding dimension", "model", cfg.EmbedModel, "error", err)
+	}
+
+	model, err := llm.NewModel(cfg, mc, usage)
+	if err != nil {
+		if closeErr := dbClient.Close(ctx); closeErr != nil {
+			slog.Warn("failed to close DB during cleanup", "error", closeErr)
+		}
+		return nil, err
+	}
+
+	reranker, err := llm.NewReranker(cfg, mc)
 	if err != nil {
 		if closeErr := dbClient.Close(ctx); closeErr != nil {
 			slog.Warn("failed to close DB during cleanup", "error", closeErr)
@@ -71,16 +149,42 @@ func NewResolver(ctx context.Context, cfg config.Config) (*Resolver, error) {
 	}
 
 	// Log configuration
-	slog.Info("embedding settings", "provider", cfg.EmbedProvider, "model", cfg.EmbedModel, "dimension", cfg.EmbedDimension)
+	slog.Info("embedding settings", "provider", cfg.EmbedProvider, "model", cfg.EmbedModel, "dimension", cfg.EmbedDimension, "cache_size", cfg.EmbedCacheSize)
 	if model != nil {
 		slog.Info("llm settings", "provider", cfg.LLMProvider, "model", cfg.LLMModel)
 	} else {
 		slog.Info("llm disabled")
 	}
-	slog.Info("ingest settings", "workers", cfg.IngestConcurrency)
+	if reranker != nil {
+		slog.Info("rerank settings", "model", cfg.RerankModel)
+	}
+	slog.Info("ingest settings", "workers", cfg.IngestConcurrency, "max_extractions", cfg.MaxConcurrentExtractions)
+	if cfg.JobWebhookURL != "" {
+		slog.Info("job webhook enabled", "signed", cfg.JobWebhookSecret != "")
+	}
+	slog.Info("context settings", "mode", cfg.ContextMode, "max_chunks", cfg.ContextMaxChunks, "max_chars", cfg.ContextMaxChars, "max_always", cfg.ContextMaxAlways, "max_total", cfg.ContextMaxTotal)
+
+	// Shared so changes from background jobs reach entityChanges subscribers
+	entityEvents := service.NewEntityEvents()
+
+	// Labels are stored as given unless normalization is enabled
+	var labels *service.LabelNormalizer
+	if cfg.NormalizeLabels {
+		labels = service.NewLabelNormalizer(cfg.LabelAliases)
+		slog.Info("label normalization enabled", "aliases", len(cfg.LabelAliases))
+	}
 
-	ingestService := service.NewIngestService(dbClient, embedder, model)
-	jobManager := service.NewJobManager(cfg.IngestConcurrency, dbClient)
+	// Answers are cached only if enabled; any entity or relation change
+	// invalidates them
+	var answerCache *service.AnswerCache
+	if cfg.AnswerCacheTTL > 0 {
+		answerCache = service.NewAnswerCache(time.Duration(cfg.AnswerCacheTTL)*time.Second, mc)
+		answerCache.Watch(entityEvents)
+		slog.Info("answer cache enabled", "ttl_seconds", cfg.AnswerCacheTTL)
+	}
+
+	ingestService := service.NewIngestService(dbClient, embedder, model, entityEvents, cfg.EntityContentLimit, cfg.EmbedTextLimit, labels, cfg.DetectLanguage, cfg.ConfidenceDefaults, cfg.MaxConcurrentExtractions, cfg.FuzzyNameThreshold, int64(cfg.IngestMaxFileBytes), cfg.IngestSkipBinaryCheck)
+	jobManager := service.NewJobManager(cfg.IngestConcurrency, dbClient, service.NewJobWebhook(cfg.JobWebhookURL, cfg.JobWebhookSecret))
 
 	// Resume any incomplete jobs from previous server run
 	if err := jobManager.ResumeIncompleteJobs(ctx, ingestService); err != nil {
@@ -88,25 +192,86 @@ func NewResolver(ctx context.Context, cfg config.Config) (*Resolver, error) {
 		slog.Warn("failed to resume incomplete jobs", "error", err)
 	}
 
+	// Periodically persist metrics so trends survive restarts
+	metricsStore := service.NewMetricsPersister(dbClient, mc, time.Duration(cfg.MetricsSnapshotInterval)*time.Second)
+	metricsStore.Start()
+
+	// Decay weights are computed from last access at query time; the runner
+	// only periodically lowers the confidence of unverified AI entities
+	decayCfg := db.DecayConfig{
+		Curve:            cfg.DecayCurve,
+		HalfLifeDays:     cfg.DecayHalfLifeDays,
+		TypeHalfLifeDays: cfg.DecayTypeHalfLifeDays,
+		MinWeight:        cfg.DecayMinWeight,
+
+		AIConfidence:             cfg.ConfidenceDefaults[string(models.SourceAIGenerated)],
+		AIConfidenceHalfLifeDays: cfg.DecayAIConfidenceDays,
+		MinConfidence:            cfg.DecayMinConfidence,
+	}
+	decay := service.NewDecayRunner(dbClient, entityEvents, decayCfg, time.Duration(cfg.DecayInterval)*time.Second)
+	decay.Start()
+
 	return &Resolver{
-		db:            dbClient,
-		entityService: service.NewEntityService(dbClient, embedder, model),
-		searchService: service.NewSearchService(dbClient, embedder, model),
-		ingestService: ingestService,
-		jobManager:    jobManager,
-		cfg:           cfg,
-		metrics:       mc,
+		db:             dbClient,
+		entityService:  service.NewEntityService(dbClient, embedder, model, entityEvents, cfg.EntityContentLimit, cfg.EmbedTextLimit, labels, cfg.DetectLanguage, cfg.ConfidenceDefaults),
+		entityEvents:   entityEvents,
+		labels:         labels,
+		answerCache:    answerCache,
+		searchService:  service.NewSearchService(dbClient, embedder, model, llm.NewModelCache(cfg, mc, usage), contextOpts, answerCache, reranker, service.NewTokenBudget(dbClient, cfg.MaxTokensPerConversation), decayCfg, labels),
+		conversations:  service.NewConversationService(dbClient, embedder),
+		contradictions: service.NewContradictionService(dbClient, model),
+		ingestService:  ingestService,
+		jobManager:     jobManager,
+		cfg:            cfg,
+		metrics:        mc,
+		metricsStore:   metricsStore,
+		decay:          decay,
 	}, nil
 }
 
 // Close closes all connections.
 func (r *Resolver) Close(ctx context.Context) error {
+	if r.decay != nil {
+		r.decay.Stop()
+	}
+	if r.metricsStore != nil {
+		if err := r.metricsStore.Stop(ctx); err != nil {
+			slog.Warn("failed to persist final metrics snapshot", "error", err)
+		}
+	}
 	if r.db != nil {
 		return r.db.Close(ctx)
 	}
 	return nil
 }
 
+// Metrics returns the runtime metrics collector.
+func (r *Resolver) Metrics() *metrics.Collector {
+	return r.metrics
+}
+
+// ExportArchive writes the whole vault to w as a newline-delimited JSON
+// archive (see db.Client.ExportAll).
+func (r *Resolver) ExportArchive(ctx context.Context, w io.Writer) error {
+	return r.db.ExportAll(ctx, w)
+}
+
+// ImportArchive imports a vault archive written by ExportArchive (see
+// db.Client.ImportAll). Cached answers are dropped, as imports bypass
+// entity change events.
+func (r *Resolver) ImportArchive(ctx context.Context, rd io.Reader, onConflict db.ConflictMode) (db.ImportStats, error) {
+	stats, err := r.db.ImportAll(ctx, rd, onConflict)
+	if stats.Imported > 0 {
+		r.answerCache.Invalidate()
+	}
+	return stats, err
+}
+
+// PingDB checks that the database is reachable (see db.Client.Ping).
+func (r *Resolver) PingDB(ctx context.Context) error {
+	return r.db.Ping(ctx)
+}
+
 // WipeData deletes all data from the database. Use for testing only.
 func (r *Resolver) WipeData(ctx context.Context) error {
 	return r.db.WipeData(ctx)
@@ -13,37 +13,40 @@ import (
 	"strings"
 	"time"
 
+	"github.com/raphaelgruber/memcp-go/internal/db"
 	"github.com/raphaelgruber/memcp-go/internal/llm"
 	"github.com/raphaelgruber/memcp-go/internal/models"
 	"github.com/raphaelgruber/memcp-go/internal/service"
 )
 
+// DecayWeight is the resolver for the decayWeight field.
+func (r *entityResolver) DecayWeight(ctx context.Context, obj *Entity) (*float64, error) {
+	weight := r.decay.Config().Weight(obj.Type, obj.AccessedAt, obj.Pinned, time.Now())
+	return &weight, nil
+}
+
 // CreateEntity is the resolver for the createEntity field.
 func (r *mutationResolver) CreateEntity(ctx context.Context, input EntityInput) (*Entity, error) {
-	// Convert GraphQL input to service input
-	modelInput := models.EntityInput{
-		Type:       input.Type,
-		Name:       input.Name,
-		Content:    input.Content,
-		Summary:    input.Summary,
-		Labels:     input.Labels,
-		Verified:   input.Verified,
-		SourcePath: input.SourcePath,
-		Metadata:   input.Metadata,
+	result, err := r.entityService.Create(ctx, entityInputToModel(input))
+	if err != nil {
+		return nil, err
 	}
 
-	// Set source if provided
-	if input.Source != nil {
-		source := models.EntitySource(*input.Source)
-		modelInput.Source = &source
+	return entityToGraphQL(result.Entity), nil
+}
+
+// CreateEntities is the resolver for the createEntities field.
+func (r *mutationResolver) CreateEntities(ctx context.Context, inputs []*EntityInput) ([]*CreateEntityResult, error) {
+	modelInputs := make([]models.EntityInput, len(inputs))
+	for i, input := range inputs {
+		modelInputs[i] = entityInputToModel(*input)
 	}
 
-	result, err := r.entityService.Create(ctx, modelInput)
+	results, err := r.entityService.CreateBatch(ctx, modelInputs)
 	if err != nil {
 		return nil, err
 	}
-
-	return entityToGraphQL(result.Entity), nil
+	return entityBatchResultsToGraphQL(results), nil
 }
 
 // UpdateEntity is the resolver for the updateEntity field.
@@ -72,6 +75,19 @@ func (r *mutationResolver) DeleteEntity(ctx context.Context, id string) (bool, e
 	return r.entityService.Delete(ctx, id)
 }
 
+// DeleteEntitiesByLabel is the resolver for the deleteEntitiesByLabel field.
+func (r *mutationResolver) DeleteEntitiesByLabel(ctx context.Context, label string) (int, error) {
+	return r.entityService.DeleteByLabel(ctx, label)
+}
+
+// ReindexEntity is the resolver for the reindexEntity field.
+func (r *mutationResolver) ReindexEntity(ctx context.Context, id string) (bool, error) {
+	if err := r.entityService.ReindexEntity(ctx, id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // CreateRelation is the resolver for the createRelation field.
 func (r *mutationResolver) CreateRelation(ctx context.Context, input RelationInput) (bool, error) {
 	modelInput := models.RelationInput{
@@ -88,21 +104,133 @@ func (r *mutationResolver) CreateRelation(ctx context.Context, input RelationInp
 	return true, nil
 }
 
+// RebuildRelationKeys is the resolver for the rebuildRelationKeys field.
+func (r *mutationResolver) RebuildRelationKeys(ctx context.Context) (int, error) {
+	return r.entityService.RebuildRelationKeys(ctx)
+}
+
+// RelinkRelations is the resolver for the relinkRelations field.
+func (r *mutationResolver) RelinkRelations(ctx context.Context) (int, error) {
+	return r.entityService.RelinkRelations(ctx)
+}
+
+// ImportRelations is the resolver for the importRelations field.
+func (r *mutationResolver) ImportRelations(ctx context.Context, csv string) (*RelationImportReport, error) {
+	report, err := r.entityService.ImportRelations(ctx, strings.NewReader(csv))
+	if err != nil {
+		return nil, err
+	}
+	return importReportToGraphQL(report), nil
+}
+
+// ApplyDecay is the resolver for the applyDecay field.
+func (r *mutationResolver) ApplyDecay(ctx context.Context) (bool, error) {
+	if err := r.decay.Run(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Compact is the resolver for the compact field.
+func (r *mutationResolver) Compact(ctx context.Context, dryRun *bool) (*CompactReport, error) {
+	dry := dryRun != nil && *dryRun
+	report, err := r.entityService.Compact(ctx, dry)
+	if err != nil {
+		return nil, err
+	}
+	return compactReportToGraphQL(report, dry), nil
+}
+
+// SetAlwaysInContext is the resolver for the setAlwaysInContext field.
+func (r *mutationResolver) SetAlwaysInContext(ctx context.Context, id string, enabled bool) (*Entity, error) {
+	entity, err := r.entityService.SetAlwaysInContext(ctx, id, enabled)
+	if err != nil {
+		return nil, err
+	}
+	return entityToGraphQL(entity), nil
+}
+
+// PinEntity is the resolver for the pinEntity field.
+func (r *mutationResolver) PinEntity(ctx context.Context, id string, pinned bool) (*Entity, error) {
+	entity, err := r.entityService.SetPinned(ctx, id, pinned)
+	if err != nil {
+		return nil, err
+	}
+	return entityToGraphQL(entity), nil
+}
+
+// AddEntityAlias is the resolver for the addEntityAlias field.
+func (r *mutationResolver) AddEntityAlias(ctx context.Context, id string, alias string) (*Entity, error) {
+	entity, err := r.entityService.AddAlias(ctx, id, alias)
+	if err != nil {
+		return nil, err
+	}
+	return entityToGraphQL(entity), nil
+}
+
+// DetectContradictions is the resolver for the detectContradictions field.
+func (r *mutationResolver) DetectContradictions(ctx context.Context, entityID string) (int, error) {
+	return r.contradictions.DetectForEntity(ctx, entityID)
+}
+
+// DetectAllContradictions is the resolver for the detectAllContradictions field.
+func (r *mutationResolver) DetectAllContradictions(ctx context.Context) (*Job, error) {
+	job, err := r.contradictions.DetectAllAsync(ctx, r.jobManager)
+	if err != nil {
+		return nil, err
+	}
+
+	return serviceJobToGraphQL(job), nil
+}
+
+// MergeEntities is the resolver for the mergeEntities field.
+func (r *mutationResolver) MergeEntities(ctx context.Context, keepID string, mergeID string) (*Entity, error) {
+	entity, err := r.entityService.Merge(ctx, keepID, mergeID)
+	if err != nil {
+		return nil, err
+	}
+	return entityToGraphQL(entity), nil
+}
+
+// DedupeEntities is the resolver for the dedupeEntities field.
+func (r *mutationResolver) DedupeEntities(ctx context.Context, threshold *float64, autoMerge *bool, cursor *string, limit *int) (*DedupeReport, error) {
+	t := service.DefaultDedupeThreshold
+	if threshold != nil {
+		t = *threshold
+	}
+	var pageCursor string
+	if cursor != nil {
+		pageCursor = *cursor
+	}
+	var pageLimit int // DB applies the default
+	if limit != nil {
+		pageLimit = *limit
+	}
+	report, err := r.entityService.Dedupe(ctx, t, autoMerge != nil && *autoMerge, pageLimit, pageCursor)
+	if err != nil {
+		return nil, err
+	}
+	return dedupeReportToGraphQL(report), nil
+}
+
+// VerifyEntities is the resolver for the verifyEntities field.
+func (r *mutationResolver) VerifyEntities(ctx context.Context, ids []string) (int, error) {
+	return r.entityService.VerifyEntities(ctx, ids)
+}
+
+// NormalizeLabels is the resolver for the normalizeLabels field.
+func (r *mutationResolver) NormalizeLabels(ctx context.Context) (int, error) {
+	return r.entityService.NormalizeAllLabels(ctx)
+}
+
+// RenameLabel is the resolver for the renameLabel field.
+func (r *mutationResolver) RenameLabel(ctx context.Context, old string, new string) (int, error) {
+	return r.entityService.RenameLabel(ctx, old, new)
+}
+
 // IngestFile is the resolver for the ingestFile field.
 func (r *mutationResolver) IngestFile(ctx context.Context, filePath string, input *IngestInput) (*Entity, error) {
-	opts := service.IngestOptions{}
-	if input != nil {
-		opts.Labels = input.Labels
-		if input.ExtractGraph != nil {
-			opts.ExtractGraph = *input.ExtractGraph
-		}
-		if input.DryRun != nil {
-			opts.DryRun = *input.DryRun
-		}
-		if input.Recursive != nil {
-			opts.Recursive = *input.Recursive
-		}
-	}
+	opts := ingestInputToOptions(input)
 
 	// Derive baseDir from parent directory for unique entity IDs
 	opts.BaseDir = filepath.Base(filepath.Dir(filePath))
@@ -117,55 +245,20 @@ func (r *mutationResolver) IngestFile(ctx context.Context, filePath string, inpu
 
 // IngestDirectory is the resolver for the ingestDirectory field.
 func (r *mutationResolver) IngestDirectory(ctx context.Context, dirPath string, input *IngestInput) (*IngestResult, error) {
-	opts := service.IngestOptions{
-		Concurrency: r.jobManager.Concurrency(),
-	}
-	if input != nil {
-		opts.Labels = input.Labels
-		if input.ExtractGraph != nil {
-			opts.ExtractGraph = *input.ExtractGraph
-		}
-		if input.DryRun != nil {
-			opts.DryRun = *input.DryRun
-		}
-		if input.Recursive != nil {
-			opts.Recursive = *input.Recursive
-		}
-	}
+	opts := ingestInputToOptions(input)
+	opts.Concurrency = r.jobManager.Concurrency()
 
 	result, err := r.ingestService.IngestDirectory(ctx, dirPath, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return &IngestResult{
-		FilesProcessed:   result.FilesProcessed,
-		FilesSkipped:     result.FilesSkipped,
-		EntitiesCreated:  result.EntitiesCreated,
-		ChunksCreated:    result.ChunksCreated,
-		RelationsCreated: result.RelationsCreated,
-		Errors:           result.Errors,
-	}, nil
+	return ingestResultToGraphQL(result), nil
 }
 
 // IngestDirectoryAsync is the resolver for the ingestDirectoryAsync field.
 func (r *mutationResolver) IngestDirectoryAsync(ctx context.Context, dirPath string, input *IngestInput) (*Job, error) {
-	opts := service.IngestOptions{}
-	if input != nil {
-		if input.Name != nil {
-			opts.Name = *input.Name
-		}
-		opts.Labels = input.Labels
-		if input.ExtractGraph != nil {
-			opts.ExtractGraph = *input.ExtractGraph
-		}
-		if input.DryRun != nil {
-			opts.DryRun = *input.DryRun
-		}
-		if input.Recursive != nil {
-			opts.Recursive = *input.Recursive
-		}
-	}
+	opts := ingestInputToOptions(input)
 
 	job, err := r.ingestService.IngestDirectoryAsync(ctx, r.jobManager, dirPath, opts)
 	if err != nil {
@@ -175,6 +268,15 @@ func (r *mutationResolver) IngestDirectoryAsync(ctx context.Context, dirPath str
 	return serviceJobToGraphQL(job), nil
 }
 
+// IngestURL is the resolver for the ingestURL field.
+func (r *mutationResolver) IngestURL(ctx context.Context, url string, input *IngestInput) (*Entity, error) {
+	result, err := r.ingestService.IngestURL(ctx, url, ingestInputToOptions(input))
+	if err != nil {
+		return nil, err
+	}
+	return entityToGraphQL(result.Entity), nil
+}
+
 // CreateTemplate is the resolver for the createTemplate field.
 func (r *mutationResolver) CreateTemplate(ctx context.Context, name string, description *string, content string) (*Template, error) {
 	input := models.TemplateInput{
@@ -198,69 +300,24 @@ func (r *mutationResolver) DeleteTemplate(ctx context.Context, name string) (boo
 
 // IngestFiles is the resolver for the ingestFiles field.
 func (r *mutationResolver) IngestFiles(ctx context.Context, input IngestFilesInput) (*IngestResult, error) {
-	opts := service.IngestOptions{
-		Concurrency: r.jobManager.Concurrency(),
-	}
-	if input.Options != nil {
-		opts.Labels = input.Options.Labels
-		if input.Options.ExtractGraph != nil {
-			opts.ExtractGraph = *input.Options.ExtractGraph
-		}
-		if input.Options.DryRun != nil {
-			opts.DryRun = *input.Options.DryRun
-		}
-	}
+	opts := ingestInputToOptions(input.Options)
+	opts.Concurrency = r.jobManager.Concurrency()
 
-	// Convert GraphQL input to service types
-	files := make([]service.FileContent, len(input.Files))
-	for i, f := range input.Files {
-		files[i] = service.FileContent{
-			Path:    f.Path,
-			Content: f.Content,
-			Hash:    f.Hash,
-		}
-	}
+	files := fileContentInputsToService(input.Files)
 
 	result, err := r.ingestService.IngestFilesWithContent(ctx, files, input.BaseDir, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return &IngestResult{
-		FilesProcessed:   result.FilesProcessed,
-		FilesSkipped:     result.FilesSkipped,
-		EntitiesCreated:  result.EntitiesCreated,
-		ChunksCreated:    result.ChunksCreated,
-		RelationsCreated: result.RelationsCreated,
-		Errors:           result.Errors,
-	}, nil
+	return ingestResultToGraphQL(result), nil
 }
 
 // IngestFilesAsync is the resolver for the ingestFilesAsync field.
 func (r *mutationResolver) IngestFilesAsync(ctx context.Context, input IngestFilesInput) (*Job, error) {
-	opts := service.IngestOptions{}
-	if input.Options != nil {
-		if input.Options.Name != nil {
-			opts.Name = *input.Options.Name
-		}
-		opts.Labels = input.Options.Labels
-		if input.Options.ExtractGraph != nil {
-			opts.ExtractGraph = *input.Options.ExtractGraph
-		}
-		if input.Options.DryRun != nil {
-			opts.DryRun = *input.Options.DryRun
-		}
-	}
+	opts := ingestInputToOptions(input.Options)
 
-	// Convert GraphQL input to service types
-	files := make([]service.FileContent, len(input.Files))
-	for i, f := range input.Files {
-		files[i] = service.FileContent{
-			Path:    f.Path,
-			Content: f.Content,
-			Hash:    f.Hash,
-		}
-	}
+	files := fileContentInputsToService(input.Files)
 
 	job, err := r.ingestService.IngestFilesWithContentAsync(ctx, r.jobManager, files, input.BaseDir, opts)
 	if err != nil {
@@ -270,6 +327,30 @@ func (r *mutationResolver) IngestFilesAsync(ctx context.Context, input IngestFil
 	return serviceJobToGraphQL(job), nil
 }
 
+// GenerateMissingSummaries is the resolver for the generateMissingSummaries field.
+func (r *mutationResolver) GenerateMissingSummaries(ctx context.Context) (*Job, error) {
+	job, err := r.ingestService.GenerateMissingSummariesAsync(ctx, r.jobManager)
+	if err != nil {
+		return nil, err
+	}
+
+	return serviceJobToGraphQL(job), nil
+}
+
+// SummarizeEntity is the resolver for the summarizeEntity field.
+func (r *mutationResolver) SummarizeEntity(ctx context.Context, id string) (*Entity, error) {
+	entity, err := r.ingestService.SummarizeEntity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return entityToGraphQL(entity), nil
+}
+
+// CancelJob is the resolver for the cancelJob field.
+func (r *mutationResolver) CancelJob(ctx context.Context, id string) (bool, error) {
+	return r.jobManager.CancelJob(id), nil
+}
+
 // UpdateEntityContent is the resolver for the updateEntityContent field.
 func (r *mutationResolver) UpdateEntityContent(ctx context.Context, id string, content string) (*Entity, error) {
 	entity, err := r.entityService.UpdateContent(ctx, id, content)
@@ -299,6 +380,14 @@ func (r *mutationResolver) DeleteConversation(ctx context.Context, id string) (b
 	return r.db.DeleteConversation(ctx, id)
 }
 
+// ResetServerStats is the resolver for the resetServerStats field.
+func (r *mutationResolver) ResetServerStats(ctx context.Context) (bool, error) {
+	if err := r.metricsStore.Reset(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // Entity is the resolver for the entity field.
 func (r *queryResolver) Entity(ctx context.Context, id string) (*Entity, error) {
 	entity, err := r.entityService.Get(ctx, id)
@@ -334,18 +423,49 @@ func (r *queryResolver) EntityByName(ctx context.Context, name string) (*Entity,
 }
 
 // Entities is the resolver for the entities field.
-func (r *queryResolver) Entities(ctx context.Context, typeArg *string, labels []string, limit *int) ([]*Entity, error) {
-	entityType := ""
+func (r *queryResolver) Entities(ctx context.Context, typeArg *string, labels []string, hasMetadataKeys []string, context *string, limit *int) ([]*Entity, error) {
+	opts := db.ListOptions{
+		Labels:          r.labels.Normalize(labels),
+		HasMetadataKeys: hasMetadataKeys,
+		Context:         context,
+		Limit:           50,
+	}
 	if typeArg != nil {
-		entityType = *typeArg
+		opts.Type = *typeArg
+	}
+	if limit != nil {
+		opts.Limit = *limit
+	}
+
+	entities, err := r.db.ListEntities(ctx, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	lim := 50
+	result := make([]*Entity, len(entities))
+	for i := range entities {
+		result[i] = entityToGraphQL(&entities[i])
+	}
+	return result, nil
+}
+
+// ReviewQueue is the resolver for the reviewQueue field.
+func (r *queryResolver) ReviewQueue(ctx context.Context, priority *string, sources []string, types []string, limit *int, offset *int) ([]*Entity, error) {
+	opts := db.ReviewQueueOptions{
+		Sources: sources,
+		Types:   types,
+	}
+	if priority != nil {
+		opts.Priority = *priority
+	}
 	if limit != nil {
-		lim = *limit
+		opts.Limit = *limit
+	}
+	if offset != nil {
+		opts.Offset = *offset
 	}
 
-	entities, err := r.db.ListEntities(ctx, entityType, labels, lim)
+	entities, err := r.db.GetReviewQueue(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -357,20 +477,109 @@ func (r *queryResolver) Entities(ctx context.Context, typeArg *string, labels []
 	return result, nil
 }
 
-// Search is the resolver for the search field.
-func (r *queryResolver) Search(ctx context.Context, input SearchInput) ([]*EntitySearchResult, error) {
-	opts := service.SearchOptions{
-		Query:  input.Query,
-		Labels: input.Labels,
-		Types:  input.Types,
+// ChangedEntities is the resolver for the changedEntities field.
+func (r *queryResolver) ChangedEntities(ctx context.Context, since string, cursor *string, limit *int) (*EntityPage, error) {
+	sinceTime, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return nil, fmt.Errorf("invalid since (want RFC 3339): %w", err)
+	}
+	var pageCursor string
+	if cursor != nil {
+		pageCursor = *cursor
 	}
-	if input.VerifiedOnly != nil {
-		opts.VerifiedOnly = *input.VerifiedOnly
+	var pageLimit int // DB applies the default
+	if limit != nil {
+		pageLimit = *limit
+	}
+
+	page, err := r.db.GetEntitiesModifiedSince(ctx, sinceTime, pageLimit, pageCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EntityPage{Entities: make([]*Entity, len(page.Entities))}
+	for i := range page.Entities {
+		result.Entities[i] = entityToGraphQL(&page.Entities[i])
+	}
+	if page.NextCursor != "" {
+		result.NextCursor = &page.NextCursor
+	}
+	return result, nil
+}
+
+// AllPaths is the resolver for the allPaths field.
+func (r *queryResolver) AllPaths(ctx context.Context, fromID string, toID string, maxDepth *int, maxPaths *int) ([][]*PathStep, error) {
+	depth, limit := 0, 0 // Service applies defaults
+	if maxDepth != nil {
+		depth = *maxDepth
 	}
-	if input.Limit != nil {
-		opts.Limit = *input.Limit
+	if maxPaths != nil {
+		limit = *maxPaths
 	}
 
+	paths, err := r.entityService.FindAllPaths(ctx, fromID, toID, depth, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([][]*PathStep, len(paths))
+	for i, path := range paths {
+		result[i] = pathToGraphQL(path)
+	}
+	return result, nil
+}
+
+// Neighbors is the resolver for the neighbors field.
+func (r *queryResolver) Neighbors(ctx context.Context, id string, depth *int, relTypes []string) ([]*Neighbor, error) {
+	hops := 1
+	if depth != nil {
+		hops = *depth
+	}
+	// Fan-out grows with every hop, so depth is capped
+	hops = min(hops, r.cfg.MaxNeighborDepth)
+
+	neighbors, err := r.entityService.Neighbors(ctx, id, hops, relTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Neighbor, len(neighbors))
+	for i := range neighbors {
+		result[i] = neighborToGraphQL(&neighbors[i])
+	}
+	return result, nil
+}
+
+// GraphAnalytics is the resolver for the graphAnalytics field.
+func (r *queryResolver) GraphAnalytics(ctx context.Context) (*GraphAnalytics, error) {
+	analytics, err := r.entityService.GraphAnalytics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphAnalyticsToGraphQL(analytics), nil
+}
+
+// ExportGraph is the resolver for the exportGraph field.
+func (r *queryResolver) ExportGraph(ctx context.Context, rootID *string, depth *int) (*GraphExport, error) {
+	root, hops := "", 0 // Service exports the whole graph without a root
+	if rootID != nil {
+		root = *rootID
+	}
+	if depth != nil {
+		hops = *depth
+	}
+
+	export, err := r.entityService.ExportGraph(ctx, root, hops)
+	if err != nil {
+		return nil, err
+	}
+	return graphExportToGraphQL(export), nil
+}
+
+// Search is the resolver for the search field.
+func (r *queryResolver) Search(ctx context.Context, input SearchInput) ([]*EntitySearchResult, error) {
+	opts := searchInputToOptions(&input)
+
 	results, err := r.searchService.SearchWithChunks(ctx, opts)
 	if err != nil {
 		return nil, err
@@ -383,26 +592,86 @@ func (r *queryResolver) Search(ctx context.Context, input SearchInput) ([]*Entit
 	return gqlResults, nil
 }
 
+// SearchChunks is the resolver for the searchChunks field.
+func (r *queryResolver) SearchChunks(ctx context.Context, input SearchInput) ([]*ChunkSearchResult, error) {
+	opts := searchInputToOptions(&input)
+
+	matches, err := r.searchService.SearchChunks(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	gqlResults := make([]*ChunkSearchResult, len(matches))
+	for i := range matches {
+		gqlResults[i] = chunkSearchResultToGraphQL(&matches[i])
+	}
+	return gqlResults, nil
+}
+
+// SearchFaceted is the resolver for the searchFaceted field.
+func (r *queryResolver) SearchFaceted(ctx context.Context, input SearchInput) (*FacetedSearchResult, error) {
+	opts := searchInputToOptions(&input)
+
+	faceted, err := r.searchService.SearchFaceted(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return facetedResultsToGraphQL(faceted), nil
+}
+
 // Ask is the resolver for the ask field.
-func (r *queryResolver) Ask(ctx context.Context, query string, input *SearchInput, templateName *string) (string, error) {
-	opts := service.SearchOptions{}
-	if input != nil {
-		opts.Query = input.Query
-		opts.Labels = input.Labels
-		opts.Types = input.Types
-		if input.VerifiedOnly != nil {
-			opts.VerifiedOnly = *input.VerifiedOnly
-		}
-		if input.Limit != nil {
-			opts.Limit = *input.Limit
-		}
+func (r *queryResolver) Ask(ctx context.Context, query string, input *SearchInput, templateName *string, provider *string, model *string, systemPrompt *string, promptPreset *string) (string, error) {
+	opts := searchInputToOptions(input)
+	if provider != nil {
+		opts.Provider = *provider
+	}
+	if model != nil {
+		opts.Model = *model
+	}
+	if systemPrompt != nil {
+		opts.SystemPrompt = *systemPrompt
+	}
+	if promptPreset != nil {
+		opts.PromptPreset = *promptPreset
 	}
 
 	if templateName != nil && *templateName != "" {
+		if opts.SystemPrompt != "" || opts.PromptPreset != "" {
+			return "", fmt.Errorf("system prompts can't be combined with templates")
+		}
 		return r.searchService.AskWithTemplate(ctx, query, *templateName, opts)
 	}
 
-	return r.searchService.Ask(ctx, query, opts)
+	answer, err := r.searchService.Ask(ctx, query, opts)
+	return answer, askError(err)
+}
+
+// AskBatch is the resolver for the askBatch field.
+func (r *queryResolver) AskBatch(ctx context.Context, questions []string, input *SearchInput) ([]*BatchAnswer, error) {
+	answers, err := r.searchService.AskBatch(ctx, questions, searchInputToOptions(input), r.jobManager.Concurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*BatchAnswer, len(answers))
+	for i, a := range answers {
+		result[i] = batchAnswerToGraphQL(a)
+	}
+	return result, nil
+}
+
+// PreviewChunks is the resolver for the previewChunks field.
+func (r *queryResolver) PreviewChunks(ctx context.Context, content string, options *ChunkOptionsInput) ([]*ChunkPreview, error) {
+	chunks, err := r.entityService.PreviewChunks(ctx, content, chunkOptionsFromGraphQL(options))
+	if err != nil {
+		return nil, err
+	}
+
+	previews := make([]*ChunkPreview, len(chunks))
+	for i, c := range chunks {
+		previews[i] = chunkPreviewToGraphQL(c)
+	}
+	return previews, nil
 }
 
 // Labels is the resolver for the labels field.
@@ -491,9 +760,40 @@ func (r *queryResolver) UsageSummary(ctx context.Context, since string) (*TokenU
 	}, nil
 }
 
+// MostUsefulSources is the resolver for the mostUsefulSources field.
+func (r *queryResolver) MostUsefulSources(ctx context.Context, since string, limit *int) ([]*AnswerSourceStats, error) {
+	lim := 20
+	if limit != nil {
+		lim = *limit
+	}
+
+	sources, err := r.searchService.MostUsefulSources(ctx, since, lim)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*AnswerSourceStats, len(sources))
+	for i, src := range sources {
+		result[i] = usefulSourceToGraphQL(src)
+	}
+	return result, nil
+}
+
 // Jobs is the resolver for the jobs field.
-func (r *queryResolver) Jobs(ctx context.Context) ([]*Job, error) {
-	jobs := r.jobManager.ListJobs()
+func (r *queryResolver) Jobs(ctx context.Context, status *string, limit *int, offset *int) ([]*Job, error) {
+	var pageLimit, pageOffset int
+	if limit != nil {
+		pageLimit = *limit
+	}
+	if offset != nil {
+		pageOffset = *offset
+	}
+
+	jobs, err := r.jobManager.ListJobsPaged(ctx, status, pageLimit, pageOffset)
+	if err != nil {
+		return nil, err
+	}
+
 	result := make([]*Job, len(jobs))
 	for i, j := range jobs {
 		result[i] = serviceJobToGraphQL(j)
@@ -536,7 +836,37 @@ func (r *queryResolver) JobByName(ctx context.Context, name string) (*Job, error
 // ServerStats is the resolver for the serverStats field.
 func (r *queryResolver) ServerStats(ctx context.Context) (*ServerStats, error) {
 	snap := r.metrics.Snapshot()
-	return metricsSnapshotToGraphQL(snap), nil
+	stats := metricsSnapshotToGraphQL(snap)
+	stats.VectorIndex = r.db.IndexStatus()
+	stats.DbConnections = dbConnectionStatsToGraphQL(r.db.ConnectionStats())
+	return stats, nil
+}
+
+// MetricsHistory is the resolver for the metricsHistory field.
+func (r *queryResolver) MetricsHistory(ctx context.Context, since string) ([]*MetricsSnapshot, error) {
+	records, err := r.db.GetMetricsSnapshots(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*MetricsSnapshot, len(records))
+	for i, rec := range records {
+		result[i] = metricsRecordToGraphQL(rec)
+	}
+	return result, nil
+}
+
+// DecayConfig is the resolver for the decayConfig field.
+func (r *queryResolver) DecayConfig(ctx context.Context) (*DecayConfig, error) {
+	typeCounts, err := r.db.ListTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	types := make([]string, len(typeCounts))
+	for i, tc := range typeCounts {
+		types[i] = tc.Type
+	}
+	return decayConfigToGraphQL(r.decay.Config(), r.decay.Interval(), types), nil
 }
 
 // CheckHashes is the resolver for the checkHashes field.
@@ -561,14 +891,39 @@ func (r *queryResolver) CheckHashes(ctx context.Context, input CheckHashesInput)
 	}, nil
 }
 
+// IngestDiff is the resolver for the ingestDiff field.
+func (r *queryResolver) IngestDiff(ctx context.Context, dirPath string, input *IngestInput) (*IngestDiff, error) {
+	diff, err := r.ingestService.DiffIngest(ctx, dirPath, ingestInputToOptions(input))
+	if err != nil {
+		return nil, err
+	}
+	return ingestDiffToGraphQL(diff), nil
+}
+
+// IngestFilesDiff is the resolver for the ingestFilesDiff field.
+func (r *queryResolver) IngestFilesDiff(ctx context.Context, input IngestFilesInput) (*IngestDiff, error) {
+	files := fileContentInputsToService(input.Files)
+	diff, err := r.ingestService.DiffFilesWithContent(ctx, files, input.BaseDir, ingestInputToOptions(input.Options))
+	if err != nil {
+		return nil, err
+	}
+	return ingestDiffToGraphQL(diff), nil
+}
+
 // Conversations is the resolver for the conversations field.
-func (r *queryResolver) Conversations(ctx context.Context, limit *int) ([]*Conversation, error) {
-	lim := 50
+func (r *queryResolver) Conversations(ctx context.Context, limit *int, offset *int, createdAfter *time.Time, createdBefore *time.Time, sort *string) ([]*Conversation, error) {
+	opts := db.ConversationListOptions{CreatedAfter: createdAfter, CreatedBefore: createdBefore}
 	if limit != nil {
-		lim = *limit
+		opts.Limit = *limit
+	}
+	if offset != nil {
+		opts.Offset = *offset
+	}
+	if sort != nil {
+		opts.Sort = *sort
 	}
 
-	convs, err := r.db.ListConversations(ctx, lim)
+	convs, err := r.db.ListConversations(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -580,6 +935,11 @@ func (r *queryResolver) Conversations(ctx context.Context, limit *int) ([]*Conve
 	return result, nil
 }
 
+// ConversationCount is the resolver for the conversationCount field.
+func (r *queryResolver) ConversationCount(ctx context.Context, createdAfter *time.Time, createdBefore *time.Time) (int, error) {
+	return r.db.CountConversations(ctx, db.ConversationListOptions{CreatedAfter: createdAfter, CreatedBefore: createdBefore})
+}
+
 // Conversation is the resolver for the conversation field.
 func (r *queryResolver) Conversation(ctx context.Context, id string) (*Conversation, error) {
 	conv, err := r.db.GetConversation(ctx, id)
@@ -603,28 +963,46 @@ func (r *queryResolver) Conversation(ctx context.Context, id string) (*Conversat
 	return conversationToGraphQL(conv, gqlMsgs), nil
 }
 
+// SearchMessages is the resolver for the searchMessages field.
+func (r *queryResolver) SearchMessages(ctx context.Context, query string, limit *int) ([]*MessageSearchResult, error) {
+	lim := 10
+	if limit != nil {
+		lim = *limit
+	}
+
+	results, err := r.conversations.SearchMessages(ctx, query, lim)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*MessageSearchResult, len(results))
+	for i := range results {
+		out[i] = messageSearchResultToGraphQL(&results[i])
+	}
+	return out, nil
+}
+
 // AskStream is the resolver for the askStream field.
-func (r *subscriptionResolver) AskStream(ctx context.Context, query string, input *SearchInput, templateName *string) (<-chan *AskStreamEvent, error) {
+func (r *subscriptionResolver) AskStream(ctx context.Context, query string, input *SearchInput, templateName *string, provider *string, model *string, systemPrompt *string, promptPreset *string) (<-chan *AskStreamEvent, error) {
 	// Template-based streaming not yet implemented
 	if templateName != nil {
 		return nil, fmt.Errorf("streaming with templates not yet supported, use regular ask query")
 	}
 
 	// Convert GraphQL input to service options
-	opts := service.SearchOptions{Query: query}
-	if input != nil {
-		if len(input.Labels) > 0 {
-			opts.Labels = input.Labels
-		}
-		if len(input.Types) > 0 {
-			opts.Types = input.Types
-		}
-		if input.VerifiedOnly != nil {
-			opts.VerifiedOnly = *input.VerifiedOnly
-		}
-		if input.Limit != nil {
-			opts.Limit = *input.Limit
-		}
+	opts := searchInputToOptions(input)
+	opts.Query = query
+	if provider != nil {
+		opts.Provider = *provider
+	}
+	if model != nil {
+		opts.Model = *model
+	}
+	if systemPrompt != nil {
+		opts.SystemPrompt = *systemPrompt
+	}
+	if promptPreset != nil {
+		opts.PromptPreset = *promptPreset
 	}
 
 	// Create channel for streaming events (buffered to avoid blocking LLM)
@@ -672,7 +1050,7 @@ func (r *subscriptionResolver) AskStream(ctx context.Context, query string, inpu
 // ChatStream is the resolver for the chatStream field.
 func (r *subscriptionResolver) ChatStream(ctx context.Context, conversationID string, message string, history []*ChatMessageInput, input *SearchInput) (<-chan *AskStreamEvent, error) {
 	// Save user message to DB
-	if _, err := r.db.CreateMessage(ctx, conversationID, "user", message); err != nil {
+	if _, err := r.conversations.AddMessage(ctx, conversationID, "user", message); err != nil {
 		return nil, fmt.Errorf("save user message: %w", err)
 	}
 
@@ -688,21 +1066,9 @@ func (r *subscriptionResolver) ChatStream(ctx context.Context, conversationID st
 	}
 
 	// Build search options
-	opts := service.SearchOptions{Query: message}
-	if input != nil {
-		if len(input.Labels) > 0 {
-			opts.Labels = input.Labels
-		}
-		if len(input.Types) > 0 {
-			opts.Types = input.Types
-		}
-		if input.VerifiedOnly != nil {
-			opts.VerifiedOnly = *input.VerifiedOnly
-		}
-		if input.Limit != nil {
-			opts.Limit = *input.Limit
-		}
-	}
+	opts := searchInputToOptions(input)
+	opts.Query = message
+	opts.ConversationID = conversationID
 
 	eventChan := make(chan *AskStreamEvent, 100)
 
@@ -731,8 +1097,8 @@ func (r *subscriptionResolver) ChatStream(ctx context.Context, conversationID st
 		// Save assistant response to DB (best-effort, use detached context
 		// since the streaming ctx may already be Done after client received all tokens)
 		if err == nil && fullResponse.Len() > 0 {
-			saveCtx, saveCancel := context.WithTimeout(context.Background(), 5*time.Second)
-			if _, dbErr := r.db.CreateMessage(saveCtx, conversationID, "assistant", fullResponse.String()); dbErr != nil {
+			saveCtx, saveCancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if _, dbErr := r.conversations.AddMessage(saveCtx, conversationID, "assistant", fullResponse.String()); dbErr != nil {
 				slog.Warn("failed to save assistant message", "conversation", conversationID, "error", dbErr)
 			}
 			saveCancel()
@@ -756,6 +1122,104 @@ func (r *subscriptionResolver) ChatStream(ctx context.Context, conversationID st
 	return eventChan, nil
 }
 
+// SearchStream is the resolver for the searchStream field.
+func (r *subscriptionResolver) SearchStream(ctx context.Context, input SearchInput) (<-chan *SearchStreamEvent, error) {
+	opts := searchInputToOptions(&input)
+
+	eventChan := make(chan *SearchStreamEvent, 100)
+
+	go func() {
+		defer close(eventChan)
+
+		err := r.searchService.SearchStream(ctx, opts, func(result models.EntitySearchResult) error {
+			select {
+			case eventChan <- &SearchStreamEvent{Result: searchResultToGraphQL(&result)}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+
+		// Send completion event
+		event := &SearchStreamEvent{Done: true}
+		if err != nil {
+			errMsg := err.Error()
+			event.Error = &errMsg
+		}
+		select {
+		case eventChan <- event:
+		case <-ctx.Done():
+		}
+	}()
+
+	return eventChan, nil
+}
+
+// EntityChanges is the resolver for the entityChanges field.
+func (r *subscriptionResolver) EntityChanges(ctx context.Context, labels []string) (<-chan *EntityChangeEvent, error) {
+	events, unsubscribe := r.entityEvents.Subscribe(labels)
+	eventChan := make(chan *EntityChangeEvent, 16)
+
+	go func() {
+		defer close(eventChan)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-events:
+				entity := entityToGraphQL(event.Entity)
+				if entity == nil {
+					continue
+				}
+				select {
+				case eventChan <- &EntityChangeEvent{Type: event.Type, Entity: entity}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return eventChan, nil
+}
+
+// JobProgress is the resolver for the jobProgress field.
+func (r *subscriptionResolver) JobProgress(ctx context.Context, id string) (<-chan *JobProgressEvent, error) {
+	updates, unsubscribe, err := r.jobManager.WatchJob(id)
+	if err != nil {
+		return nil, err
+	}
+	eventChan := make(chan *JobProgressEvent, 1)
+
+	go func() {
+		defer close(eventChan)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case update, ok := <-updates:
+				if !ok {
+					return // job finished
+				}
+				select {
+				case eventChan <- jobProgressToGraphQL(update):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return eventChan, nil
+}
+
+// Entity returns EntityResolver implementation.
+func (r *Resolver) Entity() EntityResolver { return &entityResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
@@ -765,6 +1229,7 @@ func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 // Subscription returns SubscriptionResolver implementation.
 func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
 
+type entityResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 type subscriptionResolver struct{ *Resolver }
@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/raphaelgruber/memcp-go/internal/metrics"
+)
+
+// EmbedCache keeps the most recently used embeddings, so identical text
+// (overlapping ingests, repeated queries) isn't embedded again. Entries are
+// keyed by model and text, so switching models never returns stale vectors.
+// A nil *EmbedCache caches nothing.
+type EmbedCache struct {
+	size    int
+	metrics *metrics.Collector // may be nil
+
+	mu      sync.Mutex
+	order   *list.List // most recently used first; values are *embedCacheEntry
+	entries map[embedCacheKey]*list.Element
+}
+
+// embedCacheKey is the SHA256 of model name and text.
+type embedCacheKey [sha256.Size]byte
+
+type embedCacheEntry struct {
+	key    embedCacheKey
+	vector []float32
+}
+
+// NewEmbedCache creates a cache holding up to size embeddings. Returns nil
+// (caching disabled) if size is not positive. Hits and misses are recorded
+// in mc, which may be nil.
+func NewEmbedCache(size int, mc *metrics.Collector) *EmbedCache {
+	if size <= 0 {
+		return nil
+	}
+	return &EmbedCache{
+		size:    size,
+		metrics: mc,
+		order:   list.New(),
+		entries: make(map[embedCacheKey]*list.Element),
+	}
+}
+
+// newEmbedCacheKey hashes model and text, separated so that no model/text
+// pair collides with another.
+func newEmbedCacheKey(model, text string) embedCacheKey {
+	h := sha256.New()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+	var key embedCacheKey
+	copy(key[:], h.Sum(nil))
+	return key
+}
+
+// get returns the cached embedding of text by model. The returned slice is
+// shared and must not be modified.
+func (c *EmbedCache) get(model, text string) ([]float32, bool) {
+	if c == nil {
+		return nil, false
+	}
+	key := newEmbedCacheKey(model, text)
+
+	var vector []float32
+	c.mu.Lock()
+	elem, ok := c.entries[key]
+	if ok {
+		c.order.MoveToFront(elem)
+		vector = elem.Value.(*embedCacheEntry).vector
+	}
+	c.mu.Unlock()
+
+	if c.metrics != nil {
+		c.metrics.RecordEmbedCacheLookup(ok)
+	}
+	return vector, ok
+}
+
+// put stores the embedding of text by model, evicting the least recently
+// used entry when full.
+func (c *EmbedCache) put(model, text string, vector []float32) {
+	if c == nil {
+		return
+	}
+	key := newEmbedCacheKey(model, text)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		elem.Value.(*embedCacheEntry).vector = vector
+		return
+	}
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*embedCacheEntry).key)
+	}
+	c.entries[key] = c.order.PushFront(&embedCacheEntry{key: key, vector: vector})
+}
+
+// Len returns the number of cached embeddings.
+func (c *EmbedCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
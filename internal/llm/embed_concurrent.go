@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/tmc/langchaingo/embeddings"
+)
+
+// concurrentEmbedder embeds each text of a batch in its own request, running
+// up to a fixed number at once. It's for providers without batch input, like
+// Ollama, whose client otherwise sends one request after another.
+type concurrentEmbedder struct {
+	embeddings.Embedder
+	sem chan struct{}
+}
+
+// newConcurrentEmbedder wraps model to run up to concurrency requests at
+// once. Returns model as is if concurrency is below 2.
+func newConcurrentEmbedder(model embeddings.Embedder, concurrency int) embeddings.Embedder {
+	if concurrency < 2 {
+		return model
+	}
+	return &concurrentEmbedder{Embedder: model, sem: make(chan struct{}, concurrency)}
+}
+
+// EmbedDocuments implements embeddings.Embedder. Embedding i belongs to text
+// i. The first failing request cancels the others and fails the batch.
+func (c *concurrentEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) <= 1 {
+		return c.Embedder.EmbedDocuments(ctx, texts)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	vectors := make([][]float32, len(texts))
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i, text := range texts {
+		if !c.acquire(ctx) {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-c.sem }()
+
+			embedded, err := c.Embedder.EmbedDocuments(ctx, []string{text})
+			if err == nil && len(embedded) != 1 {
+				err = fmt.Errorf("got %d embeddings for one text", len(embedded))
+			}
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			vectors[i] = embedded[0]
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// acquire takes a request slot, or returns false if ctx ends first.
+func (c *concurrentEmbedder) acquire(ctx context.Context) bool {
+	select {
+	case c.sem <- struct{}{}:
+		if ctx.Err() != nil {
+			<-c.sem
+			return false
+		}
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
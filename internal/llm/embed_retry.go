@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"syscall"
+)
+
+// transientStatuses are HTTP status codes of responses likely to succeed
+// when the request is repeated: timeouts and unavailable or overloaded
+// servers.
+var transientStatuses = []int{
+	http.StatusRequestTimeout,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// attemptOutcome records the HTTP status or transport error of the requests
+// made for one embedding attempt. Provider clients reduce failures to error
+// messages, so the outcome keeps them typed for isTransientError. Requests
+// of one attempt can run concurrently (see concurrentEmbedder).
+type attemptOutcome struct {
+	mu        sync.Mutex
+	status    int   // Status of the last failed response, 0 if none
+	transport error // Error of the last request without a response
+}
+
+// record stores the outcome of one HTTP request.
+func (o *attemptOutcome) record(resp *http.Response, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	switch {
+	case err != nil:
+		o.transport = err
+	case resp.StatusCode >= http.StatusBadRequest:
+		o.status = resp.StatusCode
+	}
+}
+
+// result returns the recorded status and transport error.
+func (o *attemptOutcome) result() (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.status, o.transport
+}
+
+type attemptOutcomeKey struct{}
+
+// withAttemptOutcome returns a context whose HTTP requests, sent with a
+// client from newEmbedHTTPClient, are recorded in outcome.
+func withAttemptOutcome(ctx context.Context, outcome *attemptOutcome) context.Context {
+	return context.WithValue(ctx, attemptOutcomeKey{}, outcome)
+}
+
+// outcomeTransport records each request in the attemptOutcome of its
+// context, if any.
+type outcomeTransport struct {
+	base http.RoundTripper
+}
+
+func (t outcomeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if outcome, ok := req.Context().Value(attemptOutcomeKey{}).(*attemptOutcome); ok {
+		outcome.record(resp, err)
+	}
+	return resp, err
+}
+
+// newEmbedHTTPClient returns the HTTP client of the embedding providers,
+// recording request outcomes for retry decisions.
+func newEmbedHTTPClient() *http.Client {
+	return &http.Client{Transport: outcomeTransport{base: http.DefaultTransport}}
+}
+
+// isTransientError reports whether a failed request is worth repeating:
+// the server answered with a transient status (see transientStatuses), or
+// it couldn't be reached or dropped the connection. The status comes from
+// outcome or, for AWS SDK errors, from the error itself. Fatal API errors
+// (auth, billing) and other client errors are not transient.
+func isTransientError(err error, outcome *attemptOutcome) bool {
+	if err == nil || isFatalAPIError(err) {
+		return false
+	}
+
+	status, transport := outcome.result()
+	if status == 0 {
+		var httpErr interface{ HTTPStatusCode() int }
+		if errors.As(err, &httpErr) {
+			status = httpErr.HTTPStatusCode()
+		}
+	}
+	if status != 0 {
+		return slices.Contains(transientStatuses, status)
+	}
+	return isNetworkError(err) || isNetworkError(transport)
+}
+
+// isNetworkError reports whether err is a failure to reach a server or to
+// complete an exchange with it.
+func isNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
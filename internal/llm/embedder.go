@@ -3,6 +3,7 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/raphaelgruber/memcp-go/internal/config"
 	"github.com/raphaelgruber/memcp-go/internal/metrics"
+	"github.com/raphaelgruber/memcp-go/internal/models"
 	"github.com/tmc/langchaingo/embeddings"
 	bedrockembed "github.com/tmc/langchaingo/embeddings/bedrock"
 	"github.com/tmc/langchaingo/llms/ollama"
@@ -22,13 +24,35 @@ import (
 type Embedder struct {
 	model     embeddings.Embedder
 	dimension int
+	provider  config.LLMProvider
 	modelName string
 	metrics   *metrics.Collector
+	usage     UsageRecorder // may be nil
+	batch     BatchConfig
+	timeout   time.Duration // per request attempt, 0 = none
+	cache     *EmbedCache   // recently embedded texts (nil disables)
+}
+
+// BatchConfig controls how EmbedBatchPartial splits a batch and how an
+// embedding request failing with a transient error (see isTransientError)
+// is retried. Other errors are never retried.
+type BatchConfig struct {
+	Size       int           // Texts per request (0 = all in one request)
+	MaxRetries int           // Retries of a request after the first attempt
+	Backoff    time.Duration // Delay before the first retry, doubled for each further retry
+}
+
+// BatchResult holds the per-text outcome of EmbedBatchPartial.
+type BatchResult struct {
+	Embeddings [][]float32 // Embedding per text, nil where it failed
+	Errors     []error     // Error per text, nil where it succeeded
+	Failed     int         // Number of texts without an embedding
 }
 
 // NewEmbedder creates an embedder based on configuration.
-// If mc is nil, metrics recording is disabled.
-func NewEmbedder(ctx context.Context, cfg config.Config, mc *metrics.Collector) (*Embedder, error) {
+// If mc is nil, metrics recording is disabled; if usage is nil, token usage
+// isn't persisted.
+func NewEmbedder(ctx context.Context, cfg config.Config, mc *metrics.Collector, usage UsageRecorder) (*Embedder, error) {
 	var model embeddings.Embedder
 	var err error
 
@@ -37,6 +61,7 @@ func NewEmbedder(ctx context.Context, cfg config.Config, mc *metrics.Collector)
 		llm, ollamaErr := ollama.New(
 			ollama.WithModel(cfg.EmbedModel),
 			ollama.WithServerURL(cfg.OllamaHost),
+			ollama.WithHTTPClient(newEmbedHTTPClient()),
 		)
 		if ollamaErr != nil {
 			return nil, fmt.Errorf("create ollama client: %w", ollamaErr)
@@ -45,15 +70,29 @@ func NewEmbedder(ctx context.Context, cfg config.Config, mc *metrics.Collector)
 		if err != nil {
 			return nil, fmt.Errorf("create ollama embedder: %w", err)
 		}
+		// Ollama takes one text per request; send a batch's requests concurrently
+		model = newConcurrentEmbedder(model, cfg.EmbedConcurrency)
 
 	case config.ProviderOpenAI:
-		if cfg.OpenAIAPIKey == "" {
-			return nil, fmt.Errorf("OpenAI API key required")
+		// OpenAI-compatible servers (vLLM, LiteLLM, ...) often need no key,
+		// but the client refuses to start without one
+		token := cfg.OpenAIAPIKey
+		if token == "" {
+			if cfg.OpenAIBaseURL == "" {
+				return nil, fmt.Errorf("OpenAI API key required")
+			}
+			token = "unused"
 		}
-		llm, openaiErr := openai.New(
-			openai.WithToken(cfg.OpenAIAPIKey),
+		opts := []openai.Option{
+			openai.WithToken(token),
 			openai.WithEmbeddingModel(cfg.EmbedModel),
-		)
+			openai.WithHTTPClient(newEmbedHTTPClient()),
+		}
+		// Custom base URL for OpenAI-compatible servers
+		if cfg.OpenAIBaseURL != "" {
+			opts = append(opts, openai.WithBaseURL(cfg.OpenAIBaseURL))
+		}
+		llm, openaiErr := openai.New(opts...)
 		if openaiErr != nil {
 			return nil, fmt.Errorf("create openai client: %w", openaiErr)
 		}
@@ -77,22 +116,37 @@ func NewEmbedder(ctx context.Context, cfg config.Config, mc *metrics.Collector)
 	return &Embedder{
 		model:     model,
 		dimension: cfg.EmbedDimension,
+		provider:  cfg.EmbedProvider,
 		modelName: cfg.EmbedModel,
 		metrics:   mc,
+		usage:     usage,
+		batch: BatchConfig{
+			Size:       cfg.EmbedBatchSize,
+			MaxRetries: cfg.EmbedBatchRetries,
+			Backoff:    time.Duration(cfg.EmbedRetryBackoffMS) * time.Millisecond,
+		},
+		timeout: time.Duration(cfg.EmbedTimeout) * time.Second,
+		cache:   NewEmbedCache(cfg.EmbedCacheSize, mc),
 	}, nil
 }
 
-// Embed generates an embedding vector for text.
+// Embed generates an embedding vector for text, or returns the cached one.
+// Each attempt fails after the configured embedding timeout; transient
+// failures are retried.
 func (e *Embedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	if embedding, ok := e.cache.get(e.modelName, text); ok {
+		return embedding, nil
+	}
+
 	textLen := len(text)
-	slog.Debug("embedding text", "model", e.modelName, "text_len", textLen)
+	slog.DebugContext(ctx, "embedding text", "model", e.modelName, "text_len", textLen)
 
 	start := time.Now()
-	vectors, err := e.model.EmbedDocuments(ctx, []string{text})
+	vectors, err := e.request(ctx, []string{text})
 	duration := time.Since(start)
 
 	if err != nil {
-		slog.Warn("embedding failed", "model", e.modelName, "text_len", textLen, "duration_ms", duration.Milliseconds(), "error", err)
+		slog.WarnContext(ctx, "embedding failed", "model", e.modelName, "text_len", textLen, "duration_ms", duration.Milliseconds(), "error", err)
 		return nil, fmt.Errorf("embed: %w", err)
 	}
 
@@ -105,27 +159,64 @@ func (e *Embedder) Embed(ctx context.Context, text string) ([]float32, error) {
 		return nil, fmt.Errorf("dimension mismatch: got %d, want %d", len(embedding), e.dimension)
 	}
 
-	slog.Debug("embedding complete", "model", e.modelName, "text_len", textLen, "duration_ms", duration.Milliseconds())
+	slog.DebugContext(ctx, "embedding complete", "model", e.modelName, "text_len", textLen, "duration_ms", duration.Milliseconds())
 
 	if e.metrics != nil {
 		e.metrics.RecordTiming(metrics.OpEmbedding, duration)
 	}
+	e.recordUsage(ctx, textLen)
 
+	e.cache.put(e.modelName, text, embedding)
 	return embedding, nil
 }
 
-// EmbedBatch generates embeddings for multiple texts.
+// EmbedBatch generates embeddings for multiple texts in one request. Cached
+// texts are not sent. Each attempt fails after the configured embedding
+// timeout; transient failures are retried.
 func (e *Embedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
 	if len(texts) == 0 {
 		return [][]float32{}, nil
 	}
+	if e.cache == nil {
+		return e.embedDocuments(ctx, texts)
+	}
+
+	vectors := make([][]float32, len(texts))
+	var missing []int
+	for i, text := range texts {
+		if embedding, ok := e.cache.get(e.modelName, text); ok {
+			vectors[i] = embedding
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	missingTexts := make([]string, len(missing))
+	for j, i := range missing {
+		missingTexts[j] = texts[i]
+	}
+	embedded, err := e.embedDocuments(ctx, missingTexts)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range missing {
+		vectors[i] = embedded[j]
+		e.cache.put(e.modelName, texts[i], embedded[j])
+	}
+	return vectors, nil
+}
 
+// embedDocuments embeds texts in one request, retried on transient errors.
+func (e *Embedder) embedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
 	start := time.Now()
-	vectors, err := e.model.EmbedDocuments(ctx, texts)
+	vectors, err := e.request(ctx, texts)
 	duration := time.Since(start)
 
 	if err != nil {
-		return nil, fmt.Errorf("embed batch: %w", err)
+		return nil, fmt.Errorf("embed batch: %w", wrapFatalError(err))
 	}
 
 	if len(vectors) != len(texts) {
@@ -143,10 +234,121 @@ func (e *Embedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32,
 	if e.metrics != nil {
 		e.metrics.RecordTiming(metrics.OpEmbedding, duration)
 	}
+	textLen := 0
+	for _, text := range texts {
+		textLen += len(text)
+	}
+	e.recordUsage(ctx, textLen)
 
 	return vectors, nil
 }
 
+// recordUsage persists the usage of a successful embedding request of
+// textLen characters. Embedding APIs don't report token counts here, so they
+// are estimated. Failures are logged only.
+func (e *Embedder) recordUsage(ctx context.Context, textLen int) {
+	if e.usage == nil {
+		return
+	}
+	model := string(e.provider) + "/" + e.modelName
+	err := e.usage.RecordTokenUsage(context.WithoutCancel(ctx), models.TokenUsageInput{
+		Operation:   metrics.OpEmbedding,
+		Model:       model,
+		InputTokens: textLen / charsPerToken,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record token usage", "model", model, "operation", metrics.OpEmbedding, "error", err)
+	}
+}
+
+// EmbedBatchPartial embeds texts in sub-batches, each request retried on
+// transient errors like any other. Unlike EmbedBatch, a failing sub-batch
+// doesn't discard the embeddings of the others: the result reports per text
+// what succeeded.
+func (e *Embedder) EmbedBatchPartial(ctx context.Context, texts []string) BatchResult {
+	result := BatchResult{
+		Embeddings: make([][]float32, len(texts)),
+		Errors:     make([]error, len(texts)),
+	}
+
+	size := e.batch.Size
+	if size <= 0 {
+		size = len(texts)
+	}
+	for start := 0; start < len(texts); start += size {
+		end := min(start+size, len(texts))
+		vectors, err := e.EmbedBatch(ctx, texts[start:end])
+		for i := start; i < end; i++ {
+			if err != nil {
+				result.Errors[i] = err
+				result.Failed++
+				continue
+			}
+			result.Embeddings[i] = vectors[i-start]
+		}
+	}
+
+	if result.Failed > 0 {
+		slog.WarnContext(ctx, "batch embedding partially failed", "model", e.modelName, "texts", len(texts), "failed", result.Failed)
+	}
+	return result
+}
+
+// request sends texts to the embedding model, bounding each attempt by the
+// configured timeout. Attempts failing with a transient error are retried
+// with exponential backoff until the configured retries are used up or ctx
+// ends.
+func (e *Embedder) request(ctx context.Context, texts []string) ([][]float32, error) {
+	backoff := e.batch.Backoff
+	for attempt := 1; ; attempt++ {
+		outcome := &attemptOutcome{}
+		callCtx, cancel := withTimeout(withAttemptOutcome(ctx, outcome), e.timeout)
+		vectors, err := e.model.EmbedDocuments(callCtx, texts)
+		timedOut := false
+		if err != nil {
+			timedOut = errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+			err = timeoutError(ctx, callCtx, e.timeout, err)
+		}
+		cancel()
+
+		if err == nil || attempt > e.batch.MaxRetries || ctx.Err() != nil || !(timedOut || isTransientError(err, outcome)) {
+			return vectors, err
+		}
+
+		slog.WarnContext(ctx, "embedding request failed, retrying", "model", e.modelName, "texts", len(texts), "attempt", attempt, "backoff_ms", backoff.Milliseconds(), "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// ErrDimensionMismatch is returned by Validate when the model's embeddings
+// don't have the configured dimension.
+var ErrDimensionMismatch = errors.New("embedding dimension mismatch")
+
+// validateProbe is the text embedded by Validate.
+const validateProbe = "knowhow embedding dimension probe"
+
+// Validate embeds a probe text and checks the model returns embeddings of
+// the configured dimension, which the vector indexes are created with.
+// Returns an error wrapping ErrDimensionMismatch if it doesn't.
+func (e *Embedder) Validate(ctx context.Context) error {
+	vectors, err := e.request(ctx, []string{validateProbe})
+	if err != nil {
+		return fmt.Errorf("embed probe: %w", err)
+	}
+	if len(vectors) == 0 {
+		return fmt.Errorf("embed probe: no embedding returned")
+	}
+	if got := len(vectors[0]); got != e.dimension {
+		return fmt.Errorf("%w: model %s returns %d dimensions, KNOWHOW_EMBED_DIMENSION is %d", ErrDimensionMismatch, e.modelName, got, e.dimension)
+	}
+	return nil
+}
+
 // Model returns the embedding model name.
 func (e *Embedder) Model() string {
 	return e.modelName
@@ -209,7 +411,7 @@ func (b *bedrockEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([
 	for _, t := range texts {
 		totalChars += len(t)
 	}
-	slog.Debug("bedrock embedding starting", "provider", b.provider, "texts", len(texts), "total_chars", totalChars)
+	slog.DebugContext(ctx, "bedrock embedding starting", "provider", b.provider, "texts", len(texts), "total_chars", totalChars)
 
 	var vecs [][]float32
 	var err error
@@ -225,10 +427,10 @@ func (b *bedrockEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([
 
 	duration := time.Since(start)
 	if err != nil {
-		slog.Warn("bedrock embedding failed", "provider", b.provider, "duration_ms", duration.Milliseconds(), "error", err)
+		slog.WarnContext(ctx, "bedrock embedding failed", "provider", b.provider, "duration_ms", duration.Milliseconds(), "error", err)
 		return nil, err
 	}
-	slog.Debug("bedrock embedding complete", "provider", b.provider, "texts", len(texts), "duration_ms", duration.Milliseconds())
+	slog.DebugContext(ctx, "bedrock embedding complete", "provider", b.provider, "texts", len(texts), "duration_ms", duration.Milliseconds())
 	return vecs, nil
 }
 
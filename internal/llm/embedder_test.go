@@ -0,0 +1,380 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/raphaelgruber/memcp-go/internal/config"
+)
+
+// fakeEmbedder returns a fixed vector per text and fails any request that
+// contains a text in failing, up to failures times (negative = always).
+type fakeEmbedder struct {
+	failing  string
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *fakeEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	f.calls++
+	if slices.Contains(texts, f.failing) && f.failures != 0 {
+		f.failures--
+		return nil, f.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{1, 0}
+	}
+	return vectors, nil
+}
+
+func (f *fakeEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := f.EmbedDocuments(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+// errRefused is a transient network error, as returned for an unreachable
+// provider.
+var errRefused = fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED)
+
+// statusError is an API error carrying its HTTP status, like the AWS SDK's.
+type statusError int
+
+func (e statusError) Error() string       { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) HTTPStatusCode() int { return int(e) }
+
+func TestEmbedBatchPartial(t *testing.T) {
+	texts := []string{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		name       string
+		fake       *fakeEmbedder
+		retries    int
+		wantFailed []int // indices without an embedding
+		wantCalls  int
+	}{
+		{"all succeed", &fakeEmbedder{}, 2, nil, 3},
+		{"retry recovers", &fakeEmbedder{failing: "c", failures: 1, err: errRefused}, 2, nil, 4},
+		{"failed sub-batch kept out", &fakeEmbedder{failing: "c", failures: -1, err: errRefused}, 2, []int{2, 3}, 5},
+		{"no retries", &fakeEmbedder{failing: "e", failures: 1, err: errRefused}, 0, []int{4}, 3},
+		{"fatal not retried", &fakeEmbedder{failing: "a", failures: -1, err: errors.New("invalid api key")}, 2, []int{0, 1}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Embedder{
+				model:     tt.fake,
+				dimension: 2,
+				batch:     BatchConfig{Size: 2, MaxRetries: tt.retries},
+			}
+
+			result := e.EmbedBatchPartial(context.Background(), texts)
+
+			var failed []int
+			for i := range texts {
+				if result.Errors[i] != nil {
+					failed = append(failed, i)
+					if result.Embeddings[i] != nil {
+						t.Errorf("text %d has both an embedding and an error", i)
+					}
+				} else if result.Embeddings[i] == nil {
+					t.Errorf("text %d has neither an embedding nor an error", i)
+				}
+			}
+			if !slices.Equal(failed, tt.wantFailed) {
+				t.Errorf("failed = %v, want %v", failed, tt.wantFailed)
+			}
+			if result.Failed != len(tt.wantFailed) {
+				t.Errorf("Failed = %d, want %d", result.Failed, len(tt.wantFailed))
+			}
+			if tt.fake.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", tt.fake.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// blockingEmbedder never answers, like a hung provider.
+type blockingEmbedder struct{}
+
+func (blockingEmbedder) EmbedDocuments(ctx context.Context, _ []string) ([][]float32, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (blockingEmbedder) EmbedQuery(ctx context.Context, _ string) ([]float32, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestEmbedTimeout(t *testing.T) {
+	e := &Embedder{model: blockingEmbedder{}, dimension: 2, timeout: 10 * time.Millisecond}
+
+	if _, err := e.Embed(context.Background(), "a"); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Embed() error = %v, want timeout", err)
+	}
+	if _, err := e.EmbedBatch(context.Background(), []string{"a", "b"}); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("EmbedBatch() error = %v, want timeout", err)
+	}
+
+	// A cancelled caller isn't reported as a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := e.Embed(ctx, "a"); err == nil || strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Embed() error = %v, want cancellation", err)
+	}
+}
+
+func TestEmbedCache(t *testing.T) {
+	fake := &fakeEmbedder{}
+	e := &Embedder{
+		model:     fake,
+		modelName: "model-a",
+		dimension: 2,
+		cache:     NewEmbedCache(2, nil),
+	}
+	ctx := context.Background()
+
+	if _, err := e.Embed(ctx, "a"); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if _, err := e.Embed(ctx, "a"); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if fake.calls != 1 {
+		t.Errorf("calls after repeated text = %d, want 1", fake.calls)
+	}
+
+	// Only uncached texts are sent
+	vectors, err := e.EmbedBatch(ctx, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("EmbedBatch: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0] == nil || vectors[1] == nil {
+		t.Fatalf("EmbedBatch = %v, want 2 embeddings", vectors)
+	}
+	if fake.calls != 2 {
+		t.Errorf("calls after batch = %d, want 2", fake.calls)
+	}
+
+	// Another model doesn't reuse embeddings
+	e.modelName = "model-b"
+	if _, err := e.Embed(ctx, "a"); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if fake.calls != 3 {
+		t.Errorf("calls after model switch = %d, want 3", fake.calls)
+	}
+
+	// The least recently used entry ("model-a"/"a") was evicted
+	if got := e.cache.Len(); got != 2 {
+		t.Errorf("cache size = %d, want 2", got)
+	}
+	if _, ok := e.cache.get("model-a", "a"); ok {
+		t.Error("least recently used entry not evicted")
+	}
+}
+
+func TestEmbedRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		fake      *fakeEmbedder
+		wantErr   bool
+		wantCalls int
+	}{
+		{"transient recovers", &fakeEmbedder{failing: "a", failures: 2, err: errRefused}, false, 3},
+		{"5xx recovers", &fakeEmbedder{failing: "a", failures: 1, err: statusError(503)}, false, 2},
+		{"gives up after retries", &fakeEmbedder{failing: "a", failures: -1, err: errRefused}, true, 4},
+		{"client error not retried", &fakeEmbedder{failing: "a", failures: -1, err: statusError(400)}, true, 1},
+		{"status text not trusted", &fakeEmbedder{failing: "a", failures: -1, err: errors.New("input of 500 tokens too long")}, true, 1},
+		{"fatal not retried", &fakeEmbedder{failing: "a", failures: -1, err: errors.New("401 unauthorized")}, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Embedder{model: tt.fake, dimension: 2, batch: BatchConfig{MaxRetries: 3, Backoff: time.Millisecond}}
+
+			_, err := e.Embed(context.Background(), "a")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Embed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.fake.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", tt.fake.calls, tt.wantCalls)
+			}
+		})
+	}
+
+	t.Run("stops when context ends", func(t *testing.T) {
+		fake := &fakeEmbedder{failing: "a", failures: -1, err: errRefused}
+		e := &Embedder{model: fake, dimension: 2, batch: BatchConfig{MaxRetries: 3, Backoff: time.Hour}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := e.Embed(ctx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Embed() error = %v, want context deadline", err)
+		}
+		if fake.calls != 1 {
+			t.Errorf("calls = %d, want 1", fake.calls)
+		}
+	})
+}
+
+func TestEmbedRetryHTTPStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int // Response status per request, 200 after the last
+		wantErr   bool
+		wantCalls int
+	}{
+		{"unavailable recovers", []int{http.StatusServiceUnavailable}, false, 2},
+		{"gateway timeout recovers", []int{http.StatusGatewayTimeout, http.StatusBadGateway}, false, 3},
+		{"bad request not retried", []int{http.StatusBadRequest, http.StatusBadRequest}, true, 1},
+		{"rate limit not retried", []int{http.StatusTooManyRequests, http.StatusTooManyRequests}, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				call := int(calls.Add(1))
+				w.Header().Set("Content-Type", "application/json")
+				if call <= len(tt.statuses) {
+					w.WriteHeader(tt.statuses[call-1])
+					fmt.Fprint(w, `{"error":{"message":"try again"}}`)
+					return
+				}
+				fmt.Fprint(w, `{"object":"list","data":[{"object":"embedding","embedding":[1,0],"index":0}]}`)
+			}))
+			defer server.Close()
+
+			e, err := NewEmbedder(context.Background(), config.Config{
+				EmbedProvider:       config.ProviderOpenAI,
+				EmbedModel:          "test-embed",
+				EmbedDimension:      2,
+				EmbedBatchRetries:   3,
+				EmbedRetryBackoffMS: 1,
+				OpenAIBaseURL:       server.URL,
+			}, nil, nil)
+			if err != nil {
+				t.Fatalf("NewEmbedder() error = %v", err)
+			}
+
+			_, err = e.Embed(context.Background(), "a")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Embed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := int(calls.Load()); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		fake      *fakeEmbedder
+		dimension int
+		wantErr   error
+	}{
+		{"matching dimension", &fakeEmbedder{}, 2, nil},
+		{"dimension mismatch", &fakeEmbedder{}, 1024, ErrDimensionMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Embedder{model: tt.fake, dimension: tt.dimension, modelName: "fake"}
+			err := e.Validate(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("unreachable model", func(t *testing.T) {
+		e := &Embedder{model: &fakeEmbedder{failing: validateProbe, failures: -1, err: errors.New("invalid api key")}, dimension: 2}
+		err := e.Validate(context.Background())
+		if err == nil || errors.Is(err, ErrDimensionMismatch) {
+			t.Errorf("Validate() = %v, want a request error", err)
+		}
+	})
+}
+
+// indexEmbedder embeds a text as its length, recording the most requests
+// in flight at once.
+type indexEmbedder struct {
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	err         error
+}
+
+func (f *indexEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	f.mu.Lock()
+	f.inFlight++
+	f.maxInFlight = max(f.maxInFlight, f.inFlight)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	time.Sleep(time.Millisecond)
+	if f.err != nil {
+		return nil, f.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text))}
+	}
+	return vectors, nil
+}
+
+func (f *indexEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := f.EmbedDocuments(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func TestConcurrentEmbedder(t *testing.T) {
+	texts := make([]string, 20)
+	for i := range texts {
+		texts[i] = strings.Repeat("x", i)
+	}
+
+	fake := &indexEmbedder{}
+	vectors, err := newConcurrentEmbedder(fake, 3).EmbedDocuments(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("EmbedDocuments() error = %v", err)
+	}
+	for i, v := range vectors {
+		if len(v) != 1 || v[0] != float32(i) {
+			t.Errorf("vector %d = %v, want [%d]", i, v, i)
+		}
+	}
+	if fake.maxInFlight > 3 {
+		t.Errorf("max in flight = %d, want at most 3", fake.maxInFlight)
+	}
+
+	failing := &indexEmbedder{err: errors.New("boom")}
+	if _, err := newConcurrentEmbedder(failing, 3).EmbedDocuments(context.Background(), texts); err == nil {
+		t.Error("EmbedDocuments() with failing requests: want error")
+	}
+}
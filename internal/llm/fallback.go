@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// fallbackCooldown is how long a model that failed with a fatal error
+// (auth, billing) is skipped in its fallback chain before it's tried again.
+const fallbackCooldown = 5 * time.Minute
+
+// name identifies the model as provider/model.
+func (m *Model) name() string {
+	return string(m.provider) + "/" + m.modelName
+}
+
+// chain returns the models to try for a generation: m itself, then its
+// fallbacks, leaving out those cooling down after a fatal error. If every
+// model is cooling down, all of them are returned rather than none.
+func (m *Model) chain() []*Model {
+	all := append([]*Model{m}, m.fallbacks...)
+	now := time.Now().UnixNano()
+	var available []*Model
+	for _, candidate := range all {
+		if candidate.disabledUntil.Load() <= now {
+			available = append(available, candidate)
+		}
+	}
+	if len(available) == 0 {
+		return all
+	}
+	return available
+}
+
+// withFallback runs call with each model of the chain until one succeeds,
+// returning the last error if all fail. A model failing with a fatal error
+// is skipped for fallbackCooldown, so only that provider is taken out of
+// the chain. The chain stops early once ctx ends, or if retryable is set
+// and reports that the failed call can't be repeated.
+func (m *Model) withFallback(ctx context.Context, call func(candidate *Model) error, retryable func() bool) error {
+	if len(m.fallbacks) == 0 {
+		return call(m)
+	}
+
+	chain := m.chain()
+	var err error
+	for i, candidate := range chain {
+		err = call(candidate)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || (retryable != nil && !retryable()) {
+			return err
+		}
+		if errors.Is(err, ErrFatalAPI) {
+			candidate.disabledUntil.Store(time.Now().Add(fallbackCooldown).UnixNano())
+		}
+		if i < len(chain)-1 {
+			slog.WarnContext(ctx, "LLM generation failed, trying fallback", "model", candidate.name(), "fallback", chain[i+1].name(), "error", err)
+		}
+	}
+	return err
+}
+
+// trackStreamed wraps onToken to report whether any token was passed on.
+// Once tokens reached the caller, a stream can't be retried elsewhere.
+func trackStreamed(onToken func(token string) error) (func(token string) error, func() bool) {
+	streamed := false
+	return func(token string) error {
+			streamed = true
+			return onToken(token)
+		}, func() bool {
+			return !streamed
+		}
+}
@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/raphaelgruber/memcp-go/internal/config"
+	"github.com/raphaelgruber/memcp-go/internal/models"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// fakeLLM answers with a fixed response or fails with err, counting calls.
+type fakeLLM struct {
+	response string
+	err      error
+	calls    int
+	lastOpts llms.CallOptions // options of the last call
+}
+
+func (f *fakeLLM) GenerateContent(ctx context.Context, _ []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	var opts llms.CallOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+	f.lastOpts = opts
+	if opts.StreamingFunc != nil {
+		if err := opts.StreamingFunc(ctx, []byte(f.response)); err != nil {
+			return nil, err
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: f.response}}}, nil
+}
+
+func (f *fakeLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+// fakeUsageRecorder collects recorded token usage.
+type fakeUsageRecorder struct {
+	recorded []models.TokenUsageInput
+}
+
+func (f *fakeUsageRecorder) RecordTokenUsage(_ context.Context, input models.TokenUsageInput) error {
+	f.recorded = append(f.recorded, input)
+	return nil
+}
+
+func newFakeModel(provider config.LLMProvider, name string, llm *fakeLLM, usage UsageRecorder) *Model {
+	return &Model{llm: llm, provider: provider, modelName: name, usage: usage}
+}
+
+func TestModelFallback(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("falls back on error and records serving model", func(t *testing.T) {
+		usage := &fakeUsageRecorder{}
+		primary := &fakeLLM{err: errors.New("connection refused")}
+		fallback := &fakeLLM{response: "answer"}
+		m := newFakeModel(config.ProviderOpenAI, "gpt-4o-mini", primary, usage)
+		m.fallbacks = []*Model{newFakeModel(config.ProviderOllama, "llama3", fallback, usage)}
+
+		content, u, err := m.GenerateWithSystemUsage(ctx, "system", "user")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if content != "answer" || u.Model != "ollama/llama3" {
+			t.Errorf("got %q from %q, want %q from ollama/llama3", content, u.Model, "answer")
+		}
+		if len(usage.recorded) != 1 || usage.recorded[0].Model != "ollama/llama3" {
+			t.Errorf("recorded usage = %+v, want one entry for ollama/llama3", usage.recorded)
+		}
+	})
+
+	t.Run("skips provider after fatal error", func(t *testing.T) {
+		primary := &fakeLLM{err: errors.New("401 unauthorized")}
+		fallback := &fakeLLM{response: "answer"}
+		m := newFakeModel(config.ProviderOpenAI, "gpt-4o-mini", primary, nil)
+		m.fallbacks = []*Model{newFakeModel(config.ProviderOllama, "llama3", fallback, nil)}
+
+		for range 2 {
+			if _, err := m.GenerateWithSystem(ctx, "system", "user"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if primary.calls != 1 || fallback.calls != 2 {
+			t.Errorf("calls = %d primary, %d fallback, want 1 and 2", primary.calls, fallback.calls)
+		}
+	})
+
+	t.Run("returns last error if all fail", func(t *testing.T) {
+		m := newFakeModel(config.ProviderOpenAI, "gpt-4o-mini", &fakeLLM{err: errors.New("first")}, nil)
+		m.fallbacks = []*Model{newFakeModel(config.ProviderOllama, "llama3", &fakeLLM{err: errors.New("second")}, nil)}
+
+		_, err := m.GenerateWithSystem(ctx, "system", "user")
+		if err == nil || err.Error() != "generate with system: second" {
+			t.Errorf("err = %v, want the fallback's error", err)
+		}
+	})
+
+	t.Run("no fallback once tokens streamed", func(t *testing.T) {
+		primary := &fakeLLM{response: "partial"}
+		fallback := &fakeLLM{response: "answer"}
+		m := newFakeModel(config.ProviderOpenAI, "gpt-4o-mini", primary, nil)
+		m.fallbacks = []*Model{newFakeModel(config.ProviderOllama, "llama3", fallback, nil)}
+
+		abort := errors.New("client gone")
+		err := m.GenerateWithSystemStream(ctx, "system", "user", func(string) error { return abort })
+		if !errors.Is(err, abort) {
+			t.Errorf("err = %v, want %v", err, abort)
+		}
+		if fallback.calls != 0 {
+			t.Errorf("fallback called %d times after tokens were streamed", fallback.calls)
+		}
+	})
+}
@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/raphaelgruber/memcp-go/internal/config"
 	"github.com/raphaelgruber/memcp-go/internal/metrics"
+	"github.com/raphaelgruber/memcp-go/internal/models"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/anthropic"
 	"github.com/tmc/langchaingo/llms/bedrock"
@@ -56,11 +59,83 @@ func wrapFatalError(err error) error {
 // charsPerToken is used to estimate token counts when real counts unavailable.
 const charsPerToken = 4
 
-// Model wraps langchaingo LLM for text generation.
+// EstimateTokens estimates the token count of text from its length.
+func EstimateTokens(text string) int {
+	return len(text) / charsPerToken
+}
+
+// conversationKey is the context key of the conversation an LLM call is
+// made for.
+type conversationKey struct{}
+
+// WithConversation returns a context attributing the token usage of LLM
+// calls made with it to a conversation.
+func WithConversation(ctx context.Context, conversationID string) context.Context {
+	return context.WithValue(ctx, conversationKey{}, conversationID)
+}
+
+// conversationFrom returns the conversation set by WithConversation, or nil.
+func conversationFrom(ctx context.Context) *string {
+	if id, ok := ctx.Value(conversationKey{}).(string); ok && id != "" {
+		return &id
+	}
+	return nil
+}
+
+// operationKey is the context key of the operation token usage of LLM calls
+// is recorded under.
+type operationKey struct{}
+
+// withOperation returns a context recording the token usage of LLM calls
+// made with it under operation instead of the call's generic operation.
+func withOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// operationFrom returns the operation set by withOperation, or fallback.
+func operationFrom(ctx context.Context, fallback string) string {
+	if op, ok := ctx.Value(operationKey{}).(string); ok && op != "" {
+		return op
+	}
+	return fallback
+}
+
+// Model wraps langchaingo LLM for text generation. If fallbacks are
+// configured, a failed generation is retried with each of them in order.
 type Model struct {
 	llm       llms.Model
+	provider  config.LLMProvider
 	modelName string
 	metrics   *metrics.Collector
+	usage     UsageRecorder // may be nil
+	timeout   time.Duration // per non-streaming call, 0 = none
+
+	genDefaults genOptions // sampling settings unless a call overrides them
+
+	fallbacks     []*Model
+	disabledUntil atomic.Int64 // unix nanos; skipped in fallback chains until then
+}
+
+// UsageRecorder persists the token usage of LLM calls.
+type UsageRecorder interface {
+	RecordTokenUsage(ctx context.Context, input models.TokenUsageInput) error
+}
+
+// withTimeout bounds ctx by timeout if it is positive.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timeoutError marks err as a timeout if callCtx hit its own deadline rather
+// than the caller's context ending.
+func timeoutError(ctx, callCtx context.Context, timeout time.Duration, err error) error {
+	if errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return fmt.Errorf("timed out after %s: %w", timeout, err)
+	}
+	return err
 }
 
 // extractTokenCounts gets input/output token counts from GenerationInfo.
@@ -123,9 +198,30 @@ func toInt64(v any) (int64, bool) {
 	}
 }
 
-// NewModel creates an LLM model based on configuration.
-// If mc is nil, metrics recording is disabled.
-func NewModel(cfg config.Config, mc *metrics.Collector) (*Model, error) {
+// NewModel creates an LLM model based on configuration, falling back to the
+// models of cfg.LLMFallback in order. If mc is nil, metrics recording is
+// disabled; if usage is nil, token usage isn't persisted.
+func NewModel(cfg config.Config, mc *metrics.Collector, usage UsageRecorder) (*Model, error) {
+	m, err := newProviderModel(cfg, mc, usage)
+	if err != nil || m == nil {
+		return m, err
+	}
+
+	for _, ref := range cfg.LLMFallback {
+		fallbackCfg := cfg
+		fallbackCfg.LLMProvider = ref.Provider
+		fallbackCfg.LLMModel = ref.Model
+		fallback, err := newProviderModel(fallbackCfg, mc, usage)
+		if err != nil {
+			return nil, fmt.Errorf("fallback %s/%s: %w", ref.Provider, ref.Model, err)
+		}
+		m.fallbacks = append(m.fallbacks, fallback)
+	}
+	return m, nil
+}
+
+// newProviderModel creates a model for cfg.LLMProvider, without fallbacks.
+func newProviderModel(cfg config.Config, mc *metrics.Collector, usage UsageRecorder) (*Model, error) {
 	var model llms.Model
 	var err error
 
@@ -146,10 +242,15 @@ func NewModel(cfg config.Config, mc *metrics.Collector) (*Model, error) {
 		if cfg.OpenAIAPIKey == "" {
 			return nil, fmt.Errorf("OpenAI API key required")
 		}
-		model, err = openai.New(
+		opts := []openai.Option{
 			openai.WithToken(cfg.OpenAIAPIKey),
 			openai.WithModel(cfg.LLMModel),
-		)
+		}
+		// Custom base URL for OpenAI-compatible servers (vLLM, LM Studio, Groq, ...)
+		if cfg.OpenAIBaseURL != "" {
+			opts = append(opts, openai.WithBaseURL(cfg.OpenAIBaseURL))
+		}
+		model, err = openai.New(opts...)
 		if err != nil {
 			return nil, fmt.Errorf("create openai model: %w", err)
 		}
@@ -185,47 +286,104 @@ func NewModel(cfg config.Config, mc *metrics.Collector) (*Model, error) {
 
 	return &Model{
 		llm:       model,
+		provider:  cfg.LLMProvider,
 		modelName: cfg.LLMModel,
 		metrics:   mc,
+		usage:     usage,
+		timeout:   time.Duration(cfg.LLMTimeout) * time.Second,
+
+		genDefaults: defaultGenOptions(cfg),
 	}, nil
 }
 
-// GenerateWithSystem generates text with a system prompt.
-func (m *Model) GenerateWithSystem(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+// Usage holds the token counts of a single LLM call.
+// Counts are estimated from text length when the provider doesn't report them.
+type Usage struct {
+	InputTokens  int64
+	OutputTokens int64
+	Model        string // provider/model that served the call
+}
+
+// recordUsage persists the usage of a successful call. Failures are logged
+// only, so bookkeeping never fails a generation.
+func (m *Model) recordUsage(ctx context.Context, operation string, usage Usage) {
+	if m.usage == nil {
+		return
+	}
+	err := m.usage.RecordTokenUsage(context.WithoutCancel(ctx), models.TokenUsageInput{
+		Operation:      operation,
+		Model:          usage.Model,
+		InputTokens:    int(usage.InputTokens),
+		OutputTokens:   int(usage.OutputTokens),
+		ConversationID: conversationFrom(ctx),
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record token usage", "model", usage.Model, "operation", operation, "error", err)
+	}
+}
+
+// GenerateWithSystem generates text with a system prompt. opts override the
+// configured sampling settings for this call.
+func (m *Model) GenerateWithSystem(ctx context.Context, systemPrompt, userPrompt string, opts ...GenOption) (string, error) {
+	content, _, err := m.GenerateWithSystemUsage(ctx, systemPrompt, userPrompt, opts...)
+	return content, err
+}
+
+// GenerateWithSystemUsage generates text with a system prompt and returns the
+// token usage of the call. The call fails after the configured LLM timeout.
+func (m *Model) GenerateWithSystemUsage(ctx context.Context, systemPrompt, userPrompt string, opts ...GenOption) (string, Usage, error) {
+	var content string
+	var usage Usage
+	err := m.withFallback(ctx, func(candidate *Model) error {
+		var err error
+		content, usage, err = candidate.generateWithSystem(ctx, systemPrompt, userPrompt, opts)
+		return err
+	}, nil)
+	return content, usage, err
+}
+
+// generateWithSystem runs GenerateWithSystemUsage against this model only.
+func (m *Model) generateWithSystem(ctx context.Context, systemPrompt, userPrompt string, opts []GenOption) (string, Usage, error) {
 	systemLen := len(systemPrompt)
 	userLen := len(userPrompt)
 	totalLen := systemLen + userLen
 
-	slog.Debug("LLM generate starting", "model", m.modelName, "system_len", systemLen, "user_len", userLen, "total_len", totalLen)
+	slog.DebugContext(ctx, "LLM generate starting", "model", m.modelName, "system_len", systemLen, "user_len", userLen, "total_len", totalLen)
 
 	messages := []llms.MessageContent{
 		llms.TextParts(llms.ChatMessageTypeSystem, systemPrompt),
 		llms.TextParts(llms.ChatMessageTypeHuman, userPrompt),
 	}
 
+	callCtx, cancel := withTimeout(ctx, m.timeout)
+	defer cancel()
+
 	start := time.Now()
-	response, err := m.llm.GenerateContent(ctx, messages, llms.WithMaxTokens(8192))
+	response, err := m.llm.GenerateContent(callCtx, messages, m.callOptions(opts)...)
 	duration := time.Since(start)
 
 	if err != nil {
-		slog.Warn("LLM generate failed", "model", m.modelName, "total_len", totalLen, "duration_ms", duration.Milliseconds(), "error", err)
-		return "", wrapFatalError(fmt.Errorf("generate with system: %w", err))
+		err = timeoutError(ctx, callCtx, m.timeout, err)
+		slog.WarnContext(ctx, "LLM generate failed", "model", m.modelName, "total_len", totalLen, "duration_ms", duration.Milliseconds(), "error", err)
+		return "", Usage{}, wrapFatalError(fmt.Errorf("generate with system: %w", err))
 	}
 
 	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no response choices")
+		return "", Usage{}, fmt.Errorf("no response choices")
 	}
 
 	choice := response.Choices[0]
 	responseLen := len(choice.Content)
-	slog.Debug("LLM generate complete", "model", m.modelName, "total_len", totalLen, "response_len", responseLen, "duration_ms", duration.Milliseconds())
+	slog.DebugContext(ctx, "LLM generate complete", "model", m.modelName, "total_len", totalLen, "response_len", responseLen, "duration_ms", duration.Milliseconds())
 
+	inputTokens, outputTokens := extractTokenCounts(choice.GenerationInfo, totalLen, responseLen)
 	if m.metrics != nil {
-		inputTokens, outputTokens := extractTokenCounts(choice.GenerationInfo, totalLen, responseLen)
-		m.metrics.RecordLLMUsage(metrics.OpLLMGenerate, duration, inputTokens, outputTokens)
+		m.metrics.RecordLLMUsage(metrics.OpLLMGenerate, m.name(), duration, inputTokens, outputTokens)
 	}
 
-	return choice.Content, nil
+	usage := Usage{InputTokens: inputTokens, OutputTokens: outputTokens, Model: m.name()}
+	m.recordUsage(ctx, operationFrom(ctx, metrics.OpLLMGenerate), usage)
+	return choice.Content, usage, nil
 }
 
 // Model returns the LLM model name.
@@ -233,12 +391,30 @@ func (m *Model) Model() string {
 	return m.modelName
 }
 
-// SynthesizeAnswer generates an answer from context and query.
-func (m *Model) SynthesizeAnswer(ctx context.Context, query string, context string) (string, error) {
-	systemPrompt := `You are a helpful knowledge assistant. Answer the user's question based ONLY on the provided context.
+// DefaultAnswerSystemPrompt is the system prompt for answer synthesis when
+// the caller provides none.
+const DefaultAnswerSystemPrompt = `You are a helpful knowledge assistant. Answer the user's question based ONLY on the provided context.
 If the context doesn't contain enough information to answer the question, say so.
 Be concise and cite specific information from the context where relevant.`
 
+// answerSystemPrompt returns systemPrompt, or the default if it is empty.
+func answerSystemPrompt(systemPrompt string) string {
+	if strings.TrimSpace(systemPrompt) == "" {
+		return DefaultAnswerSystemPrompt
+	}
+	return systemPrompt
+}
+
+// SynthesizeAnswer generates an answer from context and query.
+func (m *Model) SynthesizeAnswer(ctx context.Context, query string, context string) (string, error) {
+	answer, _, err := m.SynthesizeAnswerWithUsage(ctx, query, context, "")
+	return answer, err
+}
+
+// SynthesizeAnswerWithUsage generates an answer from context and query and
+// returns the token usage of the call. A non-empty systemPrompt replaces
+// DefaultAnswerSystemPrompt.
+func (m *Model) SynthesizeAnswerWithUsage(ctx context.Context, query string, context string, systemPrompt string) (string, Usage, error) {
 	userPrompt := fmt.Sprintf(`Context:
 %s
 
@@ -246,7 +422,45 @@ Question: %s
 
 Answer:`, context, query)
 
-	return m.GenerateWithSystem(ctx, systemPrompt, userPrompt)
+	return m.GenerateWithSystemUsage(ctx, answerSystemPrompt(systemPrompt), userPrompt)
+}
+
+// maxSummarizeInput caps the content sent for summarization to bound token usage.
+const maxSummarizeInput = 12000
+
+// truncateInput cuts s to at most maxBytes bytes, backing up to a UTF-8
+// boundary so no rune is split.
+func truncateInput(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// Summarize generates a short summary of the given content. Token usage is
+// recorded under operation "summarize".
+func (m *Model) Summarize(ctx context.Context, content string) (string, error) {
+	systemPrompt := `You are a knowledge base assistant. Write a one or two sentence summary of the provided document.
+- Describe what the document is about, not how it is structured
+- Do not add information that is not in the document
+- Output only the summary text, without a preamble`
+
+	content = truncateInput(content, maxSummarizeInput)
+
+	userPrompt := fmt.Sprintf(`Document:
+%s
+
+Summary:`, content)
+
+	summary, err := m.GenerateWithSystem(withOperation(ctx, metrics.OpSummarize), systemPrompt, userPrompt)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(summary), nil
 }
 
 // FillTemplate fills a template with gathered knowledge.
@@ -270,16 +484,29 @@ Filled Template:`, templateContent, knowledge)
 
 // GenerateWithSystemStream generates text with a system prompt, streaming tokens via callback.
 // The onToken callback is invoked for each token/chunk. Return an error from onToken to abort.
+// Fallback models are only tried if the failed model hasn't streamed any tokens yet.
 func (m *Model) GenerateWithSystemStream(
 	ctx context.Context,
 	systemPrompt, userPrompt string,
 	onToken func(token string) error,
+) error {
+	onToken, streamed := trackStreamed(onToken)
+	return m.withFallback(ctx, func(candidate *Model) error {
+		return candidate.generateWithSystemStream(ctx, systemPrompt, userPrompt, onToken)
+	}, streamed)
+}
+
+// generateWithSystemStream runs GenerateWithSystemStream against this model only.
+func (m *Model) generateWithSystemStream(
+	ctx context.Context,
+	systemPrompt, userPrompt string,
+	onToken func(token string) error,
 ) error {
 	systemLen := len(systemPrompt)
 	userLen := len(userPrompt)
 	totalLen := systemLen + userLen
 
-	slog.Debug("LLM streaming generate starting", "model", m.modelName, "system_len", systemLen, "user_len", userLen, "total_len", totalLen)
+	slog.DebugContext(ctx, "LLM streaming generate starting", "model", m.modelName, "system_len", systemLen, "user_len", userLen, "total_len", totalLen)
 
 	messages := []llms.MessageContent{
 		llms.TextParts(llms.ChatMessageTypeSystem, systemPrompt),
@@ -297,34 +524,23 @@ func (m *Model) GenerateWithSystemStream(
 		return onToken(string(chunk))
 	}
 
-	response, err := m.llm.GenerateContent(ctx, messages, llms.WithMaxTokens(8192), llms.WithStreamingFunc(streamingFunc))
+	response, err := m.llm.GenerateContent(ctx, messages, append(m.callOptions(nil), llms.WithStreamingFunc(streamingFunc))...)
 	duration := time.Since(start)
 
 	if err != nil {
-		slog.Warn("LLM streaming generate failed", "model", m.modelName, "total_len", totalLen, "duration_ms", duration.Milliseconds(), "error", err)
+		slog.WarnContext(ctx, "LLM streaming generate failed", "model", m.modelName, "total_len", totalLen, "duration_ms", duration.Milliseconds(), "error", err)
 		return wrapFatalError(fmt.Errorf("generate with system stream: %w", err))
 	}
 
-	slog.Debug("LLM streaming generate complete", "model", m.modelName, "total_len", totalLen, "output_len", outputLen, "duration_ms", duration.Milliseconds())
-
-	if m.metrics != nil {
-		var genInfo map[string]any
-		if len(response.Choices) > 0 {
-			genInfo = response.Choices[0].GenerationInfo
-		}
-		inputTokens, outputTokens := extractTokenCounts(genInfo, totalLen, outputLen)
-		m.metrics.RecordLLMUsage(metrics.OpLLMStream, duration, inputTokens, outputTokens)
-	}
+	slog.DebugContext(ctx, "LLM streaming generate complete", "model", m.modelName, "total_len", totalLen, "output_len", outputLen, "duration_ms", duration.Milliseconds())
 
+	m.recordStreamUsage(ctx, response, duration, totalLen, outputLen)
 	return nil
 }
 
 // SynthesizeAnswerStream generates an answer from context and query, streaming tokens.
-func (m *Model) SynthesizeAnswerStream(ctx context.Context, query string, context string, onToken func(token string) error) error {
-	systemPrompt := `You are a helpful knowledge assistant. Answer the user's question based ONLY on the provided context.
-If the context doesn't contain enough information to answer the question, say so.
-Be concise and cite specific information from the context where relevant.`
-
+// A non-empty systemPrompt replaces DefaultAnswerSystemPrompt.
+func (m *Model) SynthesizeAnswerStream(ctx context.Context, query string, context string, systemPrompt string, onToken func(token string) error) error {
 	userPrompt := fmt.Sprintf(`Context:
 %s
 
@@ -332,7 +548,7 @@ Question: %s
 
 Answer:`, context, query)
 
-	return m.GenerateWithSystemStream(ctx, systemPrompt, userPrompt, onToken)
+	return m.GenerateWithSystemStream(ctx, answerSystemPrompt(systemPrompt), userPrompt, onToken)
 }
 
 // ChatMessage represents a message in a multi-turn conversation.
@@ -342,13 +558,28 @@ type ChatMessage struct {
 }
 
 // GenerateWithSystemStreamMultiTurn generates text with a system prompt and multi-turn history,
-// streaming tokens via callback.
+// streaming tokens via callback. Falls back like GenerateWithSystemStream.
 func (m *Model) GenerateWithSystemStreamMultiTurn(
 	ctx context.Context,
 	systemPrompt string,
 	history []ChatMessage,
 	currentQuery string,
 	onToken func(token string) error,
+) error {
+	onToken, streamed := trackStreamed(onToken)
+	return m.withFallback(ctx, func(candidate *Model) error {
+		return candidate.generateWithSystemStreamMultiTurn(ctx, systemPrompt, history, currentQuery, onToken)
+	}, streamed)
+}
+
+// generateWithSystemStreamMultiTurn runs GenerateWithSystemStreamMultiTurn
+// against this model only.
+func (m *Model) generateWithSystemStreamMultiTurn(
+	ctx context.Context,
+	systemPrompt string,
+	history []ChatMessage,
+	currentQuery string,
+	onToken func(token string) error,
 ) error {
 	// Build message array: system + history + current query
 	messages := make([]llms.MessageContent, 0, 2+len(history))
@@ -371,7 +602,7 @@ func (m *Model) GenerateWithSystemStreamMultiTurn(
 		totalLen += len(msg.Content)
 	}
 
-	slog.Debug("LLM multi-turn streaming starting", "model", m.modelName, "history_len", len(history), "total_len", totalLen)
+	slog.DebugContext(ctx, "LLM multi-turn streaming starting", "model", m.modelName, "history_len", len(history), "total_len", totalLen)
 
 	start := time.Now()
 	var outputLen int
@@ -381,26 +612,56 @@ func (m *Model) GenerateWithSystemStreamMultiTurn(
 		return onToken(string(chunk))
 	}
 
-	response, err := m.llm.GenerateContent(ctx, messages, llms.WithMaxTokens(8192), llms.WithStreamingFunc(streamingFunc))
+	response, err := m.llm.GenerateContent(ctx, messages, append(m.callOptions(nil), llms.WithStreamingFunc(streamingFunc))...)
 	duration := time.Since(start)
 
 	if err != nil {
-		slog.Warn("LLM multi-turn streaming failed", "model", m.modelName, "total_len", totalLen, "duration_ms", duration.Milliseconds(), "error", err)
+		slog.WarnContext(ctx, "LLM multi-turn streaming failed", "model", m.modelName, "total_len", totalLen, "duration_ms", duration.Milliseconds(), "error", err)
 		return wrapFatalError(fmt.Errorf("generate multi-turn stream: %w", err))
 	}
 
-	slog.Debug("LLM multi-turn streaming complete", "model", m.modelName, "total_len", totalLen, "output_len", outputLen, "duration_ms", duration.Milliseconds())
+	slog.DebugContext(ctx, "LLM multi-turn streaming complete", "model", m.modelName, "total_len", totalLen, "output_len", outputLen, "duration_ms", duration.Milliseconds())
 
+	m.recordStreamUsage(ctx, response, duration, totalLen, outputLen)
+	return nil
+}
+
+// recordStreamUsage records metrics and token usage of a completed stream.
+func (m *Model) recordStreamUsage(ctx context.Context, response *llms.ContentResponse, duration time.Duration, inputLen, outputLen int) {
+	var genInfo map[string]any
+	if len(response.Choices) > 0 {
+		genInfo = response.Choices[0].GenerationInfo
+	}
+	inputTokens, outputTokens := extractTokenCounts(genInfo, inputLen, outputLen)
 	if m.metrics != nil {
-		var genInfo map[string]any
-		if len(response.Choices) > 0 {
-			genInfo = response.Choices[0].GenerationInfo
-		}
-		inputTokens, outputTokens := extractTokenCounts(genInfo, totalLen, outputLen)
-		m.metrics.RecordLLMUsage(metrics.OpLLMStream, duration, inputTokens, outputTokens)
+		m.metrics.RecordLLMUsage(metrics.OpLLMStream, m.name(), duration, inputTokens, outputTokens)
 	}
+	m.recordUsage(ctx, metrics.OpLLMStream, Usage{InputTokens: inputTokens, OutputTokens: outputTokens, Model: m.name()})
+}
 
-	return nil
+// DetectContradictions asks whether text makes factual claims contradicting
+// any of the numbered peers. The answer has one line per contradiction:
+// CONTRADICTION|peer number|confidence 0-1|explanation
+func (m *Model) DetectContradictions(ctx context.Context, text string, peers []string) (string, error) {
+	systemPrompt := `You are a fact checker for a knowledge base. Compare the main entry with each numbered peer entry and find contradictory factual claims: statements that cannot both be true (different values, dates, owners, versions, decisions).
+
+Output format (one per line):
+CONTRADICTION|peer_number|confidence|explanation
+
+Guidelines:
+- Only report direct contradictions, not differences in scope, detail or wording
+- confidence is a number between 0 and 1
+- explanation names both conflicting claims in one sentence
+- Output NONE if there are no contradictions`
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Main entry:\n%s\n", text)
+	for i, peer := range peers {
+		fmt.Fprintf(&b, "\nPeer %d:\n%s\n", i+1, peer)
+	}
+	b.WriteString("\nContradictions:")
+
+	return m.GenerateWithSystem(ctx, systemPrompt, b.String())
 }
 
 // ExtractEntitiesAndRelations extracts entities and relations from text (GraphRAG-style).
@@ -430,5 +691,6 @@ Guidelines:
 
 Extracted entities and relations:`, text, entitiesStr)
 
-	return m.GenerateWithSystem(ctx, systemPrompt, userPrompt)
+	// Deterministic, so re-extracting the same text yields the same graph
+	return m.GenerateWithSystem(ctx, systemPrompt, userPrompt, WithTemperature(0))
 }
@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raphaelgruber/memcp-go/internal/config"
+	"github.com/raphaelgruber/memcp-go/internal/metrics"
+)
+
+// modelCacheTTL is how long a model created for an override stays reusable.
+const modelCacheTTL = 10 * time.Minute
+
+// ModelCache creates models for per-request provider/model overrides and
+// keeps them briefly, so repeated overrides don't rebuild clients.
+// Provider settings (API keys, hosts) come from the server config.
+type ModelCache struct {
+	cfg     config.Config
+	metrics *metrics.Collector
+	usage   UsageRecorder
+
+	mu     sync.Mutex
+	models map[string]cachedModel
+}
+
+type cachedModel struct {
+	model   *Model
+	expires time.Time
+}
+
+// NewModelCache creates an empty cache using cfg for provider settings.
+// If mc is nil, metrics recording is disabled for created models; if usage
+// is nil, their token usage isn't persisted.
+func NewModelCache(cfg config.Config, mc *metrics.Collector, usage UsageRecorder) *ModelCache {
+	return &ModelCache{
+		cfg:     cfg,
+		metrics: mc,
+		usage:   usage,
+		models:  make(map[string]cachedModel),
+	}
+}
+
+// Get returns a model for the provider and model name, creating it if it
+// isn't cached. An empty provider means the configured LLM provider.
+// Returns an error for unknown providers or missing API keys.
+func (c *ModelCache) Get(provider, model string) (*Model, error) {
+	if model == "" {
+		return nil, fmt.Errorf("model name required")
+	}
+
+	p := config.LLMProvider(strings.ToLower(provider))
+	if p == "" {
+		p = c.cfg.LLMProvider
+	}
+	switch p {
+	case config.ProviderOllama, config.ProviderOpenAI, config.ProviderAnthropic, config.ProviderBedrock:
+	default:
+		return nil, fmt.Errorf("unsupported LLM provider %q (want ollama, openai, anthropic or bedrock)", p)
+	}
+
+	key := string(p) + "/" + model
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, cached := range c.models {
+		if now.After(cached.expires) {
+			delete(c.models, k)
+		}
+	}
+	if cached, ok := c.models[key]; ok {
+		return cached.model, nil
+	}
+
+	cfg := c.cfg
+	cfg.LLMProvider = p
+	cfg.LLMModel = model
+	// An explicitly requested model is used as is, without fallbacks
+	cfg.LLMFallback = nil
+	m, err := NewModel(cfg, c.metrics, c.usage)
+	if err != nil {
+		return nil, fmt.Errorf("create %s model %s: %w", p, model, err)
+	}
+
+	c.models[key] = cachedModel{model: m, expires: now.Add(modelCacheTTL)}
+	return m, nil
+}
@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/raphaelgruber/memcp-go/internal/config"
+)
+
+func TestModelCacheGet(t *testing.T) {
+	cache := NewModelCache(config.Config{
+		LLMProvider: config.ProviderOllama,
+		OllamaHost:  "http://localhost:11434",
+	}, nil, nil)
+
+	tests := []struct {
+		name     string
+		provider string
+		model    string
+		wantErr  bool
+	}{
+		{"configured provider", "", "llama3.2", false},
+		{"explicit provider", "ollama", "qwen2.5", false},
+		{"provider is case-insensitive", "Ollama", "qwen2.5", false},
+		{"missing model", "ollama", "", true},
+		{"unknown provider", "cohere", "command-r", true},
+		{"none provider", "none", "llama3.2", true},
+		{"missing openai key", "openai", "gpt-4o", true},
+		{"missing anthropic key", "anthropic", "claude-sonnet-4-5", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := cache.Get(tt.provider, tt.model)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get(%q, %q) error = %v, wantErr %v", tt.provider, tt.model, err, tt.wantErr)
+			}
+			if !tt.wantErr && m == nil {
+				t.Errorf("Get(%q, %q) returned nil model", tt.provider, tt.model)
+			}
+		})
+	}
+}
+
+func TestModelCacheReuse(t *testing.T) {
+	cache := NewModelCache(config.Config{
+		LLMProvider: config.ProviderOllama,
+		OllamaHost:  "http://localhost:11434",
+	}, nil, nil)
+
+	first, err := cache.Get("ollama", "llama3.2")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	second, err := cache.Get("", "llama3.2")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if first != second {
+		t.Error("expected cached model to be reused")
+	}
+
+	other, err := cache.Get("ollama", "qwen2.5")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if other == first {
+		t.Error("expected a different model for a different name")
+	}
+}
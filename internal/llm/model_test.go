@@ -1,9 +1,14 @@
 package llm
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
+	"unicode/utf8"
+
+	"github.com/raphaelgruber/memcp-go/internal/config"
+	"github.com/raphaelgruber/memcp-go/internal/metrics"
 )
 
 func TestIsFatalAPIError(t *testing.T) {
@@ -65,3 +70,115 @@ func TestWrapFatalError(t *testing.T) {
 		}
 	})
 }
+
+func TestAnswerSystemPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		prompt string
+		want   string
+	}{
+		{"empty uses default", "", DefaultAnswerSystemPrompt},
+		{"blank uses default", "  \n", DefaultAnswerSystemPrompt},
+		{"custom replaces default", "Answer in German.", "Answer in German."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := answerSystemPrompt(tt.prompt); got != tt.want {
+				t.Errorf("answerSystemPrompt(%q) = %q, want %q", tt.prompt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeRecordsOperation(t *testing.T) {
+	usage := &fakeUsageRecorder{}
+	m := newFakeModel(config.ProviderOpenAI, "gpt-4o-mini", &fakeLLM{response: " A summary. "}, usage)
+	ctx := context.Background()
+
+	summary, err := m.Summarize(ctx, "Some long document")
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if summary != "A summary." {
+		t.Errorf("summary = %q, want trimmed response", summary)
+	}
+	if _, err := m.GenerateWithSystem(ctx, "system", "user"); err != nil {
+		t.Fatalf("GenerateWithSystem() error = %v", err)
+	}
+
+	if len(usage.recorded) != 2 {
+		t.Fatalf("recorded %d usages, want 2", len(usage.recorded))
+	}
+	if got := usage.recorded[0].Operation; got != metrics.OpSummarize {
+		t.Errorf("summarize operation = %q, want %q", got, metrics.OpSummarize)
+	}
+	if got := usage.recorded[1].Operation; got != metrics.OpLLMGenerate {
+		t.Errorf("generate operation = %q, want %q", got, metrics.OpLLMGenerate)
+	}
+}
+
+func TestTruncateInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		maxBytes int
+		want     string
+	}{
+		{"short", "abc", 5, "abc"},
+		{"exact", "abcde", 5, "abcde"},
+		{"ascii cut", "abcdef", 4, "abcd"},
+		{"multi-byte rune kept whole", "aé", 3, "aé"},
+		{"cut backs up before a split rune", "aéb", 2, "a"},
+		{"cut inside a 4-byte rune", "ab😀", 4, "ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateInput(tt.s, tt.maxBytes)
+			if got != tt.want {
+				t.Errorf("truncateInput(%q, %d) = %q, want %q", tt.s, tt.maxBytes, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateInput(%q, %d) = %q, not valid UTF-8", tt.s, tt.maxBytes, got)
+			}
+		})
+	}
+}
+
+func TestGenerateOptions(t *testing.T) {
+	tests := []struct {
+		name            string
+		cfg             config.Config
+		opts            []GenOption
+		wantMaxTokens   int
+		wantTemperature float64
+		wantTopP        float64
+	}{
+		{"unconfigured", config.Config{LLMTemperature: -1}, nil, defaultMaxTokens, 0, 0},
+		{"configured defaults", config.Config{LLMTemperature: 0.7, LLMMaxTokens: 1024}, nil, 1024, 0.7, 0},
+		{"call overrides", config.Config{LLMTemperature: 0.7, LLMMaxTokens: 1024}, []GenOption{WithTemperature(0), WithMaxTokens(256), WithTopP(0.9)}, 256, 0, 0.9},
+		{"non-positive max tokens ignored", config.Config{LLMTemperature: -1}, []GenOption{WithMaxTokens(0)}, defaultMaxTokens, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeLLM{response: "ok"}
+			m := newFakeModel(config.ProviderOpenAI, "gpt-4o-mini", fake, nil)
+			m.genDefaults = defaultGenOptions(tt.cfg)
+
+			if _, err := m.GenerateWithSystem(context.Background(), "system", "user", tt.opts...); err != nil {
+				t.Fatalf("GenerateWithSystem() error = %v", err)
+			}
+			got := fake.lastOpts
+			if got.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("MaxTokens = %d, want %d", got.MaxTokens, tt.wantMaxTokens)
+			}
+			if got.Temperature != tt.wantTemperature {
+				t.Errorf("Temperature = %v, want %v", got.Temperature, tt.wantTemperature)
+			}
+			if got.TopP != tt.wantTopP {
+				t.Errorf("TopP = %v, want %v", got.TopP, tt.wantTopP)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"github.com/raphaelgruber/memcp-go/internal/config"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// defaultMaxTokens caps the output of a generation unless configured
+// otherwise.
+const defaultMaxTokens = 8192
+
+// GenOption adjusts the sampling of a single generation, overriding the
+// model's configured defaults.
+type GenOption func(*genOptions)
+
+// genOptions holds the sampling settings of a generation. Nil settings are
+// left to the provider.
+type genOptions struct {
+	temperature *float64
+	maxTokens   int
+	topP        *float64
+}
+
+// WithTemperature sets the sampling temperature. Use 0 for deterministic
+// output such as extraction.
+func WithTemperature(temperature float64) GenOption {
+	return func(o *genOptions) { o.temperature = &temperature }
+}
+
+// WithMaxTokens caps the number of generated tokens.
+func WithMaxTokens(maxTokens int) GenOption {
+	return func(o *genOptions) {
+		if maxTokens > 0 {
+			o.maxTokens = maxTokens
+		}
+	}
+}
+
+// WithTopP sets nucleus sampling: only tokens within the top p probability
+// mass are considered.
+func WithTopP(topP float64) GenOption {
+	return func(o *genOptions) { o.topP = &topP }
+}
+
+// defaultGenOptions returns the sampling defaults of cfg: KNOWHOW_LLM_MAX_TOKENS
+// and, if not negative, KNOWHOW_LLM_TEMPERATURE.
+func defaultGenOptions(cfg config.Config) genOptions {
+	o := genOptions{maxTokens: defaultMaxTokens}
+	if cfg.LLMMaxTokens > 0 {
+		o.maxTokens = cfg.LLMMaxTokens
+	}
+	if cfg.LLMTemperature >= 0 {
+		temperature := cfg.LLMTemperature
+		o.temperature = &temperature
+	}
+	return o
+}
+
+// callOptions returns the langchaingo call options of a generation: the
+// model's defaults with opts applied. All providers map these options to
+// their request parameters.
+func (m *Model) callOptions(opts []GenOption) []llms.CallOption {
+	o := m.genDefaults
+	if o.maxTokens <= 0 {
+		o.maxTokens = defaultMaxTokens
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	callOpts := []llms.CallOption{llms.WithMaxTokens(o.maxTokens)}
+	if o.temperature != nil {
+		callOpts = append(callOpts, llms.WithTemperature(*o.temperature))
+	}
+	if o.topP != nil {
+		callOpts = append(callOpts, llms.WithTopP(*o.topP))
+	}
+	return callOpts
+}
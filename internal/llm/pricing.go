@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// ModelPrice is the USD price of 1,000 tokens of a model.
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// Pricing maps model names to prices. Names are either "provider/model"
+// (e.g. "openai/gpt-4o-mini") or the bare model name, which applies to all
+// providers serving it.
+type Pricing map[string]ModelPrice
+
+// LoadPricing reads a pricing table from a JSON file of the form
+// {"gpt-4o-mini": {"input": 0.00015, "output": 0.0006}}. An empty path
+// returns a nil table, which disables cost tracking.
+func LoadPricing(path string) (Pricing, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read model pricing: %w", err)
+	}
+	var pricing Pricing
+	if err := json.Unmarshal(data, &pricing); err != nil {
+		return nil, fmt.Errorf("parse model pricing %s: %w", path, err)
+	}
+	for model, price := range pricing {
+		if price.Input < 0 || price.Output < 0 {
+			return nil, fmt.Errorf("parse model pricing %s: negative price for %s", path, model)
+		}
+	}
+	return pricing, nil
+}
+
+// Cost returns the USD cost of a call to model. The "provider/model" entry
+// wins over the bare model name. Models without a price cost 0.
+func (p Pricing) Cost(model string, inputTokens, outputTokens int) float64 {
+	price, ok := p[model]
+	if !ok {
+		_, bare, found := strings.Cut(model, "/")
+		if found {
+			price, ok = p[bare]
+		}
+	}
+	if !ok {
+		slog.Debug("no price configured for model, recording zero cost", "model", model)
+		return 0
+	}
+	return float64(inputTokens)/1000*price.Input + float64(outputTokens)/1000*price.Output
+}
+
+// pricedUsage is a UsageRecorder filling in the cost of calls from a
+// pricing table.
+type pricedUsage struct {
+	next    UsageRecorder
+	pricing Pricing
+}
+
+// PricedUsage returns a recorder that sets the cost of usage without one
+// from pricing, then records it with next.
+func PricedUsage(next UsageRecorder, pricing Pricing) UsageRecorder {
+	return pricedUsage{next: next, pricing: pricing}
+}
+
+func (r pricedUsage) RecordTokenUsage(ctx context.Context, input models.TokenUsageInput) error {
+	if input.CostUSD == nil {
+		cost := r.pricing.Cost(input.Model, input.InputTokens, input.OutputTokens)
+		input.CostUSD = &cost
+	}
+	return r.next.RecordTokenUsage(ctx, input)
+}
@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+func TestPricingCost(t *testing.T) {
+	pricing := Pricing{
+		"openai/gpt-4o-mini": {Input: 0.00015, Output: 0.0006},
+		"llama3.2":           {Input: 0.001, Output: 0.002},
+	}
+
+	tests := []struct {
+		name   string
+		model  string
+		input  int
+		output int
+		want   float64
+	}{
+		{"provider and model", "openai/gpt-4o-mini", 2000, 1000, 2*0.00015 + 0.0006},
+		{"bare model name", "ollama/llama3.2", 500, 1500, 0.5*0.001 + 1.5*0.002},
+		{"unknown model", "anthropic/claude-sonnet-4", 1000, 1000, 0},
+		{"no tokens", "openai/gpt-4o-mini", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pricing.Cost(tt.model, tt.input, tt.output)
+			if math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("Cost(%q, %d, %d) = %v, want %v", tt.model, tt.input, tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPricing(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+
+	pricing, err := LoadPricing("")
+	if err != nil || pricing != nil {
+		t.Errorf("LoadPricing(\"\") = %v, %v, want nil, nil", pricing, err)
+	}
+
+	pricing, err = LoadPricing(write("ok.json", `{"gpt-4o-mini": {"input": 0.00015, "output": 0.0006}}`))
+	if err != nil {
+		t.Fatalf("LoadPricing failed: %v", err)
+	}
+	if got := pricing["gpt-4o-mini"]; got.Input != 0.00015 || got.Output != 0.0006 {
+		t.Errorf("price = %+v, want input 0.00015, output 0.0006", got)
+	}
+
+	if _, err := LoadPricing(write("negative.json", `{"m": {"input": -1, "output": 0}}`)); err == nil {
+		t.Error("expected error for negative price")
+	}
+	if _, err := LoadPricing(write("invalid.json", `{"m": 1}`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+	if _, err := LoadPricing(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestPricedUsage(t *testing.T) {
+	ctx := context.Background()
+	next := &fakeUsageRecorder{}
+	usage := PricedUsage(next, Pricing{"openai/gpt-4o-mini": {Input: 0.001, Output: 0.002}})
+
+	if err := usage.RecordTokenUsage(ctx, models.TokenUsageInput{Model: "openai/gpt-4o-mini", InputTokens: 3000, OutputTokens: 1000}); err != nil {
+		t.Fatalf("RecordTokenUsage failed: %v", err)
+	}
+	given := 1.5
+	if err := usage.RecordTokenUsage(ctx, models.TokenUsageInput{Model: "openai/gpt-4o-mini", InputTokens: 1000, CostUSD: &given}); err != nil {
+		t.Fatalf("RecordTokenUsage failed: %v", err)
+	}
+
+	if len(next.recorded) != 2 {
+		t.Fatalf("recorded %d usages, want 2", len(next.recorded))
+	}
+	if cost := next.recorded[0].CostUSD; cost == nil || math.Abs(*cost-0.005) > 1e-12 {
+		t.Errorf("computed cost = %v, want 0.005", cost)
+	}
+	if cost := next.recorded[1].CostUSD; cost == nil || *cost != given {
+		t.Errorf("given cost = %v, want %v", cost, given)
+	}
+}
@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raphaelgruber/memcp-go/internal/config"
+	"github.com/raphaelgruber/memcp-go/internal/metrics"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/ollama"
+)
+
+// Reranker scores how relevant documents are to a query, for reordering
+// search results. Scores are comparable within a single call only.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, docs []string) ([]float64, error)
+}
+
+// rerankConcurrency bounds the number of documents scored at once.
+const rerankConcurrency = 4
+
+// rerankMaxDocChars truncates documents before scoring to keep prompts small.
+const rerankMaxDocChars = 2000
+
+const rerankPrompt = `Rate how relevant the document is to the query on a scale from 0 (unrelated) to 10 (answers the query directly).
+Respond with the number only.
+
+Query: %s
+
+Document:
+%s
+
+Relevance:`
+
+// OllamaReranker scores each (query, document) pair with an Ollama model
+// prompted for a relevance rating.
+type OllamaReranker struct {
+	llm       llms.Model
+	modelName string
+	metrics   *metrics.Collector
+	timeout   time.Duration // per scoring call, 0 = none
+}
+
+// NewReranker creates a reranker based on configuration. Returns nil if no
+// rerank model is configured. If mc is nil, metrics recording is disabled.
+func NewReranker(cfg config.Config, mc *metrics.Collector) (Reranker, error) {
+	if cfg.RerankModel == "" {
+		return nil, nil
+	}
+
+	model, err := ollama.New(
+		ollama.WithModel(cfg.RerankModel),
+		ollama.WithServerURL(cfg.OllamaHost),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create ollama reranker: %w", err)
+	}
+
+	return &OllamaReranker{
+		llm:       model,
+		modelName: cfg.RerankModel,
+		metrics:   mc,
+		timeout:   time.Duration(cfg.LLMTimeout) * time.Second,
+	}, nil
+}
+
+// Rerank returns a relevance score (0-10) for each document, in document
+// order. Fails if any document can't be scored.
+func (r *OllamaReranker) Rerank(ctx context.Context, query string, docs []string) ([]float64, error) {
+	start := time.Now()
+	scores := make([]float64, len(docs))
+	errs := make([]error, len(docs))
+
+	sem := make(chan struct{}, rerankConcurrency)
+	var wg sync.WaitGroup
+	for i, doc := range docs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			scores[i], errs[i] = r.score(ctx, query, doc)
+		}()
+	}
+	wg.Wait()
+
+	duration := time.Since(start)
+	if r.metrics != nil {
+		r.metrics.RecordTiming(metrics.OpRerank, duration)
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, wrapFatalError(fmt.Errorf("rerank: %w", err))
+		}
+	}
+	slog.DebugContext(ctx, "rerank complete", "model", r.modelName, "docs", len(docs), "duration_ms", duration.Milliseconds())
+	return scores, nil
+}
+
+// score asks the model to rate a single document.
+func (r *OllamaReranker) score(ctx context.Context, query, doc string) (float64, error) {
+	if len(doc) > rerankMaxDocChars {
+		doc = doc[:rerankMaxDocChars]
+	}
+
+	callCtx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	response, err := llms.GenerateFromSinglePrompt(callCtx, r.llm, fmt.Sprintf(rerankPrompt, query, doc),
+		llms.WithTemperature(0), llms.WithMaxTokens(8))
+	if err != nil {
+		return 0, timeoutError(ctx, callCtx, r.timeout, err)
+	}
+	return parseRelevanceScore(response)
+}
+
+// parseRelevanceScore extracts the first number of a model response and
+// clamps it to 0-10.
+func parseRelevanceScore(response string) (float64, error) {
+	field := strings.TrimLeft(response, " \t\r\n\"'*")
+	end := strings.IndexFunc(field, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if end >= 0 {
+		field = field[:end]
+	}
+	field = strings.TrimSuffix(field, ".")
+
+	score, err := strconv.ParseFloat(field, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse relevance score %q: %w", response, err)
+	}
+	return min(max(score, 0), 10), nil
+}
@@ -0,0 +1,34 @@
+package llm
+
+import "testing"
+
+func TestParseRelevanceScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     float64
+		wantErr  bool
+	}{
+		{"integer", "7", 7, false},
+		{"decimal", "6.5", 6.5, false},
+		{"whitespace", "  8\n", 8, false},
+		{"trailing text", "9 - directly answers the query", 9, false},
+		{"trailing period", "4.", 4, false},
+		{"quoted", `"3"`, 3, false},
+		{"clamped high", "42", 10, false},
+		{"no number", "relevant", 0, true},
+		{"empty", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRelevanceScore(tt.response)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRelevanceScore(%q) error = %v, wantErr %v", tt.response, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseRelevanceScore(%q) = %v, want %v", tt.response, got, tt.want)
+			}
+		})
+	}
+}
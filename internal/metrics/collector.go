@@ -25,31 +25,42 @@ type OperationMetrics struct {
 
 // OperationSnapshot provides computed stats from raw metrics.
 type OperationSnapshot struct {
-	Count       int64
-	TotalTimeMs int64
-	AvgTimeMs   float64
-	MinTimeMs   int64
-	MaxTimeMs   int64
+	Count       int64   `json:"count"`
+	TotalTimeMs int64   `json:"total_time_ms"`
+	AvgTimeMs   float64 `json:"avg_time_ms"`
+	MinTimeMs   int64   `json:"min_time_ms"`
+	MaxTimeMs   int64   `json:"max_time_ms"`
 
 	// Token stats (nil if not applicable)
-	TotalInputTokens  *int64
-	TotalOutputTokens *int64
-	AvgInputTokens    *float64
-	AvgOutputTokens   *float64
-	MinInputTokens    *int64
-	MaxInputTokens    *int64
-	MinOutputTokens   *int64
-	MaxOutputTokens   *int64
+	TotalInputTokens  *int64   `json:"total_input_tokens,omitempty"`
+	TotalOutputTokens *int64   `json:"total_output_tokens,omitempty"`
+	AvgInputTokens    *float64 `json:"avg_input_tokens,omitempty"`
+	AvgOutputTokens   *float64 `json:"avg_output_tokens,omitempty"`
+	MinInputTokens    *int64   `json:"min_input_tokens,omitempty"`
+	MaxInputTokens    *int64   `json:"max_input_tokens,omitempty"`
+	MinOutputTokens   *int64   `json:"min_output_tokens,omitempty"`
+	MaxOutputTokens   *int64   `json:"max_output_tokens,omitempty"`
 }
 
 // Snapshot represents the full server statistics at a point in time.
+// Field tags allow snapshots to be serialized for persistence.
 type Snapshot struct {
-	UptimeSeconds float64
-	Embedding     *OperationSnapshot
-	LLMGenerate   *OperationSnapshot
-	LLMStream     *OperationSnapshot
-	DBQuery       *OperationSnapshot
-	DBSearch      *OperationSnapshot
+	UptimeSeconds float64            `json:"uptime_seconds"`
+	Embedding     *OperationSnapshot `json:"embedding,omitempty"`
+	LLMGenerate   *OperationSnapshot `json:"llm_generate,omitempty"`
+	LLMStream     *OperationSnapshot `json:"llm_stream,omitempty"`
+	DBQuery       *OperationSnapshot `json:"db_query,omitempty"`
+	DBSearch      *OperationSnapshot `json:"db_search,omitempty"`
+	Rerank        *OperationSnapshot `json:"rerank,omitempty"`
+	AnswerCache   *CacheSnapshot     `json:"answer_cache,omitempty"`
+	EmbedCache    *CacheSnapshot     `json:"embed_cache,omitempty"`
+}
+
+// CacheSnapshot provides hit statistics of a cache.
+type CacheSnapshot struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"` // Hits / (Hits + Misses)
 }
 
 // Operation names for the collector.
@@ -59,6 +70,8 @@ const (
 	OpLLMStream   = "llm_stream"
 	OpDBQuery     = "db_query"
 	OpDBSearch    = "db_search"
+	OpRerank      = "rerank"
+	OpSummarize   = "summarize"
 )
 
 // Collector aggregates in-memory runtime statistics.
@@ -67,13 +80,38 @@ type Collector struct {
 	mu        sync.RWMutex
 	startTime time.Time
 	ops       map[string]*OperationMetrics
+
+	// Cumulative series for WritePrometheus, kept across Reset
+	histograms  map[string]*histogram
+	modelTokens map[string]*tokenCounts
+
+	// Cache lookups
+	answerCache cacheCounts
+	embedCache  cacheCounts
+}
+
+// cacheCounts counts lookups of a cache.
+type cacheCounts struct {
+	hits   int64
+	misses int64
+}
+
+// record counts a lookup. Caller must hold write lock.
+func (c *cacheCounts) record(hit bool) {
+	if hit {
+		c.hits++
+	} else {
+		c.misses++
+	}
 }
 
 // NewCollector creates a new metrics collector.
 func NewCollector() *Collector {
 	return &Collector{
-		startTime: time.Now(),
-		ops:       make(map[string]*OperationMetrics),
+		startTime:   time.Now(),
+		ops:         make(map[string]*OperationMetrics),
+		histograms:  make(map[string]*histogram),
+		modelTokens: make(map[string]*tokenCounts),
 	}
 }
 
@@ -107,10 +145,12 @@ func (c *Collector) RecordTiming(op string, duration time.Duration) {
 	if duration > m.MaxTime {
 		m.MaxTime = duration
 	}
+	c.observe(op, duration)
 }
 
-// RecordLLMUsage records timing and token usage for an LLM operation.
-func (c *Collector) RecordLLMUsage(op string, duration time.Duration, inputTokens, outputTokens int64) {
+// RecordLLMUsage records timing and token usage for an LLM operation by
+// model (provider/model).
+func (c *Collector) RecordLLMUsage(op, model string, duration time.Duration, inputTokens, outputTokens int64) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -140,6 +180,45 @@ func (c *Collector) RecordLLMUsage(op string, duration time.Duration, inputToken
 	if outputTokens > m.MaxOutputTokens {
 		m.MaxOutputTokens = outputTokens
 	}
+
+	c.observe(op, duration)
+	tokens, ok := c.modelTokens[model]
+	if !ok {
+		tokens = &tokenCounts{}
+		c.modelTokens[model] = tokens
+	}
+	tokens.input += inputTokens
+	tokens.output += outputTokens
+}
+
+// RecordCacheLookup records an answer cache hit or miss.
+func (c *Collector) RecordCacheLookup(hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.answerCache.record(hit)
+}
+
+// RecordEmbedCacheLookup records an embedding cache hit or miss.
+func (c *Collector) RecordEmbedCacheLookup(hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.embedCache.record(hit)
+}
+
+// snapshotCache creates a cache snapshot, returning nil if there were no lookups.
+// Caller must hold read lock.
+func snapshotCache(counts cacheCounts) *CacheSnapshot {
+	total := counts.hits + counts.misses
+	if total == 0 {
+		return nil
+	}
+	return &CacheSnapshot{
+		Hits:    counts.hits,
+		Misses:  counts.misses,
+		HitRate: float64(counts.hits) / float64(total),
+	}
 }
 
 // snapshotOp creates a snapshot for an operation, returning nil if no data.
@@ -199,5 +278,19 @@ func (c *Collector) Snapshot() Snapshot {
 		LLMStream:     snapshotOp(c.ops[OpLLMStream], true),
 		DBQuery:       snapshotOp(c.ops[OpDBQuery], false),
 		DBSearch:      snapshotOp(c.ops[OpDBSearch], false),
+		Rerank:        snapshotOp(c.ops[OpRerank], false),
+		AnswerCache:   snapshotCache(c.answerCache),
+		EmbedCache:    snapshotCache(c.embedCache),
 	}
 }
+
+// Reset clears all operation counters. Uptime and the cumulative series of
+// WritePrometheus are not affected.
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ops = make(map[string]*OperationMetrics)
+	c.answerCache = cacheCounts{}
+	c.embedCache = cacheCounts{}
+}
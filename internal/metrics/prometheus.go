@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationBuckets are the upper bounds in seconds of the operation duration
+// histograms, spanning fast DB queries to slow LLM generations.
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// promOperations are the operations exported as duration histograms, in
+// output order.
+var promOperations = []string{OpDBQuery, OpDBSearch, OpEmbedding, OpLLMGenerate, OpLLMStream, OpRerank}
+
+// histogram counts operation durations per bucket of durationBuckets.
+type histogram struct {
+	counts []int64 // per bucket, not cumulative; the last one is +Inf
+	sum    time.Duration
+	count  int64
+}
+
+// observe adds a duration. Caller must hold write lock.
+func (h *histogram) observe(d time.Duration) {
+	if h.counts == nil {
+		h.counts = make([]int64, len(durationBuckets)+1)
+	}
+	i := len(durationBuckets)
+	for j, bound := range durationBuckets {
+		if d.Seconds() <= bound {
+			i = j
+			break
+		}
+	}
+	h.counts[i]++
+	h.sum += d
+	h.count++
+}
+
+// tokenCounts sums the tokens used by a model.
+type tokenCounts struct {
+	input  int64
+	output int64
+}
+
+// observe records an operation duration for the Prometheus histograms.
+// Caller must hold write lock.
+func (c *Collector) observe(op string, d time.Duration) {
+	h, ok := c.histograms[op]
+	if !ok {
+		h = &histogram{}
+		c.histograms[op] = h
+	}
+	h.observe(d)
+}
+
+// labelEscaper escapes Prometheus label values.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus writes operation duration histograms and token counters
+// by model in the Prometheus text exposition format. The series are
+// cumulative since server start and unaffected by Reset.
+func (c *Collector) WritePrometheus(w io.Writer) error {
+	var b strings.Builder
+
+	c.mu.RLock()
+	b.WriteString("# HELP knowhow_operation_duration_seconds Duration of knowhow operations.\n")
+	b.WriteString("# TYPE knowhow_operation_duration_seconds histogram\n")
+	for _, op := range promOperations {
+		h := c.histograms[op]
+		if h == nil {
+			h = &histogram{}
+		}
+		var cumulative int64
+		for i, bound := range durationBuckets {
+			if h.counts != nil {
+				cumulative += h.counts[i]
+			}
+			fmt.Fprintf(&b, "knowhow_operation_duration_seconds_bucket{operation=%q,le=%q} %d\n",
+				op, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&b, "knowhow_operation_duration_seconds_bucket{operation=%q,le=\"+Inf\"} %d\n", op, h.count)
+		fmt.Fprintf(&b, "knowhow_operation_duration_seconds_sum{operation=%q} %g\n", op, h.sum.Seconds())
+		fmt.Fprintf(&b, "knowhow_operation_duration_seconds_count{operation=%q} %d\n", op, h.count)
+	}
+
+	b.WriteString("# HELP knowhow_llm_tokens_total Tokens used by LLM generations.\n")
+	b.WriteString("# TYPE knowhow_llm_tokens_total counter\n")
+	models := make([]string, 0, len(c.modelTokens))
+	for model := range c.modelTokens {
+		models = append(models, model)
+	}
+	slices.Sort(models)
+	for _, model := range models {
+		tokens := c.modelTokens[model]
+		escaped := labelEscaper.Replace(model)
+		fmt.Fprintf(&b, "knowhow_llm_tokens_total{model=\"%s\",direction=\"input\"} %d\n", escaped, tokens.input)
+		fmt.Fprintf(&b, "knowhow_llm_tokens_total{model=\"%s\",direction=\"output\"} %d\n", escaped, tokens.output)
+	}
+
+	b.WriteString("# HELP knowhow_uptime_seconds Seconds since the server started.\n")
+	b.WriteString("# TYPE knowhow_uptime_seconds gauge\n")
+	fmt.Fprintf(&b, "knowhow_uptime_seconds %g\n", time.Since(c.startTime).Seconds())
+	c.mu.RUnlock()
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteRuntimePrometheus writes Go runtime metrics (goroutines, memory, GC)
+// in the Prometheus text exposition format.
+func WriteRuntimePrometheus(w io.Writer) error {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	var b strings.Builder
+	gauge := func(name, help string, value float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+	}
+	gauge("go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine()))
+	gauge("go_memstats_alloc_bytes", "Bytes of allocated heap objects.", float64(mem.HeapAlloc))
+	gauge("go_memstats_sys_bytes", "Bytes of memory obtained from the OS.", float64(mem.Sys))
+	gauge("go_memstats_heap_inuse_bytes", "Bytes in in-use heap spans.", float64(mem.HeapInuse))
+	fmt.Fprintf(&b, "# HELP go_gc_cycles_total Number of completed GC cycles.\n# TYPE go_gc_cycles_total counter\ngo_gc_cycles_total %d\n", mem.NumGC)
+	fmt.Fprintf(&b, "# HELP go_gc_pause_seconds_total Total GC stop-the-world pause time.\n# TYPE go_gc_pause_seconds_total counter\ngo_gc_pause_seconds_total %g\n",
+		time.Duration(mem.PauseTotalNs).Seconds())
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
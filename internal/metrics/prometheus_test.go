@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// wantPrometheus is the exposition of the collector in TestWritePrometheus,
+// up to the uptime value, which varies.
+const wantPrometheus = `# HELP knowhow_operation_duration_seconds Duration of knowhow operations.
+# TYPE knowhow_operation_duration_seconds histogram
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="0.005"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="0.01"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="0.025"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="0.05"} 1
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="0.1"} 1
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="0.25"} 1
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="0.5"} 1
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="1"} 1
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="2.5"} 2
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="5"} 2
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="10"} 2
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="30"} 2
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="60"} 2
+knowhow_operation_duration_seconds_bucket{operation="db_query",le="+Inf"} 2
+knowhow_operation_duration_seconds_sum{operation="db_query"} 2.03
+knowhow_operation_duration_seconds_count{operation="db_query"} 2
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="0.005"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="0.01"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="0.025"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="0.05"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="0.1"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="0.25"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="0.5"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="1"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="2.5"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="5"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="10"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="30"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="60"} 0
+knowhow_operation_duration_seconds_bucket{operation="db_search",le="+Inf"} 0
+knowhow_operation_duration_seconds_sum{operation="db_search"} 0
+knowhow_operation_duration_seconds_count{operation="db_search"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="0.005"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="0.01"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="0.025"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="0.05"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="0.1"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="0.25"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="0.5"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="1"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="2.5"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="5"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="10"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="30"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="60"} 0
+knowhow_operation_duration_seconds_bucket{operation="embedding",le="+Inf"} 0
+knowhow_operation_duration_seconds_sum{operation="embedding"} 0
+knowhow_operation_duration_seconds_count{operation="embedding"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="0.005"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="0.01"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="0.025"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="0.05"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="0.1"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="0.25"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="0.5"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="1"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="2.5"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="5"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="10"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="30"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="60"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_generate",le="+Inf"} 1
+knowhow_operation_duration_seconds_sum{operation="llm_generate"} 90
+knowhow_operation_duration_seconds_count{operation="llm_generate"} 1
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="0.005"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="0.01"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="0.025"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="0.05"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="0.1"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="0.25"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="0.5"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="1"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="2.5"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="5"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="10"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="30"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="60"} 0
+knowhow_operation_duration_seconds_bucket{operation="llm_stream",le="+Inf"} 0
+knowhow_operation_duration_seconds_sum{operation="llm_stream"} 0
+knowhow_operation_duration_seconds_count{operation="llm_stream"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="0.005"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="0.01"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="0.025"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="0.05"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="0.1"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="0.25"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="0.5"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="1"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="2.5"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="5"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="10"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="30"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="60"} 0
+knowhow_operation_duration_seconds_bucket{operation="rerank",le="+Inf"} 0
+knowhow_operation_duration_seconds_sum{operation="rerank"} 0
+knowhow_operation_duration_seconds_count{operation="rerank"} 0
+# HELP knowhow_llm_tokens_total Tokens used by LLM generations.
+# TYPE knowhow_llm_tokens_total counter
+knowhow_llm_tokens_total{model="openai/\"gpt\"",direction="input"} 10
+knowhow_llm_tokens_total{model="openai/\"gpt\"",direction="output"} 5
+# HELP knowhow_uptime_seconds Seconds since the server started.
+# TYPE knowhow_uptime_seconds gauge
+knowhow_uptime_seconds `
+
+func TestWritePrometheus(t *testing.T) {
+	c := NewCollector()
+	c.RecordTiming(OpDBQuery, 30*time.Millisecond)
+	c.RecordTiming(OpDBQuery, 2*time.Second)
+	// Quotes in the model must be escaped in the label value
+	c.RecordLLMUsage(OpLLMGenerate, `openai/"gpt"`, 90*time.Second, 10, 5)
+
+	var b strings.Builder
+	if err := c.WritePrometheus(&b); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	got := b.String()
+
+	uptime, ok := strings.CutPrefix(got, wantPrometheus)
+	if !ok {
+		t.Fatalf("WritePrometheus() =\n%s\nwant prefix\n%s", got, wantPrometheus)
+	}
+	if !strings.HasSuffix(uptime, "\n") || strings.Count(uptime, "\n") != 1 {
+		t.Errorf("uptime line = %q, want one value line", uptime)
+	}
+}
@@ -23,3 +23,10 @@ type Message struct {
 	Content      string                 `json:"content"`
 	CreatedAt    time.Time              `json:"created_at"`
 }
+
+// MessageSearchResult is a message found by a conversation search.
+type MessageSearchResult struct {
+	Message
+	ConversationTitle string  `json:"conversation_title"`
+	Distance          float64 `json:"distance,omitempty"` // Vector distance to the query, 0 for text matches
+}
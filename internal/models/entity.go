@@ -31,7 +31,8 @@ type Entity struct {
 	Summary *string `json:"summary,omitempty"` // Short description
 
 	// Organization
-	Labels []string `json:"labels"` // Flexible tags ["work", "banking", "team-platform"]
+	Labels  []string `json:"labels"`            // Flexible tags ["work", "banking", "team-platform"]
+	Aliases []string `json:"aliases,omitempty"` // Lowercased alternative names ["k8s"], unique across entities
 
 	// Content Hash (for skip-unchanged deduplication)
 	ContentHash *string `json:"content_hash,omitempty"` // SHA256 of raw file bytes
@@ -51,8 +52,21 @@ type Entity struct {
 	// Timestamps
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	ModifiedAt  time.Time `json:"modified_at"` // Last content or metadata change, unlike UpdatedAt not bumped by access tracking
 	Accessed    time.Time `json:"accessed"`
 	AccessCount int       `json:"access_count"`
+
+	// Included in the context of every ask, regardless of the query
+	AlwaysInContext bool `json:"always_in_context"`
+
+	// Exempt from decay: its decay weight stays 1.0 however long it's unused
+	Pinned bool `json:"pinned"`
+
+	// ISO 639-1 code of the content's language, nil if unknown
+	Language *string `json:"language,omitempty"`
+
+	// Project namespace the entity belongs to, nil if global
+	Context *string `json:"context,omitempty"`
 }
 
 // EntityInput is the input structure for creating/updating entities.
@@ -72,6 +86,8 @@ type EntityInput struct {
 	Source      *EntitySource  `json:"source,omitempty"`
 	SourcePath  *string        `json:"source_path,omitempty"`
 	Metadata    map[string]any `json:"metadata,omitempty"`
+	Language    *string        `json:"language,omitempty"`
+	Context     *string        `json:"context,omitempty"` // Project namespace, nil = global
 	Embedding   []float32      `json:"embedding,omitempty"`
 }
 
@@ -87,6 +103,7 @@ type EntityUpdate struct {
 	Verified   *bool             `json:"verified,omitempty"`
 	Confidence *float64          `json:"confidence,omitempty"`
 	Metadata   map[string]any    `json:"metadata,omitempty"`
+	Language   *string           `json:"language,omitempty"` // Empty clears it
 	Embedding  []float32         `json:"embedding,omitempty"`
 }
 
@@ -98,9 +115,13 @@ type EntitySearchResult struct {
 }
 
 // ChunkMatch represents a matching chunk within a search result.
+// EntityID and EntityName are only set by chunk-level search, where the
+// chunk isn't nested in its entity.
 type ChunkMatch struct {
 	Content     string  `json:"content"`
 	HeadingPath *string `json:"heading_path,omitempty"`
 	Position    int     `json:"position"`
 	Score       float64 `json:"score,omitempty"`
+	EntityID    string  `json:"entity_id,omitempty"`
+	EntityName  string  `json:"entity_name,omitempty"`
 }
@@ -45,6 +45,14 @@ type RelationInput struct {
 	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
+// PathStep is a single hop along a path between two entities.
+type PathStep struct {
+	FromID  string `json:"from_id"`  // Entity the step starts at
+	ToID    string `json:"to_id"`    // Entity the step ends at
+	RelType string `json:"rel_type"` // Type of the traversed relation
+	Reverse bool   `json:"reverse"`  // True if the relation points ToID -> FromID
+}
+
 // Contradiction represents a detected conflict between two entities.
 type Contradiction struct {
 	ID surrealmodels.RecordID `json:"id"`
@@ -74,25 +82,27 @@ type ContradictionInput struct {
 type TokenUsage struct {
 	ID surrealmodels.RecordID `json:"id"`
 
-	Operation    string   `json:"operation"`              // "embed", "ask", "extract_graph", "render"
-	Model        string   `json:"model"`                  // "gpt-4", "claude-3", "ollama/llama3"
-	InputTokens  int      `json:"input_tokens"`
-	OutputTokens int      `json:"output_tokens"`
-	TotalTokens  int      `json:"total_tokens"`
-	CostUSD      *float64 `json:"cost_usd,omitempty"`     // Estimated cost if known
-	EntityID     *string  `json:"entity_id,omitempty"`    // Related entity if applicable
+	Operation      string   `json:"operation"` // "embed", "ask", "extract_graph", "render"
+	Model          string   `json:"model"`     // "gpt-4", "claude-3", "ollama/llama3"
+	InputTokens    int      `json:"input_tokens"`
+	OutputTokens   int      `json:"output_tokens"`
+	TotalTokens    int      `json:"total_tokens"`
+	CostUSD        *float64 `json:"cost_usd,omitempty"`        // Estimated cost if known
+	EntityID       *string  `json:"entity_id,omitempty"`       // Related entity if applicable
+	ConversationID *string  `json:"conversation_id,omitempty"` // Conversation the call was made for
 
 	CreatedAt time.Time `json:"created_at"`
 }
 
 // TokenUsageInput is the input structure for recording token usage.
 type TokenUsageInput struct {
-	Operation    string   `json:"operation"`
-	Model        string   `json:"model"`
-	InputTokens  int      `json:"input_tokens"`
-	OutputTokens int      `json:"output_tokens"`
-	CostUSD      *float64 `json:"cost_usd,omitempty"`
-	EntityID     *string  `json:"entity_id,omitempty"`
+	Operation      string   `json:"operation"`
+	Model          string   `json:"model"`
+	InputTokens    int      `json:"input_tokens"`
+	OutputTokens   int      `json:"output_tokens"`
+	CostUSD        *float64 `json:"cost_usd,omitempty"`
+	EntityID       *string  `json:"entity_id,omitempty"`
+	ConversationID *string  `json:"conversation_id,omitempty"`
 }
 
 // TokenUsageSummary provides aggregated token usage statistics.
@@ -103,3 +113,18 @@ type TokenUsageSummary struct {
 	ByModel          map[string]int   `json:"by_model"`           // model -> token count
 	OperationPercent map[string]float64 `json:"operation_percent"` // operation -> percentage
 }
+
+// AnswerSourceInput records an entity retrieved for an ask.
+type AnswerSourceInput struct {
+	EntityID string `json:"entity_id"`
+	Rank     int    `json:"rank"`     // 1-based position in the answer context
+	Included bool   `json:"included"` // Whether the entity contributed text to the context
+}
+
+// AnswerSourceStats aggregates how often an entity was retrieved for asks.
+type AnswerSourceStats struct {
+	EntityID  string  `json:"entity_id"`
+	Retrieved int     `json:"retrieved"` // Asks that retrieved the entity
+	Included  int     `json:"included"`  // Asks whose context included its text
+	AvgRank   float64 `json:"avg_rank"`
+}
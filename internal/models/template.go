@@ -6,6 +6,11 @@ import (
 	surrealmodels "github.com/surrealdb/surrealdb.go/pkg/models"
 )
 
+// SystemPromptPresetPrefix marks templates holding a reusable system prompt
+// for answer synthesis rather than an output template. A preset named "terse"
+// is stored as the template "system-prompt:terse".
+const SystemPromptPresetPrefix = "system-prompt:"
+
 // Template represents an output rendering template for synthesizing knowledge.
 // Templates are used to generate structured documents from accumulated knowledge.
 type Template struct {
@@ -3,6 +3,7 @@ package parser
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // ChunkResult represents a chunk of content.
@@ -12,8 +13,23 @@ type ChunkResult struct {
 	HeadingPath string // Section context
 }
 
+// ChunkStrategy selects how long content is split into chunks.
+type ChunkStrategy string
+
+const (
+	// ChunkByHeading splits at section headings, then paragraphs and
+	// sentences (default)
+	ChunkByHeading ChunkStrategy = "heading"
+	// ChunkFixed splits into TargetSize windows repeating Overlap characters
+	ChunkFixed ChunkStrategy = "fixed"
+	// ChunkBySentence packs sentences into chunks of up to TargetSize
+	ChunkBySentence ChunkStrategy = "sentence"
+)
+
 // ChunkConfig defines chunking parameters.
 type ChunkConfig struct {
+	// Strategy: how to split (empty = ChunkByHeading)
+	Strategy ChunkStrategy
 	// Threshold: only chunk if content exceeds this length
 	Threshold int
 	// TargetSize: ideal chunk size
@@ -59,6 +75,17 @@ func ChunkMarkdown(doc *MarkdownDoc, config ChunkConfig) []ChunkResult {
 		}}
 	}
 
+	switch config.Strategy {
+	case ChunkFixed:
+		return chunkFixed(doc.Content, config)
+	case ChunkBySentence:
+		var chunks []ChunkResult
+		for i, content := range chunkBySentences(doc.Content, config) {
+			chunks = append(chunks, ChunkResult{Content: content, Position: i})
+		}
+		return applyOverlap(chunks, config.Overlap)
+	}
+
 	// If we have sections, chunk by section first
 	if len(doc.Sections) > 0 {
 		return chunkBySections(doc.Sections, config)
@@ -179,6 +206,51 @@ func chunkByParagraphs(content string, config ChunkConfig) []ChunkResult {
 	return chunks
 }
 
+// chunkFixed splits content into windows of TargetSize characters, each
+// starting with the last Overlap characters of the previous one. Windows end
+// on rune boundaries, so multi-byte characters are never cut.
+func chunkFixed(content string, config ChunkConfig) []ChunkResult {
+	content = strings.TrimSpace(content)
+	size := max(config.TargetSize, 1)
+	overlap := min(max(config.Overlap, 0), size-1)
+
+	var chunks []ChunkResult
+	for start := 0; start < len(content); {
+		end := len(content)
+		if start+size < end {
+			end = runeStart(content, start+size)
+			if end <= start {
+				end = runeEnd(content, start)
+			}
+		}
+		chunks = append(chunks, ChunkResult{Content: content[start:end], Position: len(chunks)})
+		if end == len(content) {
+			break
+		}
+
+		next := runeStart(content, end-overlap)
+		if next <= start {
+			next = end
+		}
+		start = next
+	}
+	return chunks
+}
+
+// runeStart moves i back to the start of the rune it falls in.
+func runeStart(s string, i int) int {
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
+
+// runeEnd returns the index after the rune starting at i.
+func runeEnd(s string, i int) int {
+	_, size := utf8.DecodeRuneInString(s[i:])
+	return i + size
+}
+
 // chunkBySentences splits text by sentence boundaries.
 func chunkBySentences(text string, config ChunkConfig) []string {
 	sentences := splitSentences(text)
@@ -242,6 +314,33 @@ func splitSentences(text string) []string {
 	return sentences
 }
 
+// JoinChunks reassembles content from chunks created without overlap, in
+// position order. Section headings are restored from heading paths. Spacing
+// is normalized, so the text matches the original but not byte for byte.
+func JoinChunks(chunks []ChunkResult) string {
+	var b strings.Builder
+	var prevPath []string
+	for _, chunk := range chunks {
+		var path []string
+		if chunk.HeadingPath != "" {
+			path = strings.Split(chunk.HeadingPath, " > ")
+		}
+
+		// Emit headings below the part of the path shared with the previous chunk
+		shared := 0
+		for shared < len(path) && shared < len(prevPath) && path[shared] == prevPath[shared] {
+			shared++
+		}
+		for _, heading := range path[shared:] {
+			b.WriteString(heading + "\n\n")
+		}
+		prevPath = path
+
+		b.WriteString(strings.TrimSpace(chunk.Content) + "\n\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
 // applyOverlap adds overlap between adjacent chunks using semantic boundaries.
 // Prefers sentence boundaries (.!?) over word boundaries for better context.
 func applyOverlap(chunks []ChunkResult, overlap int) []ChunkResult {
@@ -1,8 +1,10 @@
 package parser
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestChunkMarkdown_EmptyContent(t *testing.T) {
@@ -251,3 +253,125 @@ func TestApplyOverlap_EdgeCases(t *testing.T) {
 		t.Errorf("zero overlap should not modify chunks, got %q", result[1].Content)
 	}
 }
+
+func TestJoinChunks(t *testing.T) {
+	chunks := []ChunkResult{
+		{Content: "Intro text.", Position: 0, HeadingPath: "# Guide"},
+		{Content: "Install steps.", Position: 1, HeadingPath: "# Guide > ## Setup > ### Install"},
+		{Content: "More install steps.", Position: 2, HeadingPath: "# Guide > ## Setup > ### Install"},
+		{Content: "Config steps.", Position: 3, HeadingPath: "# Guide > ## Setup > ### Configure"},
+		{Content: "Usage text.", Position: 4, HeadingPath: "# Guide > ## Usage"},
+	}
+
+	want := "# Guide\n\nIntro text.\n\n## Setup\n\n### Install\n\nInstall steps.\n\nMore install steps.\n\n" +
+		"### Configure\n\nConfig steps.\n\n## Usage\n\nUsage text."
+	if got := JoinChunks(chunks); got != want {
+		t.Errorf("JoinChunks() =\n%q\nwant\n%q", got, want)
+	}
+
+	if got := JoinChunks(nil); got != "" {
+		t.Errorf("JoinChunks(nil) = %q, want empty", got)
+	}
+}
+
+func TestJoinChunks_RoundTrip(t *testing.T) {
+	var content strings.Builder
+	content.WriteString("# Runbook\n\n")
+	for _, section := range []string{"Alerts", "Mitigation", "Escalation"} {
+		content.WriteString("## " + section + "\n\n")
+		for i := 0; i < 6; i++ {
+			content.WriteString(strings.Repeat(section+" detail sentence. ", 12) + "\n\n")
+		}
+	}
+
+	doc, err := ParseMarkdown(content.String())
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	config := DefaultChunkConfig()
+	config.Overlap = 0
+	chunks := ChunkMarkdown(doc, config)
+	if len(chunks) < 2 {
+		t.Fatalf("expected content to be chunked, got %d chunks", len(chunks))
+	}
+
+	joined := JoinChunks(chunks)
+	normalize := func(s string) string { return strings.Join(strings.Fields(s), " ") }
+	if normalize(joined) != normalize(content.String()) {
+		t.Errorf("JoinChunks() does not reproduce the content:\n%s", joined)
+	}
+}
+
+func TestChunkMarkdown_FixedOverlap(t *testing.T) {
+	var content strings.Builder
+	for i := 0; content.Len() < 3000; i++ {
+		content.WriteString("Die Größe des Pakets wird geprüft. ")
+	}
+
+	doc, err := ParseMarkdown(content.String())
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	config := DefaultChunkConfig()
+	config.Strategy = ChunkFixed
+	config.TargetSize = 500
+	config.Overlap = 50
+	chunks := ChunkMarkdown(doc, config)
+	if len(chunks) < 2 {
+		t.Fatalf("expected content to be chunked, got %d chunks", len(chunks))
+	}
+
+	for i, chunk := range chunks {
+		if len(chunk.Content) > config.TargetSize {
+			t.Errorf("chunk %d has %d bytes, want at most %d", i, len(chunk.Content), config.TargetSize)
+		}
+		if !utf8.ValidString(chunk.Content) {
+			t.Errorf("chunk %d cuts a multi-byte character", i)
+		}
+		if i == 0 {
+			continue
+		}
+		// Overlap is Overlap bytes, less up to a rune when a character straddles it
+		prev := chunks[i-1].Content
+		overlaps := false
+		for n := config.Overlap; n > config.Overlap-utf8.UTFMax; n-- {
+			if strings.HasSuffix(prev, chunk.Content[:n]) {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			t.Errorf("chunk %d doesn't start with the end of chunk %d:\nend:   %q\nstart: %q", i, i-1, prev[len(prev)-config.Overlap:], chunk.Content[:config.Overlap])
+		}
+	}
+}
+
+func TestChunkMarkdown_SentenceOverlap(t *testing.T) {
+	var content strings.Builder
+	for i := 0; content.Len() < 3000; i++ {
+		fmt.Fprintf(&content, "Step %d restarts the worker pool.\n\n", i)
+	}
+
+	doc, err := ParseMarkdown(content.String())
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	config := DefaultChunkConfig()
+	config.Strategy = ChunkBySentence
+	chunks := ChunkMarkdown(doc, config)
+	if len(chunks) < 2 {
+		t.Fatalf("expected content to be chunked, got %d chunks", len(chunks))
+	}
+
+	for i := 1; i < len(chunks); i++ {
+		prev := chunks[i-1].Content
+		// The overlap is the previous chunk's last sentence(s) within Overlap
+		lastSentence := prev[strings.LastIndex(prev, "Step "):]
+		if !strings.HasPrefix(chunks[i].Content, lastSentence+" ") {
+			t.Errorf("chunk %d doesn't start with the last sentence of chunk %d (%q):\n%q", i, i-1, lastSentence, chunks[i].Content)
+		}
+		if !strings.HasSuffix(chunks[i].Content, ".") {
+			t.Errorf("chunk %d doesn't end on a sentence boundary: %q", i, chunks[i].Content)
+		}
+	}
+}
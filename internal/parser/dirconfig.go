@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DirConfigFile is the per-directory ingest config file name.
+const DirConfigFile = ".knowhow.yaml"
+
+// DirConfig holds ingest defaults for the files in a directory.
+//
+// Example .knowhow.yaml:
+//
+//	type: runbook
+//	labels: [ops, oncall]
+//	extract_graph: true
+type DirConfig struct {
+	Type          string   `yaml:"type"`           // Entity type when frontmatter has none
+	Labels        []string `yaml:"labels"`         // Labels added to every file
+	ExtractGraph  *bool    `yaml:"extract_graph"`  // Extract relations using LLM
+	AutoSummarize *bool    `yaml:"auto_summarize"` // Generate summaries using LLM
+}
+
+// Merge returns c overridden by a more specific (nested) config.
+// Labels accumulate; other fields are replaced when set in child.
+func (c DirConfig) Merge(child DirConfig) DirConfig {
+	merged := c
+	if child.Type != "" {
+		merged.Type = child.Type
+	}
+	if len(child.Labels) > 0 {
+		merged.Labels = append(append([]string{}, c.Labels...), child.Labels...)
+	}
+	if child.ExtractGraph != nil {
+		merged.ExtractGraph = child.ExtractGraph
+	}
+	if child.AutoSummarize != nil {
+		merged.AutoSummarize = child.AutoSummarize
+	}
+	return merged
+}
+
+// LoadDirConfig reads the .knowhow.yaml in dir.
+// A missing file yields an empty config.
+func LoadDirConfig(dir string) (DirConfig, error) {
+	var cfg DirConfig
+	data, err := os.ReadFile(filepath.Join(dir, DirConfigFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("read %s: %w", DirConfigFile, err)
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse %s in %s: %w", DirConfigFile, dir, err)
+	}
+	return cfg, nil
+}
+
+// ResolveDirConfig returns the cascaded config for filePath: configs from
+// rootDir down to the file's directory are merged, nearest last.
+// Directories outside rootDir are not consulted.
+func ResolveDirConfig(rootDir, filePath string) (DirConfig, error) {
+	root := filepath.Clean(rootDir)
+	rel, err := filepath.Rel(root, filepath.Dir(filePath))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return DirConfig{}, fmt.Errorf("%s is not inside %s", filePath, rootDir)
+	}
+
+	dirs := []string{root}
+	if rel != "." {
+		current := root
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			current = filepath.Join(current, part)
+			dirs = append(dirs, current)
+		}
+	}
+
+	var cfg DirConfig
+	for _, dir := range dirs {
+		dirCfg, err := LoadDirConfig(dir)
+		if err != nil {
+			return DirConfig{}, err
+		}
+		cfg = cfg.Merge(dirCfg)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeDirConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, DirConfigFile), []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+}
+
+func TestResolveDirConfig_Cascade(t *testing.T) {
+	root := t.TempDir()
+	writeDirConfig(t, root, "type: note\nlabels: [work]\nextract_graph: true\n")
+	writeDirConfig(t, filepath.Join(root, "ops"), "type: runbook\nlabels: [oncall]\nextract_graph: false\n")
+
+	tests := []struct {
+		name         string
+		file         string
+		wantType     string
+		wantLabels   []string
+		wantExtract  bool
+		wantSumUnset bool
+	}{
+		{
+			name:         "root file uses root config",
+			file:         filepath.Join(root, "a.md"),
+			wantType:     "note",
+			wantLabels:   []string{"work"},
+			wantExtract:  true,
+			wantSumUnset: true,
+		},
+		{
+			name:         "nested file overrides type and extends labels",
+			file:         filepath.Join(root, "ops", "b.md"),
+			wantType:     "runbook",
+			wantLabels:   []string{"work", "oncall"},
+			wantExtract:  false,
+			wantSumUnset: true,
+		},
+		{
+			name:         "directory without config inherits parent",
+			file:         filepath.Join(root, "ops", "deep", "c.md"),
+			wantType:     "runbook",
+			wantLabels:   []string{"work", "oncall"},
+			wantExtract:  false,
+			wantSumUnset: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ResolveDirConfig(root, tt.file)
+			if err != nil {
+				t.Fatalf("ResolveDirConfig() error = %v", err)
+			}
+			if cfg.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", cfg.Type, tt.wantType)
+			}
+			if !slices.Equal(cfg.Labels, tt.wantLabels) {
+				t.Errorf("Labels = %v, want %v", cfg.Labels, tt.wantLabels)
+			}
+			if cfg.ExtractGraph == nil || *cfg.ExtractGraph != tt.wantExtract {
+				t.Errorf("ExtractGraph = %v, want %v", cfg.ExtractGraph, tt.wantExtract)
+			}
+			if tt.wantSumUnset && cfg.AutoSummarize != nil {
+				t.Errorf("AutoSummarize = %v, want unset", *cfg.AutoSummarize)
+			}
+		})
+	}
+}
+
+func TestResolveDirConfig_NoConfig(t *testing.T) {
+	root := t.TempDir()
+
+	cfg, err := ResolveDirConfig(root, filepath.Join(root, "a.md"))
+	if err != nil {
+		t.Fatalf("ResolveDirConfig() error = %v", err)
+	}
+	if cfg.Type != "" || len(cfg.Labels) != 0 || cfg.ExtractGraph != nil || cfg.AutoSummarize != nil {
+		t.Errorf("expected empty config, got %+v", cfg)
+	}
+}
+
+func TestResolveDirConfig_OutsideRoot(t *testing.T) {
+	root := t.TempDir()
+
+	if _, err := ResolveDirConfig(filepath.Join(root, "sub"), filepath.Join(root, "a.md")); err == nil {
+		t.Error("expected error for file outside root")
+	}
+}
+
+func TestResolveDirConfig_InvalidYAML(t *testing.T) {
+	root := t.TempDir()
+	writeDirConfig(t, root, "labels: [unclosed\n")
+
+	if _, err := ResolveDirConfig(root, filepath.Join(root, "a.md")); err == nil {
+		t.Error("expected error for invalid YAML")
+	}
+}
@@ -0,0 +1,399 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// Article is the main content of a web page.
+type Article struct {
+	Title    string
+	Markdown string // Starts with the title as h1
+}
+
+// chromeTags are elements that never hold article content.
+var chromeTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Form: true, atom.Button: true, atom.Select: true, atom.Textarea: true,
+	atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Canvas: true,
+	atom.Svg: true, atom.Dialog: true, atom.Img: true, atom.Picture: true,
+	atom.Video: true, atom.Audio: true,
+}
+
+// chromeClassRegex matches class, id and role values of navigation, ads and
+// other page chrome.
+var chromeClassRegex = regexp.MustCompile(`(?i)(^|[\s_-])(nav|navbar|navigation|menu|sidebar|header|footer|banner|breadcrumbs?|ads?|advert|advertisement|sponsored|promo|cookies?|consent|share|social|comments?|related|newsletter|subscribe|popup|modal)($|[\s_-])`)
+
+// ExtractArticle extracts the main content of an HTML page as Markdown,
+// leaving out navigation, ads and other page chrome. The content is the
+// page's largest <article>, else its <main>, else its <body>. contentType is
+// the response's Content-Type header, used to decode non-UTF-8 pages. Links
+// are resolved against base, which may be nil.
+func ExtractArticle(r io.Reader, contentType string, base *url.URL) (*Article, error) {
+	utf8, err := charset.NewReader(r, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("decode html: %w", err)
+	}
+	doc, err := html.Parse(utf8)
+	if err != nil {
+		return nil, fmt.Errorf("parse html: %w", err)
+	}
+
+	root := contentRoot(doc)
+	w := &markdownWriter{base: base}
+	w.children(root)
+	body := strings.TrimSpace(w.b.String())
+
+	title := ""
+	if h1 := findFirst(root, atom.H1); h1 != nil {
+		title = collapseSpace(textContent(h1))
+	}
+	if title == "" {
+		title = pageTitle(doc)
+	}
+
+	markdown := body
+	if title != "" && !strings.HasPrefix(body, "# ") {
+		markdown = "# " + title + "\n\n" + body
+	}
+	return &Article{Title: title, Markdown: markdown}, nil
+}
+
+// contentRoot returns the element holding the page's main content.
+func contentRoot(doc *html.Node) *html.Node {
+	var best *html.Node
+	bestLen := 0
+	for _, a := range findAll(doc, atom.Article) {
+		if n := len(strings.TrimSpace(textContent(a))); n > bestLen {
+			best, bestLen = a, n
+		}
+	}
+	if best != nil {
+		return best
+	}
+	if main := findFirst(doc, atom.Main); main != nil {
+		return main
+	}
+	if main := findFunc(doc, func(n *html.Node) bool { return attr(n, "role") == "main" }); main != nil {
+		return main
+	}
+	if body := findFirst(doc, atom.Body); body != nil {
+		return body
+	}
+	return doc
+}
+
+// pageTitle returns the og:title meta tag, else the <title> of a page.
+func pageTitle(doc *html.Node) string {
+	og := findFunc(doc, func(n *html.Node) bool {
+		return n.DataAtom == atom.Meta && attr(n, "property") == "og:title"
+	})
+	if og != nil {
+		if title := collapseSpace(attr(og, "content")); title != "" {
+			return title
+		}
+	}
+	if t := findFirst(doc, atom.Title); t != nil {
+		return collapseSpace(textContent(t))
+	}
+	return ""
+}
+
+// isChrome reports whether an element is navigation, ads or similar page
+// chrome. Elements holding an h1, article or main are kept: they are the
+// article's header or wrap the whole content.
+func isChrome(n *html.Node) bool {
+	if chromeTags[n.DataAtom] || chromeClassRegex.MatchString(attr(n, "class")+" "+attr(n, "id")+" "+attr(n, "role")) {
+		return findFunc(n, func(d *html.Node) bool {
+			return d.DataAtom == atom.H1 || d.DataAtom == atom.Article || d.DataAtom == atom.Main
+		}) == nil
+	}
+	return attr(n, "aria-hidden") == "true" || hasAttr(n, "hidden")
+}
+
+// markdownWriter renders HTML nodes as Markdown.
+type markdownWriter struct {
+	b    strings.Builder
+	base *url.URL
+}
+
+func (w *markdownWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+}
+
+func (w *markdownWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if isChrome(n) {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		if text := w.inline(n); text != "" {
+			level := int(n.Data[1] - '0')
+			w.block(strings.Repeat("#", level) + " " + text)
+		}
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Figure, atom.Figcaption, atom.Dl, atom.Dt, atom.Dd, atom.Details, atom.Summary:
+		w.blockBreak()
+		w.children(n)
+		w.blockBreak()
+	case atom.Br:
+		w.b.WriteString("\n")
+	case atom.Hr:
+		w.block("---")
+	case atom.Pre:
+		code := strings.Trim(textContent(n), "\n")
+		if code != "" {
+			w.block("```\n" + code + "\n```")
+		}
+	case atom.Code, atom.Kbd, atom.Samp:
+		if text := collapseSpace(textContent(n)); text != "" {
+			w.text("`" + text + "`")
+		}
+	case atom.Strong, atom.B:
+		w.wrapInline(n, "**", "**")
+	case atom.Em, atom.I:
+		w.wrapInline(n, "*", "*")
+	case atom.A:
+		if href := w.resolve(attr(n, "href")); href != "" {
+			w.wrapInline(n, "[", "]("+href+")")
+		} else {
+			w.children(n)
+		}
+	case atom.Ul, atom.Ol:
+		w.list(n)
+	case atom.Blockquote:
+		sub := &markdownWriter{base: w.base}
+		sub.children(n)
+		if quote := strings.TrimSpace(sub.b.String()); quote != "" {
+			w.block("> " + strings.ReplaceAll(quote, "\n", "\n> "))
+		}
+	case atom.Table:
+		w.table(n)
+	default:
+		w.children(n)
+	}
+}
+
+// text writes inline text with whitespace collapsed.
+func (w *markdownWriter) text(s string) {
+	s = collapseWhitespace(s)
+	if s == "" {
+		return
+	}
+	out := w.b.String()
+	if strings.HasPrefix(s, " ") && (out == "" || strings.HasSuffix(out, " ") || strings.HasSuffix(out, "\n")) {
+		s = s[1:]
+	}
+	w.b.WriteString(s)
+}
+
+// inline renders the children of n as a single line.
+func (w *markdownWriter) inline(n *html.Node) string {
+	sub := &markdownWriter{base: w.base}
+	sub.children(n)
+	return collapseSpace(sub.b.String())
+}
+
+// wrapInline writes the children of n between before and after, e.g. ** for
+// bold. Whitespace around the children stays outside the markers.
+func (w *markdownWriter) wrapInline(n *html.Node, before, after string) {
+	sub := &markdownWriter{base: w.base}
+	sub.children(n)
+	raw := collapseWhitespace(sub.b.String())
+	text := strings.TrimSpace(raw)
+	if text == "" {
+		w.text(raw)
+		return
+	}
+	if strings.HasPrefix(raw, " ") {
+		w.text(" ")
+	}
+	w.b.WriteString(before + text + after)
+	if strings.HasSuffix(raw, " ") {
+		w.text(" ")
+	}
+}
+
+// block writes s as a block of its own.
+func (w *markdownWriter) block(s string) {
+	w.blockBreak()
+	w.b.WriteString(s)
+	w.blockBreak()
+}
+
+// blockBreak ends the current block with a blank line.
+func (w *markdownWriter) blockBreak() {
+	out := w.b.String()
+	trimmed := strings.TrimRight(out, " ")
+	if trimmed == "" {
+		w.b.Reset()
+		return
+	}
+	if len(trimmed) != len(out) {
+		w.b.Reset()
+		w.b.WriteString(trimmed)
+	}
+	switch {
+	case strings.HasSuffix(trimmed, "\n\n"):
+	case strings.HasSuffix(trimmed, "\n"):
+		w.b.WriteString("\n")
+	default:
+		w.b.WriteString("\n\n")
+	}
+}
+
+// list writes the items of a ul or ol, indenting nested blocks.
+func (w *markdownWriter) list(n *html.Node) {
+	ordered := n.DataAtom == atom.Ol
+	start := 1
+	if s, err := strconv.Atoi(attr(n, "start")); err == nil && ordered {
+		start = s
+	}
+
+	var items []string
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li || isChrome(li) {
+			continue
+		}
+		sub := &markdownWriter{base: w.base}
+		sub.children(li)
+		item := strings.TrimSpace(sub.b.String())
+		if item == "" {
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(start+len(items)) + ". "
+		}
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.ReplaceAll(item, "\n", "\n"+indent))
+	}
+	if len(items) > 0 {
+		w.block(strings.Join(items, "\n"))
+	}
+}
+
+// table writes each row of a table as a line of cells separated by |.
+func (w *markdownWriter) table(n *html.Node) {
+	var rows []string
+	for _, tr := range findAll(n, atom.Tr) {
+		var cells []string
+		for c := tr.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && (c.DataAtom == atom.Td || c.DataAtom == atom.Th) {
+				cells = append(cells, w.inline(c))
+			}
+		}
+		if strings.TrimSpace(strings.Join(cells, "")) != "" {
+			rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+		}
+	}
+	if len(rows) > 0 {
+		w.block(strings.Join(rows, "\n"))
+	}
+}
+
+// resolve returns href as an absolute http(s) URL, or "" for anchors,
+// javascript: links and the like.
+func (w *markdownWriter) resolve(href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return ""
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if w.base != nil {
+		u = w.base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto" {
+		return ""
+	}
+	return u.String()
+}
+
+// findAll returns the elements of type a under n, in document order.
+func findAll(n *html.Node, a atom.Atom) []*html.Node {
+	var found []*html.Node
+	for d := range n.Descendants() {
+		if d.Type == html.ElementNode && d.DataAtom == a {
+			found = append(found, d)
+		}
+	}
+	return found
+}
+
+// findFirst returns the first element of type a under n, or nil.
+func findFirst(n *html.Node, a atom.Atom) *html.Node {
+	return findFunc(n, func(d *html.Node) bool { return d.DataAtom == a })
+}
+
+// findFunc returns the first element under n matching match, or nil.
+func findFunc(n *html.Node, match func(*html.Node) bool) *html.Node {
+	for d := range n.Descendants() {
+		if d.Type == html.ElementNode && match(d) {
+			return d
+		}
+	}
+	return nil
+}
+
+// textContent returns the text under n, like the DOM property.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	for d := range n.Descendants() {
+		if d.Type == html.TextNode {
+			b.WriteString(d.Data)
+		}
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+var whitespaceRegex = regexp.MustCompile(`\s+`)
+
+// collapseWhitespace replaces each run of whitespace with a single space.
+func collapseWhitespace(s string) string {
+	return whitespaceRegex.ReplaceAllString(s, " ")
+}
+
+// collapseSpace collapses whitespace and trims the result.
+func collapseSpace(s string) string {
+	return strings.TrimSpace(collapseWhitespace(s))
+}
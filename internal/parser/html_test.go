@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestExtractArticle(t *testing.T) {
+	page := `<!DOCTYPE html>
+<html>
+<head><title>Auth Service | Example Blog</title></head>
+<body>
+  <nav><a href="/">Home</a> <a href="/blog">Blog</a></nav>
+  <div class="ad-slot">Buy now!</div>
+  <article>
+    <header><h1>Auth  Service</h1><p class="byline">by Jane</p></header>
+    <p>The auth service issues <strong>tokens</strong> for the <a href="/gateway">API gateway</a>.</p>
+    <h2>Setup</h2>
+    <ul>
+      <li>Install it</li>
+      <li>Run <code>auth serve</code></li>
+    </ul>
+    <pre>auth serve
+  --port 8080</pre>
+    <div class="share-buttons"><a href="https://twitter.com">Share</a></div>
+    <script>track()</script>
+  </article>
+  <aside>Related posts</aside>
+  <footer>© Example</footer>
+</body>
+</html>`
+
+	base, err := url.Parse("https://example.com/blog/auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	article, err := ExtractArticle(strings.NewReader(page), "text/html; charset=utf-8", base)
+	if err != nil {
+		t.Fatalf("ExtractArticle() error = %v", err)
+	}
+
+	if article.Title != "Auth Service" {
+		t.Errorf("Title = %q, want %q", article.Title, "Auth Service")
+	}
+
+	want := "# Auth Service\n\n" +
+		"by Jane\n\n" +
+		"The auth service issues **tokens** for the [API gateway](https://example.com/gateway).\n\n" +
+		"## Setup\n\n" +
+		"- Install it\n" +
+		"- Run `auth serve`\n\n" +
+		"```\nauth serve\n  --port 8080\n```"
+	if article.Markdown != want {
+		t.Errorf("Markdown =\n%s\nwant\n%s", article.Markdown, want)
+	}
+}
+
+func TestExtractArticleTitleFallback(t *testing.T) {
+	page := `<html><head><title>Release Notes</title></head><body><main><p>Version 2 is out.</p></main></body></html>`
+
+	article, err := ExtractArticle(strings.NewReader(page), "text/html", nil)
+	if err != nil {
+		t.Fatalf("ExtractArticle() error = %v", err)
+	}
+	if want := "# Release Notes\n\nVersion 2 is out."; article.Markdown != want {
+		t.Errorf("Markdown = %q, want %q", article.Markdown, want)
+	}
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopwords are frequent function words per language (ISO 639-1 code).
+// Counting them is a cheap and reliable enough way to tell apart the
+// languages of prose-sized texts.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "that", "it", "for", "with", "was", "on", "are", "this", "by", "not", "have", "from", "but", "they", "which", "you", "be"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "zu", "den", "mit", "von", "sich", "des", "auf", "für", "dem", "im", "auch", "wird", "bei", "oder"},
+	"fr": {"le", "les", "et", "des", "est", "une", "du", "que", "pour", "dans", "qui", "pas", "sur", "au", "avec", "sont", "ce", "il", "elle", "ne", "nous", "vous"},
+	"es": {"el", "los", "las", "y", "que", "en", "es", "por", "con", "para", "una", "del", "se", "no", "al", "lo", "como", "pero", "más", "está", "su", "muy"},
+	"it": {"il", "di", "che", "è", "per", "non", "una", "della", "sono", "con", "gli", "nel", "alla", "anche", "come", "ma", "questo", "si", "lo", "dei", "da", "delle"},
+	"nl": {"het", "een", "en", "van", "is", "dat", "niet", "op", "te", "zijn", "met", "voor", "die", "er", "ook", "aan", "maar", "bij", "wordt", "naar", "wij", "deze"},
+	"pt": {"os", "que", "é", "do", "da", "em", "um", "uma", "para", "com", "não", "por", "se", "mais", "dos", "das", "ao", "como", "mas", "está", "foi", "muito"},
+}
+
+const (
+	// languageMinMatches is the number of stopwords needed before a language
+	// is reported; shorter texts are too ambiguous.
+	languageMinMatches = 5
+	// languageMargin is how much more often the best language's stopwords
+	// must occur than the runner-up's.
+	languageMargin = 1.5
+	// languageMaxWords bounds the words looked at in long documents.
+	languageMaxWords = 2000
+)
+
+// stopwordLanguages maps each stopword to the languages it belongs to.
+var stopwordLanguages = func() map[string][]string {
+	m := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, w := range words {
+			m[w] = append(m[w], lang)
+		}
+	}
+	return m
+}()
+
+// DetectLanguage returns the ISO 639-1 code of the language text is written
+// in (en, de, fr, es, it, nl or pt), or "" if it can't tell. Fenced code
+// blocks are ignored.
+func DetectLanguage(text string) string {
+	counts := make(map[string]int)
+	words := 0
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		for _, word := range strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+			return !unicode.IsLetter(r)
+		}) {
+			for _, lang := range stopwordLanguages[word] {
+				counts[lang]++
+			}
+			if words++; words >= languageMaxWords {
+				return bestLanguage(counts)
+			}
+		}
+	}
+	return bestLanguage(counts)
+}
+
+// bestLanguage returns the language with the most stopword matches if it is
+// clearly ahead of the others.
+func bestLanguage(counts map[string]int) string {
+	best, bestCount, second := "", 0, 0
+	for lang, n := range counts {
+		switch {
+		case n > bestCount || (n == bestCount && lang < best):
+			best, bestCount, second = lang, n, max(second, bestCount)
+		case n > second:
+			second = n
+		}
+	}
+	if bestCount < languageMinMatches || float64(bestCount) < languageMargin*float64(second) {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,55 @@
+package parser
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "english",
+			text: "The auth service issues tokens for the API gateway. It is deployed with the rest of the platform and they rotate the keys every week.",
+			want: "en",
+		},
+		{
+			name: "german",
+			text: "Der Auth-Service stellt die Tokens für das API-Gateway aus. Er wird mit der Plattform ausgerollt und die Schlüssel werden nicht automatisch rotiert, auch wenn das sinnvoll wäre.",
+			want: "de",
+		},
+		{
+			name: "french",
+			text: "Le service d'authentification émet les jetons pour la passerelle. Il est déployé avec les autres services et les clés ne sont pas renouvelées dans ce cas.",
+			want: "fr",
+		},
+		{
+			name: "spanish",
+			text: "El servicio de autenticación emite los tokens para la pasarela. Se despliega con el resto de la plataforma y las claves no se rotan, pero es muy importante.",
+			want: "es",
+		},
+		{
+			name: "code blocks ignored",
+			text: "Der Dienst wird mit dem folgenden Befehl gestartet, die Konfiguration ist nicht nötig und auch der Port wird gesetzt:\n```\n# start the service and wait for the health check of the gateway\nrun --port 8080 --with the config\n```\n",
+			want: "de",
+		},
+		{
+			name: "too short",
+			text: "Kubernetes deployment",
+			want: "",
+		},
+		{
+			name: "empty",
+			text: "",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLanguage(tt.text); got != tt.want {
+				t.Errorf("DetectLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raphaelgruber/memcp-go/internal/metrics"
+)
+
+// answerCacheMaxEntries bounds the number of cached answers.
+const answerCacheMaxEntries = 1000
+
+// AnswerCache caches Ask answers by question and search scope for a TTL.
+// Any entity or relation change invalidates all entries, since it may change
+// what an answer would say. A nil *AnswerCache caches nothing.
+type AnswerCache struct {
+	ttl     time.Duration
+	metrics *metrics.Collector // may be nil
+
+	mu        sync.Mutex
+	entries   map[string]cachedAnswer
+	lastWrite time.Time // last entity change; answers started before it are stale
+}
+
+type cachedAnswer struct {
+	answer   string
+	storedAt time.Time
+}
+
+// NewAnswerCache creates a cache keeping answers for ttl. Hits and misses
+// are recorded in mc, which may be nil.
+func NewAnswerCache(ttl time.Duration, mc *metrics.Collector) *AnswerCache {
+	return &AnswerCache{
+		ttl:     ttl,
+		metrics: mc,
+		entries: make(map[string]cachedAnswer),
+	}
+}
+
+// Watch invalidates the cache on every write reported to events. The
+// invalidation runs within the write (see EntityEvents.OnWrite), so an Ask
+// starting after a write returned never gets an answer cached before it.
+func (c *AnswerCache) Watch(events *EntityEvents) {
+	if c == nil {
+		return
+	}
+	events.OnWrite(c.Invalidate)
+}
+
+// Invalidate drops all cached answers and marks answers being generated as
+// stale.
+func (c *AnswerCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastWrite = time.Now()
+	if len(c.entries) > 0 {
+		slog.Debug("answer cache invalidated", "entries", len(c.entries))
+		c.entries = make(map[string]cachedAnswer)
+	}
+}
+
+// get returns the cached answer for key if it hasn't expired.
+func (c *AnswerCache) get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	if key == "" {
+		return "", false
+	}
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && time.Since(entry.storedAt) > c.ttl {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if c.metrics != nil {
+		c.metrics.RecordCacheLookup(ok)
+	}
+	return entry.answer, ok
+}
+
+// put stores answer for key unless an entity changed since started, when
+// generating the answer began.
+func (c *AnswerCache) put(key, answer string, started time.Time) {
+	if c == nil || key == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if started.Before(c.lastWrite) {
+		return
+	}
+	if len(c.entries) >= answerCacheMaxEntries {
+		c.evict()
+	}
+	c.entries[key] = cachedAnswer{answer: answer, storedAt: time.Now()}
+}
+
+// evict removes expired entries, or the oldest entry if none expired.
+// Caller must hold mu.
+func (c *AnswerCache) evict() {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if time.Since(entry.storedAt) > c.ttl {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.storedAt.Before(oldest) {
+			oldestKey, oldest = key, entry.storedAt
+		}
+	}
+	if len(c.entries) >= answerCacheMaxEntries {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// answerCacheKey hashes a question with its search scope (filters, limit and
+// model), everything that affects its answer. Returns "" if opts can't be
+// hashed, which callers treat as uncacheable.
+func answerCacheKey(opts SearchOptions) string {
+	opts.Query = strings.TrimSpace(opts.Query)
+	opts.ConversationID = "" // Answers are shared across conversations
+	scope, err := json.Marshal(opts)
+	if err != nil {
+		slog.Warn("failed to build answer cache key", "error", err)
+		return ""
+	}
+	hash := sha256.Sum256(scope)
+	return hex.EncodeToString(hash[:])
+}
+
+// replayAnswer sends a cached answer to onToken word by word, like a
+// streamed answer.
+func replayAnswer(answer string, onToken func(token string) error) error {
+	for _, token := range strings.SplitAfter(answer, " ") {
+		if token == "" {
+			continue
+		}
+		if err := onToken(token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
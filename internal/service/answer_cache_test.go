@@ -0,0 +1,138 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+func TestAnswerCacheGetPut(t *testing.T) {
+	c := NewAnswerCache(time.Minute, nil)
+
+	if _, ok := c.get("q"); ok {
+		t.Error("get() on empty cache hit")
+	}
+	c.put("q", "answer", time.Now())
+	if got, ok := c.get("q"); !ok || got != "answer" {
+		t.Errorf("get() = %q, %v, want %q, true", got, ok, "answer")
+	}
+
+	// Uncacheable keys are never stored
+	c.put("", "answer", time.Now())
+	if _, ok := c.get(""); ok {
+		t.Error("get(\"\") hit")
+	}
+
+	// A nil cache caches nothing
+	var nilCache *AnswerCache
+	nilCache.put("q", "answer", time.Now())
+	if _, ok := nilCache.get("q"); ok {
+		t.Error("nil cache hit")
+	}
+}
+
+func TestAnswerCacheTTL(t *testing.T) {
+	c := NewAnswerCache(time.Minute, nil)
+	c.put("fresh", "a", time.Now())
+	c.put("stale", "b", time.Now())
+	c.entries["stale"] = cachedAnswer{answer: "b", storedAt: time.Now().Add(-2 * time.Minute)}
+
+	if _, ok := c.get("fresh"); !ok {
+		t.Error("fresh entry missed")
+	}
+	if _, ok := c.get("stale"); ok {
+		t.Error("expired entry hit")
+	}
+	if _, ok := c.entries["stale"]; ok {
+		t.Error("expired entry not removed")
+	}
+}
+
+func TestAnswerCacheInvalidate(t *testing.T) {
+	c := NewAnswerCache(time.Minute, nil)
+	events := NewEntityEvents()
+	c.Watch(events)
+
+	tests := []struct {
+		name  string
+		write func()
+	}{
+		{"entity event", func() { events.Publish(EntityUpdated, &models.Entity{Name: "a"}) }},
+		{"relation write", events.Written},
+		{"direct", c.Invalidate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.put("q", "answer", time.Now())
+			tt.write()
+			// Invalidation is synchronous: no wait for a subscriber
+			if _, ok := c.get("q"); ok {
+				t.Error("answer survived a write")
+			}
+		})
+	}
+}
+
+func TestAnswerCacheSkipsAnswersStartedBeforeWrite(t *testing.T) {
+	c := NewAnswerCache(time.Minute, nil)
+
+	// An answer generated while an entity changed may be built on the old
+	// state, so it must not be cached
+	started := time.Now().Add(-time.Millisecond)
+	c.Invalidate()
+	c.put("q", "stale answer", started)
+	if _, ok := c.get("q"); ok {
+		t.Error("answer started before a write was cached")
+	}
+
+	c.put("q", "fresh answer", time.Now())
+	if got, ok := c.get("q"); !ok || got != "fresh answer" {
+		t.Errorf("get() = %q, %v, want fresh answer", got, ok)
+	}
+}
+
+func TestAnswerCacheEviction(t *testing.T) {
+	fill := func(c *AnswerCache) {
+		base := time.Now().Add(-time.Second)
+		for i := range answerCacheMaxEntries {
+			c.entries[fmt.Sprintf("k%d", i)] = cachedAnswer{answer: "a", storedAt: base.Add(time.Duration(i) * time.Millisecond)}
+		}
+	}
+
+	t.Run("oldest evicted when full", func(t *testing.T) {
+		c := NewAnswerCache(time.Minute, nil)
+		fill(c)
+
+		c.put("new", "a", time.Now())
+		if len(c.entries) != answerCacheMaxEntries {
+			t.Errorf("entries = %d, want %d", len(c.entries), answerCacheMaxEntries)
+		}
+		if _, ok := c.entries["k0"]; ok {
+			t.Error("oldest entry not evicted")
+		}
+		for _, key := range []string{"k1", "new"} {
+			if _, ok := c.entries[key]; !ok {
+				t.Errorf("entry %s evicted", key)
+			}
+		}
+	})
+
+	t.Run("expired evicted first", func(t *testing.T) {
+		c := NewAnswerCache(time.Minute, nil)
+		fill(c)
+		for _, key := range []string{"k5", "k6"} {
+			c.entries[key] = cachedAnswer{answer: "a", storedAt: time.Now().Add(-time.Hour)}
+		}
+
+		c.put("new", "a", time.Now())
+		if len(c.entries) != answerCacheMaxEntries-1 {
+			t.Errorf("entries = %d, want %d", len(c.entries), answerCacheMaxEntries-1)
+		}
+		if _, ok := c.entries["k0"]; !ok {
+			t.Error("oldest live entry evicted although expired entries were")
+		}
+	})
+}
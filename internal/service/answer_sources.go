@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// UsefulSource is an entity ranked by how often it informed answers.
+type UsefulSource struct {
+	Entity    models.Entity
+	Retrieved int     // Asks that retrieved the entity
+	Included  int     // Asks whose context included its text
+	AvgRank   float64 // Average 1-based position in the answer context
+}
+
+// recordAnswerSources logs which entities were retrieved for an ask, in
+// context order. Failures are logged, never returned: analytics must not
+// break asking.
+func (s *SearchService) recordAnswerSources(ctx context.Context, results []models.EntitySearchResult) {
+	sources := make([]models.AnswerSourceInput, 0, len(results))
+	for i, result := range results {
+		id, err := models.RecordIDString(result.ID)
+		if err != nil {
+			slog.Debug("skipping answer source with invalid ID", "error", err)
+			continue
+		}
+		sources = append(sources, models.AnswerSourceInput{
+			EntityID: id,
+			Rank:     i + 1,
+			Included: contributesContext(result, s.contextOpts),
+		})
+	}
+
+	if err := s.db.RecordAnswerSources(ctx, uuid.New().String(), sources); err != nil {
+		slog.Warn("failed to record answer sources", "error", err)
+	}
+}
+
+// contributesContext reports whether buildSearchContext adds any text of
+// result beyond its name: a summary, matched chunks or content.
+func contributesContext(result models.EntitySearchResult, opts ContextOptions) bool {
+	if result.Summary != nil && *result.Summary != "" {
+		return true
+	}
+	if opts.Mode != ContextModeContent && len(result.MatchedChunks) > 0 {
+		return true
+	}
+	return result.Content != nil && *result.Content != ""
+}
+
+// MostUsefulSources ranks entities by how often they were included in the
+// context of asks since the given datetime (RFC 3339). Deleted entities are
+// left out.
+func (s *SearchService) MostUsefulSources(ctx context.Context, since string, limit int) ([]UsefulSource, error) {
+	stats, err := s.db.GetAnswerSourceStats(ctx, since, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(stats))
+	for i, st := range stats {
+		ids[i] = st.EntityID
+	}
+	entities, err := s.db.GetEntitiesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	useful := make([]UsefulSource, 0, len(stats))
+	for _, st := range stats {
+		if limit > 0 && len(useful) >= limit {
+			break
+		}
+		entity, ok := entities[st.EntityID]
+		if !ok {
+			continue
+		}
+		useful = append(useful, UsefulSource{
+			Entity:    *entity,
+			Retrieved: st.Retrieved,
+			Included:  st.Included,
+			AvgRank:   st.AvgRank,
+		})
+	}
+	return useful, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/raphaelgruber/memcp-go/internal/db"
+	"github.com/raphaelgruber/memcp-go/internal/llm"
+)
+
+// ErrBudgetExceeded is returned when an LLM call would take a conversation
+// past its token budget.
+var ErrBudgetExceeded = errors.New("token budget exceeded")
+
+// TokenBudget caps the tokens LLM calls may use per conversation, summed
+// from the token usage recorded for it.
+type TokenBudget struct {
+	db                 *db.Client
+	maxPerConversation int
+}
+
+// NewTokenBudget creates a budget of maxPerConversation tokens per
+// conversation. Returns nil (no limit) if maxPerConversation isn't positive.
+func NewTokenBudget(db *db.Client, maxPerConversation int) *TokenBudget {
+	if maxPerConversation <= 0 {
+		return nil
+	}
+	return &TokenBudget{db: db, maxPerConversation: maxPerConversation}
+}
+
+// Check returns an error wrapping ErrBudgetExceeded if a call estimated at
+// nextTokens would take the conversation past its budget. Calls without a
+// conversation aren't limited.
+func (b *TokenBudget) Check(ctx context.Context, conversationID string, nextTokens int) error {
+	if b == nil || conversationID == "" {
+		return nil
+	}
+
+	used, err := b.db.GetConversationTokenUsage(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("check token budget: %w", err)
+	}
+	if used+nextTokens > b.maxPerConversation {
+		return fmt.Errorf("%w: conversation %s has used %d of %d tokens, the next answer needs about %d more",
+			ErrBudgetExceeded, conversationID, used, b.maxPerConversation, nextTokens)
+	}
+	return nil
+}
+
+// budgeted checks the token budget of a conversation for an LLM call with
+// prompt and returns ctx attributing the call's token usage to the
+// conversation. Without a conversation, ctx is returned as is.
+func (s *SearchService) budgeted(ctx context.Context, conversationID, prompt string) (context.Context, error) {
+	if conversationID == "" {
+		return ctx, nil
+	}
+	if err := s.budget.Check(ctx, conversationID, llm.EstimateTokens(prompt)); err != nil {
+		return ctx, err
+	}
+	return llm.WithConversation(ctx, conversationID), nil
+}
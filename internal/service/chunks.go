@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/raphaelgruber/memcp-go/internal/parser"
+)
+
+// ChunkOptions overrides chunking parameters for a preview or an ingest. Zero
+// values keep the defaults.
+type ChunkOptions struct {
+	Strategy   parser.ChunkStrategy // heading, fixed or sentence (empty = heading)
+	Threshold  int                  // Only chunk content longer than this
+	TargetSize int                  // Ideal chunk size
+	MinSize    int                  // Smaller chunks merge with neighbors
+	MaxSize    int                  // Larger chunks split at sentences
+	Overlap    *int                 // Characters repeated between chunks (nil = default)
+}
+
+// ChunkPreview is a chunk a document would produce.
+type ChunkPreview struct {
+	Position    int
+	Content     string
+	HeadingPath string
+}
+
+// apply returns cfg with the options' overrides.
+func (o ChunkOptions) apply(cfg parser.ChunkConfig) (parser.ChunkConfig, error) {
+	if o.Threshold > 0 {
+		cfg.Threshold = o.Threshold
+	}
+	if o.TargetSize > 0 {
+		cfg.TargetSize = o.TargetSize
+	}
+	if o.MinSize > 0 {
+		cfg.MinSize = o.MinSize
+	}
+	if o.MaxSize > 0 {
+		cfg.MaxSize = o.MaxSize
+	}
+	if o.Overlap != nil {
+		cfg.Overlap = *o.Overlap
+	}
+	switch o.Strategy {
+	case "":
+	case parser.ChunkByHeading, parser.ChunkFixed, parser.ChunkBySentence:
+		cfg.Strategy = o.Strategy
+	default:
+		return cfg, fmt.Errorf("unknown chunk strategy %q (want heading, fixed or sentence)", o.Strategy)
+	}
+
+	if o.Threshold < 0 || o.TargetSize < 0 || o.MinSize < 0 || o.MaxSize < 0 || cfg.Overlap < 0 {
+		return cfg, fmt.Errorf("chunk options must not be negative")
+	}
+	if cfg.MinSize > cfg.TargetSize || cfg.TargetSize > cfg.MaxSize {
+		return cfg, fmt.Errorf("chunk sizes must satisfy minSize (%d) <= targetSize (%d) <= maxSize (%d)", cfg.MinSize, cfg.TargetSize, cfg.MaxSize)
+	}
+	if cfg.Overlap >= cfg.MinSize {
+		return cfg, fmt.Errorf("overlap (%d) must be smaller than minSize (%d)", cfg.Overlap, cfg.MinSize)
+	}
+	return cfg, nil
+}
+
+// PreviewChunks returns the chunks Markdown content would be split into on
+// ingest, without writing or embedding anything. Returns no chunks if the
+// content would be stored unchunked (below the threshold or a single chunk).
+func (s *EntityService) PreviewChunks(ctx context.Context, content string, opts ChunkOptions) ([]ChunkPreview, error) {
+	cfg, err := s.chunkConfig(content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := parser.ParseMarkdown(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse markdown: %w", err)
+	}
+
+	previews := []ChunkPreview{}
+	if !parser.ShouldChunk(doc.Content, cfg) {
+		return previews, nil
+	}
+	chunks := parser.ChunkMarkdown(doc, cfg)
+	if len(chunks) < 2 {
+		return previews, nil
+	}
+
+	for _, chunk := range chunks {
+		previews = append(previews, ChunkPreview{
+			Position:    chunk.Position,
+			Content:     chunk.Content,
+			HeadingPath: chunk.HeadingPath,
+		})
+	}
+	return previews, nil
+}
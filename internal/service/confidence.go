@@ -0,0 +1,23 @@
+package service
+
+import "github.com/raphaelgruber/memcp-go/internal/models"
+
+// ConfidenceDefaults maps an entity source (e.g. "manual", "scrape",
+// "ai_generated") to the confidence given to entities created without an
+// explicit one. Sources that aren't listed keep the database default.
+type ConfidenceDefaults map[string]float64
+
+// apply sets input.Confidence from the defaults for its source, unless it is
+// already set. Entities without a source count as manual.
+func (d ConfidenceDefaults) apply(input *models.EntityInput) {
+	if input.Confidence != nil {
+		return
+	}
+	source := models.SourceManual
+	if input.Source != nil {
+		source = *input.Source
+	}
+	if confidence, ok := d[string(source)]; ok {
+		input.Confidence = &confidence
+	}
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/raphaelgruber/memcp-go/internal/db"
+	"github.com/raphaelgruber/memcp-go/internal/llm"
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+const (
+	contradictionPeers     = 5    // Most similar entities an entity is compared with
+	contradictionTextLimit = 2000 // Content characters per entity passed to the LLM
+)
+
+// ContradictionService detects entities making contradictory factual claims
+// and records them as contradicts relations.
+type ContradictionService struct {
+	db    *db.Client
+	model *llm.Model
+}
+
+// NewContradictionService creates a new contradiction service. Without a
+// model, detection fails with an error.
+func NewContradictionService(db *db.Client, model *llm.Model) *ContradictionService {
+	return &ContradictionService{db: db, model: model}
+}
+
+// errNoLLM is returned by contradiction detection without a model.
+var errNoLLM = errors.New("contradiction detection requires an LLM (set KNOWHOW_LLM_PROVIDER)")
+
+// DetectForEntity asks the LLM whether an entity contradicts any of its most
+// similar entities (by embedding, see db.FindSimilarEntities) and records
+// each contradiction found. Chunked entities, which have no embedding of
+// their own, are compared by their first chunk. Pairs with a recorded
+// contradiction, resolved or not, are left alone. Returns the number of
+// contradictions created.
+func (s *ContradictionService) DetectForEntity(ctx context.Context, entityID string) (int, error) {
+	if s.model == nil {
+		return 0, errNoLLM
+	}
+
+	entity, err := s.db.GetEntity(ctx, entityID)
+	if err != nil {
+		return 0, fmt.Errorf("get entity: %w", err)
+	}
+	if entity == nil {
+		return 0, fmt.Errorf("entity not found: %s", entityID)
+	}
+
+	embedding := entity.Embedding
+	if len(embedding) == 0 {
+		chunks, err := s.db.GetChunks(ctx, entityID)
+		if err != nil {
+			return 0, fmt.Errorf("get chunks: %w", err)
+		}
+		if len(chunks) > 0 {
+			embedding = chunks[0].Embedding
+		}
+	}
+
+	peers, err := s.db.FindSimilarEntities(ctx, embedding, entityID, contradictionPeers)
+	if err != nil {
+		return 0, err
+	}
+	if len(peers) == 0 {
+		return 0, nil
+	}
+
+	if err := s.loadChunkedContent(ctx, entity); err != nil {
+		return 0, err
+	}
+	peerTexts := make([]string, len(peers))
+	for i := range peers {
+		if err := s.loadChunkedContent(ctx, &peers[i]); err != nil {
+			return 0, err
+		}
+		peerTexts[i] = contradictionText(&peers[i])
+	}
+	answer, err := s.model.DetectContradictions(ctx, contradictionText(entity), peerTexts)
+	if err != nil {
+		return 0, fmt.Errorf("LLM contradiction detection: %w", err)
+	}
+
+	created := 0
+	for _, found := range parseContradictions(answer, len(peers)) {
+		peer := peers[found.peer-1]
+		peerID, err := models.RecordIDString(peer.ID)
+		if err != nil {
+			slog.Debug("failed to get peer ID for contradiction", "peer", peer.Name, "error", err)
+			continue
+		}
+		ok, err := s.db.CreateContradiction(ctx, models.ContradictionInput{
+			FromID:      entityID,
+			ToID:        peerID,
+			Explanation: found.explanation,
+			Confidence:  &found.confidence,
+		})
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			slog.Info("contradiction detected", "entity", entity.Name, "peer", peer.Name, "confidence", found.confidence, "explanation", found.explanation)
+			created++
+		}
+	}
+	return created, nil
+}
+
+// loadChunkedContent sets the content of a chunked entity, which is only
+// stored in its chunks, to the text of its chunks. Other entities are left
+// alone.
+func (s *ContradictionService) loadChunkedContent(ctx context.Context, e *models.Entity) error {
+	if e.Content != nil {
+		return nil
+	}
+	id, err := models.RecordIDString(e.ID)
+	if err != nil {
+		return fmt.Errorf("get entity ID: %w", err)
+	}
+	chunks, err := s.db.GetChunks(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get chunks: %w", err)
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+	texts := make([]string, len(chunks))
+	for i, ch := range chunks {
+		texts[i] = ch.Content
+	}
+	content := strings.Join(texts, "\n\n")
+	e.Content = &content
+	return nil
+}
+
+// DetectAllAsync starts a background job running DetectForEntity for every
+// verified entity, one LLM call each. The job's FilesProcessed result counts
+// the entities scanned, RelationsCreated the contradictions recorded; an
+// entity that fails is listed in Errors and the scan continues past it.
+func (s *ContradictionService) DetectAllAsync(ctx context.Context, jobManager *JobManager) (*Job, error) {
+	if s.model == nil {
+		return nil, errNoLLM
+	}
+
+	ids, err := s.db.ListVerifiedEntityIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no verified entities found")
+	}
+
+	job, err := jobManager.CreateJob(ctx, "contradictions", "", "", ids, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create job: %w", err)
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("job goroutine panicked", "job_id", job.ID, "panic", r)
+				jobManager.Fail(context.Background(), job, fmt.Errorf("internal panic: %v", r))
+			}
+		}()
+
+		bgCtx := context.Background()
+		jobCtx, done := jobManager.start(job)
+		defer done()
+		jobManager.SetRunning(bgCtx, job)
+
+		result, err := s.detectAll(jobCtx, jobManager, job, ids)
+		if err != nil {
+			jobManager.Fail(bgCtx, job, err)
+			return
+		}
+		jobManager.Complete(bgCtx, job, result)
+	}()
+
+	return job, nil
+}
+
+// detectAll runs DetectForEntity for each entity of ids using the job
+// manager's worker pool. A fatal API error stops the remaining entities.
+func (s *ContradictionService) detectAll(ctx context.Context, jobManager *JobManager, job *Job, ids []string) (*IngestResult, error) {
+	slog.Info("starting contradiction scan", "entities", len(ids), "concurrency", jobManager.Concurrency())
+
+	var (
+		scanned  atomic.Int32
+		created  atomic.Int32
+		errorsMu sync.Mutex
+		errs     []string
+	)
+
+	workChan := make(chan string, len(ids))
+	var wg sync.WaitGroup
+	var fatalOnce sync.Once
+	fatalCh := make(chan struct{}) // closed on fatal API error to abort all workers
+
+	for i := 0; i < jobManager.Concurrency(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range workChan {
+				select {
+				case <-fatalCh:
+					return
+				default:
+				}
+				if ctx.Err() != nil {
+					return
+				}
+
+				n, err := s.DetectForEntity(ctx, id)
+				created.Add(int32(n))
+				if err != nil {
+					if errors.Is(err, llm.ErrFatalAPI) {
+						fatalOnce.Do(func() { close(fatalCh) })
+					}
+					slog.Warn("contradiction detection failed", "entity", id, "error", err)
+					errorsMu.Lock()
+					errs = append(errs, fmt.Sprintf("%s: %v", id, err))
+					errorsMu.Unlock()
+				}
+				done := scanned.Add(1)
+				jobManager.UpdateProgress(ctx, job, int(done), len(ids))
+			}
+		}()
+	}
+
+	for _, id := range ids {
+		workChan <- id
+	}
+	close(workChan)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	slog.Info("contradiction scan finished", "entities", scanned.Load(), "created", created.Load(), "errors", len(errs))
+	return &IngestResult{
+		FilesProcessed:   int(scanned.Load()),
+		RelationsCreated: int(created.Load()),
+		Errors:           errs,
+	}, nil
+}
+
+// contradictionText is the name, summary and content of an entity as shown
+// to the LLM.
+func contradictionText(e *models.Entity) string {
+	text := e.Name
+	if e.Summary != nil && *e.Summary != "" {
+		text += "\n" + *e.Summary
+	}
+	if e.Content != nil && *e.Content != "" {
+		text += "\n" + truncateContent(*e.Content, contradictionTextLimit)
+	}
+	return text
+}
+
+// detectedContradiction is a contradiction reported by the LLM.
+type detectedContradiction struct {
+	peer        int // 1-based peer number
+	confidence  float64
+	explanation string
+}
+
+// parseContradictions parses CONTRADICTION|peer|confidence|explanation lines
+// of an LLM answer, skipping malformed lines and unknown peer numbers.
+func parseContradictions(answer string, peers int) []detectedContradiction {
+	var found []detectedContradiction
+	for _, line := range strings.Split(answer, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "|", 4)
+		if len(parts) < 4 || parts[0] != "CONTRADICTION" {
+			continue
+		}
+		peer, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || peer < 1 || peer > peers {
+			continue
+		}
+		confidence, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		if err != nil {
+			confidence = 0.5
+		}
+		explanation := strings.TrimSpace(parts[3])
+		if explanation == "" {
+			continue
+		}
+		found = append(found, detectedContradiction{
+			peer:        peer,
+			confidence:  min(max(confidence, 0), 1),
+			explanation: explanation,
+		})
+	}
+	return found
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseContradictions(t *testing.T) {
+	answer := `CONTRADICTION|2|0.8|Peer says the service is owned by team A, the entry says team B
+CONTRADICTION|1|high|Different launch dates
+CONTRADICTION|4|0.9|Unknown peer
+CONTRADICTION|1|0.9|
+RELATION|a|b|c|d
+NONE`
+
+	want := []detectedContradiction{
+		{peer: 2, confidence: 0.8, explanation: "Peer says the service is owned by team A, the entry says team B"},
+		{peer: 1, confidence: 0.5, explanation: "Different launch dates"},
+	}
+	if got := parseContradictions(answer, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseContradictions() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/raphaelgruber/memcp-go/internal/db"
+	"github.com/raphaelgruber/memcp-go/internal/llm"
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// ConversationService stores chat messages and searches past conversations.
+type ConversationService struct {
+	db       *db.Client
+	embedder *llm.Embedder
+}
+
+// NewConversationService creates a new conversation service. Without an
+// embedder, messages are stored without embeddings and searched by text.
+func NewConversationService(db *db.Client, embedder *llm.Embedder) *ConversationService {
+	return &ConversationService{db: db, embedder: embedder}
+}
+
+// AddMessage stores a message in a conversation with the embedding of its
+// content. Messages with empty content aren't embedded and so never show up
+// in SearchMessages. If embedding fails, the message is stored without one
+// rather than lost.
+func (s *ConversationService) AddMessage(ctx context.Context, conversationID, role, content string) (*models.Message, error) {
+	var embedding []float32
+	if s.embedder != nil && strings.TrimSpace(content) != "" {
+		var err error
+		embedding, err = s.embedder.Embed(ctx, content)
+		if err != nil {
+			slog.Warn("failed to embed message, storing it unsearchable", "conversation", conversationID, "role", role, "error", err)
+			embedding = nil
+		}
+	}
+	return s.db.CreateMessage(ctx, conversationID, role, content, embedding)
+}
+
+// SearchMessages returns the messages of past conversations most similar to
+// query, with their conversation title.
+func (s *ConversationService) SearchMessages(ctx context.Context, query string, limit int) ([]models.MessageSearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query required")
+	}
+
+	var embedding []float32
+	if s.embedder != nil {
+		var err error
+		embedding, err = s.embedder.Embed(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("embed query: %w", err)
+		}
+	}
+	return s.db.SearchMessages(ctx, query, embedding, limit)
+}
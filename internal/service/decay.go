@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/raphaelgruber/memcp-go/internal/db"
+)
+
+// DecayRunner periodically applies confidence decay to unverified
+// AI-generated entities. Decay weights need no runs: they are computed from
+// the time of last access when searching.
+type DecayRunner struct {
+	db       *db.Client
+	events   *EntityEvents
+	cfg      db.DecayConfig
+	interval time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDecayRunner creates a runner that applies decay every interval. Each
+// run is reported to events as a write, as it changes confidences.
+func NewDecayRunner(dbClient *db.Client, events *EntityEvents, cfg db.DecayConfig, interval time.Duration) *DecayRunner {
+	return &DecayRunner{
+		db:       dbClient,
+		events:   events,
+		cfg:      cfg,
+		interval: interval,
+	}
+}
+
+// Config returns the decay configuration in effect.
+func (r *DecayRunner) Config() db.DecayConfig {
+	return r.cfg
+}
+
+// Interval returns the time between runs (0 if periodic decay is disabled).
+func (r *DecayRunner) Interval() time.Duration {
+	if r.interval <= 0 {
+		return 0
+	}
+	return r.interval
+}
+
+// Start begins periodic decay in the background.
+// Does nothing if the interval is not positive.
+func (r *DecayRunner) Start() {
+	if r.interval <= 0 {
+		slog.Info("periodic decay disabled")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.Run(ctx); err != nil {
+					slog.Warn("failed to apply decay", "error", err)
+				}
+			}
+		}
+	}()
+
+	slog.Info("periodic decay enabled", "interval", r.interval, "curve", r.cfg.Curve, "half_life_days", r.cfg.HalfLifeDays, "type_half_lives", r.cfg.TypeHalfLifeDays, "ai_confidence_half_life_days", r.cfg.AIConfidenceHalfLifeDays)
+}
+
+// Run applies decay once.
+func (r *DecayRunner) Run(ctx context.Context) error {
+	if err := r.db.ApplyDecay(ctx, r.cfg); err != nil {
+		return err
+	}
+	r.events.Written()
+	return nil
+}
+
+// Stop halts periodic decay.
+func (r *DecayRunner) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+	r.cancel = nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/raphaelgruber/memcp-go/internal/db"
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// DefaultDedupeThreshold is the embedding similarity above which two
+// entities are considered duplicates unless told otherwise.
+const DefaultDedupeThreshold = 0.95
+
+// dedupePeers is how many nearest entities each entity is compared with when
+// looking for duplicates.
+const dedupePeers = 5
+
+// Reasons a duplicate pair is not merged automatically.
+const (
+	dedupeSkipTypes    = "different types"
+	dedupeSkipVerified = "both verified"
+	dedupeSkipMerged   = "already merged"
+)
+
+// DuplicatePair is a pair of near-duplicate entities found by Dedupe.
+type DuplicatePair struct {
+	Keep       models.Entity // Survivor of a merge: higher confidence, then more accessed
+	Merge      models.Entity
+	Similarity float64
+	Merged     bool
+	SkipReason string // Why the pair can't be merged automatically; empty if it can
+	Error      string // Merge failure
+}
+
+// DedupeReport is the result of Dedupe for one page of entities.
+type DedupeReport struct {
+	Pairs      []DuplicatePair
+	Merged     int
+	Skipped    int    // Pairs that can't be merged automatically or failed to merge
+	NextCursor string // Empty when there are no more entities
+}
+
+// Dedupe finds pairs of entities whose embeddings have a cosine similarity
+// of at least threshold, comparing one page of limit entities (see
+// db.FindSimilarPairs) with their nearest peers. cursor is the NextCursor of
+// the previous report, or "" for the first page. With autoMerge, each pair
+// of entities of the same type is merged into the entity with the higher
+// confidence, then access count; pairs where both are verified need human
+// judgment and are skipped. Without autoMerge nothing changes and the pairs
+// are only reported.
+func (s *EntityService) Dedupe(ctx context.Context, threshold float64, autoMerge bool, limit int, cursor string) (*DedupeReport, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("threshold must be in (0, 1], got %g", threshold)
+	}
+
+	found, err := s.db.FindSimilarPairs(ctx, threshold, dedupePeers, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DedupeReport{Pairs: make([]DuplicatePair, 0, len(found.Pairs)), NextCursor: found.NextCursor}
+	mergedAway := make(map[string]bool)
+	for _, p := range found.Pairs {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		pair := duplicatePair(p)
+		keepID, mergeID, err := pairIDs(&pair)
+		if err != nil {
+			return report, err
+		}
+		if pair.SkipReason == "" && (mergedAway[keepID] || mergedAway[mergeID]) {
+			pair.SkipReason = dedupeSkipMerged
+		}
+
+		if autoMerge && pair.SkipReason == "" {
+			if _, err := s.Merge(ctx, keepID, mergeID); err != nil {
+				slog.Warn("auto-merge failed", "keep", keepID, "merge", mergeID, "error", err)
+				pair.Error = err.Error()
+			} else {
+				pair.Merged = true
+				mergedAway[mergeID] = true
+			}
+		}
+
+		if pair.Merged {
+			report.Merged++
+		} else if pair.SkipReason != "" || pair.Error != "" {
+			report.Skipped++
+		}
+		report.Pairs = append(report.Pairs, pair)
+	}
+
+	slog.Info("dedupe finished", "threshold", threshold, "pairs", len(report.Pairs), "merged", report.Merged, "skipped", report.Skipped)
+	return report, nil
+}
+
+// duplicatePair orders a similar pair into survivor and duplicate and
+// records why it can't be merged automatically, if so.
+func duplicatePair(p db.SimilarPair) DuplicatePair {
+	keep, merge := p.A, p.B
+	if merge.Confidence > keep.Confidence ||
+		(merge.Confidence == keep.Confidence && merge.AccessCount > keep.AccessCount) {
+		keep, merge = merge, keep
+	}
+
+	pair := DuplicatePair{Keep: keep, Merge: merge, Similarity: p.Similarity}
+	switch {
+	case keep.Type != merge.Type:
+		pair.SkipReason = dedupeSkipTypes
+	case keep.Verified && merge.Verified:
+		pair.SkipReason = dedupeSkipVerified
+	}
+	return pair
+}
+
+// pairIDs returns the IDs of the survivor and the duplicate of a pair.
+func pairIDs(p *DuplicatePair) (keepID, mergeID string, err error) {
+	keepID, err = models.RecordIDString(p.Keep.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("dedupe: %w", err)
+	}
+	mergeID, err = models.RecordIDString(p.Merge.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("dedupe: %w", err)
+	}
+	return keepID, mergeID, nil
+}
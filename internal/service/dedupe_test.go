@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/raphaelgruber/memcp-go/internal/db"
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+func TestDuplicatePair(t *testing.T) {
+	entity := func(name, typ string, verified bool, confidence float64, accessCount int) models.Entity {
+		return models.Entity{Name: name, Type: typ, Verified: verified, Confidence: confidence, AccessCount: accessCount}
+	}
+
+	tests := []struct {
+		name     string
+		a, b     models.Entity
+		wantKeep string
+		wantSkip string
+	}{
+		{"higher confidence kept", entity("a", "service", false, 0.6, 9), entity("b", "service", false, 0.9, 0), "b", ""},
+		{"more accessed kept on tie", entity("a", "service", false, 0.8, 3), entity("b", "service", false, 0.8, 1), "a", ""},
+		{"different types", entity("a", "service", false, 0.8, 0), entity("b", "concept", false, 0.8, 0), "a", dedupeSkipTypes},
+		{"both verified", entity("a", "service", true, 0.8, 0), entity("b", "service", true, 0.9, 0), "b", dedupeSkipVerified},
+		{"one verified", entity("a", "service", true, 0.8, 0), entity("b", "service", false, 0.9, 0), "b", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair := duplicatePair(db.SimilarPair{A: tt.a, B: tt.b, Similarity: 0.97})
+			if pair.Keep.Name != tt.wantKeep {
+				t.Errorf("Keep = %s, want %s", pair.Keep.Name, tt.wantKeep)
+			}
+			if pair.SkipReason != tt.wantSkip {
+				t.Errorf("SkipReason = %q, want %q", pair.SkipReason, tt.wantSkip)
+			}
+		})
+	}
+}
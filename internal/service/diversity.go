@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"math"
+)
+
+// diversityCandidateFactor is how many more candidates than requested are
+// fetched when diversifying, so there is something to choose from.
+const diversityCandidateFactor = 3
+
+// validateDiversity checks that a Diversity option is within 0-1.
+func validateDiversity(diversity float64) error {
+	if diversity < 0 || diversity > 1 {
+		return fmt.Errorf("diversity must be between 0 and 1, got %g", diversity)
+	}
+	return nil
+}
+
+// mmrSelect picks up to n of the ranked candidates using Maximal Marginal
+// Relevance and returns their indices in selection order. Relevance is the
+// candidate's normalized rank; diversity (0-1) weighs it against the highest
+// cosine similarity to an already selected candidate. Candidates without an
+// embedding count as dissimilar to everything.
+func mmrSelect(embeddings [][]float32, n int, diversity float64) []int {
+	if n > len(embeddings) {
+		n = len(embeddings)
+	}
+
+	selected := make([]int, 0, n)
+	used := make([]bool, len(embeddings))
+	maxSim := make([]float64, len(embeddings)) // highest similarity to the selection so far
+
+	for len(selected) < n {
+		best, bestScore := -1, math.Inf(-1)
+		for i := range embeddings {
+			if used[i] {
+				continue
+			}
+			relevance := 1 - float64(i)/float64(len(embeddings))
+			score := (1-diversity)*relevance - diversity*maxSim[i]
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		used[best] = true
+		selected = append(selected, best)
+		for i := range embeddings {
+			if !used[i] {
+				maxSim[i] = math.Max(maxSim[i], cosineSimilarity(embeddings[i], embeddings[best]))
+			}
+		}
+	}
+	return selected
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 if either
+// is empty, zero or their dimensions differ.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// diversify reorders and trims over-fetched search results with mmrSelect
+// when opts.Diversity is set. embedding extracts a result's embedding.
+func diversify[T any](results []T, opts SearchOptions, embedding func(T) []float32) []T {
+	if opts.Diversity <= 0 {
+		return results
+	}
+
+	embeddings := make([][]float32, len(results))
+	for i, r := range results {
+		embeddings[i] = embedding(r)
+	}
+
+	picked := mmrSelect(embeddings, opts.limit(), opts.Diversity)
+	diversified := make([]T, len(picked))
+	for i, idx := range picked {
+		diversified[i] = results[idx]
+	}
+	return diversified
+}
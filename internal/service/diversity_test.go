@@ -0,0 +1,81 @@
+package service
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMMRSelect(t *testing.T) {
+	var (
+		a     = []float32{1, 0}
+		aDup  = []float32{0.99, 0.01} // near-duplicate of a
+		aDup2 = []float32{0.98, 0.02}
+		b     = []float32{0, 1}
+		c     = []float32{0.6, 0.8}
+	)
+
+	tests := []struct {
+		name       string
+		embeddings [][]float32
+		n          int
+		diversity  float64
+		want       []int
+	}{
+		{"no diversity keeps rank order", [][]float32{a, aDup, aDup2, b}, 4, 0, []int{0, 1, 2, 3}},
+		{"no diversity trims to n", [][]float32{a, aDup, b}, 2, 0, []int{0, 1}},
+		{"near-duplicate passed over", [][]float32{a, aDup, b}, 2, 0.5, []int{0, 2}},
+		{"near-duplicates spread out", [][]float32{a, aDup, aDup2, b, c}, 2, 0.5, []int{0, 3}},
+		{"high diversity prefers the unlike", [][]float32{a, aDup, aDup2, b, c}, 3, 0.9, []int{0, 3, 4}},
+		{"duplicates come last", [][]float32{a, aDup, b}, 3, 0.5, []int{0, 2, 1}},
+		{"missing embedding counts as dissimilar", [][]float32{a, aDup, nil}, 2, 0.5, []int{0, 2}},
+		{"n beyond candidates", [][]float32{a, b}, 5, 0.5, []int{0, 1}},
+		{"no candidates", nil, 3, 0.5, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mmrSelect(tt.embeddings, tt.n, tt.diversity); !slices.Equal(got, tt.want) {
+				t.Errorf("mmrSelect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiversify(t *testing.T) {
+	type result struct {
+		name      string
+		embedding []float32
+	}
+	results := []result{
+		{"a", []float32{1, 0}},
+		{"a-copy", []float32{1, 0}},
+		{"a-near", []float32{0.99, 0.01}},
+		{"b", []float32{0, 1}},
+	}
+	embedding := func(r result) []float32 { return r.embedding }
+	names := func(rs []result) []string {
+		out := make([]string, len(rs))
+		for i, r := range rs {
+			out[i] = r.name
+		}
+		return out
+	}
+
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want []string
+	}{
+		{"off leaves results alone", SearchOptions{Limit: 2}, []string{"a", "a-copy", "a-near", "b"}},
+		{"spreads near-duplicates", SearchOptions{Limit: 2, Diversity: 0.5}, []string{"a", "b"}},
+		{"trims to limit", SearchOptions{Limit: 3, Diversity: 0.5}, []string{"a", "b", "a-copy"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := names(diversify(results, tt.opts, embedding)); !slices.Equal(got, tt.want) {
+				t.Errorf("diversify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
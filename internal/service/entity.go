@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 
 	"github.com/raphaelgruber/memcp-go/internal/db"
@@ -24,28 +25,146 @@ type EntityService struct {
 	db       *db.Client
 	embedder *llm.Embedder
 	model    *llm.Model
+	events   *EntityEvents
+
+	// contentLimit is the content size in bytes above which chunked entities
+	// keep their content only in chunks (0 = always store it on the entity).
+	contentLimit int
+
+	// embedTextLimit caps the characters of the entity-level embedding text
+	// (name, summary and content); chunk embeddings cover the full content
+	// (0 = no cap).
+	embedTextLimit int
+
+	// labels normalizes labels before they are stored (nil = store as given).
+	labels *LabelNormalizer
+
+	// detectLanguage stores the detected language of content on the entity.
+	detectLanguage bool
+
+	// confidence gives new entities without a confidence one by source.
+	confidence ConfidenceDefaults
 
 	// reindexMu protects reindexCancel from concurrent access.
 	reindexMu sync.Mutex
 	// reindexCancel tracks in-flight background re-index goroutines per entity.
 	// A new save cancels any previous in-flight re-index for the same entity.
 	reindexCancel map[string]reindexState
+
+	// analyticsMu protects analytics, the last computed graph analytics.
+	analyticsMu sync.Mutex
+	analytics   *db.GraphAnalytics
 }
 
 // CreateResult contains the result of entity creation.
 type CreateResult struct {
 	Entity        *models.Entity
 	ChunksCreated int
+	ChunksFailed  int // Chunks left out because their embedding failed
 }
 
 // NewEntityService creates a new entity service.
-func NewEntityService(db *db.Client, embedder *llm.Embedder, model *llm.Model) *EntityService {
+// Changes are published on events, which may be nil. Chunked content larger
+// than contentLimit bytes is stored only in chunks (0 disables). The text of
+// entity-level embeddings is cut to embedTextLimit characters (0 = no cap),
+// chunks are embedded in full. Labels are
+// normalized with labels before they are stored, unless it is nil. With
+// detectLanguage, the language of the content is detected and stored. New
+// entities without a confidence get the one in confidence for their source.
+func NewEntityService(db *db.Client, embedder *llm.Embedder, model *llm.Model, events *EntityEvents, contentLimit, embedTextLimit int, labels *LabelNormalizer, detectLanguage bool, confidence ConfidenceDefaults) *EntityService {
 	return &EntityService{
-		db:            db,
-		embedder:      embedder,
-		model:         model,
-		reindexCancel: make(map[string]reindexState),
+		db:             db,
+		embedder:       embedder,
+		model:          model,
+		events:         events,
+		contentLimit:   contentLimit,
+		embedTextLimit: embedTextLimit,
+		labels:         labels,
+		detectLanguage: detectLanguage,
+		confidence:     confidence,
+		reindexCancel:  make(map[string]reindexState),
+	}
+}
+
+// embedEntity embeds the entity-level text of an entity, cut to the
+// configured embedding text limit.
+func (s *EntityService) embedEntity(ctx context.Context, text string) ([]float32, error) {
+	return s.embedder.Embed(ctx, truncateContent(text, s.embedTextLimit))
+}
+
+// contentLanguage returns the detected language of content for storing on
+// the entity: nil if detection is disabled, "" if the language is unknown.
+func (s *EntityService) contentLanguage(content *string) *string {
+	if !s.detectLanguage || content == nil {
+		return nil
 	}
+	language := parser.DetectLanguage(*content)
+	return &language
+}
+
+// chunkOnly reports whether content is too large to keep on the entity once
+// it is chunked.
+func (s *EntityService) chunkOnly(content string) bool {
+	return s.contentLimit > 0 && len(content) > s.contentLimit
+}
+
+// chunkConfig returns the chunking config for content with the overrides of
+// opts. Chunk-only content is chunked by heading without overlap so the
+// chunks join back into the content.
+func (s *EntityService) chunkConfig(content string, opts ChunkOptions) (parser.ChunkConfig, error) {
+	cfg, err := opts.apply(parser.DefaultChunkConfig())
+	if err != nil {
+		return cfg, err
+	}
+	if s.chunkOnly(content) {
+		cfg.Overlap = 0
+		cfg.Strategy = parser.ChunkByHeading
+	}
+	return cfg, nil
+}
+
+// dropChunkedContent removes content above the content limit from an entity
+// whose chunks were just created. GetEntityContent reassembles it.
+func (s *EntityService) dropChunkedContent(ctx context.Context, id string, content string) {
+	if !s.chunkOnly(content) {
+		return
+	}
+	if err := s.db.ClearEntityContent(ctx, id); err != nil {
+		slog.Warn("failed to drop chunked entity content", "entity", id, "error", err)
+	}
+}
+
+// GetEntityContent returns an entity's content, reassembling it from its
+// chunks when it is stored only there. Reassembled content has normalized
+// spacing. Returns "" for entities without content.
+func (s *EntityService) GetEntityContent(ctx context.Context, id string) (string, error) {
+	entity, err := s.db.GetEntity(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("get entity: %w", err)
+	}
+	if entity == nil {
+		return "", fmt.Errorf("entity not found: %s", id)
+	}
+	if entity.Content != nil {
+		return *entity.Content, nil
+	}
+	return s.chunkedContent(ctx, id)
+}
+
+// chunkedContent joins an entity's chunks back into its content.
+func (s *EntityService) chunkedContent(ctx context.Context, id string) (string, error) {
+	chunks, err := s.db.GetChunks(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	results := make([]parser.ChunkResult, len(chunks))
+	for i, c := range chunks {
+		results[i] = parser.ChunkResult{Content: c.Content, Position: c.Position}
+		if c.HeadingPath != nil {
+			results[i].HeadingPath = *c.HeadingPath
+		}
+	}
+	return parser.JoinChunks(results), nil
 }
 
 // Create creates a new entity with automatic embedding generation.
@@ -54,27 +173,29 @@ func NewEntityService(db *db.Client, embedder *llm.Embedder, model *llm.Model) *
 // If input.ID is provided, uses upsert to update existing entity (makes scrape idempotent).
 // Returns CreateResult with entity and chunk count.
 func (s *EntityService) Create(ctx context.Context, input models.EntityInput) (*CreateResult, error) {
+	return s.CreateWithChunking(ctx, input, ChunkOptions{})
+}
+
+// CreateWithChunking creates an entity like Create, chunking long content
+// with the overrides of chunking.
+func (s *EntityService) CreateWithChunking(ctx context.Context, input models.EntityInput, chunking ChunkOptions) (*CreateResult, error) {
+	var chunkCfg parser.ChunkConfig
+	if input.Content != nil {
+		var err error
+		if chunkCfg, err = s.chunkConfig(*input.Content, chunking); err != nil {
+			return nil, fmt.Errorf("chunk options: %w", err)
+		}
+	}
+
+	s.applyInputDefaults(&input)
+
 	// Check if content will be chunked - if so, skip entity-level embedding
-	willChunk := input.Content != nil && parser.ShouldChunk(*input.Content, parser.DefaultChunkConfig())
+	willChunk := input.Content != nil && parser.ShouldChunk(*input.Content, chunkCfg)
 
 	// Generate embedding from content/summary (skip if content will be chunked)
 	if s.embedder != nil && !willChunk {
-		text := ""
-		if input.Summary != nil {
-			text = *input.Summary
-		}
-		if input.Content != nil {
-			if text != "" {
-				text += " "
-			}
-			text += *input.Content
-		}
-		if input.Name != "" {
-			text = input.Name + " " + text
-		}
-
-		if text != "" {
-			embedding, err := s.embedder.Embed(ctx, text)
+		if text := embeddingText(input); text != "" {
+			embedding, err := s.embedEntity(ctx, text)
 			if err != nil {
 				return nil, fmt.Errorf("generate embedding: %w", err)
 			}
@@ -87,7 +208,7 @@ func (s *EntityService) Create(ctx context.Context, input models.EntityInput) (*
 	}
 
 	var entity *models.Entity
-	var wasCreated bool
+	wasCreated := true
 	var err error
 
 	// Use upsert when explicit ID is provided (for scrape idempotency)
@@ -113,20 +234,26 @@ func (s *EntityService) Create(ctx context.Context, input models.EntityInput) (*
 		}
 	}
 
+	if wasCreated {
+		s.events.Publish(EntityCreated, entity)
+	} else {
+		s.events.Publish(EntityUpdated, entity)
+	}
+
 	result := &CreateResult{Entity: entity}
 
 	// Check if content should be chunked (skip if content is empty)
-	if input.Content != nil && *input.Content != "" && parser.ShouldChunk(*input.Content, parser.DefaultChunkConfig()) {
+	if input.Content != nil && *input.Content != "" && parser.ShouldChunk(*input.Content, chunkCfg) {
 		idStr, idErr := models.RecordIDString(entity.ID)
 		if idErr != nil {
 			slog.Warn("failed to get entity ID for chunking", "error", idErr)
-		} else if chunksCreated, err := s.chunkEntity(ctx, entity); err != nil {
+		} else if chunksCreated, chunksFailed, err := s.chunkEntity(ctx, entity, chunking); err != nil {
 			// Chunking failed — entity has no embedding and no chunks, making it
 			// invisible to search. Fall back to entity-level embedding.
 			slog.Warn("failed to chunk entity, falling back to entity embedding", "entity", idStr, "error", err)
 			if s.embedder != nil {
 				text := input.Name + " " + *input.Content
-				if emb, embErr := s.embedder.Embed(ctx, text); embErr != nil {
+				if emb, embErr := s.embedEntity(ctx, text); embErr != nil {
 					slog.Warn("fallback entity embedding also failed", "entity", idStr, "error", embErr)
 				} else {
 					embUpdate := models.EntityUpdate{Embedding: emb}
@@ -137,8 +264,10 @@ func (s *EntityService) Create(ctx context.Context, input models.EntityInput) (*
 			}
 		} else {
 			result.ChunksCreated = chunksCreated
-			if chunksCreated > 0 {
+			result.ChunksFailed = chunksFailed
+			if chunksCreated > 0 && chunksFailed == 0 {
 				slog.Debug("chunked entity", "entity", idStr, "chunks", chunksCreated)
+				s.dropChunkedContent(ctx, idStr, *input.Content)
 			}
 		}
 	}
@@ -146,51 +275,217 @@ func (s *EntityService) Create(ctx context.Context, input models.EntityInput) (*
 	return result, nil
 }
 
-// chunkEntity creates chunks for an entity with long content.
-// Returns the number of chunks created.
-func (s *EntityService) chunkEntity(ctx context.Context, entity *models.Entity) (int, error) {
+// applyInputDefaults normalizes the labels of a new entity and fills in its
+// default confidence and detected language.
+func (s *EntityService) applyInputDefaults(input *models.EntityInput) {
+	input.Labels = s.labels.Normalize(input.Labels)
+	s.confidence.apply(input)
+	if input.Language == nil {
+		if language := s.contentLanguage(input.Content); language != nil && *language != "" {
+			input.Language = language
+		}
+	}
+}
+
+// embeddingText returns the text an entity's embedding is computed from:
+// its name, summary and content.
+func embeddingText(input models.EntityInput) string {
+	text := ""
+	if input.Summary != nil {
+		text = *input.Summary
+	}
+	if input.Content != nil {
+		if text != "" {
+			text += " "
+		}
+		text += *input.Content
+	}
+	if input.Name != "" {
+		text = input.Name + " " + text
+	}
+	return text
+}
+
+// maxCreateBatch bounds the entities of one CreateBatch call.
+const maxCreateBatch = 1000
+
+// CreateBatch creates many entities at once. Their embeddings are computed
+// in one EmbedBatch request and the entities inserted with
+// CreateEntitiesBatch. Inputs with an explicit ID or content long enough to
+// be chunked are created one by one, like Create. An input that fails only
+// fails its own result, including when its embedding fails (see
+// embedCreateBatch); the returned error means the batch failed as a whole,
+// e.g. because the insert failed. Results are in input order.
+func (s *EntityService) CreateBatch(ctx context.Context, inputs []models.EntityInput) ([]db.EntityBatchResult, error) {
+	if len(inputs) > maxCreateBatch {
+		return nil, fmt.Errorf("at most %d entities per batch, got %d", maxCreateBatch, len(inputs))
+	}
+
+	results := make([]db.EntityBatchResult, len(inputs))
+	batch, positions, single := s.splitCreateBatch(inputs, results)
+	batch, positions = withoutFailed(batch, positions, s.embedCreateBatch(ctx, batch), results)
+
+	if len(batch) > 0 {
+		created, err := s.db.CreateEntitiesBatch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		for j, result := range created {
+			results[positions[j]] = result
+			if result.Entity != nil {
+				s.events.Publish(EntityCreated, result.Entity)
+			}
+		}
+	}
+
+	for _, i := range single {
+		result, err := s.Create(ctx, inputs[i])
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Entity = result.Entity
+	}
+
+	return results, nil
+}
+
+// splitCreateBatch sorts CreateBatch inputs into those inserted together,
+// with defaults applied, and the indexes of those created one by one.
+// positions holds the index in inputs of each batch entry. Inputs with
+// invalid chunk options get their error in results and are in neither.
+func (s *EntityService) splitCreateBatch(inputs []models.EntityInput, results []db.EntityBatchResult) (batch []models.EntityInput, positions, single []int) {
+	for i, input := range inputs {
+		if input.ID != nil && *input.ID != "" {
+			single = append(single, i)
+			continue
+		}
+		if input.Content != nil {
+			cfg, err := s.chunkConfig(*input.Content, ChunkOptions{})
+			if err != nil {
+				results[i].Err = fmt.Errorf("chunk options: %w", err)
+				continue
+			}
+			if parser.ShouldChunk(*input.Content, cfg) {
+				single = append(single, i)
+				continue
+			}
+		}
+		s.applyInputDefaults(&input)
+		batch = append(batch, input)
+		positions = append(positions, i)
+	}
+	return batch, positions, single
+}
+
+// embedCreateBatch sets the embeddings of batch entries and returns the
+// error of each entry whose embedding failed. All are embedded in one
+// request; if it fails, they are embedded again in sub-batches (see
+// llm.Embedder.EmbedBatchPartial), so one bad input doesn't fail the rest.
+func (s *EntityService) embedCreateBatch(ctx context.Context, batch []models.EntityInput) []error {
+	errs := make([]error, len(batch))
+	if s.embedder == nil {
+		return errs
+	}
+
+	var texts []string
+	var embedded []int // Index in batch of each text
+	for i, input := range batch {
+		if text := embeddingText(input); text != "" {
+			texts = append(texts, truncateContent(text, s.embedTextLimit))
+			embedded = append(embedded, i)
+		}
+	}
+	if len(texts) == 0 {
+		return errs
+	}
+
+	embeddings, err := s.embedder.EmbedBatch(ctx, texts)
+	if err != nil {
+		slog.Warn("batch embedding failed, embedding in sub-batches", "texts", len(texts), "error", err)
+		partial := s.embedder.EmbedBatchPartial(ctx, texts)
+		embeddings = partial.Embeddings
+		for j, i := range embedded {
+			if partial.Errors[j] != nil {
+				errs[i] = fmt.Errorf("generate embedding: %w", partial.Errors[j])
+			}
+		}
+	}
+	for j, i := range embedded {
+		batch[i].Embedding = embeddings[j]
+	}
+	return errs
+}
+
+// withoutFailed drops the batch entries with an error in errs, recording
+// it in results at the entry's input position. Returns the remaining
+// entries and their positions, in order.
+func withoutFailed(batch []models.EntityInput, positions []int, errs []error, results []db.EntityBatchResult) ([]models.EntityInput, []int) {
+	kept, keptPositions := batch[:0], positions[:0]
+	for i, input := range batch {
+		if errs[i] != nil {
+			results[positions[i]].Err = errs[i]
+			continue
+		}
+		kept = append(kept, input)
+		keptPositions = append(keptPositions, positions[i])
+	}
+	return kept, keptPositions
+}
+
+// chunkEntity creates chunks for an entity with long content, applying the
+// overrides of chunking. Returns the number of chunks created and the number left out because their
+// embedding failed after retries. Fails only if no chunk could be embedded.
+func (s *EntityService) chunkEntity(ctx context.Context, entity *models.Entity, chunking ChunkOptions) (int, int, error) {
 	if entity.Content == nil {
-		return 0, nil
+		return 0, 0, nil
 	}
 
 	entityID, err := models.RecordIDString(entity.ID)
 	if err != nil {
-		return 0, fmt.Errorf("get entity ID: %w", err)
+		return 0, 0, fmt.Errorf("get entity ID: %w", err)
 	}
 
 	doc, err := parser.ParseMarkdown(*entity.Content)
 	if err != nil {
-		return 0, fmt.Errorf("parse markdown: %w", err)
+		return 0, 0, fmt.Errorf("parse markdown: %w", err)
 	}
 
-	chunks := parser.ChunkMarkdown(doc, parser.DefaultChunkConfig())
+	cfg, err := s.chunkConfig(*entity.Content, chunking)
+	if err != nil {
+		return 0, 0, fmt.Errorf("chunk options: %w", err)
+	}
+	chunks := parser.ChunkMarkdown(doc, cfg)
 	if len(chunks) == 0 {
 		// No meaningful content to chunk (e.g., all-empty sections)
 		slog.Debug("no chunks produced - content may be empty sections only", "entity", entityID)
-		return 0, nil
+		return 0, 0, nil
 	}
 	if len(chunks) == 1 {
-		return 0, nil // No need to chunk - single chunk handled at entity level
+		return 0, 0, nil // No need to chunk - single chunk handled at entity level
 	}
 
-	// Batch embed all chunks at once
-	var embeddings [][]float32
+	// Batch embed all chunks, keeping what succeeds if some sub-batches fail
+	var batch llm.BatchResult
 	if s.embedder != nil {
 		texts := make([]string, len(chunks))
 		for i, chunk := range chunks {
 			texts[i] = chunk.Content
 		}
-		embeddings, err = s.embedder.EmbedBatch(ctx, texts)
-		if err != nil {
-			return 0, fmt.Errorf("batch embed chunks: %w", err)
+		batch = s.embedder.EmbedBatchPartial(ctx, texts)
+		if batch.Failed == len(chunks) {
+			return 0, 0, fmt.Errorf("batch embed chunks: %w", batch.Errors[0])
 		}
 	}
 
 	chunkInputs := make([]models.ChunkInput, 0, len(chunks))
 	for i, chunk := range chunks {
 		var embedding []float32
-		if embeddings != nil {
-			embedding = embeddings[i]
+		if batch.Embeddings != nil {
+			if batch.Errors[i] != nil {
+				continue
+			}
+			embedding = batch.Embeddings[i]
 		}
 
 		headingPath := chunk.HeadingPath
@@ -205,13 +500,27 @@ func (s *EntityService) chunkEntity(ctx context.Context, entity *models.Entity)
 	}
 
 	if err := s.db.CreateChunks(ctx, entityID, chunkInputs); err != nil {
-		return 0, err
+		return 0, 0, err
 	}
-	return len(chunkInputs), nil
+	if batch.Failed > 0 {
+		slog.Warn("stored entity with incomplete chunks", "entity", entityID, "chunks", len(chunkInputs), "failed", batch.Failed)
+	}
+	return len(chunkInputs), batch.Failed, nil
 }
 
-// Update updates an entity with re-chunking if content changed.
+// Update updates an entity with re-chunking if content changed. An update
+// changing no field is rejected rather than only touching the access time.
 func (s *EntityService) Update(ctx context.Context, id string, update models.EntityUpdate) (*models.Entity, error) {
+	update.Labels = s.labels.Normalize(update.Labels)
+	update.AddLabels = s.labels.Normalize(update.AddLabels)
+	update.DelLabels = s.labels.Normalize(update.DelLabels)
+	if updatesNothing(update) {
+		return nil, fmt.Errorf("nothing to update: set at least one of name, content, summary, labels, addLabels, delLabels, verified or metadata")
+	}
+	if update.Language == nil {
+		update.Language = s.contentLanguage(update.Content)
+	}
+
 	// Re-generate embedding if content or summary changed
 	if s.embedder != nil && (update.Content != nil || update.Summary != nil) {
 		// Get current entity to merge text
@@ -235,7 +544,7 @@ func (s *EntityService) Update(ctx context.Context, id string, update models.Ent
 			text += " " + *current.Content
 		}
 
-		embedding, err := s.embedder.Embed(ctx, text)
+		embedding, err := s.embedEntity(ctx, text)
 		if err != nil {
 			return nil, fmt.Errorf("generate embedding: %w", err)
 		}
@@ -247,6 +556,7 @@ func (s *EntityService) Update(ctx context.Context, id string, update models.Ent
 	if err != nil {
 		return nil, err
 	}
+	s.events.Publish(EntityUpdated, entity)
 
 	// Re-chunk if content changed
 	if update.Content != nil {
@@ -257,10 +567,12 @@ func (s *EntityService) Update(ctx context.Context, id string, update models.Ent
 
 		// Create new chunks if content is long
 		if parser.ShouldChunk(*update.Content, parser.DefaultChunkConfig()) {
-			if _, err := s.chunkEntity(ctx, entity); err != nil {
+			if chunksCreated, chunksFailed, err := s.chunkEntity(ctx, entity, ChunkOptions{}); err != nil {
 				// Re-chunking failed after old chunks were deleted — entity has no chunks.
 				// The entity-level embedding was already updated above, so search still works.
 				slog.Warn("failed to re-chunk entity", "entity", id, "error", err)
+			} else if chunksCreated > 0 && chunksFailed == 0 {
+				s.dropChunkedContent(ctx, id, *update.Content)
 			}
 		}
 	}
@@ -268,6 +580,79 @@ func (s *EntityService) Update(ctx context.Context, id string, update models.Ent
 	return entity, nil
 }
 
+// updatesNothing reports whether update leaves every field unchanged.
+// Labels set to an empty list clear the labels, so they count as a change.
+func updatesNothing(update models.EntityUpdate) bool {
+	return update.Name == nil && update.Content == nil && update.Summary == nil &&
+		update.Labels == nil && len(update.AddLabels) == 0 && len(update.DelLabels) == 0 &&
+		update.Verified == nil && update.Confidence == nil && update.Metadata == nil &&
+		update.Language == nil && update.Embedding == nil
+}
+
+// ReindexEntity regenerates an entity's embedding and chunks from its current
+// content. Use it after content was changed without going through Update or
+// UpdateContent (e.g. directly in the database).
+func (s *EntityService) ReindexEntity(ctx context.Context, id string) error {
+	entity, err := s.db.GetEntity(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get entity: %w", err)
+	}
+	if entity == nil {
+		return fmt.Errorf("entity not found: %s", id)
+	}
+	if entity.Content == nil {
+		// Content may be stored only in chunks
+		content, err := s.chunkedContent(ctx, id)
+		if err != nil {
+			return fmt.Errorf("reassemble content: %w", err)
+		}
+		if content != "" {
+			entity.Content = &content
+		}
+	}
+
+	update := models.EntityUpdate{Language: s.contentLanguage(entity.Content)}
+	if s.embedder != nil {
+		text := entity.Name
+		if entity.Summary != nil {
+			text += " " + *entity.Summary
+		}
+		if entity.Content != nil {
+			text += " " + *entity.Content
+		}
+
+		embedding, err := s.embedEntity(ctx, text)
+		if err != nil {
+			return fmt.Errorf("generate embedding: %w", err)
+		}
+		update.Embedding = embedding
+	}
+	if update.Embedding != nil || update.Language != nil {
+		if _, err := s.db.UpdateEntity(ctx, id, update); err != nil {
+			return fmt.Errorf("save embedding: %w", err)
+		}
+	}
+
+	if err := s.db.DeleteChunks(ctx, id); err != nil {
+		return fmt.Errorf("delete old chunks: %w", err)
+	}
+	if entity.Content != nil && parser.ShouldChunk(*entity.Content, parser.DefaultChunkConfig()) {
+		chunksCreated, chunksFailed, err := s.chunkEntity(ctx, entity, ChunkOptions{})
+		if err != nil {
+			return fmt.Errorf("rechunk: %w", err)
+		}
+		if chunksFailed > 0 {
+			return fmt.Errorf("rechunk: %d of %d chunk embeddings failed", chunksFailed, chunksCreated+chunksFailed)
+		}
+		slog.Debug("reindexed entity", "entity", id, "chunks", chunksCreated)
+		if chunksCreated > 0 {
+			s.dropChunkedContent(ctx, id, *entity.Content)
+		}
+	}
+
+	return nil
+}
+
 // UpdateContent updates entity content synchronously and re-indexes in the background.
 // Returns the updated entity immediately without waiting for embedding/chunking.
 func (s *EntityService) UpdateContent(ctx context.Context, id string, content string) (*models.Entity, error) {
@@ -279,6 +664,7 @@ func (s *EntityService) UpdateContent(ctx context.Context, id string, content st
 	if err != nil {
 		return nil, fmt.Errorf("update content: %w", err)
 	}
+	s.events.Publish(EntityUpdated, entity)
 
 	// Delete old chunks (sync) so stale chunks aren't returned during re-indexing
 	if err := s.db.DeleteChunks(ctx, id); err != nil {
@@ -314,7 +700,7 @@ func (s *EntityService) UpdateContent(ctx context.Context, id string, content st
 			}
 			text += " " + content
 
-			embedding, err := s.embedder.Embed(bgCtx, text)
+			embedding, err := s.embedEntity(bgCtx, text)
 			if err != nil {
 				if bgCtx.Err() != nil {
 					return // superseded by newer save
@@ -344,11 +730,13 @@ func (s *EntityService) UpdateContent(ctx context.Context, id string, content st
 			return
 		}
 		if updated != nil && updated.Content != nil {
-			if _, err := s.chunkEntity(bgCtx, updated); err != nil {
+			if chunksCreated, chunksFailed, err := s.chunkEntity(bgCtx, updated, ChunkOptions{}); err != nil {
 				if bgCtx.Err() != nil {
 					return
 				}
 				slog.Warn("background re-chunk failed", "entity", id, "error", err)
+			} else if chunksCreated > 0 && chunksFailed == 0 {
+				s.dropChunkedContent(bgCtx, id, *updated.Content)
 			}
 		}
 	}()
@@ -356,6 +744,74 @@ func (s *EntityService) UpdateContent(ctx context.Context, id string, content st
 	return entity, nil
 }
 
+// SetAlwaysInContext sets whether an entity is included in every ask context.
+func (s *EntityService) SetAlwaysInContext(ctx context.Context, id string, enabled bool) (*models.Entity, error) {
+	entity, err := s.db.SetAlwaysInContext(ctx, id, enabled)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(EntityUpdated, entity)
+	return entity, nil
+}
+
+// SetPinned sets whether an entity is exempt from decay.
+func (s *EntityService) SetPinned(ctx context.Context, id string, pinned bool) (*models.Entity, error) {
+	entity, err := s.db.SetPinned(ctx, id, pinned)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(EntityUpdated, entity)
+	return entity, nil
+}
+
+// AddAlias adds an alternative name to an entity, so relations naming it
+// differently (e.g. "k8s" for "Kubernetes") resolve to it instead of
+// creating a duplicate. See db.Client.AddAlias.
+func (s *EntityService) AddAlias(ctx context.Context, id, alias string) (*models.Entity, error) {
+	entity, err := s.db.AddAlias(ctx, id, alias)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(EntityUpdated, entity)
+	return entity, nil
+}
+
+// Merge merges the entity mergeID into keepID (see db.Client.MergeEntities),
+// e.g. to combine duplicates ingested under different names. Returns the
+// merged entity.
+func (s *EntityService) Merge(ctx context.Context, keepID, mergeID string) (*models.Entity, error) {
+	merged, err := s.db.GetEntity(ctx, mergeID)
+	if err != nil {
+		return nil, err
+	}
+
+	entity, err := s.db.MergeEntities(ctx, keepID, mergeID)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("merged entities", "keep", keepID, "merged", mergeID)
+	if merged != nil {
+		s.events.Publish(EntityDeleted, merged)
+	}
+	s.events.Publish(EntityUpdated, entity)
+	return entity, nil
+}
+
+// VerifyEntities marks entities as verified, e.g. after reviewing them from
+// the review queue. Returns the number of entities that changed; unknown and
+// already verified IDs are skipped.
+func (s *EntityService) VerifyEntities(ctx context.Context, ids []string) (int, error) {
+	entities, err := s.db.SetVerified(ctx, ids, true)
+	if err != nil {
+		return 0, err
+	}
+	for i := range entities {
+		s.events.Publish(EntityUpdated, &entities[i])
+	}
+	return len(entities), nil
+}
+
 // Get retrieves an entity by ID and updates access tracking.
 func (s *EntityService) Get(ctx context.Context, id string) (*models.Entity, error) {
 	entity, err := s.db.GetEntity(ctx, id)
@@ -366,18 +822,82 @@ func (s *EntityService) Get(ctx context.Context, id string) (*models.Entity, err
 		if err := s.db.UpdateEntityAccess(ctx, id); err != nil {
 			slog.Warn("failed to update entity access", "entity", id, "error", err)
 		}
+		if entity.Content == nil {
+			// Content may be stored only in chunks
+			content, err := s.chunkedContent(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("reassemble content: %w", err)
+			}
+			if content != "" {
+				entity.Content = &content
+			}
+		}
 	}
 	return entity, nil
 }
 
 // Delete deletes an entity by ID (chunks/relations cascade deleted by DB).
 func (s *EntityService) Delete(ctx context.Context, id string) (bool, error) {
-	return s.db.DeleteEntity(ctx, id)
+	// Fetch the entity first so the deleted event carries its name and labels
+	entity, err := s.db.GetEntity(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("get entity: %w", err)
+	}
+
+	deleted, err := s.db.DeleteEntity(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if deleted {
+		s.events.Publish(EntityDeleted, entity)
+	}
+	return deleted, nil
+}
+
+// DeleteByLabel deletes all entities with the label, including their chunks
+// and relations. Returns the number of entities deleted.
+func (s *EntityService) DeleteByLabel(ctx context.Context, label string) (int, error) {
+	if strings.TrimSpace(label) == "" {
+		return 0, fmt.Errorf("label required")
+	}
+	// Stored labels are normalized, so an alias must match its canonical label
+	if normalized := s.labels.Normalize([]string{label}); len(normalized) == 1 {
+		label = normalized[0]
+	}
+
+	deleted, err := s.db.DeleteEntitiesByLabel(ctx, label)
+	if err != nil {
+		return 0, err
+	}
+	for i := range deleted {
+		s.events.Publish(EntityDeleted, &deleted[i])
+	}
+	slog.Info("deleted entities by label", "label", label, "count", len(deleted))
+	return len(deleted), nil
+}
+
+// Compact removes dangling chunks and relations and empty entities.
+// With dryRun, it only reports what would be removed.
+func (s *EntityService) Compact(ctx context.Context, dryRun bool) (db.CompactReport, error) {
+	report, err := s.db.Compact(ctx, dryRun)
+	if err != nil {
+		return report, err
+	}
+	if !dryRun {
+		for i := range report.EmptyEntities {
+			s.events.Publish(EntityDeleted, &report.EmptyEntities[i])
+		}
+	}
+	return report, nil
 }
 
 // CreateRelation creates a relation between entities.
 func (s *EntityService) CreateRelation(ctx context.Context, input models.RelationInput) error {
-	return s.db.CreateRelation(ctx, input)
+	if err := s.db.CreateRelation(ctx, input); err != nil {
+		return err
+	}
+	s.events.Written()
+	return nil
 }
 
 // GetRelations gets all relations for an entity.
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/raphaelgruber/memcp-go/internal/config"
+	"github.com/raphaelgruber/memcp-go/internal/db"
+	"github.com/raphaelgruber/memcp-go/internal/llm"
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+func TestUpdatesNothing(t *testing.T) {
+	content := "new content"
+	verified := false
+
+	tests := []struct {
+		name   string
+		update models.EntityUpdate
+		want   bool
+	}{
+		{"empty", models.EntityUpdate{}, true},
+		{"empty label changes", models.EntityUpdate{AddLabels: []string{}, DelLabels: []string{}}, true},
+		{"content", models.EntityUpdate{Content: &content}, false},
+		{"verified false", models.EntityUpdate{Verified: &verified}, false},
+		{"labels cleared", models.EntityUpdate{Labels: []string{}}, false},
+		{"add label", models.EntityUpdate{AddLabels: []string{"k8s"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := updatesNothing(tt.update); got != tt.want {
+				t.Errorf("updatesNothing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitCreateBatch(t *testing.T) {
+	id := "given-id"
+	short := "short content"
+	long := strings.Repeat("A paragraph of content long enough to be chunked.\n\n", 50)
+	inputs := []models.EntityInput{
+		{Name: "a", Labels: []string{"K8s"}},
+		{Name: "with id", ID: &id},
+		{Name: "long", Content: &long},
+		{Name: "b", Content: &short},
+		{Name: "c"},
+	}
+
+	s := &EntityService{labels: NewLabelNormalizer(map[string]string{"k8s": "kubernetes"})}
+	results := make([]db.EntityBatchResult, len(inputs))
+	batch, positions, single := s.splitCreateBatch(inputs, results)
+
+	var names []string
+	for _, input := range batch {
+		names = append(names, input.Name)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(names, want) {
+		t.Errorf("batch = %v, want %v", names, want)
+	}
+	if want := []int{0, 3, 4}; !slices.Equal(positions, want) {
+		t.Errorf("positions = %v, want %v", positions, want)
+	}
+	if want := []int{1, 2}; !slices.Equal(single, want) {
+		t.Errorf("single = %v, want %v", single, want)
+	}
+	// Defaults are applied to batch entries only, not to the inputs
+	if want := []string{"kubernetes"}; !slices.Equal(batch[0].Labels, want) {
+		t.Errorf("batch labels = %v, want %v", batch[0].Labels, want)
+	}
+	if inputs[0].Labels[0] != "K8s" {
+		t.Errorf("input labels modified: %v", inputs[0].Labels)
+	}
+}
+
+func TestWithoutFailed(t *testing.T) {
+	batch := []models.EntityInput{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+	positions := []int{0, 2, 3, 5}
+	errB, errD := errors.New("b failed"), errors.New("d failed")
+	results := make([]db.EntityBatchResult, 6)
+
+	kept, keptPositions := withoutFailed(batch, positions, []error{nil, errB, nil, errD}, results)
+
+	var names []string
+	for _, input := range kept {
+		names = append(names, input.Name)
+	}
+	if want := []string{"a", "c"}; !slices.Equal(names, want) {
+		t.Errorf("kept = %v, want %v", names, want)
+	}
+	if want := []int{0, 3}; !slices.Equal(keptPositions, want) {
+		t.Errorf("positions = %v, want %v", keptPositions, want)
+	}
+	// Errors land at the failed entries' input positions
+	for i, want := range []error{nil, nil, errB, nil, nil, errD} {
+		if results[i].Err != want {
+			t.Errorf("results[%d].Err = %v, want %v", i, results[i].Err, want)
+		}
+	}
+}
+
+// embeddingServer is an OpenAI-compatible embeddings API failing requests
+// with an input containing "bad" with 400, counting requests in calls.
+func embeddingServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req struct {
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if slices.ContainsFunc(req.Input, func(s string) bool { return strings.Contains(s, "bad") }) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"invalid input"}}`)
+			return
+		}
+		data := make([]map[string]any, len(req.Input))
+		for i := range req.Input {
+			data[i] = map[string]any{"object": "embedding", "embedding": []float32{1, 0}, "index": i}
+		}
+		if err := json.NewEncoder(w).Encode(map[string]any{"object": "list", "data": data}); err != nil {
+			t.Errorf("encode response: %v", err)
+		}
+	}))
+}
+
+func TestEmbedCreateBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		names     []string
+		wantErrs  []bool
+		wantCalls int32
+	}{
+		{"one request", []string{"good one", "good two", "good three"}, []bool{false, false, false}, 1},
+		{"failure embedded per input", []string{"good one", "bad one", "good two"}, []bool{false, true, false}, 4},
+		{"empty text skipped", []string{"good one", "", "bad one"}, []bool{false, false, true}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := embeddingServer(t, &calls)
+			defer server.Close()
+
+			embedder, err := llm.NewEmbedder(context.Background(), config.Config{
+				EmbedProvider:  config.ProviderOpenAI,
+				EmbedModel:     "test-embed",
+				EmbedDimension: 2,
+				EmbedBatchSize: 1,
+				OpenAIBaseURL:  server.URL,
+			}, nil, nil)
+			if err != nil {
+				t.Fatalf("NewEmbedder() error = %v", err)
+			}
+
+			batch := make([]models.EntityInput, len(tt.names))
+			for i, name := range tt.names {
+				batch[i].Name = name
+			}
+			s := &EntityService{embedder: embedder}
+			errs := s.embedCreateBatch(context.Background(), batch)
+
+			for i, wantErr := range tt.wantErrs {
+				if (errs[i] != nil) != wantErr {
+					t.Errorf("errs[%d] = %v, wantErr %v", i, errs[i], wantErr)
+				}
+				if hasEmbedding := batch[i].Embedding != nil; hasEmbedding != (tt.names[i] != "" && !wantErr) {
+					t.Errorf("batch[%d] embedding = %v", i, batch[i].Embedding)
+				}
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
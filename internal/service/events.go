@@ -0,0 +1,122 @@
+package service
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// Entity change types published on EntityEvents.
+const (
+	EntityCreated = "created"
+	EntityUpdated = "updated"
+	EntityDeleted = "deleted"
+)
+
+// entityEventBuffer is the per-subscriber channel size. Events for a
+// subscriber that falls this far behind are dropped instead of blocking writes.
+const entityEventBuffer = 64
+
+// EntityEvent describes a change to an entity.
+type EntityEvent struct {
+	Type   string         // EntityCreated, EntityUpdated or EntityDeleted
+	Entity *models.Entity // state after the change; before it for deletes
+}
+
+// EntityEvents is an in-process pub/sub for entity changes made through the
+// service layer (API calls and background jobs alike). A nil *EntityEvents is
+// valid and publishes nothing.
+type EntityEvents struct {
+	mu      sync.RWMutex
+	subs    map[int]entitySubscriber
+	nextID  int
+	onWrite []func() // run synchronously on every write, see OnWrite
+}
+
+type entitySubscriber struct {
+	ch     chan EntityEvent
+	labels []string
+}
+
+// NewEntityEvents creates an empty event bus.
+func NewEntityEvents() *EntityEvents {
+	return &EntityEvents{subs: make(map[int]entitySubscriber)}
+}
+
+// Subscribe registers a subscriber receiving events for entities with any of
+// the given labels (all entities if labels is empty). The returned function
+// unsubscribes and closes the channel.
+func (e *EntityEvents) Subscribe(labels []string) (<-chan EntityEvent, func()) {
+	ch := make(chan EntityEvent, entityEventBuffer)
+
+	e.mu.Lock()
+	id := e.nextID
+	e.nextID++
+	e.subs[id] = entitySubscriber{ch: ch, labels: labels}
+	e.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			e.mu.Lock()
+			delete(e.subs, id)
+			e.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// OnWrite registers fn to run on every write reported to e: each published
+// event and each write that changes no entity (see Written). Unlike
+// subscribers, fn runs synchronously before the write returns, so it is
+// never dropped or late. fn must not block.
+func (e *EntityEvents) OnWrite(fn func()) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onWrite = append(e.onWrite, fn)
+}
+
+// Written reports a write that changes no entity itself, such as creating
+// or removing relations, to the OnWrite functions.
+func (e *EntityEvents) Written() {
+	if e == nil {
+		return
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, fn := range e.onWrite {
+		fn()
+	}
+}
+
+// Publish runs the OnWrite functions and sends an event to all matching
+// subscribers without blocking.
+func (e *EntityEvents) Publish(eventType string, entity *models.Entity) {
+	if e == nil || entity == nil {
+		return
+	}
+	e.Written()
+
+	event := EntityEvent{Type: eventType, Entity: entity}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, sub := range e.subs {
+		if len(sub.labels) > 0 && !slices.ContainsFunc(entity.Labels, func(l string) bool {
+			return slices.Contains(sub.labels, l)
+		}) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			slog.Warn("dropping entity event for slow subscriber", "type", eventType, "entity", entity.Name)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// drain returns the names of the entities of the events buffered on ch.
+func drain(ch <-chan EntityEvent) []string {
+	var names []string
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return names
+			}
+			names = append(names, event.Type+":"+event.Entity.Name)
+		default:
+			return names
+		}
+	}
+}
+
+func TestEntityEventsLabelFilter(t *testing.T) {
+	events := NewEntityEvents()
+	all, unsubscribeAll := events.Subscribe(nil)
+	defer unsubscribeAll()
+	work, unsubscribeWork := events.Subscribe([]string{"work"})
+	defer unsubscribeWork()
+	opsOrAuth, unsubscribeOps := events.Subscribe([]string{"ops", "auth"})
+	defer unsubscribeOps()
+
+	events.Publish(EntityCreated, &models.Entity{Name: "a", Labels: []string{"work"}})
+	events.Publish(EntityUpdated, &models.Entity{Name: "b", Labels: []string{"auth", "misc"}})
+	events.Publish(EntityDeleted, &models.Entity{Name: "c"})
+	events.Publish(EntityCreated, nil) // ignored
+
+	tests := []struct {
+		name string
+		ch   <-chan EntityEvent
+		want []string
+	}{
+		{"no labels receives all", all, []string{"created:a", "updated:b", "deleted:c"}},
+		{"one label", work, []string{"created:a"}},
+		{"any of several labels", opsOrAuth, []string{"updated:b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := drain(tt.ch); !slices.Equal(got, tt.want) {
+				t.Errorf("events = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntityEventsUnsubscribe(t *testing.T) {
+	events := NewEntityEvents()
+	ch, unsubscribe := events.Subscribe(nil)
+	other, unsubscribeOther := events.Subscribe(nil)
+	defer unsubscribeOther()
+
+	unsubscribe()
+	if _, ok := <-ch; ok {
+		t.Error("channel open after unsubscribe")
+	}
+	unsubscribe() // Repeated calls are no-ops
+
+	// Publishing after unsubscribing neither panics nor reaches the channel
+	events.Publish(EntityCreated, &models.Entity{Name: "a"})
+	if got := drain(other); !slices.Equal(got, []string{"created:a"}) {
+		t.Errorf("remaining subscriber events = %q, want the event", got)
+	}
+}
+
+func TestEntityEventsSlowSubscriber(t *testing.T) {
+	events := NewEntityEvents()
+	slow, unsubscribe := events.Subscribe(nil)
+	defer unsubscribe()
+
+	written := 0
+	events.OnWrite(func() { written++ })
+
+	// A subscriber that never reads must not block publishers
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for i := range entityEventBuffer + 10 {
+			events.Publish(EntityUpdated, &models.Entity{Name: fmt.Sprintf("e%d", i)})
+		}
+	}()
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish blocked on a slow subscriber")
+	}
+
+	got := drain(slow)
+	if len(got) != entityEventBuffer {
+		t.Errorf("buffered %d events, want %d", len(got), entityEventBuffer)
+	}
+	if len(got) > 0 && got[0] != "updated:e0" {
+		t.Errorf("first event = %q, want the oldest kept", got[0])
+	}
+	// OnWrite functions run for every write, dropped events included
+	if written != entityEventBuffer+10 {
+		t.Errorf("OnWrite ran %d times, want %d", written, entityEventBuffer+10)
+	}
+}
+
+func TestEntityEventsConcurrent(t *testing.T) {
+	events := NewEntityEvents()
+
+	// Publishing while subscribers come and go is race-free (run with -race)
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				events.Publish(EntityUpdated, &models.Entity{Name: fmt.Sprintf("e%d-%d", i, j), Labels: []string{"work"}})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				ch, unsubscribe := events.Subscribe([]string{"work"})
+				drain(ch)
+				unsubscribe()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestEntityEventsNil(t *testing.T) {
+	var events *EntityEvents
+	events.OnWrite(func() { t.Error("OnWrite function of nil events ran") })
+	events.Written()
+	events.Publish(EntityCreated, &models.Entity{Name: "a"})
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// FacetedResults are search results grouped by entity type, with counts per
+// label and source for filter facets.
+type FacetedResults struct {
+	Total   int
+	Groups  []TypeGroup  // Ordered by each type's best-ranked result
+	Labels  []FacetCount // Results per label, most common first
+	Sources []FacetCount // Results per source, most common first
+}
+
+// TypeGroup holds the results of one entity type in rank order.
+type TypeGroup struct {
+	Type    string
+	Results []models.EntitySearchResult
+}
+
+// FacetCount is the number of results with a facet value.
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+// SearchFaceted runs SearchWithChunks and groups the results by type.
+func (s *SearchService) SearchFaceted(ctx context.Context, opts SearchOptions) (*FacetedResults, error) {
+	results, err := s.SearchWithChunks(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return facetResults(results), nil
+}
+
+// facetResults groups ranked results by type and counts labels and sources
+// in a single pass.
+func facetResults(results []models.EntitySearchResult) *FacetedResults {
+	faceted := &FacetedResults{
+		Total:   len(results),
+		Groups:  []TypeGroup{},
+		Labels:  []FacetCount{},
+		Sources: []FacetCount{},
+	}
+
+	groupIdx := make(map[string]int)
+	labelCounts := make(map[string]int)
+	sourceCounts := make(map[string]int)
+	for _, r := range results {
+		i, ok := groupIdx[r.Type]
+		if !ok {
+			i = len(faceted.Groups)
+			groupIdx[r.Type] = i
+			faceted.Groups = append(faceted.Groups, TypeGroup{Type: r.Type})
+		}
+		faceted.Groups[i].Results = append(faceted.Groups[i].Results, r)
+
+		for _, label := range r.Labels {
+			labelCounts[label]++
+		}
+		sourceCounts[string(r.Source)]++
+	}
+
+	faceted.Labels = sortedFacets(labelCounts)
+	faceted.Sources = sortedFacets(sourceCounts)
+	return faceted
+}
+
+// sortedFacets orders counts by count descending, then value.
+func sortedFacets(counts map[string]int) []FacetCount {
+	facets := make([]FacetCount, 0, len(counts))
+	for value, count := range counts {
+		facets = append(facets, FacetCount{Value: value, Count: count})
+	}
+	slices.SortFunc(facets, func(a, b FacetCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Value, b.Value)
+	})
+	return facets
+}
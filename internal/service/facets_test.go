@@ -0,0 +1,88 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+func TestFacetResults(t *testing.T) {
+	result := func(name, typ string, source models.EntitySource, labels ...string) models.EntitySearchResult {
+		return models.EntitySearchResult{Entity: models.Entity{Name: name, Type: typ, Source: source, Labels: labels}}
+	}
+
+	tests := []struct {
+		name        string
+		results     []models.EntitySearchResult
+		wantGroups  map[string][]string // type → result names in rank order
+		wantOrder   []string            // group types
+		wantLabels  []FacetCount
+		wantSources []FacetCount
+	}{
+		{
+			name:        "empty",
+			wantGroups:  map[string][]string{},
+			wantLabels:  []FacetCount{},
+			wantSources: []FacetCount{},
+		},
+		{
+			name: "grouped by type in order of best result",
+			results: []models.EntitySearchResult{
+				result("runbook", "document", models.SourceScrape, "ops", "k8s"),
+				result("alice", "person", models.SourceManual, "team"),
+				result("deploy", "document", models.SourceScrape, "ops"),
+				result("bob", "person", models.SourceManual, "team", "ops"),
+				result("k8s", "concept", models.SourceAIGenerated),
+			},
+			wantGroups: map[string][]string{
+				"document": {"runbook", "deploy"},
+				"person":   {"alice", "bob"},
+				"concept":  {"k8s"},
+			},
+			wantOrder:   []string{"document", "person", "concept"},
+			wantLabels:  []FacetCount{{"ops", 3}, {"team", 2}, {"k8s", 1}},
+			wantSources: []FacetCount{{"manual", 2}, {"scrape", 2}, {"ai_generated", 1}},
+		},
+		{
+			name: "ties ordered by value",
+			results: []models.EntitySearchResult{
+				result("x", "note", models.SourceManual, "zeta", "alpha"),
+			},
+			wantGroups:  map[string][]string{"note": {"x"}},
+			wantOrder:   []string{"note"},
+			wantLabels:  []FacetCount{{"alpha", 1}, {"zeta", 1}},
+			wantSources: []FacetCount{{"manual", 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := facetResults(tt.results)
+
+			if got.Total != len(tt.results) {
+				t.Errorf("Total = %d, want %d", got.Total, len(tt.results))
+			}
+			var order []string
+			for _, group := range got.Groups {
+				order = append(order, group.Type)
+				var names []string
+				for _, r := range group.Results {
+					names = append(names, r.Name)
+				}
+				if !slices.Equal(names, tt.wantGroups[group.Type]) {
+					t.Errorf("group %s = %v, want %v", group.Type, names, tt.wantGroups[group.Type])
+				}
+			}
+			if !slices.Equal(order, tt.wantOrder) {
+				t.Errorf("group order = %v, want %v", order, tt.wantOrder)
+			}
+			if !slices.Equal(got.Labels, tt.wantLabels) {
+				t.Errorf("Labels = %v, want %v", got.Labels, tt.wantLabels)
+			}
+			if !slices.Equal(got.Sources, tt.wantSources) {
+				t.Errorf("Sources = %v, want %v", got.Sources, tt.wantSources)
+			}
+		})
+	}
+}
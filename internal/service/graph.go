@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/raphaelgruber/memcp-go/internal/db"
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// Path search limits. Traversal is done in Go over GetRelations, so depth is
+// capped and at most maxExploredPaths partial paths are queued; a search
+// hitting that cap returns the paths found so far.
+const (
+	defaultPathDepth = 4
+	maxPathDepth     = 6
+	defaultMaxPaths  = 10
+	maxExploredPaths = 10000
+)
+
+// Graph export limits. A rooted export traverses up to the given depth; a
+// full export includes at most maxExportEntities entities.
+const (
+	defaultExportDepth = 2
+	maxExportDepth     = 6
+	maxExportEntities  = 10000
+)
+
+// Graph analytics scan the whole graph, so results are cached briefly.
+const (
+	graphAnalyticsTTL  = time.Minute
+	graphAnalyticsTopN = 10
+)
+
+// GraphAnalytics returns graph-wide statistics, recomputed at most once per
+// graphAnalyticsTTL.
+func (s *EntityService) GraphAnalytics(ctx context.Context) (db.GraphAnalytics, error) {
+	s.analyticsMu.Lock()
+	defer s.analyticsMu.Unlock()
+
+	if s.analytics != nil && time.Since(s.analytics.ComputedAt) < graphAnalyticsTTL {
+		return *s.analytics, nil
+	}
+
+	analytics, err := s.db.GraphAnalytics(ctx, graphAnalyticsTopN)
+	if err != nil {
+		return db.GraphAnalytics{}, fmt.Errorf("graph analytics: %w", err)
+	}
+	s.analytics = &analytics
+	return analytics, nil
+}
+
+// FindAllPaths returns up to maxPaths distinct paths between two entities,
+// shortest first. Relations are traversed in both directions and no entity is
+// visited twice within a path. Each path is an ordered sequence of steps. In
+// dense graphs the search stops early (see maxExploredPaths), so fewer paths
+// may be returned.
+func (s *EntityService) FindAllPaths(ctx context.Context, fromID, toID string, maxDepth, maxPaths int) ([][]models.PathStep, error) {
+	if maxDepth <= 0 {
+		maxDepth = defaultPathDepth
+	}
+	if maxDepth > maxPathDepth {
+		maxDepth = maxPathDepth
+	}
+	if maxPaths <= 0 {
+		maxPaths = defaultMaxPaths
+	}
+	if fromID == toID {
+		return [][]models.PathStep{}, nil
+	}
+
+	// Cache adjacency so each entity's relations are fetched at most once
+	adjacency := make(map[string][]models.PathStep)
+	neighbors := func(id string) ([]models.PathStep, error) {
+		if steps, ok := adjacency[id]; ok {
+			return steps, nil
+		}
+		rels, err := s.db.GetRelations(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("get relations for %s: %w", id, err)
+		}
+		steps := make([]models.PathStep, 0, len(rels))
+		for _, rel := range rels {
+			inID, err := models.RecordIDString(rel.In)
+			if err != nil {
+				slog.Debug("skipping relation with invalid source", "entity", id, "error", err)
+				continue
+			}
+			outID, err := models.RecordIDString(rel.Out)
+			if err != nil {
+				slog.Debug("skipping relation with invalid target", "entity", id, "error", err)
+				continue
+			}
+			if inID == id {
+				steps = append(steps, models.PathStep{FromID: id, ToID: outID, RelType: rel.RelType})
+			} else {
+				steps = append(steps, models.PathStep{FromID: id, ToID: inID, RelType: rel.RelType, Reverse: true})
+			}
+		}
+		adjacency[id] = steps
+		return steps, nil
+	}
+
+	return findPaths(ctx, fromID, toID, maxDepth, maxPaths, maxExploredPaths, neighbors)
+}
+
+// findPaths searches paths from fromID to toID breadth-first, so shorter
+// paths are found first. At most maxExplored partial paths are queued; once
+// the cap is hit, no more are queued and the paths found so far are returned.
+func findPaths(ctx context.Context, fromID, toID string, maxDepth, maxPaths, maxExplored int, neighbors func(id string) ([]models.PathStep, error)) ([][]models.PathStep, error) {
+	// BFS over partial paths; each entry tracks the entities it has visited
+	type partial struct {
+		steps   []models.PathStep
+		visited map[string]bool
+	}
+	queue := []partial{{visited: map[string]bool{fromID: true}}}
+	paths := [][]models.PathStep{}
+	explored := 0
+
+	for len(queue) > 0 && len(paths) < maxPaths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		current := queue[0]
+		queue = queue[1:]
+
+		head := fromID
+		if len(current.steps) > 0 {
+			head = current.steps[len(current.steps)-1].ToID
+		}
+
+		next, err := neighbors(head)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, step := range next {
+			if current.visited[step.ToID] {
+				continue
+			}
+
+			steps := make([]models.PathStep, len(current.steps), len(current.steps)+1)
+			copy(steps, current.steps)
+			steps = append(steps, step)
+
+			if step.ToID == toID {
+				paths = append(paths, steps)
+				if len(paths) >= maxPaths {
+					break
+				}
+				continue
+			}
+			if len(steps) >= maxDepth {
+				continue
+			}
+			if explored >= maxExplored {
+				continue
+			}
+			explored++
+			if explored == maxExplored {
+				slog.Warn("path search hit the exploration cap", "from", fromID, "to", toID, "max_explored", maxExplored, "paths", len(paths))
+			}
+
+			visited := make(map[string]bool, len(current.visited)+1)
+			for id := range current.visited {
+				visited[id] = true
+			}
+			visited[step.ToID] = true
+			queue = append(queue, partial{steps: steps, visited: visited})
+		}
+	}
+
+	return paths, nil
+}
+
+// Neighbors returns the entities within depth hops of an entity (default 1),
+// nearest first, each with its shortest distance. With relTypes set, only
+// relations of those types are followed.
+func (s *EntityService) Neighbors(ctx context.Context, id string, depth int, relTypes []string) ([]db.Neighbor, error) {
+	entity, err := s.db.GetEntity(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get entity: %w", err)
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("entity not found: %s", id)
+	}
+	return s.db.GetNeighbors(ctx, id, depth, relTypes)
+}
+
+// GraphExport is a set of entities and the relations between them.
+type GraphExport struct {
+	Entities  []models.Entity
+	Relations []models.Relation
+}
+
+// ExportGraph exports the knowledge graph. With rootID set, only the
+// neighborhood reachable from the root within depth hops is exported,
+// following relations in both directions. Otherwise the whole graph is
+// exported (up to maxExportEntities entities).
+func (s *EntityService) ExportGraph(ctx context.Context, rootID string, depth int) (*GraphExport, error) {
+	if rootID == "" {
+		return s.exportFullGraph(ctx)
+	}
+
+	if depth <= 0 {
+		depth = defaultExportDepth
+	}
+	if depth > maxExportDepth {
+		depth = maxExportDepth
+	}
+
+	root, err := s.db.GetEntity(ctx, rootID)
+	if err != nil {
+		return nil, fmt.Errorf("get root entity: %w", err)
+	}
+	if root == nil {
+		return nil, fmt.Errorf("entity not found: %s", rootID)
+	}
+
+	// BFS from the root, one hop per level
+	visited := map[string]bool{rootID: true}
+	order := []string{rootID}
+	relations := []models.Relation{}
+	seenRelations := make(map[string]bool)
+	frontier := []string{rootID}
+
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		var next []string
+		for _, id := range frontier {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			rels, err := s.db.GetRelations(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("get relations for %s: %w", id, err)
+			}
+			for _, rel := range rels {
+				inID, err := models.RecordIDString(rel.In)
+				if err != nil {
+					slog.Debug("skipping relation with invalid source", "entity", id, "error", err)
+					continue
+				}
+				outID, err := models.RecordIDString(rel.Out)
+				if err != nil {
+					slog.Debug("skipping relation with invalid target", "entity", id, "error", err)
+					continue
+				}
+
+				relID := fmt.Sprintf("%v", rel.ID.ID)
+				if !seenRelations[relID] {
+					seenRelations[relID] = true
+					relations = append(relations, rel)
+				}
+
+				neighbor := outID
+				if outID == id {
+					neighbor = inID
+				}
+				if !visited[neighbor] {
+					visited[neighbor] = true
+					order = append(order, neighbor)
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	found, err := s.db.GetEntitiesByIDs(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &GraphExport{Entities: make([]models.Entity, 0, len(order))}
+	for _, id := range order {
+		if entity, ok := found[id]; ok {
+			export.Entities = append(export.Entities, *entity)
+		}
+	}
+
+	// Leave out relations to entities that no longer exist
+	export.Relations = make([]models.Relation, 0, len(relations))
+	for _, rel := range relations {
+		if _, ok := found[models.MustRecordIDString(rel.In)]; !ok {
+			continue
+		}
+		if _, ok := found[models.MustRecordIDString(rel.Out)]; !ok {
+			continue
+		}
+		export.Relations = append(export.Relations, rel)
+	}
+
+	slog.Info("exported subgraph", "root", rootID, "depth", depth, "entities", len(export.Entities), "relations", len(export.Relations))
+	return export, nil
+}
+
+// exportFullGraph exports all entities and the relations between them.
+func (s *EntityService) exportFullGraph(ctx context.Context) (*GraphExport, error) {
+	entities, err := s.db.ListEntities(ctx, db.ListOptions{Limit: maxExportEntities})
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == maxExportEntities {
+		slog.Warn("graph export truncated", "max_entities", maxExportEntities)
+	}
+
+	ids := make(map[string]bool, len(entities))
+	for _, entity := range entities {
+		ids[models.MustRecordIDString(entity.ID)] = true
+	}
+
+	all, err := s.db.ListRelations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	relations := make([]models.Relation, 0, len(all))
+	for _, rel := range all {
+		if ids[models.MustRecordIDString(rel.In)] && ids[models.MustRecordIDString(rel.Out)] {
+			relations = append(relations, rel)
+		}
+	}
+
+	return &GraphExport{Entities: entities, Relations: relations}, nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// testGraph returns a neighbors function over relations given as "from>to"
+// edges, traversable in both directions like FindAllPaths' adjacency.
+func testGraph(edges ...string) func(id string) ([]models.PathStep, error) {
+	adjacency := make(map[string][]models.PathStep)
+	for _, edge := range edges {
+		from, to, _ := strings.Cut(edge, ">")
+		adjacency[from] = append(adjacency[from], models.PathStep{FromID: from, ToID: to, RelType: "rel"})
+		adjacency[to] = append(adjacency[to], models.PathStep{FromID: to, ToID: from, RelType: "rel", Reverse: true})
+	}
+	return func(id string) ([]models.PathStep, error) {
+		return adjacency[id], nil
+	}
+}
+
+// pathString formats a path as "a>b<c", where "<" marks a reverse step.
+func pathString(steps []models.PathStep) string {
+	if len(steps) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(steps[0].FromID)
+	for _, step := range steps {
+		if step.Reverse {
+			b.WriteString("<")
+		} else {
+			b.WriteString(">")
+		}
+		b.WriteString(step.ToID)
+	}
+	return b.String()
+}
+
+func TestFindPaths(t *testing.T) {
+	tests := []struct {
+		name        string
+		edges       []string
+		from, to    string
+		maxDepth    int
+		maxPaths    int
+		maxExplored int
+		want        []string
+	}{
+		{
+			name:     "shortest first",
+			edges:    []string{"a>b", "b>c", "c>d", "a>d"},
+			from:     "a",
+			to:       "d",
+			maxDepth: 4, maxPaths: 10, maxExplored: 100,
+			want: []string{"a>d", "a>b>c>d"},
+		},
+		{
+			name:     "reverse steps",
+			edges:    []string{"b>a", "b>c"},
+			from:     "a",
+			to:       "c",
+			maxDepth: 4, maxPaths: 10, maxExplored: 100,
+			want: []string{"a<b>c"},
+		},
+		{
+			name:     "cycles visit no entity twice",
+			edges:    []string{"a>b", "b>c", "c>a", "c>d"},
+			from:     "a",
+			to:       "d",
+			maxDepth: 6, maxPaths: 10, maxExplored: 100,
+			want: []string{"a<c>d", "a>b>c>d"},
+		},
+		{
+			name:     "depth limit",
+			edges:    []string{"a>b", "b>c", "c>d"},
+			from:     "a",
+			to:       "d",
+			maxDepth: 2, maxPaths: 10, maxExplored: 100,
+			want: []string{},
+		},
+		{
+			name:     "max paths",
+			edges:    []string{"a>d", "a>b", "b>d", "a>c", "c>d"},
+			from:     "a",
+			to:       "d",
+			maxDepth: 4, maxPaths: 2, maxExplored: 100,
+			want: []string{"a>d", "a>b>d"},
+		},
+		{
+			name:     "exploration cap returns paths found so far",
+			edges:    []string{"a>b", "a>c", "b>x", "c>y", "x>d", "y>d"},
+			from:     "a",
+			to:       "d",
+			maxDepth: 4, maxPaths: 10, maxExplored: 2,
+			want: []string{},
+		},
+		{
+			name:     "exploration cap keeps queued paths",
+			edges:    []string{"a>b", "a>c", "b>d", "c>d"},
+			from:     "a",
+			to:       "d",
+			maxDepth: 4, maxPaths: 10, maxExplored: 1,
+			want: []string{"a>b>d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := findPaths(context.Background(), tt.from, tt.to, tt.maxDepth, tt.maxPaths, tt.maxExplored, testGraph(tt.edges...))
+			if err != nil {
+				t.Fatalf("findPaths() error = %v", err)
+			}
+			got := make([]string, len(paths))
+			for i, path := range paths {
+				got[i] = pathString(path)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findPaths() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPathsExplorationCap(t *testing.T) {
+	// A complete graph has a factorial number of simple paths; the cap bounds
+	// the partial paths queued regardless
+	var edges []string
+	for i := range 12 {
+		for j := i + 1; j < 12; j++ {
+			edges = append(edges, fmt.Sprintf("n%d>n%d", i, j))
+		}
+	}
+	graph := testGraph(edges...)
+	expanded := 0
+	neighbors := func(id string) ([]models.PathStep, error) {
+		expanded++
+		return graph(id)
+	}
+
+	const maxExplored = 50
+	if _, err := findPaths(context.Background(), "n0", "missing", 6, 10, maxExplored, neighbors); err != nil {
+		t.Fatalf("findPaths() error = %v", err)
+	}
+	// The start plus every queued partial is expanded at most once
+	if expanded > maxExplored+1 {
+		t.Errorf("expanded %d partial paths, want at most %d", expanded, maxExplored+1)
+	}
+}
+
+func TestFindPathsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := findPaths(ctx, "a", "b", 4, 10, 100, testGraph("a>b")); err == nil {
+		t.Error("findPaths() with cancelled context succeeded")
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// ImportReport summarizes a relation import.
+type ImportReport struct {
+	Rows       int          // Edge rows read (excluding header)
+	Created    int          // Relations created or updated
+	Unresolved []string     // Endpoint references that matched no entity
+	Skipped    []SkippedRow // Rows that were not imported
+}
+
+// SkippedRow is an edge list row that could not be imported.
+type SkippedRow struct {
+	Line   int
+	Reason string
+}
+
+// ImportRelations reads an edge list in CSV form (from,to,type[,strength])
+// and creates the relations. Endpoints are resolved by entity name
+// (case-insensitive), then ID, then slugified name. A leading header row
+// starting with "from,to" is skipped. Rows with unresolved endpoints or
+// invalid fields are reported in the result instead of failing the import.
+// Relations are written in batches; when a batch fails, the report counts
+// the relations written before it.
+func (s *EntityService) ImportRelations(ctx context.Context, r io.Reader) (ImportReport, error) {
+	var report ImportReport
+
+	type edge struct {
+		line          int
+		from, to, rel string
+		strength      *float64
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var edges []edge
+	refs := make(map[string]bool)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, fmt.Errorf("read edge list: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if report.Rows == 0 && len(record) >= 2 &&
+			strings.EqualFold(strings.TrimSpace(record[0]), "from") && strings.EqualFold(strings.TrimSpace(record[1]), "to") {
+			continue
+		}
+		report.Rows++
+
+		if len(record) < 3 {
+			report.Skipped = append(report.Skipped, SkippedRow{Line: line, Reason: "expected from,to,type[,strength]"})
+			continue
+		}
+		e := edge{
+			line: line,
+			from: strings.TrimSpace(record[0]),
+			to:   strings.TrimSpace(record[1]),
+			rel:  strings.TrimSpace(record[2]),
+		}
+		if e.from == "" || e.to == "" || e.rel == "" {
+			report.Skipped = append(report.Skipped, SkippedRow{Line: line, Reason: "from, to and type are required"})
+			continue
+		}
+		if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+			strength, err := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+			if err != nil || strength < 0 || strength > 1 {
+				report.Skipped = append(report.Skipped, SkippedRow{Line: line, Reason: fmt.Sprintf("invalid strength %q (want 0-1)", record[3])})
+				continue
+			}
+			e.strength = &strength
+		}
+
+		edges = append(edges, e)
+		refs[e.from] = true
+		refs[e.to] = true
+	}
+
+	resolved, err := s.resolveEntityRefs(ctx, refs)
+	if err != nil {
+		return report, err
+	}
+
+	unresolved := make(map[string]bool)
+	var inputs []models.RelationInput
+	for _, e := range edges {
+		fromID, fromOK := resolved[e.from]
+		toID, toOK := resolved[e.to]
+		if !fromOK || !toOK {
+			var missing []string
+			if !fromOK {
+				missing = append(missing, e.from)
+			}
+			if !toOK && e.to != e.from {
+				missing = append(missing, e.to)
+			}
+			for _, ref := range missing {
+				if !unresolved[ref] {
+					unresolved[ref] = true
+					report.Unresolved = append(report.Unresolved, ref)
+				}
+			}
+			report.Skipped = append(report.Skipped, SkippedRow{Line: e.line, Reason: "unresolved endpoint: " + strings.Join(missing, ", ")})
+			continue
+		}
+		inputs = append(inputs, models.RelationInput{
+			FromID:   fromID,
+			ToID:     toID,
+			RelType:  e.rel,
+			Strength: e.strength,
+		})
+	}
+
+	created, err := s.db.CreateRelations(ctx, inputs)
+	report.Created = created
+	if created > 0 {
+		s.events.Written()
+	}
+	if err != nil {
+		return report, fmt.Errorf("%d of %d relations created: %w", created, len(inputs), err)
+	}
+	return report, nil
+}
+
+// resolveEntityRefs maps entity references (names or IDs) to entity IDs.
+// References that match no entity are absent from the result.
+func (s *EntityService) resolveEntityRefs(ctx context.Context, refs map[string]bool) (map[string]string, error) {
+	names := make([]string, 0, len(refs))
+	for ref := range refs {
+		names = append(names, ref)
+	}
+
+	byName, err := s.db.GetEntitiesByNames(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("resolve entities: %w", err)
+	}
+
+	resolved := make(map[string]string, len(refs))
+	for _, ref := range names {
+		if entity, ok := byName[strings.ToLower(ref)]; ok {
+			resolved[ref] = models.MustRecordIDString(entity.ID)
+			continue
+		}
+		for _, id := range []string{ref, models.Slugify(ref)} {
+			entity, err := s.db.GetEntity(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("resolve entity %q: %w", ref, err)
+			}
+			if entity != nil {
+				resolved[ref] = models.MustRecordIDString(entity.ID)
+				break
+			}
+		}
+	}
+	return resolved, nil
+}
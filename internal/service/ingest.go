@@ -1,15 +1,19 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/raphaelgruber/memcp-go/internal/db"
 	"github.com/raphaelgruber/memcp-go/internal/llm"
@@ -23,15 +27,71 @@ type IngestService struct {
 	embedder      *llm.Embedder
 	model         *llm.Model
 	entityService *EntityService
+
+	// extractSem bounds concurrent LLM graph extractions across all jobs
+	// (nil = unbounded).
+	extractSem chan struct{}
+
+	// fuzzyThreshold is the name similarity a fuzzy match needs to stand in
+	// for a relation target that isn't found by exact name (0 = disabled).
+	fuzzyThreshold float64
+
+	// maxFileBytes is the size above which files are skipped unless an ingest
+	// sets its own limit (0 = no limit).
+	maxFileBytes int64
+
+	// skipBinaryCheck ingests content without checking it is valid text.
+	skipBinaryCheck bool
+
+	// httpClient fetches pages for IngestURL.
+	httpClient *http.Client
 }
 
+// ErrFileTooLarge is returned for files above the ingest's size limit.
+var ErrFileTooLarge = errors.New("file too large")
+
+// ErrNotText is returned for files that look binary: invalid UTF-8 or
+// mostly control characters.
+var ErrNotText = errors.New("not valid text")
+
 // NewIngestService creates a new ingest service.
-func NewIngestService(db *db.Client, embedder *llm.Embedder, model *llm.Model) *IngestService {
-	return &IngestService{
-		db:            db,
-		embedder:      embedder,
-		model:         model,
-		entityService: NewEntityService(db, embedder, model),
+// Entity changes are published on events, which may be nil. contentLimit,
+// embedTextLimit, labels, detectLanguage and confidence are passed to the
+// entity service (see
+// NewEntityService). At most maxExtractions graph extractions run at once,
+// regardless of which job they belong to (0 = unlimited). Relation targets
+// missing by exact name link to the closest entity name whose similarity
+// reaches fuzzyThreshold (0 = never). Files larger than maxFileBytes are
+// skipped unless an ingest sets its own limit (0 = no limit). Files that
+// don't look like text fail with ErrNotText unless skipBinaryCheck is set.
+func NewIngestService(db *db.Client, embedder *llm.Embedder, model *llm.Model, events *EntityEvents, contentLimit, embedTextLimit int, labels *LabelNormalizer, detectLanguage bool, confidence ConfidenceDefaults, maxExtractions int, fuzzyThreshold float64, maxFileBytes int64, skipBinaryCheck bool) *IngestService {
+	s := &IngestService{
+		db:              db,
+		embedder:        embedder,
+		model:           model,
+		entityService:   NewEntityService(db, embedder, model, events, contentLimit, embedTextLimit, labels, detectLanguage, confidence),
+		fuzzyThreshold:  fuzzyThreshold,
+		maxFileBytes:    maxFileBytes,
+		skipBinaryCheck: skipBinaryCheck,
+		httpClient:      newURLClient(),
+	}
+	if maxExtractions > 0 {
+		s.extractSem = make(chan struct{}, maxExtractions)
+	}
+	return s
+}
+
+// acquireExtraction blocks until a graph extraction slot is free or ctx is
+// done. The returned func releases the slot.
+func (s *IngestService) acquireExtraction(ctx context.Context) (func(), error) {
+	if s.extractSem == nil {
+		return func() {}, nil
+	}
+	select {
+	case s.extractSem <- struct{}{}:
+		return func() { <-s.extractSem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
 }
 
@@ -43,16 +103,181 @@ type IngestOptions struct {
 	Labels []string
 	// ExtractGraph uses LLM to extract entity relationships
 	ExtractGraph bool
+	// AutoSummarize uses LLM to generate a summary for long content without one
+	AutoSummarize bool
 	// DryRun previews what would be ingested without making changes
 	DryRun bool
 	// Recursive processes subdirectories
 	Recursive bool
-	// Concurrency sets number of parallel workers (default 4)
+	// Concurrency sets number of parallel workers (default 4, or the job
+	// manager's for background jobs; capped at maxIngestConcurrency)
 	Concurrency int
 	// Job for progress reporting (optional, set by async ingestion)
 	Job *Job
 	// BaseDir is used to compute unique entity IDs (e.g., "insights" from ~/.claude/insights)
 	BaseDir string
+	// DefaultType is the entity type for files without a frontmatter type (default "document")
+	DefaultType string
+	// ConfigDir is the ingest root for .knowhow.yaml lookup when reading files from disk (empty disables)
+	ConfigDir string
+	// FailFast stops all workers and fails the job on the first file error
+	// instead of collecting errors and continuing
+	FailFast bool
+	// Prune deletes scraped entities ingested from the directory whose source
+	// file was not found by the walk and no longer exists (directory ingests only)
+	Prune bool
+	// ChunkStrategy selects how long content is split (empty = heading)
+	ChunkStrategy parser.ChunkStrategy
+	// ChunkSize is the chunk size in characters (0 = default)
+	ChunkSize int
+	// ChunkOverlap is the number of characters repeated between neighboring
+	// chunks (nil = default)
+	ChunkOverlap *int
+	// MaxFileBytes skips files larger than this many bytes (0 = server
+	// default, negative = no limit)
+	MaxFileBytes int64
+}
+
+// fileSizeLimit returns the file size limit of an ingest (0 = no limit).
+func (s *IngestService) fileSizeLimit(opts IngestOptions) int64 {
+	switch {
+	case opts.MaxFileBytes < 0:
+		return 0
+	case opts.MaxFileBytes > 0:
+		return opts.MaxFileBytes
+	default:
+		return max(s.maxFileBytes, 0)
+	}
+}
+
+// checkFileSize returns an error wrapping ErrFileTooLarge if size exceeds
+// limit (0 = no limit).
+func checkFileSize(size, limit int64) error {
+	if limit > 0 && size > limit {
+		return fmt.Errorf("%w: %d bytes, limit is %d", ErrFileTooLarge, size, limit)
+	}
+	return nil
+}
+
+// textSniffBytes is the prefix of a file checkText looks at.
+const textSniffBytes = 8192
+
+// maxControlRatio is the share of control characters from which content
+// counts as binary. Text has next to none besides tabs and line breaks.
+const maxControlRatio = 0.1
+
+// checkText returns an error wrapping ErrNotText if the start of content
+// contains NUL bytes, invalid UTF-8 or more than maxControlRatio control
+// characters. Only a prefix is checked, so it's cheap for large files.
+func checkText(content []byte) error {
+	prefix := content[:min(len(content), textSniffBytes)]
+	if len(content) > textSniffBytes {
+		// Don't count a rune cut off at the end of the prefix as invalid
+		for i := len(prefix) - 1; i >= 0 && i >= len(prefix)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(prefix[i]) {
+				if !utf8.FullRune(prefix[i:]) {
+					prefix = prefix[:i]
+				}
+				break
+			}
+		}
+	}
+	if bytes.IndexByte(prefix, 0) >= 0 {
+		return fmt.Errorf("%w: contains NUL bytes", ErrNotText)
+	}
+	if !utf8.Valid(prefix) {
+		return fmt.Errorf("%w: invalid UTF-8", ErrNotText)
+	}
+
+	control, total := 0, 0
+	for _, r := range string(prefix) {
+		total++
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' && r != '\f' {
+			control++
+		}
+	}
+	if total > 0 && float64(control)/float64(total) > maxControlRatio {
+		return fmt.Errorf("%w: %d of %d characters are control characters", ErrNotText, control, total)
+	}
+	return nil
+}
+
+// maxIngestConcurrency caps the workers an ingest can ask for.
+const maxIngestConcurrency = 32
+
+// concurrency returns the number of workers of the ingest: Concurrency
+// capped at maxIngestConcurrency, or fallback if unset.
+func (o IngestOptions) concurrency(fallback int) int {
+	if o.Concurrency <= 0 {
+		return fallback
+	}
+	return min(o.Concurrency, maxIngestConcurrency)
+}
+
+// chunkOptions returns the chunking overrides of the ingest. ChunkSize sets
+// both the target and the maximum chunk size.
+func (o IngestOptions) chunkOptions() ChunkOptions {
+	opts := ChunkOptions{Strategy: o.ChunkStrategy, Overlap: o.ChunkOverlap}
+	if o.ChunkSize > 0 {
+		opts.TargetSize = o.ChunkSize
+		opts.MaxSize = o.ChunkSize
+		opts.MinSize = min(parser.DefaultChunkConfig().MinSize, o.ChunkSize)
+	}
+	return opts
+}
+
+// validateChunking checks the chunking overrides before any file is ingested.
+func (o IngestOptions) validateChunking() error {
+	if o.ChunkSize < 0 {
+		return fmt.Errorf("chunk size must not be negative")
+	}
+	if _, err := o.chunkOptions().apply(parser.DefaultChunkConfig()); err != nil {
+		return fmt.Errorf("chunk options: %w", err)
+	}
+	return nil
+}
+
+// persistChunking adds the chunking overrides to persisted job options.
+func (o IngestOptions) persistChunking(persistOpts map[string]any) {
+	if o.ChunkStrategy != "" {
+		persistOpts["chunk_strategy"] = string(o.ChunkStrategy)
+	}
+	if o.ChunkSize > 0 {
+		persistOpts["chunk_size"] = o.ChunkSize
+	}
+	if o.ChunkOverlap != nil {
+		persistOpts["chunk_overlap"] = *o.ChunkOverlap
+	}
+}
+
+// chunkingFromRecord restores chunking overrides saved by persistChunking.
+func (o *IngestOptions) chunkingFromRecord(persistOpts map[string]any) {
+	if strategy, ok := persistOpts["chunk_strategy"].(string); ok {
+		o.ChunkStrategy = parser.ChunkStrategy(strategy)
+	}
+	o.ChunkSize = recordInt(persistOpts, "chunk_size")
+	if _, ok := persistOpts["chunk_overlap"]; ok {
+		overlap := recordInt(persistOpts, "chunk_overlap")
+		o.ChunkOverlap = &overlap
+	}
+}
+
+// withDirConfig applies .knowhow.yaml defaults to the options. Explicit options
+// take precedence: labels are combined and enabled LLM features stay enabled.
+func (o IngestOptions) withDirConfig(cfg parser.DirConfig) IngestOptions {
+	if o.DefaultType == "" {
+		o.DefaultType = cfg.Type
+	}
+	if len(cfg.Labels) > 0 {
+		o.Labels = append(append([]string{}, cfg.Labels...), o.Labels...)
+	}
+	if cfg.ExtractGraph != nil && !o.ExtractGraph {
+		o.ExtractGraph = *cfg.ExtractGraph
+	}
+	if cfg.AutoSummarize != nil && !o.AutoSummarize {
+		o.AutoSummarize = *cfg.AutoSummarize
+	}
+	return o
 }
 
 // IngestResult summarizes an ingestion operation.
@@ -61,8 +286,11 @@ type IngestResult struct {
 	FilesSkipped     int
 	EntitiesCreated  int
 	ChunksCreated    int
+	ChunksFailed     int // Chunks not stored because embedding failed; their files are in Errors
 	RelationsCreated int
+	EntitiesDeleted  int // Entities of removed files pruned (or that would be, on a dry run)
 	Errors           []string
+	Changes          []FileAction // Dry runs only: whether each file would create, update or leave its entity
 }
 
 // FileHash represents a file path and its content hash.
@@ -76,6 +304,7 @@ type FileContent struct {
 	Path    string
 	Content string
 	Hash    string
+	Config  parser.DirConfig // .knowhow.yaml defaults resolved by the client
 }
 
 // IngestFilesInput contains files and metadata for content-based ingestion.
@@ -88,6 +317,7 @@ type IngestFilesInput struct {
 type IngestFileResult struct {
 	Entity        *models.Entity
 	ChunksCreated int
+	ChunksFailed  int
 }
 
 // CheckHashes determines which files need uploading based on their content hashes.
@@ -132,23 +362,48 @@ func (s *IngestService) CheckHashes(ctx context.Context, files []FileHash) ([]st
 // Used by the two-phase hash-based ingestion flow.
 // baseDir is used to compute unique entity IDs from relative file paths.
 func (s *IngestService) IngestFileWithContent(ctx context.Context, filePath, content, contentHash, baseDir string, opts IngestOptions) (*IngestFileResult, error) {
-	return s.ingestFileInternal(ctx, filePath, []byte(content), &contentHash, baseDir, opts)
+	if err := checkFileSize(int64(len(content)), s.fileSizeLimit(opts)); err != nil {
+		return nil, err
+	}
+	return s.ingestFileInternal(ctx, filePath, []byte(content), &contentHash, fileEntityID(baseDir, filePath), opts)
 }
 
 // IngestFile ingests a single Markdown file.
 func (s *IngestService) IngestFile(ctx context.Context, filePath string, opts IngestOptions) (*IngestFileResult, error) {
+	// Check the size before reading, the file may be huge
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	if err := checkFileSize(info.Size(), s.fileSizeLimit(opts)); err != nil {
+		return nil, err
+	}
+
 	// Read file
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("read file: %w", err)
 	}
-	return s.ingestFileInternal(ctx, filePath, content, nil, opts.BaseDir, opts)
+	if opts.ConfigDir != "" {
+		cfg, err := parser.ResolveDirConfig(opts.ConfigDir, filePath)
+		if err != nil {
+			return nil, fmt.Errorf("load directory config: %w", err)
+		}
+		opts = opts.withDirConfig(cfg)
+	}
+	return s.ingestFileInternal(ctx, filePath, content, nil, fileEntityID(opts.BaseDir, filePath), opts)
 }
 
-// ingestFileInternal handles the core ingestion logic for both IngestFile and IngestFileWithContent.
+// ingestFileInternal handles the core ingestion logic for IngestFile, IngestFileWithContent and IngestURL.
 // If contentHash is nil, no hash is stored; if provided, it's stored for skip-unchanged deduplication.
-// baseDir is used to compute unique entity IDs: baseDir + filename (without ext). If empty, uses name.
-func (s *IngestService) ingestFileInternal(ctx context.Context, filePath string, content []byte, contentHash *string, baseDir string, opts IngestOptions) (*IngestFileResult, error) {
+// entityID is the ID of the entity to create or update (see fileEntityID). If nil, it's derived from the name.
+func (s *IngestService) ingestFileInternal(ctx context.Context, filePath string, content []byte, contentHash *string, entityID *string, opts IngestOptions) (*IngestFileResult, error) {
+	if !s.skipBinaryCheck {
+		if err := checkText(content); err != nil {
+			return nil, err
+		}
+	}
+
 	// Parse markdown
 	doc, err := parser.ParseMarkdown(string(content))
 	if err != nil {
@@ -157,6 +412,9 @@ func (s *IngestService) ingestFileInternal(ctx context.Context, filePath string,
 
 	// Determine entity type from frontmatter or default
 	entityType := doc.GetFrontmatterString("type")
+	if entityType == "" {
+		entityType = opts.DefaultType
+	}
 	if entityType == "" {
 		entityType = "document"
 	}
@@ -167,15 +425,6 @@ func (s *IngestService) ingestFileInternal(ctx context.Context, filePath string,
 		name = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
 	}
 
-	// Compute entity ID from baseDir + filename for uniqueness
-	// e.g., baseDir="insights", filePath=".../2026-02-04-tests-abc.md" → ID="insights-2026-02-04-tests-abc"
-	var entityID *string
-	if baseDir != "" {
-		filename := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
-		id := slugify(baseDir + "-" + filename)
-		entityID = &id
-	}
-
 	// Merge labels from frontmatter and options
 	labels := doc.GetFrontmatterStringSlice("labels")
 	if labels == nil {
@@ -218,6 +467,7 @@ func (s *IngestService) ingestFileInternal(ctx context.Context, filePath string,
 				Type:        input.Type,
 				Name:        name,
 				Content:     &fullContent,
+				Summary:     input.Summary,
 				Labels:      labels,
 				SourcePath:  &filePath,
 				ContentHash: contentHash,
@@ -226,8 +476,23 @@ func (s *IngestService) ingestFileInternal(ctx context.Context, filePath string,
 		}, nil
 	}
 
+	// Generate summary using LLM if requested and none was provided
+	if opts.AutoSummarize && input.Summary == nil && len(fullContent) >= autoSummarizeMinLength {
+		if s.model == nil {
+			slog.Warn("auto-summarize requested but LLM is disabled, skipping", "file", filePath)
+		} else if summary, err := s.model.Summarize(ctx, fullContent); err != nil {
+			// Fatal API errors (billing, auth) should stop everything
+			if errors.Is(err, llm.ErrFatalAPI) {
+				return nil, fmt.Errorf("auto-summarize: %w", err)
+			}
+			slog.Warn("auto-summarize failed", "file", filePath, "error", err)
+		} else if summary != "" {
+			input.Summary = &summary
+		}
+	}
+
 	// Create entity
-	createResult, err := s.entityService.Create(ctx, input)
+	createResult, err := s.entityService.CreateWithChunking(ctx, input, opts.chunkOptions())
 	if err != nil {
 		return nil, fmt.Errorf("create entity: %w", err)
 	}
@@ -235,7 +500,7 @@ func (s *IngestService) ingestFileInternal(ctx context.Context, filePath string,
 	// Extract relations from content
 	relations := s.extractInferredRelations(ctx, doc, createResult.Entity)
 	for _, rel := range relations {
-		if err := s.db.CreateRelation(ctx, rel); err != nil {
+		if err := s.entityService.CreateRelation(ctx, rel); err != nil {
 			// Log but don't fail
 			slog.Warn("failed to create inferred relation", "from", rel.FromID, "to", rel.ToID, "error", err)
 		}
@@ -255,12 +520,58 @@ func (s *IngestService) ingestFileInternal(ctx context.Context, filePath string,
 		}
 	}
 
+	// Chunks are missing - forget the hash so the next ingest retries the file
+	if createResult.ChunksFailed > 0 && contentHash != nil {
+		if id, err := models.RecordIDString(createResult.Entity.ID); err != nil {
+			slog.Warn("failed to get entity ID to clear content hash", "file", filePath, "error", err)
+		} else if err := s.db.ClearContentHash(ctx, id); err != nil {
+			slog.Warn("failed to clear content hash", "entity", id, "error", err)
+		}
+	}
+
 	return &IngestFileResult{
 		Entity:        createResult.Entity,
 		ChunksCreated: createResult.ChunksCreated,
+		ChunksFailed:  createResult.ChunksFailed,
 	}, nil
 }
 
+// fileEntityID computes the entity ID for a file from baseDir + filename for
+// uniqueness, e.g. baseDir="insights", filePath=".../2026-02-04-tests-abc.md"
+// → ID="insights-2026-02-04-tests-abc". Returns nil if baseDir is empty.
+func fileEntityID(baseDir, filePath string) *string {
+	if baseDir == "" {
+		return nil
+	}
+	filename := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	id := slugify(baseDir + "-" + filename)
+	return &id
+}
+
+// fuzzyNameMaxDistance bounds the edits between a relation target name and
+// the fuzzy match standing in for it.
+const fuzzyNameMaxDistance = 3
+
+// findEntityFuzzy returns the entity whose name is closest to name if its
+// similarity reaches the fuzzy threshold, or nil. Used when an exact name
+// lookup for a relation target misses, e.g. on typos or plural forms.
+func (s *IngestService) findEntityFuzzy(ctx context.Context, name string) *models.Entity {
+	if s.fuzzyThreshold <= 0 {
+		return nil
+	}
+	matches, err := s.db.FindEntitiesByNameFuzzy(ctx, name, fuzzyNameMaxDistance)
+	if err != nil {
+		slog.Warn("failed to find entity by fuzzy name", "name", name, "error", err)
+		return nil
+	}
+	if len(matches) == 0 || matches[0].Score < s.fuzzyThreshold {
+		return nil
+	}
+	best := matches[0]
+	slog.Info("resolved entity name by fuzzy match", "name", name, "matched", best.Name, "score", best.Score)
+	return &best.Entity
+}
+
 // extractInferredRelations finds [[wiki-links]] and @mentions.
 func (s *IngestService) extractInferredRelations(ctx context.Context, doc *parser.MarkdownDoc, entity *models.Entity) []models.RelationInput {
 	var relations []models.RelationInput
@@ -297,6 +608,9 @@ func (s *IngestService) extractInferredRelations(ctx context.Context, doc *parse
 	// Process wiki links
 	for _, link := range links {
 		target := entityMap[strings.ToLower(link)]
+		if target == nil {
+			target = s.findEntityFuzzy(ctx, link)
+		}
 		if target == nil {
 			continue
 		}
@@ -316,6 +630,9 @@ func (s *IngestService) extractInferredRelations(ctx context.Context, doc *parse
 	// Process mentions (only person entities)
 	for _, mention := range mentions {
 		target := entityMap[strings.ToLower(mention)]
+		if target == nil {
+			target = s.findEntityFuzzy(ctx, mention)
+		}
 		if target == nil || target.Type != "person" {
 			continue
 		}
@@ -335,6 +652,9 @@ func (s *IngestService) extractInferredRelations(ctx context.Context, doc *parse
 	// Process frontmatter relates_to
 	for _, targetName := range relatesTo {
 		target := entityMap[strings.ToLower(targetName)]
+		if target == nil {
+			target = s.findEntityFuzzy(ctx, targetName)
+		}
 		if target == nil {
 			continue
 		}
@@ -365,11 +685,17 @@ func (s *IngestService) extractGraphRelations(ctx context.Context, entity *model
 		return fmt.Errorf("get entity ID: %w", err)
 	}
 
+	release, err := s.acquireExtraction(ctx)
+	if err != nil {
+		return fmt.Errorf("wait for extraction slot: %w", err)
+	}
+	defer release()
+
 	contentLen := len(*entity.Content)
 	slog.Debug("starting graph extraction", "entity", entity.Name, "content_len", contentLen)
 
 	// Get existing entity names for context
-	existingEntities, err := s.db.ListEntities(ctx, "", nil, 100)
+	existingEntities, err := s.db.ListEntities(ctx, db.ListOptions{Limit: 100})
 	if err != nil {
 		slog.Warn("failed to list entities for graph context", "error", err)
 		// Continue with empty list - LLM can still extract new entities
@@ -405,15 +731,13 @@ func (s *IngestService) extractGraphRelations(ctx context.Context, entity *model
 				description := strings.TrimSpace(parts[3])
 				aiSource := models.SourceAIGenerated
 				verified := false
-				confidence := 0.7
 
 				_, err := s.entityService.Create(ctx, models.EntityInput{
-					Type:       entityType,
-					Name:       name,
-					Summary:    &description,
-					Source:     &aiSource,
-					Verified:   &verified,
-					Confidence: &confidence,
+					Type:     entityType,
+					Name:     name,
+					Summary:  &description,
+					Source:   &aiSource,
+					Verified: &verified,
 				})
 				if err != nil {
 					// Race condition: entity may have been created by another worker
@@ -438,11 +762,17 @@ func (s *IngestService) extractGraphRelations(ctx context.Context, entity *model
 				slog.Debug("failed to lookup source entity for relation", "source", sourceName, "error", err)
 				continue
 			}
+			if sourceEntity == nil {
+				sourceEntity = s.findEntityFuzzy(ctx, sourceName)
+			}
 			targetEntity, err := s.db.GetEntityByName(ctx, targetName)
 			if err != nil {
 				slog.Debug("failed to lookup target entity for relation", "target", targetName, "error", err)
 				continue
 			}
+			if targetEntity == nil {
+				targetEntity = s.findEntityFuzzy(ctx, targetName)
+			}
 
 			if sourceEntity != nil && targetEntity != nil {
 				sourceID, srcErr := models.RecordIDString(sourceEntity.ID)
@@ -450,7 +780,7 @@ func (s *IngestService) extractGraphRelations(ctx context.Context, entity *model
 				if srcErr == nil && tgtErr == nil {
 					relSource := string(models.RelationSourceAIDetected)
 
-					err := s.db.CreateRelation(ctx, models.RelationInput{
+					err := s.entityService.CreateRelation(ctx, models.RelationInput{
 						FromID:  sourceID,
 						ToID:    targetID,
 						RelType: relType,
@@ -479,7 +809,7 @@ func (s *IngestService) extractGraphRelations(ctx context.Context, entity *model
 				}
 				relSource := string(models.RelationSourceAIDetected)
 
-				if err := s.db.CreateRelation(ctx, models.RelationInput{
+				if err := s.entityService.CreateRelation(ctx, models.RelationInput{
 					FromID:  entityID,
 					ToID:    targetID,
 					RelType: "mentions",
@@ -494,9 +824,10 @@ func (s *IngestService) extractGraphRelations(ctx context.Context, entity *model
 	return nil
 }
 
-// CollectFiles walks a directory and returns all markdown files.
-func (s *IngestService) CollectFiles(dirPath string, recursive bool) ([]string, error) {
-	var files []string
+// CollectFiles walks a directory and returns all markdown files up to
+// maxBytes in size (0 = no limit). Larger files are left out without being
+// read; skipped holds a "path: file too large" message for each.
+func (s *IngestService) CollectFiles(dirPath string, recursive bool, maxBytes int64) (files, skipped []string, err error) {
 	walkFn := func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -505,27 +836,57 @@ func (s *IngestService) CollectFiles(dirPath string, recursive bool) ([]string,
 			return filepath.SkipDir
 		}
 		ext := strings.ToLower(filepath.Ext(path))
-		if !d.IsDir() && (ext == ".md" || ext == ".markdown") {
-			files = append(files, path)
+		if d.IsDir() || (ext != ".md" && ext != ".markdown") {
+			return nil
 		}
+		if maxBytes > 0 {
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			if err := checkFileSize(info.Size(), maxBytes); err != nil {
+				slog.Warn("skipping file", "file", path, "error", err)
+				skipped = append(skipped, fmt.Sprintf("%s: %v", path, err))
+				return nil
+			}
+		}
+		files = append(files, path)
 		return nil
 	}
 
 	if err := filepath.WalkDir(dirPath, walkFn); err != nil {
-		return nil, fmt.Errorf("scan directory: %w", err)
+		return nil, nil, fmt.Errorf("scan directory: %w", err)
 	}
-	return files, nil
+	return files, skipped, nil
 }
 
 // IngestDirectory ingests all Markdown files from a directory (synchronous).
 func (s *IngestService) IngestDirectory(ctx context.Context, dirPath string, opts IngestOptions) (*IngestResult, error) {
-	files, err := s.CollectFiles(dirPath, opts.Recursive)
+	if err := opts.validateChunking(); err != nil {
+		return nil, err
+	}
+	files, skipped, err := s.CollectFiles(dirPath, opts.Recursive, s.fileSizeLimit(opts))
 	if err != nil {
 		return nil, err
 	}
 	// Compute baseDir from directory path for unique entity IDs
 	opts.BaseDir = filepath.Base(filepath.Clean(dirPath))
-	return s.processFilesInternal(ctx, nil, nil, files, len(files), opts)
+	opts.ConfigDir = dirPath
+	result, err := s.processFilesInternal(ctx, nil, nil, files, len(files), opts)
+	if err != nil {
+		return nil, err
+	}
+	result.FilesSkipped += len(skipped)
+	result.Errors = append(skipped, result.Errors...)
+	if opts.DryRun {
+		diff, err := s.DiffIngest(ctx, dirPath, opts)
+		if err != nil {
+			return nil, fmt.Errorf("classify files: %w", err)
+		}
+		result.Changes = diff.Actions()
+	}
+	s.pruneAfterIngest(ctx, dirPath, files, opts, result)
+	return result, nil
 }
 
 // IngestFilesWithContent ingests multiple files with provided content (not from disk).
@@ -535,20 +896,21 @@ func (s *IngestService) IngestFilesWithContent(ctx context.Context, files []File
 	if len(files) == 0 {
 		return &IngestResult{}, nil
 	}
+	if err := opts.validateChunking(); err != nil {
+		return nil, err
+	}
 
 	slog.Info("starting content-based file processing", "files", len(files), "base_dir", baseDir, "extract_graph", opts.ExtractGraph)
 
 	// Set default concurrency
-	concurrency := opts.Concurrency
-	if concurrency <= 0 {
-		concurrency = 4
-	}
+	concurrency := opts.concurrency(4)
 
 	// Result aggregation with thread-safe counters
 	var (
 		filesProcessed  atomic.Int32
 		entitiesCreated atomic.Int32
 		chunksCreated   atomic.Int32
+		chunksFailed    atomic.Int32
 		errorsMu        sync.Mutex
 		errs            []string
 	)
@@ -559,12 +921,19 @@ func (s *IngestService) IngestFilesWithContent(ctx context.Context, files []File
 		content string
 		hash    string
 		baseDir string
+		config  parser.DirConfig
 	}
 	workChan := make(chan workItem, len(files))
 	var wg sync.WaitGroup
 	var fatalOnce sync.Once
 	fatalCh := make(chan struct{}) // closed on fatal API error to abort all workers
 
+	// With FailFast, the first file error cancels the remaining work
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var failOnce sync.Once
+	var failErr error
+
 	// Start workers
 	for i := 0; i < concurrency; i++ {
 		wg.Add(1)
@@ -583,11 +952,17 @@ func (s *IngestService) IngestFilesWithContent(ctx context.Context, files []File
 				processed := filesProcessed.Add(1)
 				slog.Info("processing file", "worker", workerID, "file", filepath.Base(item.path), "progress", fmt.Sprintf("%d/%d", processed, len(files)))
 
-				result, err := s.IngestFileWithContent(ctx, item.path, item.content, item.hash, item.baseDir, opts)
+				result, err := s.IngestFileWithContent(ctx, item.path, item.content, item.hash, item.baseDir, opts.withDirConfig(item.config))
 				if err != nil {
 					if errors.Is(err, llm.ErrFatalAPI) {
 						fatalOnce.Do(func() { close(fatalCh) })
 					}
+					if opts.FailFast {
+						failOnce.Do(func() {
+							failErr = fmt.Errorf("%s: %w", item.path, err)
+							cancel()
+						})
+					}
 					errorsMu.Lock()
 					errs = append(errs, fmt.Sprintf("%s: %v", item.path, err))
 					errorsMu.Unlock()
@@ -600,27 +975,46 @@ func (s *IngestService) IngestFilesWithContent(ctx context.Context, files []File
 				if result != nil && !opts.DryRun {
 					chunksCreated.Add(int32(result.ChunksCreated))
 				}
+				if result != nil && result.ChunksFailed > 0 {
+					chunksFailed.Add(int32(result.ChunksFailed))
+					errorsMu.Lock()
+					errs = append(errs, fmt.Sprintf("%s: %d chunk embeddings failed, re-ingest to retry", item.path, result.ChunksFailed))
+					errorsMu.Unlock()
+				}
 			}
 		}(i)
 	}
 
 	// Send files to workers
 	for _, f := range files {
-		workChan <- workItem{path: f.Path, content: f.Content, hash: f.Hash, baseDir: baseDir}
+		workChan <- workItem{path: f.Path, content: f.Content, hash: f.Hash, baseDir: baseDir, config: f.Config}
 	}
 	close(workChan)
 
 	// Wait for completion
 	wg.Wait()
 
+	if failErr != nil {
+		return nil, failErr
+	}
+
 	slog.Info("content-based processing complete", "entities", entitiesCreated.Load(), "chunks", chunksCreated.Load(), "errors", len(errs))
 
-	return &IngestResult{
+	result := &IngestResult{
 		FilesProcessed:  int(filesProcessed.Load()),
 		EntitiesCreated: int(entitiesCreated.Load()),
 		ChunksCreated:   int(chunksCreated.Load()),
+		ChunksFailed:    int(chunksFailed.Load()),
 		Errors:          errs,
-	}, nil
+	}
+	if opts.DryRun {
+		diff, err := s.DiffFilesWithContent(ctx, files, baseDir, opts)
+		if err != nil {
+			return nil, fmt.Errorf("classify files: %w", err)
+		}
+		result.Changes = diff.Actions()
+	}
+	return result, nil
 }
 
 // ProcessFiles processes a list of files with job manager integration.
@@ -635,19 +1029,18 @@ func (s *IngestService) processFilesInternal(ctx context.Context, jobManager *Jo
 	slog.Info("starting file processing", "files", len(files), "total", totalFiles, "concurrency", opts.Concurrency, "extract_graph", opts.ExtractGraph)
 
 	// Set default concurrency
-	concurrency := opts.Concurrency
-	if concurrency <= 0 {
-		concurrency = 4
-	}
+	concurrency := opts.concurrency(4)
 
 	// Calculate starting progress (for resumed jobs)
 	startProgress := totalFiles - len(files)
 
 	// Result aggregation with thread-safe counters
 	var (
-		filesProcessed  atomic.Int32
+		filesProcessed  atomic.Int32 // Files started
+		filesCompleted  atomic.Int32 // Files done, reported as job progress
 		entitiesCreated atomic.Int32
 		chunksCreated   atomic.Int32
+		chunksFailed    atomic.Int32
 		errorsMu        sync.Mutex
 		errs            []string
 	)
@@ -658,11 +1051,24 @@ func (s *IngestService) processFilesInternal(ctx context.Context, jobManager *Jo
 	var fatalOnce sync.Once
 	fatalCh := make(chan struct{}) // closed on fatal API error to abort all workers
 
+	// With FailFast, the first file error cancels the remaining work
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var failOnce sync.Once
+	var failErr error
+
 	// Start workers
 	for i := 0; i < concurrency; i++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
+			// finish counts a file as done, with or without error
+			finish := func() {
+				done := filesCompleted.Add(1)
+				if jobManager != nil && job != nil {
+					jobManager.UpdateProgress(ctx, job, startProgress+int(done), totalFiles)
+				}
+			}
 			for file := range fileChan {
 				select {
 				case <-fatalCh:
@@ -674,19 +1080,25 @@ func (s *IngestService) processFilesInternal(ctx context.Context, jobManager *Jo
 				}
 
 				processed := filesProcessed.Add(1)
-				currentProgress := startProgress + int(processed)
-				slog.Info("processing file", "worker", workerID, "file", filepath.Base(file), "progress", fmt.Sprintf("%d/%d", currentProgress, totalFiles))
-
-				// Update job progress via job manager (handles DB persistence)
-				if jobManager != nil && job != nil {
-					jobManager.UpdateProgress(ctx, job, currentProgress, totalFiles)
-				}
+				slog.Info("processing file", "worker", workerID, "file", filepath.Base(file), "progress", fmt.Sprintf("%d/%d", startProgress+int(processed), totalFiles))
 
 				result, err := s.IngestFile(ctx, file, opts)
 				if err != nil {
+					// Interrupted by cancellation, not processed
+					if ctx.Err() != nil {
+						filesProcessed.Add(-1)
+						continue
+					}
+					finish()
 					if errors.Is(err, llm.ErrFatalAPI) {
 						fatalOnce.Do(func() { close(fatalCh) })
 					}
+					if opts.FailFast {
+						failOnce.Do(func() {
+							failErr = fmt.Errorf("%s: %w", file, err)
+							cancel()
+						})
+					}
 					errorsMu.Lock()
 					errs = append(errs, fmt.Sprintf("%s: %v", file, err))
 					errorsMu.Unlock()
@@ -699,6 +1111,13 @@ func (s *IngestService) processFilesInternal(ctx context.Context, jobManager *Jo
 				if result != nil && !opts.DryRun {
 					chunksCreated.Add(int32(result.ChunksCreated))
 				}
+				if result != nil && result.ChunksFailed > 0 {
+					chunksFailed.Add(int32(result.ChunksFailed))
+					errorsMu.Lock()
+					errs = append(errs, fmt.Sprintf("%s: %d chunk embeddings failed, re-ingest to retry", file, result.ChunksFailed))
+					errorsMu.Unlock()
+				}
+				finish()
 			}
 		}(i)
 	}
@@ -712,12 +1131,17 @@ func (s *IngestService) processFilesInternal(ctx context.Context, jobManager *Jo
 	// Wait for completion
 	wg.Wait()
 
+	if failErr != nil {
+		return nil, failErr
+	}
+
 	slog.Info("file processing complete", "entities", entitiesCreated.Load(), "chunks", chunksCreated.Load(), "errors", len(errs))
 
 	return &IngestResult{
 		FilesProcessed:  int(filesProcessed.Load()),
 		EntitiesCreated: int(entitiesCreated.Load()),
 		ChunksCreated:   int(chunksCreated.Load()),
+		ChunksFailed:    int(chunksFailed.Load()),
 		Errors:          errs,
 	}, nil
 }
@@ -730,6 +1154,9 @@ func (s *IngestService) IngestFilesWithContentAsync(ctx context.Context, jobMana
 	if len(files) == 0 {
 		return nil, fmt.Errorf("no files to ingest")
 	}
+	if err := opts.validateChunking(); err != nil {
+		return nil, err
+	}
 
 	// Extract file paths for job tracking
 	filePaths := make([]string, len(files))
@@ -739,9 +1166,15 @@ func (s *IngestService) IngestFilesWithContentAsync(ctx context.Context, jobMana
 
 	// Prepare options for persistence (excluding name and labels which are now top-level)
 	persistOpts := map[string]any{
-		"extract_graph": opts.ExtractGraph,
-		"content_based": true, // Mark as content-based job
-		"base_dir":      baseDir,
+		"extract_graph":  opts.ExtractGraph,
+		"auto_summarize": opts.AutoSummarize,
+		"fail_fast":      opts.FailFast,
+		"content_based":  true, // Mark as content-based job
+		"base_dir":       baseDir,
+	}
+	opts.persistChunking(persistOpts)
+	if opts.Concurrency > 0 {
+		persistOpts["concurrency"] = opts.concurrency(0)
 	}
 
 	// Create job with persistence (using first file's directory as dirPath for display)
@@ -751,8 +1184,8 @@ func (s *IngestService) IngestFilesWithContentAsync(ctx context.Context, jobMana
 		return nil, fmt.Errorf("create job: %w", err)
 	}
 
-	// Set concurrency from job manager
-	opts.Concurrency = jobManager.Concurrency()
+	// The job manager's concurrency unless the ingest sets its own
+	opts.Concurrency = opts.concurrency(jobManager.Concurrency())
 	opts.Job = job
 
 	// Start processing in background with detached context
@@ -765,11 +1198,13 @@ func (s *IngestService) IngestFilesWithContentAsync(ctx context.Context, jobMana
 		}()
 
 		bgCtx := context.Background()
+		jobCtx, done := jobManager.start(job)
+		defer done()
 
 		// Mark as running
 		jobManager.SetRunning(bgCtx, job)
 
-		result, err := s.processFilesWithContentInternal(bgCtx, jobManager, job, files, baseDir, opts)
+		result, err := s.processFilesWithContentInternal(jobCtx, jobManager, job, files, baseDir, opts)
 		if err != nil {
 			jobManager.Fail(bgCtx, job, err)
 			return
@@ -785,18 +1220,17 @@ func (s *IngestService) processFilesWithContentInternal(ctx context.Context, job
 	slog.Info("starting async content-based file processing", "files", len(files), "extract_graph", opts.ExtractGraph)
 
 	// Set default concurrency
-	concurrency := opts.Concurrency
-	if concurrency <= 0 {
-		concurrency = 4
-	}
+	concurrency := opts.concurrency(4)
 
 	totalFiles := len(files)
 
 	// Result aggregation with thread-safe counters
 	var (
-		filesProcessed  atomic.Int32
+		filesProcessed  atomic.Int32 // Files started
+		filesCompleted  atomic.Int32 // Files done, reported as job progress
 		entitiesCreated atomic.Int32
 		chunksCreated   atomic.Int32
+		chunksFailed    atomic.Int32
 		errorsMu        sync.Mutex
 		errs            []string
 	)
@@ -807,17 +1241,31 @@ func (s *IngestService) processFilesWithContentInternal(ctx context.Context, job
 		content string
 		hash    string
 		baseDir string
+		config  parser.DirConfig
 	}
 	workChan := make(chan workItem, len(files))
 	var wg sync.WaitGroup
 	var fatalOnce sync.Once
 	fatalCh := make(chan struct{}) // closed on fatal API error to abort all workers
 
+	// With FailFast, the first file error cancels the remaining work
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var failOnce sync.Once
+	var failErr error
+
 	// Start workers
 	for i := 0; i < concurrency; i++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
+			// finish counts a file as done, with or without error
+			finish := func() {
+				done := filesCompleted.Add(1)
+				if jobManager != nil && job != nil {
+					jobManager.UpdateProgress(ctx, job, int(done), totalFiles)
+				}
+			}
 			for item := range workChan {
 				select {
 				case <-fatalCh:
@@ -831,16 +1279,23 @@ func (s *IngestService) processFilesWithContentInternal(ctx context.Context, job
 				processed := filesProcessed.Add(1)
 				slog.Info("processing file", "worker", workerID, "file", filepath.Base(item.path), "progress", fmt.Sprintf("%d/%d", processed, totalFiles))
 
-				// Update job progress via job manager
-				if jobManager != nil && job != nil {
-					jobManager.UpdateProgress(ctx, job, int(processed), totalFiles)
-				}
-
-				result, err := s.IngestFileWithContent(ctx, item.path, item.content, item.hash, item.baseDir, opts)
+				result, err := s.IngestFileWithContent(ctx, item.path, item.content, item.hash, item.baseDir, opts.withDirConfig(item.config))
 				if err != nil {
+					// Interrupted by cancellation, not processed
+					if ctx.Err() != nil {
+						filesProcessed.Add(-1)
+						continue
+					}
+					finish()
 					if errors.Is(err, llm.ErrFatalAPI) {
 						fatalOnce.Do(func() { close(fatalCh) })
 					}
+					if opts.FailFast {
+						failOnce.Do(func() {
+							failErr = fmt.Errorf("%s: %w", item.path, err)
+							cancel()
+						})
+					}
 					errorsMu.Lock()
 					errs = append(errs, fmt.Sprintf("%s: %v", item.path, err))
 					errorsMu.Unlock()
@@ -853,31 +1308,47 @@ func (s *IngestService) processFilesWithContentInternal(ctx context.Context, job
 				if result != nil && !opts.DryRun {
 					chunksCreated.Add(int32(result.ChunksCreated))
 				}
+				if result != nil && result.ChunksFailed > 0 {
+					chunksFailed.Add(int32(result.ChunksFailed))
+					errorsMu.Lock()
+					errs = append(errs, fmt.Sprintf("%s: %d chunk embeddings failed, re-ingest to retry", item.path, result.ChunksFailed))
+					errorsMu.Unlock()
+				}
+				finish()
 			}
 		}(i)
 	}
 
 	// Send files to workers
 	for _, f := range files {
-		workChan <- workItem{path: f.Path, content: f.Content, hash: f.Hash, baseDir: baseDir}
+		workChan <- workItem{path: f.Path, content: f.Content, hash: f.Hash, baseDir: baseDir, config: f.Config}
 	}
 	close(workChan)
 
 	// Wait for completion
 	wg.Wait()
 
+	if failErr != nil {
+		return nil, failErr
+	}
+
 	slog.Info("async content-based processing complete", "entities", entitiesCreated.Load(), "chunks", chunksCreated.Load(), "errors", len(errs))
 
 	return &IngestResult{
 		FilesProcessed:  int(filesProcessed.Load()),
 		EntitiesCreated: int(entitiesCreated.Load()),
 		ChunksCreated:   int(chunksCreated.Load()),
+		ChunksFailed:    int(chunksFailed.Load()),
 		Errors:          errs,
 	}, nil
 }
 
 // IngestDirectoryAsync starts an async ingestion job with persistence.
 func (s *IngestService) IngestDirectoryAsync(ctx context.Context, jobManager *JobManager, dirPath string, opts IngestOptions) (*Job, error) {
+	if err := opts.validateChunking(); err != nil {
+		return nil, err
+	}
+
 	// Validate path exists before starting job
 	info, err := os.Stat(dirPath)
 	if err != nil {
@@ -888,12 +1359,15 @@ func (s *IngestService) IngestDirectoryAsync(ctx context.Context, jobManager *Jo
 	}
 
 	// Collect files upfront (deterministic list for resume)
-	files, err := s.CollectFiles(dirPath, opts.Recursive)
+	files, skipped, err := s.CollectFiles(dirPath, opts.Recursive, s.fileSizeLimit(opts))
 	if err != nil {
 		return nil, err
 	}
 
 	if len(files) == 0 {
+		if len(skipped) > 0 {
+			return nil, fmt.Errorf("all %d markdown files in %s are too large", len(skipped), dirPath)
+		}
 		return nil, fmt.Errorf("no markdown files found in %s", dirPath)
 	}
 
@@ -902,9 +1376,16 @@ func (s *IngestService) IngestDirectoryAsync(ctx context.Context, jobManager *Jo
 
 	// Prepare options for persistence (excluding name and labels which are now top-level)
 	persistOpts := map[string]any{
-		"extract_graph": opts.ExtractGraph,
-		"recursive":     opts.Recursive,
-		"base_dir":      baseDir,
+		"extract_graph":  opts.ExtractGraph,
+		"auto_summarize": opts.AutoSummarize,
+		"fail_fast":      opts.FailFast,
+		"recursive":      opts.Recursive,
+		"prune":          opts.Prune,
+		"base_dir":       baseDir,
+	}
+	opts.persistChunking(persistOpts)
+	if opts.Concurrency > 0 {
+		persistOpts["concurrency"] = opts.concurrency(0)
 	}
 
 	// Create job with persistence
@@ -913,9 +1394,10 @@ func (s *IngestService) IngestDirectoryAsync(ctx context.Context, jobManager *Jo
 		return nil, fmt.Errorf("create job: %w", err)
 	}
 
-	// Set concurrency and baseDir from job manager
-	opts.Concurrency = jobManager.Concurrency()
+	// The job manager's concurrency unless the ingest sets its own
+	opts.Concurrency = opts.concurrency(jobManager.Concurrency())
 	opts.BaseDir = baseDir
+	opts.ConfigDir = dirPath
 
 	// Start processing in background
 	go func() {
@@ -927,15 +1409,20 @@ func (s *IngestService) IngestDirectoryAsync(ctx context.Context, jobManager *Jo
 		}()
 
 		bgCtx := context.Background()
+		jobCtx, done := jobManager.start(job)
+		defer done()
 
 		// Mark as running
 		jobManager.SetRunning(bgCtx, job)
 
-		result, err := s.ProcessFiles(bgCtx, jobManager, job, files, opts)
+		result, err := s.ProcessFiles(jobCtx, jobManager, job, files, opts)
 		if err != nil {
 			jobManager.Fail(bgCtx, job, err)
 			return
 		}
+		result.FilesSkipped += len(skipped)
+		result.Errors = append(skipped, result.Errors...)
+		s.pruneAfterIngest(jobCtx, dirPath, files, opts, result)
 		jobManager.Complete(bgCtx, job, result)
 	}()
 
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+	"github.com/raphaelgruber/memcp-go/internal/parser"
+)
+
+// IngestDiff describes what ingesting a set of files would change.
+type IngestDiff struct {
+	New       []string     // Files without an existing entity
+	Changed   []FileChange // Files whose entity would be updated
+	Unchanged []string     // Files whose entity is up to date
+}
+
+// FileChange compares an existing entity with the entity a file would produce.
+type FileChange struct {
+	Path         string
+	EntityID     string
+	Old          EntityPreview
+	New          EntityPreview
+	LinesAdded   int // Content lines only in the file
+	LinesRemoved int // Content lines only in the existing entity
+}
+
+// Actions a re-ingest takes on a file's entity, reported by dry runs.
+const (
+	FileActionCreate    = "create"
+	FileActionUpdate    = "update"
+	FileActionUnchanged = "unchanged"
+)
+
+// FileAction is what ingesting a file would do to its entity.
+type FileAction struct {
+	Path   string
+	Action string // FileActionCreate, FileActionUpdate or FileActionUnchanged
+}
+
+// Actions lists the action for each file of d, ordered by path.
+func (d IngestDiff) Actions() []FileAction {
+	actions := make([]FileAction, 0, len(d.New)+len(d.Changed)+len(d.Unchanged))
+	for _, p := range d.New {
+		actions = append(actions, FileAction{Path: p, Action: FileActionCreate})
+	}
+	for _, c := range d.Changed {
+		actions = append(actions, FileAction{Path: c.Path, Action: FileActionUpdate})
+	}
+	for _, p := range d.Unchanged {
+		actions = append(actions, FileAction{Path: p, Action: FileActionUnchanged})
+	}
+	slices.SortFunc(actions, func(a, b FileAction) int { return strings.Compare(a.Path, b.Path) })
+	return actions
+}
+
+// EntityPreview is the subset of an entity shown when comparing versions.
+type EntityPreview struct {
+	Name          string
+	Type          string
+	Summary       *string
+	Labels        []string
+	ContentLength int
+}
+
+// DiffIngest reports which Markdown files in dirPath are new, changed or
+// unchanged compared to the entities a previous ingest created. Nothing is
+// written.
+func (s *IngestService) DiffIngest(ctx context.Context, dirPath string, opts IngestOptions) (IngestDiff, error) {
+	paths, _, err := s.CollectFiles(dirPath, opts.Recursive, s.fileSizeLimit(opts))
+	if err != nil {
+		return IngestDiff{}, err
+	}
+
+	files := make([]FileContent, 0, len(paths))
+	for _, p := range paths {
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return IngestDiff{}, fmt.Errorf("read file: %w", err)
+		}
+		cfg, err := parser.ResolveDirConfig(dirPath, p)
+		if err != nil {
+			return IngestDiff{}, fmt.Errorf("load directory config: %w", err)
+		}
+		hash := sha256.Sum256(content)
+		files = append(files, FileContent{
+			Path:    p,
+			Content: string(content),
+			Hash:    hex.EncodeToString(hash[:]),
+			Config:  cfg,
+		})
+	}
+
+	return s.DiffFilesWithContent(ctx, files, filepath.Base(filepath.Clean(dirPath)), opts)
+}
+
+// DiffFilesWithContent is DiffIngest for file content provided by a client.
+// baseDir is used to derive entity IDs as during ingestion.
+func (s *IngestService) DiffFilesWithContent(ctx context.Context, files []FileContent, baseDir string, opts IngestOptions) (IngestDiff, error) {
+	diff := IngestDiff{
+		New:       []string{},
+		Changed:   []FileChange{},
+		Unchanged: []string{},
+	}
+	if len(files) == 0 {
+		return diff, nil
+	}
+
+	ids := make([]string, 0, len(files))
+	for _, f := range files {
+		if id := fileEntityID(baseDir, f.Path); id != nil {
+			ids = append(ids, *id)
+		}
+	}
+	existing, err := s.db.GetEntitiesByIDs(ctx, ids)
+	if err != nil {
+		return diff, err
+	}
+
+	previewOpts := opts
+	previewOpts.DryRun = true
+
+	for _, f := range files {
+		id := fileEntityID(baseDir, f.Path)
+		var old *models.Entity
+		if id != nil {
+			old = existing[*id]
+		}
+		if old == nil {
+			diff.New = append(diff.New, f.Path)
+			continue
+		}
+
+		if old.ContentHash != nil && *old.ContentHash == f.Hash {
+			diff.Unchanged = append(diff.Unchanged, f.Path)
+			continue
+		}
+
+		preview, err := s.ingestFileInternal(ctx, f.Path, []byte(f.Content), &f.Hash, fileEntityID(baseDir, f.Path), previewOpts.withDirConfig(f.Config))
+		if err != nil {
+			return diff, fmt.Errorf("preview %s: %w", f.Path, err)
+		}
+		next := preview.Entity
+
+		oldContent, newContent := derefString(old.Content), derefString(next.Content)
+		if old.Content == nil {
+			// Content may be stored only in chunks
+			if oldContent, err = s.entityService.chunkedContent(ctx, *id); err != nil {
+				return diff, fmt.Errorf("reassemble %s: %w", *id, err)
+			}
+		}
+		if old.ContentHash == nil && oldContent == newContent && old.Name == next.Name && old.Type == next.Type {
+			// Ingested without a hash (server-side path), but content matches
+			diff.Unchanged = append(diff.Unchanged, f.Path)
+			continue
+		}
+
+		added, removed := lineDiff(oldContent, newContent)
+		diff.Changed = append(diff.Changed, FileChange{
+			Path:         f.Path,
+			EntityID:     *id,
+			Old:          entityPreview(old),
+			New:          entityPreview(next),
+			LinesAdded:   added,
+			LinesRemoved: removed,
+		})
+	}
+
+	return diff, nil
+}
+
+// entityPreview extracts the fields compared by DiffIngest.
+func entityPreview(e *models.Entity) EntityPreview {
+	return EntityPreview{
+		Name:          e.Name,
+		Type:          e.Type,
+		Summary:       e.Summary,
+		Labels:        e.Labels,
+		ContentLength: len(derefString(e.Content)),
+	}
+}
+
+// lineDiff counts lines only in newText (added) and only in oldText (removed),
+// treating each text as a multiset of lines.
+func lineDiff(oldText, newText string) (added, removed int) {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(oldText, "\n") {
+		counts[line]++
+	}
+	for _, line := range strings.Split(newText, "\n") {
+		if counts[line] > 0 {
+			counts[line]--
+		} else {
+			added++
+		}
+	}
+	for _, n := range counts {
+		removed += n
+	}
+	return added, removed
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
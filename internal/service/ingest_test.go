@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+func TestCollectFilesSkipsLargeFiles(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small.md")
+	if err := os.WriteFile(small, []byte("# Small\n\nFits the limit."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sparse file: large on stat without writing the bytes
+	large := filepath.Join(dir, "large.md")
+	f, err := os.Create(large)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(50 << 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &IngestService{maxFileBytes: 10 << 20}
+	limit := s.fileSizeLimit(IngestOptions{})
+
+	files, skipped, err := s.CollectFiles(dir, true, limit)
+	if err != nil {
+		t.Fatalf("CollectFiles() error = %v", err)
+	}
+	if len(files) != 1 || files[0] != small {
+		t.Errorf("files = %v, want [%s]", files, small)
+	}
+	if len(skipped) != 1 || !strings.HasPrefix(skipped[0], large+": file too large") {
+		t.Errorf("skipped = %v, want a file too large error for %s", skipped, large)
+	}
+
+	// Without a limit, both are collected
+	files, skipped, err = s.CollectFiles(dir, true, s.fileSizeLimit(IngestOptions{MaxFileBytes: -1}))
+	if err != nil {
+		t.Fatalf("CollectFiles() error = %v", err)
+	}
+	if len(files) != 2 || len(skipped) != 0 {
+		t.Errorf("without limit: files = %v, skipped = %v, want both files collected", files, skipped)
+	}
+
+	// Single file ingest rejects the file before reading it
+	if _, err := s.IngestFile(context.Background(), large, IngestOptions{}); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("IngestFile() error = %v, want ErrFileTooLarge", err)
+	}
+}
+
+func TestFileSizeLimit(t *testing.T) {
+	s := &IngestService{maxFileBytes: 100}
+	tests := []struct {
+		name string
+		opts IngestOptions
+		want int64
+	}{
+		{"server default", IngestOptions{}, 100},
+		{"ingest override", IngestOptions{MaxFileBytes: 5}, 5},
+		{"no limit", IngestOptions{MaxFileBytes: -1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.fileSizeLimit(tt.opts); got != tt.want {
+				t.Errorf("fileSizeLimit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckText(t *testing.T) {
+	// A multi-byte rune straddling the end of the sniffed prefix
+	straddling := strings.Repeat("a", textSniffBytes-1) + "é" + "rest"
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"markdown", "# Title\n\n\tIndented text, ümlauts and emoji 🚀\r\n", false},
+		{"empty", "", false},
+		{"rune cut by prefix", straddling, false},
+		{"NUL bytes", "# Title\x00\x00\x00", true},
+		{"invalid UTF-8", "# Title \xff\xfe", true},
+		{"control characters", "\x01\x02\x03\x04 text", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkText([]byte(tt.content))
+			if tt.wantErr != errors.Is(err, ErrNotText) {
+				t.Errorf("checkText() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIngestSkipsBinaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "binary.md")
+	if err := os.WriteFile(binary, []byte("# Looks like Markdown\x00\x00\x01\x02garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &IngestService{}
+	result, err := s.IngestDirectory(context.Background(), dir, IngestOptions{})
+	if err != nil {
+		t.Fatalf("IngestDirectory() error = %v", err)
+	}
+	if result.EntitiesCreated != 0 {
+		t.Errorf("EntitiesCreated = %d, want 0", result.EntitiesCreated)
+	}
+	if len(result.Errors) != 1 || !strings.HasPrefix(result.Errors[0], binary+": not valid text") {
+		t.Errorf("Errors = %v, want a not valid text error for %s", result.Errors, binary)
+	}
+}
+
+func TestIngestConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		fallback    int
+		want        int
+	}{
+		{"unset uses fallback", 0, 8, 8},
+		{"negative uses fallback", -2, 8, 8},
+		{"override below fallback", 1, 8, 1},
+		{"override above fallback", 12, 8, 12},
+		{"capped", 1000, 8, maxIngestConcurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := IngestOptions{Concurrency: tt.concurrency}
+			if got := opts.concurrency(tt.fallback); got != tt.want {
+				t.Errorf("concurrency(%d) = %d, want %d", tt.fallback, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngestDiffActions(t *testing.T) {
+	diff := IngestDiff{
+		New:       []string{"docs/c.md"},
+		Changed:   []FileChange{{Path: "docs/a.md", EntityID: "docs-a"}},
+		Unchanged: []string{"docs/b.md"},
+	}
+	want := []FileAction{
+		{Path: "docs/a.md", Action: FileActionUpdate},
+		{Path: "docs/b.md", Action: FileActionUnchanged},
+		{Path: "docs/c.md", Action: FileActionCreate},
+	}
+	if got := diff.Actions(); !slices.Equal(got, want) {
+		t.Errorf("Actions() = %v, want %v", got, want)
+	}
+}
+
+func TestIngestFailFast(t *testing.T) {
+	// One binary file fails before touching the database; the dry-run files
+	// after it would succeed
+	const total = 200
+	files := []FileContent{{Path: "docs/bad.md", Content: "bin\x00\x00"}}
+	for i := 1; i < total; i++ {
+		files = append(files, FileContent{Path: fmt.Sprintf("docs/file%03d.md", i), Content: "# Title\n\nText"})
+	}
+
+	tests := []struct {
+		name         string
+		concurrency  int
+		failFast     bool
+		wantStatus   JobStatus
+		maxProgress  int
+		wantProgress int // exact progress, if set
+	}{
+		{"single worker stops after the failure", 1, true, JobStatusFailed, 1, 1},
+		{"other workers stop", 4, true, JobStatusFailed, total / 2, 0},
+		{"without fail-fast all files are processed", 4, false, JobStatusCompleted, total, total},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			m := NewJobManager(1, nil, nil)
+			s := &IngestService{}
+
+			job, err := s.IngestFilesWithContentAsync(ctx, m, files, "docs", IngestOptions{DryRun: true, FailFast: tt.failFast, Concurrency: tt.concurrency})
+			if err != nil {
+				t.Fatalf("IngestFilesWithContentAsync() error = %v", err)
+			}
+			updates, unsubscribe, err := m.WatchJob(job.ID)
+			if err != nil {
+				t.Fatalf("WatchJob() error = %v", err)
+			}
+			defer unsubscribe()
+			for range updates {
+			}
+
+			got := job.Snapshot()
+			if got.Status != tt.wantStatus {
+				t.Fatalf("status = %q (error %q), want %q", got.Status, got.Error, tt.wantStatus)
+			}
+			if tt.failFast && (!strings.HasPrefix(got.Error, "docs/bad.md: ") || !strings.Contains(got.Error, ErrNotText.Error())) {
+				t.Errorf("error = %q, want the failing file's error", got.Error)
+			}
+			if got.Progress > tt.maxProgress {
+				t.Errorf("progress = %d, want at most %d", got.Progress, tt.maxProgress)
+			}
+			if tt.wantProgress > 0 && got.Progress != tt.wantProgress {
+				t.Errorf("progress = %d, want %d", got.Progress, tt.wantProgress)
+			}
+			if !tt.failFast && (got.Result == nil || len(got.Result.Errors) != 1) {
+				t.Errorf("result = %+v, want the failing file's error collected", got.Result)
+			}
+		})
+	}
+}
+
+func TestResumeOptions(t *testing.T) {
+	// Options as stored with the job: numbers come back from the database as
+	// floats
+	stored := map[string]any{
+		"extract_graph":  true,
+		"auto_summarize": false,
+		"fail_fast":      true,
+		"recursive":      true,
+		"prune":          true,
+		"concurrency":    float64(2),
+	}
+
+	opts := resumeOptions(models.IngestJob{DirPath: "/docs", Options: stored}, 8)
+	if !opts.FailFast {
+		t.Error("FailFast lost on resume")
+	}
+	if !opts.ExtractGraph || opts.AutoSummarize || !opts.Recursive || !opts.Prune {
+		t.Errorf("resumed options = %+v, want extract_graph, recursive and prune", opts)
+	}
+	if opts.Concurrency != 2 || opts.ConfigDir != "/docs" {
+		t.Errorf("Concurrency = %d, ConfigDir = %q, want 2 and /docs", opts.Concurrency, opts.ConfigDir)
+	}
+
+	// Jobs stored without options resume with defaults
+	opts = resumeOptions(models.IngestJob{DirPath: "/docs"}, 8)
+	if opts.FailFast || opts.Concurrency != 8 {
+		t.Errorf("resumed options = %+v, want defaults with concurrency 8", opts)
+	}
+}
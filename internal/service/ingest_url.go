@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+
+	"github.com/raphaelgruber/memcp-go/internal/parser"
+)
+
+const (
+	urlFetchTimeout = 30 * time.Second
+	urlUserAgent    = "knowhow/1.0 (+https://github.com/raphi011/knowhow)"
+)
+
+// ErrPrivateAddress is returned when a URL resolves to an address on the
+// server's own network: loopback, private, link-local or unspecified.
+var ErrPrivateAddress = errors.New("address not allowed")
+
+// newURLClient returns the HTTP client of URL ingests. It refuses to connect
+// to private addresses, checked after DNS resolution on every connection, so
+// redirects and DNS names can't reach internal services either. Proxies are
+// not used, as they would hide the address actually connected to.
+func newURLClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: urlFetchTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return checkPublicAddress(address)
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: urlFetchTimeout, Transport: transport}
+}
+
+// checkPublicAddress returns an error wrapping ErrPrivateAddress unless the
+// resolved host:port address is publicly routable.
+func checkPublicAddress(address string) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+	}
+	ip := addrPort.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, ip)
+	}
+	return nil
+}
+
+// IngestURL fetches a web page and ingests its main content, extracted as
+// Markdown without navigation and ads, like a file whose source path is the
+// URL. Plain text and Markdown responses are ingested as they are. Pages
+// larger than the ingest's size limit fail with ErrFileTooLarge, pages on
+// private addresses with ErrPrivateAddress.
+//
+// The content hash is taken over the extracted text, so re-ingesting an
+// unchanged page returns the existing entity without changes.
+func (s *IngestService) IngestURL(ctx context.Context, rawURL string, opts IngestOptions) (*IngestFileResult, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: want an http or https URL", rawURL)
+	}
+	u.Fragment = ""
+	sourcePath := u.String()
+
+	content, err := s.fetchURL(ctx, u, s.fileSizeLimit(opts))
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("no content found at %s", sourcePath)
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	contentHash := hex.EncodeToString(sum[:])
+	entityID := urlEntityID(u)
+
+	// Skip unchanged pages like unchanged files
+	existing, err := s.db.GetEntity(ctx, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("get entity: %w", err)
+	}
+	if existing != nil && existing.ContentHash != nil && *existing.ContentHash == contentHash {
+		slog.Info("page unchanged, skipping", "url", sourcePath, "entity", entityID)
+		return &IngestFileResult{Entity: existing}, nil
+	}
+
+	return s.ingestFileInternal(ctx, sourcePath, []byte(content), &contentHash, &entityID, opts)
+}
+
+// fetchURL gets a page and returns its content as Markdown. Responses larger
+// than limit bytes fail with ErrFileTooLarge (0 = no limit).
+func (s *IngestService) fetchURL(ctx context.Context, u *url.URL, limit int64) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", urlUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/markdown;q=0.9,text/plain;q=0.8")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("fetch %s: unexpected status %s", u, resp.Status)
+	}
+	if err := checkFileSize(resp.ContentLength, limit); err != nil {
+		return "", fmt.Errorf("fetch %s: %w", u, err)
+	}
+
+	body := io.Reader(resp.Body)
+	if limit > 0 {
+		body = io.LimitReader(resp.Body, limit+1)
+	}
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", u, err)
+	}
+	if err := checkFileSize(int64(len(raw)), limit); err != nil {
+		return "", fmt.Errorf("fetch %s: %w", u, err)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// Missing or malformed header: assume HTML, the common case
+		mediaType = "text/html"
+	}
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		// Resolve links against the final URL, after redirects
+		article, err := parser.ExtractArticle(bytes.NewReader(raw), contentType, resp.Request.URL)
+		if err != nil {
+			return "", fmt.Errorf("extract %s: %w", u, err)
+		}
+		return article.Markdown, nil
+	case "text/markdown", "text/x-markdown", "text/plain":
+		return string(raw), nil
+	default:
+		return "", fmt.Errorf("fetch %s: unsupported content type %q", u, mediaType)
+	}
+}
+
+// urlEntityID derives the entity ID of a page from its host, path and query,
+// e.g. https://example.com/blog/auth → "example-com-blog-auth".
+func urlEntityID(u *url.URL) string {
+	parts := strings.FieldsFunc(u.Host+u.Path+"-"+u.RawQuery, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return slugify(strings.Join(parts, "-"))
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestFetchURL(t *testing.T) {
+	var userAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		switch r.URL.Path {
+		case "/article":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><body><nav>Menu</nav><article><h1>Post</h1><p>Body text.</p></article></body></html>`))
+		case "/notes.md":
+			w.Header().Set("Content-Type", "text/markdown")
+			w.Write([]byte("# Notes\n\nAs is."))
+		case "/large":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(strings.Repeat("x", 200)))
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("png"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	s := &IngestService{httpClient: srv.Client()}
+	fetch := func(path string, limit int64) (string, error) {
+		u, err := url.Parse(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.fetchURL(context.Background(), u, limit)
+	}
+
+	got, err := fetch("/article", 0)
+	if err != nil {
+		t.Fatalf("article: error = %v", err)
+	}
+	if want := "# Post\n\nBody text."; got != want {
+		t.Errorf("article = %q, want %q", got, want)
+	}
+	if userAgent != urlUserAgent {
+		t.Errorf("User-Agent = %q, want %q", userAgent, urlUserAgent)
+	}
+
+	if got, err := fetch("/notes.md", 0); err != nil || got != "# Notes\n\nAs is." {
+		t.Errorf("markdown = %q, %v, want it as is", got, err)
+	}
+	if _, err := fetch("/large", 100); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("large: error = %v, want ErrFileTooLarge", err)
+	}
+	if _, err := fetch("/image", 0); err == nil || !strings.Contains(err.Error(), "unsupported content type") {
+		t.Errorf("image: error = %v, want unsupported content type", err)
+	}
+	if _, err := fetch("/missing", 0); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("missing: error = %v, want 404 status", err)
+	}
+}
+
+func TestFetchURLPrivateAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request to loopback server was not refused")
+	}))
+	defer srv.Close()
+
+	s := &IngestService{httpClient: newURLClient()}
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.fetchURL(context.Background(), u, 0); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("loopback: error = %v, want ErrPrivateAddress", err)
+	}
+}
+
+func TestCheckPublicAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", true},
+		{"127.0.0.1:80", false},
+		{"[::1]:80", false},
+		{"10.0.0.5:80", false},
+		{"172.16.3.4:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"0.0.0.0:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"[fd00::1]:80", false},
+		{"[fe80::1]:80", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := checkPublicAddress(tt.address)
+			if tt.allowed != (err == nil) {
+				t.Errorf("checkPublicAddress(%q) = %v, want allowed %v", tt.address, err, tt.allowed)
+			}
+			if err != nil && !errors.Is(err, ErrPrivateAddress) {
+				t.Errorf("error = %v, want ErrPrivateAddress", err)
+			}
+		})
+	}
+}
+
+func TestURLEntityID(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.com/blog/auth", "example-com-blog-auth"},
+		{"https://Example.com/blog/auth/", "example-com-blog-auth"},
+		{"https://example.com/post?id=5", "example-com-post-id-5"},
+		{"http://localhost:8080/", "localhost-8080"},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := urlEntityID(u); got != tt.want {
+			t.Errorf("urlEntityID(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+)
+
+// JobProgress is a job's state as pushed to its watchers.
+type JobProgress struct {
+	JobID    string
+	Progress int
+	Total    int
+	Status   JobStatus
+	Error    string
+}
+
+// Finished reports whether the status is final: completed, failed or
+// cancelled.
+func (s JobStatus) Finished() bool {
+	return s == JobStatusCompleted || s == JobStatusFailed || s == JobStatusCancelled
+}
+
+// jobWatchers holds the channels of the watchers of each job.
+type jobWatchers struct {
+	mu     sync.Mutex
+	byJob  map[string]map[int]chan JobProgress
+	nextID int
+}
+
+// WatchJob subscribes to the progress of a job. The channel receives the
+// job's current state right away, then each change; a watcher that falls
+// behind skips to the latest state. After the job finishes, the channel
+// receives the final state and is closed. The returned function
+// unsubscribes. Returns an error if no such job is known.
+func (m *JobManager) WatchJob(id string) (<-chan JobProgress, func(), error) {
+	job := m.GetJob(id)
+	if job == nil {
+		return nil, nil, fmt.Errorf("job not found: %s", id)
+	}
+
+	// Buffer of one: publish replaces a state the watcher hasn't read yet
+	ch := make(chan JobProgress, 1)
+	w := &m.watchers
+
+	w.mu.Lock()
+	current := jobProgress(job)
+	ch <- current
+	if current.Status.Finished() {
+		close(ch)
+		w.mu.Unlock()
+		return ch, func() {}, nil
+	}
+	watcherID := w.nextID
+	w.nextID++
+	if w.byJob[id] == nil {
+		w.byJob[id] = make(map[int]chan JobProgress)
+	}
+	w.byJob[id][watcherID] = ch
+	w.mu.Unlock()
+
+	unsubscribe := func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		// Already closed if the job finished
+		if _, ok := w.byJob[id][watcherID]; !ok {
+			return
+		}
+		delete(w.byJob[id], watcherID)
+		if len(w.byJob[id]) == 0 {
+			delete(w.byJob, id)
+		}
+		close(ch)
+	}
+	return ch, unsubscribe, nil
+}
+
+// publishProgress pushes the job's state to its watchers without blocking,
+// closing their channels once the job has finished.
+func (m *JobManager) publishProgress(job *Job) {
+	w := &m.watchers
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	watchers := w.byJob[job.ID]
+	if len(watchers) == 0 {
+		return
+	}
+
+	// Read under w.mu so concurrent publishes reach watchers in order
+	progress := jobProgress(job)
+	for _, ch := range watchers {
+		select {
+		case ch <- progress:
+		default:
+			// Replace the unread state; publishers hold w.mu, so the send can't block
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- progress
+		}
+	}
+
+	if progress.Status.Finished() {
+		for _, ch := range watchers {
+			close(ch)
+		}
+		delete(w.byJob, job.ID)
+	}
+}
+
+// jobProgress returns the current progress of a job.
+func jobProgress(job *Job) JobProgress {
+	job.mu.RLock()
+	defer job.mu.RUnlock()
+	return JobProgress{
+		JobID:    job.ID,
+		Progress: job.Progress,
+		Total:    job.Total,
+		Status:   job.Status,
+		Error:    job.Error,
+	}
+}
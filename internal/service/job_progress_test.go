@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestWatchJob(t *testing.T) {
+	ctx := context.Background()
+	m := NewJobManager(1, nil, nil)
+
+	if _, _, err := m.WatchJob("missing"); err == nil {
+		t.Fatal("WatchJob() of unknown job: want error")
+	}
+
+	job, err := m.CreateJob(ctx, "ingest", "docs", "/docs", []string{"a.md", "b.md"}, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+
+	updates, unsubscribe, err := m.WatchJob(job.ID)
+	if err != nil {
+		t.Fatalf("WatchJob() error = %v", err)
+	}
+	defer unsubscribe()
+
+	if got := <-updates; got.Status != JobStatusPending || got.Total != 2 {
+		t.Errorf("initial state = %+v, want pending with total 2", got)
+	}
+
+	m.UpdateProgress(ctx, job, 1, 2)
+	m.UpdateProgress(ctx, job, 2, 2)
+	// A watcher that fell behind skips to the latest state
+	if got := <-updates; got.Progress != 2 || got.Status != JobStatusRunning {
+		t.Errorf("progress = %+v, want running at 2", got)
+	}
+
+	m.Complete(ctx, job, &IngestResult{FilesProcessed: 2})
+	if got := <-updates; got.Status != JobStatusCompleted {
+		t.Errorf("final state = %+v, want completed", got)
+	}
+	if _, ok := <-updates; ok {
+		t.Error("channel still open after the job finished")
+	}
+
+	// Watching a finished job yields its final state only
+	updates, unsubscribe, err = m.WatchJob(job.ID)
+	if err != nil {
+		t.Fatalf("WatchJob() of finished job error = %v", err)
+	}
+	defer unsubscribe()
+	if got := <-updates; got.Status != JobStatusCompleted {
+		t.Errorf("state = %+v, want completed", got)
+	}
+	if _, ok := <-updates; ok {
+		t.Error("channel of finished job still open")
+	}
+}
+
+func TestJobProgressConcurrentCompletion(t *testing.T) {
+	ctx := context.Background()
+	m := NewJobManager(1, nil, nil)
+
+	const total = 50
+	files := make([]FileContent, total)
+	paths := make([]string, total)
+	for i := range files {
+		// Binary content fails before touching the database
+		paths[i] = fmt.Sprintf("/docs/file%02d.md", i)
+		files[i] = FileContent{Path: paths[i], Content: "bin\x00\x00"}
+	}
+	job, err := m.CreateJob(ctx, "ingest", "docs", "/docs", paths, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+
+	updates, unsubscribe, err := m.WatchJob(job.ID)
+	if err != nil {
+		t.Fatalf("WatchJob() error = %v", err)
+	}
+	defer unsubscribe()
+	watched := make(chan error, 1)
+	go func() {
+		last := 0
+		for p := range updates {
+			if p.Progress < last || p.Progress > total {
+				watched <- fmt.Errorf("progress went from %d to %d of %d", last, p.Progress, total)
+				return
+			}
+			last = p.Progress
+			if p.Status == JobStatusCompleted {
+				break
+			}
+		}
+		watched <- nil
+	}()
+
+	s := &IngestService{}
+	result, err := s.processFilesWithContentInternal(ctx, m, job, files, "/docs", IngestOptions{Concurrency: 8})
+	if err != nil {
+		t.Fatalf("processFilesWithContentInternal() error = %v", err)
+	}
+	if len(result.Errors) != total {
+		t.Errorf("errors = %d, want %d", len(result.Errors), total)
+	}
+	if job.Progress != total {
+		t.Errorf("final progress = %d, want %d", job.Progress, total)
+	}
+
+	// Late or stale reports never move progress back or past total
+	m.UpdateProgress(ctx, job, 3, total)
+	m.UpdateProgress(ctx, job, total+5, total)
+	if job.Progress != total {
+		t.Errorf("progress after stale reports = %d, want %d", job.Progress, total)
+	}
+
+	m.Complete(ctx, job, result)
+	if err := <-watched; err != nil {
+		t.Error(err)
+	}
+}
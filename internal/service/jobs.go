@@ -22,12 +22,13 @@ const (
 	JobStatusRunning   JobStatus = "running"
 	JobStatusCompleted JobStatus = "completed"
 	JobStatusFailed    JobStatus = "failed"
+	JobStatusCancelled JobStatus = "cancelled"
 )
 
 // Job represents a background processing job.
 type Job struct {
 	ID          string
-	Type        string // "ingest"
+	Type        string // "ingest" | "summarize"
 	Status      JobStatus
 	Name        string   // User-provided name for rerunning
 	Labels      []string // Curated labels applied to entities
@@ -45,7 +46,8 @@ type Job struct {
 
 	// Internal fields
 	mu                 sync.RWMutex
-	lastProgressUpdate time.Time // For debouncing DB writes
+	lastProgressUpdate time.Time          // For debouncing DB writes
+	cancel             context.CancelFunc // Stops the job's goroutine (nil until it starts)
 }
 
 // JobManager tracks and manages background jobs.
@@ -54,10 +56,13 @@ type JobManager struct {
 	mu          sync.RWMutex
 	concurrency int
 	db          *db.Client
+	webhook     *JobWebhook // Notified when a job finishes (nil disables)
+	watchers    jobWatchers // Subscribers to job progress (see WatchJob)
 }
 
-// NewJobManager creates a new job manager.
-func NewJobManager(concurrency int, dbClient *db.Client) *JobManager {
+// NewJobManager creates a new job manager. If webhook is set, it's notified
+// whenever a job completes, fails or is cancelled.
+func NewJobManager(concurrency int, dbClient *db.Client, webhook *JobWebhook) *JobManager {
 	if concurrency <= 0 {
 		concurrency = 4
 	}
@@ -65,6 +70,8 @@ func NewJobManager(concurrency int, dbClient *db.Client) *JobManager {
 		jobs:        make(map[string]*Job),
 		concurrency: concurrency,
 		db:          dbClient,
+		webhook:     webhook,
+		watchers:    jobWatchers{byJob: make(map[string]map[int]chan JobProgress)},
 	}
 }
 
@@ -89,7 +96,7 @@ func (m *JobManager) CreateJob(ctx context.Context, jobType, name, dirPath strin
 
 	// Persist to database
 	if m.db != nil {
-		if err := m.db.CreateIngestJob(ctx, job.ID, name, dirPath, files, labels, opts); err != nil {
+		if err := m.db.CreateIngestJob(ctx, job.ID, jobType, name, dirPath, files, labels, opts); err != nil {
 			return nil, err
 		}
 	}
@@ -134,23 +141,131 @@ func (m *JobManager) ListJobs() []*Job {
 	return jobs
 }
 
+// defaultJobsPageSize is used by ListJobsPaged when no limit is given.
+const defaultJobsPageSize = 50
+
+// ListJobsPaged returns a page of the job history, most recent first,
+// optionally filtered by status. History comes from the database so jobs
+// pruned from memory are included; jobs still in memory are returned with
+// their live state. Without a database only in-memory jobs are listed.
+func (m *JobManager) ListJobsPaged(ctx context.Context, status *string, limit, offset int) ([]*Job, error) {
+	if limit <= 0 {
+		limit = defaultJobsPageSize
+	}
+	offset = max(offset, 0)
+
+	if m.db == nil {
+		var jobs []*Job
+		for _, job := range m.ListJobs() {
+			if status == nil || string(job.Snapshot().Status) == *status {
+				jobs = append(jobs, job)
+			}
+		}
+		if offset >= len(jobs) {
+			return []*Job{}, nil
+		}
+		return jobs[offset:min(offset+limit, len(jobs))], nil
+	}
+
+	records, err := m.db.ListIngestJobs(ctx, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(records))
+	for _, record := range records {
+		id, err := models.RecordIDString(record.ID)
+		if err != nil {
+			slog.Warn("skipping job with unexpected ID", "error", err)
+			continue
+		}
+		if live := m.GetJob(id); live != nil {
+			jobs = append(jobs, live)
+			continue
+		}
+		jobs = append(jobs, jobFromRecord(id, record))
+	}
+	return jobs, nil
+}
+
+// jobFromRecord converts a persisted job to a Job.
+func jobFromRecord(id string, r models.IngestJob) *Job {
+	job := &Job{
+		ID:          id,
+		Type:        r.JobType,
+		Status:      JobStatus(r.Status),
+		Labels:      r.Labels,
+		Progress:    r.Progress,
+		Total:       r.Total,
+		StartedAt:   r.StartedAt,
+		CompletedAt: r.CompletedAt,
+		DirPath:     r.DirPath,
+	}
+	if r.Name != nil {
+		job.Name = *r.Name
+	}
+	if r.Error != nil {
+		job.Error = *r.Error
+	}
+	if r.Result != nil {
+		job.Result = &IngestResult{
+			FilesProcessed:   recordInt(r.Result, "files_processed"),
+			EntitiesCreated:  recordInt(r.Result, "entities_created"),
+			ChunksCreated:    recordInt(r.Result, "chunks_created"),
+			ChunksFailed:     recordInt(r.Result, "chunks_failed"),
+			RelationsCreated: recordInt(r.Result, "relations_created"),
+			EntitiesDeleted:  recordInt(r.Result, "entities_deleted"),
+		}
+		if errs, ok := r.Result["errors"].([]any); ok {
+			for _, e := range errs {
+				if s, ok := e.(string); ok {
+					job.Result.Errors = append(job.Result.Errors, s)
+				}
+			}
+		}
+	}
+	return job
+}
+
+// recordInt reads a number from a persisted result map.
+func recordInt(m map[string]any, key string) int {
+	switch n := m[key].(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case uint64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+// progressPersistInterval is the minimum time between persisted progress
+// updates of a job; the last file is always persisted.
+const progressPersistInterval = 5 * time.Second
+
 // UpdateProgress updates job progress with debounced DB persistence.
+// Concurrent workers may report out of order, so progress only moves
+// forward and never beyond total.
 func (m *JobManager) UpdateProgress(ctx context.Context, job *Job, current, total int) {
 	job.mu.Lock()
-	job.Progress = current
+	job.Progress = min(max(job.Progress, current), total)
 	job.Total = total
+	current = job.Progress
 	if job.Status == JobStatusPending {
 		job.Status = JobStatusRunning
 	}
 
-	// Debounce DB updates - only persist every 5 seconds or every 10 files
-	shouldPersist := m.db != nil && (time.Since(job.lastProgressUpdate) > 5*time.Second ||
-		current%10 == 0 || current == total)
+	shouldPersist := m.db != nil && (time.Since(job.lastProgressUpdate) > progressPersistInterval || current == total)
 	if shouldPersist {
 		job.lastProgressUpdate = time.Now()
 	}
 	job.mu.Unlock()
 
+	m.publishProgress(job)
+
 	if shouldPersist {
 		if err := m.db.UpdateJobProgress(ctx, job.ID, current); err != nil {
 			slog.Warn("failed to persist job progress", "job_id", job.ID, "error", err)
@@ -158,12 +273,77 @@ func (m *JobManager) UpdateProgress(ctx context.Context, job *Job, current, tota
 	}
 }
 
+// start returns the context a job's background goroutine processes with,
+// which CancelJob cancels. The context is already done if the job was
+// cancelled before it started. Call done when the goroutine finishes.
+func (m *JobManager) start(job *Job) (ctx context.Context, done context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	job.mu.Lock()
+	if job.Status == JobStatusCancelled {
+		cancel()
+	}
+	job.cancel = cancel
+	job.mu.Unlock()
+
+	return ctx, cancel
+}
+
+// CancelJob stops a pending or running job. Files being processed are
+// finished or abandoned, the rest are skipped; the job keeps the result of
+// the files processed before it stopped. Returns false if no such job is
+// pending or running.
+func (m *JobManager) CancelJob(id string) bool {
+	job := m.GetJob(id)
+	if job == nil {
+		return false
+	}
+
+	job.mu.Lock()
+	if job.Status != JobStatusPending && job.Status != JobStatusRunning {
+		job.mu.Unlock()
+		return false
+	}
+	job.Status = JobStatusCancelled
+	now := time.Now()
+	job.CompletedAt = &now
+	if job.cancel != nil {
+		job.cancel()
+	}
+	job.mu.Unlock()
+
+	m.publishProgress(job)
+
+	// Persisted right away so a restart doesn't resume the job
+	if m.db != nil {
+		if err := m.db.UpdateJobStatus(context.Background(), id, string(JobStatusCancelled)); err != nil {
+			slog.Warn("failed to persist job cancellation", "job_id", id, "error", err)
+		}
+	}
+
+	slog.Info("job cancelled", "job_id", id)
+	return true
+}
+
+// cancelled reports whether the job was cancelled.
+func (j *Job) cancelled() bool {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.Status == JobStatusCancelled
+}
+
 // SetRunning marks job as running in DB.
 func (m *JobManager) SetRunning(ctx context.Context, job *Job) {
 	job.mu.Lock()
+	if job.Status == JobStatusCancelled {
+		job.mu.Unlock()
+		return
+	}
 	job.Status = JobStatusRunning
 	job.mu.Unlock()
 
+	m.publishProgress(job)
+
 	if m.db != nil {
 		if err := m.db.UpdateJobStatus(ctx, job.ID, string(JobStatusRunning)); err != nil {
 			slog.Warn("failed to set job running", "job_id", job.ID, "error", err)
@@ -171,46 +351,76 @@ func (m *JobManager) SetRunning(ctx context.Context, job *Job) {
 	}
 }
 
-// Complete marks job as completed with result.
+// Complete marks job as completed with result. A cancelled job stays
+// cancelled and keeps the result of the work done before it stopped.
 func (m *JobManager) Complete(ctx context.Context, job *Job, result *IngestResult) {
 	job.mu.Lock()
-	job.Status = JobStatusCompleted
+	cancelled := job.Status == JobStatusCancelled
+	if !cancelled {
+		job.Status = JobStatusCompleted
+		now := time.Now()
+		job.CompletedAt = &now
+	}
 	job.Result = result
-	now := time.Now()
-	job.CompletedAt = &now
+	progress := job.Progress
 	job.mu.Unlock()
 
+	m.publishProgress(job)
+
 	if m.db != nil {
 		resultMap := map[string]any{
 			"files_processed":   result.FilesProcessed,
 			"entities_created":  result.EntitiesCreated,
 			"chunks_created":    result.ChunksCreated,
+			"chunks_failed":     result.ChunksFailed,
 			"relations_created": result.RelationsCreated,
+			"entities_deleted":  result.EntitiesDeleted,
 			"errors":            result.Errors,
 		}
-		if err := m.db.CompleteJob(ctx, job.ID, resultMap); err != nil {
+		if cancelled {
+			// Progress updates stop with the job's context, so save the final count
+			if err := m.db.SetJobResult(ctx, job.ID, progress, resultMap); err != nil {
+				slog.Warn("failed to persist cancelled job result", "job_id", job.ID, "error", err)
+			}
+		} else if err := m.db.CompleteJob(ctx, job.ID, resultMap); err != nil {
 			slog.Warn("failed to persist job completion", "job_id", job.ID, "error", err)
 		}
 	}
 
+	m.webhook.Notify(job)
+
+	if cancelled {
+		slog.Info("cancelled job stopped", "job_id", job.ID, "files_processed", result.FilesProcessed, "errors", len(result.Errors))
+		return
+	}
 	slog.Info("job completed", "job_id", job.ID, "entities", result.EntitiesCreated, "errors", len(result.Errors))
 }
 
-// Fail marks job as failed with error.
+// Fail marks job as failed with error. A cancelled job stays cancelled.
 func (m *JobManager) Fail(ctx context.Context, job *Job, err error) {
 	job.mu.Lock()
+	if job.Status == JobStatusCancelled {
+		job.mu.Unlock()
+		m.webhook.Notify(job)
+		slog.Info("cancelled job stopped with error", "job_id", job.ID, "error", err)
+		return
+	}
 	job.Status = JobStatusFailed
 	job.Error = err.Error()
 	now := time.Now()
 	job.CompletedAt = &now
 	job.mu.Unlock()
 
+	m.publishProgress(job)
+
 	if m.db != nil {
 		if dbErr := m.db.FailJob(ctx, job.ID, err.Error()); dbErr != nil {
 			slog.Warn("failed to persist job failure", "job_id", job.ID, "error", dbErr)
 		}
 	}
 
+	m.webhook.Notify(job)
+
 	slog.Error("job failed", "job_id", job.ID, "error", err)
 }
 
@@ -239,6 +449,13 @@ func (m *JobManager) ResumeIncompleteJobs(ctx context.Context, ingestService *In
 			continue
 		}
 
+		// Only ingest jobs track files that can be resumed; maintenance jobs
+		// are cheap to re-trigger.
+		if dbJob.JobType != "ingest" {
+			slog.Info("skipping non-ingest job (requires re-trigger)", "job_id", jobID, "type", dbJob.JobType)
+			continue
+		}
+
 		// Skip content-based jobs - they can't be resumed because file content
 		// was provided by the client, not read from disk. User should re-run CLI.
 		if dbJob.Options != nil {
@@ -321,32 +538,16 @@ func (m *JobManager) ResumeIncompleteJobs(ctx context.Context, ingestService *In
 			}()
 
 			bgCtx := context.Background()
+			jobCtx, done := m.start(job)
+			defer done()
 
-			// Parse options from stored job
-			opts := IngestOptions{
-				Concurrency: m.concurrency,
-			}
-			if dbJob.Options != nil {
-				if labels, ok := dbJob.Options["labels"].([]any); ok {
-					for _, l := range labels {
-						if s, ok := l.(string); ok {
-							opts.Labels = append(opts.Labels, s)
-						}
-					}
-				}
-				if extractGraph, ok := dbJob.Options["extract_graph"].(bool); ok {
-					opts.ExtractGraph = extractGraph
-				}
-				if recursive, ok := dbJob.Options["recursive"].(bool); ok {
-					opts.Recursive = recursive
-				}
-			}
-
-			result, err := ingestService.ProcessFiles(bgCtx, m, job, pendingFiles, opts)
+			opts := resumeOptions(dbJob, m.concurrency)
+			result, err := ingestService.ProcessFiles(jobCtx, m, job, pendingFiles, opts)
 			if err != nil {
 				m.Fail(bgCtx, job, err)
 				return
 			}
+			ingestService.pruneAfterIngest(jobCtx, dbJob.DirPath, dbJob.Files, opts, result)
 			m.Complete(bgCtx, job, result)
 		}(job, pendingFiles, dbJob)
 	}
@@ -354,6 +555,45 @@ func (m *JobManager) ResumeIncompleteJobs(ctx context.Context, ingestService *In
 	return nil
 }
 
+// resumeOptions restores the ingest options a job was started with from its
+// stored record. concurrency applies unless the job set its own.
+func resumeOptions(dbJob models.IngestJob, concurrency int) IngestOptions {
+	opts := IngestOptions{
+		Concurrency: concurrency,
+		ConfigDir:   dbJob.DirPath,
+	}
+	if dbJob.Options == nil {
+		return opts
+	}
+	if labels, ok := dbJob.Options["labels"].([]any); ok {
+		for _, l := range labels {
+			if s, ok := l.(string); ok {
+				opts.Labels = append(opts.Labels, s)
+			}
+		}
+	}
+	if extractGraph, ok := dbJob.Options["extract_graph"].(bool); ok {
+		opts.ExtractGraph = extractGraph
+	}
+	if autoSummarize, ok := dbJob.Options["auto_summarize"].(bool); ok {
+		opts.AutoSummarize = autoSummarize
+	}
+	if recursive, ok := dbJob.Options["recursive"].(bool); ok {
+		opts.Recursive = recursive
+	}
+	if failFast, ok := dbJob.Options["fail_fast"].(bool); ok {
+		opts.FailFast = failFast
+	}
+	if prune, ok := dbJob.Options["prune"].(bool); ok {
+		opts.Prune = prune
+	}
+	opts.chunkingFromRecord(dbJob.Options)
+	if concurrency := recordInt(dbJob.Options, "concurrency"); concurrency > 0 {
+		opts.Concurrency = concurrency
+	}
+	return opts
+}
+
 // Snapshot returns a thread-safe copy of job state.
 func (j *Job) Snapshot() Job {
 	j.mu.RLock()
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// webhookServer returns a webhook whose deliveries are sent on the returned
+// channel.
+func webhookServer(t *testing.T) (*JobWebhook, <-chan JobWebhookPayload) {
+	t.Helper()
+	payloads := make(chan JobWebhookPayload, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload JobWebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		payloads <- payload
+	}))
+	t.Cleanup(server.Close)
+	return NewJobWebhook(server.URL, ""), payloads
+}
+
+// receive returns the next value of ch, failing the test after a timeout.
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a value")
+		var zero T
+		return zero
+	}
+}
+
+func TestCancelRunningJob(t *testing.T) {
+	ctx := context.Background()
+	webhook, payloads := webhookServer(t)
+	m := NewJobManager(1, nil, webhook)
+
+	job, err := m.CreateJob(ctx, "ingest", "docs", "/docs", []string{"a.md", "b.md"}, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	updates, unsubscribe, err := m.WatchJob(job.ID)
+	if err != nil {
+		t.Fatalf("WatchJob() error = %v", err)
+	}
+	defer unsubscribe()
+
+	// A job goroutine that processes one file, then waits for cancellation
+	running := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		jobCtx, done := m.start(job)
+		defer done()
+		m.SetRunning(ctx, job)
+		m.UpdateProgress(jobCtx, job, 1, 2)
+		close(running)
+		<-jobCtx.Done()
+		m.Complete(ctx, job, &IngestResult{FilesProcessed: 1})
+	}()
+	<-running
+
+	if !m.CancelJob(job.ID) {
+		t.Fatal("CancelJob() of running job = false, want true")
+	}
+	<-stopped
+
+	snapshot := job.Snapshot()
+	if snapshot.Status != JobStatusCancelled {
+		t.Errorf("status = %q, want %q", snapshot.Status, JobStatusCancelled)
+	}
+	if snapshot.CompletedAt == nil {
+		t.Error("CompletedAt not set")
+	}
+	if snapshot.Result == nil || snapshot.Result.FilesProcessed != 1 {
+		t.Errorf("result = %+v, want the file processed before cancelling", snapshot.Result)
+	}
+
+	// Watchers see the cancellation as the final state
+	var last JobProgress
+	for p := range updates {
+		last = p
+	}
+	if last.Status != JobStatusCancelled {
+		t.Errorf("last watched state = %+v, want cancelled", last)
+	}
+
+	// The webhook is notified once the job stops
+	payload := receive(t, payloads)
+	if payload.JobID != job.ID || payload.Status != JobStatusCancelled {
+		t.Errorf("webhook payload = %+v, want job %s cancelled", payload, job.ID)
+	}
+	if payload.Result == nil || payload.Result.FilesProcessed != 1 {
+		t.Errorf("webhook result = %+v, want 1 file processed", payload.Result)
+	}
+
+	if m.CancelJob(job.ID) {
+		t.Error("CancelJob() of cancelled job = true, want false")
+	}
+}
+
+func TestCancelJobBeforeStart(t *testing.T) {
+	ctx := context.Background()
+	webhook, payloads := webhookServer(t)
+	m := NewJobManager(1, nil, webhook)
+
+	job, err := m.CreateJob(ctx, "summarize", "", "", []string{"e1"}, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	if !m.CancelJob(job.ID) {
+		t.Fatal("CancelJob() of pending job = false, want true")
+	}
+
+	// The goroutine starting late gets a done context and can't revive the job
+	jobCtx, done := m.start(job)
+	defer done()
+	if jobCtx.Err() == nil {
+		t.Error("context of job cancelled before start not done")
+	}
+	m.SetRunning(ctx, job)
+	m.Fail(ctx, job, jobCtx.Err())
+
+	snapshot := job.Snapshot()
+	if snapshot.Status != JobStatusCancelled || snapshot.Error != "" {
+		t.Errorf("job = %q (error %q), want cancelled without error", snapshot.Status, snapshot.Error)
+	}
+	if payload := receive(t, payloads); payload.Status != JobStatusCancelled {
+		t.Errorf("webhook status = %q, want %q", payload.Status, JobStatusCancelled)
+	}
+}
+
+func TestCancelJobNotActive(t *testing.T) {
+	ctx := context.Background()
+	m := NewJobManager(1, nil, nil)
+
+	if m.CancelJob("missing") {
+		t.Error("CancelJob() of unknown job = true, want false")
+	}
+
+	tests := []struct {
+		name   string
+		finish func(job *Job)
+		want   JobStatus
+	}{
+		{"completed", func(job *Job) { m.Complete(ctx, job, &IngestResult{FilesProcessed: 1}) }, JobStatusCompleted},
+		{"failed", func(job *Job) { m.Fail(ctx, job, context.DeadlineExceeded) }, JobStatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := m.CreateJob(ctx, "ingest", "docs", "/docs", []string{"a.md"}, nil, nil)
+			if err != nil {
+				t.Fatalf("CreateJob() error = %v", err)
+			}
+			tt.finish(job)
+
+			if m.CancelJob(job.ID) {
+				t.Error("CancelJob() of finished job = true, want false")
+			}
+			if got := job.Snapshot(); got.Status != tt.want {
+				t.Errorf("status = %q, want %q", got.Status, tt.want)
+			}
+		})
+	}
+}
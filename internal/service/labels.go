@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// LabelNormalizer cleans labels before they are stored so re-ingesting
+// doesn't accumulate near-duplicates like "k8s", "Kubernetes" and
+// "kubernetes ". Labels are trimmed and lowercased, aliases are replaced by
+// their canonical label, and duplicates and empty labels are dropped.
+type LabelNormalizer struct {
+	aliases map[string]string
+}
+
+// NewLabelNormalizer creates a normalizer with aliases mapping a label to its
+// canonical form (e.g. "k8s" → "kubernetes"). Alias keys and values are
+// normalized themselves, so matching is case-insensitive.
+func NewLabelNormalizer(aliases map[string]string) *LabelNormalizer {
+	normalized := make(map[string]string, len(aliases))
+	for alias, canonical := range aliases {
+		alias, canonical = normalizeLabel(alias), normalizeLabel(canonical)
+		if alias == "" || canonical == "" {
+			continue
+		}
+		normalized[alias] = canonical
+	}
+	return &LabelNormalizer{aliases: normalized}
+}
+
+// Normalize returns the normalized labels in their original order, or labels
+// unchanged if n is nil.
+func (n *LabelNormalizer) Normalize(labels []string) []string {
+	if n == nil || labels == nil {
+		return labels
+	}
+	result := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = normalizeLabel(label)
+		if canonical, ok := n.aliases[label]; ok {
+			label = canonical
+		}
+		if label != "" && !slices.Contains(result, label) {
+			result = append(result, label)
+		}
+	}
+	return result
+}
+
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
+
+// NormalizeAllLabels applies label normalization to all existing entities
+// and their chunks. Returns the number of entities whose labels changed.
+func (s *EntityService) NormalizeAllLabels(ctx context.Context) (int, error) {
+	if s.labels == nil {
+		return 0, fmt.Errorf("label normalization is disabled (set KNOWHOW_NORMALIZE_LABELS=true)")
+	}
+
+	all, err := s.db.ListEntityLabels(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for id, labels := range all {
+		normalized := s.labels.Normalize(labels)
+		if slices.Equal(normalized, labels) {
+			continue
+		}
+		if err := s.db.SetEntityLabels(ctx, id, normalized); err != nil {
+			return updated, fmt.Errorf("normalize labels of %s: %w", id, err)
+		}
+		updated++
+		s.events.Written()
+	}
+
+	slog.Info("normalized labels", "entities", len(all), "updated", updated)
+	return updated, nil
+}
+
+// RenameLabel replaces oldLabel with newLabel on all entities and their
+// chunks (see db.Client.RenameLabel). With label normalization enabled,
+// newLabel is normalized first. Returns the number of entities changed.
+func (s *EntityService) RenameLabel(ctx context.Context, oldLabel, newLabel string) (int, error) {
+	if normalized := s.labels.Normalize([]string{newLabel}); len(normalized) == 1 {
+		newLabel = normalized[0]
+	}
+
+	renamed, err := s.db.RenameLabel(ctx, oldLabel, strings.TrimSpace(newLabel))
+	if err != nil {
+		return 0, err
+	}
+	if renamed > 0 {
+		s.events.Written()
+	}
+	slog.Info("renamed label", "old", oldLabel, "new", newLabel, "entities", renamed)
+	return renamed, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLabelNormalizerNormalize(t *testing.T) {
+	n := NewLabelNormalizer(map[string]string{
+		"K8s":   "Kubernetes",
+		" js ":  "javascript",
+		"empty": " ",
+	})
+
+	tests := []struct {
+		name   string
+		labels []string
+		want   []string
+	}{
+		{"nil stays nil", nil, nil},
+		{"empty", []string{}, []string{}},
+		{"trimmed and lowercased", []string{" Work ", "AUTH"}, []string{"work", "auth"}},
+		{"alias replaced", []string{"k8s"}, []string{"kubernetes"}},
+		{"alias matched case-insensitively", []string{" K8S"}, []string{"kubernetes"}},
+		{"alias key trimmed", []string{"JS"}, []string{"javascript"}},
+		{"duplicates after normalizing dropped", []string{"Kubernetes", "k8s", "kubernetes "}, []string{"kubernetes"}},
+		{"blank dropped", []string{"", "  ", "ops"}, []string{"ops"}},
+		{"order kept", []string{"b", "A", "c"}, []string{"b", "a", "c"}},
+		{"alias to blank ignored", []string{"empty"}, []string{"empty"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := n.Normalize(tt.labels)
+			if !slices.Equal(got, tt.want) || (got == nil) != (tt.want == nil) {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.labels, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("nil normalizer keeps labels", func(t *testing.T) {
+		var nilNormalizer *LabelNormalizer
+		labels := []string{" K8s "}
+		if got := nilNormalizer.Normalize(labels); !slices.Equal(got, labels) {
+			t.Errorf("Normalize() = %q, want %q", got, labels)
+		}
+	})
+}
+
+func TestNormalizeLabelFilters(t *testing.T) {
+	opts := SearchOptions{
+		Labels:      []string{"K8s", " Work"},
+		LabelGroups: [][]string{{"JS", "k8s"}, {"Ops"}},
+	}
+
+	s := &SearchService{labels: NewLabelNormalizer(map[string]string{"k8s": "kubernetes", "js": "javascript"})}
+	got := s.normalizeLabelFilters(opts)
+	if want := []string{"kubernetes", "work"}; !slices.Equal(got.Labels, want) {
+		t.Errorf("Labels = %q, want %q", got.Labels, want)
+	}
+	wantGroups := [][]string{{"javascript", "kubernetes"}, {"ops"}}
+	if !slices.EqualFunc(got.LabelGroups, wantGroups, slices.Equal) {
+		t.Errorf("LabelGroups = %q, want %q", got.LabelGroups, wantGroups)
+	}
+	// The caller's groups are left alone
+	if opts.LabelGroups[0][0] != "JS" {
+		t.Errorf("input groups modified: %q", opts.LabelGroups)
+	}
+
+	// Without normalization, filters are used as given
+	plain := (&SearchService{}).normalizeLabelFilters(opts)
+	if !slices.Equal(plain.Labels, opts.Labels) || !slices.EqualFunc(plain.LabelGroups, opts.LabelGroups, slices.Equal) {
+		t.Errorf("normalizeLabelFilters() without normalizer = %+v, want filters unchanged", plain)
+	}
+}
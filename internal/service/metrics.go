@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/raphaelgruber/memcp-go/internal/db"
+	"github.com/raphaelgruber/memcp-go/internal/metrics"
+)
+
+// MetricsPersister periodically writes metrics snapshots to the database
+// so runtime statistics survive server restarts.
+type MetricsPersister struct {
+	db        *db.Client
+	collector *metrics.Collector
+	interval  time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewMetricsPersister creates a persister that snapshots every interval.
+func NewMetricsPersister(dbClient *db.Client, collector *metrics.Collector, interval time.Duration) *MetricsPersister {
+	return &MetricsPersister{
+		db:        dbClient,
+		collector: collector,
+		interval:  interval,
+	}
+}
+
+// Start begins periodic persistence in the background.
+// Does nothing if the interval is not positive.
+func (p *MetricsPersister) Start() {
+	if p.interval <= 0 {
+		slog.Info("metrics snapshots disabled")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.Persist(ctx); err != nil {
+					slog.Warn("failed to persist metrics snapshot", "error", err)
+				}
+			}
+		}
+	}()
+
+	slog.Info("metrics snapshots enabled", "interval", p.interval)
+}
+
+// Persist writes the current metrics snapshot to the database.
+func (p *MetricsPersister) Persist(ctx context.Context) error {
+	return p.db.SaveMetricsSnapshot(ctx, p.collector.Snapshot())
+}
+
+// Reset persists the current snapshot and then clears all counters,
+// so no history is lost by resetting.
+func (p *MetricsPersister) Reset(ctx context.Context) error {
+	if err := p.Persist(ctx); err != nil {
+		return err
+	}
+	p.collector.Reset()
+	return nil
+}
+
+// Stop halts periodic persistence and writes a final snapshot.
+func (p *MetricsPersister) Stop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+
+	p.cancel()
+	<-p.done
+	p.cancel = nil
+
+	return p.Persist(ctx)
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// pruneAfterIngest runs PruneRemovedFiles for a directory ingest with
+// opts.Prune set and records the outcome in result. files are the Markdown
+// files collected from dirPath. Skipped if the ingest was cancelled.
+func (s *IngestService) pruneAfterIngest(ctx context.Context, dirPath string, files []string, opts IngestOptions, result *IngestResult) {
+	if !opts.Prune || ctx.Err() != nil {
+		return
+	}
+	deleted, err := s.PruneRemovedFiles(ctx, dirPath, files, opts.Recursive, opts.DryRun)
+	result.EntitiesDeleted = deleted
+	if err != nil {
+		slog.Warn("prune failed", "dir", dirPath, "error", err)
+		result.Errors = append(result.Errors, fmt.Sprintf("prune: %v", err))
+	}
+}
+
+// PruneRemovedFiles deletes scraped entities ingested from dirPath whose
+// file is no longer among files, the Markdown files just collected from it
+// (see removedFileEntities). Only entities with source "scrape" are
+// considered, so manually created entities are never touched. An entity is
+// kept if its file still exists, e.g. when it was skipped as too large.
+// With dryRun, nothing is deleted and the number of entities that would be
+// is returned.
+func (s *IngestService) PruneRemovedFiles(ctx context.Context, dirPath string, files []string, recursive, dryRun bool) (int, error) {
+	// Ingested paths are joined onto the cleaned directory; the trailing
+	// separator keeps "docs" from matching "docs-old"
+	prefix := filepath.Clean(dirPath)
+	if prefix == "." {
+		prefix = ""
+	} else {
+		prefix += string(filepath.Separator)
+	}
+
+	paths, err := s.db.GetEntitiesBySourcePathPrefix(ctx, prefix, models.SourceScrape)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, id := range removedFileEntities(paths, dirPath, files, recursive) {
+		path := paths[id]
+		if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+			if err != nil {
+				slog.Warn("can't check source file, keeping entity", "entity", id, "path", path, "error", err)
+			}
+			continue
+		}
+
+		if dryRun {
+			slog.Info("would prune entity of removed file", "entity", id, "path", path)
+			deleted++
+			continue
+		}
+		ok, err := s.entityService.Delete(ctx, id)
+		if err != nil {
+			return deleted, fmt.Errorf("delete %s: %w", id, err)
+		}
+		if ok {
+			slog.Info("pruned entity of removed file", "entity", id, "path", path)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// removedFileEntities returns the sorted IDs of the entities in paths
+// (entity ID → source path) whose path lies in the scope of a walk of
+// dirPath but isn't among the walked files. Paths outside the walk, such as
+// absolute paths under a relative dirPath, relative paths of uploaded
+// files under an absolute one, or files in subdirectories of a
+// non-recursive walk, are left alone.
+func removedFileEntities(paths map[string]string, dirPath string, files []string, recursive bool) []string {
+	dir := filepath.Clean(dirPath)
+	walked := make(map[string]bool, len(files))
+	for _, f := range files {
+		walked[filepath.Clean(f)] = true
+	}
+
+	var ids []string
+	for id, path := range paths {
+		path = filepath.Clean(path)
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || !filepath.IsLocal(rel) {
+			continue
+		}
+		if !recursive && filepath.Dir(rel) != "." {
+			continue
+		}
+		if !walked[path] {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
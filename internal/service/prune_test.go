@@ -0,0 +1,73 @@
+package service
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRemovedFileEntities(t *testing.T) {
+	paths := map[string]string{
+		"kept":       "docs/a.md",
+		"removed":    "docs/gone.md",
+		"nested":     "docs/sub/b.md",
+		"nested-rm":  "docs/sub/gone.md",
+		"sibling":    "docs-old/c.md",
+		"absolute":   "/srv/docs/gone.md",
+		"uploaded":   "notes/gone.md",
+		"parent":     "../docs/gone.md",
+		"unclean":    "./docs/a.md",
+		"abs-kept":   "/srv/kb/a.md",
+		"abs-remove": "/srv/kb/gone.md",
+	}
+
+	tests := []struct {
+		name      string
+		dirPath   string
+		files     []string
+		recursive bool
+		want      []string
+	}{
+		{
+			name:      "relative dir",
+			dirPath:   "docs",
+			files:     []string{"docs/a.md", "docs/sub/b.md"},
+			recursive: true,
+			want:      []string{"nested-rm", "removed"},
+		},
+		{
+			name:    "non-recursive leaves subdirectories alone",
+			dirPath: "docs/",
+			files:   []string{"docs/a.md"},
+			want:    []string{"removed"},
+		},
+		{
+			name:      "current dir skips absolute and parent paths",
+			dirPath:   ".",
+			files:     []string{"docs/a.md", "docs/sub/b.md", "docs-old/c.md"},
+			recursive: true,
+			want:      []string{"nested-rm", "removed", "uploaded"},
+		},
+		{
+			name:      "absolute dir skips uploaded relative paths",
+			dirPath:   "/srv/kb",
+			files:     []string{"/srv/kb/a.md"},
+			recursive: true,
+			want:      []string{"abs-remove"},
+		},
+		{
+			name:      "empty walk",
+			dirPath:   "/srv/empty",
+			recursive: true,
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := removedFileEntities(paths, tt.dirPath, tt.files, tt.recursive)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("removedFileEntities() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
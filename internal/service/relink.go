@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+	surrealmodels "github.com/surrealdb/surrealdb.go/pkg/models"
+)
+
+// RebuildRelationKeys recomputes relation unique keys and removes duplicate
+// relations (see db.Client.RebuildRelationKeys). Returns the number of
+// duplicates removed.
+func (s *EntityService) RebuildRelationKeys(ctx context.Context) (int, error) {
+	removed, err := s.db.RebuildRelationKeys(ctx)
+	if err != nil {
+		return 0, err
+	}
+	s.events.Written()
+	return removed, nil
+}
+
+// RelinkRelations repairs relations whose source or target entity no longer
+// exists, e.g. after an entity was renamed or merged under a new ID. A
+// missing endpoint is re-resolved to the entity whose name matches its old
+// ID; relations that can't be resolved, or would link an entity to itself,
+// are removed. Returns the number of relations repaired or removed.
+func (s *EntityService) RelinkRelations(ctx context.Context) (int, error) {
+	dangling, err := s.db.ListDanglingRelations(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(dangling) == 0 {
+		return 0, nil
+	}
+
+	names, err := s.db.ListEntityNames(ctx)
+	if err != nil {
+		return 0, err
+	}
+	resolve := entityResolver(names)
+
+	var stale []surrealmodels.RecordID
+	repaired := 0
+	for _, rel := range dangling {
+		from, to := models.MustRecordIDString(rel.In), models.MustRecordIDString(rel.Out)
+		newFrom, fromOK := resolve(from)
+		newTo, toOK := resolve(to)
+		stale = append(stale, rel.ID)
+
+		if !fromOK || !toOK || newFrom == newTo {
+			slog.Info("removing dangling relation", "from", from, "to", to, "rel_type", rel.RelType)
+			continue
+		}
+
+		strength, source := rel.Strength, rel.Source
+		if err := s.db.CreateRelation(ctx, models.RelationInput{
+			FromID:   newFrom,
+			ToID:     newTo,
+			RelType:  rel.RelType,
+			Strength: &strength,
+			Source:   &source,
+			Metadata: rel.Metadata,
+		}); err != nil {
+			return repaired, fmt.Errorf("relink %s -[%s]-> %s: %w", from, rel.RelType, to, err)
+		}
+		slog.Info("relinked relation", "from", from, "to", to, "new_from", newFrom, "new_to", newTo, "rel_type", rel.RelType)
+		repaired++
+	}
+
+	err = s.db.DeleteRelationsByID(ctx, stale)
+	s.events.Written()
+	if err != nil {
+		return repaired, err
+	}
+
+	slog.Info("relinked relations", "dangling", len(dangling), "repaired", repaired, "removed", len(dangling)-repaired)
+	return len(dangling), nil
+}
+
+// entityResolver returns a lookup from an entity reference (an existing ID,
+// a name or the slug of a name) to an entity ID. Names shared by several
+// entities don't resolve.
+func entityResolver(names map[string]string) func(ref string) (string, bool) {
+	byName := make(map[string]string, len(names))
+	ambiguous := make(map[string]bool)
+	for id, name := range names {
+		for _, key := range []string{strings.ToLower(name), models.Slugify(name)} {
+			if other, ok := byName[key]; ok && other != id {
+				ambiguous[key] = true
+			}
+			byName[key] = id
+		}
+	}
+
+	return func(ref string) (string, bool) {
+		if _, ok := names[ref]; ok {
+			return ref, true
+		}
+		for _, key := range []string{strings.ToLower(ref), models.Slugify(ref)} {
+			if id, ok := byName[key]; ok && !ambiguous[key] {
+				return id, true
+			}
+		}
+		return "", false
+	}
+}
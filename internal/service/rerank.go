@@ -0,0 +1,89 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/raphaelgruber/memcp-go/internal/llm"
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// rerankCandidateFactor is how many more candidates than requested are
+// fetched when reranking, so relevant results ranked low by RRF can move up.
+const rerankCandidateFactor = 3
+
+// validateRerank checks that a requested rerank can be served.
+func (s *SearchService) validateRerank(opts SearchOptions) error {
+	if opts.Rerank && s.reranker == nil {
+		return fmt.Errorf("rerank is not available: no rerank model configured")
+	}
+	return nil
+}
+
+// rerank reorders over-fetched search results by reranker relevance and,
+// unless diversify trims them afterwards, cuts them to the requested limit.
+// If the reranker fails, the original order is kept. doc extracts the text
+// scored for a result.
+func rerank[T any](ctx context.Context, reranker llm.Reranker, results []T, opts SearchOptions, doc func(T) string) []T {
+	if !opts.Rerank || reranker == nil {
+		return results
+	}
+
+	docs := make([]string, len(results))
+	for i, r := range results {
+		docs[i] = doc(r)
+	}
+
+	scores, err := reranker.Rerank(ctx, opts.Query, docs)
+	if err != nil {
+		slog.Warn("rerank failed, keeping fused order", "query", opts.Query, "candidates", len(results), "error", err)
+	} else {
+		order := make([]int, len(results))
+		for i := range order {
+			order[i] = i
+		}
+		// Stable, so equally scored results keep their fused order
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(scores[b], scores[a])
+		})
+		reranked := make([]T, len(results))
+		for i, idx := range order {
+			reranked[i] = results[idx]
+		}
+		results = reranked
+	}
+
+	if opts.Diversity <= 0 && len(results) > opts.limit() {
+		results = results[:opts.limit()]
+	}
+	return results
+}
+
+// entityRerankDoc is the text scored for an entity: name, summary and content.
+func entityRerankDoc(e models.Entity) string {
+	parts := []string{e.Name}
+	if e.Summary != nil && *e.Summary != "" {
+		parts = append(parts, *e.Summary)
+	}
+	if e.Content != nil && *e.Content != "" {
+		parts = append(parts, *e.Content)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// searchResultRerankDoc is the text scored for a search result: its matched
+// chunks if any, otherwise the entity's text.
+func searchResultRerankDoc(r models.EntitySearchResult) string {
+	if len(r.MatchedChunks) == 0 {
+		return entityRerankDoc(r.Entity)
+	}
+	parts := []string{r.Name}
+	for _, chunk := range r.MatchedChunks {
+		parts = append(parts, chunk.Content)
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -5,72 +5,302 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/raphaelgruber/memcp-go/internal/db"
 	"github.com/raphaelgruber/memcp-go/internal/llm"
 	"github.com/raphaelgruber/memcp-go/internal/models"
+	surrealmodels "github.com/surrealdb/surrealdb.go/pkg/models"
 )
 
 // SearchService handles search operations with LLM synthesis.
 type SearchService struct {
-	db       *db.Client
-	embedder *llm.Embedder
-	model    *llm.Model
+	db          *db.Client
+	embedder    *llm.Embedder
+	model       *llm.Model
+	models      *llm.ModelCache // per-request model overrides (nil disables)
+	contextOpts ContextOptions
+	cache       *AnswerCache // caches synthesized answers (nil disables)
+	reranker    llm.Reranker // reorders results on request (nil disables)
+	budget      *TokenBudget // caps tokens per conversation (nil disables)
+	decay       db.DecayConfig
+	labels      *LabelNormalizer // normalizes label filters like stored labels (nil = as given)
 }
 
 // NewSearchService creates a new search service.
-func NewSearchService(db *db.Client, embedder *llm.Embedder, model *llm.Model) *SearchService {
+// models provides per-request model overrides for Ask; nil disables them.
+// contextOpts controls how search results are assembled into LLM context.
+// cache caches answers of identical questions; nil disables caching.
+// reranker reorders results of searches asking for it; nil disables reranking.
+// budget rejects answers for conversations out of tokens; nil disables it.
+// decay weights the ranking of searches with ApplyDecay.
+// labels normalizes label filters the way stored labels are; nil leaves
+// them as given.
+func NewSearchService(db *db.Client, embedder *llm.Embedder, model *llm.Model, models *llm.ModelCache, contextOpts ContextOptions, cache *AnswerCache, reranker llm.Reranker, budget *TokenBudget, decay db.DecayConfig, labels *LabelNormalizer) *SearchService {
 	return &SearchService{
-		db:       db,
-		embedder: embedder,
-		model:    model,
+		db:          db,
+		embedder:    embedder,
+		model:       model,
+		models:      models,
+		contextOpts: contextOpts,
+		cache:       cache,
+		reranker:    reranker,
+		budget:      budget,
+		decay:       decay,
+		labels:      labels,
 	}
 }
 
+// Context assembly modes.
+const (
+	ContextModeChunks  = "chunks"  // Matched chunks with heading path; content preview if no chunk matched
+	ContextModeContent = "content" // Entity content regardless of chunk matches
+)
+
+// ContextOptions controls how search results are turned into LLM context.
+type ContextOptions struct {
+	Mode               string // ContextModeChunks (default) or ContextModeContent
+	MaxChunksPerSource int    // Top matched chunks used per entity (0 = all)
+	MaxCharsPerSource  int    // Content characters per entity, summary excluded (0 = unlimited)
+	MaxAlwaysInContext int    // Entities flagged always_in_context added before search results (0 = none)
+	MaxTotalChars      int    // Characters of the whole context, search results first, then related entities (0 = unlimited)
+}
+
+// Validate checks that the mode is empty or a known context mode.
+func (o ContextOptions) Validate() error {
+	switch o.Mode {
+	case "", ContextModeChunks, ContextModeContent:
+		return nil
+	}
+	return fmt.Errorf("unknown context mode %q (want %s or %s)", o.Mode, ContextModeChunks, ContextModeContent)
+}
+
 // SearchOptions configures a search operation.
 type SearchOptions struct {
-	Query        string
-	Labels       []string
-	Types        []string
-	VerifiedOnly bool
-	Limit        int
+	Mode            string // db.SearchMode*: hybrid (default), keyword or vector
+	Query           string
+	Labels          []string
+	LabelGroups     [][]string // Each group is OR'd; groups are AND'd together
+	HasMetadataKeys []string   // Only entities with these metadata keys set
+	Types           []string
+	VerifiedOnly    bool
+	MinConfidence   *float64 // Only entities with at least this confidence (nil = any)
+	ExcludeIDs      []string // Entity IDs to leave out (e.g. the entity itself, already shown results)
+	Language        string   // Only entities in this language (ISO 639-1 code)
+	Context         string   // Only entities in this project context ("" = all)
+	Diversity       float64  // 0-1: trade relevance for variety among results via MMR (0 = off)
+	Rerank          bool     // Reorder results by reranker relevance before applying Limit
+	ConversationID  string   // Conversation answers are for, for its token budget (Ask only)
+	ApplyDecay      bool     // Weight ranking by decay weight so recently accessed entities rank higher
+	ExpandGraph     bool     // Add summaries of entities related to the top results to the context (Ask only)
+	EfSearch        *int     // HNSW candidates explored per vector search (nil = db default)
+	OverFetchFactor int      // Candidates fetched per retriever as a multiple of the limit (0 = db default)
+	SortBy          string   // Sort*: order of the results, relevance (default), recency or popularity
+	Limit           int
+
+	// Provider and Model override the configured LLM for answer synthesis
+	// (Ask only). An empty Provider means the configured one.
+	Provider string
+	Model    string
+
+	// SystemPrompt replaces the default system prompt of answer synthesis
+	// (Ask only). PromptPreset instead names a system prompt stored as a
+	// template under models.SystemPromptPresetPrefix. Set at most one.
+	SystemPrompt string
+	PromptPreset string
+}
+
+// resolveSystemPrompt replaces opts.PromptPreset with the content of the
+// preset it names, so answers are cached by the prompt actually used.
+func (s *SearchService) resolveSystemPrompt(ctx context.Context, opts SearchOptions) (SearchOptions, error) {
+	if opts.PromptPreset == "" {
+		return opts, nil
+	}
+	if opts.SystemPrompt != "" {
+		return opts, fmt.Errorf("set either a system prompt or a prompt preset, not both")
+	}
+
+	preset, err := s.db.GetTemplate(ctx, models.SystemPromptPresetPrefix+opts.PromptPreset)
+	if err != nil {
+		return opts, fmt.Errorf("get prompt preset: %w", err)
+	}
+	if preset == nil {
+		return opts, fmt.Errorf("system prompt preset not found: %s (add it as template %q)",
+			opts.PromptPreset, models.SystemPromptPresetPrefix+opts.PromptPreset)
+	}
+	opts.SystemPrompt = preset.Content
+	opts.PromptPreset = ""
+	return opts, nil
+}
+
+// synthesisModel returns the LLM answering for opts: the override if one is
+// set, otherwise the configured model (nil when the LLM is disabled).
+func (s *SearchService) synthesisModel(opts SearchOptions) (*llm.Model, error) {
+	if opts.Provider == "" && opts.Model == "" {
+		return s.model, nil
+	}
+	if s.models == nil {
+		return nil, fmt.Errorf("model overrides are not available")
+	}
+	return s.models.Get(opts.Provider, opts.Model)
+}
+
+// validateSearchMode checks that mode is empty or a known search mode that
+// can be served: vector search needs an embedding model.
+func (s *SearchService) validateSearchMode(mode string) error {
+	switch mode {
+	case "", db.SearchModeHybrid, db.SearchModeKeyword:
+		return nil
+	case db.SearchModeVector:
+		if s.embedder == nil {
+			return fmt.Errorf("vector search is not available: no embedding model configured")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown search mode %q (want %s, %s or %s)", mode, db.SearchModeHybrid, db.SearchModeKeyword, db.SearchModeVector)
+}
+
+// validateMinConfidence checks that a MinConfidence option is within 0-1.
+func validateMinConfidence(minConfidence *float64) error {
+	if minConfidence != nil && (*minConfidence < 0 || *minConfidence > 1) {
+		return fmt.Errorf("min confidence must be between 0 and 1, got %g", *minConfidence)
+	}
+	return nil
+}
+
+// queryEmbedding embeds the query of a search. Keyword searches don't
+// use it, so they skip the embedding request.
+func (s *SearchService) queryEmbedding(ctx context.Context, opts SearchOptions) ([]float32, error) {
+	if s.embedder == nil || opts.Mode == db.SearchModeKeyword {
+		return nil, nil
+	}
+	embedding, err := s.embedder.Embed(ctx, opts.Query)
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	return embedding, nil
+}
+
+// limit returns the number of results to return (default 10).
+func (o SearchOptions) limit() int {
+	if o.Limit <= 0 {
+		return 10
+	}
+	return o.Limit
+}
+
+// normalizeLabelFilters returns opts with its label filters normalized
+// like stored labels, so "K8s" finds entities stored as "kubernetes".
+func (s *SearchService) normalizeLabelFilters(opts SearchOptions) SearchOptions {
+	opts.Labels = s.labels.Normalize(opts.Labels)
+	if s.labels != nil && opts.LabelGroups != nil {
+		groups := make([][]string, len(opts.LabelGroups))
+		for i, group := range opts.LabelGroups {
+			groups[i] = s.labels.Normalize(group)
+		}
+		opts.LabelGroups = groups
+	}
+	return opts
+}
+
+// decayCandidateFactor is how many more candidates than requested are
+// fetched when weighting by decay, so fresh results ranked just below the
+// limit can move into it.
+const decayCandidateFactor = 3
+
+// trimResults cuts results to the requested limit, dropping the candidates
+// fetched extra for diversify, rerank or decay that weren't picked.
+func trimResults[T any](results []T, opts SearchOptions) []T {
+	if len(results) > opts.limit() {
+		return results[:opts.limit()]
+	}
+	return results
+}
+
+// toDB converts search options to database search options with the query embedding.
+// With Diversity, ApplyDecay or Rerank set, extra candidates are fetched for
+// diversify, decay weighting and rerank to choose from (see trimResults).
+// entityContext returns the project context filter of the search, nil for
+// all entities.
+func (o SearchOptions) entityContext() *string {
+	if o.Context == "" {
+		return nil
+	}
+	return &o.Context
+}
+
+func (o SearchOptions) toDB(embedding []float32) db.SearchOptions {
+	limit := o.Limit
+	if o.Diversity > 0 {
+		limit = o.limit() * diversityCandidateFactor
+	}
+	if o.ApplyDecay {
+		limit = o.limit() * decayCandidateFactor
+	}
+	if o.Rerank {
+		limit = o.limit() * rerankCandidateFactor
+	}
+	entityContext := o.entityContext()
+	return db.SearchOptions{
+		Mode:            o.Mode,
+		Query:           o.Query,
+		Embedding:       embedding,
+		Labels:          o.Labels,
+		LabelGroups:     o.LabelGroups,
+		HasMetadataKeys: o.HasMetadataKeys,
+		Types:           o.Types,
+		VerifiedOnly:    o.VerifiedOnly,
+		MinConfidence:   o.MinConfidence,
+		ExcludeIDs:      o.ExcludeIDs,
+		Language:        o.Language,
+		Context:         entityContext,
+		ApplyDecay:      o.ApplyDecay,
+		Limit:           limit,
+		EfSearch:        o.EfSearch,
+		OverFetchFactor: o.OverFetchFactor,
+	}
 }
 
 // Search performs hybrid search without LLM synthesis.
 func (s *SearchService) Search(ctx context.Context, opts SearchOptions) ([]models.Entity, error) {
-	// Generate query embedding
-	var embedding []float32
-	if s.embedder != nil {
-		var err error
-		embedding, err = s.embedder.Embed(ctx, opts.Query)
-		if err != nil {
-			return nil, fmt.Errorf("embed query: %w", err)
-		}
+	if err := validateDiversity(opts.Diversity); err != nil {
+		return nil, err
+	}
+	if err := s.validateSearchMode(opts.Mode); err != nil {
+		return nil, err
+	}
+	if err := validateMinConfidence(opts.MinConfidence); err != nil {
+		return nil, err
+	}
+	if err := validateSortBy(opts.SortBy); err != nil {
+		return nil, err
+	}
+	if err := s.validateRerank(opts); err != nil {
+		return nil, err
 	}
 
-	dbOpts := db.SearchOptions{
-		Query:        opts.Query,
-		Embedding:    embedding,
-		Labels:       opts.Labels,
-		Types:        opts.Types,
-		VerifiedOnly: opts.VerifiedOnly,
-		Limit:        opts.Limit,
+	embedding, err := s.queryEmbedding(ctx, opts)
+	if err != nil {
+		return nil, err
 	}
 
+	dbOpts := s.normalizeLabelFilters(opts).toDB(embedding)
+	dbOpts.Decay = s.decay
+
 	results, err := s.db.HybridSearch(ctx, dbOpts)
 	if err != nil {
 		return nil, err
 	}
+	results = rerank(ctx, s.reranker, results, opts, entityRerankDoc)
+	results = diversify(results, opts, func(e models.Entity) []float32 { return e.Embedding })
+	results = trimResults(results, opts)
+	sortResults(results, opts, func(e models.Entity) models.Entity { return e })
 
 	// Update access for returned entities
 	for _, entity := range results {
-		if idStr, err := models.RecordIDString(entity.ID); err == nil {
-			if err := s.db.UpdateEntityAccess(ctx, idStr); err != nil {
-				slog.Warn("failed to update entity access", "entity", idStr, "error", err)
-			}
-		} else {
-			slog.Warn("failed to get entity ID for access tracking", "error", err)
-		}
+		s.updateAccess(ctx, entity.ID)
 	}
 
 	return results, nil
@@ -78,46 +308,192 @@ func (s *SearchService) Search(ctx context.Context, opts SearchOptions) ([]model
 
 // SearchWithChunks performs search including chunk matches.
 func (s *SearchService) SearchWithChunks(ctx context.Context, opts SearchOptions) ([]models.EntitySearchResult, error) {
-	// Generate query embedding
-	var embedding []float32
-	if s.embedder != nil {
-		var err error
-		embedding, err = s.embedder.Embed(ctx, opts.Query)
-		if err != nil {
-			return nil, fmt.Errorf("embed query: %w", err)
-		}
+	results, err := s.rankWithChunks(ctx, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	dbOpts := db.SearchOptions{
-		Query:        opts.Query,
-		Embedding:    embedding,
-		Labels:       opts.Labels,
-		Types:        opts.Types,
-		VerifiedOnly: opts.VerifiedOnly,
-		Limit:        opts.Limit,
+	// Update access for returned entities
+	for _, result := range results {
+		s.updateAccess(ctx, result.ID)
 	}
 
-	results, err := s.db.SearchWithChunks(ctx, dbOpts)
+	return results, nil
+}
+
+// SearchChunks returns the best matching chunks across all entities, not
+// grouped by entity. Diversity, rerank and decay don't apply to chunks and
+// are ignored. Each entity contributing a chunk counts as accessed once.
+func (s *SearchService) SearchChunks(ctx context.Context, opts SearchOptions) ([]models.ChunkMatch, error) {
+	if err := s.validateSearchMode(opts.Mode); err != nil {
+		return nil, err
+	}
+	if err := validateMinConfidence(opts.MinConfidence); err != nil {
+		return nil, err
+	}
+
+	embedding, err := s.queryEmbedding(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update access for returned entities
+	// Nothing picks among extra candidates here, so none are fetched
+	dbOpts := s.normalizeLabelFilters(opts).toDB(embedding)
+	dbOpts.Limit = opts.Limit
+	matches, err := s.db.SearchChunks(ctx, dbOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	accessed := make(map[string]bool, len(matches))
+	for _, m := range matches {
+		if accessed[m.EntityID] {
+			continue
+		}
+		accessed[m.EntityID] = true
+		if err := s.db.UpdateEntityAccess(ctx, m.EntityID); err != nil {
+			slog.Warn("failed to update entity access", "entity", m.EntityID, "error", err)
+		}
+	}
+	return matches, nil
+}
+
+// SearchStream performs search including chunk matches and passes each
+// result to onResult in rank order. This is not incremental retrieval: all
+// results are ranked first, like SearchWithChunks. Only delivery is
+// per result, without waiting for the access tracking writes
+// SearchWithChunks does up front. Stops at the first error returned by
+// onResult.
+func (s *SearchService) SearchStream(ctx context.Context, opts SearchOptions, onResult func(models.EntitySearchResult) error) error {
+	results, err := s.rankWithChunks(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return sendResults(results, onResult, func(result models.EntitySearchResult) {
+		s.updateAccess(ctx, result.ID)
+	})
+}
+
+// sendResults passes results to onResult in order and calls sent after
+// each one it accepted. Stops at the first error returned by onResult.
+func sendResults(results []models.EntitySearchResult, onResult func(models.EntitySearchResult) error, sent func(models.EntitySearchResult)) error {
 	for _, result := range results {
-		if idStr, err := models.RecordIDString(result.ID); err == nil {
-			if err := s.db.UpdateEntityAccess(ctx, idStr); err != nil {
-				slog.Warn("failed to update entity access", "entity", idStr, "error", err)
-			}
-		} else {
-			slog.Warn("failed to get entity ID for access tracking", "error", err)
+		if err := onResult(result); err != nil {
+			return err
 		}
+		sent(result)
+	}
+	return nil
+}
+
+// updateAccess records that an entity was returned by a search. Failures
+// are logged only.
+func (s *SearchService) updateAccess(ctx context.Context, id surrealmodels.RecordID) {
+	idStr, err := models.RecordIDString(id)
+	if err != nil {
+		slog.Warn("failed to get entity ID for access tracking", "error", err)
+		return
+	}
+	if err := s.db.UpdateEntityAccess(ctx, idStr); err != nil {
+		slog.Warn("failed to update entity access", "entity", idStr, "error", err)
+	}
+}
+
+// rankWithChunks runs the search of SearchWithChunks, including rerank and
+// diversification, without tracking access.
+func (s *SearchService) rankWithChunks(ctx context.Context, opts SearchOptions) ([]models.EntitySearchResult, error) {
+	if err := validateDiversity(opts.Diversity); err != nil {
+		return nil, err
+	}
+	if err := s.validateSearchMode(opts.Mode); err != nil {
+		return nil, err
+	}
+	if err := validateMinConfidence(opts.MinConfidence); err != nil {
+		return nil, err
+	}
+	if err := validateSortBy(opts.SortBy); err != nil {
+		return nil, err
+	}
+	if err := s.validateRerank(opts); err != nil {
+		return nil, err
+	}
+
+	embedding, err := s.queryEmbedding(ctx, opts)
+	if err != nil {
+		return nil, err
 	}
 
+	dbOpts := s.normalizeLabelFilters(opts).toDB(embedding)
+	dbOpts.Decay = s.decay
+
+	results, err := s.db.SearchWithChunks(ctx, dbOpts)
+	if err != nil {
+		return nil, err
+	}
+	results = rerank(ctx, s.reranker, results, opts, searchResultRerankDoc)
+	results = diversify(results, opts, func(r models.EntitySearchResult) []float32 { return r.Embedding })
+	results = trimResults(results, opts)
+	sortResults(results, opts, func(r models.EntitySearchResult) models.Entity { return r.Entity })
 	return results, nil
 }
 
+// contextResults runs the search for an ask and puts the entities flagged
+// always_in_context first, up to MaxAlwaysInContext. Only flagged entities of
+// the searched context are added. A flagged entity that the search also
+// found keeps its matched chunks and isn't repeated.
+func (s *SearchService) contextResults(ctx context.Context, opts SearchOptions) ([]models.EntitySearchResult, error) {
+	results, err := s.SearchWithChunks(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if s.contextOpts.MaxAlwaysInContext <= 0 {
+		return results, nil
+	}
+
+	always, err := s.db.ListAlwaysInContext(ctx, s.contextOpts.MaxAlwaysInContext, opts.entityContext())
+	if err != nil {
+		slog.Warn("failed to load always-in-context entities", "error", err)
+		return results, nil
+	}
+	if len(always) == 0 {
+		return results, nil
+	}
+
+	found := make(map[string]int, len(results))
+	for i, r := range results {
+		if id, err := models.RecordIDString(r.ID); err == nil {
+			found[id] = i
+		}
+	}
+
+	combined := make([]models.EntitySearchResult, 0, len(always)+len(results))
+	used := make(map[int]bool, len(always))
+	for _, e := range always {
+		id, err := models.RecordIDString(e.ID)
+		if err != nil {
+			slog.Warn("failed to get always-in-context entity ID", "error", err)
+			continue
+		}
+		if i, ok := found[id]; ok {
+			combined = append(combined, results[i])
+			used[i] = true
+			continue
+		}
+		combined = append(combined, models.EntitySearchResult{Entity: e})
+	}
+	for i, r := range results {
+		if !used[i] {
+			combined = append(combined, r)
+		}
+	}
+	return combined, nil
+}
+
 // buildSearchContext formats search results into a context string for LLM consumption.
-func buildSearchContext(results []models.EntitySearchResult) string {
+// Each entity contributes its summary plus either its top matched chunks or its
+// content, truncated to opts.MaxCharsPerSource. The whole context is cut to
+// opts.MaxTotalChars, dropping the results that no longer fit.
+func buildSearchContext(results []models.EntitySearchResult, opts ContextOptions) string {
 	contextParts := make([]string, 0, len(results))
 	for _, result := range results {
 		part := fmt.Sprintf("## %s (%s)\n", result.Name, result.Type)
@@ -125,62 +501,294 @@ func buildSearchContext(results []models.EntitySearchResult) string {
 			part += *result.Summary + "\n"
 		}
 
-		if len(result.MatchedChunks) > 0 {
-			for _, chunk := range result.MatchedChunks {
+		if opts.Mode != ContextModeContent && len(result.MatchedChunks) > 0 {
+			chunks := result.MatchedChunks
+			if opts.MaxChunksPerSource > 0 && len(chunks) > opts.MaxChunksPerSource {
+				chunks = chunks[:opts.MaxChunksPerSource]
+			}
+
+			budget := opts.MaxCharsPerSource
+			for _, chunk := range chunks {
+				if opts.MaxCharsPerSource > 0 && budget <= 0 {
+					break
+				}
 				if chunk.HeadingPath != nil {
 					part += fmt.Sprintf("\n### %s\n", *chunk.HeadingPath)
 				}
-				part += chunk.Content + "\n"
+				content := chunk.Content
+				if opts.MaxCharsPerSource > 0 {
+					content = truncateContent(content, budget)
+					budget -= len(content)
+				}
+				part += content + "\n"
 			}
 		} else if result.Content != nil {
-			content := *result.Content
-			if len(content) > 500 {
-				content = content[:500] + "..."
-			}
-			part += content + "\n"
+			part += truncateContent(*result.Content, opts.MaxCharsPerSource) + "\n"
 		}
 
 		contextParts = append(contextParts, part)
 	}
-	return strings.Join(contextParts, "\n---\n")
+	return joinContext(contextParts, opts.MaxTotalChars)
+}
+
+// contextSeparator separates the entities in LLM context.
+const contextSeparator = "\n---\n"
+
+// joinContext joins context parts, stopping before the total length exceeds
+// maxChars (0 = unlimited). The first part is truncated rather than dropped.
+func joinContext(parts []string, maxChars int) string {
+	var b strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			part = contextSeparator + part
+		}
+		if maxChars > 0 && b.Len()+len(part) > maxChars {
+			if i == 0 {
+				b.WriteString(truncateContent(part, maxChars))
+			}
+			break
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+const (
+	expandGraphHits    = 5   // Top search results whose related entities are added with ExpandGraph
+	relatedContentPeek = 300 // Content characters shown for related entities without a summary
+)
+
+// searchContext builds the LLM context for an answer from search results.
+// With opts.ExpandGraph, the entities one hop away from the top results (in
+// the searched context) are added with their summaries, in the budget the
+// results leave.
+func (s *SearchService) searchContext(ctx context.Context, results []models.EntitySearchResult, opts SearchOptions) string {
+	searchContext := buildSearchContext(results, s.contextOpts)
+	if !opts.ExpandGraph {
+		return searchContext
+	}
+
+	remaining := 0
+	if s.contextOpts.MaxTotalChars > 0 {
+		remaining = s.contextOpts.MaxTotalChars - len(searchContext) - len(contextSeparator)
+		if remaining <= 0 {
+			return searchContext
+		}
+	}
+
+	related := s.relatedEntities(ctx, results, opts.entityContext())
+	if len(related) == 0 {
+		return searchContext
+	}
+	parts := make([]string, len(related))
+	for i, r := range related {
+		part := fmt.Sprintf("## %s (%s), related to %s\n", r.entity.Name, r.entity.Type, r.via)
+		if r.entity.Summary != nil && *r.entity.Summary != "" {
+			part += *r.entity.Summary + "\n"
+		} else if r.entity.Content != nil {
+			part += truncateContent(*r.entity.Content, relatedContentPeek) + "\n"
+		}
+		parts[i] = part
+	}
+	slog.Debug("expanded ask context with related entities", "related", len(related))
+	return searchContext + contextSeparator + joinContext(parts, remaining)
+}
+
+// relatedEntity is an entity linked to a search result.
+type relatedEntity struct {
+	entity models.Entity
+	via    string // name of the search result it's related to
+}
+
+// relatedEntities returns the entities one relation away from the top
+// search results that aren't results themselves, in result order. With
+// entityContext set, only entities of that context are returned. Lookup
+// failures are logged and skipped.
+func (s *SearchService) relatedEntities(ctx context.Context, results []models.EntitySearchResult, entityContext *string) []relatedEntity {
+	seen := make(map[string]bool, len(results))
+	for _, r := range results {
+		if id, err := models.RecordIDString(r.ID); err == nil {
+			seen[id] = true
+		}
+	}
+
+	var related []relatedEntity
+	for _, r := range results[:min(len(results), expandGraphHits)] {
+		id, err := models.RecordIDString(r.ID)
+		if err != nil {
+			slog.Warn("failed to get search result ID for graph expansion", "entity", r.Name, "error", err)
+			continue
+		}
+		neighbors, err := s.db.GetNeighborsIn(ctx, id, 1, nil, entityContext)
+		if err != nil {
+			slog.Warn("failed to get related entities", "entity", id, "error", err)
+			continue
+		}
+		for _, n := range neighbors {
+			nid, err := models.RecordIDString(n.ID)
+			if err != nil || seen[nid] {
+				continue
+			}
+			seen[nid] = true
+			related = append(related, relatedEntity{entity: n.Entity, via: r.Name})
+		}
+	}
+	return related
+}
+
+// truncateContent cuts s to at most maxChars bytes on a UTF-8 boundary and
+// marks the cut with "...". maxChars <= 0 disables truncation.
+func truncateContent(s string, maxChars int) string {
+	if maxChars <= 0 || len(s) <= maxChars {
+		return s
+	}
+	cut := maxChars
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
 }
 
 // Ask performs search and synthesizes an answer using LLM.
 // When no LLM is configured, returns the raw search context.
 func (s *SearchService) Ask(ctx context.Context, query string, opts SearchOptions) (string, error) {
+	answer, _, err := s.AskWithUsage(ctx, query, opts)
+	return answer, err
+}
+
+// AskWithUsage is like Ask but also returns the LLM token usage.
+// Usage is zero when no LLM call was made, including cached answers.
+func (s *SearchService) AskWithUsage(ctx context.Context, query string, opts SearchOptions) (string, llm.Usage, error) {
+	model, err := s.synthesisModel(opts)
+	if err != nil {
+		return "", llm.Usage{}, err
+	}
+	opts, err = s.resolveSystemPrompt(ctx, opts)
+	if err != nil {
+		return "", llm.Usage{}, err
+	}
+
 	opts.Query = query
 	if opts.Limit == 0 {
 		opts.Limit = 20
 	}
 
-	results, err := s.SearchWithChunks(ctx, opts)
+	started := time.Now()
+	cacheKey := ""
+	if model != nil && s.cache != nil {
+		cacheKey = answerCacheKey(opts)
+		if answer, ok := s.cache.get(cacheKey); ok {
+			slog.Debug("answer cache hit", "query", query)
+			return answer, llm.Usage{}, nil
+		}
+	}
+
+	results, err := s.contextResults(ctx, opts)
 	if err != nil {
-		return "", fmt.Errorf("search: %w", err)
+		return "", llm.Usage{}, fmt.Errorf("search: %w", err)
 	}
 
 	if len(results) == 0 {
-		return "No relevant knowledge found for this query.", nil
+		return "No relevant knowledge found for this query.", llm.Usage{}, nil
 	}
+	s.recordAnswerSources(ctx, results)
 
-	searchContext := buildSearchContext(results)
+	searchContext := s.searchContext(ctx, results, opts)
 
-	if s.model == nil {
+	if model == nil {
 		slog.Info("returning raw search context (LLM disabled)", "query", query, "result_count", len(results))
-		return searchContext, nil
+		return searchContext, llm.Usage{}, nil
+	}
+
+	ctx, err = s.budgeted(ctx, opts.ConversationID, query+searchContext)
+	if err != nil {
+		return "", llm.Usage{}, err
+	}
+	answer, usage, err := model.SynthesizeAnswerWithUsage(ctx, query, searchContext, opts.SystemPrompt)
+	if err != nil {
+		return "", usage, err
+	}
+	s.cache.put(cacheKey, answer, started)
+	return answer, usage, nil
+}
+
+// maxAskBatchQuestions caps the number of questions in a single AskBatch call.
+const maxAskBatchQuestions = 50
+
+// BatchAnswer is the result for one question of an AskBatch call.
+type BatchAnswer struct {
+	Question string
+	Answer   string
+	Usage    llm.Usage
+	Error    string // set if this question failed; other answers are unaffected
+}
+
+// AskBatch answers multiple questions with the same search options, running at
+// most concurrency questions at a time. Answers are returned in question order.
+func (s *SearchService) AskBatch(ctx context.Context, questions []string, opts SearchOptions, concurrency int) ([]BatchAnswer, error) {
+	if len(questions) > maxAskBatchQuestions {
+		return nil, fmt.Errorf("too many questions: %d (max %d)", len(questions), maxAskBatchQuestions)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	answers := make([]BatchAnswer, len(questions))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, question := range questions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			answers[i].Question = question
+			answer, usage, err := s.AskWithUsage(ctx, question, opts)
+			if err != nil {
+				slog.Warn("batch question failed", "question", question, "error", err)
+				answers[i].Error = err.Error()
+				return
+			}
+			answers[i].Answer = answer
+			answers[i].Usage = usage
+		}()
 	}
+	wg.Wait()
 
-	return s.model.SynthesizeAnswer(ctx, query, searchContext)
+	return answers, nil
 }
 
 // AskStream performs search and streams the LLM-synthesized answer token by token.
 // When no LLM is configured, sends the raw search context as a single token event.
+// Cached answers are replayed word by word.
 func (s *SearchService) AskStream(ctx context.Context, query string, opts SearchOptions, onToken func(token string) error) error {
+	model, err := s.synthesisModel(opts)
+	if err != nil {
+		return err
+	}
+	opts, err = s.resolveSystemPrompt(ctx, opts)
+	if err != nil {
+		return err
+	}
+
 	opts.Query = query
 	if opts.Limit == 0 {
 		opts.Limit = 20
 	}
 
-	results, err := s.SearchWithChunks(ctx, opts)
+	started := time.Now()
+	cacheKey := ""
+	if model != nil && s.cache != nil {
+		cacheKey = answerCacheKey(opts)
+		if answer, ok := s.cache.get(cacheKey); ok {
+			slog.Debug("answer cache hit", "query", query)
+			return replayAnswer(answer, onToken)
+		}
+	}
+
+	results, err := s.contextResults(ctx, opts)
 	if err != nil {
 		return fmt.Errorf("search: %w", err)
 	}
@@ -188,15 +796,28 @@ func (s *SearchService) AskStream(ctx context.Context, query string, opts Search
 	if len(results) == 0 {
 		return onToken("No relevant knowledge found for this query.")
 	}
+	s.recordAnswerSources(ctx, results)
 
-	searchContext := buildSearchContext(results)
+	searchContext := s.searchContext(ctx, results, opts)
 
-	if s.model == nil {
+	if model == nil {
 		slog.Info("streaming raw search context (LLM disabled)", "query", query, "result_count", len(results))
 		return onToken(searchContext)
 	}
 
-	return s.model.SynthesizeAnswerStream(ctx, query, searchContext, onToken)
+	ctx, err = s.budgeted(ctx, opts.ConversationID, query+searchContext)
+	if err != nil {
+		return err
+	}
+	var answer strings.Builder
+	if err := model.SynthesizeAnswerStream(ctx, query, searchContext, opts.SystemPrompt, func(token string) error {
+		answer.WriteString(token)
+		return onToken(token)
+	}); err != nil {
+		return err
+	}
+	s.cache.put(cacheKey, answer.String(), started)
+	return nil
 }
 
 // AskStreamMultiTurn performs search and streams LLM answer with multi-turn conversation history.
@@ -216,14 +837,14 @@ func (s *SearchService) AskStreamMultiTurn(
 		opts.Limit = 20
 	}
 
-	results, err := s.SearchWithChunks(ctx, opts)
+	results, err := s.contextResults(ctx, opts)
 	if err != nil {
 		return fmt.Errorf("search: %w", err)
 	}
 
 	searchContext := ""
 	if len(results) > 0 {
-		searchContext = buildSearchContext(results)
+		searchContext = s.searchContext(ctx, results, opts)
 	}
 
 	systemPrompt := `You are a helpful knowledge assistant. Answer the user's question based on the provided context.
@@ -236,12 +857,24 @@ Be concise and cite specific information from the context where relevant.`
 		systemPrompt += "\n\nNo relevant knowledge was found for this query. Let the user know."
 	}
 
+	prompt := systemPrompt + query
+	for _, msg := range history {
+		prompt += msg.Content
+	}
+	ctx, err = s.budgeted(ctx, opts.ConversationID, prompt)
+	if err != nil {
+		return err
+	}
 	return s.model.GenerateWithSystemStreamMultiTurn(ctx, systemPrompt, history, query, onToken)
 }
 
 // AskWithTemplate fills a template with knowledge from search.
 func (s *SearchService) AskWithTemplate(ctx context.Context, query string, templateName string, opts SearchOptions) (string, error) {
-	if s.model == nil {
+	model, err := s.synthesisModel(opts)
+	if err != nil {
+		return "", err
+	}
+	if model == nil {
 		return "", fmt.Errorf("template filling requires an LLM (set KNOWHOW_LLM_PROVIDER)")
 	}
 
@@ -260,7 +893,7 @@ func (s *SearchService) AskWithTemplate(ctx context.Context, query string, templ
 		opts.Limit = 30 // More context for template filling
 	}
 
-	results, err := s.SearchWithChunks(ctx, opts)
+	results, err := s.contextResults(ctx, opts)
 	if err != nil {
 		return "", fmt.Errorf("search: %w", err)
 	}
@@ -268,6 +901,7 @@ func (s *SearchService) AskWithTemplate(ctx context.Context, query string, templ
 	if len(results) == 0 {
 		return "", fmt.Errorf("no relevant knowledge found for %q", query)
 	}
+	s.recordAnswerSources(ctx, results)
 
 	// Build knowledge context
 	knowledgeParts := make([]string, 0, len(results))
@@ -288,5 +922,5 @@ func (s *SearchService) AskWithTemplate(ctx context.Context, query string, templ
 	knowledge := strings.Join(knowledgeParts, "\n---\n")
 
 	// Fill template with LLM
-	return s.model.FillTemplate(ctx, template.Content, knowledge)
+	return model.FillTemplate(ctx, template.Content, knowledge)
 }
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raphaelgruber/memcp-go/internal/db"
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+func TestJoinContext(t *testing.T) {
+	parts := []string{"aaaa", "bbbb", "cccc"}
+
+	tests := []struct {
+		name     string
+		maxChars int
+		want     string
+	}{
+		{"unlimited", 0, "aaaa" + contextSeparator + "bbbb" + contextSeparator + "cccc"},
+		{"drops parts past the budget", 4 + len(contextSeparator) + 4 + 2, "aaaa" + contextSeparator + "bbbb"},
+		{"truncates the first part", 2, "aa..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := joinContext(parts, tt.maxChars); got != tt.want {
+				t.Errorf("joinContext() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildSearchContextBudget(t *testing.T) {
+	content := strings.Repeat("x", 100)
+	results := []models.EntitySearchResult{
+		{Entity: models.Entity{Name: "first", Type: "note", Content: &content}},
+		{Entity: models.Entity{Name: "second", Type: "note", Content: &content}},
+	}
+
+	got := buildSearchContext(results, ContextOptions{Mode: ContextModeContent, MaxTotalChars: 150})
+	if !strings.Contains(got, "## first") || strings.Contains(got, "## second") {
+		t.Errorf("context = %q, want only the first result", got)
+	}
+	if len(got) > 150 {
+		t.Errorf("context length = %d, want at most 150", len(got))
+	}
+}
+
+func TestToDBCandidates(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      SearchOptions
+		wantLimit int
+	}{
+		{"plain", SearchOptions{Limit: 5}, 5},
+		{"plain default", SearchOptions{}, 0},
+		{"decay", SearchOptions{Limit: 5, ApplyDecay: true}, 5 * decayCandidateFactor},
+		{"decay default", SearchOptions{ApplyDecay: true}, 10 * decayCandidateFactor},
+		{"diversity", SearchOptions{Limit: 5, Diversity: 0.5}, 5 * diversityCandidateFactor},
+		{"rerank", SearchOptions{Limit: 5, Rerank: true}, 5 * rerankCandidateFactor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.toDB(nil).Limit; got != tt.wantLimit {
+				t.Errorf("toDB().Limit = %d, want %d", got, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestTrimResults(t *testing.T) {
+	candidates := make([]int, 30)
+	for i := range candidates {
+		candidates[i] = i
+	}
+
+	tests := []struct {
+		name    string
+		results []int
+		opts    SearchOptions
+		want    int
+	}{
+		{"over-fetched cut to limit", candidates, SearchOptions{Limit: 5, ApplyDecay: true}, 5},
+		{"default limit", candidates, SearchOptions{ApplyDecay: true}, 10},
+		{"fewer than limit kept", candidates[:3], SearchOptions{Limit: 5}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := trimResults(tt.results, tt.opts)
+			if len(got) != tt.want {
+				t.Fatalf("len = %d, want %d", len(got), tt.want)
+			}
+			// The best ranked candidates are kept
+			if !slices.Equal(got, tt.results[:tt.want]) {
+				t.Errorf("trimResults() = %v, want the first %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortResults(t *testing.T) {
+	now := time.Now()
+	// In relevance order
+	ranked := []models.Entity{
+		{Name: "relevant", AccessCount: 1, Accessed: now.Add(-48 * time.Hour)},
+		{Name: "popular", AccessCount: 50, Accessed: now.Add(-24 * time.Hour)},
+		{Name: "recent", AccessCount: 5, Accessed: now},
+		{Name: "also popular", AccessCount: 50, Accessed: now.Add(-72 * time.Hour)},
+	}
+
+	tests := []struct {
+		sortBy string
+		want   []string
+	}{
+		{"", []string{"relevant", "popular", "recent", "also popular"}},
+		{SortRelevance, []string{"relevant", "popular", "recent", "also popular"}},
+		{SortPopularity, []string{"popular", "also popular", "recent", "relevant"}},
+		{SortRecency, []string{"recent", "popular", "relevant", "also popular"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			results := append([]models.Entity(nil), ranked...)
+			sortResults(results, SearchOptions{SortBy: tt.sortBy}, func(e models.Entity) models.Entity { return e })
+
+			names := make([]string, len(results))
+			for i, e := range results {
+				names[i] = e.Name
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("order = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	if err := validateSortBy("alphabetical"); err == nil {
+		t.Error("validateSortBy(alphabetical) = nil, want error")
+	}
+}
+
+func TestValidateSearchMode(t *testing.T) {
+	noEmbedder := &SearchService{}
+
+	tests := []struct {
+		mode    string
+		wantErr bool
+	}{
+		{"", false},
+		{db.SearchModeHybrid, false},
+		{db.SearchModeKeyword, false},
+		{db.SearchModeVector, true}, // no embedding model to embed the query
+		{"fuzzy", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			err := noEmbedder.validateSearchMode(tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSearchMode(%q) error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestContextOptionsValidate(t *testing.T) {
+	tests := []struct {
+		mode    string
+		wantErr bool
+	}{
+		{"", false},
+		{ContextModeChunks, false},
+		{ContextModeContent, false},
+		{"contents", true},
+		{"Chunks", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			err := ContextOptions{Mode: tt.mode}.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() with mode %q error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSendResults(t *testing.T) {
+	results := []models.EntitySearchResult{
+		{Entity: models.Entity{Name: "first"}, Score: 0.9},
+		{Entity: models.Entity{Name: "second"}, Score: 0.5},
+		{Entity: models.Entity{Name: "third"}, Score: 0.1},
+	}
+	errStop := errors.New("client gone")
+
+	tests := []struct {
+		name     string
+		failAt   int // onResult fails for this result (-1 = never)
+		wantErr  error
+		wantGot  []string
+		wantSent []string
+	}{
+		{"all in rank order", -1, nil, []string{"first", "second", "third"}, []string{"first", "second", "third"}},
+		{"stops at onResult error", 1, errStop, []string{"first", "second"}, []string{"first"}},
+		{"fails on first", 0, errStop, []string{"first"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got, sent []string
+			err := sendResults(results, func(r models.EntitySearchResult) error {
+				got = append(got, r.Name)
+				if len(got)-1 == tt.failAt {
+					return errStop
+				}
+				return nil
+			}, func(r models.EntitySearchResult) {
+				sent = append(sent, r.Name)
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("sendResults() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.wantGot) {
+				t.Errorf("onResult got %v, want %v", got, tt.wantGot)
+			}
+			// Results the client refused aren't tracked as accessed
+			if !slices.Equal(sent, tt.wantSent) {
+				t.Errorf("sent = %v, want %v", sent, tt.wantSent)
+			}
+		})
+	}
+}
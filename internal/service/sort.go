@@ -0,0 +1,41 @@
+package service
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// Search result orders.
+const (
+	SortRelevance  = "relevance"  // Ranked order of the search (default)
+	SortRecency    = "recency"    // Most recently accessed first
+	SortPopularity = "popularity" // Most accessed first
+)
+
+// validateSortBy checks that sortBy is empty or a known result order.
+func validateSortBy(sortBy string) error {
+	switch sortBy {
+	case "", SortRelevance, SortRecency, SortPopularity:
+		return nil
+	}
+	return fmt.Errorf("unknown sort order %q (want %s, %s or %s)", sortBy, SortRelevance, SortRecency, SortPopularity)
+}
+
+// sortResults reorders ranked search results by opts.SortBy. The results
+// are the ones the search picked by relevance, only their order changes;
+// ties keep their relevance order. entity extracts a result's entity.
+func sortResults[T any](results []T, opts SearchOptions, entity func(T) models.Entity) {
+	switch opts.SortBy {
+	case SortRecency:
+		slices.SortStableFunc(results, func(a, b T) int {
+			return entity(b).Accessed.Compare(entity(a).Accessed)
+		})
+	case SortPopularity:
+		slices.SortStableFunc(results, func(a, b T) int {
+			return cmp.Compare(entity(b).AccessCount, entity(a).AccessCount)
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/raphaelgruber/memcp-go/internal/llm"
+	"github.com/raphaelgruber/memcp-go/internal/models"
+)
+
+// autoSummarizeMinLength is the minimum content length (in characters) for
+// which a summary is generated. Shorter content is its own preview.
+const autoSummarizeMinLength = 500
+
+// summarizeListPageSize is the number of entity IDs fetched per query when
+// listing entities without a summary.
+const summarizeListPageSize = 500
+
+// GenerateMissingSummariesAsync starts a background job that generates LLM
+// summaries for existing entities with long content but no summary,
+// including entities whose content is kept only in chunks. Entities are
+// loaded one at a time by the workers. The job's FilesProcessed result
+// counts summarized entities.
+func (s *IngestService) GenerateMissingSummariesAsync(ctx context.Context, jobManager *JobManager) (*Job, error) {
+	if s.model == nil {
+		return nil, fmt.Errorf("LLM is disabled, cannot generate summaries")
+	}
+
+	// Track entity IDs as the job's work items
+	var ids []string
+	for {
+		after := ""
+		if len(ids) > 0 {
+			after = ids[len(ids)-1]
+		}
+		page, err := s.db.ListEntitiesWithoutSummary(ctx, autoSummarizeMinLength, after, summarizeListPageSize)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, page...)
+		if len(page) < summarizeListPageSize {
+			break
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no entities without summary found")
+	}
+
+	job, err := jobManager.CreateJob(ctx, "summarize", "", "", ids, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create job: %w", err)
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("job goroutine panicked", "job_id", job.ID, "panic", r)
+				jobManager.Fail(context.Background(), job, fmt.Errorf("internal panic: %v", r))
+			}
+		}()
+
+		bgCtx := context.Background()
+		jobCtx, done := jobManager.start(job)
+		defer done()
+		jobManager.SetRunning(bgCtx, job)
+
+		result := s.generateSummaries(jobCtx, jobManager, job, ids)
+		jobManager.Complete(bgCtx, job, result)
+	}()
+
+	return job, nil
+}
+
+// generateSummaries summarizes entities using the job manager's worker pool.
+func (s *IngestService) generateSummaries(ctx context.Context, jobManager *JobManager, job *Job, ids []string) *IngestResult {
+	slog.Info("starting summary generation", "entities", len(ids), "concurrency", jobManager.Concurrency())
+
+	var (
+		processed  atomic.Int32
+		summarized atomic.Int32
+		errorsMu   sync.Mutex
+		errs       []string
+	)
+
+	workChan := make(chan string, len(ids))
+	var wg sync.WaitGroup
+	var fatalOnce sync.Once
+	fatalCh := make(chan struct{}) // closed on fatal API error to abort all workers
+
+	for i := 0; i < jobManager.Concurrency(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range workChan {
+				select {
+				case <-fatalCh:
+					return
+				default:
+				}
+				if ctx.Err() != nil {
+					return
+				}
+
+				current := processed.Add(1)
+				jobManager.UpdateProgress(ctx, job, int(current), len(ids))
+
+				name := id
+				entity, err := s.summarizableEntity(ctx, id)
+				if err == nil {
+					name = entity.Name
+					_, err = s.summarizeEntity(ctx, *entity)
+				}
+				if err != nil {
+					if errors.Is(err, llm.ErrFatalAPI) {
+						fatalOnce.Do(func() { close(fatalCh) })
+					}
+					errorsMu.Lock()
+					errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+					errorsMu.Unlock()
+					continue
+				}
+				summarized.Add(1)
+			}
+		}()
+	}
+
+	for _, id := range ids {
+		workChan <- id
+	}
+	close(workChan)
+	wg.Wait()
+
+	slog.Info("summary generation complete", "summarized", summarized.Load(), "errors", len(errs))
+
+	return &IngestResult{
+		FilesProcessed: int(summarized.Load()),
+		Errors:         errs,
+	}
+}
+
+// SummarizeEntity generates a summary for one entity on demand and stores
+// it, replacing the entity's summary if it has one. Unlike ingest and
+// GenerateMissingSummariesAsync, content of any length is summarized.
+// Returns the updated entity.
+func (s *IngestService) SummarizeEntity(ctx context.Context, id string) (*models.Entity, error) {
+	if s.model == nil {
+		return nil, fmt.Errorf("LLM is disabled, cannot generate summaries")
+	}
+
+	entity, err := s.summarizableEntity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.summarizeEntity(ctx, *entity)
+}
+
+// summarizableEntity loads an entity with its content, reassembling content
+// kept only in chunks. It fails for an entity without content.
+func (s *IngestService) summarizableEntity(ctx context.Context, id string) (*models.Entity, error) {
+	entity, err := s.db.GetEntity(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get entity: %w", err)
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("entity not found: %s", id)
+	}
+	if entity.Content == nil {
+		// Content may be stored only in chunks
+		content, err := s.entityService.chunkedContent(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("reassemble content: %w", err)
+		}
+		if content != "" {
+			entity.Content = &content
+		}
+	}
+	if entity.Content == nil || strings.TrimSpace(*entity.Content) == "" {
+		return nil, fmt.Errorf("entity %s has no content to summarize", id)
+	}
+	return entity, nil
+}
+
+// summarizeEntity generates and stores a summary for a single entity.
+// Returns the updated entity, or nil for an entity without content.
+func (s *IngestService) summarizeEntity(ctx context.Context, entity models.Entity) (*models.Entity, error) {
+	if entity.Content == nil {
+		return nil, nil
+	}
+
+	id, err := models.RecordIDString(entity.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get entity ID: %w", err)
+	}
+
+	summary, err := s.model.Summarize(ctx, *entity.Content)
+	if err != nil {
+		return nil, fmt.Errorf("summarize: %w", err)
+	}
+	if summary == "" {
+		return nil, fmt.Errorf("summarize: empty response")
+	}
+
+	updated, err := s.db.UpdateEntity(ctx, id, models.EntityUpdate{Summary: &summary})
+	if err != nil {
+		return nil, fmt.Errorf("save summary: %w", err)
+	}
+	s.entityService.events.Publish(EntityUpdated, updated)
+	return updated, nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 of the request body keyed
+// with the webhook secret, as "sha256=<hex>".
+const WebhookSignatureHeader = "X-Knowhow-Signature"
+
+const (
+	webhookTimeout  = 5 * time.Second // Per delivery attempt
+	webhookAttempts = 3
+)
+
+// JobWebhookPayload is the JSON body posted when a job finishes.
+type JobWebhookPayload struct {
+	JobID       string            `json:"job_id"`
+	Type        string            `json:"type"`
+	Name        string            `json:"name,omitempty"`
+	Status      JobStatus         `json:"status"`
+	Error       string            `json:"error,omitempty"`
+	Result      *JobWebhookResult `json:"result,omitempty"`
+	StartedAt   time.Time         `json:"started_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+}
+
+// JobWebhookResult summarizes the result of a finished job.
+type JobWebhookResult struct {
+	FilesProcessed   int `json:"files_processed"`
+	EntitiesCreated  int `json:"entities_created"`
+	ChunksCreated    int `json:"chunks_created"`
+	ChunksFailed     int `json:"chunks_failed"`
+	RelationsCreated int `json:"relations_created"`
+	EntitiesDeleted  int `json:"entities_deleted"`
+	Errors           int `json:"errors"`
+}
+
+// JobWebhook posts a notification to a URL when a job completes, fails or
+// is cancelled. Delivery runs in the background, is retried a few times and
+// only logs a warning if it fails, so it never affects the job.
+type JobWebhook struct {
+	url     string
+	secret  string
+	client  *http.Client
+	backoff time.Duration // Delay before the first retry, doubled for each further retry
+}
+
+// NewJobWebhook creates a webhook posting to url. Bodies are signed with
+// secret if it's set. Returns nil if url is empty.
+func NewJobWebhook(url, secret string) *JobWebhook {
+	if url == "" {
+		return nil
+	}
+	return &JobWebhook{
+		url:     url,
+		secret:  secret,
+		client:  &http.Client{Timeout: webhookTimeout},
+		backoff: time.Second,
+	}
+}
+
+// Notify posts the finished job's state in the background. The returned
+// channel is closed once delivery succeeded or was given up.
+func (w *JobWebhook) Notify(j *Job) <-chan struct{} {
+	done := make(chan struct{})
+	if w == nil {
+		close(done)
+		return done
+	}
+
+	job := j.Snapshot()
+
+	payload := JobWebhookPayload{
+		JobID:       job.ID,
+		Type:        job.Type,
+		Name:        job.Name,
+		Status:      job.Status,
+		Error:       job.Error,
+		StartedAt:   job.StartedAt,
+		CompletedAt: job.CompletedAt,
+	}
+	if r := job.Result; r != nil {
+		payload.Result = &JobWebhookResult{
+			FilesProcessed:   r.FilesProcessed,
+			EntitiesCreated:  r.EntitiesCreated,
+			ChunksCreated:    r.ChunksCreated,
+			ChunksFailed:     r.ChunksFailed,
+			RelationsCreated: r.RelationsCreated,
+			EntitiesDeleted:  r.EntitiesDeleted,
+			Errors:           len(r.Errors),
+		}
+	}
+
+	go func() {
+		defer close(done)
+		if err := w.deliver(payload); err != nil {
+			slog.Warn("failed to deliver job webhook", "job_id", job.ID, "status", job.Status, "error", err)
+		}
+	}()
+	return done
+}
+
+// deliver posts payload, retrying failed attempts with backoff.
+func (w *JobWebhook) deliver(payload JobWebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	delay := w.backoff
+	for attempt := 1; ; attempt++ {
+		err = w.post(body)
+		if err == nil {
+			return nil
+		}
+		if attempt == webhookAttempts {
+			return fmt.Errorf("after %d attempts: %w", attempt, err)
+		}
+		slog.Debug("job webhook attempt failed, retrying", "job_id", payload.JobID, "attempt", attempt, "error", err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// post sends one delivery attempt. Any non-2xx response is an error.
+func (w *JobWebhook) post(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(w.secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// SignWebhook returns the hex-encoded HMAC-SHA256 of body keyed with secret,
+// as sent in WebhookSignatureHeader.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJobWebhook(t *testing.T) {
+	job := &Job{
+		ID:     "abc12345",
+		Type:   "ingest",
+		Name:   "docs",
+		Status: JobStatusCompleted,
+		Result: &IngestResult{FilesProcessed: 3, EntitiesCreated: 2, Errors: []string{"bad.md: parse error"}},
+	}
+
+	t.Run("posts signed payload", func(t *testing.T) {
+		var payload JobWebhookPayload
+		var signature, expected string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("read body: %v", err)
+			}
+			signature = r.Header.Get(WebhookSignatureHeader)
+			expected = "sha256=" + SignWebhook("secret", body)
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Errorf("unmarshal payload: %v", err)
+			}
+		}))
+		defer server.Close()
+
+		<-NewJobWebhook(server.URL, "secret").Notify(job)
+
+		if signature != expected {
+			t.Errorf("signature = %q, want %q", signature, expected)
+		}
+		if payload.JobID != job.ID || payload.Status != JobStatusCompleted {
+			t.Errorf("payload = %+v, want job %s completed", payload, job.ID)
+		}
+		if payload.Result == nil || payload.Result.FilesProcessed != 3 || payload.Result.Errors != 1 {
+			t.Errorf("result = %+v, want 3 files processed and 1 error", payload.Result)
+		}
+	})
+
+	t.Run("retries failed deliveries", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < webhookAttempts {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+		defer server.Close()
+
+		webhook := NewJobWebhook(server.URL, "")
+		webhook.backoff = time.Millisecond
+		<-webhook.Notify(job)
+
+		if got := calls.Load(); got != webhookAttempts {
+			t.Errorf("calls = %d, want %d", got, webhookAttempts)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		webhook := NewJobWebhook(server.URL, "")
+		webhook.backoff = time.Millisecond
+		<-webhook.Notify(job)
+
+		if got := calls.Load(); got != webhookAttempts {
+			t.Errorf("calls = %d, want %d", got, webhookAttempts)
+		}
+	})
+
+	t.Run("nil webhook is a no-op", func(t *testing.T) {
+		var webhook *JobWebhook
+		<-webhook.Notify(job)
+	})
+}
@@ -0,0 +1,92 @@
+// Package tracing propagates a request-scoped trace ID through contexts and
+// adds it to log records as trace_id, so the embedding, search and LLM logs
+// of one request can be correlated.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Header is the response header carrying the trace ID of a request.
+const Header = "X-Trace-Id"
+
+type ctxKey struct{}
+
+// NewID returns a random trace ID: 32 lowercase hex digits, as in W3C Trace
+// Context.
+func NewID() string {
+	var id [16]byte
+	// crypto/rand.Read never returns an error; it crashes if randomness is unavailable
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// WithID returns a copy of ctx carrying the trace ID id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// ID returns the trace ID carried by ctx, or "" if there is none.
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// FromTraceparent returns the trace ID of a W3C traceparent header
+// ("00-<trace-id>-<parent-id>-<flags>"), so requests from a traced caller
+// keep its trace ID. Returns "" for missing or malformed headers.
+func FromTraceparent(header string) string {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 {
+		return ""
+	}
+	id := strings.ToLower(parts[1])
+	if _, err := hex.DecodeString(id); err != nil || id == strings.Repeat("0", 32) {
+		return ""
+	}
+	return id
+}
+
+// Start marks the start of an operation of a traced request. The returned
+// function logs the operation's duration at debug level, with the trace ID,
+// so slow steps of a request stand out. Cheap when debug logging is off.
+func Start(ctx context.Context, operation string) func() {
+	start := time.Now()
+	return func() {
+		slog.DebugContext(ctx, "span", "operation", operation, "duration_ms", time.Since(start).Milliseconds())
+	}
+}
+
+// Handler is a slog.Handler adding the trace ID of the context to records
+// logged with one (slog.InfoContext etc.) as trace_id.
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps next to add trace IDs to its records.
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{Handler: next}
+}
+
+// Handle adds the trace ID of ctx, if any, to r and passes it on.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if id := ID(ctx); id != "" {
+		r.AddAttrs(slog.String("trace_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a Handler whose records include attrs.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a Handler qualifying later attributes with name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
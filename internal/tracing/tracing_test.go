@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestFromTraceparent(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"empty", "", ""},
+		{"too few parts", "00-4bf92f3577b34da6a3ce929d0e0e4736", ""},
+		{"short trace ID", "00-4bf92f35-00f067aa0ba902b7-01", ""},
+		{"not hex", "00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""},
+		{"all zeros", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromTraceparent(tt.header); got != tt.want {
+				t.Errorf("FromTraceparent(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewID(t *testing.T) {
+	id := NewID()
+	if len(id) != 32 {
+		t.Errorf("len(NewID()) = %d, want 32", len(id))
+	}
+	if id == NewID() {
+		t.Error("NewID returned the same ID twice")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil))).With("component", "test")
+
+	logger.InfoContext(WithID(context.Background(), "abc123"), "traced")
+	logger.Info("untraced")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2", len(lines))
+	}
+	if !strings.Contains(lines[0], "trace_id=abc123") || !strings.Contains(lines[0], "component=test") {
+		t.Errorf("traced record = %q, want trace_id and component", lines[0])
+	}
+	if strings.Contains(lines[1], "trace_id") {
+		t.Errorf("untraced record = %q, want no trace_id", lines[1])
+	}
+}